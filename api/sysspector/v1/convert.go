@@ -0,0 +1,46 @@
+package sysspectorv1
+
+import (
+	"encoding/json"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// NewSnapshot 将系统信息转换为Snapshot消息，完整的系统信息以JSON格式放在system_json中
+func NewSnapshot(info model.SystemInfo, collectedAt time.Time) (*Snapshot, error) {
+	systemJSON, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+	network := info.Network
+	return &Snapshot{
+		CollectedAt:      timestamppb.New(collectedAt),
+		Hostname:         info.Hostname,
+		DeviceId:         info.DeviceID,
+		Os:               info.OS,
+		Model:            info.Model,
+		SerialNumber:     info.SerialNumber,
+		SystemVersion:    info.SystemVersion,
+		Uptime:           info.UpTime,
+		CpuModel:         info.CPU.Model,
+		CpuCores:         int32(info.CPU.Cores),
+		MemoryTotalBytes: info.Memory.Total,
+		Network: &NetworkSummary{
+			Ip:                network.IP,
+			Ssid:              network.WiFi.SSID,
+			PublicIp:          network.PublicIP,
+			VpnConnected:      network.VPN.IsConnected,
+			VpnProvider:       network.VPN.Provider,
+			AvgLatencyMs:      network.Latency.AvgLatency,
+			JitterMs:          network.Latency.Jitter,
+			PacketLossPercent: network.Latency.PacketLoss,
+			HealthScore:       int32(network.HealthScore.Score),
+			HealthGrade:       network.HealthScore.Grade,
+			HealthVerdict:     network.HealthScore.Verdict,
+		},
+		SystemJson: systemJSON,
+	}, nil
+}
@@ -0,0 +1,4 @@
+// Package sysspectorv1 是SysSpector gRPC服务的接口定义（sysspector.proto）及其生成的代码
+package sysspectorv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative sysspector/v1/sysspector.proto
@@ -0,0 +1,217 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: sysspector/v1/sysspector.proto
+
+package sysspectorv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	SysSpector_GetSnapshot_FullMethodName          = "/sysspector.v1.SysSpector/GetSnapshot"
+	SysSpector_StreamDynamicMetrics_FullMethodName = "/sysspector.v1.SysSpector/StreamDynamicMetrics"
+	SysSpector_RunProbe_FullMethodName             = "/sysspector.v1.SysSpector/RunProbe"
+)
+
+// SysSpectorClient is the client API for SysSpector service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SysSpectorClient interface {
+	// GetSnapshot 返回系统信息快照，缓存时长内返回上次的采集结果
+	GetSnapshot(ctx context.Context, in *GetSnapshotRequest, opts ...grpc.CallOption) (*Snapshot, error)
+	// StreamDynamicMetrics 按指定间隔持续推送CPU、内存和网络速率，直到客户端取消
+	StreamDynamicMetrics(ctx context.Context, in *StreamDynamicMetricsRequest, opts ...grpc.CallOption) (SysSpector_StreamDynamicMetricsClient, error)
+	// RunProbe 对指定目标执行一次ICMP、TCP或HTTP延迟探测
+	RunProbe(ctx context.Context, in *RunProbeRequest, opts ...grpc.CallOption) (*ProbeResult, error)
+}
+
+type sysSpectorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSysSpectorClient(cc grpc.ClientConnInterface) SysSpectorClient {
+	return &sysSpectorClient{cc}
+}
+
+func (c *sysSpectorClient) GetSnapshot(ctx context.Context, in *GetSnapshotRequest, opts ...grpc.CallOption) (*Snapshot, error) {
+	out := new(Snapshot)
+	err := c.cc.Invoke(ctx, SysSpector_GetSnapshot_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sysSpectorClient) StreamDynamicMetrics(ctx context.Context, in *StreamDynamicMetricsRequest, opts ...grpc.CallOption) (SysSpector_StreamDynamicMetricsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SysSpector_ServiceDesc.Streams[0], SysSpector_StreamDynamicMetrics_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sysSpectorStreamDynamicMetricsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SysSpector_StreamDynamicMetricsClient interface {
+	Recv() (*DynamicMetrics, error)
+	grpc.ClientStream
+}
+
+type sysSpectorStreamDynamicMetricsClient struct {
+	grpc.ClientStream
+}
+
+func (x *sysSpectorStreamDynamicMetricsClient) Recv() (*DynamicMetrics, error) {
+	m := new(DynamicMetrics)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sysSpectorClient) RunProbe(ctx context.Context, in *RunProbeRequest, opts ...grpc.CallOption) (*ProbeResult, error) {
+	out := new(ProbeResult)
+	err := c.cc.Invoke(ctx, SysSpector_RunProbe_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SysSpectorServer is the server API for SysSpector service.
+// All implementations must embed UnimplementedSysSpectorServer
+// for forward compatibility
+type SysSpectorServer interface {
+	// GetSnapshot 返回系统信息快照，缓存时长内返回上次的采集结果
+	GetSnapshot(context.Context, *GetSnapshotRequest) (*Snapshot, error)
+	// StreamDynamicMetrics 按指定间隔持续推送CPU、内存和网络速率，直到客户端取消
+	StreamDynamicMetrics(*StreamDynamicMetricsRequest, SysSpector_StreamDynamicMetricsServer) error
+	// RunProbe 对指定目标执行一次ICMP、TCP或HTTP延迟探测
+	RunProbe(context.Context, *RunProbeRequest) (*ProbeResult, error)
+	mustEmbedUnimplementedSysSpectorServer()
+}
+
+// UnimplementedSysSpectorServer must be embedded to have forward compatible implementations.
+type UnimplementedSysSpectorServer struct {
+}
+
+func (UnimplementedSysSpectorServer) GetSnapshot(context.Context, *GetSnapshotRequest) (*Snapshot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSnapshot not implemented")
+}
+func (UnimplementedSysSpectorServer) StreamDynamicMetrics(*StreamDynamicMetricsRequest, SysSpector_StreamDynamicMetricsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamDynamicMetrics not implemented")
+}
+func (UnimplementedSysSpectorServer) RunProbe(context.Context, *RunProbeRequest) (*ProbeResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunProbe not implemented")
+}
+func (UnimplementedSysSpectorServer) mustEmbedUnimplementedSysSpectorServer() {}
+
+// UnsafeSysSpectorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SysSpectorServer will
+// result in compilation errors.
+type UnsafeSysSpectorServer interface {
+	mustEmbedUnimplementedSysSpectorServer()
+}
+
+func RegisterSysSpectorServer(s grpc.ServiceRegistrar, srv SysSpectorServer) {
+	s.RegisterService(&SysSpector_ServiceDesc, srv)
+}
+
+func _SysSpector_GetSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SysSpectorServer).GetSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SysSpector_GetSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SysSpectorServer).GetSnapshot(ctx, req.(*GetSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SysSpector_StreamDynamicMetrics_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamDynamicMetricsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SysSpectorServer).StreamDynamicMetrics(m, &sysSpectorStreamDynamicMetricsServer{stream})
+}
+
+type SysSpector_StreamDynamicMetricsServer interface {
+	Send(*DynamicMetrics) error
+	grpc.ServerStream
+}
+
+type sysSpectorStreamDynamicMetricsServer struct {
+	grpc.ServerStream
+}
+
+func (x *sysSpectorStreamDynamicMetricsServer) Send(m *DynamicMetrics) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _SysSpector_RunProbe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunProbeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SysSpectorServer).RunProbe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SysSpector_RunProbe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SysSpectorServer).RunProbe(ctx, req.(*RunProbeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SysSpector_ServiceDesc is the grpc.ServiceDesc for SysSpector service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SysSpector_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sysspector.v1.SysSpector",
+	HandlerType: (*SysSpectorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetSnapshot",
+			Handler:    _SysSpector_GetSnapshot_Handler,
+		},
+		{
+			MethodName: "RunProbe",
+			Handler:    _SysSpector_RunProbe_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamDynamicMetrics",
+			Handler:       _SysSpector_StreamDynamicMetrics_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "sysspector/v1/sysspector.proto",
+}
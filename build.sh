@@ -1,20 +1,54 @@
 #!/bin/bash
 
+# 版本号，用于检查更新，例如 VERSION=1.5.0 ./build.sh
+VERSION=${VERSION:-dev}
+LDFLAGS="-X main.version=$VERSION"
+
+# 历史数据库使用的SQLite驱动（github.com/mattn/go-sqlite3）需要cgo，CGO_ENABLED=0编译的程序中只有一个
+# 打开数据库时就返回错误的桩实现，因此每个目标都需要对应平台的C编译器，可以通过环境变量指定：
+#   CC_WINDOWS      Windows 64位，默认为x86_64-w64-mingw32-gcc（mingw-w64）
+#   CC_DARWIN_AMD64 macOS Intel，在macOS上默认为clang -arch x86_64，其他系统上默认为o64-clang（osxcross）
+#   CC_DARWIN_ARM64 macOS M系列芯片，在macOS上默认为clang -arch arm64，其他系统上默认为oa64-clang（osxcross）
+#   CC_LINUX        Linux 64位（运行aggregate汇总服务），默认为x86_64-linux-gnu-gcc
+if [ "$(uname -s)" = "Darwin" ]; then
+    CC_DARWIN_AMD64=${CC_DARWIN_AMD64:-"clang -arch x86_64"}
+    CC_DARWIN_ARM64=${CC_DARWIN_ARM64:-"clang -arch arm64"}
+else
+    CC_DARWIN_AMD64=${CC_DARWIN_AMD64:-o64-clang}
+    CC_DARWIN_ARM64=${CC_DARWIN_ARM64:-oa64-clang}
+fi
+CC_WINDOWS=${CC_WINDOWS:-x86_64-w64-mingw32-gcc}
+CC_LINUX=${CC_LINUX:-x86_64-linux-gnu-gcc}
+
+# build 使用cgo编译一个目标，找不到C编译器时退出，避免生成无法打开数据库的程序
+build() {
+    local goos=$1 goarch=$2 cc=$3 output=$4
+    if ! command -v ${cc%% *} >/dev/null 2>&1; then
+        echo "找不到 $goos/$goarch 的C编译器 ${cc%% *}，请安装或通过环境变量指定" >&2
+        exit 1
+    fi
+    CGO_ENABLED=1 CC="$cc" GOOS=$goos GOARCH=$goarch go build -ldflags "$LDFLAGS" -o "$output" ./cmd/sysinfo || exit 1
+}
+
 # 创建输出目录
 mkdir -p build
 
-echo "开始编译 SysSpector..."
+echo "开始编译 SysSpector $VERSION..."
 
 # 编译 Windows 64位版本
 echo "编译 Windows 64位版本..."
-GOOS=windows GOARCH=amd64 go build -o build/sysinfo_windows_amd64.exe ./cmd/sysinfo
+build windows amd64 "$CC_WINDOWS" build/sysinfo_windows_amd64.exe
 
 # 编译 macOS Intel版本
 echo "编译 macOS Intel版本..."
-GOOS=darwin GOARCH=amd64 go build -o build/sysinfo_macos_intel ./cmd/sysinfo
+build darwin amd64 "$CC_DARWIN_AMD64" build/sysinfo_macos_intel
 
 # 编译 macOS M系列芯片版本
 echo "编译 macOS M系列芯片版本..."
-GOOS=darwin GOARCH=arm64 go build -o build/sysinfo_macos_arm ./cmd/sysinfo
+build darwin arm64 "$CC_DARWIN_ARM64" build/sysinfo_macos_arm
+
+# 编译 Linux 64位版本，用于在服务器上运行aggregate汇总服务
+echo "编译 Linux 64位版本..."
+build linux amd64 "$CC_LINUX" build/sysinfo_linux_amd64
 
 echo "编译完成！所有二进制文件都在 build 目录中。"
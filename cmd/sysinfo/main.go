@@ -2,74 +2,1637 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math/rand"
+	"net"
+	"net/http"
 	"os"
 	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"reflect"
 	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/aggregator"
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/internal/collect"
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/darwin"
+	"github.com/AsterZephyr/SysSpector/internal/device"
+	"github.com/AsterZephyr/SysSpector/internal/history"
+	"github.com/AsterZephyr/SysSpector/internal/logfile"
+	"github.com/AsterZephyr/SysSpector/internal/report"
+	"github.com/AsterZephyr/SysSpector/internal/server"
+	"github.com/AsterZephyr/SysSpector/internal/sink"
+	"github.com/AsterZephyr/SysSpector/internal/tlsconfig"
+	"github.com/AsterZephyr/SysSpector/internal/update"
+	"github.com/AsterZephyr/SysSpector/internal/windows"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// version 为程序的版本号，发布时通过 -ldflags "-X main.version=1.5.0" 设置，用于检查更新
+var version = "dev"
+
+func main() {
+	// 如果参数为 --version，则打印版本号
+	if len(os.Args) > 1 && os.Args[1] == "--version" {
+		fmt.Println(version)
+		return
+	}
+
+	// 设置日志输出到标准错误
+	log.SetOutput(os.Stderr)
+	log.Println("Starting system information collection...")
+
+	// 加载配置文件，可通过 --config 参数指定路径
+	if err := loadConfig(); err != nil {
+		log.Printf("Error loading config: %v", err)
+	}
+
+	// 如果命令行参数中包含 --watch，则持续监控网络延迟，直到按下 Ctrl+C
+	if hasArg("--watch") {
+		watchLatency()
+		return
+	}
+
+	// 如果参数为 history query，则查询serve模式保存的历史数据
+	if len(os.Args) > 2 && os.Args[1] == "history" && os.Args[2] == "query" {
+		if err := queryHistory(); err != nil {
+			log.Fatalf("Error querying history: %v", err)
+		}
+		return
+	}
+
+	// 如果第一个参数为 enroll，则向设备管理服务器注册本机，保存设备令牌和下发的配置
+	if len(os.Args) > 1 && os.Args[1] == "enroll" {
+		if err := enroll(context.Background()); err != nil {
+			log.Fatalf("Error enrolling device: %v", err)
+		}
+		return
+	}
+
+	// 如果第一个参数为 update，则从更新通道下载并校验新版本，替换程序后重新启动已安装的服务
+	if len(os.Args) > 1 && os.Args[1] == "update" {
+		if err := runUpdate(context.Background()); err != nil {
+			log.Fatalf("Error updating: %v", err)
+		}
+		return
+	}
+
+	// 如果第一个参数为 aggregate，则启动汇总服务，接收多台设备推送的快照并提供设备清单的查询和导出
+	if len(os.Args) > 1 && os.Args[1] == "aggregate" {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		err := aggregate(ctx)
+		stop()
+		if err != nil {
+			log.Fatalf("Error serving aggregator: %v", err)
+		}
+		return
+	}
+
+	// 如果第一个参数为 serve，则启动REST API服务，按需采集系统信息并以JSON格式返回
+	// 由Windows服务控制管理器启动时，停止请求通过服务控制管理器发送，而不是Ctrl+C
+	// 自动更新替换程序后，Windows服务以错误退出，由服务的恢复操作重新启动；其他情况下直接执行新的程序
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		var err error
+		if windows.IsService() {
+			err = windows.RunService(serve)
+		} else {
+			// macOS的LaunchDaemon以 --log-file 指定日志文件，由程序按大小轮转
+			if path, ok := argValue("--log-file"); ok {
+				log.SetOutput(logfile.New(path))
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			err = serve(ctx)
+			stop()
+			if errors.Is(err, update.ErrRestart) {
+				log.Printf("Restarting to apply the update...")
+				err = update.Restart()
+			}
+		}
+		if err != nil {
+			log.Fatalf("Error serving API: %v", err)
+		}
+		return
+	}
+
+	// 如果第一个参数为 service，则将 serve 安装为系统服务（macOS LaunchDaemon、Windows服务），或卸载、启动、停止服务
+	if len(os.Args) > 2 && os.Args[1] == "service" {
+		if err := manageService(os.Args[2]); err != nil {
+			log.Fatalf("Error managing service: %v", err)
+		}
+		return
+	}
+
+	sysInfo, err := collectSystemInfo()
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+
+	// 以格式化的方式打印系统信息
+	printSystemInfo(sysInfo)
+
+	// 配置了推送地址时，将快照推送到远程收集服务器
+	if config.Current().Push.URL != "" {
+		pushSnapshot(sysInfo, time.Now())
+	}
+
+	// 配置了对象存储时，将快照上传到存储桶，离线模式下不上传
+	if config.Current().Upload.Bucket != "" && !config.Current().Offline {
+		uploadSnapshot(sysInfo, time.Now())
+	}
+
+	// 配置了MQTT服务器时，将快照发布到MQTT主题，离线模式下不发布
+	if config.Current().MQTT.Broker != "" && !config.Current().Offline {
+		publishSnapshot(sysInfo, time.Now())
+	}
+
+	// 配置了通知渠道时，将告警发送到对应的渠道，离线模式下不发送
+	if notifyCfg := config.Current().Notify; len(notifyCfg.Channels) > 0 && !config.Current().Offline {
+		if alerts := sink.DetectAlerts(sysInfo, notifyCfg, time.Now()); len(alerts) > 0 {
+			notifier, err := sink.NewNotifier(notifyCfg)
+			if err == nil {
+				err = notifier.Notify(context.Background(), alerts)
+			}
+			if err != nil {
+				log.Printf("Error sending notifications: %v", err)
+			}
+		}
+	}
+
+	// 配置了syslog服务器时，转发快照摘要和告警，离线模式下不转发
+	if syslogCfg := config.Current().Syslog; syslogCfg.Address != "" && !config.Current().Offline {
+		forwarder, err := sink.NewSyslogForwarder(syslogCfg)
+		if err == nil {
+			err = forwarder.Forward(sysInfo, time.Now(), sink.DetectAlerts(sysInfo, config.Current().Notify, time.Now()))
+			forwarder.Close()
+		}
+		if err != nil {
+			log.Printf("Error forwarding to syslog: %v", err)
+		}
+	}
+
+	// 如果命令行参数中包含 --email，则立即发送一次本机的邮件报告，离线模式下不发送
+	if hasArg("--email") && !config.Current().Offline {
+		emailReport(sysInfo, time.Now())
+	}
+
+	// 如果命令行参数中包含 --save，则将系统信息保存到文件
+	if len(os.Args) > 1 && os.Args[1] == "--save" {
+		outputFile := "sysinfo.txt"
+		if len(os.Args) > 2 {
+			// 如果提供了文件名，则使用提供的文件名
+			outputFile = os.Args[2]
+		}
+
+		// 格式化输出内容
+		output := formatSystemInfo(sysInfo)
+
+		// 写入文件
+		err = os.WriteFile(outputFile, []byte(output), 0644)
+		if err != nil {
+			log.Fatalf("Error writing to file %s: %v", outputFile, err)
+		}
+		log.Printf("System information saved to %s", outputFile)
+	}
+
+	// 在Windows系统上，程序结束前暂停，等待用户按键
+	if runtime.GOOS == "windows" {
+		fmt.Println("\nPress Enter to exit...")
+		reader := bufio.NewReader(os.Stdin)
+		reader.ReadString('\n')
+	}
+}
+
+// collectSystemInfo 采集当前平台的系统信息，并完成磁盘趋势、按参数开启的深度扫描、资源占用汇总和网络健康评分
+func collectSystemInfo() (model.SystemInfo, error) {
+	var sysInfo model.SystemInfo
+	var err error
+
+	if runtime.GOOS == "darwin" {
+		sysInfo, err = darwin.GetSystemInfo()
+	} else if runtime.GOOS == "windows" {
+		sysInfo, err = windows.GetAllSystemInfo()
+	} else {
+		return sysInfo, fmt.Errorf("Unsupported OS: %s", runtime.GOOS)
+	}
+	if err != nil {
+		return sysInfo, fmt.Errorf("Error getting system info: %v", err)
+	}
+
+	// 开启了历史数据库时记录磁盘使用并计算增长趋势
+	if err := updateDiskTrend(&sysInfo); err != nil {
+		log.Printf("Error updating disk history: %v", err)
+	}
+
+	// 如果命令行参数中包含 --scan-disk，则进行深度磁盘空间分析（可能需要数分钟）
+	if hasArg("--scan-disk") {
+		log.Println("Scanning disk usage, this may take a few minutes...")
+		if err := scanDisk(&sysInfo); err != nil {
+			log.Printf("Error scanning disk: %v", err)
+		}
+	}
+
+	// 如果命令行参数中包含 --scan-wifi，则扫描附近的无线网络并评估信道拥挤程度
+	if hasArg("--scan-wifi") {
+		log.Println("Scanning nearby Wi-Fi networks...")
+		if err := scanWiFi(&sysInfo); err != nil {
+			log.Printf("Error scanning Wi-Fi networks: %v", err)
+		}
+	}
+
+	// 如果命令行参数中包含 --scan-lan，则通过mDNS发现局域网中的打印机、AirPlay等服务，检查组播是否被过滤
+	if hasArg("--scan-lan") {
+		log.Println("Browsing mDNS services on the local network...")
+		sysInfo.Network.MDNS = analysis.DiscoverMDNS(config.Current().MDNS.ServiceTypes)
+	}
+
+	// 如果命令行参数中包含 --scan-switch，则抓取有线网络的LLDP/CDP通告，获取所连接的交换机端口（需要管理员权限）
+	if hasArg("--scan-switch") {
+		log.Println("Capturing LLDP/CDP announcements on wired interfaces...")
+		if err := scanSwitch(&sysInfo); err != nil {
+			log.Printf("Error capturing LLDP/CDP: %v", err)
+		}
+	}
+
+	// 如果命令行参数中包含 --network-quality，则测试下载占满带宽时的延迟（RPM），发现空闲时ping无法反映的缓冲区膨胀
+	if hasArg("--network-quality") {
+		if config.Current().Offline {
+			log.Println("Offline mode, skipping network quality test")
+		} else {
+			log.Println("Measuring responsiveness under load, this saturates the link for about 20 seconds...")
+			if err := measureNetworkQuality(&sysInfo); err != nil {
+				log.Printf("Error measuring network quality: %v", err)
+			}
+		}
+	}
+
+	// 汇总资源占用最高的进程，阈值可通过 --hog-cpu、--hog-mem、--hog-net 参数调整
+	analysis.ApplyProcessTraffic(sysInfo.RunningApps, sysInfo.Network.ProcessTrafficStats)
+	sysInfo.ResourceHogs = analysis.SummarizeResourceHogs(sysInfo.RunningApps, hogThresholds())
+
+	// 根据延迟、丢包、DNS和端点检查结果计算网络健康评分
+	sysInfo.Network.HealthScore = analysis.ScoreNetworkHealth(sysInfo.Network)
+
+	// 设备ID随每个报告一起发送，用于在多台设备的数据中关联同一台设备
+	sysInfo.DeviceID = device.ID(sysInfo.UUID, config.Current().Device.Salt)
+
+	return sysInfo, nil
+}
+
+// deviceID 获取硬件UUID并计算设备ID，不需要采集其他系统信息
+func deviceID() (string, error) {
+	var uuid string
+	var err error
+	if runtime.GOOS == "darwin" {
+		uuid, err = darwin.HardwareUUID()
+	} else if runtime.GOOS == "windows" {
+		uuid, err = windows.HardwareUUID()
+	} else {
+		return "", fmt.Errorf("Unsupported OS: %s", runtime.GOOS)
+	}
+	if err != nil {
+		return "", err
+	}
+	id := device.ID(uuid, config.Current().Device.Salt)
+	if id == "" {
+		return "", errors.New("hardware UUID is empty")
+	}
+	return id, nil
+}
+
+// hasArg 判断命令行参数中是否包含指定参数
+func hasArg(name string) bool {
+	for _, arg := range os.Args[1:] {
+		if arg == name {
+			return true
+		}
+	}
+	return false
+}
+
+// argValue 获取 --name=value 或 --name value 形式的命令行参数值
+func argValue(name string) (string, bool) {
+	args := os.Args[1:]
+	for i, arg := range args {
+		if value, ok := strings.CutPrefix(arg, name+"="); ok {
+			return value, true
+		}
+		if arg == name && i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
+			return args[i+1], true
+		}
+	}
+	return "", false
+}
+
+// configWatchInterval serve模式下检查配置文件是否修改的间隔
+const configWatchInterval = 5 * time.Second
+
+// configPath 返回 --config 指定的配置文件路径，未指定时返回默认路径，explicit表示是否通过参数指定
+func configPath() (path string, explicit bool, err error) {
+	path, explicit = argValue("--config")
+	if !explicit {
+		path, err = config.DefaultPath()
+	}
+	return path, explicit, err
+}
+
+// loadConfig 加载 --config 指定的配置文件，未指定时使用默认路径，默认路径下没有配置文件时使用默认配置
+// 配置文件无效时使用默认配置，注册结果无法应用时仍然使用配置文件
+func loadConfig() error {
+	cfg, err := buildConfig()
+	if cfg == nil {
+		cfg = config.Default()
+		applyOfflineArg(cfg)
+	}
+	config.Set(cfg)
+	return err
+}
+
+// reloadConfig 从默认配置开始重新加载配置文件和注册结果，完整构建新配置后一次发布，失败时保留当前配置
+func reloadConfig() error {
+	cfg, err := buildConfig()
+	if err != nil {
+		return err
+	}
+	config.Set(cfg)
+	return nil
+}
+
+// buildConfig 读取配置文件，叠加注册结果和 --offline 参数，返回构建出的配置，不修改当前配置
+// 配置文件无效时返回nil；注册结果无法应用时返回只有配置文件的配置和错误
+func buildConfig() (*config.Config, error) {
+	path, explicit, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	// Windows服务以SYSTEM运行，普通用户可以修改的配置文件不加载
+	if windows.IsService() {
+		if err := windows.CheckConfigPermissions(path); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		if explicit || !os.IsNotExist(err) {
+			return nil, err
+		}
+		cfg = config.Default()
+	} else {
+		log.Printf("Loaded config from %s", path)
+	}
+	// 下发的配置不能修改offline，叠加注册结果后仍然保留
+	applyOfflineArg(cfg)
+
+	enrolled, err := loadEnrollment(cfg)
+	if err != nil {
+		return cfg, err
+	}
+	return enrolled, nil
+}
+
+// applyOfflineArg 如果命令行参数中包含 --offline，则不访问公网IP查询等外部服务
+func applyOfflineArg(cfg *config.Config) {
+	if hasArg("--offline") {
+		cfg.Offline = true
+	}
+}
+
+// loadEnrollment 已向设备管理服务器注册时，返回在cfg上应用保存的注册结果后的配置
+func loadEnrollment(cfg *config.Config) (*config.Config, error) {
+	if cfg.Device.EnrollURL == "" {
+		return cfg, nil
+	}
+	path, err := device.StatePath(cfg.Device)
+	if err != nil {
+		return nil, err
+	}
+	enrollment, err := device.LoadEnrollment(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, err
+	}
+	return applyEnrollment(cfg, enrollment)
+}
+
+// applyEnrollment 返回将服务器下发的配置叠加到cfg上（只覆盖允许下发的字段，见config.Config.Overlay）后的配置，
+// 没有配置push.token、commands.token时使用设备令牌，cfg本身不修改
+func applyEnrollment(cfg *config.Config, enrollment *device.Enrollment) (*config.Config, error) {
+	if len(enrollment.Config) > 0 {
+		overlaid, err := cfg.Overlay(enrollment.Config)
+		if err != nil {
+			return nil, fmt.Errorf("apply enrolled config: %v", err)
+		}
+		cfg = overlaid
+	} else {
+		copied := *cfg
+		cfg = &copied
+	}
+	if cfg.Push.Token == "" {
+		cfg.Push.Token = enrollment.Token
+	}
+	if cfg.Commands.Token == "" {
+		cfg.Commands.Token = enrollment.Token
+	}
+	return cfg, nil
+}
+
+// enroll 向设备管理服务器注册本机，保存注册结果并应用下发的配置
+func enroll(ctx context.Context) error {
+	deviceCfg := config.Current().Device
+	id, err := deviceID()
+	if err != nil {
+		return fmt.Errorf("get device ID: %v", err)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	registration := device.Registration{DeviceID: id, Hostname: hostname, OS: runtime.GOOS, Arch: runtime.GOARCH}
+	if commandCfg := config.Current().Commands; len(commandCfg.PublicKeys) > 0 {
+		key, err := commandKey(commandCfg)
+		if err != nil {
+			return err
+		}
+		registration.CommandKey = device.PublicKey(key)
+	}
+	enrollment, err := device.Enroll(ctx, deviceCfg, registration)
+	if err != nil {
+		return err
+	}
+	path, err := device.StatePath(deviceCfg)
+	if err != nil {
+		return err
+	}
+	if err := enrollment.Save(path); err != nil {
+		return err
+	}
+	log.Printf("Enrolled device %s with %s", id, deviceCfg.EnrollURL)
+	cfg, err := applyEnrollment(config.Current(), enrollment)
+	if err != nil {
+		return err
+	}
+	config.Set(cfg)
+	return nil
+}
+
+// ensureEnrolled 还没有注册或设备ID已变化（例如更换了盐）时重新注册
+func ensureEnrolled(ctx context.Context) error {
+	path, err := device.StatePath(config.Current().Device)
+	if err != nil {
+		return err
+	}
+	if enrollment, err := device.LoadEnrollment(path); err == nil {
+		if id, err := deviceID(); err == nil && id == enrollment.DeviceID {
+			return nil
+		}
+	}
+	return enroll(ctx)
+}
+
+// durationArg 读取 --name=1m30s 形式的时长参数，未指定或格式错误时返回默认值
+func durationArg(name string, fallback time.Duration) time.Duration {
+	if value, ok := argValue(name); ok {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			return d
+		}
+		log.Printf("Invalid %s value %q, using %s", name, value, fallback)
+	}
+	return fallback
+}
+
+// serve 启动REST API服务和可选的gRPC服务，直到parent被取消；监听地址可通过 --listen、--grpc-listen 参数指定
+func serve(parent context.Context) error {
+	// 配置了设备管理服务器时先完成注册，注册失败时使用本地配置继续运行
+	if config.Current().Device.EnrollURL != "" && !config.Current().Offline {
+		if err := ensureEnrolled(parent); err != nil {
+			log.Printf("Error enrolling device: %v", err)
+		}
+	}
+
+	cfg := config.Current().Server
+	listen, grpcListen := cfg.Listen, cfg.GRPCListen
+	if value, ok := argValue("--listen"); ok {
+		listen = value
+	}
+	if value, ok := argValue("--grpc-listen"); ok {
+		grpcListen = value
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	srv := server.New(collectSystemInfo, time.Duration(cfg.CacheTTL)*time.Second)
+	srv.SetWiFiReader(metricsWiFi)
+	collect.SetReporter(srv.ReportCollector)
+	defer collect.SetReporter(nil)
+	tlsConfig, err := tlsconfig.Server(cfg.TLS)
+	if err != nil {
+		return err
+	}
+	srv.SetTLSConfig(tlsConfig)
+	srv.SetTokens(cfg.Tokens)
+	srv.SetAllowedOrigins(cfg.AllowedOrigins)
+	if len(cfg.Tokens) == 0 && !isLoopback(listen) {
+		log.Printf("Warning: server tokens are not configured, API requests on %s are not authenticated", listen)
+	}
+	errCh := make(chan error, 2)
+	servers := 1
+	if grpcListen != "" {
+		log.Printf("Serving gRPC on %s...", grpcListen)
+		go func() { errCh <- srv.ServeGRPC(ctx, grpcListen) }()
+		servers++
+	}
+	restartCh := make(chan struct{}, 1)
+	restart := func() {
+		select {
+		case restartCh <- struct{}{}:
+		default:
+		}
+	}
+	tasks, err := startTasks(ctx, srv, restart)
+	if err != nil {
+		return err
+	}
+	go srv.RefreshCache(ctx)
+	reloader := &reloader{ctx: ctx, srv: srv, restart: restart, tasks: tasks}
+	defer reloader.stop()
+	srv.SetReloader(reloader.reload)
+	go reloader.watch(ctx)
+	log.Printf("Serving API on %s, press Ctrl+C to stop...", listen)
+	go func() { errCh <- srv.ListenAndServe(ctx, listen) }()
+
+	// 任一服务启动失败时停止另一个；自动更新替换程序后停止所有服务，等待释放监听端口后重新启动
+	select {
+	case err = <-errCh:
+		cancel()
+		return err
+	case <-restartCh:
+		cancel()
+		for ; servers > 0; servers-- {
+			<-errCh
+		}
+		return update.ErrRestart
+	}
+}
+
+// taskGroup 表示serve模式下按配置启动的后台任务，重新加载配置时全部停止后按新配置重新启动
+type taskGroup struct {
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	cleanups []func() // 任务停止后依次执行，关闭数据库和连接
+}
+
+// run 在后台运行fn，stop时等待其返回
+func (g *taskGroup) run(fn func()) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		fn()
+	}()
+}
+
+// onStop 添加任务停止后执行的清理，按添加的相反顺序执行
+func (g *taskGroup) onStop(fn func()) {
+	g.cleanups = append(g.cleanups, fn)
+}
+
+// stop 取消所有后台任务，等待其返回后执行清理
+func (g *taskGroup) stop() {
+	g.cancel()
+	g.wg.Wait()
+	for i := len(g.cleanups) - 1; i >= 0; i-- {
+		g.cleanups[i]()
+	}
+	g.cleanups = nil
+}
+
+// startTasks 按当前配置启动推送、上传、历史记录、通知、syslog、命令轮询、邮件报告、MQTT发布和自动更新等后台任务，
+// 启动失败时停止已经启动的任务并返回错误，返回的任务组总是可以停止；自动更新替换程序后调用restart
+func startTasks(parent context.Context, srv *server.Server, restart func()) (*taskGroup, error) {
+	ctx, cancel := context.WithCancel(parent)
+	g := &taskGroup{cancel: cancel}
+	if err := g.start(ctx, srv, restart); err != nil {
+		g.stop()
+		return g, err
+	}
+	return g, nil
+}
+
+// start 启动配置中启用的后台任务，直到ctx取消
+func (g *taskGroup) start(ctx context.Context, srv *server.Server, restart func()) error {
+	if push := config.Current().Push; push.URL != "" && push.Interval > 0 {
+		pusher, err := sink.NewPusher(push)
+		if err != nil {
+			return err
+		}
+		srv.SetQueueDepth(pusher.Pending)
+		g.onStop(func() { srv.SetQueueDepth(nil) })
+		log.Printf("Pushing snapshots to %s every %ds...", push.URL, push.Interval)
+		g.run(func() {
+			srv.RunPeriodic(ctx, time.Duration(push.Interval)*time.Second, func(info model.SystemInfo, collectedAt time.Time) {
+				err := pusher.Push(ctx, info, collectedAt)
+				srv.ReportTask("push", err)
+				if err != nil {
+					log.Printf("Error pushing snapshot: %v", err)
+				}
+			})
+		})
+	}
+	if upload := config.Current().Upload; upload.Bucket != "" && upload.Interval > 0 && !config.Current().Offline {
+		uploader, err := sink.NewUploader(upload)
+		if err != nil {
+			return err
+		}
+		log.Printf("Uploading snapshots to %s bucket %s every %ds...", upload.Provider, upload.Bucket, upload.Interval)
+		g.run(func() {
+			srv.RunPeriodic(ctx, time.Duration(upload.Interval)*time.Second, func(info model.SystemInfo, collectedAt time.Time) {
+				_, err := uploader.Upload(ctx, info, collectedAt)
+				srv.ReportTask("upload", err)
+				if err != nil {
+					log.Printf("Error uploading snapshot: %v", err)
+				}
+			})
+		})
+	}
+	if historyCfg := config.Current().History; historyCfg.Enabled {
+		db, err := openHistoryDB()
+		if err != nil {
+			return err
+		}
+		srv.SetHistory(db)
+		g.onStop(func() {
+			srv.SetHistory(nil)
+			db.Close()
+		})
+		log.Printf("Recording history to the local database...")
+		startHistory(ctx, g, srv, db, historyCfg)
+	}
+	if notifyCfg := config.Current().Notify; len(notifyCfg.Channels) > 0 && notifyCfg.Interval > 0 && !config.Current().Offline {
+		notifier, err := sink.NewNotifier(notifyCfg)
+		if err != nil {
+			return err
+		}
+		log.Printf("Checking alerts every %ds...", notifyCfg.Interval)
+		g.run(func() {
+			srv.RunPeriodic(ctx, time.Duration(notifyCfg.Interval)*time.Second, func(info model.SystemInfo, collectedAt time.Time) {
+				alerts := notifier.NewAlerts(sink.DetectAlerts(info, notifyCfg, collectedAt))
+				if len(alerts) == 0 {
+					return
+				}
+				err := notifier.Notify(ctx, alerts)
+				srv.ReportTask("notify", err)
+				if err != nil {
+					log.Printf("Error sending notifications: %v", err)
+				}
+			})
+		})
+	}
+	if syslogCfg := config.Current().Syslog; syslogCfg.Address != "" && syslogCfg.Interval > 0 && !config.Current().Offline {
+		forwarder, err := sink.NewSyslogForwarder(syslogCfg)
+		if err != nil {
+			return err
+		}
+		g.onStop(func() { forwarder.Close() })
+		log.Printf("Forwarding to syslog server %s every %ds...", syslogCfg.Address, syslogCfg.Interval)
+		g.run(func() {
+			srv.RunPeriodic(ctx, time.Duration(syslogCfg.Interval)*time.Second, func(info model.SystemInfo, collectedAt time.Time) {
+				alerts := forwarder.NewAlerts(sink.DetectAlerts(info, config.Current().Notify, collectedAt))
+				err := forwarder.Forward(info, collectedAt, alerts)
+				srv.ReportTask("syslog", err)
+				if err != nil {
+					log.Printf("Error forwarding to syslog: %v", err)
+				}
+			})
+		})
+	}
+	if commandCfg := config.Current().Commands; len(commandCfg.PublicKeys) > 0 {
+		commands, err := newCommands(srv)
+		if err != nil {
+			return fmt.Errorf("enable commands: %v", err)
+		}
+		srv.SetCommands(commands)
+		g.onStop(func() { srv.SetCommands(nil) })
+		if commandCfg.PollURL != "" && !config.Current().Offline {
+			pollTLS, err := tlsconfig.Client(commandCfg.TLS)
+			if err != nil {
+				return err
+			}
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.TLSClientConfig = pollTLS
+			log.Printf("Polling commands from %s...", commandCfg.PollURL)
+			g.run(func() { commands.Poll(ctx, commandCfg.PollURL, commandCfg.Token, &http.Client{Transport: transport}) })
+		}
+	}
+	if emailCfg := config.Current().Email; emailCfg.Host != "" && emailCfg.Interval > 0 && !config.Current().Offline {
+		mailer, err := sink.NewMailer(emailCfg)
+		if err != nil {
+			return err
+		}
+		statePath, err := sink.EmailStatePath("report")
+		if err != nil {
+			return err
+		}
+		log.Printf("Emailing reports to %s every %ds...", strings.Join(emailCfg.To, ", "), emailCfg.Interval)
+		g.run(func() {
+			sink.RunSchedule(ctx, statePath, time.Duration(emailCfg.Interval)*time.Second, func() error {
+				info, collectedAt, err := srv.Cached(ctx)
+				if err == nil {
+					alerts := sink.DetectAlerts(info, config.Current().Notify, collectedAt)
+					err = sendReport(ctx, mailer, emailCfg.Format, info.Hostname, report.Device(info, collectedAt, alerts))
+				}
+				srv.ReportTask("email", err)
+				if err != nil {
+					log.Printf("Error emailing report: %v", err)
+				}
+				return err
+			})
+		})
+	}
+	if mqttCfg := config.Current().MQTT; mqttCfg.Broker != "" && !config.Current().Offline {
+		publisher, err := sink.NewMQTTPublisher(mqttCfg)
+		if err != nil {
+			return err
+		}
+		g.onStop(publisher.Close)
+		log.Printf("Publishing to MQTT broker %s...", mqttCfg.Broker)
+		startMQTT(ctx, g, srv, publisher, mqttCfg)
+	}
+	if updateCfg := config.Current().Update; updateCfg.URL != "" && updateCfg.Interval > 0 && !config.Current().Offline {
+		updater, err := update.New(updateCfg)
+		if err != nil {
+			return err
+		}
+		log.Printf("Checking for updates every %ds...", updateCfg.Interval)
+		g.run(func() { autoUpdate(ctx, srv, updater, time.Duration(updateCfg.Interval)*time.Second, restart) })
+	}
+	return nil
+}
+
+// autoUpdate 每隔interval检查一次更新，有新版本时替换程序并调用restart，直到ctx取消
+// 第一次检查在一个间隔内的随机时刻，避免大量设备同时启动后同时下载
+func autoUpdate(ctx context.Context, srv *server.Server, updater *update.Updater, interval time.Duration, restart func()) {
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(interval))))
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return
+		}
+		manifest, err := updater.Check(ctx)
+		if err == nil && update.Newer(manifest.Version, version) {
+			if err = applyUpdate(ctx, updater, manifest); err == nil {
+				srv.ReportTask("update", nil)
+				restart()
+				return
+			}
+		}
+		srv.ReportTask("update", err)
+		if err != nil {
+			log.Printf("Error checking for updates: %v", err)
+		}
+		timer.Reset(interval)
+	}
+}
+
+// runUpdate 检查更新通道，有新版本时下载、校验签名和校验和后替换程序，并重新启动已安装的服务，需要对程序文件有写权限
+// --check 只检查不更新；--force 在没有新版本或当前为开发版本时也重新安装通道中的版本
+func runUpdate(ctx context.Context) error {
+	updater, err := update.New(config.Current().Update)
+	if err != nil {
+		return err
+	}
+	manifest, err := updater.Check(ctx)
+	if err != nil {
+		return err
+	}
+	if !update.Newer(manifest.Version, version) && !hasArg("--force") {
+		log.Printf("No newer version available (current %s, latest %s), use --force to reinstall", version, manifest.Version)
+		return nil
+	}
+	if hasArg("--check") {
+		log.Printf("Version %s is available (current %s)", manifest.Version, version)
+		return nil
+	}
+	if err := applyUpdate(ctx, updater, manifest); err != nil {
+		return err
+	}
+	var restarted bool
+	if runtime.GOOS == "windows" {
+		restarted, err = windows.RestartService()
+	} else {
+		restarted, err = darwin.RestartService()
+	}
+	if err != nil {
+		return fmt.Errorf("restart service: %v", err)
+	}
+	if restarted {
+		log.Printf("Service restarted")
+	}
+	return nil
+}
+
+// applyUpdate 下载清单中当前平台的程序并替换当前程序
+func applyUpdate(ctx context.Context, updater *update.Updater, manifest update.Manifest) error {
+	asset, ok := manifest.Asset()
+	if !ok {
+		return fmt.Errorf("version %s has no release for %s-%s", manifest.Version, runtime.GOOS, runtime.GOARCH)
+	}
+	log.Printf("Updating from %s to %s...", version, manifest.Version)
+	if err := updater.Apply(ctx, asset); err != nil {
+		return err
+	}
+	log.Printf("Updated to %s", manifest.Version)
+	return nil
+}
+
+// reloader 在serve模式下重新加载配置并重启后台任务，由配置文件修改、SIGHUP或 POST /v1/reload 触发
+type reloader struct {
+	ctx     context.Context
+	srv     *server.Server
+	restart func()
+
+	mu    sync.Mutex
+	tasks *taskGroup
+}
+
+// reload 重新加载配置并按新配置重启后台任务，新配置无效或无法启动时恢复原来的配置
+// 采集项、探测目标等每次采集时读取当前配置，不需要重启；监听地址、TLS和缓存时长需要重启服务后才生效
+func (r *reloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	previous := config.Current()
+	if err := reloadConfig(); err != nil {
+		return err
+	}
+	// 访问令牌重新加载后立即生效，其他服务设置需要重启服务
+	previousServer, currentServer := previous.Server, config.Current().Server
+	previousServer.Tokens, currentServer.Tokens = nil, nil
+	if !reflect.DeepEqual(previousServer, currentServer) {
+		log.Printf("Server settings changed, restart the service to apply them")
+	}
+
+	r.tasks.stop()
+	tasks, err := startTasks(r.ctx, r.srv, r.restart)
+	if err != nil {
+		config.Set(previous)
+		r.tasks, _ = startTasks(r.ctx, r.srv, r.restart)
+		return fmt.Errorf("apply reloaded config: %v", err)
+	}
+	r.tasks = tasks
+	r.srv.SetTokens(config.Current().Server.Tokens)
+	r.srv.SetAllowedOrigins(config.Current().Server.AllowedOrigins)
+	log.Printf("Config reloaded")
+	return nil
+}
+
+// stop 停止当前的后台任务
+func (r *reloader) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tasks.stop()
+}
+
+// watch 收到SIGHUP或配置文件修改后重新加载配置，直到ctx被取消
+// 配置文件按修改时间定期检查，编辑器保存过程中读到不完整的文件时保留当前配置，保存完成后再次加载
+func (r *reloader) watch(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	path, _, err := configPath()
+	if err != nil {
+		log.Printf("Error watching config file: %v", err)
+	}
+	modTime := fileModTime(path)
+	ticker := time.NewTicker(configWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-hup:
+			log.Printf("Received SIGHUP, reloading config...")
+		case <-ticker.C:
+			if path == "" {
+				continue
+			}
+			t := fileModTime(path)
+			if t.Equal(modTime) {
+				continue
+			}
+			modTime = t
+			log.Printf("Config file %s changed, reloading...", path)
+		case <-ctx.Done():
+			return
+		}
+		if err := r.reload(); err != nil {
+			log.Printf("Error reloading config: %v", err)
+		}
+	}
+}
+
+// isLoopback 判断监听地址是否只监听本机回环地址
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// fileModTime 返回文件的修改时间，文件不存在时返回零值
+func fileModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// aggregate 启动汇总服务，直到ctx被取消，监听地址可通过 --listen 参数覆盖；
+// 参数中包含 --email 时只发送一次设备清单汇总邮件，用于测试邮件配置或由计划任务调用；
+// --unenroll 设备ID 删除设备绑定的设备令牌或客户端证书，之后设备可以重新注册
+func aggregate(ctx context.Context) error {
+	cfg := config.Current().Aggregate
+	listen := cfg.Listen
+	if value, ok := argValue("--listen"); ok {
+		listen = value
+	}
+	path := cfg.DBPath
+	if path == "" {
+		var err error
+		if path, err = aggregator.DefaultDBPath(); err != nil {
+			return err
+		}
+	}
+	store, err := aggregator.OpenStore(path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if deviceID, ok := argValue("--unenroll"); ok {
+		if err := store.Unenroll(deviceID); errors.Is(err, aggregator.ErrNotFound) {
+			return fmt.Errorf("device %s is not enrolled", deviceID)
+		} else if err != nil {
+			return err
+		}
+		log.Printf("Unenrolled device %s", deviceID)
+		return nil
+	}
+
+	emailCfg := config.Current().Email
+	if hasArg("--email") {
+		mailer, err := sink.NewMailer(emailCfg)
+		if err != nil {
+			return err
+		}
+		return emailFleet(ctx, mailer, store, emailCfg)
+	}
+
+	srv := aggregator.NewServer(store, cfg.Tokens)
+	tlsConfig, err := tlsconfig.Server(cfg.TLS)
+	if err != nil {
+		return err
+	}
+	srv.SetTLSConfig(tlsConfig)
+	if len(cfg.Tokens) == 0 {
+		log.Printf("Warning: aggregate tokens are not configured, only client certificates or local access protect the aggregator")
+	}
+	go srv.RunCompaction(ctx, time.Duration(cfg.Retention)*24*time.Hour)
+	if emailCfg.Host != "" && emailCfg.Interval > 0 {
+		mailer, err := sink.NewMailer(emailCfg)
+		if err != nil {
+			return err
+		}
+		statePath, err := sink.EmailStatePath("fleet")
+		if err != nil {
+			return err
+		}
+		log.Printf("Emailing fleet summaries to %s every %ds...", strings.Join(emailCfg.To, ", "), emailCfg.Interval)
+		go sink.RunSchedule(ctx, statePath, time.Duration(emailCfg.Interval)*time.Second, func() error {
+			err := emailFleet(ctx, mailer, store, emailCfg)
+			if err != nil {
+				log.Printf("Error emailing fleet summary: %v", err)
+			}
+			return err
+		})
+	}
+	log.Printf("Serving aggregator on %s with database %s, press Ctrl+C to stop...", listen, path)
+	return srv.ListenAndServe(ctx, listen)
+}
+
+// newCommands 创建按需采集命令的处理器，注册可以由服务台远程触发的采集项和探测，
+// 无法获取设备ID或本机签名私钥时返回错误，不启用命令
+func newCommands(srv *server.Server) (*server.Commands, error) {
+	cfg := config.Current()
+	id, err := deviceID()
+	if err != nil {
+		return nil, fmt.Errorf("get device ID: %v", err)
+	}
+	key, err := commandKey(cfg.Commands)
+	if err != nil {
+		return nil, err
+	}
+	commands, err := server.NewCommands(cfg.Commands.PublicKeys, key, id, time.Duration(cfg.Commands.MaxAge)*time.Second, srv.CommandLog())
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Signing command results with device key %s", device.PublicKey(key))
+	commands.Register("snapshot", func(ctx context.Context, args json.RawMessage) (interface{}, error) {
+		info, _, err := srv.Snapshot(true)
+		return info, err
+	})
+	commands.Register("network", func(ctx context.Context, args json.RawMessage) (interface{}, error) {
+		return collectNetworkInfo()
+	})
+	commands.Register("wifi", func(ctx context.Context, args json.RawMessage) (interface{}, error) {
+		return currentWiFi()
+	})
+	commands.Register("scan-wifi", func(ctx context.Context, args json.RawMessage) (interface{}, error) {
+		var info model.SystemInfo
+		wifi, err := currentWiFi()
+		if err != nil {
+			return nil, err
+		}
+		info.Network.WiFi = wifi
+		if err := scanWiFi(&info); err != nil {
+			return nil, err
+		}
+		return info.Network.WiFiScan, nil
+	})
+	commands.Register("latency", func(ctx context.Context, args json.RawMessage) (interface{}, error) {
+		var latency model.LatencyInfo
+		err := analysis.MeasureLatency(&latency, cfg.Latency.Groups)
+		return latency, err
+	})
+	commands.Register("probe", server.ProbeCommand)
+	commands.Register("endpoints", func(ctx context.Context, args json.RawMessage) (interface{}, error) {
+		return analysis.CheckEndpoints(cfg.Endpoint), nil
+	})
+	commands.Register("saas", func(ctx context.Context, args json.RawMessage) (interface{}, error) {
+		return analysis.CheckSaaS(cfg.SaaS, cfg.Endpoint.InspectionIssuers), nil
+	})
+	commands.Register("ports", func(ctx context.Context, args json.RawMessage) (interface{}, error) {
+		return analysis.CheckPorts(cfg.Ports), nil
+	})
+	return commands, nil
+}
+
+// commandKey 读取本机签名命令执行结果的私钥，第一次使用时生成
+func commandKey(cfg config.CommandConfig) (ed25519.PrivateKey, error) {
+	path, err := device.KeyPath(cfg)
+	if err != nil {
+		return nil, err
+	}
+	key, err := device.LoadOrCreateKey(path)
+	if err != nil {
+		return nil, fmt.Errorf("load command key: %v", err)
+	}
+	return key, nil
+}
+
+// collectNetworkInfo 只采集网络信息并计算网络健康评分
+func collectNetworkInfo() (model.NetworkInfo, error) {
+	var info model.SystemInfo
+	var err error
+	if runtime.GOOS == "darwin" {
+		err = darwin.GetNetworkInfo(&info)
+	} else if runtime.GOOS == "windows" {
+		info.Network, err = windows.GetNetworkInfo()
+	} else {
+		return info.Network, fmt.Errorf("Unsupported OS: %s", runtime.GOOS)
+	}
+	if err != nil {
+		return info.Network, err
+	}
+	info.Network.HealthScore = analysis.ScoreNetworkHealth(info.Network)
+	return info.Network, nil
+}
+
+// pushSnapshot 将快照推送到远程收集服务器，发送失败的快照已暂存，下次推送时补发
+func pushSnapshot(info model.SystemInfo, collectedAt time.Time) {
+	pusher, err := sink.NewPusher(config.Current().Push)
+	if err != nil {
+		log.Printf("Error creating pusher: %v", err)
+		return
+	}
+	if err := pusher.Push(context.Background(), info, collectedAt); err != nil {
+		log.Printf("Error pushing snapshot: %v", err)
+		return
+	}
+	log.Printf("Snapshot pushed to %s", config.Current().Push.URL)
+}
+
+// uploadSnapshot 将快照上传到对象存储
+func uploadSnapshot(info model.SystemInfo, collectedAt time.Time) {
+	uploader, err := sink.NewUploader(config.Current().Upload)
+	if err != nil {
+		log.Printf("Error creating uploader: %v", err)
+		return
+	}
+	key, err := uploader.Upload(context.Background(), info, collectedAt)
+	if err != nil {
+		log.Printf("Error uploading snapshot: %v", err)
+		return
+	}
+	log.Printf("Snapshot uploaded to %s", key)
+}
+
+// emailReport 将本机的报告通过邮件发送
+func emailReport(info model.SystemInfo, collectedAt time.Time) {
+	emailCfg := config.Current().Email
+	mailer, err := sink.NewMailer(emailCfg)
+	if err != nil {
+		log.Printf("Error creating mailer: %v", err)
+		return
+	}
+	alerts := sink.DetectAlerts(info, config.Current().Notify, collectedAt)
+	if err := sendReport(context.Background(), mailer, emailCfg.Format, info.Hostname, report.Device(info, collectedAt, alerts)); err != nil {
+		log.Printf("Error emailing report: %v", err)
+		return
+	}
+	log.Printf("Report emailed to %s", strings.Join(emailCfg.To, ", "))
+}
 
-	"github.com/AsterZephyr/SysSpector/internal/darwin"
-	"github.com/AsterZephyr/SysSpector/internal/windows"
-	"github.com/AsterZephyr/SysSpector/pkg/model"
-)
+// emailFleet 将汇总数据库中的设备清单汇总通过邮件发送
+func emailFleet(ctx context.Context, mailer *sink.Mailer, store *aggregator.Store, cfg config.EmailConfig) error {
+	devices, err := store.Devices(aggregator.Filter{})
+	if err != nil {
+		return err
+	}
+	return sendReport(ctx, mailer, cfg.Format, "设备清单", report.Fleet(devices, time.Now(), cfg.StaleDays))
+}
 
-func main() {
-	// 设置日志输出到标准错误
-	log.SetOutput(os.Stderr)
-	log.Println("Starting system information collection...")
+// sendReport 将报告渲染为Markdown文本，格式为html时同时渲染HTML，以"主题前缀 title 日期"为主题发送
+func sendReport(ctx context.Context, mailer *sink.Mailer, format, title string, doc report.Document) error {
+	var html string
+	if format == config.ReportHTML {
+		var err error
+		if html, err = doc.HTML(); err != nil {
+			return err
+		}
+	}
+	return mailer.Send(ctx, mailer.Subject(title, time.Now()), doc.Markdown(), html)
+}
 
-	var sysInfo model.SystemInfo
-	var err error
+// publishSnapshot 将快照发布到MQTT服务器
+func publishSnapshot(info model.SystemInfo, collectedAt time.Time) {
+	publisher, err := sink.NewMQTTPublisher(config.Current().MQTT)
+	if err != nil {
+		log.Printf("Error connecting to MQTT broker: %v", err)
+		return
+	}
+	defer publisher.Close()
+	if err := publisher.PublishSnapshot(info, collectedAt); err != nil {
+		log.Printf("Error publishing snapshot: %v", err)
+		return
+	}
+	log.Printf("Snapshot published to %s", config.Current().MQTT.Broker)
+}
 
-	if runtime.GOOS == "darwin" {
-		sysInfo, err = darwin.GetSystemInfo()
+// startMQTT 按配置的间隔在后台发布快照和动态指标，直到ctx取消
+func startMQTT(ctx context.Context, g *taskGroup, srv *server.Server, publisher *sink.MQTTPublisher, cfg config.MQTTConfig) {
+	if cfg.SnapshotInterval > 0 {
+		g.run(func() {
+			srv.RunPeriodic(ctx, time.Duration(cfg.SnapshotInterval)*time.Second, func(info model.SystemInfo, collectedAt time.Time) {
+				err := publisher.PublishSnapshot(info, collectedAt)
+				srv.ReportTask("mqtt", err)
+				if err != nil {
+					log.Printf("Error publishing snapshot: %v", err)
+				}
+			})
+		})
+	}
+	if cfg.MetricsInterval > 0 {
+		g.run(func() {
+			server.RunMetrics(ctx, time.Duration(cfg.MetricsInterval)*time.Second, metricsWiFi, func(metrics server.Metrics) {
+				err := publisher.PublishMetrics(metrics)
+				srv.ReportTask("mqtt-metrics", err)
+				if err != nil {
+					log.Printf("Error publishing metrics: %v", err)
+				}
+			})
+		})
+	}
+}
+
+// diskSamples 返回快照中各分区的磁盘使用采样
+func diskSamples(info model.SystemInfo, collectedAt time.Time) []history.DiskSample {
+	var samples []history.DiskSample
+	for _, partition := range info.DiskUsage {
+		samples = append(samples, history.DiskSample{
+			Time:       collectedAt,
+			MountPoint: partition.MountPoint,
+			Total:      partition.Total,
+			Used:       partition.Used,
+		})
+	}
+	return samples
+}
+
+// openHistoryDB 打开配置的历史数据库
+func openHistoryDB() (*history.DB, error) {
+	path := config.Current().History.Path
+	if path == "" {
+		var err error
+		if path, err = history.DefaultDBPath(); err != nil {
+			return nil, err
+		}
+	}
+	return history.OpenDB(path)
+}
+
+// startHistory 在后台按配置的间隔保存快照、动态指标和磁盘使用采样，并每小时降采样和清理一次过期数据，直到ctx取消
+func startHistory(ctx context.Context, g *taskGroup, srv *server.Server, db *history.DB, cfg config.HistoryConfig) {
+	g.run(func() {
+		srv.RunPeriodic(ctx, time.Duration(cfg.SnapshotInterval)*time.Second, func(info model.SystemInfo, collectedAt time.Time) {
+			err := db.AddSnapshot(info, collectedAt)
+			srv.ReportTask("history", err)
+			if err != nil {
+				log.Printf("Error saving snapshot: %v", err)
+			}
+		})
+	})
+	g.run(func() {
+		srv.RunPeriodic(ctx, time.Duration(cfg.DiskInterval)*time.Second, func(info model.SystemInfo, collectedAt time.Time) {
+			err := db.AddDiskSamples(diskSamples(info, collectedAt)...)
+			srv.ReportTask("history-disk", err)
+			if err != nil {
+				log.Printf("Error saving disk samples: %v", err)
+			}
+		})
+	})
+	g.run(func() {
+		server.RunMetrics(ctx, time.Duration(cfg.MetricsInterval)*time.Second, metricsWiFi, func(metrics server.Metrics) {
+			err := db.AddPoint(history.Point{
+				Time:          metrics.Time,
+				CPUPercent:    metrics.CPUPercent,
+				MemoryPercent: metrics.MemoryPercent,
+				MemoryUsed:    metrics.MemoryUsed,
+				RxRate:        metrics.RxRate,
+				TxRate:        metrics.TxRate,
+				RSSI:          metrics.RSSI,
+			})
+			srv.ReportTask("history-metrics", err)
+			if err != nil {
+				log.Printf("Error saving metrics: %v", err)
+			}
+		})
+	})
+	g.run(func() {
+		retention := history.Retention{
+			Snapshots: time.Duration(cfg.SnapshotRetention) * 24 * time.Hour,
+			Raw:       time.Duration(cfg.RawRetention) * time.Hour,
+			Metrics:   time.Duration(cfg.MetricsRetention) * 24 * time.Hour,
+		}
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			if err := db.Compact(time.Now(), retention); err != nil {
+				log.Printf("Error compacting history: %v", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// queryHistory 打印历史数据库中的动态指标或快照列表
+// 时间范围默认为最近 --since（默认24h），可通过 --from、--to 参数（RFC 3339格式）指定；
+// --snapshots 列出保存的快照，--at 以JSON格式输出指定时间之前最近的快照，--json 以JSON格式输出
+func queryHistory() error {
+	db, err := openHistoryDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	to := time.Now()
+	from := to.Add(-durationArg("--since", 24*time.Hour))
+	for _, param := range []struct {
+		name  string
+		value *time.Time
+	}{{"--from", &from}, {"--to", &to}} {
+		if text, ok := argValue(param.name); ok {
+			if *param.value, err = time.Parse(time.RFC3339, text); err != nil {
+				return fmt.Errorf("invalid %s: %v", param.name, err)
+			}
+		}
+	}
+
+	if text, ok := argValue("--at"); ok {
+		at, err := time.Parse(time.RFC3339, text)
 		if err != nil {
-			fmt.Printf("Error getting system info: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("invalid --at: %v", err)
 		}
-	} else if runtime.GOOS == "windows" {
-		sysInfo, err = windows.GetAllSystemInfo()
+		info, _, err := db.Snapshot(at)
 		if err != nil {
-			fmt.Printf("Error getting system info: %v\n", err)
-			os.Exit(1)
+			return err
 		}
-	} else {
-		fmt.Printf("Unsupported OS: %s\n", runtime.GOOS)
-		os.Exit(1)
+		return printJSON(info)
 	}
 
-	// 以格式化的方式打印系统信息
-	printSystemInfo(sysInfo)
+	if hasArg("--snapshots") {
+		records, err := db.Snapshots(from, to)
+		if err != nil {
+			return err
+		}
+		if hasArg("--json") {
+			return printJSON(records)
+		}
+		fmt.Printf("%-20s %s\n", "采集时间", "网络健康评分")
+		for _, record := range records {
+			fmt.Printf("%-20s %d\n", record.Time.Format("2006-01-02 15:04:05"), record.HealthScore)
+		}
+		return nil
+	}
 
-	// 如果命令行参数中包含 --save，则将系统信息保存到文件
-	if len(os.Args) > 1 && os.Args[1] == "--save" {
-		outputFile := "sysinfo.txt"
-		if len(os.Args) > 2 {
-			// 如果提供了文件名，则使用提供的文件名
-			outputFile = os.Args[2]
+	points, err := db.Points(from, to)
+	if err != nil {
+		return err
+	}
+	if hasArg("--json") {
+		return printJSON(points)
+	}
+	fmt.Printf("%-20s %-8s %-8s %-8s %-14s %-14s %s\n", "时间", "粒度", "CPU", "内存", "接收", "发送", "WiFi信号")
+	for _, point := range points {
+		resolution := "原始"
+		if point.Resolution == history.ResolutionHour {
+			resolution = "每小时"
+		}
+		rssi := "-"
+		if point.RSSI != 0 {
+			rssi = fmt.Sprintf("%d dBm", point.RSSI)
 		}
+		fmt.Printf("%-20s %-8s %-8s %-8s %-14s %-14s %s\n", point.Time.Format("2006-01-02 15:04:05"), resolution,
+			fmt.Sprintf("%.1f%%", point.CPUPercent), fmt.Sprintf("%.1f%%", point.MemoryPercent),
+			fmt.Sprintf("%.2f KB/s", point.RxRate/1024), fmt.Sprintf("%.2f KB/s", point.TxRate/1024), rssi)
+	}
+	return nil
+}
 
-		// 格式化输出内容
-		output := formatSystemInfo(sysInfo)
+// printJSON 以缩进的JSON格式输出到标准输出
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
 
-		// 写入文件
-		err = os.WriteFile(outputFile, []byte(output), 0644)
+// manageService 安装、卸载、启动或停止系统服务，需要管理员权限
+// 安装的服务以 serve 模式运行，使用 --config 参数指定的配置文件，未指定时使用系统级的配置文件路径
+func manageService(action string) error {
+	switch action {
+	case "install":
+		executable, err := os.Executable()
 		if err != nil {
-			log.Fatalf("Error writing to file %s: %v", outputFile, err)
+			return err
 		}
-		log.Printf("System information saved to %s", outputFile)
+		if executable, err = filepath.EvalSymlinks(executable); err != nil {
+			return err
+		}
+		configPath, ok := argValue("--config")
+		if !ok {
+			configPath = darwin.ServiceConfigPath
+			if runtime.GOOS == "windows" {
+				configPath = windows.ServiceConfigPath()
+			}
+		}
+		if configPath, err = filepath.Abs(configPath); err != nil {
+			return err
+		}
+		args := []string{"serve", "--config", configPath}
+		if runtime.GOOS == "windows" {
+			// 服务以SYSTEM运行并热加载配置文件，配置文件只能由管理员修改
+			defaults, err := json.MarshalIndent(config.Default(), "", "  ")
+			if err != nil {
+				return err
+			}
+			if err := windows.SecureConfigFile(configPath, defaults); err != nil {
+				return err
+			}
+			err = windows.InstallService(executable, args)
+		} else {
+			err = darwin.InstallService(executable, args)
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("Service installed and started, using config %s", configPath)
+		return nil
+	case "uninstall":
+		if runtime.GOOS == "windows" {
+			return windows.UninstallService()
+		}
+		return darwin.UninstallService()
+	case "start":
+		if runtime.GOOS == "windows" {
+			return windows.StartService()
+		}
+		return darwin.StartService()
+	case "stop":
+		if runtime.GOOS == "windows" {
+			return windows.StopService()
+		}
+		return darwin.StopService()
+	default:
+		return fmt.Errorf("unknown service action %q, expected install, uninstall, start or stop", action)
 	}
+}
 
-	// 在Windows系统上，程序结束前暂停，等待用户按键
+// metricsWiFi serve模式下动态指标采样使用的WiFi读取，结果缓存一个默认采样间隔，供各个推送任务和连接共用
+var metricsWiFi = server.CacheWiFi(currentWiFi, server.DefaultMetricsInterval)
+
+// currentWiFi 读取当前WiFi连接的信息
+func currentWiFi() (model.WiFiInfo, error) {
 	if runtime.GOOS == "windows" {
-		fmt.Println("\nPress Enter to exit...")
-		reader := bufio.NewReader(os.Stdin)
-		reader.ReadString('\n')
+		return windows.CurrentWiFi(context.Background())
+	}
+	return darwin.CurrentWiFi(context.Background())
+}
+
+// watchLatency 持续监控配置中的延迟探测目标，定期打印滚动窗口内的统计结果
+// 探测间隔、窗口长度和打印间隔可通过 --watch-interval、--watch-window、--watch-report 参数调整
+func watchLatency() {
+	interval := durationArg("--watch-interval", time.Second)
+	window := durationArg("--watch-window", 5*time.Minute)
+	report := durationArg("--watch-report", 10*time.Second)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	monitor := analysis.NewLatencyMonitor(config.Current().Latency.Groups, window)
+	go monitor.Run(ctx, interval)
+	log.Printf("Monitoring latency every %s, press Ctrl+C to stop...", interval)
+
+	ticker := time.NewTicker(report)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			printLatencyWindow(now, window, monitor.Snapshot())
+		}
+	}
+}
+
+// printLatencyWindow 打印滚动窗口内每个目标的百分位数、丢包率和中断记录
+func printLatencyWindow(now time.Time, window time.Duration, latency model.LatencyInfo) {
+	fmt.Printf("\n[%s] 延迟监控（最近 %s）\n", now.Format("15:04:05"), window)
+	for _, target := range latency.Targets {
+		summary := fmt.Sprintf("P50 %.1fms，P95 %.1fms，P99 %.1fms，丢包 %.1f%%",
+			target.P50, target.P95, target.P99, target.PacketLoss)
+		if len(target.Outages) > 0 {
+			summary += fmt.Sprintf("，中断 %d 次", len(target.Outages))
+		}
+		if len(target.Warnings) > 0 {
+			summary += "（" + strings.Join(target.Warnings, "，") + "）"
+		}
+		fmt.Printf("%-20s %-20s %s\n", target.Group+"/"+target.TargetName, target.TargetHost, summary)
+
+		for _, outage := range target.Outages {
+			end := outage.End
+			if end == "" {
+				end = "仍在中断"
+			}
+			fmt.Printf("  %-18s %-20s %s\n", "中断", outage.Start+" - "+end,
+				fmt.Sprintf("%.0f秒，丢失 %d 个数据包", outage.Duration, outage.Lost))
+		}
+	}
+}
+
+// hogThresholds 读取资源占用阈值参数：--hog-cpu（百分比）、--hog-mem（MB）、--hog-net（KB/s）
+func hogThresholds() analysis.HogThresholds {
+	thresholds := analysis.DefaultHogThresholds
+	if value, ok := argValue("--hog-cpu"); ok {
+		if cpu, err := strconv.ParseFloat(value, 64); err == nil {
+			thresholds.CPU = cpu
+		}
+	}
+	if value, ok := argValue("--hog-mem"); ok {
+		if mb, err := strconv.ParseUint(value, 10, 64); err == nil {
+			thresholds.Memory = mb * 1024 * 1024
+		}
+	}
+	if value, ok := argValue("--hog-net"); ok {
+		if kb, err := strconv.ParseUint(value, 10, 64); err == nil {
+			thresholds.Network = kb * 1024
+		}
+	}
+	return thresholds
+}
+
+// scanDisk 分析用户目录的磁盘空间占用
+func scanDisk(info *model.SystemInfo) error {
+	var scan model.DiskScanInfo
+	var err error
+	if runtime.GOOS == "windows" {
+		scan, err = windows.ScanDisk()
+	} else {
+		scan, err = darwin.ScanDisk()
 	}
+	if err != nil {
+		return err
+	}
+	info.DiskScan = scan
+	return nil
+}
+
+// scanWiFi 扫描附近的无线网络
+func scanWiFi(info *model.SystemInfo) error {
+	if runtime.GOOS == "windows" {
+		scan, err := windows.ScanWiFi(context.Background(), info.Network.WiFi)
+		if err != nil {
+			return err
+		}
+		info.Network.WiFiScan = scan
+		return nil
+	}
+	return darwin.ScanWiFi(context.Background(), &info.Network)
+}
+
+// scanSwitch 被动抓取LLDP/CDP通告，获取有线网络所连接的交换机名称、端口和VLAN
+func scanSwitch(info *model.SystemInfo) error {
+	if runtime.GOOS == "windows" {
+		neighbors, err := windows.CaptureNeighbors(context.Background())
+		if err != nil {
+			return err
+		}
+		info.Network.Neighbors = neighbors
+		return nil
+	}
+	return darwin.CaptureNeighbors(context.Background(), &info.Network)
+}
+
+// measureNetworkQuality 测试负载下的响应能力，macOS优先使用系统自带的networkQuality
+func measureNetworkQuality(info *model.SystemInfo) error {
+	if runtime.GOOS == "windows" {
+		info.Network.Quality = analysis.MeasureNetworkQuality(config.Current().Quality)
+		if info.Network.Quality.Error != "" {
+			return errors.New(info.Network.Quality.Error)
+		}
+		return nil
+	}
+	return darwin.MeasureNetworkQuality(context.Background(), &info.Network)
+}
+
+// updateDiskTrend 根据历史数据库中的磁盘使用采样计算系统盘的增长趋势，只读取不写入，
+// 采样由serve模式的历史记录任务按history.disk_interval写入；没有开启history.enabled时不计算
+func updateDiskTrend(info *model.SystemInfo) error {
+	if len(info.DiskUsage) == 0 || !config.Current().History.Enabled {
+		return nil
+	}
+
+	db, err := openHistoryDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	allSamples, err := db.DiskSamples(time.Now().Add(-analysis.TrendWindow))
+	if err != nil {
+		return err
+	}
+
+	// 系统盘：macOS为根目录，Windows为系统驱动器
+	systemMount := "/"
+	if runtime.GOOS == "windows" {
+		systemMount = os.Getenv("SystemDrive")
+		if systemMount == "" {
+			systemMount = "C:"
+		}
+	}
+	for _, partition := range info.DiskUsage {
+		if strings.EqualFold(strings.TrimSuffix(partition.MountPoint, "\\"), systemMount) {
+			info.DiskTrend = analysis.ProjectDiskUsage(allSamples, partition)
+			break
+		}
+	}
+
+	return nil
 }
 
 // printSystemInfo 格式化输出系统信息
@@ -86,10 +1649,32 @@ func printSystemInfo(info model.SystemInfo) {
 	}
 	fmt.Printf("%-20s %-20s %s\n", "序列号", "", info.SerialNumber)
 	fmt.Printf("%-20s %-20s %s\n", "硬件UUID", "", info.UUID)
+	if info.DeviceID != "" {
+		fmt.Printf("%-20s %-20s %s\n", "设备ID", "", info.DeviceID)
+	}
 	fmt.Printf("%-20s %-20s %s\n", "处理器名称", "", info.CPU.Model)
 	fmt.Printf("%-20s %-20s %d\n", "CPU核心数", "", info.CPU.Cores)
 	fmt.Printf("%-20s %-20s %.2f GB\n", "内存", "", float64(info.Memory.Total)/(1024*1024*1024))
 	fmt.Printf("%-20s %-20s %s\n", "内存类型", "", info.Memory.Type)
+	if len(info.Memory.Modules) > 0 {
+		fmt.Printf("%-20s\n", "内存插槽")
+		for _, module := range info.Memory.Modules {
+			if module.Size == 0 {
+				fmt.Printf("  %-18s %-20s %s\n", module.Slot, "", "空")
+				continue
+			}
+			fmt.Printf("  %-18s %-20s %.0f GB %s %dMT/s %s %s\n", module.Slot, "",
+				float64(module.Size)/(1024*1024*1024), module.Type, module.Speed, module.Manufacturer, module.PartNumber)
+		}
+		if info.Memory.Upgradeable {
+			fmt.Printf("%-20s %-20s %s\n", "内存可升级", "", "是")
+		} else {
+			fmt.Printf("%-20s %-20s %s\n", "内存可升级", "", "否")
+		}
+		if info.Memory.Mismatched {
+			fmt.Printf("%-20s %-20s %s\n", "内存条不一致", "", "是（容量、速度或型号不同）")
+		}
+	}
 
 	// 显示硬盘容量
 	var maxDiskSize uint64
@@ -140,6 +1725,102 @@ func printSystemInfo(info model.SystemInfo) {
 		fmt.Printf("%-20s %-20s %s\n", "硬盘容量", "", "未知")
 	}
 
+	// 显示固件信息
+	if info.Firmware.Version != "" {
+		fmt.Printf("%-20s %-20s %s %s\n", "固件版本", "", info.Firmware.Vendor, info.Firmware.Version)
+		if info.Firmware.ReleaseDate != "" {
+			fmt.Printf("%-20s %-20s %s\n", "固件发布日期", "", info.Firmware.ReleaseDate)
+		}
+		if info.Firmware.BootROMVersion != "" && info.Firmware.BootROMVersion != info.Firmware.Version {
+			fmt.Printf("%-20s %-20s %s\n", "Boot ROM版本", "", info.Firmware.BootROMVersion)
+		}
+		if info.Firmware.SMCVersion != "" {
+			fmt.Printf("%-20s %-20s %s\n", "SMC版本", "", info.Firmware.SMCVersion)
+		}
+		if info.Firmware.BridgeFirmware != "" {
+			fmt.Printf("%-20s %-20s %s\n", "iBridge固件版本", "", info.Firmware.BridgeFirmware)
+		}
+		if info.Firmware.OSLoaderVersion != "" {
+			fmt.Printf("%-20s %-20s %s\n", "OS Loader版本", "", info.Firmware.OSLoaderVersion)
+		}
+		if info.Firmware.Mode != "" {
+			fmt.Printf("%-20s %-20s %s\n", "启动模式", "", info.Firmware.Mode)
+		}
+		if info.Firmware.SecureBoot != "" {
+			fmt.Printf("%-20s %-20s %s\n", "安全启动", "", info.Firmware.SecureBoot)
+		}
+	}
+
+	// 显示安全硬件信息
+	if info.SecurityHardware.SecurityChip != "" {
+		fmt.Printf("%-20s %-20s %s\n", "安全芯片", "", info.SecurityHardware.SecurityChip)
+		if info.SecurityHardware.HasSecureEnclave {
+			fmt.Printf("%-20s %-20s %s\n", "安全隔区", "", "支持")
+		}
+	}
+	if info.SecurityHardware.TouchIDPresent {
+		fmt.Printf("%-20s %-20s %s\n", "Touch ID", "", fmt.Sprintf("已录入 %d 个指纹", info.SecurityHardware.TouchIDEnrolled))
+	}
+	if info.SecurityHardware.SecurityMode != "" {
+		fmt.Printf("%-20s %-20s %s\n", "启动安全模式", "", info.SecurityHardware.SecurityMode)
+	}
+	if info.SecurityHardware.TPMPresent {
+		tpmReady := "未就绪"
+		if info.SecurityHardware.TPMReady {
+			tpmReady = "已就绪"
+		}
+		fmt.Printf("%-20s %-20s %s\n", "TPM", "", fmt.Sprintf("%s（%s，%s）", info.SecurityHardware.TPMVersion, info.SecurityHardware.TPMManufacturer, tpmReady))
+	} else if info.SecurityHardware.TPMUnknown {
+		fmt.Printf("%-20s %-20s %s\n", "TPM", "", "未知")
+	} else if runtime.GOOS == "windows" {
+		fmt.Printf("%-20s %-20s %s\n", "TPM", "", "未检测到")
+	}
+
+	// 显示PCIe/雷雳设备
+	if len(info.PCIDevices) > 0 {
+		fmt.Printf("%-20s\n", "PCIe设备")
+		for _, device := range info.PCIDevices {
+			link := strings.TrimSpace(device.LinkWidth + " " + device.LinkSpeed)
+			fmt.Printf("  %-18s %-20s %s %s:%s %s\n", device.Type, "", device.Name, device.VendorID, device.DeviceID, link)
+		}
+	}
+
+	// 显示第三方驱动和扩展，标记未签名或已弃用的项目
+	if len(info.Drivers) > 0 {
+		fmt.Printf("%-20s\n", "第三方驱动/扩展")
+		for _, driver := range info.Drivers {
+			var flags []string
+			if driver.Signature == "未签名" {
+				flags = append(flags, "未签名")
+			}
+			if driver.Deprecated {
+				flags = append(flags, "已弃用")
+			}
+			warning := ""
+			if len(flags) > 0 {
+				warning = "（" + strings.Join(flags, "，") + "）"
+			}
+			fmt.Printf("  %-18s %-20s %s %s%s\n", driver.Kind, driver.Name, driver.Version, driver.Provider, warning)
+		}
+	}
+
+	// 显示启动盘加密信息
+	if info.DiskEncryption.Unknown {
+		fmt.Printf("%-20s %-20s %s\n", "硬件加密", "", "未知")
+		fmt.Printf("%-20s %-20s %s\n", info.DiskEncryption.SoftwareName, "", "未知")
+	} else if info.DiskEncryption.HardwareEncrypted {
+		fmt.Printf("%-20s %-20s %s\n", "硬件加密", "", fmt.Sprintf("是（%s）", info.DiskEncryption.HardwareMethod))
+	} else {
+		fmt.Printf("%-20s %-20s %s\n", "硬件加密", "", "否")
+	}
+	if info.DiskEncryption.SoftwareStatus != "" {
+		softwareState := "关闭"
+		if info.DiskEncryption.SoftwareEnabled {
+			softwareState = "开启"
+		}
+		fmt.Printf("%-20s %-20s %s\n", info.DiskEncryption.SoftwareName, "", fmt.Sprintf("%s（%s）", softwareState, info.DiskEncryption.SoftwareStatus))
+	}
+
 	// 显示WiFi支持的PHY模式
 	if info.Network.WiFi.SupportedPHY != "" {
 		fmt.Printf("%-20s %-20s %s\n", "WiFi支持的PHY模式", "", info.Network.WiFi.SupportedPHY)
@@ -158,6 +1839,40 @@ func printSystemInfo(info model.SystemInfo) {
 		fmt.Printf("%-20s %-20s %.2f GB\n", "硬盘容量（已使用）", "", usedGB)
 	}
 
+	// 显示磁盘增长趋势
+	if info.DiskTrend.DailyGrowth != 0 {
+		fmt.Printf("%-20s %-20s %+.2f GB/天（自 %s 起 %d 次采样）\n", "磁盘增长趋势", info.DiskTrend.MountPoint,
+			info.DiskTrend.DailyGrowth/(1024*1024*1024), info.DiskTrend.Since, info.DiskTrend.Samples)
+		if info.DiskTrend.DaysUntilFull > 0 {
+			fmt.Printf("%-20s %-20s %s\n", "预计磁盘写满", "", fmt.Sprintf("约 %.0f 天后写满", info.DiskTrend.DaysUntilFull))
+		}
+	}
+
+	// 显示深度磁盘空间分析结果（--scan-disk）
+	if info.DiskScan.Root != "" {
+		fmt.Printf("%-20s %-20s %s\n", "最大目录", info.DiskScan.Root, "扫描耗时 "+info.DiskScan.Duration)
+		for _, dir := range info.DiskScan.LargestDirs {
+			fmt.Printf("  %-18s %-20s %s\n", dir.Name, fmt.Sprintf("%.2f GB", float64(dir.Size)/(1024*1024*1024)), dir.Path)
+		}
+		if len(info.DiskScan.SpaceHogs) > 0 {
+			fmt.Printf("%-20s\n", "可清理空间")
+			for _, hog := range info.DiskScan.SpaceHogs {
+				fmt.Printf("  %-18s %-20s %s\n", hog.Name, fmt.Sprintf("%.2f GB", float64(hog.Size)/(1024*1024*1024)), hog.Hint)
+			}
+		}
+	}
+
+	// 显示外接和可移动存储
+	if len(info.ExternalStorage) > 0 {
+		fmt.Printf("%-20s\n", "外接/可移动存储")
+		for _, volume := range info.ExternalStorage {
+			fmt.Printf("  %-18s %-20s %s %s %.2f GB（可用 %.2f GB）%s %s\n", volume.Device, "",
+				volume.Name, volume.Filesystem,
+				float64(volume.Total)/(1024*1024*1024), float64(volume.Free)/(1024*1024*1024),
+				volume.Bus, volume.Encryption)
+		}
+	}
+
 	// 显示内存使用情况
 	fmt.Printf("%-20s %-20s %.2f GB\n", "内存容量（已使用）", "", float64(info.MemoryUsage.Used)/(1024*1024*1024))
 
@@ -184,6 +1899,17 @@ func printSystemInfo(info model.SystemInfo) {
 			fmt.Printf("%-20s %-20s %s\n", "电池状态", "", info.Battery.Status)
 		}
 
+		if info.Battery.HealthPercent > 0 {
+			fmt.Printf("%-20s %-20s %.0f%%（%s）\n", "电池健康度", "", info.Battery.HealthPercent, info.Battery.HealthVerdict)
+			fmt.Printf("%-20s %-20s %s\n", "电池更换建议", "", info.Battery.Advice)
+		}
+
+		if info.Battery.LowPowerMode {
+			fmt.Printf("%-20s %-20s %s\n", "低电量模式", "", "开启")
+		} else {
+			fmt.Printf("%-20s %-20s %s\n", "低电量模式", "", "关闭")
+		}
+
 		if info.Battery.TimeRemaining > 0 {
 			hours := info.Battery.TimeRemaining / 60
 			minutes := info.Battery.TimeRemaining % 60
@@ -191,6 +1917,16 @@ func printSystemInfo(info model.SystemInfo) {
 		}
 	}
 
+	// 显示屏幕亮度信息
+	if info.Display.Brightness > 0 {
+		fmt.Printf("%-20s %-20s %.0f%%\n", "屏幕亮度", "", info.Display.Brightness)
+	}
+	if info.Display.AutoBrightness {
+		fmt.Printf("%-20s %-20s %s\n", "自动调节亮度", "", "开启")
+	} else {
+		fmt.Printf("%-20s %-20s %s\n", "自动调节亮度", "", "关闭")
+	}
+
 	// 显示交流充电器信息
 	if info.ACAdapter.Connected {
 		fmt.Printf("%-20s %-20s %s\n", "交流充电器-连接状态", "", "已连接")
@@ -206,6 +1942,16 @@ func printSystemInfo(info model.SystemInfo) {
 		if info.ACAdapter.ChipModel != "" {
 			fmt.Printf("%-20s %-20s %s\n", "交流充电器-芯片型号", "", info.ACAdapter.ChipModel)
 		}
+		if info.ACAdapter.NegotiatedWattage > 0 {
+			fmt.Printf("%-20s %-20s %.1fV / %.2fA / %.0fW\n", "交流充电器-协商参数", "",
+				info.ACAdapter.NegotiatedVoltage, info.ACAdapter.NegotiatedCurrent, info.ACAdapter.NegotiatedWattage)
+		}
+		if info.ACAdapter.BatteryChargeRate > 0 {
+			fmt.Printf("%-20s %-20s %.1fW\n", "电池-充电速率", "", info.ACAdapter.BatteryChargeRate)
+		}
+		if info.ACAdapter.ChargingSlowly {
+			fmt.Printf("%-20s %-20s %s\n", "交流充电器-充电缓慢", "", fmt.Sprintf("是（低于本机额定功率%dW）", info.ACAdapter.RatedWattage))
+		}
 	} else {
 		fmt.Printf("%-20s %-20s %s\n", "交流充电器-连接状态", "", "未连接")
 	}
@@ -232,6 +1978,20 @@ func printSystemInfo(info model.SystemInfo) {
 		fmt.Printf("%-20s %-20s %s\n", "蓝牙-状态", "", "关闭")
 	}
 
+	// 显示输入设备信息
+	if len(info.InputDevices.BuiltIn) > 0 {
+		fmt.Printf("%-20s\n", "内置输入设备")
+		for _, device := range info.InputDevices.BuiltIn {
+			fmt.Printf("  %-18s %-20s %s（%s）\n", device.Type, "", device.Name, device.Status)
+		}
+	}
+	if len(info.InputDevices.External) > 0 {
+		fmt.Printf("%-20s\n", "外接输入设备")
+		for _, device := range info.InputDevices.External {
+			fmt.Printf("  %-18s %-20s %s（%s，%s）\n", device.Type, "", device.Name, device.Transport, device.Status)
+		}
+	}
+
 	// 显示温度信息
 	if len(info.Temperature) > 0 {
 		fmt.Printf("%-20s\n", "设备温度")
@@ -256,6 +2016,13 @@ func printSystemInfo(info model.SystemInfo) {
 	// 网络客户端动态数据
 	fmt.Println("\n======================= 网络客户端动态数据 =======================")
 
+	// 显示网络健康评分
+	health := info.Network.HealthScore
+	fmt.Printf("%-20s %-20s %s\n", "网络健康评分", fmt.Sprintf("%d（%s）", health.Score, health.Grade), health.Verdict)
+	for _, deduction := range health.Deductions {
+		fmt.Printf("  %-18s %s\n", "", deduction)
+	}
+
 	// 显示WiFi信息
 	fmt.Printf("%-20s %-20s %s\n", "客户端SSID", "", info.Network.WiFi.SSID)
 	fmt.Printf("%-20s %-20s %s\n", "客户端IP", "", info.Network.IP)
@@ -278,12 +2045,22 @@ func printSystemInfo(info model.SystemInfo) {
 	}
 
 	fmt.Printf("%-20s %-20s %s\n", "PHY模式", "", info.Network.WiFi.PHYMode)
+	if analysis.WeakWiFiSecurity(info.Network.WiFi.Security) {
+		fmt.Printf("%-20s %-20s %s\n", "安全类型", "", info.Network.WiFi.Security+"（不加密或加密强度弱）")
+	} else {
+		fmt.Printf("%-20s %-20s %s\n", "安全类型", "", info.Network.WiFi.Security)
+	}
 	fmt.Printf("%-20s %-20s %s\n", "WiFi支持的PHY模式", "", info.Network.WiFi.SupportedPHY)
 	if info.Network.WiFi.Channel > 0 && info.Network.WiFi.Frequency > 0 {
 		fmt.Printf("%-20s %-20s %d（%.1f Ghz）\n", "频道", "", info.Network.WiFi.Channel, info.Network.WiFi.Frequency)
 	} else {
 		fmt.Printf("%-20s %-20s %s\n", "频道", "", "")
 	}
+	if info.Network.WiFi.ChannelWidth > 0 {
+		fmt.Printf("%-20s %-20s %d MHz\n", "信道宽度", "", info.Network.WiFi.ChannelWidth)
+	} else {
+		fmt.Printf("%-20s %-20s %s\n", "信道宽度", "", "")
+	}
 
 	if info.Network.WiFi.TxRate > 0 {
 		fmt.Printf("%-20s %-20s %dMbps\n", "Tx速率", "", info.Network.WiFi.TxRate)
@@ -291,16 +2068,86 @@ func printSystemInfo(info model.SystemInfo) {
 		fmt.Printf("%-20s %-20s %s\n", "Tx速率", "", "")
 	}
 
-	if info.Network.WiFi.MCS > 0 {
-		fmt.Printf("%-20s %-20s %d\n", "MCS", "", info.Network.WiFi.MCS)
-	} else {
-		fmt.Printf("%-20s %-20s %s\n", "MCS", "", "")
+	if info.Network.WiFi.MCS > 0 {
+		fmt.Printf("%-20s %-20s %d\n", "MCS", "", info.Network.WiFi.MCS)
+	} else {
+		fmt.Printf("%-20s %-20s %s\n", "MCS", "", "")
+	}
+
+	if info.Network.WiFi.NSS > 0 {
+		fmt.Printf("%-20s %-20s %d\n", "NSS", "", info.Network.WiFi.NSS)
+	} else {
+		fmt.Printf("%-20s %-20s %s\n", "NSS", "", "")
+	}
+
+	// 显示最近的WiFi连接、漫游和断开记录
+	if history := info.Network.WiFiHistory; len(history.Events) > 0 {
+		fmt.Printf("%-20s %-20s %s\n", "WiFi事件记录", "自 "+history.Since,
+			fmt.Sprintf("连接 %d 次，漫游 %d 次，断开 %d 次，连接失败 %d 次", history.Connects, history.Roams, history.Disconnects, history.Failures))
+		for _, event := range history.Events {
+			detail := strings.TrimSpace(strings.Join([]string{event.SSID, event.BSSID}, " "))
+			if event.Reason != "" {
+				detail = strings.TrimSpace(detail + " " + event.Reason)
+			} else if event.ReasonCode != 0 {
+				detail = strings.TrimSpace(fmt.Sprintf("%s 原因代码 %d", detail, event.ReasonCode))
+			}
+			fmt.Printf("  %-18s %-20s %s\n", event.Time, event.Type, detail)
+		}
+	}
+
+	// 显示附近的无线网络（--scan-wifi）
+	if len(info.Network.WiFiScan.Networks) > 0 {
+		fmt.Printf("%-20s %-20s %s\n", "附近无线网络", fmt.Sprintf("%d 个", len(info.Network.WiFiScan.Networks)), info.Network.WiFiScan.Summary)
+		for _, network := range info.Network.WiFiScan.Networks {
+			ssid := network.SSID
+			if ssid == "" {
+				ssid = "（隐藏网络）"
+			}
+			fmt.Printf("  %-18s %-20s %s\n", ssid, fmt.Sprintf("%d dBm", network.RSSI),
+				fmt.Sprintf("信道 %d（%s）%s %s", network.Channel, network.Band, network.Security, network.BSSID))
+		}
+		fmt.Printf("%-20s\n", "信道占用")
+		for _, channel := range info.Network.WiFiScan.Channels {
+			fmt.Printf("  %-18s %-20s %s\n", fmt.Sprintf("%s 信道 %d", channel.Band, channel.Channel), fmt.Sprintf("%d 个网络", channel.Networks),
+				fmt.Sprintf("相邻信道 %d 个，最强信号 %d dBm", channel.Adjacent, channel.Strongest))
+		}
+		if info.Network.WiFiScan.Suggestion != "" {
+			fmt.Printf("%-20s %-20s %s\n", "信道建议", "", info.Network.WiFiScan.Suggestion)
+		}
+	}
+
+	// 显示局域网mDNS服务（--scan-lan）
+	if info.Network.MDNS.Assessment != "" {
+		fmt.Printf("%-20s %-20s %s\n", "局域网服务发现", fmt.Sprintf("%d 台设备应答", len(info.Network.MDNS.Responders)), info.Network.MDNS.Assessment)
+		for _, service := range info.Network.MDNS.Services {
+			if len(service.Instances) == 0 {
+				continue
+			}
+			name := service.Type
+			if service.Description != "" {
+				name = service.Description
+			}
+			fmt.Printf("  %-18s %-20s %s\n", name, fmt.Sprintf("%d 个", len(service.Instances)), strings.Join(service.Instances, ", "))
+		}
 	}
 
-	if info.Network.WiFi.NSS > 0 {
-		fmt.Printf("%-20s %-20s %d\n", "NSS", "", info.Network.WiFi.NSS)
-	} else {
-		fmt.Printf("%-20s %-20s %s\n", "NSS", "", "")
+	// 显示交换机端口（--scan-switch）
+	for _, neighbor := range info.Network.Neighbors {
+		port := neighbor.PortID
+		if neighbor.PortDescription != "" && neighbor.PortDescription != port {
+			port += "（" + neighbor.PortDescription + "）"
+		}
+		if neighbor.VLAN > 0 {
+			port += fmt.Sprintf("，VLAN %d", neighbor.VLAN)
+		}
+		name := neighbor.SwitchName
+		if name == "" {
+			name = neighbor.ChassisID
+		}
+		fmt.Printf("%-20s %-20s %s\n", "交换机端口", neighbor.Interface+" "+neighbor.Protocol, name+" 端口 "+port)
+		if neighbor.ManagementAddress != "" || neighbor.Description != "" {
+			fmt.Printf("  %-18s %-20s %s\n", "", neighbor.ManagementAddress, neighbor.Description)
+		}
 	}
 
 	// 显示网卡流量
@@ -309,19 +2156,50 @@ func printSystemInfo(info model.SystemInfo) {
 	} else {
 		fmt.Printf("%-20s %-20s %s\n", "网卡流量", "", "")
 	}
+	for _, traffic := range info.Network.InterfaceTraffic {
+		fmt.Printf("  %-18s %-20s %s\n", traffic.Name, "",
+			fmt.Sprintf("接收 %.2f KB/s，发送 %.2f KB/s", traffic.RxRate/1024, traffic.TxRate/1024))
+	}
 
 	if info.Network.ProcessTraffic != "" {
 		fmt.Printf("%-20s %-20s %s\n", "各进程流量", "", info.Network.ProcessTraffic)
 	} else {
 		fmt.Printf("%-20s %-20s %s\n", "各进程流量", "", "")
 	}
+	for i, traffic := range info.Network.ProcessTrafficStats {
+		if i >= 10 { // 只显示流量最大的10个进程
+			break
+		}
+		fmt.Printf("  %-18s %-20s %s\n", traffic.Process, fmt.Sprintf("PID %d", traffic.PID),
+			fmt.Sprintf("接收 %.2f KB/s，发送 %.2f KB/s", traffic.RateIn/1024, traffic.RateOut/1024))
+	}
 
 	// 显示网络延迟信息
 	if info.Network.Latency.AvgLatency > 0 {
-		fmt.Printf("%-20s %-20s %s\n", "探测点延迟、抖动、丢包", "", fmt.Sprintf("%.0fms", info.Network.Latency.AvgLatency))
+		fmt.Printf("%-20s %-20s %s\n", "探测点延迟、抖动、丢包", "", fmt.Sprintf("%.0fms，抖动 %.1fms，丢包 %.0f%%",
+			info.Network.Latency.AvgLatency, info.Network.Latency.Jitter, info.Network.Latency.PacketLoss))
 	} else {
 		fmt.Printf("%-20s %-20s %s\n", "探测点延迟、抖动、丢包", "", "")
 	}
+	for _, target := range info.Network.Latency.Targets {
+		host := target.TargetHost
+		if target.Protocol == config.ProtocolTCP {
+			host = fmt.Sprintf("%s:%d", target.TargetHost, target.Port)
+		}
+		summary := fmt.Sprintf("%s %.1fms（%.1f/%.1f），抖动 %.1fms，标准差 %.1fms，丢包 %.0f%%", strings.ToUpper(target.Protocol),
+			target.AvgLatency, target.MinLatency, target.MaxLatency, target.Jitter, target.StdDev, target.PacketLoss)
+		if len(target.Warnings) > 0 {
+			summary += "（" + strings.Join(target.Warnings, "，") + "）"
+		}
+		fmt.Printf("  %-18s %-20s %s\n", target.Group+"/"+target.TargetName, host, summary)
+	}
+	if len(info.Network.Latency.NetworkHops) > 0 {
+		fmt.Printf("%-20s %-20s %s\n", "网络路径", "", fmt.Sprintf("%d跳", len(info.Network.Latency.NetworkHops)))
+		for _, hop := range info.Network.Latency.NetworkHops {
+			fmt.Printf("  %-18s %-20s %s\n", fmt.Sprintf("第%d跳", hop.HopNum), hop.Host, fmt.Sprintf("平均 %.1fms（%.1f/%.1f），丢包 %.0f%%",
+				hop.AvgLatency, hop.BestLatency, hop.WorstLatency, hop.Loss))
+		}
+	}
 
 	// 显示VPN信息
 	if info.Network.VPN.IsConnected {
@@ -329,6 +2207,38 @@ func printSystemInfo(info model.SystemInfo) {
 	} else {
 		fmt.Printf("%-20s %-20s %s\n", "VPN状态及连接的节点", "", "未连接")
 	}
+	for _, client := range info.Network.VPN.Clients {
+		state := "已安装"
+		switch {
+		case client.Connected:
+			state = "已连接"
+		case client.Running:
+			state = "运行中，未连接"
+		}
+		if client.Node != "" {
+			state += "，节点：" + client.Node
+		}
+		fmt.Printf("  %-18s %-20s %s\n", client.Provider, client.Interface, state+"（"+client.Source+"）")
+	}
+	if split := info.Network.VPN.SplitTunnel; split.Mode != "" {
+		fmt.Printf("  %-18s %-20s %s\n", "隧道模式", strings.Join(split.Interfaces, ", "), split.Mode+"："+split.Assessment)
+		if len(split.TunnelRoutes) > 0 {
+			fmt.Printf("  %-18s %-20s %s\n", "经过隧道", "", strings.Join(split.TunnelRoutes, ", "))
+		}
+		if len(split.BypassRoutes) > 0 {
+			fmt.Printf("  %-18s %-20s %s\n", "绕过隧道", "", strings.Join(split.BypassRoutes, ", "))
+		}
+		for _, subnet := range split.Subnets {
+			result := "未经过隧道"
+			if subnet.Tunneled {
+				result = "经过隧道"
+			}
+			if subnet.Route != "" {
+				result += fmt.Sprintf("（路由 %s，接口 %s）", subnet.Route, subnet.Interface)
+			}
+			fmt.Printf("  %-18s %-20s %s\n", "公司网段", subnet.Subnet, result)
+		}
+	}
 
 	// 显示客户端路由表
 	if len(info.Network.RouteTable) > 0 {
@@ -351,6 +2261,50 @@ func printSystemInfo(info model.SystemInfo) {
 		fmt.Printf("%-20s %-20s %s\n", "客户端路由表", "", "未找到路由信息")
 	}
 
+	// 显示监听中的端口，对外开放的端口单独标注
+	if len(info.Network.ListeningPorts) > 0 {
+		exposed := 0
+		for _, port := range info.Network.ListeningPorts {
+			if port.Exposed {
+				exposed++
+			}
+		}
+		fmt.Printf("%-20s %-20s %s\n", "监听端口", "", fmt.Sprintf("%d 个，其中 %d 个对外开放", len(info.Network.ListeningPorts), exposed))
+		for _, port := range info.Network.ListeningPorts {
+			process := "未知进程（可能需要管理员权限）"
+			if port.PID > 0 {
+				process = fmt.Sprintf("%s（PID %d）", port.Process, port.PID)
+			}
+			if port.Signer != "" {
+				process += " " + port.Signer
+			}
+			if !port.Exposed {
+				process += " 仅本机"
+			}
+			fmt.Printf("  %-18s %-20s %s\n", fmt.Sprintf("%s %d", port.Protocol, port.Port), port.Address, process)
+		}
+	}
+
+	// 显示按进程和远端组织汇总的已建立连接
+	if len(info.Network.Connections) > 0 {
+		total := 0
+		for _, group := range info.Network.Connections {
+			total += group.Connections
+		}
+		fmt.Printf("%-20s %-20s %s\n", "已建立连接", "", fmt.Sprintf("%d 个", total))
+		for i, group := range info.Network.Connections {
+			if i >= 10 { // 只显示连接最多的10组
+				fmt.Printf("  ... 还有 %d 组连接 ...\n", len(info.Network.Connections)-10)
+				break
+			}
+			remote := group.Remote
+			if group.ASN != "" {
+				remote += "（" + group.ASN + "）"
+			}
+			fmt.Printf("  %-18s %-20s %s\n", group.Process, fmt.Sprintf("%d 个连接", group.Connections), "到 "+remote)
+		}
+	}
+
 	// 显示hosts文件
 	if len(info.Network.DNS.HostEntries) > 0 {
 		fmt.Printf("%-20s %-20s\n", "host文件", "")
@@ -382,12 +2336,202 @@ func printSystemInfo(info model.SystemInfo) {
 		fmt.Printf("%-20s %-20s %s\n", "dns配置", "", "")
 	}
 
+	// 显示按接口和作用域区分的DNS解析器
+	for _, resolver := range info.Network.DNS.Resolvers {
+		scope := resolver.Scope
+		if resolver.Domain != "" {
+			scope += "（" + resolver.Domain + "）"
+		}
+		servers := strings.Join(resolver.Servers, ", ")
+		if resolver.VPN {
+			servers += "（VPN）"
+		}
+		fmt.Printf("  %-18s %-20s %s\n", resolver.Interface, scope, servers)
+	}
+	for _, finding := range info.Network.DNS.Findings {
+		fmt.Printf("%-20s %-20s %s\n", "DNS冲突", "", finding)
+	}
+
+	// 显示各DNS服务器的解析耗时
+	for _, benchmark := range info.Network.DNSBenchmark {
+		summary := benchmark.Status
+		if benchmark.AvgTime > 0 {
+			summary += fmt.Sprintf("，平均 %.1fms（%.1f/%.1f）", benchmark.AvgTime, benchmark.MinTime, benchmark.MaxTime)
+		}
+		if benchmark.Failures+benchmark.Timeouts > 0 {
+			summary += fmt.Sprintf("，失败 %d 次，超时 %d 次", benchmark.Failures, benchmark.Timeouts)
+		}
+		fmt.Printf("%-20s %-20s %s\n", "DNS解析测试", benchmark.Server+"（"+benchmark.Source+"）", summary)
+	}
+
+	// 显示关键域名的DNS诊断结果
+	for _, finding := range info.Network.DNSDiagnostics.Findings {
+		fmt.Printf("%-20s %-20s %s\n", "DNS诊断", "", finding)
+	}
+	for _, domain := range info.Network.DNSDiagnostics.Domains {
+		fmt.Printf("%-20s %-20s %s\n", "DNS诊断", domain.Domain, strings.Join(domain.Findings, "；"))
+		for _, answer := range domain.Answers {
+			result := answer.Error
+			if result == "" {
+				addrs := append(append(append([]string{}, answer.CNAME...), answer.A...), answer.AAAA...)
+				result = answer.RCode + " " + strings.Join(addrs, ", ")
+			}
+			fmt.Printf("  %-18s %-20s %s\n", answer.Server, answer.Source, result)
+		}
+	}
+
 	// 显示公网IP
 	if info.Network.PublicIP != "" {
-		fmt.Printf("%-20s %-20s %s\n", "公网出口IP", "", info.Network.PublicIP)
+		fmt.Printf("%-20s %-20s %s\n", "公网出口IP", info.Network.PublicIPAgree, info.Network.PublicIP)
+		if len(info.Network.PublicIPOthers) > 0 {
+			fmt.Printf("  %-18s %-20s %s\n", "其他出口", "", strings.Join(info.Network.PublicIPOthers, ", ")+"（不同服务看到的地址不一致，可能存在分流代理或多出口）")
+		}
+		if info.Network.PublicASN != "" || info.Network.PublicISP != "" {
+			fmt.Printf("  %-18s %-20s %s\n", "运营商", info.Network.PublicASN, info.Network.PublicISP)
+		}
+		if info.Network.PublicCountry != "" {
+			fmt.Printf("  %-18s %-20s %s\n", "归属地", info.Network.PublicCountry, info.Network.PublicRegion)
+		}
+		if info.Network.EgressProvider != "" {
+			fmt.Printf("  %-18s %-20s %s\n", "VPN/代理服务商", info.Network.EgressProvider, "出口流量经过该服务商")
+		}
 	} else {
 		fmt.Printf("%-20s %-20s %s\n", "公网出口IP", "", "")
 	}
+	if info.Network.PublicIPv6 != "" {
+		fmt.Printf("%-20s %-20s %s\n", "IPv6公网出口", "", info.Network.PublicIPv6)
+	}
+
+	// 显示默认网关与互联网的连通性对比
+	gateway := info.Network.Gateway
+	fmt.Printf("%-20s %-20s %s\n", "默认网关", gateway.Address, gateway.Assessment)
+	if gateway.Address != "" {
+		fmt.Printf("  %-18s %-20s %s\n", "网关", gateway.Interface, fmt.Sprintf("平均延迟 %.1fms，丢包率 %.0f%%", gateway.Latency, gateway.Loss))
+		fmt.Printf("  %-18s %-20s %s\n", "互联网", gateway.InternetTarget, fmt.Sprintf("平均延迟 %.1fms，丢包率 %.0f%%", gateway.InternetLatency, gateway.InternetLoss))
+	}
+
+	// 显示各接口的DHCP租约
+	for _, lease := range info.Network.DHCPLeases {
+		fmt.Printf("%-20s %-20s %s\n", "DHCP租约", lease.Interface+" "+lease.IPAddress, "服务器 "+lease.Server)
+		if lease.LeaseExpires != "" {
+			fmt.Printf("  %-18s %-20s %s\n", "租期", fmt.Sprintf("%d 秒", lease.LeaseTime), lease.LeaseObtained+" 至 "+lease.LeaseExpires)
+		} else if lease.LeaseTime > 0 {
+			fmt.Printf("  %-18s %-20s %s\n", "租期", fmt.Sprintf("%d 秒", lease.LeaseTime), "")
+		}
+		fmt.Printf("  %-18s %-20s %s\n", "路由器/DNS", strings.Join(lease.Routers, ", "), strings.Join(lease.DNSServers, ", "))
+		if lease.Domain != "" {
+			fmt.Printf("  %-18s %-20s %s\n", "域名", lease.Domain, "")
+		}
+		if lease.VendorSpecific != "" || lease.VendorClass != "" {
+			fmt.Printf("  %-18s %-20s %s\n", "选项43/60", lease.VendorClass, lease.VendorSpecific)
+		}
+	}
+
+	// 显示HTTP/HTTPS端点检查结果
+	for _, check := range info.Network.EndpointChecks {
+		status := check.Error
+		if status == "" {
+			status = fmt.Sprintf("HTTP %d %s", check.Status, check.TLSVersion)
+		}
+		fmt.Printf("%-20s %-20s %s\n", "端点检查", check.Name, check.Assessment)
+		fmt.Printf("  %-18s %-20s %s\n", status, fmt.Sprintf("总耗时 %.0fms", check.TotalTime),
+			fmt.Sprintf("DNS %.0fms，连接 %.0fms，TLS %.0fms，首字节 %.0fms", check.DNSTime, check.ConnectTime, check.TLSTime, check.TTFB))
+		for _, cert := range check.Certificates {
+			fmt.Printf("  %-18s %-20s %s\n", "", cert.Subject, fmt.Sprintf("签发者 %s，%s 到期", cert.Issuer, cert.NotAfter))
+		}
+	}
+
+	// 显示常用SaaS服务的可达性
+	for _, suite := range info.Network.SaaS {
+		fmt.Printf("%-20s %-20s %s\n", "SaaS可达性", suite.Name, fmt.Sprintf("%d/%d 可访问，%s", suite.Reachable, len(suite.Checks), suite.Assessment))
+		for _, check := range suite.Checks {
+			result := check.Error
+			if result == "" {
+				result = fmt.Sprintf("HTTP %d，总耗时 %.0fms", check.Status, check.TotalTime)
+			}
+			fmt.Printf("  %-18s %-20s %s\n", check.Name, check.URL, result)
+		}
+	}
+
+	// 显示出站端口可达性
+	if info.Network.PortMatrix.Assessment != "" {
+		fmt.Printf("%-20s %-20s %s\n", "出站端口", "", info.Network.PortMatrix.Assessment)
+		for _, check := range info.Network.PortMatrix.Checks {
+			result := check.Status
+			if check.Open {
+				result += fmt.Sprintf("，%.0fms", check.Time)
+			}
+			fmt.Printf("  %-18s %-20s %s\n", check.Name, fmt.Sprintf("%s:%d", check.Host, check.Port), result)
+		}
+	}
+
+	// 显示负载下的响应能力（--network-quality）
+	if quality := info.Network.Quality; quality.Assessment != "" {
+		rating := quality.Rating
+		if quality.RPM > 0 {
+			rating = fmt.Sprintf("%d RPM（%s）", quality.RPM, quality.Rating)
+		}
+		fmt.Printf("%-20s %-20s %s\n", "负载下响应能力", rating, quality.Assessment)
+		if quality.Error != "" {
+			fmt.Printf("  %-18s %-20s %s\n", quality.Source, "", quality.Error)
+		} else {
+			throughput := fmt.Sprintf("下载 %.1f Mbps", quality.Download)
+			if quality.Upload > 0 {
+				throughput += fmt.Sprintf("，上传 %.1f Mbps", quality.Upload)
+			}
+			fmt.Printf("  %-18s %-20s %s\n", quality.Source, fmt.Sprintf("延迟增加 %.0fms", quality.Bufferbloat), throughput)
+		}
+	}
+
+	// 显示TCP重传和错误统计
+	tcp := info.Network.TCPStats
+	if tcp.Assessment != "" {
+		fmt.Printf("%-20s %-20s %s\n", "TCP重传", fmt.Sprintf("%.2f%%（%s）", tcp.RetransmitRate, tcp.SampleWindow), tcp.Assessment)
+		fmt.Printf("  %-18s %-20s %s\n", "报文段", fmt.Sprintf("发送 %d，接收 %d", tcp.SegmentsSent, tcp.SegmentsReceived),
+			fmt.Sprintf("重传 %d，连接重置 %d，连接失败 %d，无效 %d", tcp.Retransmitted, tcp.Resets, tcp.Failures, tcp.Errors))
+	}
+
+	// 显示路径MTU
+	mtu := info.Network.MTU
+	fmt.Printf("%-20s %-20s %s\n", "路径MTU", mtu.Target, mtu.Assessment)
+	if mtu.PathMTU > 0 {
+		fmt.Printf("  %-18s %-20s %s\n", "MTU", mtu.Interface, fmt.Sprintf("接口 %d 字节，路径 %d 字节", mtu.InterfaceMTU, mtu.PathMTU))
+	}
+
+	// 显示NAT类型
+	nat := info.Network.NAT
+	fmt.Printf("%-20s %-20s %s\n", "NAT类型", nat.Type, nat.Assessment)
+	if nat.MappedAddress != "" {
+		fmt.Printf("  %-18s %-20s %s\n", "外部映射地址", nat.MappedAddress, "本机 "+nat.LocalAddress)
+	}
+
+	// 显示IPv6连通性
+	ipv6 := info.Network.IPv6
+	fmt.Printf("%-20s %-20s %s\n", "IPv6", ipv6.SourceAddress, ipv6.Assessment)
+	for _, addr := range ipv6.Addresses {
+		fmt.Printf("  %-18s %-20s %s\n", addr.Interface, addr.Scope, addr.Address)
+	}
+	if ipv6.DefaultRoute {
+		gateway := ipv6.Gateway
+		if ipv6.Interface != "" {
+			gateway += "（" + ipv6.Interface + "）"
+		}
+		fmt.Printf("  %-18s %-20s %s\n", "默认路由", gateway, fmt.Sprintf("IPv6 %.1fms，IPv4 %.1fms", ipv6.IPv6Latency, ipv6.IPv4Latency))
+	}
+
+	// 显示时间同步状态和时钟偏差
+	timeSync := info.Network.TimeSync
+	if timeSync.Enabled {
+		fmt.Printf("%-20s %-20s %s\n", "网络时间同步", "", "已启用 "+strings.Join(timeSync.Servers, ", "))
+	} else {
+		fmt.Printf("%-20s %-20s %s\n", "网络时间同步", "", "未启用")
+	}
+	if timeSync.LastSync != "" {
+		fmt.Printf("%-20s %-20s %s\n", "上次同步", "", timeSync.LastSync)
+	}
+	if timeSync.Status != "" {
+		fmt.Printf("%-20s %-20s %s\n", "时钟偏差", timeSync.ReferenceServer, fmt.Sprintf("%+.3f 秒（%s）", timeSync.Offset, timeSync.Status))
+	}
 
 	// 显示网络代理状态
 	if info.Network.ProxyStatus {
@@ -395,6 +2539,38 @@ func printSystemInfo(info model.SystemInfo) {
 	} else {
 		fmt.Printf("%-20s %-20s %s\n", "网络代理状态", "", "关闭")
 	}
+	for _, setting := range info.Network.ProxyInfo.Settings {
+		address := setting.Server
+		if setting.Port > 0 {
+			address = net.JoinHostPort(setting.Server, strconv.Itoa(setting.Port))
+		}
+		if setting.Type == analysis.ProxyWPAD {
+			address = "自动发现代理"
+		}
+		fmt.Printf("  %-18s %-20s %s\n", setting.Source, setting.Type, address)
+	}
+	for _, pac := range info.Network.ProxyInfo.PAC {
+		summary := pac.Error
+		if summary == "" {
+			summary = fmt.Sprintf("%d 字节，%d 条规则", pac.Size, pac.Rules)
+			if len(pac.Proxies) > 0 {
+				summary += "，代理：" + strings.Join(pac.Proxies, ", ")
+			}
+			if pac.Direct {
+				summary += "，包含直连规则"
+			}
+		}
+		fmt.Printf("  %-18s %-20s %s\n", "PAC文件", pac.Source, pac.URL+"（"+summary+"）")
+	}
+	if len(info.Network.ProxyInfo.Bypass) > 0 {
+		fmt.Printf("  %-18s %-20s %s\n", "不使用代理", "", strings.Join(info.Network.ProxyInfo.Bypass, ", "))
+	}
+	for _, env := range info.Network.ProxyInfo.Environment {
+		fmt.Printf("  %-18s %-20s %s\n", env.Name, env.Source, env.Value)
+	}
+	if info.Network.ProxyInfo.EnvironmentNote != "" {
+		fmt.Printf("  %-18s %-20s %s\n", "代理环境变量", "", info.Network.ProxyInfo.EnvironmentNote)
+	}
 
 	// 系统信息部分
 	fmt.Println("\n======================= 系统信息 =======================")
@@ -407,6 +2583,40 @@ func printSystemInfo(info model.SystemInfo) {
 		fmt.Printf("%-20s %-20s %s\n", "启动后的时间长度", "", uptime)
 	}
 
+	// 显示最近的启动和关机记录
+	if len(info.BootHistory) > 0 {
+		fmt.Printf("%-20s\n", "启动和关机记录")
+		for _, event := range info.BootHistory {
+			details := event.Reason
+			if event.Detail != "" {
+				details += "（" + event.Detail + "）"
+			}
+			fmt.Printf("  %-18s %-20s %s\n", event.Time, event.Type, details)
+		}
+	}
+
+	// 显示时区、区域和输入法
+	if info.Locale.TimeZone != "" || info.Locale.UTCOffset != "" {
+		fmt.Printf("%-20s %-20s %s\n", "时区", "", strings.TrimSpace(info.Locale.TimeZone+" (UTC"+info.Locale.UTCOffset+")"))
+	}
+	if info.Locale.Locale != "" {
+		fmt.Printf("%-20s %-20s %s\n", "区域设置", "", info.Locale.Locale)
+	}
+	if len(info.Locale.Languages) > 0 {
+		fmt.Printf("%-20s %-20s %s\n", "首选语言", "", strings.Join(info.Locale.Languages, ", "))
+	}
+	if len(info.Locale.InputSources) > 0 {
+		fmt.Printf("%-20s %-20s %s\n", "输入法", "", strings.Join(info.Locale.InputSources, ", "))
+	}
+
+	// 显示关键环境变量（敏感值已脱敏）
+	if len(info.Environment) > 0 {
+		fmt.Printf("%-20s\n", "环境变量")
+		for _, env := range info.Environment {
+			fmt.Printf("  %-18s %-20s %s\n", env.Name, "", env.Value)
+		}
+	}
+
 	// 显示蓝牙信息
 	if info.Bluetooth.IsAvailable {
 		fmt.Printf("%-20s %-20s %s\n", "蓝牙状态", "", info.Bluetooth.Status)
@@ -425,9 +2635,393 @@ func printSystemInfo(info model.SystemInfo) {
 	// 显示已安装应用（默认隐藏）
 	fmt.Printf("%-20s %-20s %s\n", "已安装应用", "", fmt.Sprintf("共 %d 个应用 (使用 -apps 参数查看详情)", len(info.InstalledApps)))
 
+	// 显示浏览器信息
+	if info.Browsers.Default != "" {
+		fmt.Printf("%-20s %-20s %s\n", "默认浏览器", "", info.Browsers.Default)
+	}
+	if len(info.Browsers.Installed) > 0 {
+		fmt.Printf("%-20s\n", "已安装浏览器")
+		for _, b := range info.Browsers.Installed {
+			details := b.Version
+			if b.Extensions >= 0 {
+				details += fmt.Sprintf("，%d 个扩展", b.Extensions)
+			}
+			if b.Proxy != "" {
+				details += "，代理：" + b.Proxy
+			}
+			fmt.Printf("  %-18s %-20s %s\n", b.Name, "", details)
+		}
+	}
+
+	// 显示协议和文件类型的默认打开程序
+	if len(info.DefaultHandlers) > 0 {
+		fmt.Printf("%-20s\n", "默认打开程序")
+		for _, handler := range info.DefaultHandlers {
+			name := handler.Handler
+			if handler.SystemDefault {
+				name += "（系统默认）"
+			}
+			fmt.Printf("  %-18s %-20s %s\n", handler.Type, name, handler.ID)
+		}
+	}
+
+	// 显示已安装的补丁
+	if len(info.Hotfixes) > 0 {
+		fmt.Printf("%-20s %-20s %s\n", "已安装补丁", "", fmt.Sprintf("共 %d 个，最近安装于 %s", len(info.Hotfixes), info.Hotfixes[0].InstalledOn))
+		for _, hotfix := range info.Hotfixes {
+			fmt.Printf("  %-18s %-20s %s\n", hotfix.ID, hotfix.InstalledOn, hotfix.Description)
+		}
+	}
+
+	// 显示Windows激活信息
+	if info.Activation.Status != "" {
+		details := info.Activation.Status
+		if info.Activation.Channel != "" {
+			details += "，" + info.Activation.Channel
+		}
+		if info.Activation.PartialKey != "" {
+			details += "，密钥 *****-" + info.Activation.PartialKey
+		}
+		if info.Activation.GraceRemaining > 0 {
+			details += fmt.Sprintf("，宽限期剩余 %d 天", info.Activation.GraceRemaining)
+		}
+		fmt.Printf("%-20s %-20s %s\n", "Windows激活", "", details)
+		if info.Activation.KMSHost != "" {
+			fmt.Printf("%-20s %-20s %s\n", "KMS服务器", "", info.Activation.KMSHost)
+		}
+	}
+
+	// 显示最近24小时的严重和错误事件
+	if len(info.EventLog.Logs) > 0 {
+		fmt.Printf("%-20s %-20s %s\n", "事件日志（24小时）", "", fmt.Sprintf("严重 %d 个，错误 %d 个", info.EventLog.Critical, info.EventLog.Errors))
+		for _, event := range info.EventLog.Recent {
+			source := fmt.Sprintf("%s/%d", event.Source, event.EventID)
+			if event.Category != "" {
+				source = "[" + event.Category + "] " + source
+			}
+			fmt.Printf("  %-18s %-20s %s %s\n", event.Time, event.Level, source, event.Message)
+		}
+	}
+
+	// 显示搜索索引状态
+	if info.SearchIndex.Service != "" {
+		details := info.SearchIndex.State
+		if info.SearchIndex.StartType != "" {
+			details += "，" + info.SearchIndex.StartType
+		}
+		if info.SearchIndex.IndexSize > 0 {
+			details += fmt.Sprintf("，索引大小 %.2f GB", float64(info.SearchIndex.IndexSize)/(1024*1024*1024))
+		}
+		details += fmt.Sprintf("，索引进程CPU %.1f%%", info.SearchIndex.IndexerCPU)
+		if info.SearchIndex.Busy {
+			details += "（正在大量索引）"
+		}
+		fmt.Printf("%-20s %-20s %s\n", "搜索索引", info.SearchIndex.Service, details)
+		for _, volume := range info.SearchIndex.Volumes {
+			fmt.Printf("  %-18s %-20s %s\n", volume.Volume, "", volume.Status)
+		}
+	}
+
+	// 显示计划任务
+	if len(info.ScheduledTasks) > 0 {
+		fmt.Printf("%-20s\n", "计划任务")
+		for _, task := range info.ScheduledTasks {
+			details := fmt.Sprintf("[%s] %s", task.Source, task.Trigger)
+			if task.LastRun != "" {
+				details += "，上次运行 " + task.LastRun
+			}
+			if task.LastResult != "" {
+				details += "，结果：" + task.LastResult
+			}
+			fmt.Printf("  %-18s %-20s %s\n", task.Name, details, task.Command)
+		}
+	}
+
 	// 显示正在运行的应用（默认隐藏）
 	fmt.Printf("%-20s %-20s %s\n", "正在运行的应用", "", fmt.Sprintf("共 %d 个进程 (使用 -procs 参数查看详情)", len(info.RunningApps)))
 
+	// 显示资源占用最高的进程
+	hogLists := []struct {
+		label string
+		hogs  []model.ProcessHogInfo
+	}{
+		{"CPU占用最高", info.ResourceHogs.TopCPU},
+		{"内存占用最高", info.ResourceHogs.TopMemory},
+		{"网络流量最高", info.ResourceHogs.TopNetwork},
+	}
+	for _, list := range hogLists {
+		if len(list.hogs) == 0 {
+			continue
+		}
+		fmt.Printf("%-20s %-20s %s\n", list.label, "", "采样 "+info.ResourceHogs.SampleWindow)
+		for _, hog := range list.hogs {
+			value := formatHogValue(hog)
+			if hog.Exceeded {
+				value += "（超过阈值）"
+			}
+			fmt.Printf("  %-18s %-20s %s\n", hog.Name, fmt.Sprintf("PID %d", hog.PID), value)
+		}
+	}
+	if len(info.ResourceHogs.Offenders) > 0 {
+		var offenders []string
+		for _, hog := range info.ResourceHogs.Offenders {
+			offenders = append(offenders, fmt.Sprintf("%s(%s %s)", hog.Name, hog.Resource, formatHogValue(hog)))
+		}
+		fmt.Printf("%-20s %-20s %s\n", "资源占用超过阈值", "", strings.Join(offenders, ", "))
+	}
+
+	// 安全状态
+	fmt.Println("\n======================= 安全状态 =======================")
+
+	// 显示防火墙状态
+	firewallStatus := "未启用"
+	if info.Firewall.Enabled {
+		firewallStatus = "已启用"
+	}
+	if runtime.GOOS == "darwin" {
+		var modes []string
+		if info.Firewall.StealthMode {
+			modes = append(modes, "隐身模式")
+		}
+		if info.Firewall.BlockAllIncoming {
+			modes = append(modes, "阻止所有传入连接")
+		}
+		if len(modes) > 0 {
+			firewallStatus += "（" + strings.Join(modes, "，") + "）"
+		}
+	}
+	fmt.Printf("%-20s %-20s %s\n", "防火墙", "", firewallStatus)
+	for _, profile := range info.Firewall.Profiles {
+		state := "未启用"
+		if profile.Enabled {
+			state = "已启用"
+		}
+		fmt.Printf("  %-18s %-20s %s\n", profile.Name, state, fmt.Sprintf("入站 %s，出站 %s", profile.DefaultInbound, profile.DefaultOutbound))
+	}
+	if runtime.GOOS == "darwin" {
+		pfStatus := "未启用"
+		if info.Firewall.PFEnabled {
+			pfStatus = "已启用"
+		}
+		fmt.Printf("%-20s %-20s %s\n", "pf包过滤", "", pfStatus)
+	}
+	fmt.Printf("%-20s %-20s %d\n", "入站允许规则", "", info.Firewall.InboundAllowRules)
+
+	// 显示macOS平台安全设置
+	if info.PlatformSecurity.SIPStatus != "" {
+		fmt.Printf("%-20s %-20s %s\n", "系统完整性保护", "", info.PlatformSecurity.SIPStatus)
+	}
+	if info.PlatformSecurity.Gatekeeper != "" {
+		fmt.Printf("%-20s %-20s %s\n", "Gatekeeper", "", info.PlatformSecurity.Gatekeeper)
+	}
+	if info.PlatformSecurity.XProtectVersion != "" {
+		fmt.Printf("%-20s %-20s %s\n", "XProtect版本", "", info.PlatformSecurity.XProtectVersion)
+	}
+	if info.PlatformSecurity.ThirdPartyKexts != "" {
+		fmt.Printf("%-20s %-20s %s\n", "第三方内核扩展", "", info.PlatformSecurity.ThirdPartyKexts)
+	}
+
+	// 显示防病毒和EDR产品
+	if len(info.Antivirus) > 0 {
+		fmt.Printf("%-20s\n", "防病毒/EDR")
+		for _, product := range info.Antivirus {
+			state := "未运行"
+			if product.Running {
+				state = "运行中"
+			}
+			details := fmt.Sprintf("%s %s，实时保护：%s", product.Version, state, product.RealTime)
+			if product.Definitions != "" {
+				details += "，病毒库：" + product.Definitions
+			}
+			fmt.Printf("  %-18s %-20s %s\n", product.Kind, product.Name, details)
+		}
+	} else {
+		fmt.Printf("%-20s %-20s %s\n", "防病毒/EDR", "", "未检测到")
+	}
+
+	// 显示目录/身份加入状态
+	var joins []string
+	if info.Identity.DomainJoined {
+		joins = append(joins, "AD域 "+info.Identity.Domain)
+	}
+	if info.Identity.AzureADJoined {
+		joins = append(joins, "Entra ID加入 "+info.Identity.TenantName)
+	} else if info.Identity.WorkplaceJoined {
+		joins = append(joins, "Entra ID注册 "+info.Identity.TenantName)
+	}
+	if info.Identity.EnterpriseJoined {
+		joins = append(joins, "企业设备注册")
+	}
+	if len(joins) == 0 {
+		joins = append(joins, "未加入目录")
+	}
+	fmt.Printf("%-20s %-20s %s\n", "身份加入状态", "", strings.Join(joins, "，"))
+	if info.Identity.PlatformSSO != "" {
+		fmt.Printf("%-20s %-20s %s\n", "平台单点登录", "", info.Identity.PlatformSSO)
+	}
+
+	// 显示MDM管理状态和配置描述文件
+	if info.Management.Enrolled {
+		fmt.Printf("%-20s %-20s %s\n", "MDM注册", "", info.Management.Provider)
+		if info.Management.ServerURL != "" {
+			fmt.Printf("%-20s %-20s %s\n", "MDM服务器", "", info.Management.ServerURL)
+		}
+		if info.Management.Compliance != "" {
+			fmt.Printf("%-20s %-20s %s\n", "合规状态", "", info.Management.Compliance)
+		}
+	} else {
+		fmt.Printf("%-20s %-20s %s\n", "MDM注册", "", "未注册")
+	}
+	if len(info.Management.Profiles) > 0 {
+		fmt.Printf("%-20s\n", "配置描述文件")
+		for _, profile := range info.Management.Profiles {
+			fmt.Printf("  %-18s %-20s %s\n", profile.Name, profile.Scope, strings.Join(profile.PayloadTypes, ", "))
+		}
+	}
+
+	// 显示证书，标记30天内到期和已过期的证书
+	if len(info.Certificates) > 0 {
+		expiring := 0
+		for _, cert := range info.Certificates {
+			if cert.ExpiringSoon || cert.Expired {
+				expiring++
+			}
+		}
+		fmt.Printf("%-20s %-20s %s\n", "证书", "", fmt.Sprintf("共 %d 个，%d 个即将到期或已过期", len(info.Certificates), expiring))
+		for _, cert := range info.Certificates {
+			status := fmt.Sprintf("%s到期（剩余 %d 天）", cert.NotAfter, cert.DaysLeft)
+			if cert.Expired {
+				status = fmt.Sprintf("%s已过期", cert.NotAfter)
+			} else if cert.ExpiringSoon {
+				status += "，即将到期"
+			}
+			fmt.Printf("  %-18s %-20s %s\n", cert.Subject, cert.Purpose, status)
+		}
+	}
+
+	// 显示本地账户
+	if len(info.LocalAccounts) > 0 {
+		admins := 0
+		for _, account := range info.LocalAccounts {
+			if account.IsAdmin && !account.Disabled {
+				admins++
+			}
+		}
+		fmt.Printf("%-20s %-20s %s\n", "本地账户", "", fmt.Sprintf("共 %d 个，启用的管理员 %d 个", len(info.LocalAccounts), admins))
+		for _, account := range info.LocalAccounts {
+			var flags []string
+			if account.IsAdmin {
+				flags = append(flags, "管理员")
+			}
+			if account.Disabled {
+				flags = append(flags, "已禁用")
+			}
+			if account.NoPassword {
+				flags = append(flags, "无密码")
+			}
+			lastLogin := account.LastLogin
+			if lastLogin == "" {
+				lastLogin = "从未登录"
+			}
+			fmt.Printf("  %-18s %-20s %s\n", account.Name, strings.Join(flags, "，"), "上次登录："+lastLogin)
+		}
+	}
+
+	// 显示远程访问服务状态
+	for _, access := range info.RemoteAccess {
+		if !access.Enabled {
+			fmt.Printf("%-20s %-20s %s\n", access.Name, "", "未启用")
+			continue
+		}
+		var ports []string
+		for _, port := range access.Ports {
+			ports = append(ports, strconv.Itoa(port))
+		}
+		users := "所有用户"
+		if len(access.AllowedUsers) > 0 {
+			users = strings.Join(access.AllowedUsers, ", ")
+		}
+		fmt.Printf("%-20s %-20s %s\n", access.Name, "", fmt.Sprintf("已启用，端口 %s，允许：%s", strings.Join(ports, "/"), users))
+	}
+
+	// 显示文件共享状态
+	if info.FileSharing.Enabled {
+		fmt.Printf("%-20s %-20s %s\n", "文件共享", "", "已启用（"+strings.Join(info.FileSharing.Protocols, ", ")+"）")
+	} else {
+		fmt.Printf("%-20s %-20s %s\n", "文件共享", "", "未启用")
+	}
+	for _, share := range info.FileSharing.Shares {
+		details := share.Path + " " + share.Permissions
+		if share.GuestAccess {
+			details += "，允许访客访问"
+		}
+		fmt.Printf("  %-18s %-20s %s\n", share.Name, share.Protocols, details)
+	}
+
+	// 显示服务信息
+	if len(info.Services) > 0 {
+		fmt.Println("\n======================= 服务 =======================")
+		running := 0
+		var stopped []model.ServiceInfo
+		for _, service := range info.Services {
+			if service.PID > 0 || service.State == "运行中" {
+				running++
+			} else if service.StartType == "自动" && service.State != "未知" && service.State != "已加载" {
+				stopped = append(stopped, service)
+			}
+		}
+		fmt.Printf("%-20s %-20s %s\n", "服务数量", "", fmt.Sprintf("共 %d 个，运行中 %d 个", len(info.Services), running))
+		if len(stopped) > 0 {
+			fmt.Printf("%-20s\n", "自动启动但未运行")
+			for _, service := range stopped {
+				name := service.DisplayName
+				if name == "" {
+					name = service.Name
+				}
+				fmt.Printf("  %-18s %-20s %s\n", name, service.Kind, service.State)
+			}
+		}
+	}
+
+	// 显示启动项
+	if len(info.StartupItems) > 0 {
+		fmt.Println("\n======================= 启动项 =======================")
+		for _, item := range info.StartupItems {
+			fmt.Printf("  %-18s %-20s %s\n", item.Name, item.Location, item.Target)
+		}
+	}
+
+	// 显示运行中的容器和虚拟机
+	if len(info.Workloads) > 0 {
+		fmt.Println("\n======================= 容器和虚拟机 =======================")
+		var totalMemory uint64
+		for _, w := range info.Workloads {
+			details := w.State
+			if w.CPUs > 0 {
+				details += fmt.Sprintf("，%g 个CPU", w.CPUs)
+			}
+			if w.Memory > 0 {
+				details += fmt.Sprintf("，内存 %.1f GB", float64(w.Memory)/(1024*1024*1024))
+				// 容器的内存限制已包含在所在虚拟机的分配中
+				if !strings.HasSuffix(w.Kind, "容器") {
+					totalMemory += w.Memory
+				}
+			}
+			fmt.Printf("  %-18s %-20s %s\n", w.Name, w.Kind, details)
+		}
+		if totalMemory > 0 {
+			fmt.Printf("%-20s %-20s %s\n", "虚拟机内存合计", "", fmt.Sprintf("%.1f GB", float64(totalMemory)/(1024*1024*1024)))
+		}
+	}
+
+	// 显示开发工具
+	if len(info.DevTools) > 0 {
+		fmt.Println("\n======================= 开发工具 =======================")
+		for _, tool := range info.DevTools {
+			fmt.Printf("  %-18s %-20s %s\n", tool.Name, tool.Version, tool.Path)
+		}
+	}
+
 	// 如果有命令行参数 --json，则输出 JSON 格式
 	if len(os.Args) > 1 && strings.Contains(os.Args[1], "--json") {
 		jsonOutput, err := json.MarshalIndent(info, "", "  ")
@@ -439,6 +3033,18 @@ func printSystemInfo(info model.SystemInfo) {
 	}
 }
 
+// formatHogValue 按资源类型格式化进程的资源占用
+func formatHogValue(hog model.ProcessHogInfo) string {
+	switch hog.Resource {
+	case analysis.ResourceMemory:
+		return fmt.Sprintf("%.2f GB", hog.Value/(1024*1024*1024))
+	case analysis.ResourceNetwork:
+		return fmt.Sprintf("%.1f KB/s", hog.Value/1024)
+	default:
+		return fmt.Sprintf("%.1f%%", hog.Value)
+	}
+}
+
 // formatSystemInfo 将系统信息格式化为指定的输出格式
 func formatSystemInfo(info model.SystemInfo) string {
 	var sb strings.Builder
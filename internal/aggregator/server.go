@@ -0,0 +1,547 @@
+package aggregator
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	sysspectorv1 "github.com/AsterZephyr/SysSpector/api/sysspector/v1"
+	"github.com/AsterZephyr/SysSpector/internal/sink"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// 汇总服务的参数
+const (
+	maxSnapshotSize    = 32 << 20 // 解压后的快照的最大长度
+	defaultSnapshotAge = 30 * 24 * time.Hour
+	shutdownTimeout    = 5 * time.Second
+)
+
+// csvHeader 导出的CSV的表头，与Device的字段顺序相同
+var csvHeader = []string{"DeviceID", "Hostname", "OS", "SystemVersion", "Model", "SerialNumber", "FirmwareVendor",
+	"FirmwareVersion", "FirmwareDate", "IP", "PublicIP", "HealthScore", "CollectedAt", "ReceivedAt"}
+
+// errorResponse 表示请求失败时返回的JSON
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server 表示汇总服务：接收设备推送的快照（与push的请求格式相同），提供设备清单的查询和导出
+type Server struct {
+	store  *Store
+	tokens []string
+	tls    *tls.Config
+}
+
+// NewServer 创建汇总服务，tokens为查询和注册设备使用的Bearer令牌，为空时不校验
+func NewServer(store *Store, tokens []string) *Server {
+	return &Server{store: store, tokens: tokens}
+}
+
+// SetTLSConfig 设置服务使用的TLS配置，为nil时不使用TLS
+func (s *Server) SetTLSConfig(tlsConfig *tls.Config) {
+	s.tls = tlsConfig
+}
+
+// Handler 返回汇总服务的路由：
+//
+//	POST /v1/enroll                  注册设备，签发绑定设备ID的设备令牌，作为客户端device.enroll_url
+//	POST /v1/snapshots               接收设备推送的快照，作为客户端push.url
+//	GET  /v1/devices                 设备清单，按查询参数过滤，format=csv时导出CSV
+//	GET  /v1/devices/{id}            设备的最新快照，at参数（RFC 3339格式）指定时返回该时间之前最近的历史快照
+//	GET  /v1/devices/{id}/snapshots  设备在[from, to]内的历史快照的采集时间，默认为最近30天
+//
+// 配置了令牌时所有请求都需要带有Authorization: Bearer 令牌，推送快照使用设备令牌，其他请求使用tokens中的令牌
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/enroll", s.handleEnroll)
+	mux.HandleFunc("/v1/snapshots", s.handleIngest)
+	mux.HandleFunc("/v1/devices", s.handleDevices)
+	mux.HandleFunc("/v1/devices/", s.handleDevice)
+	return s.authorize(mux)
+}
+
+// authorize 校验请求的Bearer令牌，推送快照时也接受设备令牌
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(s.tokens) > 0 {
+			token := bearerToken(r)
+			authorized := s.fleetToken(token)
+			if !authorized && token != "" && r.URL.Path == "/v1/snapshots" {
+				_, err := s.store.CredentialDevice(tokenCredential(token))
+				authorized = err == nil
+			}
+			if !authorized {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthorized"})
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bearerToken 返回请求的Bearer令牌，没有时返回空字符串
+func bearerToken(r *http.Request) string {
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+// fleetToken 判断token是否为tokens中的令牌
+func (s *Server) fleetToken(token string) bool {
+	authorized := false
+	for _, allowed := range s.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(allowed)) == 1 {
+			authorized = true
+		}
+	}
+	return authorized
+}
+
+// tokenCredential 返回设备令牌对应的凭据，数据库中只保存令牌的摘要
+func tokenCredential(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(digest[:])
+}
+
+// certCredential 返回客户端证书对应的凭据（公钥的摘要），证书续期时保留公钥即可继续使用
+func certCredential(cert *x509.Certificate) string {
+	digest := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "cert:" + base64.StdEncoding.EncodeToString(digest[:])
+}
+
+// enrollRequest 表示注册请求，与客户端的device.Registration相同
+type enrollRequest struct {
+	DeviceID string `json:"device_id"`
+	Hostname string `json:"hostname"`
+}
+
+// enrollResponse 表示注册结果，客户端没有配置push.token时使用设备令牌推送快照
+type enrollResponse struct {
+	DeviceID string `json:"device_id"`
+	Token    string `json:"token"`
+}
+
+// handleEnroll 为设备签发设备令牌并绑定设备ID，之后按设备令牌确定推送的快照属于哪台设备；
+// 设备已经注册时返回409，需要先在汇总服务上执行 sysinfo aggregate --unenroll 设备ID
+func (s *Server) handleEnroll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+	var req enrollRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid registration: %v", err)})
+		return
+	}
+	if req.DeviceID == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "registration has no device ID"})
+		return
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+	if err := s.store.Enroll(req.DeviceID, tokenCredential(token)); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrEnrolled) {
+			status = http.StatusConflict
+		}
+		writeJSON(w, status, errorResponse{Error: err.Error()})
+		return
+	}
+	log.Printf("Enrolled device %s (%s)", req.DeviceID, req.Hostname)
+	writeJSON(w, http.StatusOK, enrollResponse{DeviceID: req.DeviceID, Token: token})
+}
+
+// tokenDevice 返回请求的设备令牌绑定的设备ID，请求没有使用设备令牌时返回空字符串
+func (s *Server) tokenDevice(r *http.Request) (string, error) {
+	token := bearerToken(r)
+	if token == "" || s.fleetToken(token) {
+		return "", nil
+	}
+	deviceID, err := s.store.CredentialDevice(tokenCredential(token))
+	if errors.Is(err, ErrNotFound) {
+		return "", nil
+	}
+	return deviceID, err
+}
+
+// handleIngest 接收一个快照：完整快照为JSON或protobuf（Snapshot消息），增量快照为相对基准快照的JSON合并补丁，
+// 基准快照与设备的最新快照不一致时返回409，客户端下次会发送完整快照
+func (s *Server) handleIngest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+	body, err := readBody(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	collectedAt := time.Now()
+	if value := r.Header.Get(sink.HeaderCollectedAt); value != "" {
+		if collectedAt, err = time.Parse(time.RFC3339Nano, value); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid %s: %v", sink.HeaderCollectedAt, err)})
+			return
+		}
+	}
+	// 使用设备令牌时设备ID由令牌确定；使用客户端证书时设备ID在第一次推送时绑定到证书；
+	// 配置了令牌时不接受只使用tokens中的令牌推送的快照，否则任何设备都可以覆盖其他设备的快照
+	deviceID := r.Header.Get(sink.HeaderDeviceID)
+	tokenDeviceID, err := s.tokenDevice(r)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	cert := clientCertificate(r)
+	switch {
+	case tokenDeviceID != "":
+		if deviceID != "" && deviceID != tokenDeviceID {
+			writeJSON(w, http.StatusForbidden, errorResponse{Error: "device ID does not match the device token"})
+			return
+		}
+		deviceID = tokenDeviceID
+	case cert == nil && len(s.tokens) > 0:
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: "pushing snapshots requires a device token from /v1/enroll or a client certificate"})
+		return
+	}
+
+	var info model.SystemInfo
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		err = json.Unmarshal(body, &info)
+	case "application/x-protobuf":
+		var snapshot sysspectorv1.Snapshot
+		if err = proto.Unmarshal(body, &snapshot); err == nil {
+			err = json.Unmarshal(snapshot.SystemJson, &info)
+		}
+	case "application/merge-patch+json":
+		status, patchErr := s.applyPatch(r, deviceID, body, &info)
+		if patchErr != nil {
+			writeJSON(w, status, errorResponse{Error: patchErr.Error()})
+			return
+		}
+	default:
+		writeJSON(w, http.StatusUnsupportedMediaType, errorResponse{Error: fmt.Sprintf("unsupported content type %q", mediaType)})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid snapshot: %v", err)})
+		return
+	}
+
+	// 补发的暂存快照没有设备ID请求头，从快照中取设备ID，没有时使用主机名
+	if deviceID == "" {
+		deviceID = info.DeviceID
+	}
+	if deviceID == "" {
+		deviceID = info.Hostname
+	}
+	if deviceID == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "snapshot has no device ID or hostname"})
+		return
+	}
+	if tokenDeviceID == "" && cert != nil {
+		if err := s.store.Bind(deviceID, certCredential(cert)); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, ErrCredentialMismatch) {
+				status = http.StatusForbidden
+			}
+			writeJSON(w, status, errorResponse{Error: err.Error()})
+			return
+		}
+	}
+	if err := s.store.Save(deviceID, info, collectedAt); err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// clientCertificate 返回请求的客户端证书，没有使用mTLS时返回nil
+func clientCertificate(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	return r.TLS.PeerCertificates[0]
+}
+
+// applyPatch 将增量快照合并到设备的最新快照上，失败时返回响应的状态码
+func (s *Server) applyPatch(r *http.Request, deviceID string, patch []byte, info *model.SystemInfo) (int, error) {
+	if deviceID == "" {
+		return http.StatusBadRequest, fmt.Errorf("merge patch requires %s", sink.HeaderDeviceID)
+	}
+	baseTime, err := time.Parse(time.RFC3339Nano, r.Header.Get(sink.HeaderBase))
+	if err != nil {
+		return http.StatusBadRequest, fmt.Errorf("invalid %s: %v", sink.HeaderBase, err)
+	}
+	base, latestAt, err := s.store.Latest(deviceID)
+	if errors.Is(err, ErrNotFound) || (err == nil && !latestAt.Equal(baseTime)) {
+		return http.StatusConflict, errors.New("base snapshot does not match, send a full snapshot")
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if *info, err = model.Patch(base, patch); err != nil {
+		return http.StatusBadRequest, fmt.Errorf("invalid merge patch: %v", err)
+	}
+	return 0, nil
+}
+
+// readBody 读取请求体，Content-Encoding为gzip时解压
+func readBody(r *http.Request) ([]byte, error) {
+	var reader io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+	body, err := io.ReadAll(io.LimitReader(reader, maxSnapshotSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxSnapshotSize {
+		return nil, errors.New("snapshot is too large")
+	}
+	return body, nil
+}
+
+// handleDevices 返回符合查询参数的设备清单，参数与Filter的字段对应：
+// os、model、hostname、serial、firmware_before、firmware_after、seen_before、seen_after、health_below
+func (s *Server) handleDevices(w http.ResponseWriter, r *http.Request) {
+	if !checkGet(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	filter := Filter{
+		OS:             query.Get("os"),
+		Model:          query.Get("model"),
+		Hostname:       query.Get("hostname"),
+		SerialNumber:   query.Get("serial"),
+		FirmwareBefore: query.Get("firmware_before"),
+		FirmwareAfter:  query.Get("firmware_after"),
+	}
+	for _, param := range []struct {
+		name  string
+		value *time.Time
+	}{{"seen_before", &filter.SeenBefore}, {"seen_after", &filter.SeenAfter}} {
+		if text := query.Get(param.name); text != "" {
+			t, err := time.Parse(time.RFC3339, text)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid %s: %v", param.name, err)})
+				return
+			}
+			*param.value = t
+		}
+	}
+	if text := query.Get("health_below"); text != "" {
+		score, err := strconv.Atoi(text)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid health_below: %v", err)})
+			return
+		}
+		filter.HealthBelow = score
+	}
+
+	devices, err := s.store.Devices(filter)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	switch query.Get("format") {
+	case "", "json":
+		writeJSON(w, http.StatusOK, devices)
+	case "csv":
+		writeCSV(w, devices)
+	default:
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "format must be json or csv"})
+	}
+}
+
+// writeCSV 以CSV格式导出设备清单
+func writeCSV(w http.ResponseWriter, devices []Device) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="fleet.csv"`)
+	writer := csv.NewWriter(w)
+	writer.Write(csvHeader)
+	for _, d := range devices {
+		writer.Write([]string{d.DeviceID, d.Hostname, d.OS, d.SystemVersion, d.Model, d.SerialNumber, d.FirmwareVendor,
+			d.FirmwareVersion, d.FirmwareDate, d.IP, d.PublicIP, strconv.Itoa(d.HealthScore),
+			d.CollectedAt.UTC().Format(time.RFC3339), d.ReceivedAt.UTC().Format(time.RFC3339)})
+	}
+	writer.Flush()
+}
+
+// handleDevice 返回一台设备的快照或历史快照列表
+func (s *Server) handleDevice(w http.ResponseWriter, r *http.Request) {
+	if !checkGet(w, r) {
+		return
+	}
+	deviceID, sub, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/v1/devices/"), "/")
+	switch sub {
+	case "":
+		s.handleDeviceSnapshot(w, r, deviceID)
+	case "snapshots":
+		s.handleDeviceSnapshots(w, r, deviceID)
+	default:
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "not found"})
+	}
+}
+
+// handleDeviceSnapshot 返回设备的最新快照或at参数之前最近的历史快照，响应头Last-Modified为采集时间
+func (s *Server) handleDeviceSnapshot(w http.ResponseWriter, r *http.Request, deviceID string) {
+	var info model.SystemInfo
+	var collectedAt time.Time
+	var err error
+	if value := r.URL.Query().Get("at"); value != "" {
+		at, parseErr := time.Parse(time.RFC3339, value)
+		if parseErr != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid at: %v", parseErr)})
+			return
+		}
+		info, collectedAt, err = s.store.Snapshot(deviceID, at)
+	} else {
+		info, collectedAt, err = s.store.Latest(deviceID)
+	}
+	if errors.Is(err, ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	w.Header().Set("Last-Modified", collectedAt.UTC().Format(http.TimeFormat))
+	writeJSON(w, http.StatusOK, info)
+}
+
+// handleDeviceSnapshots 返回设备在[from, to]内的历史快照的采集时间
+func (s *Server) handleDeviceSnapshots(w http.ResponseWriter, r *http.Request, deviceID string) {
+	to := time.Now()
+	from := to.Add(-defaultSnapshotAge)
+	for _, param := range []struct {
+		name  string
+		value *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		if text := r.URL.Query().Get(param.name); text != "" {
+			t, err := time.Parse(time.RFC3339, text)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid %s: %v", param.name, err)})
+				return
+			}
+			*param.value = t
+		}
+	}
+	times, err := s.store.Snapshots(deviceID, from, to)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, times)
+}
+
+// checkGet 检查请求方法，不是GET时写入错误响应并返回false
+func checkGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return false
+	}
+	return true
+}
+
+// writeJSON 以JSON格式写入响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+// ListenAndServe 在指定地址上提供汇总服务，设置了TLS配置时使用HTTPS，直到ctx被取消。
+// 没有配置令牌也不要求客户端证书时只能监听本机地址
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	if len(s.tokens) == 0 && !s.requiresClientCert() && !loopback(addr) {
+		return fmt.Errorf("refusing to serve on %s without aggregate tokens or client certificates, listen on a loopback address or configure aggregate.tokens or aggregate.tls", addr)
+	}
+	httpServer := &http.Server{Addr: addr, Handler: s.Handler(), TLSConfig: s.tls, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+	var err error
+	if s.tls != nil {
+		err = httpServer.ListenAndServeTLS("", "")
+	} else {
+		err = httpServer.ListenAndServe()
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// requiresClientCert 判断是否要求客户端提供证书（mTLS）
+func (s *Server) requiresClientCert() bool {
+	return s.tls != nil && (s.tls.ClientAuth == tls.RequireAnyClientCert || s.tls.ClientAuth == tls.RequireAndVerifyClientCert)
+}
+
+// loopback 判断监听地址是否只能从本机访问
+func loopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// RunCompaction 每小时删除一次超过保留期限的历史快照，直到ctx取消
+func (s *Server) RunCompaction(ctx context.Context, retention time.Duration) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		if err := s.store.Compact(time.Now().Add(-retention)); err != nil {
+			log.Printf("Error compacting snapshots: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,348 @@
+// Package aggregator 汇总多台设备推送的快照：保存每台设备的最新快照和历史快照，
+// 提供设备清单的查询和CSV/JSON导出，与客户端一起构成最小的资产清单系统
+package aggregator
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// ErrNotFound 表示没有该设备或指定时间之前没有保存快照
+var ErrNotFound = errors.New("no snapshot found")
+
+// 设备凭据的错误
+var (
+	ErrEnrolled           = errors.New("device is already enrolled")
+	ErrCredentialMismatch = errors.New("device ID is bound to a different credential")
+)
+
+// devices保存每台设备的最新快照和用于查询的字段，snapshots保存历史快照，
+// credentials保存设备ID绑定的凭据（设备令牌的摘要或客户端证书公钥的摘要）
+const schema = `
+CREATE TABLE IF NOT EXISTS devices (
+	device_id        TEXT PRIMARY KEY,
+	hostname         TEXT NOT NULL,
+	os               TEXT NOT NULL,
+	system_version   TEXT NOT NULL,
+	model            TEXT NOT NULL,
+	serial_number    TEXT NOT NULL,
+	firmware_vendor  TEXT NOT NULL,
+	firmware_version TEXT NOT NULL,
+	firmware_date    TEXT NOT NULL,
+	ip               TEXT NOT NULL,
+	public_ip        TEXT NOT NULL,
+	health_score     INTEGER NOT NULL,
+	collected_at     INTEGER NOT NULL,
+	received_at      INTEGER NOT NULL,
+	data             TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS snapshots (
+	device_id    TEXT NOT NULL,
+	collected_at INTEGER NOT NULL,
+	data         TEXT NOT NULL,
+	PRIMARY KEY (device_id, collected_at)
+);
+CREATE TABLE IF NOT EXISTS credentials (
+	device_id  TEXT PRIMARY KEY,
+	credential TEXT NOT NULL UNIQUE,
+	created_at INTEGER NOT NULL
+);
+`
+
+// deviceColumns 设备清单查询的列，与Device的字段顺序相同
+const deviceColumns = "device_id, hostname, os, system_version, model, serial_number, firmware_vendor, firmware_version, " +
+	"firmware_date, ip, public_ip, health_score, collected_at, received_at"
+
+// Device 表示设备清单中的一台设备，字段取自最新的快照
+type Device struct {
+	DeviceID        string
+	Hostname        string
+	OS              string
+	SystemVersion   string
+	Model           string
+	SerialNumber    string
+	FirmwareVendor  string
+	FirmwareVersion string
+	FirmwareDate    string // 固件发布日期（YYYY-MM-DD），macOS通常为空
+	IP              string
+	PublicIP        string
+	HealthScore     int       // 网络健康评分
+	CollectedAt     time.Time // 最新快照的采集时间
+	ReceivedAt      time.Time // 最新快照的接收时间
+}
+
+// Filter 表示设备清单的查询条件，为零值的条件不参与过滤
+type Filter struct {
+	OS             string    // 操作系统，不区分大小写
+	Model          string    // 型号，不区分大小写
+	Hostname       string    // 主机名包含的文本
+	SerialNumber   string    // 序列号包含的文本
+	FirmwareBefore string    // 固件发布日期早于该日期（YYYY-MM-DD），没有发布日期的设备不匹配
+	FirmwareAfter  string    // 固件发布日期不早于该日期
+	SeenBefore     time.Time // 最新快照的采集时间早于该时间，用于找出长时间没有上报的设备
+	SeenAfter      time.Time // 最新快照的采集时间不早于该时间
+	HealthBelow    int       // 网络健康评分低于该值
+}
+
+// Store 表示汇总服务的SQLite数据库
+type Store struct {
+	db *sql.DB
+}
+
+// DefaultDBPath 返回汇总数据库的默认路径
+func DefaultDBPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "SysSpector", "fleet.db"), nil
+}
+
+// OpenStore 打开汇总数据库，不存在时创建
+func OpenStore(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite3", "file:"+path+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close 关闭数据库
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Save 保存一台设备的快照，补发的较早快照只加入历史快照，不会替换设备的最新快照
+func (s *Store) Save(deviceID string, info model.SystemInfo, collectedAt time.Time) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("INSERT OR REPLACE INTO snapshots (device_id, collected_at, data) VALUES (?, ?, ?)",
+		deviceID, collectedAt.UnixNano(), string(data)); err != nil {
+		return err
+	}
+	_, err = tx.Exec("INSERT INTO devices ("+deviceColumns+", data) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) "+
+		"ON CONFLICT (device_id) DO UPDATE SET hostname = excluded.hostname, os = excluded.os, "+
+		"system_version = excluded.system_version, model = excluded.model, serial_number = excluded.serial_number, "+
+		"firmware_vendor = excluded.firmware_vendor, firmware_version = excluded.firmware_version, "+
+		"firmware_date = excluded.firmware_date, ip = excluded.ip, public_ip = excluded.public_ip, "+
+		"health_score = excluded.health_score, collected_at = excluded.collected_at, "+
+		"received_at = excluded.received_at, data = excluded.data "+
+		"WHERE excluded.collected_at >= devices.collected_at",
+		deviceID, info.Hostname, info.OS, info.SystemVersion, info.Model, info.SerialNumber,
+		info.Firmware.Vendor, info.Firmware.Version, info.Firmware.ReleaseDate, info.Network.IP, info.Network.PublicIP,
+		info.Network.HealthScore.Score, collectedAt.UnixNano(), time.Now().UnixNano(), string(data))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Enroll 将设备ID绑定到注册时签发的凭据，设备已经绑定了凭据时返回ErrEnrolled
+func (s *Store) Enroll(deviceID, credential string) error {
+	result, err := s.db.Exec("INSERT INTO credentials (device_id, credential, created_at) VALUES (?, ?, ?) "+
+		"ON CONFLICT (device_id) DO NOTHING", deviceID, credential, time.Now().UnixNano())
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrEnrolled
+	}
+	return nil
+}
+
+// Bind 检查设备ID与凭据的绑定：设备ID第一次出现时绑定到该凭据，之后只能使用同一个凭据，
+// 一个凭据也只能用于一台设备，不一致时返回ErrCredentialMismatch
+func (s *Store) Bind(deviceID, credential string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var bound string
+	err = tx.QueryRow("SELECT device_id FROM credentials WHERE credential = ?", credential).Scan(&bound)
+	if err == nil {
+		if bound != deviceID {
+			return ErrCredentialMismatch
+		}
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if err := tx.QueryRow("SELECT device_id FROM credentials WHERE device_id = ?", deviceID).Scan(&bound); err == nil {
+		return ErrCredentialMismatch
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if _, err := tx.Exec("INSERT INTO credentials (device_id, credential, created_at) VALUES (?, ?, ?)",
+		deviceID, credential, time.Now().UnixNano()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CredentialDevice 返回凭据绑定的设备ID，没有时返回ErrNotFound
+func (s *Store) CredentialDevice(credential string) (string, error) {
+	var deviceID string
+	err := s.db.QueryRow("SELECT device_id FROM credentials WHERE credential = ?", credential).Scan(&deviceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return deviceID, err
+}
+
+// Unenroll 删除设备绑定的凭据，之后设备可以重新注册或使用新的客户端证书，没有绑定凭据时返回ErrNotFound
+func (s *Store) Unenroll(deviceID string) error {
+	result, err := s.db.Exec("DELETE FROM credentials WHERE device_id = ?", deviceID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Latest 返回设备的最新快照及其采集时间，没有该设备时返回ErrNotFound
+func (s *Store) Latest(deviceID string) (model.SystemInfo, time.Time, error) {
+	return s.snapshot("SELECT collected_at, data FROM devices WHERE device_id = ?", deviceID)
+}
+
+// Snapshot 返回设备在at及之前最近的一个历史快照及其采集时间，没有时返回ErrNotFound
+func (s *Store) Snapshot(deviceID string, at time.Time) (model.SystemInfo, time.Time, error) {
+	return s.snapshot("SELECT collected_at, data FROM snapshots WHERE device_id = ? AND collected_at <= ? "+
+		"ORDER BY collected_at DESC LIMIT 1", deviceID, at.UnixNano())
+}
+
+// snapshot 执行返回采集时间和快照的查询
+func (s *Store) snapshot(query string, args ...interface{}) (model.SystemInfo, time.Time, error) {
+	var info model.SystemInfo
+	var nanos int64
+	var data string
+	err := s.db.QueryRow(query, args...).Scan(&nanos, &data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return info, time.Time{}, ErrNotFound
+	}
+	if err != nil {
+		return info, time.Time{}, err
+	}
+	if err := json.Unmarshal([]byte(data), &info); err != nil {
+		return info, time.Time{}, err
+	}
+	return info, time.Unix(0, nanos), nil
+}
+
+// Snapshots 按时间顺序返回设备在[from, to]内的历史快照的采集时间
+func (s *Store) Snapshots(deviceID string, from, to time.Time) ([]time.Time, error) {
+	rows, err := s.db.Query("SELECT collected_at FROM snapshots WHERE device_id = ? AND collected_at BETWEEN ? AND ? "+
+		"ORDER BY collected_at", deviceID, from.UnixNano(), to.UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	times := make([]time.Time, 0)
+	for rows.Next() {
+		var nanos int64
+		if err := rows.Scan(&nanos); err != nil {
+			return nil, err
+		}
+		times = append(times, time.Unix(0, nanos))
+	}
+	return times, rows.Err()
+}
+
+// Devices 按主机名顺序返回符合条件的设备
+func (s *Store) Devices(filter Filter) ([]Device, error) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		conditions = append(conditions, condition)
+		args = append(args, arg)
+	}
+	if filter.OS != "" {
+		add("os = ? COLLATE NOCASE", filter.OS)
+	}
+	if filter.Model != "" {
+		add("model = ? COLLATE NOCASE", filter.Model)
+	}
+	if filter.Hostname != "" {
+		add("instr(lower(hostname), lower(?)) > 0", filter.Hostname)
+	}
+	if filter.SerialNumber != "" {
+		add("instr(lower(serial_number), lower(?)) > 0", filter.SerialNumber)
+	}
+	if filter.FirmwareBefore != "" {
+		add("firmware_date != '' AND firmware_date < ?", filter.FirmwareBefore)
+	}
+	if filter.FirmwareAfter != "" {
+		add("firmware_date >= ?", filter.FirmwareAfter)
+	}
+	if !filter.SeenBefore.IsZero() {
+		add("collected_at < ?", filter.SeenBefore.UnixNano())
+	}
+	if !filter.SeenAfter.IsZero() {
+		add("collected_at >= ?", filter.SeenAfter.UnixNano())
+	}
+	if filter.HealthBelow > 0 {
+		add("health_score < ?", filter.HealthBelow)
+	}
+	query := "SELECT " + deviceColumns + " FROM devices"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	rows, err := s.db.Query(query+" ORDER BY hostname, device_id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	devices := make([]Device, 0)
+	for rows.Next() {
+		var d Device
+		var collectedAt, receivedAt int64
+		if err := rows.Scan(&d.DeviceID, &d.Hostname, &d.OS, &d.SystemVersion, &d.Model, &d.SerialNumber,
+			&d.FirmwareVendor, &d.FirmwareVersion, &d.FirmwareDate, &d.IP, &d.PublicIP, &d.HealthScore,
+			&collectedAt, &receivedAt); err != nil {
+			return nil, err
+		}
+		d.CollectedAt, d.ReceivedAt = time.Unix(0, collectedAt), time.Unix(0, receivedAt)
+		devices = append(devices, d)
+	}
+	return devices, rows.Err()
+}
+
+// Compact 删除before之前的历史快照，每台设备的最新快照保存在设备清单中，不受影响
+func (s *Store) Compact(before time.Time) error {
+	_, err := s.db.Exec("DELETE FROM snapshots WHERE collected_at < ?", before.UnixNano())
+	return err
+}
@@ -0,0 +1,48 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// 电池健康度阈值（百分比），参考苹果和主流厂商的保修标准
+const (
+	batteryGoodThreshold = 80.0
+	batteryFairThreshold = 60.0
+)
+
+// EvaluateBatteryHealth 根据设计容量和满充容量计算电池健康度并给出更换建议
+// 如果平台只提供了健康百分比（如macOS的Maximum Capacity），可以直接设置HealthPercent后调用
+func EvaluateBatteryHealth(battery *model.BatteryInfo) {
+	if !battery.IsPresent {
+		return
+	}
+
+	// 优先使用设计容量和满充容量计算
+	if battery.DesignCapacity > 0 && battery.FullChargeCapacity > 0 {
+		battery.HealthPercent = float64(battery.FullChargeCapacity) / float64(battery.DesignCapacity) * 100
+	}
+
+	if battery.HealthPercent <= 0 {
+		return
+	}
+
+	// 新电池的满充容量可能略高于设计容量
+	if battery.HealthPercent > 100 {
+		battery.HealthPercent = 100
+	}
+	battery.WearPercent = 100 - battery.HealthPercent
+
+	switch {
+	case battery.HealthPercent >= batteryGoodThreshold:
+		battery.HealthVerdict = "良好"
+		battery.Advice = fmt.Sprintf("电池为设计容量的%.0f%%，无需更换", battery.HealthPercent)
+	case battery.HealthPercent >= batteryFairThreshold:
+		battery.HealthVerdict = "一般"
+		battery.Advice = fmt.Sprintf("电池为设计容量的%.0f%%，建议考虑更换", battery.HealthPercent)
+	default:
+		battery.HealthVerdict = "建议更换"
+		battery.Advice = fmt.Sprintf("电池为设计容量的%.0f%%，请尽快更换电池", battery.HealthPercent)
+	}
+}
@@ -0,0 +1,85 @@
+package analysis
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// certificateExpiryWarning 证书到期提醒的提前天数
+const certificateExpiryWarning = 30
+
+// EvaluateCertificate 提取证书的主题、用途和到期时间，并标记即将到期或已过期的证书
+func EvaluateCertificate(cert *x509.Certificate, store string) model.CertificateInfo {
+	info := model.CertificateInfo{
+		Subject:  certificateName(cert.Subject.CommonName, cert.Subject.String()),
+		Issuer:   certificateName(cert.Issuer.CommonName, cert.Issuer.String()),
+		Store:    store,
+		Purpose:  certificatePurpose(cert),
+		NotAfter: cert.NotAfter.Local().Format("2006-01-02 15:04:05"),
+	}
+
+	remaining := time.Until(cert.NotAfter)
+	info.DaysLeft = int(remaining.Hours() / 24)
+	info.Expired = remaining < 0
+	info.ExpiringSoon = !info.Expired && info.DaysLeft < certificateExpiryWarning
+
+	return info
+}
+
+// ParsePEMCertificates 解析PEM格式的证书列表，跳过无法解析的证书
+func ParsePEMCertificates(data []byte) []*x509.Certificate {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certs = append(certs, cert)
+		}
+	}
+	return certs
+}
+
+// certificatePurpose 根据基本约束和扩展密钥用法判断证书用途
+func certificatePurpose(cert *x509.Certificate) string {
+	if cert.IsCA {
+		return "CA证书"
+	}
+
+	var purposes []string
+	for _, usage := range cert.ExtKeyUsage {
+		switch usage {
+		case x509.ExtKeyUsageClientAuth:
+			purposes = append(purposes, "客户端认证")
+		case x509.ExtKeyUsageServerAuth:
+			purposes = append(purposes, "服务器认证")
+		case x509.ExtKeyUsageCodeSigning:
+			purposes = append(purposes, "代码签名")
+		case x509.ExtKeyUsageEmailProtection:
+			purposes = append(purposes, "邮件保护")
+		case x509.ExtKeyUsageIPSECEndSystem, x509.ExtKeyUsageIPSECTunnel, x509.ExtKeyUsageIPSECUser:
+			purposes = append(purposes, "IPSec")
+		}
+	}
+	if len(purposes) == 0 {
+		return "其他"
+	}
+	return strings.Join(purposes, "、")
+}
+
+// certificateName 优先使用通用名称，没有时使用完整的可分辨名称
+func certificateName(commonName, distinguishedName string) string {
+	if commonName != "" {
+		return commonName
+	}
+	return distinguishedName
+}
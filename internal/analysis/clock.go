@@ -0,0 +1,47 @@
+package analysis
+
+import (
+	"math"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/netprobe"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// 时钟偏差阈值（秒），Kerberos默认只容忍5分钟的偏差
+const (
+	clockOffsetWarning  = 1.0
+	clockOffsetCritical = 300.0
+)
+
+// DefaultNTPServer 未配置NTP服务器时使用的参考服务器
+const DefaultNTPServer = "pool.ntp.org"
+
+// MeasureClockOffset 使用配置的第一个NTP服务器（没有时使用pool.ntp.org）测量本机时钟偏差并评估
+func MeasureClockOffset(timeSync *model.TimeSyncInfo) error {
+	server := DefaultNTPServer
+	if len(timeSync.Servers) > 0 {
+		server = timeSync.Servers[0]
+	}
+	timeSync.ReferenceServer = server
+
+	result, err := netprobe.QueryNTP(server, 3*time.Second)
+	if err != nil {
+		timeSync.Status = "无法连接参考服务器"
+		return err
+	}
+
+	// QueryNTP返回服务器减本机，这里转换为本机减服务器
+	timeSync.Offset = -result.Offset.Seconds()
+
+	switch offset := math.Abs(timeSync.Offset); {
+	case offset < clockOffsetWarning:
+		timeSync.Status = "正常"
+	case offset < clockOffsetCritical:
+		timeSync.Status = "偏差较大"
+	default:
+		timeSync.Status = "严重偏差（TLS证书校验和Kerberos认证可能失败）"
+	}
+
+	return nil
+}
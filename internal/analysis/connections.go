@@ -0,0 +1,169 @@
+package analysis
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/netprobe"
+	"github.com/AsterZephyr/SysSpector/internal/sockets"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// 远端地址解析的并发数和反向解析超时
+const (
+	remoteLookupWorkers  = 16
+	reverseLookupTimeout = 2 * time.Second
+)
+
+// remoteOwner 表示远端地址的归属
+type remoteOwner struct {
+	name string // 组织名称（ASN名称、反向解析的域名或IP）
+	asn  string // 自治系统号
+	host string // 反向解析的主机名，没有时为IP
+}
+
+// SummarizeConnections 将已建立的连接按进程和远端组织汇总，例如“Teams 到 Microsoft 的 14 个连接”，
+// 远端组织优先使用ASN名称，其次使用反向解析的域名，按连接数从多到少排序
+// 离线模式下不向Team Cymru查询ASN，只使用反向解析的域名
+func SummarizeConnections(connections []sockets.Connection) []model.ConnectionGroupInfo {
+	owners := resolveOwners(connections)
+
+	groups := make(map[string]*model.ConnectionGroupInfo)
+	var order []string
+	for _, conn := range connections {
+		process := conn.Process
+		if process == "" {
+			process = "未知进程"
+		}
+		owner := owners[conn.RemoteIP.String()]
+		key := process + "\x00" + owner.name
+		group, ok := groups[key]
+		if !ok {
+			group = &model.ConnectionGroupInfo{Process: process, Remote: owner.name, ASN: owner.asn}
+			groups[key] = group
+			order = append(order, key)
+		}
+		group.Connections++
+		if conn.PID > 0 {
+			group.PIDs = appendUniquePID(group.PIDs, conn.PID)
+		}
+		group.Hosts = appendUnique(group.Hosts, owner.host)
+		group.Ports = appendUniquePort(group.Ports, conn.RemotePort)
+	}
+
+	summary := make([]model.ConnectionGroupInfo, 0, len(order))
+	for _, key := range order {
+		summary = append(summary, *groups[key])
+	}
+	sort.SliceStable(summary, func(i, j int) bool {
+		return summary[i].Connections > summary[j].Connections
+	})
+	return summary
+}
+
+// resolveOwners 并发解析所有远端地址的归属，每个远端IP只查询一次
+func resolveOwners(connections []sockets.Connection) map[string]remoteOwner {
+	lookupASN := !config.Current().Offline
+	owners := make(map[string]remoteOwner)
+	var ips []net.IP
+	for _, conn := range connections {
+		key := conn.RemoteIP.String()
+		if _, ok := owners[key]; ok {
+			continue
+		}
+		owners[key] = remoteOwner{}
+		ips = append(ips, conn.RemoteIP)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan net.IP)
+	for i := 0; i < remoteLookupWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ip := range queue {
+				owner := lookupOwner(ip, lookupASN)
+				mu.Lock()
+				owners[ip.String()] = owner
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, ip := range ips {
+		queue <- ip
+	}
+	close(queue)
+	wg.Wait()
+	return owners
+}
+
+// lookupOwner 解析一个远端地址的归属，内网地址不做查询；lookupASN为false时不查询ASN，
+// Team Cymru的DNS接口会看到本机连接的每个远端IP
+func lookupOwner(ip net.IP, lookupASN bool) remoteOwner {
+	owner := remoteOwner{name: ip.String(), host: ip.String()}
+	if ip.IsPrivate() || ip.IsLinkLocalUnicast() {
+		owner.name = "局域网"
+		return owner
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reverseLookupTimeout)
+	defer cancel()
+	if names, err := net.DefaultResolver.LookupAddr(ctx, ip.String()); err == nil && len(names) > 0 {
+		owner.host = strings.TrimSuffix(names[0], ".")
+		owner.name = registrableDomain(owner.host)
+	}
+
+	if !lookupASN {
+		return owner
+	}
+	if asn, err := netprobe.LookupASN(ip); err == nil {
+		owner.asn = asn.Number
+		if asn.Name != "" {
+			owner.name = asn.Name
+		}
+	}
+	return owner
+}
+
+// registrableDomain 粗略地取主机名的注册域名，例如 lb-140-82-112-25-iad.github.com 取 github.com，
+// 对 com.cn、co.uk 这类二级后缀保留三段
+func registrableDomain(host string) string {
+	labels := strings.Split(host, ".")
+	if len(labels) <= 2 {
+		return host
+	}
+	keep := 2
+	if len(labels[len(labels)-1]) == 2 && len(labels[len(labels)-2]) <= 3 {
+		keep = 3
+	}
+	if keep > len(labels) {
+		keep = len(labels)
+	}
+	return strings.Join(labels[len(labels)-keep:], ".")
+}
+
+// appendUniquePID 追加不重复的进程ID
+func appendUniquePID(pids []int32, pid int32) []int32 {
+	for _, p := range pids {
+		if p == pid {
+			return pids
+		}
+	}
+	return append(pids, pid)
+}
+
+// appendUniquePort 追加不重复的端口
+func appendUniquePort(ports []uint32, port uint32) []uint32 {
+	for _, p := range ports {
+		if p == port {
+			return ports
+		}
+	}
+	return append(ports, port)
+}
@@ -0,0 +1,57 @@
+package analysis
+
+import (
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/history"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// TrendWindow 计算磁盘增长趋势时使用的历史窗口
+const TrendWindow = 30 * 24 * time.Hour
+
+// minTrendSpan 历史采样至少需要覆盖的时长，太短的数据无法反映每日增长
+const minTrendSpan = 12 * time.Hour
+
+// ProjectDiskUsage 对指定挂载点的历史采样做线性回归，计算每日增长量和预计写满天数
+func ProjectDiskUsage(samples []history.DiskSample, partition model.DiskPartitionInfo) model.DiskTrendInfo {
+	trend := model.DiskTrendInfo{MountPoint: partition.MountPoint}
+
+	cutoff := time.Now().Add(-TrendWindow)
+	var points []history.DiskSample
+	for _, sample := range samples {
+		if sample.MountPoint == partition.MountPoint && sample.Time.After(cutoff) {
+			points = append(points, sample)
+		}
+	}
+
+	trend.Samples = len(points)
+	if len(points) < 2 || points[len(points)-1].Time.Sub(points[0].Time) < minTrendSpan {
+		return trend
+	}
+	trend.Since = points[0].Time.Format("2006-01-02 15:04")
+
+	// 以天为单位做最小二乘拟合：used = a + b*days
+	origin := points[0].Time
+	var sumX, sumY, sumXY, sumXX float64
+	for _, p := range points {
+		x := p.Time.Sub(origin).Hours() / 24
+		y := float64(p.Used)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	n := float64(len(points))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return trend
+	}
+	trend.DailyGrowth = (n*sumXY - sumX*sumY) / denominator
+
+	if trend.DailyGrowth > 0 {
+		trend.DaysUntilFull = float64(partition.Free) / trend.DailyGrowth
+	}
+
+	return trend
+}
@@ -0,0 +1,117 @@
+package analysis
+
+import (
+	"sync"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/netprobe"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// slowDNSThreshold 平均解析耗时超过该值时认为DNS较慢
+const slowDNSThreshold = 200 * time.Millisecond
+
+// DNS服务器来源
+const (
+	dnsSourceSystem = "系统"
+	dnsSourceConfig = "配置文件"
+)
+
+// dnsResolver 表示一个待测试的DNS服务器
+type dnsResolver struct {
+	server string
+	source string
+}
+
+// dnsResolvers 合并系统DNS服务器和配置文件中额外指定的服务器并去重
+func dnsResolvers(systemServers []string, cfg config.DNSConfig) []dnsResolver {
+	var resolvers []dnsResolver
+	seen := make(map[string]bool)
+	add := func(servers []string, source string) {
+		for _, server := range servers {
+			if server == "" || seen[server] {
+				continue
+			}
+			seen[server] = true
+			resolvers = append(resolvers, dnsResolver{server: server, source: source})
+		}
+	}
+	add(systemServers, dnsSourceSystem)
+	add(cfg.Servers, dnsSourceConfig)
+	return resolvers
+}
+
+// BenchmarkDNS 并发测试系统DNS服务器和配置文件中额外指定的服务器，
+// 逐个查询测试域名的A记录，统计每个服务器的解析耗时、失败和超时次数
+func BenchmarkDNS(systemServers []string, cfg config.DNSConfig) []model.DNSBenchmarkInfo {
+	var benchmarks []model.DNSBenchmarkInfo
+	for _, resolver := range dnsResolvers(systemServers, cfg) {
+		benchmarks = append(benchmarks, model.DNSBenchmarkInfo{Server: resolver.server, Source: resolver.source})
+	}
+
+	var wg sync.WaitGroup
+	for i := range benchmarks {
+		wg.Add(1)
+		go func(benchmark *model.DNSBenchmarkInfo) {
+			defer wg.Done()
+			benchmarkServer(benchmark, cfg.TestNames)
+		}(&benchmarks[i])
+	}
+	wg.Wait()
+	return benchmarks
+}
+
+// benchmarkServer 依次查询所有测试域名并评估服务器状态
+func benchmarkServer(benchmark *model.DNSBenchmarkInfo, names []string) {
+	var total time.Duration
+	var answered int
+	for _, name := range names {
+		response := netprobe.QueryDNS(benchmark.Server, name, "A", netprobe.DNSTimeout)
+		benchmark.Queries++
+		result := model.DNSQueryResultInfo{Name: name}
+
+		switch {
+		case response.Err == netprobe.ErrDNSTimeout:
+			benchmark.Timeouts++
+			result.Status = "超时"
+		case response.Err != nil:
+			benchmark.Failures++
+			result.Status = response.Err.Error()
+		default:
+			result.Time = milliseconds(response.RTT)
+			result.Status = response.RCode
+			// NXDOMAIN说明服务器正常应答，只有服务器错误计为失败
+			if response.RCode != "NOERROR" && response.RCode != "NXDOMAIN" {
+				benchmark.Failures++
+			}
+
+			if answered == 0 || result.Time < benchmark.MinTime {
+				benchmark.MinTime = result.Time
+			}
+			if result.Time > benchmark.MaxTime {
+				benchmark.MaxTime = result.Time
+			}
+			total += response.RTT
+			answered++
+		}
+		benchmark.Results = append(benchmark.Results, result)
+	}
+
+	if answered > 0 {
+		benchmark.AvgTime = milliseconds(total / time.Duration(answered))
+	}
+
+	switch {
+	case benchmark.Queries == 0:
+		benchmark.Status = "未测试"
+	case answered == 0:
+		benchmark.Status = "不可用"
+	case benchmark.Failures+benchmark.Timeouts > 0:
+		benchmark.Status = "部分失败"
+	case benchmark.AvgTime > milliseconds(slowDNSThreshold):
+		benchmark.Status = "较慢"
+	default:
+		benchmark.Status = "正常"
+	}
+}
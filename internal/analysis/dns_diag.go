@@ -0,0 +1,199 @@
+package analysis
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/netprobe"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// dnsDiagTypes 诊断时查询的记录类型
+var dnsDiagTypes = []string{"A", "AAAA", "CNAME"}
+
+// DiagnoseDNS 用所有DNS服务器解析配置文件中的关键域名，比较系统DNS与配置文件中的服务器的应答，
+// 检测分区解析、解析失败和DNS污染，并通过查询随机的不存在域名检测NXDOMAIN劫持
+func DiagnoseDNS(systemServers []string, cfg config.DNSConfig) model.DNSDiagnosticsInfo {
+	var diag model.DNSDiagnosticsInfo
+	resolvers := dnsResolvers(systemServers, cfg)
+	if len(resolvers) == 0 {
+		return diag
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	// 检测NXDOMAIN劫持
+	probeName := randomProbeName()
+	for _, resolver := range resolvers {
+		wg.Add(1)
+		go func(resolver dnsResolver) {
+			defer wg.Done()
+			response := netprobe.QueryDNS(resolver.server, probeName, "A", netprobe.DNSTimeout)
+			if response.Err != nil || len(response.Answers) == 0 {
+				return
+			}
+			var addrs []string
+			for _, answer := range response.Answers {
+				if answer.Type == "A" {
+					addrs = append(addrs, answer.Value)
+				}
+			}
+			mu.Lock()
+			diag.Findings = append(diag.Findings, fmt.Sprintf("DNS服务器 %s（%s）对不存在的域名返回了地址 %s，疑似NXDOMAIN劫持",
+				resolver.server, resolver.source, strings.Join(addrs, ", ")))
+			mu.Unlock()
+		}(resolver)
+	}
+
+	diag.Domains = make([]model.DNSDomainDiagInfo, len(cfg.Domains))
+	for i, domain := range cfg.Domains {
+		diag.Domains[i] = model.DNSDomainDiagInfo{Domain: domain, Answers: make([]model.DNSResolverAnswerInfo, len(resolvers))}
+		for j, resolver := range resolvers {
+			wg.Add(1)
+			go func(answer *model.DNSResolverAnswerInfo, domain string, resolver dnsResolver) {
+				defer wg.Done()
+				*answer = resolveAll(domain, resolver)
+			}(&diag.Domains[i].Answers[j], domain, resolver)
+		}
+	}
+	wg.Wait()
+
+	for i := range diag.Domains {
+		diag.Domains[i].Findings = domainFindings(diag.Domains[i].Answers)
+	}
+	return diag
+}
+
+// resolveAll 用一个DNS服务器查询域名的A、AAAA和CNAME记录
+func resolveAll(domain string, resolver dnsResolver) model.DNSResolverAnswerInfo {
+	answer := model.DNSResolverAnswerInfo{Server: resolver.server, Source: resolver.source}
+	for _, qtype := range dnsDiagTypes {
+		response := netprobe.QueryDNS(resolver.server, domain, qtype, netprobe.DNSTimeout)
+		if response.Err != nil {
+			answer.Error = response.Err.Error()
+			continue
+		}
+		if answer.RCode == "" || answer.RCode == "NOERROR" {
+			answer.RCode = response.RCode
+		}
+		if t := milliseconds(response.RTT); t > answer.Time {
+			answer.Time = t
+		}
+		for _, record := range response.Answers {
+			switch record.Type {
+			case "A":
+				answer.A = appendUnique(answer.A, record.Value)
+			case "AAAA":
+				answer.AAAA = appendUnique(answer.AAAA, record.Value)
+			case "CNAME":
+				answer.CNAME = appendUnique(answer.CNAME, record.Value)
+			}
+		}
+	}
+	// 至少有一种记录类型查询成功时不再报告错误
+	if answer.RCode != "" {
+		answer.Error = ""
+	}
+	return answer
+}
+
+// domainFindings 比较系统DNS和配置文件中的服务器对同一域名的应答
+func domainFindings(answers []model.DNSResolverAnswerInfo) []string {
+	var findings []string
+	var systemAddrs, referenceAddrs []string
+	var systemResolved, referenceResolved, hasReference bool
+
+	for _, answer := range answers {
+		addrs := append(append([]string{}, answer.A...), answer.AAAA...)
+		switch {
+		case answer.Error != "":
+			findings = append(findings, fmt.Sprintf("DNS服务器 %s 查询失败：%s", answer.Server, answer.Error))
+		case answer.RCode != "NOERROR":
+			findings = append(findings, fmt.Sprintf("DNS服务器 %s 返回 %s", answer.Server, answer.RCode))
+		}
+
+		if answer.Source == dnsSourceSystem {
+			systemAddrs = append(systemAddrs, addrs...)
+			systemResolved = systemResolved || len(addrs) > 0
+			continue
+		}
+
+		hasReference = true
+		referenceAddrs = append(referenceAddrs, addrs...)
+		referenceResolved = referenceResolved || len(addrs) > 0
+		for _, addr := range addrs {
+			if isInternalAddr(addr) {
+				findings = append(findings, fmt.Sprintf("DNS服务器 %s 返回了内网或无效地址 %s，疑似DNS污染", answer.Server, addr))
+			}
+		}
+	}
+	if !hasReference {
+		return findings
+	}
+
+	switch {
+	case systemResolved && anyInternal(systemAddrs) && !anyInternal(referenceAddrs):
+		findings = append(findings, "分区解析：系统DNS返回内网地址，公共DNS返回公网地址或无法解析")
+	case systemResolved && !referenceResolved:
+		findings = append(findings, "只有系统DNS能解析该域名")
+	case !systemResolved && referenceResolved:
+		findings = append(findings, "系统DNS无法解析该域名，配置文件中的DNS服务器可以解析")
+	case systemResolved && referenceResolved && !overlaps(systemAddrs, referenceAddrs):
+		findings = append(findings, "系统DNS与配置文件中的DNS服务器返回的地址完全不同，可能是CDN调度，也可能被劫持")
+	}
+	return findings
+}
+
+// isInternalAddr 判断地址是否为内网、回环、链路本地或未指定地址
+func isInternalAddr(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()
+}
+
+// anyInternal 判断地址列表中是否有内网地址
+func anyInternal(addrs []string) bool {
+	for _, addr := range addrs {
+		if isInternalAddr(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// overlaps 判断两个地址列表是否有相同的地址
+func overlaps(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// appendUnique 追加不重复的值
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// randomProbeName 生成一个随机的、不应存在的域名
+func randomProbeName() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return "sysspector-" + hex.EncodeToString(buf) + ".com"
+}
@@ -0,0 +1,82 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// DNS解析器的作用域
+const (
+	DNSScopeDefault   = "默认"
+	DNSScopeDomain    = "指定域名"
+	DNSScopeInterface = "接口限定"
+)
+
+// CheckDNSResolvers 标记VPN隧道接口的解析器，检查VPN推送的DNS与物理接口（通常由DHCP获取）的DNS之间的冲突
+// 默认解析器为作用域为默认的解析器中顺序最靠前的一个；没有绑定接口的解析器（如Windows的NRPT规则）使用了VPN接口的DNS服务器时也视为VPN推送
+func CheckDNSResolvers(dns *model.DNSConfigInfo, vpn model.VPNInfo) {
+	tunnels := make(map[string]bool)
+	if vpn.IsConnected {
+		for _, name := range append(append([]string{}, vpn.Interfaces...), vpn.SplitTunnel.Interfaces...) {
+			tunnels[name] = true
+		}
+	}
+
+	tunnelServers := make(map[string]bool)
+	for i := range dns.Resolvers {
+		resolver := &dns.Resolvers[i]
+		resolver.VPN = tunnels[resolver.Interface] || (vpn.IsConnected && tunnelInterfaceRegex.MatchString(resolver.Interface))
+		if resolver.VPN {
+			for _, server := range resolver.Servers {
+				tunnelServers[server] = true
+			}
+		}
+	}
+
+	var defaults, vpnResolvers []*model.DNSResolverInfo
+	for i := range dns.Resolvers {
+		resolver := &dns.Resolvers[i]
+		if !resolver.VPN && len(resolver.Servers) > 0 && tunnelServers[resolver.Servers[0]] {
+			resolver.VPN = true
+		}
+		if resolver.VPN {
+			vpnResolvers = append(vpnResolvers, resolver)
+		}
+		if resolver.Scope == DNSScopeDefault && len(resolver.Servers) > 0 {
+			defaults = append(defaults, resolver)
+		}
+	}
+	sort.SliceStable(defaults, func(i, j int) bool { return defaults[i].Order < defaults[j].Order })
+
+	dns.Findings = nil
+	if vpn.IsConnected {
+		var vpnDomains, vpnServers []string
+		for _, resolver := range vpnResolvers {
+			for _, server := range resolver.Servers {
+				vpnServers = appendUnique(vpnServers, server)
+			}
+			if resolver.Scope == DNSScopeDomain && resolver.Domain != "" {
+				vpnDomains = appendUnique(vpnDomains, resolver.Domain)
+			}
+		}
+		switch {
+		case len(vpnServers) == 0:
+			dns.Findings = append(dns.Findings, "VPN已连接，但VPN没有提供DNS服务器，内部域名可能无法解析")
+		case len(defaults) > 0 && !defaults[0].VPN && len(vpnDomains) == 0:
+			dns.Findings = append(dns.Findings, "VPN提供的DNS服务器（"+strings.Join(vpnServers, ", ")+"）没有用于默认解析，所有域名使用"+
+				defaults[0].Interface+"的DNS服务器（"+strings.Join(defaults[0].Servers, ", ")+"），内部域名可能无法解析")
+		case len(defaults) > 0 && !defaults[0].VPN:
+			dns.Findings = append(dns.Findings, "内部域名 "+strings.Join(vpnDomains, ", ")+" 使用VPN的DNS服务器解析，其他域名使用"+
+				defaults[0].Interface+"的DNS服务器（"+strings.Join(defaults[0].Servers, ", ")+"）")
+		}
+		return
+	}
+
+	// 没有VPN时，多个接口配置了不同的DNS服务器会导致解析结果取决于接口顺序
+	if len(defaults) > 1 && strings.Join(defaults[0].Servers, ",") != strings.Join(defaults[1].Servers, ",") && defaults[0].Interface != defaults[1].Interface {
+		dns.Findings = append(dns.Findings, "多个网络接口配置了不同的DNS服务器，当前优先使用"+defaults[0].Interface+"（"+
+			strings.Join(defaults[0].Servers, ", ")+"）")
+	}
+}
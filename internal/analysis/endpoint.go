@@ -0,0 +1,107 @@
+package analysis
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/netprobe"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// CheckEndpoints 同时检查所有配置的HTTP/HTTPS端点，记录各阶段耗时并检查证书链
+func CheckEndpoints(cfg config.EndpointConfig) []model.EndpointCheckInfo {
+	checks := make([]model.EndpointCheckInfo, len(cfg.Targets))
+	var wg sync.WaitGroup
+	for i, target := range cfg.Targets {
+		wg.Add(1)
+		go func(check *model.EndpointCheckInfo, target config.EndpointTarget) {
+			defer wg.Done()
+			*check = checkEndpoint(target, cfg.InspectionIssuers)
+		}(&checks[i], target)
+	}
+	wg.Wait()
+	return checks
+}
+
+// checkEndpoint 检查一个端点：证书链不被系统信任、签发者为已知的TLS解密产品或与预期的签发者不一致时标记为异常
+// 公司安装了解密代理的根证书时证书链仍然有效，只能通过签发者识别
+func checkEndpoint(target config.EndpointTarget, inspectionIssuers []string) model.EndpointCheckInfo {
+	info := model.EndpointCheckInfo{Name: target.Name, URL: target.URL}
+	result := netprobe.CheckHTTP(target.URL, netprobe.HTTPCheckTimeout)
+	if result.Err != nil {
+		info.Error = result.Err.Error()
+		info.Assessment = "无法访问"
+		return info
+	}
+
+	info.Status = result.Status
+	info.RemoteAddr = result.RemoteAddr
+	info.DNSTime = milliseconds(result.DNS)
+	info.ConnectTime = milliseconds(result.Connect)
+	info.TLSTime = milliseconds(result.TLS)
+	info.TTFB = milliseconds(result.TTFB)
+	info.TotalTime = milliseconds(result.Total)
+	info.TLSVersion = result.TLSVersion
+
+	var findings []string
+	if len(result.Certificates) > 0 {
+		for _, cert := range result.Certificates {
+			info.Certificates = append(info.Certificates, EvaluateCertificate(cert, "服务器证书链"))
+		}
+		info.CertificateValid = result.VerifyErr == nil
+		if result.VerifyErr != nil {
+			info.CertificateError = result.VerifyErr.Error()
+			findings = append(findings, "证书无效："+info.CertificateError)
+		} else if info.Certificates[0].ExpiringSoon {
+			findings = append(findings, fmt.Sprintf("证书将在 %d 天后到期", info.Certificates[0].DaysLeft))
+		}
+		info.InterceptedBy = inspectionIssuer(result.Certificates, inspectionIssuers)
+		if info.InterceptedBy == "" && target.ExpectedIssuer != "" && !hasIssuer(result.Certificates, target.ExpectedIssuer) {
+			info.InterceptedBy = certificateName(result.Certificates[0].Issuer.CommonName, result.Certificates[0].Issuer.String())
+		}
+		if info.InterceptedBy != "" {
+			info.Intercepted = true
+			findings = append(findings, "证书被 "+info.InterceptedBy+" 重新签发，HTTPS流量经过TLS解密代理，使用证书固定的应用可能无法连接")
+		}
+	}
+
+	if result.Status >= 500 {
+		findings = append(findings, fmt.Sprintf("服务器返回 %d 错误", result.Status))
+	}
+	if target.MaxTotal > 0 && info.TotalTime > target.MaxTotal {
+		findings = append(findings, fmt.Sprintf("总耗时 %.0fms 超过阈值 %.0fms", info.TotalTime, target.MaxTotal))
+	}
+	if len(findings) == 0 {
+		info.Assessment = "正常"
+	} else {
+		info.Assessment = strings.Join(findings, "；")
+	}
+	return info
+}
+
+// inspectionIssuer 在证书链中查找签发者为已知TLS解密产品的证书，返回该签发者名称
+func inspectionIssuer(certs []*x509.Certificate, keywords []string) string {
+	for _, cert := range certs {
+		issuer := strings.ToLower(cert.Issuer.String())
+		for _, keyword := range keywords {
+			if keyword != "" && strings.Contains(issuer, strings.ToLower(keyword)) {
+				return certificateName(cert.Issuer.CommonName, cert.Issuer.String())
+			}
+		}
+	}
+	return ""
+}
+
+// hasIssuer 判断证书链中是否有签发者名称包含指定关键字的证书
+func hasIssuer(certs []*x509.Certificate, keyword string) bool {
+	keyword = strings.ToLower(keyword)
+	for _, cert := range certs {
+		if strings.Contains(strings.ToLower(cert.Issuer.String()), keyword) {
+			return true
+		}
+	}
+	return false
+}
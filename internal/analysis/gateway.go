@@ -0,0 +1,67 @@
+package analysis
+
+import (
+	"sync"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/netprobe"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// gatewayProbeOptions 网关和互联网对比测试的ping参数，两者同时发送相同数量的数据包
+var gatewayProbeOptions = netprobe.PingOptions{Count: 10, Interval: 100 * time.Millisecond, Timeout: time.Second}
+
+// 判断链路异常的阈值
+const (
+	gatewayMaxLatency  = 20  // 局域网内网关的平均延迟上限（毫秒）
+	internetMaxLatency = 150 // 互联网参考目标的平均延迟上限（毫秒）
+	maxLoss            = 5   // 丢包率上限（%）
+)
+
+// AssessGateway 同时ping默认网关和互联网参考目标，根据两者的延迟和丢包判断问题出在局域网还是上游网络
+// 平台相关的网关地址和接口需要在调用前填写
+func AssessGateway(info *model.GatewayInfo) {
+	if info.Address == "" {
+		info.Assessment = "没有默认网关"
+		return
+	}
+	info.InternetTarget = TraceTarget
+
+	var gateway, internet netprobe.PingResult
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		gateway = netprobe.Ping(info.Address, gatewayProbeOptions)
+	}()
+	go func() {
+		defer wg.Done()
+		internet = netprobe.Ping(info.InternetTarget, gatewayProbeOptions)
+	}()
+	wg.Wait()
+
+	info.Loss, info.InternetLoss = gateway.Loss(), internet.Loss()
+	if gateway.Received > 0 {
+		_, avg, _, _ := gateway.Stats()
+		info.Latency = milliseconds(avg)
+	}
+	if internet.Received > 0 {
+		_, avg, _, _ := internet.Stats()
+		info.InternetLatency = milliseconds(avg)
+	}
+
+	gatewayBad := info.Loss > maxLoss || info.Latency > gatewayMaxLatency
+	internetBad := info.InternetLoss > maxLoss || info.InternetLatency > internetMaxLatency
+	switch {
+	case gateway.Received == 0 && internet.Received > 0:
+		info.Assessment = "网关不响应ping（可能禁用了ICMP），互联网连接正常"
+	case gateway.Received == 0:
+		info.Assessment = "网关和互联网都无法连通，请检查WiFi或网线连接"
+	case gatewayBad:
+		info.Assessment = "到网关的延迟或丢包异常，问题在本地网络（WiFi信号、干扰或路由器负载）"
+	case internetBad:
+		info.Assessment = "网关正常，但互联网延迟或丢包异常，问题在上游网络或运营商"
+	default:
+		info.Assessment = "正常"
+	}
+}
@@ -0,0 +1,148 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// 健康评分各项的最大扣分
+const (
+	healthMaxLatency  = 25
+	healthMaxLoss     = 25
+	healthMaxDNS      = 20
+	healthMaxEndpoint = 25
+	healthMaxTCP      = 10
+	healthMaxQuality  = 10
+)
+
+// ScoreNetworkHealth 根据延迟、丢包、DNS、端点检查、TCP重传和负载下的响应能力计算0-100的网络健康评分，
+// 各项按严重程度扣分，扣分最多的一项作为结论，便于一线支持人员直接判断问题所在
+func ScoreNetworkHealth(info model.NetworkInfo) model.HealthScoreInfo {
+	var health model.HealthScoreInfo
+	score := 100
+	worst := 0
+	deduct := func(points int, reason string) {
+		if points <= 0 {
+			return
+		}
+		score -= points
+		health.Deductions = append(health.Deductions, fmt.Sprintf("-%d %s", points, reason))
+		if points > worst {
+			worst = points
+			health.Verdict = reason
+		}
+	}
+
+	// 延迟：可达目标的平均延迟超过100ms开始扣分，每50ms扣5分；抖动超过30ms扣5分
+	if latency := info.Latency.AvgLatency; latency > 100 {
+		deduct(minInt(healthMaxLatency, int((latency-100)/50+1)*5), fmt.Sprintf("平均延迟 %.0fms 偏高", latency))
+	}
+	if info.Latency.Jitter > 30 {
+		deduct(5, fmt.Sprintf("抖动 %.0fms 偏高，语音和视频通话可能卡顿", info.Latency.Jitter))
+	}
+
+	// 丢包：有完全无法访问的目标时每个扣5分，否则探测目标和网关的丢包率每1%扣3分
+	var unreachable int
+	for _, target := range info.Latency.Targets {
+		if target.PacketLoss >= 100 {
+			unreachable++
+		}
+	}
+	loss := math.Max(info.Latency.PacketLoss, info.Gateway.Loss)
+	if unreachable > 0 {
+		deduct(minInt(healthMaxLoss, unreachable*5), fmt.Sprintf("%d 个探测目标无法访问", unreachable))
+	} else if loss >= 1 {
+		deduct(minInt(healthMaxLoss, int(loss*3)), fmt.Sprintf("丢包率 %.1f%%", loss))
+	}
+
+	// DNS：系统DNS服务器不可用、部分失败或较慢，以及诊断发现的劫持等问题
+	dnsPoints, dnsReason := 0, ""
+	for _, benchmark := range info.DNSBenchmark {
+		if benchmark.Source != dnsSourceSystem {
+			continue
+		}
+		points := map[string]int{"不可用": healthMaxDNS, "部分失败": 10, "较慢": 5}[benchmark.Status]
+		if points > dnsPoints {
+			dnsPoints, dnsReason = points, "系统DNS服务器 "+benchmark.Server+" "+benchmark.Status
+		}
+	}
+	deduct(dnsPoints, dnsReason)
+	if len(info.DNSDiagnostics.Findings) > 0 {
+		deduct(minInt(10, len(info.DNSDiagnostics.Findings)*5), "DNS诊断："+info.DNSDiagnostics.Findings[0])
+	}
+
+	// 端点：配置的端点和SaaS服务中无法访问的比例
+	checks := append([]model.EndpointCheckInfo{}, info.EndpointChecks...)
+	for _, suite := range info.SaaS {
+		checks = append(checks, suite.Checks...)
+	}
+	var failed int
+	var firstFailed string
+	for _, check := range checks {
+		if check.Error != "" {
+			if failed == 0 {
+				firstFailed = check.Name + "（" + check.URL + "）"
+			}
+			failed++
+		}
+	}
+	if failed > 0 {
+		deduct(int(math.Ceil(float64(healthMaxEndpoint*failed)/float64(len(checks)))),
+			fmt.Sprintf("%d/%d 个HTTP端点无法访问，例如 %s", failed, len(checks), firstFailed))
+	}
+
+	// TCP重传：采样期间流量太少时使用开机以来的累计重传率
+	rate := info.TCPStats.RetransmitRate
+	if info.TCPStats.SegmentsSent < minSampledSegments {
+		rate = info.TCPStats.TotalRetransmitRate
+	}
+	switch {
+	case rate >= retransmitRateHigh:
+		deduct(healthMaxTCP, fmt.Sprintf("TCP重传率 %.2f%%", rate))
+	case rate >= retransmitRateWarning:
+		deduct(healthMaxTCP/2, fmt.Sprintf("TCP重传率 %.2f%%", rate))
+	}
+
+	// 负载下的响应能力：只有运行了 --network-quality 时才有结果
+	switch info.Quality.Rating {
+	case ResponsivenessLow:
+		reason := fmt.Sprintf("负载下延迟 %.0fms（%d RPM），存在严重的缓冲区膨胀", info.Quality.LoadedLatency, info.Quality.RPM)
+		if info.Quality.RPM == 0 {
+			reason = "下载占满带宽时无法建立连接，存在严重的缓冲区膨胀"
+		}
+		deduct(healthMaxQuality, reason)
+	case ResponsivenessMedium:
+		deduct(healthMaxQuality/2, fmt.Sprintf("负载下延迟 %.0fms（%d RPM），存在一定的缓冲区膨胀", info.Quality.LoadedLatency, info.Quality.RPM))
+	}
+
+	if score < 0 {
+		score = 0
+	}
+	health.Score = score
+	switch {
+	case score >= 90:
+		health.Grade = "良好"
+	case score >= 70:
+		health.Grade = "一般"
+	case score >= 50:
+		health.Grade = "较差"
+	default:
+		health.Grade = "很差"
+	}
+	if health.Verdict == "" {
+		health.Verdict = "网络状况良好"
+	} else {
+		health.Verdict = "主要问题：" + health.Verdict
+	}
+	return health
+}
+
+// minInt 返回两个整数中较小的一个
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,96 @@
+package analysis
+
+import (
+	"sort"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// ProcessSampleWindow 进程CPU使用率的采样时长
+const ProcessSampleWindow = time.Second
+
+// resourceHogCount 每项资源报告的进程数量
+const resourceHogCount = 5
+
+// 资源类型
+const (
+	ResourceCPU     = "CPU"
+	ResourceMemory  = "内存"
+	ResourceNetwork = "网络"
+)
+
+// HogThresholds 标记资源占用过高进程的阈值，为0时不检查该项
+type HogThresholds struct {
+	CPU     float64 // CPU使用率（百分比，多核可超过100）
+	Memory  uint64  // 内存占用（字节）
+	Network uint64  // 网络流量（字节/秒）
+}
+
+// DefaultHogThresholds 默认阈值：CPU 80%，内存2 GB，网络5 MB/s
+var DefaultHogThresholds = HogThresholds{
+	CPU:     80,
+	Memory:  2 * 1024 * 1024 * 1024,
+	Network: 5 * 1024 * 1024,
+}
+
+// SummarizeResourceHogs 列出CPU、内存和网络占用最高的前5个进程，并标记超过阈值的进程
+func SummarizeResourceHogs(processes []model.ProcessInfo, thresholds HogThresholds) model.ResourceHogsInfo {
+	summary := model.ResourceHogsInfo{SampleWindow: ProcessSampleWindow.String()}
+
+	cpu := rankProcesses(processes, ResourceCPU, thresholds.CPU, func(p model.ProcessInfo) float64 {
+		return p.CPU
+	})
+	memory := rankProcesses(processes, ResourceMemory, float64(thresholds.Memory), func(p model.ProcessInfo) float64 {
+		return float64(p.Memory)
+	})
+	network := rankProcesses(processes, ResourceNetwork, float64(thresholds.Network), func(p model.ProcessInfo) float64 {
+		return float64(p.NetworkUsage)
+	})
+
+	// 超过阈值的进程不限于前5名
+	for _, ranked := range [][]model.ProcessHogInfo{cpu, memory, network} {
+		for _, hog := range ranked {
+			if hog.Exceeded {
+				summary.Offenders = append(summary.Offenders, hog)
+			}
+		}
+	}
+
+	summary.TopCPU = limitHogs(cpu)
+	summary.TopMemory = limitHogs(memory)
+	summary.TopNetwork = limitHogs(network)
+
+	return summary
+}
+
+// rankProcesses 按value从大到小排列占用不为0的进程，并标记超过阈值的进程
+func rankProcesses(processes []model.ProcessInfo, resource string, threshold float64, value func(model.ProcessInfo) float64) []model.ProcessHogInfo {
+	var hogs []model.ProcessHogInfo
+	for _, p := range processes {
+		v := value(p)
+		if v <= 0 {
+			continue
+		}
+		hogs = append(hogs, model.ProcessHogInfo{
+			PID:      p.PID,
+			Name:     p.Name,
+			Resource: resource,
+			Value:    v,
+			Exceeded: threshold > 0 && v >= threshold,
+		})
+	}
+
+	sort.Slice(hogs, func(i, j int) bool {
+		return hogs[i].Value > hogs[j].Value
+	})
+	return hogs
+}
+
+// limitHogs 只保留前resourceHogCount个进程
+func limitHogs(hogs []model.ProcessHogInfo) []model.ProcessHogInfo {
+	if len(hogs) > resourceHogCount {
+		return hogs[:resourceHogCount]
+	}
+	return hogs
+}
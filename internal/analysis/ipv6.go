@@ -0,0 +1,105 @@
+package analysis
+
+import (
+	"net"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/netprobe"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// ipv6 出口测试使用的双栈目标（Google和Cloudflare公共DNS的TCP 53端口）
+var (
+	ipv6EgressTargets = []string{"2001:4860:4860::8888", "2606:4700:4700::1111"}
+	ipv4EgressTargets = []string{"8.8.8.8", "1.1.1.1"}
+)
+
+// happyEyeballsDelay 常见的Happy Eyeballs回退等待时间，IPv6比IPv4慢超过该值时浏览器等应用会感觉到变慢
+const happyEyeballsDelay = 250 * time.Millisecond
+
+// ipv6ProbeOptions 出口测试的连接参数
+var ipv6ProbeOptions = netprobe.PingOptions{Count: 3, Interval: 100 * time.Millisecond, Timeout: 2 * time.Second}
+
+// AssessIPv6 收集本机IPv6地址，测试IPv6出口是否可用，并与IPv4对比评估双栈回退是否会导致访问变慢
+// 平台相关的默认路由和网关需要在调用前填写
+func AssessIPv6(info *model.IPv6Info) {
+	info.Addresses = ipv6Addresses()
+
+	// 通过UDP“连接”获取内核选择的源地址，不会发送数据包
+	if conn, err := net.Dial("udp6", net.JoinHostPort(ipv6EgressTargets[0], "53")); err == nil {
+		info.SourceAddress = conn.LocalAddr().(*net.UDPAddr).IP.String()
+		info.DefaultRoute = true
+		conn.Close()
+	}
+
+	hasGlobal := false
+	for _, addr := range info.Addresses {
+		if addr.Scope == "全局单播" {
+			hasGlobal = true
+		}
+	}
+
+	if info.DefaultRoute {
+		info.IPv6Latency, info.Egress = tcpLatency(ipv6EgressTargets)
+	}
+	info.IPv4Latency, _ = tcpLatency(ipv4EgressTargets)
+
+	switch {
+	case !hasGlobal && !info.DefaultRoute:
+		info.Assessment = "未启用IPv6，仅使用IPv4"
+	case !info.DefaultRoute:
+		info.Assessment = "有全局IPv6地址但没有默认路由，IPv6不可用"
+	case !info.Egress:
+		info.Assessment = "有IPv6默认路由但无法访问公网，应用可能先尝试IPv6再回退到IPv4，导致连接变慢"
+	case info.IPv4Latency > 0 && info.IPv6Latency-info.IPv4Latency > milliseconds(happyEyeballsDelay):
+		info.Assessment = "IPv6可用但明显慢于IPv4，双栈应用可能因优先使用IPv6而变慢"
+	default:
+		info.Assessment = "IPv6可用"
+	}
+}
+
+// ipv6Addresses 列出所有网络接口上的IPv6地址，忽略回环地址
+func ipv6Addresses() []model.IPv6AddressInfo {
+	var addresses []model.IPv6AddressInfo
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+	for _, iface := range interfaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.To4() != nil || ipNet.IP.IsLoopback() {
+				continue
+			}
+			address := model.IPv6AddressInfo{Interface: iface.Name, Address: ipNet.String()}
+			switch {
+			case ipNet.IP.IsLinkLocalUnicast():
+				address.Scope = "链路本地"
+			case ipNet.IP.IsPrivate():
+				address.Scope = "唯一本地"
+			case ipNet.IP.IsGlobalUnicast():
+				address.Scope = "全局单播"
+			default:
+				address.Scope = "其他"
+			}
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// tcpLatency 依次连接目标的TCP 53端口，返回第一个可达目标的平均连接耗时
+func tcpLatency(targets []string) (float64, bool) {
+	for _, target := range targets {
+		result := netprobe.TCPPing(target, 53, ipv6ProbeOptions)
+		if result.Received > 0 {
+			_, avg, _, _ := result.Stats()
+			return milliseconds(avg), true
+		}
+	}
+	return 0, false
+}
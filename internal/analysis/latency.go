@@ -0,0 +1,200 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/netprobe"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// probe 表示一个属于某个分组的探测目标
+type probe struct {
+	group  string
+	target config.ProbeTarget
+}
+
+// flattenProbes 展开所有分组中的探测目标
+func flattenProbes(groups []config.ProbeGroup) []probe {
+	var probes []probe
+	for _, group := range groups {
+		for _, target := range group.Targets {
+			probes = append(probes, probe{group: group.Name, target: target})
+		}
+	}
+	return probes
+}
+
+// MeasureLatency 并发探测所有分组的目标，记录每个数据包的往返时延、统计值和超出的阈值，并计算所有可达目标的平均值
+func MeasureLatency(latency *model.LatencyInfo, groups []config.ProbeGroup) error {
+	probes := flattenProbes(groups)
+
+	results := make([]netprobe.PingResult, len(probes))
+	var wg sync.WaitGroup
+	for i, p := range probes {
+		wg.Add(1)
+		go func(i int, target config.ProbeTarget) {
+			defer wg.Done()
+			results[i] = probeTarget(target, netprobe.DefaultPingOptions)
+		}(i, p.target)
+	}
+	wg.Wait()
+
+	var lastErr error
+	for i, result := range results {
+		if result.Err != nil && result.Sent == 0 {
+			lastErr = result.Err
+			continue
+		}
+		latency.Targets = append(latency.Targets, targetLatency(probes[i], result))
+	}
+	summarizeLatency(latency)
+
+	if len(latency.Targets) == 0 {
+		if lastErr == nil {
+			lastErr = errors.New("no latency targets")
+		}
+		return lastErr
+	}
+	return nil
+}
+
+// targetLatency 根据探测结果计算目标的延迟统计值并检查阈值
+func targetLatency(p probe, result netprobe.PingResult) model.TargetLatencyInfo {
+	min, avg, max, stddev := result.Stats()
+	p50, p95, p99 := result.Percentiles()
+	target := model.TargetLatencyInfo{
+		Group:      p.group,
+		TargetName: p.target.Name,
+		TargetHost: p.target.Host,
+		Protocol:   p.target.Protocol,
+		Port:       p.target.Port,
+		MinLatency: milliseconds(min),
+		AvgLatency: milliseconds(avg),
+		MaxLatency: milliseconds(max),
+		P50:        milliseconds(p50),
+		P95:        milliseconds(p95),
+		P99:        milliseconds(p99),
+		StdDev:     milliseconds(stddev),
+		PacketLoss: result.Loss(),
+		Jitter:     milliseconds(result.Jitter()),
+		Method:     result.Method,
+	}
+	for _, rtt := range result.RTTs {
+		if rtt < 0 {
+			target.RTTs = append(target.RTTs, -1)
+		} else {
+			target.RTTs = append(target.RTTs, milliseconds(rtt))
+		}
+	}
+	target.Warnings = latencyWarnings(target, p.target)
+	return target
+}
+
+// summarizeLatency 计算所有目标的平均丢包率，以及可达目标的平均延迟和抖动
+func summarizeLatency(latency *model.LatencyInfo) {
+	var reachable int
+	for _, target := range latency.Targets {
+		latency.PacketLoss += target.PacketLoss
+		if target.PacketLoss < 100 {
+			latency.AvgLatency += target.AvgLatency
+			latency.Jitter += target.Jitter
+			reachable++
+		}
+	}
+
+	if len(latency.Targets) > 0 {
+		latency.PacketLoss /= float64(len(latency.Targets))
+	}
+	if reachable > 0 {
+		latency.AvgLatency /= float64(reachable)
+		latency.Jitter /= float64(reachable)
+	}
+}
+
+// ProbeTarget 按默认参数探测单个目标，count为发送的数据包数，为0时使用默认值；无法发送探测包时返回错误
+func ProbeTarget(target config.ProbeTarget, count int) (model.TargetLatencyInfo, error) {
+	opts := netprobe.DefaultPingOptions
+	if count > 0 {
+		opts.Count = count
+	}
+	result := probeTarget(target, opts)
+	if result.Err != nil && result.Sent == 0 {
+		return model.TargetLatencyInfo{}, result.Err
+	}
+	return targetLatency(probe{target: target}, result), nil
+}
+
+// probeTarget 按目标的协议选择探测方式
+func probeTarget(target config.ProbeTarget, opts netprobe.PingOptions) netprobe.PingResult {
+	switch target.Protocol {
+	case config.ProtocolTCP:
+		return netprobe.TCPPing(target.Host, target.Port, opts)
+	case config.ProtocolHTTP:
+		return netprobe.HTTPPing(target.URL(), opts)
+	default:
+		return netprobe.Ping(target.Host, opts)
+	}
+}
+
+// latencyWarnings 检查探测结果是否超出目标配置的阈值
+func latencyWarnings(result model.TargetLatencyInfo, target config.ProbeTarget) []string {
+	var warnings []string
+	if target.MaxLoss > 0 && result.PacketLoss > target.MaxLoss {
+		warnings = append(warnings, fmt.Sprintf("丢包率 %.0f%% 超过阈值 %.0f%%", result.PacketLoss, target.MaxLoss))
+	}
+	// 全部丢包时延迟和抖动没有意义
+	if result.PacketLoss >= 100 {
+		return warnings
+	}
+	if target.MaxLatency > 0 && result.AvgLatency > target.MaxLatency {
+		warnings = append(warnings, fmt.Sprintf("平均延迟 %.1fms 超过阈值 %.1fms", result.AvgLatency, target.MaxLatency))
+	}
+	if target.MaxJitter > 0 && result.Jitter > target.MaxJitter {
+		warnings = append(warnings, fmt.Sprintf("抖动 %.1fms 超过阈值 %.1fms", result.Jitter, target.MaxJitter))
+	}
+	return warnings
+}
+
+// TraceTarget 默认的路由跟踪目标
+const TraceTarget = "8.8.8.8"
+
+// TraceRoute 使用内置的路由跟踪获取到目标的每一跳，没有应答的跳点主机地址记为"???"
+func TraceRoute(latency *model.LatencyInfo, host string) error {
+	hops, err := netprobe.Trace(host, netprobe.DefaultTraceOptions)
+	if err != nil {
+		return err
+	}
+
+	for _, hop := range hops {
+		min, avg, max, stddev := hop.Stats()
+		info := model.NetworkHopInfo{
+			HopNum:       hop.TTL,
+			Host:         hop.Addr,
+			Loss:         hop.Loss(),
+			SentPackets:  len(hop.RTTs),
+			AvgLatency:   milliseconds(avg),
+			BestLatency:  milliseconds(min),
+			WorstLatency: milliseconds(max),
+			StdDev:       milliseconds(stddev),
+		}
+		if info.Host == "" {
+			info.Host = "???"
+		}
+		for _, rtt := range hop.RTTs {
+			if rtt >= 0 {
+				info.LastLatency = milliseconds(rtt)
+			}
+		}
+		latency.NetworkHops = append(latency.NetworkHops, info)
+	}
+	return nil
+}
+
+// milliseconds 将时长转换为毫秒
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
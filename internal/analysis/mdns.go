@@ -0,0 +1,67 @@
+package analysis
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/AsterZephyr/SysSpector/internal/netprobe"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// mdnsServiceNames 常见mDNS服务类型的说明
+var mdnsServiceNames = map[string]string{
+	"_ipp._tcp":            "打印机（IPP）",
+	"_ipps._tcp":           "打印机（IPPS）",
+	"_printer._tcp":        "打印机（LPD）",
+	"_pdl-datastream._tcp": "打印机（RAW）",
+	"_airplay._tcp":        "AirPlay",
+	"_raop._tcp":           "AirPlay音频",
+	"_googlecast._tcp":     "Chromecast",
+	"_ssh._tcp":            "SSH",
+	"_smb._tcp":            "文件共享（SMB）",
+	"_afpovertcp._tcp":     "文件共享（AFP）",
+}
+
+// DiscoverMDNS 查询局域网中的mDNS服务，根据是否收到其他设备的应答判断组播是否被过滤
+// 本机的mDNS服务也会应答查询，只有本机应答时不能说明组播可用
+func DiscoverMDNS(serviceTypes []string) model.MDNSInfo {
+	var info model.MDNSInfo
+	browse, err := netprobe.BrowseMDNS(serviceTypes, netprobe.MDNSTimeout)
+	if err != nil {
+		info.Assessment = "无法发送mDNS查询：" + err.Error()
+		return info
+	}
+
+	for _, serviceType := range serviceTypes {
+		info.Services = append(info.Services, model.MDNSServiceInfo{
+			Type:        serviceType,
+			Description: mdnsServiceNames[serviceType],
+			Instances:   browse.Instances[serviceType],
+		})
+	}
+
+	local := make(map[string]bool)
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok {
+				local[ipNet.IP.String()] = true
+			}
+		}
+	}
+	for _, responder := range browse.Responders {
+		if !local[responder] {
+			info.Responders = append(info.Responders, responder)
+		}
+	}
+	info.LocalOnly = len(browse.Responders) > 0 && len(info.Responders) == 0
+
+	switch {
+	case len(info.Responders) > 0:
+		info.Assessment = fmt.Sprintf("收到 %d 台设备的应答，本网段内的组播可以正常使用", len(info.Responders))
+	case info.LocalOnly:
+		info.Assessment = "只收到本机的应答，网段内其他设备没有响应，组播可能被过滤（例如无线网络开启了AP隔离），也可能网段内没有提供这些服务的设备"
+	default:
+		info.Assessment = "没有收到任何mDNS应答，组播可能被过滤（例如无线网络开启了AP隔离或防火墙阻止了UDP 5353端口），也可能网段内没有提供这些服务的设备"
+	}
+	return info
+}
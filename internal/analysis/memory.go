@@ -0,0 +1,24 @@
+package analysis
+
+import (
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// MemoryModulesMismatched 判断已安装的内存条容量、速度或型号是否不一致
+func MemoryModulesMismatched(modules []model.MemoryModule) bool {
+	var populated []model.MemoryModule
+	for _, module := range modules {
+		if module.Size > 0 {
+			populated = append(populated, module)
+		}
+	}
+
+	for i := 1; i < len(populated); i++ {
+		if populated[i].Size != populated[0].Size ||
+			populated[i].Speed != populated[0].Speed ||
+			populated[i].PartNumber != populated[0].PartNumber {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package analysis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/netprobe"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// minOutageLost 连续丢失多少个数据包视为一次中断
+const minOutageLost = 3
+
+// rttSample 表示一次探测的结果
+type rttSample struct {
+	time   time.Time
+	rtt    time.Duration // 丢失时为-1
+	method string
+}
+
+// monitoredTarget 表示一个被持续监控的目标及其滚动窗口内的样本
+type monitoredTarget struct {
+	probe
+	samples []rttSample
+}
+
+// LatencyMonitor 持续探测所有目标，为每个目标保留滚动窗口内的往返时延样本
+type LatencyMonitor struct {
+	mu      sync.Mutex
+	window  time.Duration
+	targets []*monitoredTarget
+}
+
+// NewLatencyMonitor 创建延迟监控，window为统计使用的滚动窗口长度
+func NewLatencyMonitor(groups []config.ProbeGroup, window time.Duration) *LatencyMonitor {
+	m := &LatencyMonitor{window: window}
+	for _, p := range flattenProbes(groups) {
+		m.targets = append(m.targets, &monitoredTarget{probe: p})
+	}
+	return m
+}
+
+// Run 每隔interval向所有目标各发送一个探测包，直到ctx结束
+func (m *LatencyMonitor) Run(ctx context.Context, interval time.Duration) {
+	opts := netprobe.PingOptions{Count: 1, Timeout: interval}
+	if opts.Timeout > netprobe.DefaultPingOptions.Timeout {
+		opts.Timeout = netprobe.DefaultPingOptions.Timeout
+	}
+
+	var wg sync.WaitGroup
+	for _, target := range m.targets {
+		wg.Add(1)
+		go func(target *monitoredTarget) {
+			defer wg.Done()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				sent := time.Now()
+				result := probeTarget(target.target, opts)
+				sample := rttSample{time: sent, rtt: -1, method: result.Method}
+				if result.Received > 0 {
+					sample.rtt = result.RTTs[0]
+				}
+				m.add(target, sample)
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(target)
+	}
+	wg.Wait()
+}
+
+// add 记录一个样本并丢弃滚动窗口之外的样本
+func (m *LatencyMonitor) add(target *monitoredTarget, sample rttSample) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	target.samples = append(target.samples, sample)
+	cutoff := sample.time.Add(-m.window)
+	expired := 0
+	for expired < len(target.samples) && target.samples[expired].time.Before(cutoff) {
+		expired++
+	}
+	target.samples = target.samples[expired:]
+}
+
+// Snapshot 根据滚动窗口内的样本计算每个目标的百分位数、丢包率和中断记录
+func (m *LatencyMonitor) Snapshot() model.LatencyInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var latency model.LatencyInfo
+	for _, target := range m.targets {
+		if len(target.samples) == 0 {
+			continue
+		}
+
+		result := netprobe.PingResult{Host: target.target.Host}
+		for _, sample := range target.samples {
+			result.Sent++
+			if sample.rtt >= 0 {
+				result.Received++
+			}
+			result.RTTs = append(result.RTTs, sample.rtt)
+			if sample.method != "" {
+				result.Method = sample.method
+			}
+		}
+
+		info := targetLatency(target.probe, result)
+		// 窗口内的样本数量较多，不保留每个数据包的往返时延
+		info.RTTs = nil
+		info.Outages = outages(target.samples)
+		latency.Targets = append(latency.Targets, info)
+	}
+	summarizeLatency(&latency)
+	return latency
+}
+
+// outages 找出连续丢失至少minOutageLost个数据包的时间段
+func outages(samples []rttSample) []model.OutageInfo {
+	var result []model.OutageInfo
+	start := -1
+	for i := 0; i <= len(samples); i++ {
+		if i < len(samples) && samples[i].rtt < 0 {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && i-start >= minOutageLost {
+			outage := model.OutageInfo{
+				Start: samples[start].time.Format("2006-01-02 15:04:05"),
+				Lost:  i - start,
+			}
+			end := samples[i-1].time
+			if i < len(samples) {
+				end = samples[i].time
+				outage.End = end.Format("2006-01-02 15:04:05")
+			}
+			outage.Duration = end.Sub(samples[start].time).Seconds()
+			result = append(result, outage)
+		}
+		start = -1
+	}
+	return result
+}
@@ -0,0 +1,34 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/AsterZephyr/SysSpector/internal/netprobe"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// DiscoverPathMTU 探测到目标的路径MTU并与接口MTU对比
+// 路径MTU小于接口MTU而路由器没有返回需要分片报文时，超过路径MTU的数据包会被静默丢弃（MTU黑洞），
+// 常见于VPN隧道，表现为部分网站或大文件传输卡住而ping正常
+func DiscoverPathMTU(target string) model.MTUInfo {
+	result := netprobe.PathMTU(target, netprobe.DefaultMTUOptions)
+	info := model.MTUInfo{
+		Target:       target,
+		Interface:    result.Interface,
+		InterfaceMTU: result.InterfaceMTU,
+		PathMTU:      result.PathMTU,
+	}
+
+	switch {
+	case result.Err != nil:
+		info.Assessment = "无法探测：" + result.Err.Error()
+	case info.PathMTU >= info.InterfaceMTU:
+		info.Assessment = "正常"
+	case result.FragNeeded:
+		info.Assessment = fmt.Sprintf("路径MTU小于接口MTU，路由器返回了需要分片报文，系统可以自动调整（最大 %d 字节）", info.PathMTU)
+	default:
+		info.Blackhole = true
+		info.Assessment = fmt.Sprintf("存在MTU黑洞：超过 %d 字节的数据包被静默丢弃，可能导致部分网站或VPN连接卡住，建议将接口MTU调低到 %d", info.PathMTU, info.PathMTU)
+	}
+	return info
+}
@@ -0,0 +1,60 @@
+package analysis
+
+import (
+	"net"
+
+	"github.com/AsterZephyr/SysSpector/internal/netprobe"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// NAT类型
+const (
+	NATNone           = "无NAT"
+	NATFullCone       = "完全锥形NAT"
+	NATRestrictedCone = "受限锥形NAT"
+	NATCone           = "锥形NAT"
+	NATSymmetric      = "对称NAT"
+	NATBlocked        = "UDP不可用"
+)
+
+// DetectNAT 通过STUN获取外部映射地址并判断NAT类型
+// 两个服务器看到的映射地址不同为对称NAT，点对点连接通常无法建立，语音和视频通话需要经过中继服务器
+func DetectNAT(servers []string) model.NATInfo {
+	var info model.NATInfo
+	probe, err := netprobe.ProbeNAT(servers, netprobe.STUNTimeout)
+	if err != nil {
+		info.Type = NATBlocked
+		info.Assessment = "无法访问STUN服务器，UDP可能被防火墙阻止，语音和视频通话会退回到TCP或中继，质量可能下降"
+		return info
+	}
+
+	info.LocalAddress = probe.Local
+	info.MappedAddress = probe.Bindings[0].Mapped
+	for _, binding := range probe.Bindings {
+		info.Servers = append(info.Servers, binding.Server)
+	}
+
+	localHost, _, _ := net.SplitHostPort(info.LocalAddress)
+	mappedHost, _, _ := net.SplitHostPort(info.MappedAddress)
+	switch {
+	case localHost != "" && localHost == mappedHost:
+		info.Type = NATNone
+		info.Assessment = "本机直接使用公网地址，点对点连接不受NAT限制"
+	case len(probe.Bindings) > 1 && probe.Bindings[1].Mapped != info.MappedAddress:
+		info.Type = NATSymmetric
+		info.Assessment = "访问不同服务器时使用不同的外部端口，点对点连接通常无法建立，语音和视频通话需要经过中继"
+	case probe.FilteringTested && probe.AcceptsAnySource:
+		info.Type = NATFullCone
+		info.Assessment = "外部映射固定且接受任意来源，点对点连接容易建立"
+	case probe.FilteringTested:
+		info.Type = NATRestrictedCone
+		info.Assessment = "外部映射固定，只接受已通信过的地址，点对点连接通常可以通过打洞建立"
+	case len(probe.Bindings) > 1:
+		info.Type = NATCone
+		info.Assessment = "外部映射固定，点对点连接通常可以建立"
+	default:
+		info.Type = NATCone
+		info.Assessment = "只有一个STUN服务器响应，无法判断是否为对称NAT"
+	}
+	return info
+}
@@ -0,0 +1,104 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// portCheckTimeout 每个端口的连接超时
+const portCheckTimeout = 3 * time.Second
+
+// 端口检查结果
+const (
+	PortOpen       = "开放"
+	PortRefused    = "拒绝"
+	PortTimeout    = "超时"
+	PortDNSFailure = "解析失败"
+	PortError      = "失败"
+)
+
+// CheckPorts 同时尝试连接所有配置的出站端口，超时通常说明被防火墙丢弃，拒绝说明被防火墙或目标主机重置
+// 只有HTTP/HTTPS可以连接而其他端口都被拦截时，通常是访客网络或严格的出站防火墙
+func CheckPorts(cfg config.PortConfig) model.PortMatrixInfo {
+	var matrix model.PortMatrixInfo
+	matrix.Checks = make([]model.PortCheckInfo, len(cfg.Targets))
+	var wg sync.WaitGroup
+	for i, target := range cfg.Targets {
+		wg.Add(1)
+		go func(check *model.PortCheckInfo, target config.PortTarget) {
+			defer wg.Done()
+			*check = checkPort(target)
+		}(&matrix.Checks[i], target)
+	}
+	wg.Wait()
+
+	var open, resolved int
+	webOnly := true
+	for _, check := range matrix.Checks {
+		if check.Status == PortDNSFailure {
+			continue
+		}
+		resolved++
+		if check.Open {
+			open++
+			if check.Port != 80 && check.Port != 443 {
+				webOnly = false
+			}
+		} else {
+			matrix.Blocked = append(matrix.Blocked, fmt.Sprintf("%s %d", check.Name, check.Port))
+		}
+	}
+
+	switch {
+	case len(matrix.Checks) == 0:
+	case resolved == 0:
+		matrix.Assessment = "无法解析检查目标的域名，无法判断端口是否被拦截"
+	case open == 0:
+		matrix.Assessment = "所有端口都无法连接，可能需要通过代理访问外网，或检查目标不可用"
+	case len(matrix.Blocked) == 0:
+		matrix.Assessment = "所有端口都可以连接，没有发现出站限制"
+	case webOnly:
+		matrix.Assessment = "只有网页端口可以连接，可能是访客网络或严格的出站防火墙，邮件客户端、远程桌面和VPN可能无法使用"
+	default:
+		matrix.Assessment = "以下端口被拦截：" + strings.Join(matrix.Blocked, "、")
+	}
+	return matrix
+}
+
+// checkPort 建立一次TCP连接并区分失败的原因
+func checkPort(target config.PortTarget) model.PortCheckInfo {
+	check := model.PortCheckInfo{Name: target.Name, Host: target.Host, Port: target.Port}
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(target.Host, strconv.Itoa(target.Port)), portCheckTimeout)
+	if err == nil {
+		check.Time = milliseconds(time.Since(start))
+		check.Status, check.Open = PortOpen, true
+		conn.Close()
+		return check
+	}
+
+	check.Error = err.Error()
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &dnsErr):
+		check.Status = PortDNSFailure
+	// Windows的WSAECONNREFUSED与syscall.ECONNREFUSED不是同一个值，按错误信息判断
+	case errors.Is(err, syscall.ECONNREFUSED) || strings.Contains(err.Error(), "actively refused"):
+		check.Status = PortRefused
+	case errors.As(err, &netErr) && netErr.Timeout():
+		check.Status = PortTimeout
+	default:
+		check.Status = PortError
+	}
+	return check
+}
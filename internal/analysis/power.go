@@ -0,0 +1,58 @@
+package analysis
+
+import (
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// ratedWattages 记录常见Mac笔记本的标配充电器功率（W），按型号标识符索引
+var ratedWattages = map[string]int{
+	"MacBookAir9,1":  30,
+	"MacBookAir10,1": 30,
+	"Mac14,2":        30,
+	"Mac14,15":       35,
+	"Mac15,12":       30,
+	"Mac15,13":       35,
+	"MacBookPro15,1": 87,
+	"MacBookPro15,3": 87,
+	"MacBookPro16,1": 96,
+	"MacBookPro16,2": 61,
+	"MacBookPro16,3": 61,
+	"MacBookPro16,4": 96,
+	"MacBookPro17,1": 61,
+	"MacBookPro18,1": 140,
+	"MacBookPro18,2": 140,
+	"MacBookPro18,3": 67,
+	"MacBookPro18,4": 96,
+	"Mac14,5":        96,
+	"Mac14,6":        140,
+	"Mac14,7":        67,
+	"Mac14,9":        67,
+	"Mac14,10":       140,
+	"Mac15,3":        70,
+	"Mac15,6":        70,
+	"Mac15,7":        140,
+	"Mac15,8":        96,
+	"Mac15,9":        140,
+	"Mac15,10":       96,
+	"Mac15,11":       140,
+}
+
+// slowChargingRatio 协商功率低于额定功率的该比例时认为充电缓慢
+const slowChargingRatio = 0.9
+
+// RatedWattage 返回型号标识符对应的额定充电功率，未知型号返回0
+func RatedWattage(modelID string) int {
+	return ratedWattages[modelID]
+}
+
+// EvaluateCharging 根据协商功率和额定功率判断是否充电缓慢
+func EvaluateCharging(adapter *model.ACAdapterInfo) {
+	if adapter.NegotiatedWattage == 0 && adapter.NegotiatedVoltage > 0 && adapter.NegotiatedCurrent > 0 {
+		adapter.NegotiatedWattage = adapter.NegotiatedVoltage * adapter.NegotiatedCurrent
+	}
+
+	if !adapter.Connected || adapter.RatedWattage == 0 || adapter.NegotiatedWattage == 0 {
+		return
+	}
+	adapter.ChargingSlowly = adapter.NegotiatedWattage < float64(adapter.RatedWattage)*slowChargingRatio
+}
@@ -0,0 +1,155 @@
+package analysis
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// 代理类型
+const (
+	ProxyHTTP  = "HTTP"
+	ProxyHTTPS = "HTTPS"
+	ProxySOCKS = "SOCKS"
+	ProxyPAC   = "PAC"
+	ProxyWPAD  = "WPAD"
+)
+
+// PAC文件来源
+const (
+	PACSourceManual = "手动配置"
+	PACSourceWPAD   = "WPAD自动发现"
+)
+
+const (
+	pacFetchTimeout = 5 * time.Second
+	pacMaxSize      = 1 << 20
+	// wpadURL WPAD通过DNS搜索域解析wpad主机名，再下载其中的wpad.dat
+	wpadURL = "http://wpad/wpad.dat"
+)
+
+var (
+	// pacProxyRegex 匹配PAC文件返回值中的代理，例如"PROXY proxy.example.com:8080; DIRECT"
+	pacProxyRegex = regexp.MustCompile(`\b(PROXY|HTTPS|SOCKS[45]?)\s+([\w.\-]+(?::\d+)?|\[[0-9a-fA-F:]+\](?::\d+)?)`)
+	// pacRuleRegex 匹配PAC文件中常用的条件判断函数
+	pacRuleRegex   = regexp.MustCompile(`\b(shExpMatch|dnsDomainIs|isInNet|isPlainHostName|localHostOrDomainIs|isResolvable|dnsDomainLevels|weekdayRange|timeRange)\s*\(`)
+	pacDirectRegex = regexp.MustCompile(`\bDIRECT\b`)
+)
+
+// SummarizeProxy 汇总平台收集的代理设置：填写兼容字段，下载并分析PAC文件
+// 启用了WPAD时尝试通过DNS搜索域发现wpad.dat；离线模式下不下载PAC文件
+func SummarizeProxy(info *model.ProxyInfo) {
+	var pacURLs []string
+	for _, setting := range info.Settings {
+		switch setting.Type {
+		case ProxyHTTP, ProxyHTTPS, ProxySOCKS:
+			if info.Server == "" {
+				info.Server, info.Port = setting.Server, setting.Port
+			}
+		case ProxyPAC:
+			if setting.Server != "" {
+				pacURLs = appendUnique(pacURLs, setting.Server)
+			}
+		case ProxyWPAD:
+			info.WPAD = true
+		}
+	}
+	// Windows默认开启自动检测设置，只有发现了WPAD文件时才认为使用了代理
+	info.Enabled = len(pacURLs) > 0 || info.Server != ""
+
+	// WinINET和WinHTTP的例外列表经常有重复的项
+	var bypass []string
+	for _, host := range info.Bypass {
+		bypass = appendUnique(bypass, host)
+	}
+	info.Bypass = bypass
+
+	if config.Current().Offline {
+		return
+	}
+	for _, pacURL := range pacURLs {
+		info.PAC = append(info.PAC, FetchPAC(pacURL, PACSourceManual))
+	}
+	if info.WPAD {
+		if _, err := net.LookupHost("wpad"); err == nil {
+			pac := FetchPAC(wpadURL, PACSourceWPAD)
+			info.PAC = append(info.PAC, pac)
+			if pac.Error == "" {
+				info.Enabled = true
+			}
+		}
+	}
+}
+
+// FetchPAC 下载PAC文件并统计其中的规则和代理，支持http、https和file地址
+// PAC文件本身不经过代理下载，与系统的行为一致
+func FetchPAC(pacURL, source string) model.PACInfo {
+	pac := model.PACInfo{URL: pacURL, Source: source}
+	body, err := readPAC(pacURL)
+	if err != nil {
+		pac.Error = err.Error()
+		return pac
+	}
+	pac.Size = len(body)
+	if !strings.Contains(body, "FindProxyForURL") {
+		pac.Error = "不是有效的PAC文件（没有FindProxyForURL函数）"
+		return pac
+	}
+
+	pac.Rules = len(pacRuleRegex.FindAllStringIndex(body, -1))
+	pac.Direct = pacDirectRegex.MatchString(body)
+	for _, match := range pacProxyRegex.FindAllStringSubmatch(body, -1) {
+		pac.Proxies = appendUnique(pac.Proxies, match[1]+" "+match[2])
+	}
+	return pac
+}
+
+// readPAC 读取PAC文件内容，最多读取1MB
+func readPAC(pacURL string) (string, error) {
+	u, err := url.Parse(pacURL)
+	if err != nil {
+		return "", err
+	}
+
+	var reader io.Reader
+	switch u.Scheme {
+	case "file":
+		f, err := os.Open(u.Path)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		reader = f
+	case "http", "https":
+		client := &http.Client{
+			Timeout:   pacFetchTimeout,
+			Transport: &http.Transport{Proxy: nil},
+		}
+		resp, err := client.Get(pacURL)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", errors.New(resp.Status)
+		}
+		reader = resp.Body
+	default:
+		return "", errors.New("unsupported pac url scheme: " + u.Scheme)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(reader, pacMaxSize))
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
@@ -0,0 +1,142 @@
+package analysis
+
+import (
+	"bufio"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// proxyEnvSourceCurrent 当前进程环境中的变量
+const proxyEnvSourceCurrent = "当前环境"
+
+// proxyEnvNames 命令行工具（curl、git、pip、npm等）读取的代理环境变量，不区分大小写
+var proxyEnvNames = []string{"HTTP_PROXY", "HTTPS_PROXY", "FTP_PROXY", "ALL_PROXY", "NO_PROXY"}
+
+// shellProfiles 可能导出代理变量的shell配置文件，相对于用户主目录，绝对路径为系统级配置
+var shellProfiles = []string{
+	".zshenv", ".zprofile", ".zshrc", ".bash_profile", ".bashrc", ".profile",
+	".config/fish/config.fish", ".cshrc", ".tcshrc",
+	"Documents/PowerShell/Microsoft.PowerShell_profile.ps1",
+	"Documents/WindowsPowerShell/Microsoft.PowerShell_profile.ps1",
+	"/etc/zshenv", "/etc/zprofile", "/etc/zshrc", "/etc/profile", "/etc/bashrc",
+}
+
+// profileExportRegexes 匹配各种shell中设置环境变量的语句，第1组为变量名，第2组为值
+var profileExportRegexes = []*regexp.Regexp{
+	regexp.MustCompile(`^\s*(?:export\s+)?([A-Za-z_]+)=(.*)$`),                                              // sh、bash、zsh
+	regexp.MustCompile(`^\s*set\s+(?:-[A-Za-z]+\s+)*([A-Za-z_]+)\s+(.*)$`),                                  // fish
+	regexp.MustCompile(`^\s*setenv\s+([A-Za-z_]+)\s+(.*)$`),                                                 // csh、tcsh
+	regexp.MustCompile(`^\s*\$env:([A-Za-z_]+)\s*=\s*(.*)$`),                                                // PowerShell
+	regexp.MustCompile(`^\s*\[Environment\]::SetEnvironmentVariable\(\s*['"]([A-Za-z_]+)['"]\s*,\s*(.*)\)`), // PowerShell
+}
+
+// CollectProxyEnvironment 收集当前环境和shell配置文件中的代理变量，并与系统代理设置比较
+// 命令行工具只读取环境变量，图形界面应用只读取系统设置，两者不一致时同一个地址在浏览器和终端中的表现不同
+func CollectProxyEnvironment(info *model.ProxyInfo, environ []string) {
+	info.Environment = nil
+	for _, entry := range environ {
+		name, value, ok := strings.Cut(entry, "=")
+		if ok && isProxyEnvName(name) {
+			info.Environment = append(info.Environment, proxyEnv(name, value, proxyEnvSourceCurrent))
+		}
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	for _, profile := range shellProfiles {
+		path := profile
+		if !filepath.IsAbs(profile) {
+			if homeDir == "" {
+				continue
+			}
+			path = filepath.Join(homeDir, profile)
+		}
+		display := path
+		if !filepath.IsAbs(profile) {
+			display = "~/" + profile
+		}
+		info.Environment = append(info.Environment, scanProfileExports(path, display)...)
+	}
+
+	info.EnvironmentNote = compareProxyEnvironment(info)
+}
+
+// scanProfileExports 读取配置文件中设置代理变量的语句，文件不存在时返回空
+func scanProfileExports(path, display string) []model.ProxyEnvInfo {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var vars []model.ProxyEnvInfo
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		for _, re := range profileExportRegexes {
+			matches := re.FindStringSubmatch(line)
+			if matches == nil {
+				continue
+			}
+			if isProxyEnvName(matches[1]) {
+				value := strings.Trim(strings.TrimSpace(matches[2]), `"'`)
+				vars = append(vars, proxyEnv(matches[1], value, display+":"+strconv.Itoa(lineNo)))
+			}
+			break
+		}
+	}
+	return vars
+}
+
+// compareProxyEnvironment 比较当前环境中的代理变量和系统代理设置，一致时返回空字符串
+func compareProxyEnvironment(info *model.ProxyInfo) string {
+	var envHost string
+	for _, env := range info.Environment {
+		name := strings.ToUpper(env.Name)
+		if env.Source != proxyEnvSourceCurrent || name == "NO_PROXY" || env.Value == "" {
+			continue
+		}
+		if u, err := url.Parse(env.Value); err == nil && u.Hostname() != "" {
+			envHost = u.Hostname()
+		} else {
+			envHost, _, _ = strings.Cut(env.Value, ":")
+		}
+		break
+	}
+
+	switch {
+	case envHost != "" && !info.Enabled:
+		return "环境变量设置了代理，但系统没有设置代理：命令行工具经过代理，浏览器等图形界面应用直接连接"
+	case envHost == "" && info.Server != "":
+		return "系统设置了代理，但环境变量中没有代理：浏览器等图形界面应用经过代理，curl、git、pip等命令行工具直接连接"
+	case envHost == "" && info.Enabled:
+		return "系统使用自动代理配置，但环境变量中没有代理：curl、git、pip等命令行工具直接连接"
+	case envHost != "" && info.Server != "" && !strings.EqualFold(envHost, info.Server):
+		return "环境变量中的代理（" + envHost + "）与系统代理（" + info.Server + "）不一致"
+	}
+	return ""
+}
+
+// isProxyEnvName 判断变量名是否为代理环境变量
+func isProxyEnvName(name string) bool {
+	for _, n := range proxyEnvNames {
+		if strings.EqualFold(name, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyEnv 生成代理变量记录，隐藏地址中的密码
+func proxyEnv(name, value, source string) model.ProxyEnvInfo {
+	redacted, _ := redactURLCredentials(value)
+	return model.ProxyEnvInfo{Name: name, Value: redacted, Source: source}
+}
@@ -0,0 +1,95 @@
+package analysis
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/netprobe"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// EnrichPublicIP 查询公网出口IP所属的ASN、运营商和归属地，并判断是否属于已知的VPN/代理服务商
+// ASN通过DNS查询，归属地通过HTTP接口查询，两者同时进行，任意一个失败时保留另一个的结果
+func EnrichPublicIP(info *model.NetworkInfo, cfg config.PublicIPConfig) {
+	ip := net.ParseIP(info.PublicIP)
+	if ip == nil {
+		return
+	}
+
+	var asn netprobe.ASNInfo
+	var geo netprobe.GeoInfo
+	var asnErr error
+	geoErr := errors.New("no geo url")
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		asn, asnErr = netprobe.LookupASN(ip)
+	}()
+	if cfg.GeoURL != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			geo, geoErr = netprobe.LookupGeo(cfg.GeoURL, ip.String())
+		}()
+	}
+	wg.Wait()
+
+	if asnErr == nil {
+		info.PublicASN = asn.Number
+		info.PublicISP = asn.Name
+		info.PublicCountry = asn.Country
+	}
+	if geoErr == nil {
+		// ipinfo的org字段格式为"AS4134 CHINANET-BACKBONE"，ASN查询失败时从中取出ASN和名称
+		if fields := strings.SplitN(geo.Org, " ", 2); len(fields) == 2 && strings.HasPrefix(fields[0], "AS") {
+			if info.PublicASN == "" {
+				info.PublicASN = fields[0]
+			}
+			if info.PublicISP == "" {
+				info.PublicISP = fields[1]
+			}
+		} else if info.PublicISP == "" {
+			info.PublicISP = geo.Org
+		}
+		// ASN的注册国家不一定是IP实际所在的国家，优先使用归属地接口的结果
+		if geo.Country != "" {
+			info.PublicCountry = geo.Country
+		}
+		info.PublicRegion = joinNonEmpty(" ", geo.Region, geo.City)
+	}
+	info.EgressProvider = matchEgressProvider(cfg.Providers, info.PublicASN, info.PublicISP, geo.Org)
+}
+
+// matchEgressProvider 按ASN或运营商名称中的关键字匹配VPN/代理服务商，没有匹配时返回空字符串
+func matchEgressProvider(providers []config.EgressProvider, asn string, names ...string) string {
+	for _, provider := range providers {
+		for _, number := range provider.ASNs {
+			if asn != "" && strings.EqualFold(number, asn) {
+				return provider.Name
+			}
+		}
+		for _, keyword := range provider.Keywords {
+			for _, name := range names {
+				if keyword != "" && strings.Contains(strings.ToLower(name), strings.ToLower(keyword)) {
+					return provider.Name
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// joinNonEmpty 用分隔符连接非空且不重复的字符串
+func joinNonEmpty(sep string, values ...string) string {
+	var parts []string
+	for _, value := range values {
+		if value != "" && (len(parts) == 0 || parts[len(parts)-1] != value) {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, sep)
+}
@@ -0,0 +1,108 @@
+package analysis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/netprobe"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// 响应能力等级，与macOS networkQuality的划分一致
+const (
+	ResponsivenessHigh   = "高"
+	ResponsivenessMedium = "中"
+	ResponsivenessLow    = "低"
+)
+
+// 响应能力等级的RPM阈值
+const (
+	rpmMedium = 300
+	rpmHigh   = 1000
+)
+
+// 测试方式
+const (
+	qualitySourceSystem  = "networkQuality"
+	qualitySourceBuiltin = "内置负载测试"
+)
+
+// ParseNetworkQuality 解析macOS networkQuality -c输出的JSON，例如：
+//
+//	{"base_rtt": 18.2, "dl_throughput": 412345678, "ul_throughput": 32456789, "responsiveness": 1234}
+//
+// 吞吐量的单位为bit/s，负载下延迟由RPM换算：60000/RPM毫秒
+func ParseNetworkQuality(output []byte) (model.NetworkQualityInfo, error) {
+	info := model.NetworkQualityInfo{Source: qualitySourceSystem}
+	var result struct {
+		Responsiveness float64 `json:"responsiveness"`
+		BaseRTT        float64 `json:"base_rtt"`
+		DLThroughput   float64 `json:"dl_throughput"`
+		ULThroughput   float64 `json:"ul_throughput"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return info, err
+	}
+	if result.Responsiveness <= 0 {
+		return info, errors.New("networkQuality reported no responsiveness")
+	}
+	info.RPM = int(math.Round(result.Responsiveness))
+	info.IdleLatency = result.BaseRTT
+	info.LoadedLatency = 60000 / result.Responsiveness
+	info.Download = result.DLThroughput / 1e6
+	info.Upload = result.ULThroughput / 1e6
+	assessQuality(&info)
+	return info, nil
+}
+
+// MeasureNetworkQuality 使用内置的负载下延迟测试评估响应能力，用于Windows和没有networkQuality的旧版macOS
+func MeasureNetworkQuality(cfg config.QualityConfig) model.NetworkQualityInfo {
+	info := model.NetworkQualityInfo{Source: qualitySourceBuiltin}
+	result, err := netprobe.MeasureLoadedLatency(cfg.DownloadURL, cfg.Streams, time.Duration(cfg.Duration)*time.Second)
+	if err != nil {
+		info.Error = err.Error()
+		info.Assessment = "无法完成负载下延迟测试"
+		return info
+	}
+	if len(result.Loaded) == 0 {
+		info.Error = fmt.Sprintf("%d probes timed out under load", result.LoadedLost)
+		info.Assessment = "下载占满带宽时无法与服务器建立连接，存在严重的缓冲区膨胀或丢包"
+		info.Rating = ResponsivenessLow
+		return info
+	}
+
+	idle, loaded := result.Medians()
+	info.IdleLatency = milliseconds(idle)
+	info.LoadedLatency = milliseconds(loaded)
+	info.RPM = int(math.Round(60000 / info.LoadedLatency))
+	info.Download = result.Throughput * 8 / 1e6
+	assessQuality(&info)
+	return info
+}
+
+// assessQuality 根据RPM划分等级，并计算负载下延迟比空闲时增加的部分
+func assessQuality(info *model.NetworkQualityInfo) {
+	if info.IdleLatency > 0 {
+		info.Bufferbloat = math.Max(0, info.LoadedLatency-info.IdleLatency)
+	}
+	detail := fmt.Sprintf("负载下延迟 %.0fms", info.LoadedLatency)
+	if info.IdleLatency > 0 {
+		detail += fmt.Sprintf("，空闲时 %.0fms", info.IdleLatency)
+	}
+	switch {
+	case info.RPM < rpmMedium:
+		info.Rating = ResponsivenessLow
+		info.Assessment = detail + "，存在严重的缓冲区膨胀，上传或下载大文件时视频会议、远程桌面和网页浏览会明显卡顿，" +
+			"可以在路由器上开启SQM（fq_codel/CAKE）或智能QoS"
+	case info.RPM < rpmHigh:
+		info.Rating = ResponsivenessMedium
+		info.Assessment = detail + "，存在一定的缓冲区膨胀，大量下载时实时应用可能受到影响"
+	default:
+		info.Rating = ResponsivenessHigh
+		info.Assessment = detail + "，负载下延迟稳定，没有明显的缓冲区膨胀"
+	}
+}
@@ -0,0 +1,105 @@
+package analysis
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// saasSuites 内置的SaaS服务及其登录、接口和主要功能的端点
+var saasSuites = []struct {
+	name    string
+	targets []config.EndpointTarget
+}{
+	{"Microsoft 365", []config.EndpointTarget{
+		{Name: "登录", URL: "https://login.microsoftonline.com/"},
+		{Name: "Outlook", URL: "https://outlook.office365.com/"},
+		{Name: "Office", URL: "https://www.office.com/"},
+		{Name: "Teams", URL: "https://teams.microsoft.com/"},
+	}},
+	{"Zoom", []config.EndpointTarget{
+		{Name: "Web", URL: "https://zoom.us/"},
+		{Name: "API", URL: "https://api.zoom.us/"},
+	}},
+	{"Google Workspace", []config.EndpointTarget{
+		{Name: "登录", URL: "https://accounts.google.com/"},
+		{Name: "Gmail", URL: "https://mail.google.com/"},
+		{Name: "Drive", URL: "https://drive.google.com/"},
+		{Name: "Meet", URL: "https://meet.google.com/"},
+	}},
+	{"飞书", []config.EndpointTarget{
+		{Name: "Web", URL: "https://www.feishu.cn/"},
+		{Name: "开放平台", URL: "https://open.feishu.cn/"},
+	}},
+	{"钉钉", []config.EndpointTarget{
+		{Name: "Web", URL: "https://www.dingtalk.com/"},
+		{Name: "开放平台", URL: "https://oapi.dingtalk.com/"},
+	}},
+}
+
+// saasSlowTime SaaS端点平均总耗时超过该值（毫秒）时认为访问较慢
+const saasSlowTime = 2000
+
+// CheckSaaS 同时检查配置中选择的内置SaaS服务的所有端点
+func CheckSaaS(cfg config.SaaSConfig, inspectionIssuers []string) []model.SaaSSuiteInfo {
+	var suites []model.SaaSSuiteInfo
+	var targets [][]config.EndpointTarget
+	for _, name := range cfg.Suites {
+		found := false
+		for _, suite := range saasSuites {
+			if strings.EqualFold(suite.name, name) {
+				suites = append(suites, model.SaaSSuiteInfo{Name: suite.name})
+				targets = append(targets, suite.targets)
+				found = true
+				break
+			}
+		}
+		if !found {
+			log.Printf("Unknown SaaS suite %q", name)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range suites {
+		wg.Add(1)
+		go func(suite *model.SaaSSuiteInfo, targets []config.EndpointTarget) {
+			defer wg.Done()
+			suite.Checks = CheckEndpoints(config.EndpointConfig{Targets: targets, InspectionIssuers: inspectionIssuers})
+			summarizeSaaSSuite(suite)
+		}(&suites[i], targets[i])
+	}
+	wg.Wait()
+	return suites
+}
+
+// summarizeSaaSSuite 统计可以访问的端点数量和平均耗时
+func summarizeSaaSSuite(suite *model.SaaSSuiteInfo) {
+	var total float64
+	var failed []string
+	for _, check := range suite.Checks {
+		if check.Error != "" {
+			failed = append(failed, check.Name)
+			continue
+		}
+		suite.Reachable++
+		total += check.TotalTime
+	}
+	if suite.Reachable > 0 {
+		suite.AvgTime = total / float64(suite.Reachable)
+	}
+
+	switch {
+	case suite.Reachable == 0:
+		suite.Assessment = "无法访问"
+	case len(failed) > 0:
+		suite.Assessment = "部分端点无法访问：" + strings.Join(failed, "、")
+	case suite.AvgTime > saasSlowTime:
+		suite.Assessment = fmt.Sprintf("可以访问，但平均耗时 %.0fms，较慢", suite.AvgTime)
+	default:
+		suite.Assessment = "正常"
+	}
+}
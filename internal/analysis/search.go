@@ -0,0 +1,24 @@
+package analysis
+
+import (
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// indexerBusyCPU 索引进程CPU使用率合计超过该值（百分比）时认为正在大量索引
+const indexerBusyCPU = 50.0
+
+// EvaluateIndexerLoad 汇总索引相关进程的CPU使用率，判断是否正在大量重建索引
+// names为索引进程名称（不区分大小写）
+func EvaluateIndexerLoad(index *model.SearchIndexInfo, processes []model.ProcessInfo, names []string) {
+	for _, p := range processes {
+		for _, name := range names {
+			if strings.EqualFold(p.Name, name) {
+				index.IndexerCPU += p.CPU
+				break
+			}
+		}
+	}
+	index.Busy = index.IndexerCPU >= indexerBusyCPU
+}
@@ -0,0 +1,135 @@
+package analysis
+
+import (
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// 隧道模式
+const (
+	TunnelFull  = "全隧道"
+	TunnelSplit = "分离隧道"
+	TunnelNone  = "没有路由经过隧道"
+)
+
+// tunnelInterfaceRegex 常见的隧道接口名称，用于客户端没有报告隧道接口时识别
+var tunnelInterfaceRegex = regexp.MustCompile(`^(utun|ppp|ipsec|tun|tap|wg)\d+$`)
+
+// Route 表示用于分析的IPv4路由
+type Route struct {
+	Prefix    *net.IPNet
+	Gateway   string
+	Interface string
+	Metric    int // 相同前缀的路由中优先使用跃点数小的，没有跃点数时为0
+}
+
+// AnalyzeSplitTunnel 分析VPN连接时的路由表：默认路由（或0.0.0.0/1和128.0.0.0/1两条路由）经过隧道为全隧道，
+// 否则列出经过隧道的网段，并按最长前缀匹配检查配置的公司网段是否经过隧道
+func AnalyzeSplitTunnel(vpn model.VPNInfo, routes []Route, corporateSubnets []string) model.SplitTunnelInfo {
+	var info model.SplitTunnelInfo
+	tunnels := make(map[string]bool)
+	for _, name := range vpn.Interfaces {
+		tunnels[name] = true
+	}
+	for _, route := range routes {
+		if tunnelInterfaceRegex.MatchString(route.Interface) && !route.Prefix.IP.IsLinkLocalUnicast() && !route.Prefix.IP.IsMulticast() {
+			tunnels[route.Interface] = true
+		}
+	}
+	for name := range tunnels {
+		info.Interfaces = append(info.Interfaces, name)
+	}
+	sort.Strings(info.Interfaces)
+
+	if defaultRoute := lookupRoute(routes, net.IPv4zero, 0); defaultRoute != nil {
+		info.DefaultInterface = defaultRoute.Interface
+	}
+	lowerHalf := lookupRoute(routes, net.IPv4(1, 0, 0, 0), 1)
+	upperHalf := lookupRoute(routes, net.IPv4(128, 0, 0, 0), 1)
+	fullTunnel := tunnels[info.DefaultInterface] ||
+		(lowerHalf != nil && upperHalf != nil && tunnels[lowerHalf.Interface] && tunnels[upperHalf.Interface])
+
+	for _, route := range routes {
+		ones, _ := route.Prefix.Mask.Size()
+		prefix := route.Prefix.String()
+		switch {
+		case tunnels[route.Interface]:
+			if !route.Prefix.IP.IsLinkLocalUnicast() && !route.Prefix.IP.IsMulticast() {
+				info.TunnelRoutes = appendUnique(info.TunnelRoutes, prefix)
+			}
+		case fullTunnel && ones > 0 && !route.Prefix.IP.IsLoopback() && !route.Prefix.IP.IsLinkLocalUnicast() &&
+			!route.Prefix.IP.IsMulticast() && !route.Prefix.IP.Equal(net.IPv4bcast):
+			info.BypassRoutes = appendUnique(info.BypassRoutes, prefix)
+		}
+	}
+
+	for _, subnet := range corporateSubnets {
+		info.Subnets = append(info.Subnets, checkSubnetRoute(routes, tunnels, subnet))
+	}
+
+	var missing []string
+	for _, subnet := range info.Subnets {
+		if !subnet.Tunneled {
+			missing = append(missing, subnet.Subnet)
+		}
+	}
+	switch {
+	case fullTunnel:
+		info.Mode = TunnelFull
+		info.Assessment = "所有流量都经过VPN隧道，访问互联网的速度取决于VPN服务器"
+	case len(info.TunnelRoutes) > 0:
+		info.Mode = TunnelSplit
+		info.Assessment = "只有经过隧道的网段使用VPN，其他流量直接访问互联网"
+	default:
+		info.Mode = TunnelNone
+		info.Assessment = "VPN已连接，但没有路由经过隧道接口，所有流量都直接访问"
+	}
+	if len(missing) > 0 {
+		info.Assessment += "；公司网段 " + strings.Join(missing, ", ") + " 没有经过隧道，分离隧道配置可能缺少这些网段，或与本地网络网段冲突"
+	}
+	return info
+}
+
+// checkSubnetRoute 查找公司网段的网络地址匹配的路由，配置为IP地址时按/32处理
+func checkSubnetRoute(routes []Route, tunnels map[string]bool, subnet string) model.SplitTunnelSubnet {
+	result := model.SplitTunnelSubnet{Subnet: subnet}
+	ip, ipNet, err := net.ParseCIDR(subnet)
+	if err != nil {
+		if ip = net.ParseIP(subnet); ip == nil {
+			return result
+		}
+	} else {
+		ip = ipNet.IP
+	}
+	if ip.To4() == nil {
+		return result
+	}
+
+	if route := lookupRoute(routes, ip, -1); route != nil {
+		result.Route = route.Prefix.String()
+		result.Interface = route.Interface
+		result.Tunneled = tunnels[route.Interface]
+	}
+	return result
+}
+
+// lookupRoute 按最长前缀匹配查找路由，前缀长度相同时取跃点数最小的；prefixLen不为-1时只匹配该长度的路由
+func lookupRoute(routes []Route, ip net.IP, prefixLen int) *Route {
+	var best *Route
+	bestLen := -1
+	for i := range routes {
+		route := &routes[i]
+		ones, bits := route.Prefix.Mask.Size()
+		if bits != 32 || !route.Prefix.Contains(ip) || (prefixLen >= 0 && ones != prefixLen) {
+			continue
+		}
+		if ones > bestLen || (ones == bestLen && route.Metric < best.Metric) {
+			best, bestLen = route, ones
+		}
+	}
+	return best
+}
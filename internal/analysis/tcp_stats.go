@@ -0,0 +1,88 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// TCPSampleWindow TCP统计的采样时长，重传通常是零星发生的，采样时间比进程CPU采样长
+const TCPSampleWindow = 5 * time.Second
+
+// 重传率阈值（%），超过时认为路径上存在丢包或拥塞
+const (
+	retransmitRateWarning = 1.0
+	retransmitRateHigh    = 3.0
+)
+
+// minSampledSegments 采样窗口内发送的报文段少于该值时，重传率没有参考价值，改用开机以来的累计重传率评估
+const minSampledSegments = 200
+
+// TCPCounters 表示系统开机以来的TCP累计计数
+type TCPCounters struct {
+	SegmentsSent     uint64
+	SegmentsReceived uint64
+	Retransmitted    uint64
+	Resets           uint64
+	Failures         uint64
+	Errors           uint64
+}
+
+// SampleTCPStats 间隔window两次读取TCP累计计数，计算采样窗口内的重传率和错误数
+// 平均延迟无法反映偶发的丢包，重传率高说明路径上存在丢包或拥塞。ctx被取消时停止等待
+func SampleTCPStats(ctx context.Context, read func() (TCPCounters, error), window time.Duration) (model.TCPStatsInfo, error) {
+	info := model.TCPStatsInfo{SampleWindow: window.String()}
+	first, err := read()
+	if err != nil {
+		return info, err
+	}
+	select {
+	case <-time.After(window):
+	case <-ctx.Done():
+		return info, ctx.Err()
+	}
+	second, err := read()
+	if err != nil {
+		return info, err
+	}
+
+	info.SegmentsSent = counterDelta(first.SegmentsSent, second.SegmentsSent)
+	info.SegmentsReceived = counterDelta(first.SegmentsReceived, second.SegmentsReceived)
+	info.Retransmitted = counterDelta(first.Retransmitted, second.Retransmitted)
+	info.Resets = counterDelta(first.Resets, second.Resets)
+	info.Failures = counterDelta(first.Failures, second.Failures)
+	info.Errors = counterDelta(first.Errors, second.Errors)
+	if info.SegmentsSent > 0 {
+		info.RetransmitRate = float64(info.Retransmitted) / float64(info.SegmentsSent) * 100
+	}
+	if second.SegmentsSent > 0 {
+		info.TotalRetransmitRate = float64(second.Retransmitted) / float64(second.SegmentsSent) * 100
+	}
+
+	rate, scope := info.RetransmitRate, "采样期间"
+	if info.SegmentsSent < minSampledSegments {
+		rate, scope = info.TotalRetransmitRate, "开机以来"
+	}
+	switch {
+	case rate >= retransmitRateHigh:
+		info.Assessment = fmt.Sprintf("%sTCP重传率为 %.2f%%，路径上存在明显的丢包或拥塞，即使平均延迟正常，视频会议和文件传输也会卡顿", scope, rate)
+	case rate >= retransmitRateWarning:
+		info.Assessment = fmt.Sprintf("%sTCP重传率为 %.2f%%，路径上存在一定的丢包", scope, rate)
+	default:
+		info.Assessment = fmt.Sprintf("%sTCP重传率为 %.2f%%，正常", scope, rate)
+	}
+	if info.Errors > 0 {
+		info.Assessment += fmt.Sprintf("；采样期间收到 %d 个无效报文段，网卡、网线或驱动可能有问题", info.Errors)
+	}
+	return info, nil
+}
+
+// counterDelta 计算两次读取之间的增量，计数器被重置或回绕时为0
+func counterDelta(before, after uint64) uint64 {
+	if after < before {
+		return 0
+	}
+	return after - before
+}
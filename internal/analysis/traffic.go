@@ -0,0 +1,58 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// trafficSummaryCount 流量摘要中列出的进程数量
+const trafficSummaryCount = 3
+
+// RankProcessTraffic 根据采样窗口计算各进程的收发速率，去掉没有流量的进程，按收发合计从大到小排序
+func RankProcessTraffic(traffic []model.ProcessTrafficInfo, window time.Duration) []model.ProcessTrafficInfo {
+	var ranked []model.ProcessTrafficInfo
+	for _, t := range traffic {
+		if t.BytesIn == 0 && t.BytesOut == 0 {
+			continue
+		}
+		t.RateIn = float64(t.BytesIn) / window.Seconds()
+		t.RateOut = float64(t.BytesOut) / window.Seconds()
+		ranked = append(ranked, t)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		return ranked[i].BytesIn+ranked[i].BytesOut > ranked[j].BytesIn+ranked[j].BytesOut
+	})
+	return ranked
+}
+
+// SummarizeProcessTraffic 生成流量最大的几个进程的摘要，例如“Chrome 120.5 KB/s，Dropbox 30.2 KB/s”
+func SummarizeProcessTraffic(ranked []model.ProcessTrafficInfo) string {
+	if len(ranked) == 0 {
+		return "0 KB/s"
+	}
+	var parts []string
+	for i, t := range ranked {
+		if i >= trafficSummaryCount {
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s %.2f KB/s", t.Process, (t.RateIn+t.RateOut)/1024))
+	}
+	return strings.Join(parts, "，")
+}
+
+// ApplyProcessTraffic 将各进程的网络速率填入进程列表，供资源占用汇总使用
+func ApplyProcessTraffic(processes []model.ProcessInfo, traffic []model.ProcessTrafficInfo) {
+	rates := make(map[int]uint64)
+	for _, t := range traffic {
+		rates[int(t.PID)] += uint64(t.RateIn + t.RateOut)
+	}
+	for i := range processes {
+		if rate, ok := rates[processes[i].PID]; ok {
+			processes[i].NetworkUsage = rate
+		}
+	}
+}
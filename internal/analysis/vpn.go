@@ -0,0 +1,170 @@
+package analysis
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/process"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// VPN客户端的检测依据
+const (
+	VPNSourceProcess = "进程"
+	VPNSourceCLI     = "命令行工具"
+)
+
+// vpnClients 已知的VPN客户端及其进程名、网络扩展标识符、虚拟网卡描述中的关键字（小写）
+var vpnClients = []struct {
+	provider string
+	keywords []string
+}{
+	{"WireGuard", []string{"wireguard"}},
+	{"Tailscale", []string{"tailscale"}},
+	{"Zscaler", []string{"zscaler", "zsatunnel", "zsatray"}},
+	{"GlobalProtect", []string{"globalprotect", "pangp", "paloalto"}},
+	{"FortiClient", []string{"forticlient", "fortitray", "fortissl", "fortinet", "openfortivpn", "fctservctl"}},
+	{"Cisco AnyConnect", []string{"anyconnect", "secureclient", "vpnagent"}},
+	{"OpenVPN", []string{"openvpn"}},
+}
+
+// VPNProvider 根据进程名、网络扩展标识符或网卡描述判断所属的VPN客户端，无法识别时返回空字符串
+func VPNProvider(name string) string {
+	name = strings.ToLower(name)
+	for _, client := range vpnClients {
+		for _, keyword := range client.keywords {
+			if strings.Contains(name, keyword) {
+				return client.provider
+			}
+		}
+	}
+	return ""
+}
+
+// AddVPNClient 记录检测到的VPN客户端，同一个客户端的多条检测结果合并为一条
+func AddVPNClient(info *model.VPNInfo, client model.VPNClientInfo) {
+	for i := range info.Clients {
+		existing := &info.Clients[i]
+		if existing.Provider != client.Provider {
+			continue
+		}
+		existing.Running = existing.Running || client.Running
+		existing.Connected = existing.Connected || client.Connected
+		if existing.Interface == "" {
+			existing.Interface = client.Interface
+		}
+		if existing.Node == "" {
+			existing.Node = client.Node
+		}
+		if !strings.Contains(existing.Source, client.Source) {
+			existing.Source += "、" + client.Source
+		}
+		return
+	}
+	info.Clients = append(info.Clients, client)
+}
+
+// DetectVPNProcesses 通过正在运行的进程检测VPN客户端，包括macOS的网络扩展进程
+func DetectVPNProcesses(info *model.VPNInfo) error {
+	processes, err := process.Processes()
+	if err != nil {
+		return err
+	}
+	for _, p := range processes {
+		name, err := p.Name()
+		if err != nil {
+			continue
+		}
+		if provider := VPNProvider(name); provider != "" {
+			AddVPNClient(info, model.VPNClientInfo{Provider: provider, Running: true, Source: VPNSourceProcess})
+		}
+	}
+	return nil
+}
+
+// ParseTailscaleStatus 解析tailscale status --json的输出，节点为使用中的出口节点，没有时为tailnet名称
+func ParseTailscaleStatus(output []byte) (model.VPNClientInfo, error) {
+	client := model.VPNClientInfo{Provider: "Tailscale", Running: true, Source: VPNSourceCLI}
+	var status struct {
+		BackendState   string
+		CurrentTailnet *struct{ Name string }
+		Peer           map[string]struct {
+			HostName string
+			ExitNode bool
+		}
+	}
+	if err := json.Unmarshal(output, &status); err != nil {
+		return client, err
+	}
+
+	client.Connected = status.BackendState == "Running"
+	if status.CurrentTailnet != nil {
+		client.Node = status.CurrentTailnet.Name
+	}
+	for _, peer := range status.Peer {
+		if peer.ExitNode {
+			client.Node = peer.HostName + "（出口节点）"
+			break
+		}
+	}
+	return client, nil
+}
+
+// ParseWireGuardShow 解析wg show的输出，有最近握手记录的隧道视为已连接，例如：
+//
+//	interface: utun3
+//	peer: xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg=
+//	  endpoint: 203.0.113.1:51820
+//	  latest handshake: 1 minute, 2 seconds ago
+func ParseWireGuardShow(output string) []model.VPNClientInfo {
+	var clients []model.VPNClientInfo
+	var current *model.VPNClientInfo
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "interface":
+			clients = append(clients, model.VPNClientInfo{Provider: "WireGuard", Running: true, Interface: value, Source: VPNSourceCLI})
+			current = &clients[len(clients)-1]
+		case "endpoint":
+			if current != nil && current.Node == "" {
+				current.Node = value
+			}
+		case "latest handshake":
+			if current != nil {
+				current.Connected = true
+			}
+		}
+	}
+	return clients
+}
+
+// SummarizeVPN 根据检测到的客户端填写连接状态、提供商、节点和隧道接口
+func SummarizeVPN(info *model.VPNInfo) {
+	for _, client := range info.Clients {
+		if !client.Connected {
+			continue
+		}
+		if !info.IsConnected {
+			info.IsConnected = true
+			info.Provider = client.Provider
+			if client.Node != "" {
+				info.NodeName = client.Node
+			}
+		}
+		if client.Interface != "" {
+			info.Interfaces = appendUnique(info.Interfaces, client.Interface)
+		}
+	}
+	if info.Status == "" {
+		if info.IsConnected {
+			info.Status = "已连接"
+		} else {
+			info.Status = "未连接"
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// 无线频段
+const (
+	Band24GHz = "2.4GHz"
+	Band5GHz  = "5GHz"
+	Band6GHz  = "6GHz"
+)
+
+// candidateChannels 各频段建议使用的信道：2.4GHz只有1、6、11互不重叠，5GHz选择不需要DFS雷达检测的信道
+var candidateChannels = map[string][]int{
+	Band24GHz: {1, 6, 11},
+	Band5GHz:  {36, 40, 44, 48, 149, 153, 157, 161, 165},
+}
+
+// AnalyzeWiFiScan 统计扫描到的无线网络在各信道上的分布，评估当前连接信道的同信道和相邻信道干扰，
+// 并在有明显更空闲的信道时给出建议
+func AnalyzeWiFiScan(current model.WiFiInfo, networks []model.WiFiScanResultInfo) model.WiFiScanInfo {
+	var scan model.WiFiScanInfo
+	for _, network := range networks {
+		if network.Channel <= 0 {
+			continue
+		}
+		if network.Band == "" {
+			network.Band = bandForChannel(network.Channel)
+		}
+		network.Security = WiFiSecurity(network.Security)
+		scan.Networks = append(scan.Networks, network)
+	}
+	// 信号强度未知（0）的网络排在最后
+	sort.SliceStable(scan.Networks, func(i, j int) bool {
+		return sortableRSSI(scan.Networks[i].RSSI) > sortableRSSI(scan.Networks[j].RSSI)
+	})
+
+	channels := make(map[string]*model.WiFiChannelInfo)
+	for _, network := range scan.Networks {
+		key := fmt.Sprintf("%s/%d", network.Band, network.Channel)
+		channel, ok := channels[key]
+		if !ok {
+			channel = &model.WiFiChannelInfo{Band: network.Band, Channel: network.Channel, Strongest: network.RSSI}
+			channels[key] = channel
+		}
+		channel.Networks++
+		if network.RSSI != 0 && (channel.Strongest == 0 || network.RSSI > channel.Strongest) {
+			channel.Strongest = network.RSSI
+		}
+	}
+	for _, channel := range channels {
+		_, channel.Adjacent = channelLoad(scan.Networks, channel.Band, channel.Channel, nil)
+		scan.Channels = append(scan.Channels, *channel)
+	}
+	sort.Slice(scan.Channels, func(i, j int) bool {
+		if scan.Channels[i].Band != scan.Channels[j].Band {
+			return scan.Channels[i].Band < scan.Channels[j].Band
+		}
+		return scan.Channels[i].Channel < scan.Channels[j].Channel
+	})
+
+	if !current.IsConnected || current.Channel <= 0 {
+		return scan
+	}
+
+	// 排除当前连接的网络本身
+	own := func(network model.WiFiScanResultInfo) bool {
+		if current.BSSID != "" && network.BSSID != "" {
+			return strings.EqualFold(current.BSSID, network.BSSID)
+		}
+		return current.SSID != "" && network.SSID == current.SSID && network.Channel == current.Channel
+	}
+	band := bandForChannel(current.Channel)
+	if current.Frequency >= 5.9 {
+		band = Band6GHz
+	}
+	co, adjacent := channelLoad(scan.Networks, band, current.Channel, own)
+
+	level := "空闲"
+	switch load := co + adjacent; {
+	case load > 5:
+		level = "拥挤，可能导致速率下降和延迟抖动"
+	case load > 2:
+		level = "较拥挤"
+	}
+	scan.Summary = fmt.Sprintf("信道%d（%s）有%d个其他网络同信道", current.Channel, band, co)
+	if band == Band24GHz {
+		scan.Summary += fmt.Sprintf("、%d个网络在重叠的相邻信道", adjacent)
+	}
+	scan.Summary += "，" + level
+
+	best, bestCo, bestAdjacent := 0, 0, 0
+	for _, channel := range candidateChannels[band] {
+		c, a := channelLoad(scan.Networks, band, channel, own)
+		if best == 0 || c+a < bestCo+bestAdjacent {
+			best, bestCo, bestAdjacent = channel, c, a
+		}
+	}
+	// 只有明显更空闲时才建议更换信道
+	if best != 0 && best != current.Channel && bestCo+bestAdjacent+2 <= co+adjacent {
+		scan.Suggestion = fmt.Sprintf("将路由器的%s信道改为%d（同信道%d个网络", band, best, bestCo)
+		if band == Band24GHz {
+			scan.Suggestion += fmt.Sprintf("、相邻信道%d个网络", bestAdjacent)
+		}
+		scan.Suggestion += "）"
+	}
+	return scan
+}
+
+// channelLoad 统计与指定信道相同以及在2.4GHz频段上与其重叠（相差不到5个信道）的网络数量，skip返回true的网络不计入
+func channelLoad(networks []model.WiFiScanResultInfo, band string, channel int, skip func(model.WiFiScanResultInfo) bool) (co, adjacent int) {
+	for _, network := range networks {
+		if network.Band != band || (skip != nil && skip(network)) {
+			continue
+		}
+		diff := network.Channel - channel
+		if diff < 0 {
+			diff = -diff
+		}
+		switch {
+		case diff == 0:
+			co++
+		case band == Band24GHz && diff < 5:
+			adjacent++
+		}
+	}
+	return co, adjacent
+}
+
+// WiFiSecurity 将系统报告的安全类型统一为Open、WEP、OWE、WPA/WPA2/WPA3-Personal或Enterprise等名称
+// macOS的格式为"WPA2 Personal"、"WPA2/WPA3 Personal"、"None"，Windows的格式为"WPA2-Personal"、"Open"
+func WiFiSecurity(raw string) string {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	kind := "Personal"
+	if strings.Contains(s, "enterprise") || strings.Contains(s, "802.1x") {
+		kind = "Enterprise"
+	}
+	switch {
+	case s == "":
+		return ""
+	case s == "none" || s == "open":
+		return "Open"
+	case strings.Contains(s, "owe") || strings.Contains(s, "enhanced open"):
+		return "OWE"
+	case strings.Contains(s, "wep") || s == "shared":
+		return "WEP"
+	case strings.Contains(s, "wpa2") && strings.Contains(s, "wpa3"):
+		return "WPA2/WPA3-" + kind
+	case strings.Contains(s, "wpa3"):
+		return "WPA3-" + kind
+	case strings.Contains(s, "wpa2"):
+		return "WPA2-" + kind
+	case strings.Contains(s, "wpa"):
+		return "WPA-" + kind
+	}
+	return strings.TrimSpace(raw)
+}
+
+// WeakWiFiSecurity 判断安全类型是否不加密或使用已被攻破的加密方式
+func WeakWiFiSecurity(security string) bool {
+	return security == "Open" || security == "WEP" || strings.HasPrefix(security, "WPA-")
+}
+
+// bandForChannel 根据信道号推断频段，无法区分的6GHz信道按5GHz处理
+func bandForChannel(channel int) string {
+	if channel <= 14 {
+		return Band24GHz
+	}
+	return Band5GHz
+}
+
+// sortableRSSI 将未知的信号强度视为最弱
+func sortableRSSI(rssi int) int {
+	if rssi == 0 {
+		return -1000
+	}
+	return rssi
+}
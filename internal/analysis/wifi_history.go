@@ -0,0 +1,75 @@
+package analysis
+
+import (
+	"sort"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// WiFi事件类型
+const (
+	WiFiEventConnect    = "连接"
+	WiFiEventRoam       = "漫游"
+	WiFiEventDisconnect = "断开"
+	WiFiEventFailure    = "连接失败"
+)
+
+// WiFiHistoryWindow 统计WiFi事件的时间范围
+const WiFiHistoryWindow = 24 * time.Hour
+
+// wifiHistoryRecentCount 报告的最近事件数量
+const wifiHistoryRecentCount = 20
+
+// wifiReasons 常见的802.11断开（Deauthentication/Disassociation）原因代码
+var wifiReasons = map[int]string{
+	1:  "未指定原因",
+	2:  "之前的认证已失效",
+	3:  "客户端主动离开网络",
+	4:  "长时间无活动被基站断开",
+	5:  "基站负载过高",
+	6:  "未认证的客户端发送了数据帧",
+	7:  "未关联的客户端发送了数据帧",
+	8:  "客户端离开基站（漫游或关闭WiFi）",
+	9:  "未完成认证就请求关联",
+	14: "消息完整性校验失败",
+	15: "四次握手超时，密码可能错误",
+	16: "组密钥握手超时",
+	23: "802.1X认证失败",
+	34: "信道条件差导致丢包过多",
+}
+
+// WiFiReason 返回802.11原因代码的说明
+func WiFiReason(code int) string {
+	if reason, ok := wifiReasons[code]; ok {
+		return reason
+	}
+	return ""
+}
+
+// SummarizeWiFiHistory 统计各类WiFi事件的次数，并保留最近的事件
+func SummarizeWiFiHistory(since time.Time, events []model.WiFiEventInfo) model.WiFiHistoryInfo {
+	history := model.WiFiHistoryInfo{Since: since.Format("2006-01-02 15:04:05")}
+	for _, event := range events {
+		switch event.Type {
+		case WiFiEventConnect:
+			history.Connects++
+		case WiFiEventRoam:
+			history.Roams++
+		case WiFiEventDisconnect:
+			history.Disconnects++
+		case WiFiEventFailure:
+			history.Failures++
+		}
+	}
+
+	// 时间格式固定，可以直接按字符串排序
+	history.Events = append(history.Events, events...)
+	sort.SliceStable(history.Events, func(i, j int) bool {
+		return history.Events[i].Time > history.Events[j].Time
+	})
+	if len(history.Events) > wifiHistoryRecentCount {
+		history.Events = history.Events[:wifiHistoryRecentCount]
+	}
+	return history
+}
@@ -0,0 +1,144 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// 代理配置描述
+const (
+	ProxySystem = "跟随系统"
+	ProxyDirect = "不使用代理"
+)
+
+// chromiumPreferences 表示Chromium系浏览器Preferences文件中的代理设置
+type chromiumPreferences struct {
+	Proxy struct {
+		Mode   string `json:"mode"`
+		Server string `json:"server"`
+		PacURL string `json:"pac_url"`
+	} `json:"proxy"`
+}
+
+// ChromiumProfile 读取Chromium系浏览器（Chrome、Edge、Brave等）默认配置文件的扩展数量和代理设置
+// userDataDir为浏览器的用户数据目录，无法读取扩展目录时扩展数量为-1
+func ChromiumProfile(userDataDir string) (int, string) {
+	profileDir := filepath.Join(userDataDir, "Default")
+
+	extensions := -1
+	if entries, err := os.ReadDir(filepath.Join(profileDir, "Extensions")); err == nil {
+		extensions = 0
+		for _, entry := range entries {
+			if entry.IsDir() && entry.Name() != "Temp" {
+				extensions++
+			}
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(profileDir, "Preferences"))
+	if err != nil {
+		return extensions, ""
+	}
+	var prefs chromiumPreferences
+	if err := json.Unmarshal(data, &prefs); err != nil {
+		return extensions, ""
+	}
+
+	switch prefs.Proxy.Mode {
+	case "", "system":
+		return extensions, ProxySystem
+	case "direct":
+		return extensions, ProxyDirect
+	case "fixed_servers":
+		return extensions, fmt.Sprintf("手动代理 %s", prefs.Proxy.Server)
+	case "pac_script":
+		return extensions, fmt.Sprintf("PAC自动配置 %s", prefs.Proxy.PacURL)
+	case "auto_detect":
+		return extensions, "自动检测"
+	}
+	return extensions, prefs.Proxy.Mode
+}
+
+// firefoxExtensions 表示Firefox配置文件中的extensions.json
+type firefoxExtensions struct {
+	Addons []struct {
+		Type     string `json:"type"`
+		Location string `json:"location"`
+	} `json:"addons"`
+}
+
+// firefoxPrefRegex 匹配prefs.js中的user_pref设置
+var firefoxPrefRegex = regexp.MustCompile(`user_pref\("(network\.proxy\.[a-z_]+)",\s*"?([^")]*)"?\);`)
+
+// FirefoxProfile 读取Firefox默认配置文件的扩展数量和代理设置
+// profilesDir为Firefox的Profiles目录，无法读取时扩展数量为-1
+func FirefoxProfile(profilesDir string) (int, string) {
+	profileDir := defaultFirefoxProfile(profilesDir)
+	if profileDir == "" {
+		return -1, ""
+	}
+
+	extensions := -1
+	if data, err := os.ReadFile(filepath.Join(profileDir, "extensions.json")); err == nil {
+		var addons firefoxExtensions
+		if err := json.Unmarshal(data, &addons); err == nil {
+			extensions = 0
+			for _, addon := range addons.Addons {
+				// 只统计用户安装的扩展，跳过系统内置组件
+				if addon.Type == "extension" && addon.Location == "app-profile" {
+					extensions++
+				}
+			}
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(profileDir, "prefs.js"))
+	if err != nil {
+		return extensions, ""
+	}
+	prefs := make(map[string]string)
+	for _, match := range firefoxPrefRegex.FindAllStringSubmatch(string(data), -1) {
+		prefs[match[1]] = match[2]
+	}
+
+	// network.proxy.type未设置时默认为5（跟随系统）
+	switch prefs["network.proxy.type"] {
+	case "", "5":
+		return extensions, ProxySystem
+	case "0":
+		return extensions, ProxyDirect
+	case "1":
+		return extensions, fmt.Sprintf("手动代理 %s:%s", prefs["network.proxy.http"], prefs["network.proxy.http_port"])
+	case "2":
+		return extensions, fmt.Sprintf("PAC自动配置 %s", prefs["network.proxy.autoconfig_url"])
+	case "4":
+		return extensions, "自动检测"
+	}
+	return extensions, ""
+}
+
+// defaultFirefoxProfile 返回Firefox默认配置文件目录，优先选择default-release配置
+func defaultFirefoxProfile(profilesDir string) string {
+	entries, err := os.ReadDir(profilesDir)
+	if err != nil {
+		return ""
+	}
+
+	var fallback string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(entry.Name(), ".default-release") {
+			return filepath.Join(profilesDir, entry.Name())
+		}
+		if fallback == "" || strings.HasSuffix(entry.Name(), ".default") {
+			fallback = filepath.Join(profilesDir, entry.Name())
+		}
+	}
+	return fallback
+}
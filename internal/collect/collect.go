@@ -0,0 +1,149 @@
+// Package collect 并发执行采集项，每个采集项有独立的超时，同时执行的采集项数量不超过配置的并发数
+package collect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+)
+
+// Task 表示一个采集项，Run将采集结果写入传入的T。ctx在采集项超时后取消，
+// 采集项启动的命令应使用exec.CommandContext，超时后被终止
+type Task[T any] struct {
+	Name    string        // 名称，用于日志和按名称配置超时
+	Timeout time.Duration // 耗时较长的采集项的默认超时，短于collect.timeout时使用collect.timeout
+	Run     func(ctx context.Context, dst *T) error
+}
+
+// ErrTimeout 表示采集项超时，报告给Reporter的错误包装了ErrTimeout
+var ErrTimeout = errors.New("timed out")
+
+// Reporter 接收每个采集项的运行结果：name为采集项名称，err为采集项返回的错误，超时时包装了ErrTimeout
+type Reporter func(name string, err error)
+
+var (
+	reporterMu sync.RWMutex
+	reporter   Reporter
+)
+
+// SetReporter 设置接收采集项运行结果的函数，serve模式下用于 /healthz 和 /internal/metrics，为nil时不报告
+func SetReporter(r Reporter) {
+	reporterMu.Lock()
+	defer reporterMu.Unlock()
+	reporter = r
+}
+
+// report 将采集项的运行结果报告给Reporter
+func report(name string, err error) {
+	reporterMu.RLock()
+	r := reporter
+	reporterMu.RUnlock()
+	if r != nil {
+		r(name, err)
+	}
+}
+
+// Run 并发执行tasks并将结果合并到dst中，等待所有采集项完成或超时后返回
+// 每个采集项在dst的副本上运行，完成后将与开始时不同的字段合并到dst，因此采集项只能读取开始前dst中已有的信息，
+// 互相依赖的采集步骤应放在同一个采集项中按顺序执行。采集项出错时记录日志，已采集的字段仍然合并；
+// 超时的采集项不再等待并丢弃其结果，同时取消传给采集项的ctx，终止其启动的命令
+func Run[T any](dst *T, tasks []Task[T]) {
+	cfg := config.Current().Collect
+	base := *dst
+	var mu sync.Mutex
+	var g errgroup.Group
+	g.SetLimit(cfg.Parallelism)
+	for _, task := range tasks {
+		task := task
+		g.Go(func() error {
+			timeout := taskTimeout(task.Name, task.Timeout, cfg)
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			local := base
+			done := make(chan error, 1)
+			go func() { done <- task.Run(ctx, &local) }()
+			select {
+			case err := <-done:
+				if err != nil {
+					log.Printf("Error collecting %s: %v", task.Name, err)
+				}
+				mu.Lock()
+				merge(reflect.ValueOf(dst).Elem(), reflect.ValueOf(&base).Elem(), reflect.ValueOf(&local).Elem())
+				mu.Unlock()
+				report(task.Name, err)
+			case <-ctx.Done():
+				log.Printf("Collecting %s timed out after %s", task.Name, timeout)
+				report(task.Name, fmt.Errorf("%w after %s", ErrTimeout, timeout))
+			}
+			return nil
+		})
+	}
+	g.Wait()
+}
+
+// Steps 将按顺序执行的多个步骤组合为一个采集项，后面的步骤可以读取前面步骤的结果；
+// 某个步骤出错时继续执行后面的步骤，返回所有步骤的错误
+func Steps[T any](steps ...func(context.Context, *T) error) func(context.Context, *T) error {
+	return func(ctx context.Context, dst *T) error {
+		var errs []error
+		for _, step := range steps {
+			if err := step(ctx, dst); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// Field 将采集T中某个字段的采集项转换为采集T的采集项，例如将网络信息的采集项加入系统信息的采集
+func Field[T, F any](tasks []Task[F], field func(*T) *F) []Task[T] {
+	converted := make([]Task[T], len(tasks))
+	for i, task := range tasks {
+		run := task.Run
+		converted[i] = Task[T]{Name: task.Name, Timeout: task.Timeout, Run: func(ctx context.Context, dst *T) error { return run(ctx, field(dst)) }}
+	}
+	return converted
+}
+
+// taskTimeout 返回采集项的超时：collect.timeouts中按名称配置的超时，否则为采集项的默认超时和collect.timeout中较长的一个
+func taskTimeout(name string, fallback time.Duration, cfg config.CollectConfig) time.Duration {
+	if seconds, ok := cfg.Timeouts[name]; ok {
+		return time.Duration(seconds) * time.Second
+	}
+	if timeout := time.Duration(cfg.Timeout) * time.Second; timeout > fallback {
+		return timeout
+	}
+	return fallback
+}
+
+// merge 将local中与base不同的字段写入dst。结构体逐个字段比较，多个采集项写入同一结构体的不同字段时都会保留；
+// 其他类型（包括切片和映射）整体比较和替换
+func merge(dst, base, local reflect.Value) {
+	if local.Kind() == reflect.Struct && exported(local.Type()) {
+		for i := 0; i < local.NumField(); i++ {
+			merge(dst.Field(i), base.Field(i), local.Field(i))
+		}
+		return
+	}
+	if !reflect.DeepEqual(base.Interface(), local.Interface()) {
+		dst.Set(local)
+	}
+}
+
+// exported 判断结构体的字段是否都是导出的，time.Time等有未导出字段的结构体整体比较
+func exported(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() {
+			return false
+		}
+	}
+	return true
+}
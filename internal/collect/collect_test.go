@@ -0,0 +1,99 @@
+package collect
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type mergeInner struct {
+	A, B string
+}
+
+type mergeTarget struct {
+	Name  string
+	Inner mergeInner
+	Items []string
+	Tags  map[string]string
+	At    time.Time
+}
+
+func TestMerge(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name             string
+		dst, base, local mergeTarget
+		want             mergeTarget
+	}{
+		{
+			name:  "unchanged fields keep values from other tasks",
+			dst:   mergeTarget{Name: "other task"},
+			local: mergeTarget{},
+			want:  mergeTarget{Name: "other task"},
+		},
+		{
+			name:  "changed field",
+			local: mergeTarget{Name: "host"},
+			want:  mergeTarget{Name: "host"},
+		},
+		{
+			name:  "nested struct merged field by field",
+			dst:   mergeTarget{Inner: mergeInner{A: "a"}},
+			local: mergeTarget{Inner: mergeInner{B: "b"}},
+			want:  mergeTarget{Inner: mergeInner{A: "a", B: "b"}},
+		},
+		{
+			name:  "slice replaced as a whole",
+			dst:   mergeTarget{Items: []string{"x"}},
+			base:  mergeTarget{Items: []string{"x"}},
+			local: mergeTarget{Items: []string{"x", "y"}},
+			want:  mergeTarget{Items: []string{"x", "y"}},
+		},
+		{
+			name:  "slice cleared by the task",
+			dst:   mergeTarget{Items: []string{"x"}},
+			base:  mergeTarget{Items: []string{"x"}},
+			local: mergeTarget{},
+			want:  mergeTarget{},
+		},
+		{
+			name:  "map replaced as a whole",
+			dst:   mergeTarget{Tags: map[string]string{"a": "1"}},
+			base:  mergeTarget{Tags: map[string]string{"a": "1"}},
+			local: mergeTarget{Tags: map[string]string{"b": "2"}},
+			want:  mergeTarget{Tags: map[string]string{"b": "2"}},
+		},
+		{
+			name:  "struct with unexported fields compared as a whole",
+			local: mergeTarget{At: at},
+			want:  mergeTarget{At: at},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := tt.dst
+			merge(reflect.ValueOf(&dst).Elem(), reflect.ValueOf(&tt.base).Elem(), reflect.ValueOf(&tt.local).Elem())
+			if !reflect.DeepEqual(dst, tt.want) {
+				t.Errorf("merge() = %+v, want %+v", dst, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeConcurrentTasks(t *testing.T) {
+	// 两个采集项从同一个base开始，分别写入同一个结构体的不同字段，合并后都保留
+	base := mergeTarget{Name: "host"}
+	first, second := base, base
+	first.Inner.A = "a"
+	second.Inner.B = "b"
+	second.Items = []string{"x"}
+
+	dst := base
+	merge(reflect.ValueOf(&dst).Elem(), reflect.ValueOf(&base).Elem(), reflect.ValueOf(&first).Elem())
+	merge(reflect.ValueOf(&dst).Elem(), reflect.ValueOf(&base).Elem(), reflect.ValueOf(&second).Elem())
+
+	want := mergeTarget{Name: "host", Inner: mergeInner{A: "a", B: "b"}, Items: []string{"x"}}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("merge() = %+v, want %+v", dst, want)
+	}
+}
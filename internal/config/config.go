@@ -0,0 +1,1344 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 延迟探测协议
+const (
+	ProtocolICMP = "icmp"
+	ProtocolTCP  = "tcp"
+	ProtocolHTTP = "http"
+)
+
+// Config 表示SysSpector的配置文件，以JSON格式保存
+type Config struct {
+	Latency  LatencyConfig  `json:"latency"`
+	DNS      DNSConfig      `json:"dns"`
+	MTU      MTUConfig      `json:"mtu"`
+	STUN     STUNConfig     `json:"stun"`
+	PublicIP PublicIPConfig `json:"public_ip"`
+	VPN      VPNConfig      `json:"vpn"`
+	MDNS     MDNSConfig     `json:"mdns"`
+	Endpoint EndpointConfig `json:"endpoint_checks"`
+	SaaS     SaaSConfig     `json:"saas"`
+	Ports    PortConfig     `json:"port_checks"`
+	Quality  QualityConfig  `json:"network_quality"`
+	Collect  CollectConfig  `json:"collect"`
+	Server   ServerConfig   `json:"server"`
+	Push     PushConfig     `json:"push"`
+	MQTT     MQTTConfig     `json:"mqtt"`
+	History  HistoryConfig  `json:"history"`
+	Notify   NotifyConfig   `json:"notify"`
+	Device   DeviceConfig   `json:"device"`
+	Commands CommandConfig  `json:"commands"`
+	Syslog   SyslogConfig   `json:"syslog"`
+	Upload   UploadConfig   `json:"upload"`
+	Email    EmailConfig    `json:"email"`
+	Update   UpdateConfig   `json:"update"`
+
+	// Aggregate 为 aggregate 子命令（汇总多台设备推送的快照）的配置，客户端不使用
+	Aggregate AggregateConfig `json:"aggregate"`
+
+	// Offline 为true时不访问公网IP查询等外部服务，可通过 --offline 参数开启
+	Offline bool `json:"offline"`
+}
+
+// LatencyConfig 表示延迟探测配置
+type LatencyConfig struct {
+	Groups []ProbeGroup `json:"groups"`
+}
+
+// ProbeGroup 表示一组延迟探测目标，例如公司网关、DNS、SaaS服务、VPN接入点
+type ProbeGroup struct {
+	Name    string        `json:"name"`
+	Targets []ProbeTarget `json:"targets"`
+}
+
+// ProbeTarget 表示一个延迟探测目标及其告警阈值，阈值为0表示不检查
+type ProbeTarget struct {
+	Name       string  `json:"name"`
+	Host       string  `json:"host"`        // 主机名或IP地址，HTTP协议也可以是完整的URL
+	Protocol   string  `json:"protocol"`    // icmp、tcp或http，默认为icmp
+	Port       int     `json:"port"`        // TCP协议必填，HTTP协议默认为443
+	MaxLatency float64 `json:"max_latency"` // 平均延迟阈值（毫秒）
+	MaxJitter  float64 `json:"max_jitter"`  // 抖动阈值（毫秒）
+	MaxLoss    float64 `json:"max_loss"`    // 丢包率阈值（%）
+}
+
+// DNSConfig 表示DNS测试配置
+type DNSConfig struct {
+	Servers   []string `json:"servers"`    // 除系统DNS服务器外额外测试的服务器，例如公共DNS
+	TestNames []string `json:"test_names"` // 用于测试解析耗时的域名
+	Domains   []string `json:"domains"`    // 需要诊断解析结果的关键域名，例如公司内部域名
+}
+
+// MTUConfig 表示路径MTU探测配置
+type MTUConfig struct {
+	Target string `json:"target"` // 探测目标，例如VPN另一端的内网主机，默认为8.8.8.8
+}
+
+// STUNConfig 表示NAT类型检测使用的STUN服务器
+type STUNConfig struct {
+	Servers []string `json:"servers"` // host:port，按顺序尝试，需要至少两个不同IP的服务器才能判断是否为对称NAT
+}
+
+// PublicIPConfig 表示公网出口IP的归属地查询和VPN/代理服务商识别配置
+type PublicIPConfig struct {
+	Endpoints []string         `json:"endpoints"` // 公网IP查询服务，返回纯文本或{"ip": "..."}格式的JSON，同时支持IPv4和IPv6的服务可以用于检测两种出口
+	GeoURL    string           `json:"geo_url"`   // ipinfo.io格式的归属地接口，{ip}会被替换为公网IP
+	Providers []EgressProvider `json:"providers"` // 已知的VPN/代理服务商
+}
+
+// EgressProvider 表示一个VPN/代理服务商，公网IP的ASN或运营商名称匹配时认为流量经过该服务商
+type EgressProvider struct {
+	Name     string   `json:"name"`
+	ASNs     []string `json:"asns"`     // 自治系统号，例如AS53813
+	Keywords []string `json:"keywords"` // 运营商名称中的关键字，不区分大小写
+}
+
+// VPNConfig 表示VPN分离隧道检查配置
+type VPNConfig struct {
+	CorporateSubnets []string `json:"corporate_subnets"` // 应该经过VPN隧道的公司网段（CIDR或IP地址）
+}
+
+// MDNSConfig 表示局域网mDNS服务发现配置
+type MDNSConfig struct {
+	ServiceTypes []string `json:"service_types"` // 查询的服务类型，例如_ipp._tcp
+}
+
+// EndpointConfig 表示HTTP/HTTPS端点检查配置
+type EndpointConfig struct {
+	Targets           []EndpointTarget `json:"targets"`
+	InspectionIssuers []string         `json:"inspection_issuers"` // TLS解密代理签发证书时使用的签发者名称中的关键字，不区分大小写
+}
+
+// EndpointTarget 表示一个需要检查的HTTP/HTTPS端点
+type EndpointTarget struct {
+	Name           string  `json:"name"`
+	URL            string  `json:"url"`
+	ExpectedIssuer string  `json:"expected_issuer"` // 证书链中应该出现的签发者名称关键字，不一致时认为证书被重新签发，为空时不检查
+	MaxTotal       float64 `json:"max_total"`       // 总耗时阈值（毫秒），为0时不检查
+}
+
+// SaaSConfig 表示SaaS服务可达性检查配置
+type SaaSConfig struct {
+	Suites []string `json:"suites"` // 需要检查的内置SaaS服务：Microsoft 365、Zoom、Google Workspace、飞书、钉钉，为空列表时不检查
+}
+
+// PortConfig 表示出站端口可达性检查配置
+type PortConfig struct {
+	Targets []PortTarget `json:"targets"`
+}
+
+// PortTarget 表示一个需要检查的出站TCP端口
+type PortTarget struct {
+	Name string `json:"name"`
+	Host string `json:"host"` // 目标主机，默认使用在所有端口上监听的portquiz.net
+	Port int    `json:"port"`
+}
+
+// QualityConfig 表示负载下延迟测试（--network-quality）的配置，macOS优先使用系统自带的networkQuality
+type QualityConfig struct {
+	DownloadURL string `json:"download_url"` // 产生下载负载的大文件地址，负载下延迟通过与该服务器新建TCP连接测量
+	Streams     int    `json:"streams"`      // 并发下载的连接数
+	Duration    int    `json:"duration"`     // 下载负载持续的时间（秒）
+}
+
+// ServerConfig 表示REST API服务（sysinfo serve）的配置
+type ServerConfig struct {
+	Listen     string `json:"listen"`      // 监听地址，可通过 --listen 参数覆盖
+	GRPCListen string `json:"grpc_listen"` // gRPC服务的监听地址，可通过 --grpc-listen 参数覆盖，为空时不提供gRPC服务
+	CacheTTL   int    `json:"cache_ttl"`   // 在后台重新采集系统信息的间隔（秒），查询请求返回最近一次采集的结果，默认为60
+
+	// TLS 为REST和gRPC服务的证书，没有配置证书时不使用TLS；配置了ca_file或pins时要求客户端提供证书（mTLS）
+	TLS TLSConfig `json:"tls"`
+
+	// Tokens 为REST和gRPC接口的访问令牌，没有配置时不校验令牌；
+	// 轮换令牌时先加入新令牌并给旧令牌设置expires_at，客户端切换后删除旧令牌，重新加载配置后即可生效
+	Tokens []APIToken `json:"tokens"`
+
+	// AllowedOrigins 为可以连接 /v1/stream 的其他网站的页面来源，例如"https://dashboard.example.com"，
+	// 与API同源的页面和不发送Origin的客户端不受限制
+	AllowedOrigins []string `json:"allowed_origins"`
+}
+
+// 访问令牌的权限范围，权限高的范围包含权限低的范围可以访问的全部接口
+const (
+	ScopeRead    = "read"    // 查询系统信息、历史数据和指标，只返回缓存的采集结果
+	ScopeCollect = "collect" // 另外可以触发重新采集（refresh=1）、执行按需采集命令和延迟探测
+	ScopeAdmin   = "admin"   // 另外可以重新加载配置
+)
+
+// APIToken 表示serve模式下REST和gRPC接口的一个访问令牌
+type APIToken struct {
+	Name      string    `json:"name"`       // 令牌名称，用于日志，默认为"token"加序号
+	Token     string    `json:"token"`      // 令牌，或"sha256:"加令牌的SHA-256摘要（十六进制），避免在配置文件中保存明文
+	Scope     string    `json:"scope"`      // read、collect或admin，默认为read
+	ExpiresAt time.Time `json:"expires_at"` // 过期时间（RFC 3339），为零值时不过期
+}
+
+// TLSConfig 表示TLS证书配置，用于连接远程服务器（推送、MQTT、通知）或在serve模式下提供服务
+// 证书和私钥从PEM文件或系统证书存储中加载，二者只能选择一种
+type TLSConfig struct {
+	CertFile  string   `json:"cert_file"`  // 证书（PEM文件），作为客户端时为mTLS客户端证书
+	KeyFile   string   `json:"key_file"`   // 私钥（PEM文件）
+	CertStore string   `json:"cert_store"` // 系统证书存储中的证书名称：macOS钥匙串中的身份，Windows证书存储（My）中主题包含该名称的证书
+	CAFile    string   `json:"ca_file"`    // 作为客户端时为校验服务器证书的CA证书，为空时使用系统根证书；作为服务器时为校验客户端证书的CA证书
+	Pins      []string `json:"pins"`       // 证书固定：对端证书链中必须有证书公钥的SHA-256（base64编码的SubjectPublicKeyInfo摘要）在列表中
+}
+
+// clearClientCertificate 清除客户端证书，保留校验服务器证书的CA和证书固定
+func (t *TLSConfig) clearClientCertificate() {
+	t.CertFile, t.KeyFile, t.CertStore = "", "", ""
+}
+
+// HasCertificate 判断是否配置了证书
+func (t TLSConfig) HasCertificate() bool {
+	return t.CertFile != "" || t.CertStore != ""
+}
+
+// 推送快照的编码格式和压缩方式
+const (
+	PushFormatJSON     = "json"
+	PushFormatProtobuf = "protobuf"
+
+	CompressionGzip = "gzip"
+	CompressionNone = "none"
+)
+
+// PushConfig 表示将快照推送到远程收集服务器的配置
+type PushConfig struct {
+	URL         string `json:"url"`          // 接收快照的HTTPS地址，为空时不推送
+	Format      string `json:"format"`       // json（默认）或protobuf（api/sysspector/v1中的Snapshot消息）
+	Compression string `json:"compression"`  // gzip（默认）或none
+	Token       string `json:"token"`        // Bearer令牌，为空时不发送Authorization头
+	Interval    int    `json:"interval"`     // serve模式下的推送间隔（秒），为0时serve模式下不推送
+	SpoolDir    string `json:"spool_dir"`    // 推送失败时暂存快照的目录，默认为用户配置目录下的SysSpector/spool
+	MaxSpooled  int    `json:"max_spooled"`  // 最多暂存的快照数，超出时丢弃最早的快照
+	MaxAttempts int    `json:"max_attempts"` // 每个快照的最多发送次数，每次失败后等待时间加倍
+
+	// 收集服务器恢复时避免大量设备同时补发：连续失败后按带有随机抖动的指数退避等待，期间只暂存不发送，
+	// 服务器返回的Retry-After优先；补发和推送受带宽上限限制
+	MaxSpoolSize int64 `json:"max_spool_size"` // 暂存快照的总大小上限（字节），超出时丢弃最早的快照，默认为50MB
+	MaxBackoff   int   `json:"max_backoff"`    // 连续失败后两次尝试之间的最长等待时间（秒），默认为一小时
+	MaxBandwidth int64 `json:"max_bandwidth"`  // 上传带宽上限（字节/秒），为0时不限制
+
+	// Delta 为true时serve模式下每隔FullInterval秒发送一次完整快照，其他时候只发送相对上次被确认的快照的变化
+	// （JSON合并补丁，RFC 7396），只支持json格式
+	Delta        bool `json:"delta"`
+	FullInterval int  `json:"full_interval"` // 增量模式下发送完整快照的间隔（秒），默认为一天
+
+	// TLS 为mTLS客户端证书、私有CA和证书固定的配置
+	TLS TLSConfig `json:"tls"`
+}
+
+// MQTTConfig 表示将快照和动态指标发布到MQTT服务器的配置
+type MQTTConfig struct {
+	Broker           string `json:"broker"`            // 服务器地址，例如tcp://broker:1883、ssl://broker:8883，为空时不发布
+	ClientID         string `json:"client_id"`         // 客户端ID，默认为sysspector-主机名
+	Username         string `json:"username"`          // 用户名，为空时不认证
+	Password         string `json:"password"`          // 密码
+	TopicPrefix      string `json:"topic_prefix"`      // 主题前缀，{host}会被替换为主机名
+	QoS              byte   `json:"qos"`               // 服务质量等级，0（默认）、1或2
+	SnapshotInterval int    `json:"snapshot_interval"` // serve模式下发布快照的间隔（秒），为0时serve模式下不发布快照
+	MetricsInterval  int    `json:"metrics_interval"`  // serve模式下发布动态指标的间隔（秒），为0时不发布动态指标
+
+	// TLS 为ssl://、tls://、wss://地址的mTLS客户端证书、私有CA和证书固定的配置
+	TLS TLSConfig `json:"tls"`
+}
+
+// HistoryConfig 表示serve模式下本地历史数据库的配置
+type HistoryConfig struct {
+	Enabled           bool   `json:"enabled"`            // 为true时serve模式下保存快照和动态指标
+	Path              string `json:"path"`               // 数据库文件，默认为用户配置目录下的SysSpector/history.db
+	SnapshotInterval  int    `json:"snapshot_interval"`  // 保存快照的间隔（秒）
+	MetricsInterval   int    `json:"metrics_interval"`   // 采样动态指标的间隔（秒）
+	DiskInterval      int    `json:"disk_interval"`      // 记录各分区磁盘使用量的间隔（秒），用于计算磁盘增长趋势
+	SnapshotRetention int    `json:"snapshot_retention"` // 快照的保留天数
+	RawRetention      int    `json:"raw_retention"`      // 原始指标的保留小时数，之后降采样为每小时平均值
+	MetricsRetention  int    `json:"metrics_retention"`  // 降采样后的指标的保留天数
+}
+
+// 通知渠道的消息格式
+const (
+	NotifyWebhook  = "webhook"
+	NotifySlack    = "slack"
+	NotifyTeams    = "teams"
+	NotifyDingTalk = "dingtalk"
+	NotifyFeishu   = "feishu"
+)
+
+// 告警的严重程度
+const (
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// NotifyConfig 表示告警通知的配置
+type NotifyConfig struct {
+	Channels       []NotifyChannel `json:"channels"`
+	MinHealthScore int             `json:"min_health_score"` // 网络健康评分低于该值时告警，默认60
+	Interval       int             `json:"interval"`         // serve模式下检查告警的间隔（秒），为0时serve模式下不检查
+}
+
+// NotifyChannel 表示一个通知渠道
+type NotifyChannel struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`         // webhook、slack、teams、dingtalk或feishu
+	URL         string   `json:"url"`          // Webhook地址
+	Secret      string   `json:"secret"`       // 钉钉、飞书机器人的签名密钥，为空时不签名
+	MinSeverity string   `json:"min_severity"` // warning（默认）或critical，低于该级别的告警不发送到此渠道
+	Sources     []string `json:"sources"`      // 只发送这些来源的告警（latency、process、health），为空时发送全部
+
+	// TLS 为自建Webhook服务的mTLS客户端证书、私有CA和证书固定的配置
+	TLS TLSConfig `json:"tls"`
+}
+
+// DeviceConfig 表示设备ID和向设备管理服务器注册的配置
+type DeviceConfig struct {
+	Salt        string `json:"salt"`         // 计算设备ID时与硬件UUID一起哈希的盐，同一组织的设备应使用相同的盐
+	EnrollURL   string `json:"enroll_url"`   // 设备管理服务器的注册地址，为空时不注册
+	EnrollToken string `json:"enroll_token"` // 注册使用的令牌，作为Bearer令牌发送
+	StatePath   string `json:"state_path"`   // 保存注册结果（设备令牌和下发的配置）的文件，默认为用户配置目录下的SysSpector/enrollment.json
+
+	// TLS 为设备管理服务器的mTLS客户端证书、私有CA和证书固定的配置
+	TLS TLSConfig `json:"tls"`
+}
+
+// CommandConfig 表示serve模式下按需采集命令的配置，命令通过REST接口（POST /v1/commands）或长轮询接收
+type CommandConfig struct {
+	PublicKeys []string `json:"public_keys"` // 验证命令签名的Ed25519公钥（base64），可以配置多个用于轮换签名密钥，为空时不接受命令
+	KeyPath    string   `json:"key_path"`    // 本机签名执行结果的Ed25519私钥文件，不存在时自动生成，默认为用户配置目录下的SysSpector/command.key
+	MaxAge     int      `json:"max_age"`     // 命令的有效期（秒）
+	PollURL    string   `json:"poll_url"`    // 长轮询获取命令并提交结果的地址，为空时只通过REST接口接收命令
+	Token      string   `json:"token"`       // 长轮询使用的Bearer令牌，为空时使用注册时获得的设备令牌
+
+	// TLS 为长轮询的mTLS客户端证书、私有CA和证书固定的配置
+	TLS TLSConfig `json:"tls"`
+}
+
+// syslog的传输方式
+const (
+	SyslogUDP = "udp"
+	SyslogTCP = "tcp"
+	SyslogTLS = "tls"
+)
+
+// SyslogFacilities syslog设施名称对应的设施号
+var SyslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// SyslogConfig 表示将快照摘要和告警转发到syslog服务器（RFC 5424）的配置
+type SyslogConfig struct {
+	Address  string `json:"address"`  // 服务器地址，省略端口时UDP和TCP为514，TLS为6514；为空时不转发
+	Network  string `json:"network"`  // udp（默认）、tcp或tls
+	Facility string `json:"facility"` // 设施名称，默认为local0
+	Interval int    `json:"interval"` // serve模式下发送快照摘要和新告警的间隔（秒），为0时serve模式下不发送
+
+	// TLS 为tls传输的mTLS客户端证书、私有CA和证书固定的配置
+	TLS TLSConfig `json:"tls"`
+}
+
+// 对象存储的类型
+const (
+	UploadS3    = "s3"
+	UploadAzure = "azure"
+)
+
+// S3的服务端加密方式
+const (
+	SSEAES256 = "AES256"
+	SSEKMS    = "aws:kms"
+)
+
+// UploadConfig 表示将快照上传到对象存储（兼容S3的存储或Azure Blob）的配置，
+// 对象名为"前缀/设备ID/年/月/日/采集时间"加扩展名，不需要部署收集服务器
+type UploadConfig struct {
+	Provider    string `json:"provider"`    // s3（默认，包括MinIO等兼容S3的存储）或azure
+	Endpoint    string `json:"endpoint"`    // 服务地址，S3默认为https://s3.{region}.amazonaws.com，Azure默认为https://{account}.blob.core.windows.net
+	Bucket      string `json:"bucket"`      // S3存储桶或Azure容器，为空时不上传
+	Prefix      string `json:"prefix"`      // 对象名前缀，默认为sysspector
+	Format      string `json:"format"`      // json（默认）或protobuf
+	Compression string `json:"compression"` // gzip（默认）或none
+	Interval    int    `json:"interval"`    // serve模式下的上传间隔（秒），为0时serve模式下不上传
+
+	// S3的区域、访问密钥和服务端加密，访问密钥为空时使用AWS_ACCESS_KEY_ID等环境变量
+	Region          string `json:"region"`     // 默认为us-east-1
+	PathStyle       bool   `json:"path_style"` // 为true时使用路径形式的地址（endpoint/bucket/key），MinIO等通常需要开启
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+	SessionToken    string `json:"session_token"`
+	SSE             string `json:"sse"`        // AES256或aws:kms，为空时使用存储桶的默认加密
+	KMSKeyID        string `json:"kms_key_id"` // aws:kms加密使用的KMS密钥，为空时使用AWS托管的密钥
+
+	// Azure的存储账户和认证，AccountKey和SASToken二选一
+	Account         string `json:"account"`
+	AccountKey      string `json:"account_key"`      // 存储账户的访问密钥（base64），使用共享密钥签名
+	SASToken        string `json:"sas_token"`        // 共享访问签名，需要有创建和写入权限
+	EncryptionScope string `json:"encryption_scope"` // 加密范围，为空时使用容器的默认加密
+
+	// CustomerKey 客户提供的加密密钥（base64编码的256位AES密钥），S3为SSE-C，Azure为CPK，读取对象时需要提供同一个密钥
+	CustomerKey string `json:"customer_key"`
+
+	// TLS 为私有CA、mTLS客户端证书和证书固定的配置
+	TLS TLSConfig `json:"tls"`
+}
+
+// 邮件报告的格式
+const (
+	ReportHTML     = "html"
+	ReportMarkdown = "markdown"
+)
+
+// SMTP连接的加密方式
+const (
+	SMTPStartTLS = "starttls"
+	SMTPTLS      = "tls"
+	SMTPNone     = "none"
+)
+
+// EmailConfig 表示定期发送的邮件报告：serve模式下发送本机的报告，aggregate模式下发送设备清单的汇总
+type EmailConfig struct {
+	Host      string   `json:"host"`       // SMTP服务器，为空时不发送邮件
+	Port      int      `json:"port"`       // 端口，默认security为tls时为465，否则为587
+	Security  string   `json:"security"`   // starttls（默认）、tls（SMTPS）或none（只应用于本机或可信网络中的邮件中继）
+	Username  string   `json:"username"`   // SMTP认证的用户名，为空时不认证
+	Password  string   `json:"password"`   // SMTP认证的密码
+	From      string   `json:"from"`       // 发件人地址
+	To        []string `json:"to"`         // 收件人地址
+	Subject   string   `json:"subject"`    // 主题前缀，默认为"SysSpector"，之后为主机名或"设备清单"和日期
+	Format    string   `json:"format"`     // html（默认，同时附带Markdown文本）或markdown
+	Interval  int      `json:"interval"`   // 发送间隔（秒），例如86400为每天一次；为0时不定期发送
+	StaleDays int      `json:"stale_days"` // 设备清单汇总中列出超过该天数没有上报的设备
+
+	// TLS 为SMTP服务器证书的校验方式（私有CA、证书固定）和客户端证书
+	TLS TLSConfig `json:"tls"`
+}
+
+// CollectConfig 表示采集项的并发数和超时：各采集项并发执行，超时仍未完成的采集项不再等待，对应的信息为空
+type CollectConfig struct {
+	Parallelism int            `json:"parallelism"` // 同时执行的采集项数，默认为8
+	Timeout     int            `json:"timeout"`     // 每个采集项的超时（秒），默认为60；延迟探测等耗时较长的采集项默认超时更长
+	Timeouts    map[string]int `json:"timeouts"`    // 按采集项名称设置超时（秒），例如{"latency": 180}，名称见超时和错误日志
+}
+
+// UpdateConfig 表示程序更新的配置：从更新通道下载签名的发布清单，校验签名和校验和后替换程序并重新启动服务
+// 设备管理服务器只能下发Channel和Interval，URL、PublicKeys和TLS只能来自本地配置文件
+type UpdateConfig struct {
+	URL        string   `json:"url"`         // 发布清单的地址，可以包含{channel}；清单的签名在同一地址加".sig"，为空时不检查更新
+	Channel    string   `json:"channel"`     // 更新通道，替换URL中的{channel}，默认为stable
+	PublicKeys []string `json:"public_keys"` // 校验发布清单签名的Ed25519公钥（base64），可以配置多个用于轮换签名密钥
+	Interval   int      `json:"interval"`    // serve模式下自动检查更新的间隔（秒），为0时只能通过 sysinfo update 更新
+
+	// TLS 为更新服务器证书的校验方式（私有CA、证书固定）和客户端证书
+	TLS TLSConfig `json:"tls"`
+}
+
+// AggregateConfig 表示汇总服务的配置：接收多台设备推送的快照，保存到SQLite数据库，提供查询和导出接口
+type AggregateConfig struct {
+	Listen    string   `json:"listen"`    // 监听地址，可通过 --listen 参数覆盖；没有配置令牌也不要求客户端证书时只能监听本机地址
+	DBPath    string   `json:"db_path"`   // 数据库文件，默认为用户配置目录下的SysSpector/fleet.db
+	Tokens    []string `json:"tokens"`    // 查询和注册设备使用的Bearer令牌，设备用注册获得的设备令牌推送快照；为空时不校验
+	Retention int      `json:"retention"` // 历史快照的保留天数，每台设备的最新快照始终保留
+
+	// TLS 为服务的证书，没有配置证书时不使用TLS；配置了ca_file或pins时要求设备提供证书（mTLS）
+	TLS TLSConfig `json:"tls"`
+}
+
+// Default 返回默认配置
+func Default() *Config {
+	return &Config{
+		Latency: LatencyConfig{
+			Groups: []ProbeGroup{
+				{
+					Name: "公共DNS",
+					Targets: []ProbeTarget{
+						{Name: "Google DNS", Host: "8.8.8.8", Protocol: ProtocolICMP},
+						{Name: "Cloudflare DNS", Host: "1.1.1.1", Protocol: ProtocolICMP},
+					},
+				},
+				{
+					Name: "互联网",
+					Targets: []ProbeTarget{
+						{Name: "Baidu", Host: "www.baidu.com", Protocol: ProtocolICMP},
+					},
+				},
+			},
+		},
+		DNS: DNSConfig{
+			TestNames: []string{"www.baidu.com", "www.qq.com", "www.apple.com", "www.microsoft.com", "www.bing.com"},
+		},
+		MTU: MTUConfig{
+			Target: "8.8.8.8",
+		},
+		STUN: STUNConfig{
+			Servers: []string{"stun.miwifi.com:3478", "stun.qq.com:3478", "stun.cloudflare.com:3478", "stun.l.google.com:19302"},
+		},
+		PublicIP: PublicIPConfig{
+			Endpoints: []string{"https://api64.ipify.org?format=json", "https://ipinfo.io/ip", "https://api.ip.sb/ip", "https://icanhazip.com"},
+			GeoURL:    "https://ipinfo.io/{ip}/json",
+			Providers: []EgressProvider{
+				{Name: "Zscaler", ASNs: []string{"AS53813", "AS62044"}, Keywords: []string{"zscaler"}},
+				{Name: "Netskope", ASNs: []string{"AS55256"}, Keywords: []string{"netskope"}},
+				{Name: "Palo Alto Prisma Access", Keywords: []string{"palo alto"}},
+				{Name: "Cisco Umbrella", ASNs: []string{"AS36692"}, Keywords: []string{"opendns", "umbrella"}},
+				{Name: "Cloudflare WARP", ASNs: []string{"AS13335"}},
+				{Name: "M247", ASNs: []string{"AS9009"}, Keywords: []string{"m247"}},
+				{Name: "DataCamp", ASNs: []string{"AS60068"}, Keywords: []string{"datacamp", "cdn77"}},
+			},
+		},
+		Endpoint: EndpointConfig{
+			Targets: []EndpointTarget{
+				{Name: "Baidu", URL: "https://www.baidu.com/"},
+				{Name: "Microsoft", URL: "https://www.microsoft.com/"},
+				{Name: "Apple", URL: "https://www.apple.com/"},
+			},
+			InspectionIssuers: []string{"zscaler", "netskope", "fortinet", "fortigate", "palo alto", "blue coat", "bluecoat", "symantec web",
+				"cisco umbrella", "sophos", "forcepoint", "websense", "checkpoint", "check point", "mcafee web gateway", "kaspersky",
+				"eset", "avast", "bitdefender", "sangfor", "深信服", "qianxin", "奇安信"},
+		},
+		SaaS: SaaSConfig{
+			Suites: []string{"Microsoft 365", "Zoom", "Google Workspace", "飞书", "钉钉"},
+		},
+		Ports: PortConfig{
+			Targets: []PortTarget{
+				{Name: "HTTP", Host: "portquiz.net", Port: 80},
+				{Name: "HTTPS", Host: "portquiz.net", Port: 443},
+				{Name: "SSH", Host: "portquiz.net", Port: 22},
+				{Name: "SMTP", Host: "portquiz.net", Port: 25},
+				{Name: "SMTP提交", Host: "portquiz.net", Port: 587},
+				{Name: "IMAPS", Host: "portquiz.net", Port: 993},
+				{Name: "远程桌面", Host: "portquiz.net", Port: 3389},
+				{Name: "OpenVPN", Host: "portquiz.net", Port: 1194},
+				{Name: "HTTP代理", Host: "portquiz.net", Port: 8080},
+			},
+		},
+		Quality: QualityConfig{
+			DownloadURL: "https://speed.cloudflare.com/__down?bytes=1000000000",
+			Streams:     8,
+			Duration:    15,
+		},
+		Server: ServerConfig{
+			Listen:   "127.0.0.1:8080",
+			CacheTTL: 60,
+		},
+		Push: PushConfig{
+			Format:       PushFormatJSON,
+			Compression:  CompressionGzip,
+			MaxSpooled:   100,
+			MaxAttempts:  4,
+			MaxSpoolSize: 50 << 20,
+			MaxBackoff:   60 * 60,
+			FullInterval: 24 * 60 * 60,
+		},
+		MQTT: MQTTConfig{
+			TopicPrefix: "sysspector/{host}",
+		},
+		Commands: CommandConfig{
+			MaxAge: 300,
+		},
+		Syslog: SyslogConfig{
+			Network:  SyslogUDP,
+			Facility: "local0",
+		},
+		Upload: UploadConfig{
+			Provider:    UploadS3,
+			Prefix:      "sysspector",
+			Format:      PushFormatJSON,
+			Compression: CompressionGzip,
+			Region:      "us-east-1",
+		},
+		Email: EmailConfig{
+			Security:  SMTPStartTLS,
+			Subject:   "SysSpector",
+			Format:    ReportHTML,
+			StaleDays: 7,
+		},
+		Update: UpdateConfig{
+			Channel: "stable",
+		},
+		Collect: CollectConfig{
+			Parallelism: 8,
+			Timeout:     60,
+		},
+		Aggregate: AggregateConfig{
+			Listen:    "127.0.0.1:8090",
+			Retention: 90,
+		},
+		Notify: NotifyConfig{
+			MinHealthScore: 60,
+		},
+		History: HistoryConfig{
+			SnapshotInterval:  300,
+			MetricsInterval:   10,
+			DiskInterval:      3600,
+			SnapshotRetention: 7,
+			RawRetention:      24,
+			MetricsRetention:  90,
+		},
+		MDNS: MDNSConfig{
+			ServiceTypes: []string{"_ipp._tcp", "_ipps._tcp", "_printer._tcp", "_pdl-datastream._tcp", "_airplay._tcp", "_raop._tcp",
+				"_googlecast._tcp", "_ssh._tcp", "_smb._tcp", "_afpovertcp._tcp"},
+		},
+	}
+}
+
+var (
+	mu      sync.RWMutex
+	current = Default()
+)
+
+// Current 返回当前生效的配置，未加载配置文件时为默认配置
+func Current() *Config {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Set 替换当前生效的配置
+func Set(cfg *Config) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = cfg
+}
+
+// DefaultPath 返回配置文件的默认路径
+func DefaultPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "SysSpector", "config.json"), nil
+}
+
+// Load 读取并校验配置文件，文件中没有定义的部分使用默认配置
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %v", path, err)
+	}
+	if err := cfg.normalize(); err != nil {
+		return nil, fmt.Errorf("parse %s: %v", path, err)
+	}
+	return &cfg, nil
+}
+
+// Overlay 返回在当前配置上叠加设备管理服务器下发的配置data后的配置
+// 只有managedConfig中的字段可以被覆盖，data中的其他字段忽略，没有出现的字段保持不变；
+// 探测目标等各部分整体替换，上报方式的部分按字段替换
+func (c *Config) Overlay(data []byte) (*Config, error) {
+	base, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(base, &cfg); err != nil {
+		return nil, err
+	}
+	var managed managedConfig
+	if err := json.Unmarshal(data, &managed); err != nil {
+		return nil, err
+	}
+	managed.apply(&cfg)
+	if err := cfg.normalize(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// managedConfig 表示设备管理服务器可以下发的配置：探测目标、采集和上报的间隔、上报地址。
+// 访问令牌、密钥、TLS证书和证书固定等信任相关的配置只能来自本地配置文件，
+// 否则控制了设备管理服务器（或注册结果文件）就可以放开本机的API、命令和更新的校验
+// 字段为指针，data中没有出现的字段为nil
+type managedConfig struct {
+	Latency  *LatencyConfig  `json:"latency"`
+	DNS      *DNSConfig      `json:"dns"`
+	MTU      *MTUConfig      `json:"mtu"`
+	STUN     *STUNConfig     `json:"stun"`
+	PublicIP *PublicIPConfig `json:"public_ip"`
+	VPN      *VPNConfig      `json:"vpn"`
+	MDNS     *MDNSConfig     `json:"mdns"`
+	Endpoint *EndpointConfig `json:"endpoint_checks"`
+	SaaS     *SaaSConfig     `json:"saas"`
+	Ports    *PortConfig     `json:"port_checks"`
+	Quality  *QualityConfig  `json:"network_quality"`
+	Collect  *CollectConfig  `json:"collect"`
+
+	Server struct {
+		CacheTTL *int `json:"cache_ttl"`
+	} `json:"server"`
+	Push struct {
+		URL          *string `json:"url"`
+		Format       *string `json:"format"`
+		Compression  *string `json:"compression"`
+		Interval     *int    `json:"interval"`
+		Delta        *bool   `json:"delta"`
+		FullInterval *int    `json:"full_interval"`
+	} `json:"push"`
+	MQTT struct {
+		Broker           *string `json:"broker"`
+		TopicPrefix      *string `json:"topic_prefix"`
+		QoS              *byte   `json:"qos"`
+		SnapshotInterval *int    `json:"snapshot_interval"`
+		MetricsInterval  *int    `json:"metrics_interval"`
+	} `json:"mqtt"`
+	History struct {
+		SnapshotInterval *int `json:"snapshot_interval"`
+		MetricsInterval  *int `json:"metrics_interval"`
+		DiskInterval     *int `json:"disk_interval"`
+	} `json:"history"`
+	Notify struct {
+		MinHealthScore *int `json:"min_health_score"`
+		Interval       *int `json:"interval"`
+	} `json:"notify"`
+	Syslog struct {
+		Address  *string `json:"address"`
+		Network  *string `json:"network"`
+		Facility *string `json:"facility"`
+		Interval *int    `json:"interval"`
+	} `json:"syslog"`
+	Upload struct {
+		Interval *int `json:"interval"`
+	} `json:"upload"`
+	Email struct {
+		Interval *int `json:"interval"`
+	} `json:"email"`
+	Update struct {
+		Channel  *string `json:"channel"`
+		Interval *int    `json:"interval"`
+	} `json:"update"`
+}
+
+// apply 将下发的字段写入cfg。下发的推送、MQTT或syslog地址与本地配置不同时，清除本地配置中该部分的令牌、
+// 用户名密码和客户端证书，避免本地凭据被发送到设备管理服务器指定的地址；推送改用注册获得的设备令牌
+func (m *managedConfig) apply(cfg *Config) {
+	if m.Push.URL != nil && *m.Push.URL != cfg.Push.URL {
+		cfg.Push.Token = ""
+		cfg.Push.TLS.clearClientCertificate()
+	}
+	if m.MQTT.Broker != nil && *m.MQTT.Broker != cfg.MQTT.Broker {
+		cfg.MQTT.Username, cfg.MQTT.Password = "", ""
+		cfg.MQTT.TLS.clearClientCertificate()
+	}
+	if m.Syslog.Address != nil && *m.Syslog.Address != cfg.Syslog.Address {
+		cfg.Syslog.TLS.clearClientCertificate()
+	}
+
+	set(&cfg.Latency, m.Latency)
+	set(&cfg.DNS, m.DNS)
+	set(&cfg.MTU, m.MTU)
+	set(&cfg.STUN, m.STUN)
+	set(&cfg.PublicIP, m.PublicIP)
+	set(&cfg.VPN, m.VPN)
+	set(&cfg.MDNS, m.MDNS)
+	set(&cfg.Endpoint, m.Endpoint)
+	set(&cfg.SaaS, m.SaaS)
+	set(&cfg.Ports, m.Ports)
+	set(&cfg.Quality, m.Quality)
+	set(&cfg.Collect, m.Collect)
+	set(&cfg.Server.CacheTTL, m.Server.CacheTTL)
+	set(&cfg.Push.URL, m.Push.URL)
+	set(&cfg.Push.Format, m.Push.Format)
+	set(&cfg.Push.Compression, m.Push.Compression)
+	set(&cfg.Push.Interval, m.Push.Interval)
+	set(&cfg.Push.Delta, m.Push.Delta)
+	set(&cfg.Push.FullInterval, m.Push.FullInterval)
+	set(&cfg.MQTT.Broker, m.MQTT.Broker)
+	set(&cfg.MQTT.TopicPrefix, m.MQTT.TopicPrefix)
+	set(&cfg.MQTT.QoS, m.MQTT.QoS)
+	set(&cfg.MQTT.SnapshotInterval, m.MQTT.SnapshotInterval)
+	set(&cfg.MQTT.MetricsInterval, m.MQTT.MetricsInterval)
+	set(&cfg.History.SnapshotInterval, m.History.SnapshotInterval)
+	set(&cfg.History.MetricsInterval, m.History.MetricsInterval)
+	set(&cfg.History.DiskInterval, m.History.DiskInterval)
+	set(&cfg.Notify.MinHealthScore, m.Notify.MinHealthScore)
+	set(&cfg.Notify.Interval, m.Notify.Interval)
+	set(&cfg.Syslog.Address, m.Syslog.Address)
+	set(&cfg.Syslog.Network, m.Syslog.Network)
+	set(&cfg.Syslog.Facility, m.Syslog.Facility)
+	set(&cfg.Syslog.Interval, m.Syslog.Interval)
+	set(&cfg.Upload.Interval, m.Upload.Interval)
+	set(&cfg.Email.Interval, m.Email.Interval)
+	set(&cfg.Update.Channel, m.Update.Channel)
+	set(&cfg.Update.Interval, m.Update.Interval)
+}
+
+// set 在src不为nil时将*src写入*dst
+func set[T any](dst *T, src *T) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+// normalize 为没有定义的部分填写默认配置，并校验各部分的配置
+func (c *Config) normalize() error {
+	if len(c.Latency.Groups) == 0 {
+		c.Latency = Default().Latency
+	}
+	if len(c.DNS.TestNames) == 0 {
+		c.DNS.TestNames = Default().DNS.TestNames
+	}
+	if c.MTU.Target == "" {
+		c.MTU.Target = Default().MTU.Target
+	}
+	if len(c.STUN.Servers) == 0 {
+		c.STUN.Servers = Default().STUN.Servers
+	}
+	if len(c.PublicIP.Endpoints) == 0 {
+		c.PublicIP.Endpoints = Default().PublicIP.Endpoints
+	}
+	if c.PublicIP.GeoURL == "" {
+		c.PublicIP.GeoURL = Default().PublicIP.GeoURL
+	}
+	if c.PublicIP.Providers == nil {
+		c.PublicIP.Providers = Default().PublicIP.Providers
+	}
+	if c.Endpoint.Targets == nil {
+		c.Endpoint.Targets = Default().Endpoint.Targets
+	}
+	if c.Endpoint.InspectionIssuers == nil {
+		c.Endpoint.InspectionIssuers = Default().Endpoint.InspectionIssuers
+	}
+	if c.SaaS.Suites == nil {
+		c.SaaS.Suites = Default().SaaS.Suites
+	}
+	if c.Ports.Targets == nil {
+		c.Ports.Targets = Default().Ports.Targets
+	}
+	if c.Quality.DownloadURL == "" {
+		c.Quality.DownloadURL = Default().Quality.DownloadURL
+	}
+	if c.Quality.Streams <= 0 {
+		c.Quality.Streams = Default().Quality.Streams
+	}
+	if c.Quality.Duration <= 0 {
+		c.Quality.Duration = Default().Quality.Duration
+	}
+	if err := c.Server.normalize(); err != nil {
+		return err
+	}
+	if len(c.MDNS.ServiceTypes) == 0 {
+		c.MDNS.ServiceTypes = Default().MDNS.ServiceTypes
+	}
+	if err := c.Push.normalize(); err != nil {
+		return err
+	}
+	if c.MQTT.TopicPrefix == "" {
+		c.MQTT.TopicPrefix = Default().MQTT.TopicPrefix
+	}
+	if c.MQTT.QoS > 2 {
+		return errors.New("mqtt qos must be 0, 1 or 2")
+	}
+	if c.MQTT.SnapshotInterval < 0 || c.MQTT.MetricsInterval < 0 {
+		return errors.New("mqtt intervals must not be negative")
+	}
+	if err := c.MQTT.TLS.normalize(); err != nil {
+		return fmt.Errorf("mqtt: %v", err)
+	}
+	if err := c.Device.TLS.normalize(); err != nil {
+		return fmt.Errorf("device: %v", err)
+	}
+	if c.Commands.MaxAge <= 0 {
+		c.Commands.MaxAge = Default().Commands.MaxAge
+	}
+	if c.Commands.PollURL != "" && len(c.Commands.PublicKeys) == 0 {
+		return errors.New("commands: poll_url requires public_keys")
+	}
+	for _, key := range c.Commands.PublicKeys {
+		if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != ed25519.PublicKeySize {
+			return fmt.Errorf("commands: invalid public key %q", key)
+		}
+	}
+	if err := c.Commands.TLS.normalize(); err != nil {
+		return fmt.Errorf("commands: %v", err)
+	}
+	if err := c.Syslog.normalize(); err != nil {
+		return err
+	}
+	if err := c.Upload.normalize(); err != nil {
+		return err
+	}
+	if err := c.Email.normalize(); err != nil {
+		return err
+	}
+	if err := c.Update.normalize(); err != nil {
+		return err
+	}
+	if err := c.Collect.normalize(); err != nil {
+		return err
+	}
+	if c.Aggregate.Listen == "" {
+		c.Aggregate.Listen = Default().Aggregate.Listen
+	}
+	if c.Aggregate.Retention <= 0 {
+		c.Aggregate.Retention = Default().Aggregate.Retention
+	}
+	if err := c.Aggregate.TLS.normalize(); err != nil {
+		return fmt.Errorf("aggregate: %v", err)
+	}
+	c.History.normalize()
+	if err := c.Notify.normalize(); err != nil {
+		return err
+	}
+	if err := c.Latency.normalize(); err != nil {
+		return err
+	}
+	for i := range c.Ports.Targets {
+		target := &c.Ports.Targets[i]
+		if target.Port <= 0 || target.Port > 65535 {
+			return fmt.Errorf("port check %d has an invalid port", i+1)
+		}
+		if target.Host == "" {
+			target.Host = "portquiz.net"
+		}
+		if target.Name == "" {
+			target.Name = strconv.Itoa(target.Port)
+		}
+	}
+	for i := range c.Endpoint.Targets {
+		target := &c.Endpoint.Targets[i]
+		if target.URL == "" {
+			return fmt.Errorf("endpoint check %d has no url", i+1)
+		}
+		if target.Name == "" {
+			target.Name = target.URL
+		}
+	}
+	return nil
+}
+
+// normalize 补全serve模式的默认监听地址和令牌的默认名称、权限范围，并检查TLS配置和令牌
+func (s *ServerConfig) normalize() error {
+	if s.Listen == "" {
+		s.Listen = Default().Server.Listen
+	}
+	if s.CacheTTL < 0 {
+		return errors.New("server cache_ttl must not be negative")
+	}
+	if s.CacheTTL == 0 {
+		s.CacheTTL = Default().Server.CacheTTL
+	}
+	if err := s.TLS.normalize(); err != nil {
+		return fmt.Errorf("server: %v", err)
+	}
+	for i := range s.Tokens {
+		token := &s.Tokens[i]
+		if token.Name == "" {
+			token.Name = "token" + strconv.Itoa(i+1)
+		}
+		if token.Token == "" {
+			return fmt.Errorf("server: token %s is empty", token.Name)
+		}
+		if digest, ok := strings.CutPrefix(token.Token, "sha256:"); ok {
+			if sum, err := hex.DecodeString(digest); err != nil || len(sum) != sha256.Size {
+				return fmt.Errorf("server: token %s is not a valid SHA-256 digest", token.Name)
+			}
+		}
+		switch token.Scope {
+		case "":
+			token.Scope = ScopeRead
+		case ScopeRead, ScopeCollect, ScopeAdmin:
+		default:
+			return fmt.Errorf("server: token %s has an unknown scope %q", token.Name, token.Scope)
+		}
+	}
+	for i, origin := range s.AllowedOrigins {
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+			return fmt.Errorf("server: allowed origin %q must be a scheme and host, e.g. https://dashboard.example.com", origin)
+		}
+		s.AllowedOrigins[i] = strings.ToLower(u.Scheme + "://" + u.Host)
+	}
+	return nil
+}
+
+// normalize 补全探测目标的默认协议和端口，并检查必填项
+func (l *LatencyConfig) normalize() error {
+	for i := range l.Groups {
+		group := &l.Groups[i]
+		for j := range group.Targets {
+			target := &group.Targets[j]
+			if target.Host == "" {
+				return fmt.Errorf("latency group %q: target %d has no host", group.Name, j+1)
+			}
+			if err := target.Normalize(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Normalize 校验探测目标并填写默认的名称、协议和端口
+func (t *ProbeTarget) Normalize() error {
+	if t.Host == "" {
+		return errors.New("latency target has no host")
+	}
+	if t.Name == "" {
+		t.Name = t.Host
+	}
+
+	t.Protocol = strings.ToLower(t.Protocol)
+	switch t.Protocol {
+	case "":
+		t.Protocol = ProtocolICMP
+	case ProtocolICMP:
+	case ProtocolTCP:
+		if t.Port <= 0 || t.Port > 65535 {
+			return fmt.Errorf("latency target %q: tcp probe requires a valid port", t.Name)
+		}
+	case ProtocolHTTP:
+		if t.Port == 0 {
+			t.Port = 443
+		}
+	default:
+		return fmt.Errorf("latency target %q: unknown protocol %q", t.Name, t.Protocol)
+	}
+	return nil
+}
+
+// normalize 为没有设置或设置不正确的间隔和保留期限填写默认值
+func (h *HistoryConfig) normalize() {
+	defaults := Default().History
+	if h.SnapshotInterval <= 0 {
+		h.SnapshotInterval = defaults.SnapshotInterval
+	}
+	if h.MetricsInterval <= 0 {
+		h.MetricsInterval = defaults.MetricsInterval
+	}
+	if h.DiskInterval <= 0 {
+		h.DiskInterval = defaults.DiskInterval
+	}
+	if h.SnapshotRetention <= 0 {
+		h.SnapshotRetention = defaults.SnapshotRetention
+	}
+	if h.RawRetention <= 0 {
+		h.RawRetention = defaults.RawRetention
+	}
+	if h.MetricsRetention <= 0 {
+		h.MetricsRetention = defaults.MetricsRetention
+	}
+}
+
+// normalize 校验通知渠道并填写默认值
+func (n *NotifyConfig) normalize() error {
+	if n.MinHealthScore <= 0 {
+		n.MinHealthScore = Default().Notify.MinHealthScore
+	}
+	if n.Interval < 0 {
+		return errors.New("notify: interval must not be negative")
+	}
+	for i := range n.Channels {
+		channel := &n.Channels[i]
+		if channel.Name == "" {
+			channel.Name = channel.Type
+		}
+		switch channel.Type {
+		case NotifyWebhook, NotifySlack, NotifyTeams, NotifyDingTalk, NotifyFeishu:
+		default:
+			return fmt.Errorf("notify channel %q: unknown type %q", channel.Name, channel.Type)
+		}
+		if channel.URL == "" {
+			return fmt.Errorf("notify channel %q: url is required", channel.Name)
+		}
+		switch channel.MinSeverity {
+		case "":
+			channel.MinSeverity = SeverityWarning
+		case SeverityWarning, SeverityCritical:
+		default:
+			return fmt.Errorf("notify channel %q: unknown severity %q", channel.Name, channel.MinSeverity)
+		}
+		if err := channel.TLS.normalize(); err != nil {
+			return fmt.Errorf("notify channel %q: %v", channel.Name, err)
+		}
+	}
+	return nil
+}
+
+// normalize 校验证书配置，证书固定的摘要可以带有"sha256/"前缀
+func (t *TLSConfig) normalize() error {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return errors.New("tls: cert_file and key_file must be set together")
+	}
+	if t.CertFile != "" && t.CertStore != "" {
+		return errors.New("tls: cert_file and cert_store cannot both be set")
+	}
+	for i, pin := range t.Pins {
+		pin = strings.TrimPrefix(pin, "sha256/")
+		if digest, err := base64.StdEncoding.DecodeString(pin); err != nil || len(digest) != sha256.Size {
+			return fmt.Errorf("tls: pin %q is not a base64 SHA-256 digest", t.Pins[i])
+		}
+		t.Pins[i] = pin
+	}
+	return nil
+}
+
+// normalize 校验syslog配置，填写默认的传输方式、设施和端口
+func (s *SyslogConfig) normalize() error {
+	defaults := Default().Syslog
+	switch s.Network {
+	case "":
+		s.Network = defaults.Network
+	case SyslogUDP, SyslogTCP, SyslogTLS:
+	default:
+		return fmt.Errorf("syslog: unknown network %q", s.Network)
+	}
+	if s.Facility == "" {
+		s.Facility = defaults.Facility
+	}
+	if _, ok := SyslogFacilities[s.Facility]; !ok {
+		return fmt.Errorf("syslog: unknown facility %q", s.Facility)
+	}
+	if s.Interval < 0 {
+		return errors.New("syslog: interval must not be negative")
+	}
+	if s.Address != "" {
+		if _, _, err := net.SplitHostPort(s.Address); err != nil {
+			port := "514"
+			if s.Network == SyslogTLS {
+				port = "6514"
+			}
+			s.Address = net.JoinHostPort(s.Address, port)
+		}
+	}
+	if err := s.TLS.normalize(); err != nil {
+		return fmt.Errorf("syslog: %v", err)
+	}
+	return nil
+}
+
+// normalize 校验邮件报告配置并填写默认值
+func (e *EmailConfig) normalize() error {
+	defaults := Default().Email
+	switch e.Security {
+	case "":
+		e.Security = defaults.Security
+	case SMTPStartTLS, SMTPTLS, SMTPNone:
+	default:
+		return fmt.Errorf("email: unknown security %q", e.Security)
+	}
+	switch e.Format {
+	case "":
+		e.Format = defaults.Format
+	case ReportHTML, ReportMarkdown:
+	default:
+		return fmt.Errorf("email: unknown format %q", e.Format)
+	}
+	if e.Port == 0 {
+		e.Port = 587
+		if e.Security == SMTPTLS {
+			e.Port = 465
+		}
+	}
+	if e.Port < 0 || e.Port > 65535 {
+		return errors.New("email: invalid port")
+	}
+	if e.Subject == "" {
+		e.Subject = defaults.Subject
+	}
+	if e.Interval < 0 {
+		return errors.New("email: interval must not be negative")
+	}
+	if e.StaleDays <= 0 {
+		e.StaleDays = defaults.StaleDays
+	}
+	if e.Host != "" && (e.From == "" || len(e.To) == 0) {
+		return errors.New("email: from and to are required")
+	}
+	if err := e.TLS.normalize(); err != nil {
+		return fmt.Errorf("email: %v", err)
+	}
+	return nil
+}
+
+// normalize 校验采集的并发数和超时并填写默认值
+func (c *CollectConfig) normalize() error {
+	defaults := Default().Collect
+	if c.Parallelism <= 0 {
+		c.Parallelism = defaults.Parallelism
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaults.Timeout
+	}
+	for name, timeout := range c.Timeouts {
+		if timeout <= 0 {
+			return fmt.Errorf("collect: timeout of %s must be positive", name)
+		}
+	}
+	return nil
+}
+
+// normalize 校验更新配置并填写默认值，配置了更新地址时必须配置公钥
+func (u *UpdateConfig) normalize() error {
+	if u.Channel == "" {
+		u.Channel = Default().Update.Channel
+	}
+	if u.Interval < 0 {
+		return errors.New("update: interval must not be negative")
+	}
+	if u.URL != "" && len(u.PublicKeys) == 0 {
+		return errors.New("update: public_keys are required")
+	}
+	for _, key := range u.PublicKeys {
+		if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != ed25519.PublicKeySize {
+			return fmt.Errorf("update: invalid public key %q", key)
+		}
+	}
+	if err := u.TLS.normalize(); err != nil {
+		return fmt.Errorf("update: %v", err)
+	}
+	return nil
+}
+
+// normalize 校验对象存储配置并填写默认值
+func (u *UploadConfig) normalize() error {
+	defaults := Default().Upload
+	switch u.Provider {
+	case "":
+		u.Provider = defaults.Provider
+	case UploadS3, UploadAzure:
+	default:
+		return fmt.Errorf("upload: unknown provider %q", u.Provider)
+	}
+	u.Prefix = strings.Trim(u.Prefix, "/")
+	if u.Prefix == "" {
+		u.Prefix = defaults.Prefix
+	}
+	switch u.Format {
+	case "":
+		u.Format = defaults.Format
+	case PushFormatJSON, PushFormatProtobuf:
+	default:
+		return fmt.Errorf("upload: unknown format %q", u.Format)
+	}
+	switch u.Compression {
+	case "":
+		u.Compression = defaults.Compression
+	case CompressionGzip, CompressionNone:
+	default:
+		return fmt.Errorf("upload: unknown compression %q", u.Compression)
+	}
+	if u.Interval < 0 {
+		return errors.New("upload: interval must not be negative")
+	}
+	if u.Region == "" {
+		u.Region = defaults.Region
+	}
+	switch u.SSE {
+	case "", SSEAES256, SSEKMS:
+	default:
+		return fmt.Errorf("upload: unknown sse %q", u.SSE)
+	}
+	if u.KMSKeyID != "" && u.SSE != SSEKMS {
+		return errors.New("upload: kms_key_id requires sse aws:kms")
+	}
+	if u.CustomerKey != "" {
+		if key, err := base64.StdEncoding.DecodeString(u.CustomerKey); err != nil || len(key) != 32 {
+			return errors.New("upload: customer_key must be a base64 256-bit key")
+		}
+		if u.SSE != "" || u.EncryptionScope != "" {
+			return errors.New("upload: customer_key cannot be used with sse or encryption_scope")
+		}
+	}
+	if u.Bucket != "" && u.Provider == UploadAzure {
+		if u.Account == "" {
+			return errors.New("upload: azure requires an account")
+		}
+		if (u.AccountKey == "") == (u.SASToken == "") {
+			return errors.New("upload: azure requires either account_key or sas_token")
+		}
+		if u.SSE != "" {
+			return errors.New("upload: sse is only supported by s3, use encryption_scope for azure")
+		}
+	}
+	if u.Provider == UploadS3 && u.EncryptionScope != "" {
+		return errors.New("upload: encryption_scope is only supported by azure")
+	}
+	u.SASToken = strings.TrimPrefix(u.SASToken, "?")
+	if err := u.TLS.normalize(); err != nil {
+		return fmt.Errorf("upload: %v", err)
+	}
+	return nil
+}
+
+// normalize 校验推送配置并填写默认值
+func (p *PushConfig) normalize() error {
+	defaults := Default().Push
+	switch p.Format {
+	case "":
+		p.Format = defaults.Format
+	case PushFormatJSON, PushFormatProtobuf:
+	default:
+		return fmt.Errorf("push: unknown format %q", p.Format)
+	}
+	switch p.Compression {
+	case "":
+		p.Compression = defaults.Compression
+	case CompressionGzip, CompressionNone:
+	default:
+		return fmt.Errorf("push: unknown compression %q", p.Compression)
+	}
+	if err := p.TLS.normalize(); err != nil {
+		return fmt.Errorf("push: %v", err)
+	}
+	if p.Interval < 0 {
+		return errors.New("push: interval must not be negative")
+	}
+	if p.MaxSpooled <= 0 {
+		p.MaxSpooled = defaults.MaxSpooled
+	}
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaults.MaxAttempts
+	}
+	if p.MaxSpoolSize <= 0 {
+		p.MaxSpoolSize = defaults.MaxSpoolSize
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaults.MaxBackoff
+	}
+	if p.MaxBandwidth < 0 {
+		return errors.New("push: max_bandwidth must not be negative")
+	}
+	if p.Delta && p.Format != PushFormatJSON {
+		return errors.New("push: delta requires json format")
+	}
+	if p.FullInterval <= 0 {
+		p.FullInterval = defaults.FullInterval
+	}
+	return nil
+}
+
+// URL 返回HTTP探测的请求地址，Host不是完整URL时根据端口选择http或https
+func (t ProbeTarget) URL() string {
+	if strings.HasPrefix(t.Host, "http://") || strings.HasPrefix(t.Host, "https://") {
+		return t.Host
+	}
+	scheme := "https"
+	if t.Port == 80 {
+		scheme = "http"
+	}
+	return scheme + "://" + net.JoinHostPort(t.Host, strconv.Itoa(t.Port)) + "/"
+}
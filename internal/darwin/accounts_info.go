@@ -0,0 +1,101 @@
+package darwin
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// lastLoginRegex 匹配last输出中的登录时间，如：Tue Oct 14 09:12
+// 输出末尾的"wtmp begins Mon Sep  1 08:00"也带有时间，只能用于以用户名开头的登录记录行
+var lastLoginRegex = regexp.MustCompile(`[A-Z][a-z]{2} [A-Z][a-z]{2}\s+\d+ \d{2}:\d{2}`)
+
+// getLocalAccounts 获取本地用户账户、管理员身份、密码状态和上次登录时间
+func getLocalAccounts(ctx context.Context, info *model.SystemInfo) error {
+	// 输出格式为：用户名 UID
+	output, err := runCommand(ctx, "dscl", ".", "-list", "/Users", "UniqueID")
+	if err != nil {
+		return err
+	}
+
+	admins := make(map[string]bool)
+	if adminOutput, err := runCommand(ctx, "dscl", ".", "-read", "/Groups/admin", "GroupMembership"); err == nil {
+		for _, member := range strings.Fields(strings.TrimPrefix(strings.TrimSpace(adminOutput), "GroupMembership:")) {
+			admins[member] = true
+		}
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		name := fields[0]
+		uid, err := strconv.Atoi(fields[1])
+		// 跳过系统账户（以下划线开头或UID小于500）
+		if err != nil || uid < 500 || strings.HasPrefix(name, "_") {
+			continue
+		}
+
+		account := model.LocalAccountInfo{
+			Name:    name,
+			ID:      fields[1],
+			IsAdmin: admins[name],
+		}
+
+		if userOutput, err := runCommand(ctx, "dscl", ".", "-read", "/Users/"+name, "RealName", "AuthenticationAuthority"); err == nil {
+			if matches := regexp.MustCompile(`RealName:\s*\n?\s*(.+)`).FindStringSubmatch(userOutput); len(matches) > 1 {
+				account.FullName = strings.TrimSpace(matches[1])
+			}
+			// 没有ShadowHash认证方式的账户无法使用密码登录
+			account.NoPassword = !strings.Contains(userOutput, "ShadowHash")
+			account.Disabled = strings.Contains(userOutput, "DisabledUser")
+		}
+
+		if lastOutput, err := runCommand(ctx, "last", "-1", name); err == nil {
+			account.LastLogin = parseLastLogin(lastOutput, name)
+		}
+
+		info.LocalAccounts = append(info.LocalAccounts, account)
+	}
+
+	return nil
+}
+
+// parseLastLogin 从last -1的输出中解析用户name的上次登录时间，没有登录记录时返回空字符串
+func parseLastLogin(output, name string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == name {
+			return lastLoginRegex.FindString(line)
+		}
+	}
+	return ""
+}
+
+// getIdentity 获取Active Directory绑定和平台单点登录状态
+func getIdentity(ctx context.Context, info *model.SystemInfo) error {
+	// 未绑定时dsconfigad -show无输出
+	output, err := runCommand(ctx, "dsconfigad", "-show")
+	if err == nil {
+		if matches := regexp.MustCompile(`Active Directory Domain\s*=\s*(.+)`).FindStringSubmatch(output); len(matches) > 1 {
+			info.Identity.DomainJoined = true
+			info.Identity.Domain = strings.TrimSpace(matches[1])
+		}
+	}
+
+	// 平台单点登录（macOS 13及以上）
+	if ssoOutput, ssoErr := runCommand(ctx, "app-sso", "platform", "-s"); ssoErr == nil {
+		info.Identity.PlatformSSO = "未注册"
+		if regexp.MustCompile(`"registrationCompleted"\s*:\s*true`).MatchString(ssoOutput) {
+			info.Identity.PlatformSSO = "已注册"
+			if matches := regexp.MustCompile(`"extensionIdentifier"\s*:\s*"([^"]+)"`).FindStringSubmatch(ssoOutput); len(matches) > 1 {
+				info.Identity.PlatformSSO += "（" + matches[1] + "）"
+			}
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,187 @@
+package darwin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+	"howett.net/plist"
+)
+
+// bootHistoryCount 报告的启动和关机事件数量
+const bootHistoryCount = 10
+
+// lastEventRegex 匹配last命令输出的reboot/shutdown记录，如"reboot    ~    Tue Oct 14 09:12"
+var lastEventRegex = regexp.MustCompile(`^(reboot|shutdown)\s+~\s+(\w{3} \w{3}\s+\d+ \d{2}:\d{2})`)
+
+// shutdownCauseRegex 匹配内核在启动时记录的上次关机原因
+var shutdownCauseRegex = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}).*Previous shutdown cause: (-?\d+)`)
+
+// shutdownCauses 上次关机原因代码的含义，其余负数代码通常表示硬件或固件异常
+var shutdownCauses = map[int]string{
+	5: "正常关机",
+	3: "强制关机（长按电源键）",
+	0: "意外断电",
+}
+
+// installHistoryEntry 表示/Library/Receipts/InstallHistory.plist中的安装记录
+type installHistoryEntry struct {
+	Date        time.Time `plist:"date"`
+	DisplayName string    `plist:"displayName"`
+	ProcessName string    `plist:"processName"`
+}
+
+// getBootHistory 获取最近的启动和关机记录，并结合关机原因、内核崩溃报告和系统更新记录判断原因
+func getBootHistory(ctx context.Context, info *model.SystemInfo) error {
+	output, err := runCommand(ctx, "last", "reboot", "shutdown")
+	if err != nil {
+		return err
+	}
+
+	causes := getShutdownCauses(ctx)
+	panics := getPanicTimes()
+	updates := getSystemUpdateTimes()
+
+	now := time.Now()
+	year := now.Year()
+	var previous time.Time
+	for _, line := range strings.Split(output, "\n") {
+		matches := lastEventRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		// last不输出年份，记录从新到旧排列，时间比上一条更晚时说明跨年
+		eventTime, err := time.ParseInLocation("Mon Jan _2 15:04", matches[2], time.Local)
+		if err != nil {
+			continue
+		}
+		eventTime = eventTime.AddDate(year, 0, 0)
+		if eventTime.After(now) || (!previous.IsZero() && eventTime.After(previous)) {
+			year--
+			eventTime = eventTime.AddDate(-1, 0, 0)
+		}
+		previous = eventTime
+
+		event := model.BootEventInfo{Time: eventTime.Format("2006-01-02 15:04")}
+		if matches[1] == "shutdown" {
+			// last只记录正常关机
+			event.Type = "关机"
+			event.Reason = "正常关机"
+		} else {
+			event.Type = "启动"
+			event.Reason, event.Detail = bootReason(eventTime, causes, panics, updates)
+		}
+		info.BootHistory = append(info.BootHistory, event)
+
+		if len(info.BootHistory) >= bootHistoryCount {
+			break
+		}
+	}
+
+	return nil
+}
+
+// bootReason 判断启动前一次关机的原因：内核崩溃报告优先，其次是系统更新和内核记录的关机原因
+func bootReason(bootTime time.Time, causes map[time.Time]int, panics, updates []time.Time) (string, string) {
+	for _, panicTime := range panics {
+		if withinBefore(panicTime, bootTime, 30*time.Minute) {
+			return "系统崩溃", "内核崩溃报告 " + panicTime.Format("15:04")
+		}
+	}
+
+	// 关机原因在启动后几分钟内写入日志，last的时间只精确到分钟
+	cause, hasCause := 0, false
+	for logTime, code := range causes {
+		if logTime.After(bootTime.Add(-time.Minute)) && logTime.Before(bootTime.Add(10*time.Minute)) {
+			cause, hasCause = code, true
+			break
+		}
+	}
+
+	for _, updateTime := range updates {
+		if withinBefore(updateTime, bootTime, 2*time.Hour) && (!hasCause || cause == 5) {
+			return "系统更新", "更新安装于 " + updateTime.Format("15:04")
+		}
+	}
+
+	if !hasCause {
+		return "", ""
+	}
+	if reason, ok := shutdownCauses[cause]; ok {
+		return reason, fmt.Sprintf("关机代码 %d", cause)
+	}
+	return "异常关机", fmt.Sprintf("关机代码 %d", cause)
+}
+
+// withinBefore 判断t是否在ref之前的window时间内
+func withinBefore(t, ref time.Time, window time.Duration) bool {
+	return !t.After(ref) && ref.Sub(t) <= window
+}
+
+// getShutdownCauses 从统一日志中读取最近两周每次启动时记录的上次关机原因
+func getShutdownCauses(ctx context.Context) map[time.Time]int {
+	causes := make(map[time.Time]int)
+	output, err := runCommand(ctx, "log", "show", "--style", "syslog", "--last", "14d",
+		"--predicate", `eventMessage CONTAINS "Previous shutdown cause"`)
+	if err != nil {
+		return causes
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		matches := shutdownCauseRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		logTime, err := time.ParseInLocation("2006-01-02 15:04:05", matches[1], time.Local)
+		if err != nil {
+			continue
+		}
+		if code, err := strconv.Atoi(matches[2]); err == nil {
+			causes[logTime] = code
+		}
+	}
+	return causes
+}
+
+// getPanicTimes 获取内核崩溃报告的生成时间
+func getPanicTimes() []time.Time {
+	var times []time.Time
+	for _, pattern := range []string{"/Library/Logs/DiagnosticReports/*.panic", "/Library/Logs/DiagnosticReports/panic-full-*"} {
+		matches, _ := filepath.Glob(pattern)
+		for _, path := range matches {
+			if stat, err := os.Stat(path); err == nil {
+				times = append(times, stat.ModTime())
+			}
+		}
+	}
+	return times
+}
+
+// getSystemUpdateTimes 获取macOS系统更新和快速安全响应的安装时间
+func getSystemUpdateTimes() []time.Time {
+	data, err := os.ReadFile("/Library/Receipts/InstallHistory.plist")
+	if err != nil {
+		return nil
+	}
+	var entries []installHistoryEntry
+	if _, err := plist.Unmarshal(data, &entries); err != nil {
+		return nil
+	}
+
+	var times []time.Time
+	for _, entry := range entries {
+		// softwareupdated也会静默安装XProtect等安全数据，这些更新不需要重启
+		systemUpdate := strings.HasPrefix(entry.DisplayName, "macOS") || strings.Contains(entry.DisplayName, "Security Response")
+		if systemUpdate && (entry.ProcessName == "softwareupdated" || entry.ProcessName == "macOS Installer") {
+			times = append(times, entry.Date)
+		}
+	}
+	return times
+}
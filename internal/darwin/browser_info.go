@@ -0,0 +1,127 @@
+package darwin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/internal/browser"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+	"howett.net/plist"
+)
+
+// knownBrowser 表示已知浏览器的Bundle ID及其用户数据目录
+type knownBrowser struct {
+	bundleID string
+	name     string
+	dataDir  string // 相对于~/Library/Application Support的用户数据目录
+	firefox  bool
+}
+
+// knownBrowsers 已知的浏览器列表
+var knownBrowsers = []knownBrowser{
+	{bundleID: "com.apple.safari", name: "Safari"},
+	{bundleID: "com.google.chrome", name: "Google Chrome", dataDir: "Google/Chrome"},
+	{bundleID: "com.microsoft.edgemac", name: "Microsoft Edge", dataDir: "Microsoft Edge"},
+	{bundleID: "org.mozilla.firefox", name: "Firefox", dataDir: "Firefox/Profiles", firefox: true},
+	{bundleID: "com.brave.browser", name: "Brave", dataDir: "BraveSoftware/Brave-Browser"},
+	{bundleID: "company.thebrowser.browser", name: "Arc", dataDir: "Arc/User Data"},
+	{bundleID: "com.operasoftware.opera", name: "Opera", dataDir: "com.operasoftware.Opera"},
+	{bundleID: "com.vivaldi.vivaldi", name: "Vivaldi", dataDir: "Vivaldi"},
+	{bundleID: "org.chromium.chromium", name: "Chromium", dataDir: "Chromium"},
+}
+
+// launchServicesHandlers 表示LaunchServices中的URL和文件类型处理程序配置
+type launchServicesHandlers struct {
+	LSHandlers []struct {
+		URLScheme   string `plist:"LSHandlerURLScheme"`
+		ContentType string `plist:"LSHandlerContentType"`
+		RoleAll     string `plist:"LSHandlerRoleAll"`
+		RoleViewer  string `plist:"LSHandlerRoleViewer"`
+	} `plist:"LSHandlers"`
+}
+
+// getBrowsers 从已安装应用中识别浏览器，并获取默认浏览器
+// 依赖getInstalledApps先收集应用的Bundle ID
+func getBrowsers(ctx context.Context, info *model.SystemInfo) error {
+	homeDir, _ := os.UserHomeDir()
+	defaultBundleID := getDefaultBrowserBundleID(homeDir)
+
+	seen := make(map[string]bool)
+	for _, app := range info.InstalledApps {
+		bundleID := strings.ToLower(app.BundleID)
+		for _, known := range knownBrowsers {
+			if bundleID != known.bundleID || seen[bundleID] {
+				continue
+			}
+			seen[bundleID] = true
+
+			browserInfo := model.BrowserInfo{
+				Name:       known.name,
+				Version:    app.Version,
+				Path:       app.Path,
+				IsDefault:  bundleID == defaultBundleID,
+				Extensions: -1,
+			}
+
+			if known.dataDir != "" && homeDir != "" {
+				dataDir := filepath.Join(homeDir, "Library", "Application Support", known.dataDir)
+				if known.firefox {
+					browserInfo.Extensions, browserInfo.Proxy = browser.FirefoxProfile(dataDir)
+				} else {
+					browserInfo.Extensions, browserInfo.Proxy = browser.ChromiumProfile(dataDir)
+				}
+			} else if bundleID == "com.apple.safari" {
+				// Safari始终使用系统代理
+				browserInfo.Proxy = browser.ProxySystem
+			}
+
+			if browserInfo.IsDefault {
+				info.Browsers.Default = browserInfo.Name
+			}
+			info.Browsers.Installed = append(info.Browsers.Installed, browserInfo)
+		}
+	}
+
+	// 默认浏览器不在已知列表中时显示Bundle ID
+	if info.Browsers.Default == "" && defaultBundleID != "" {
+		info.Browsers.Default = defaultBundleID
+	}
+
+	return nil
+}
+
+// getDefaultBrowserBundleID 读取LaunchServices配置获取http协议的默认处理程序
+// 用户未修改过默认浏览器时返回Safari
+func getDefaultBrowserBundleID(homeDir string) string {
+	if homeDir == "" {
+		return ""
+	}
+
+	handlers, err := readLaunchServicesHandlers(homeDir)
+	if err != nil {
+		return "com.apple.safari"
+	}
+
+	for _, handler := range handlers.LSHandlers {
+		if handler.URLScheme == "http" && handler.RoleAll != "" {
+			return strings.ToLower(handler.RoleAll)
+		}
+	}
+	return "com.apple.safari"
+}
+
+// readLaunchServicesHandlers 读取当前用户的LaunchServices处理程序配置
+func readLaunchServicesHandlers(homeDir string) (launchServicesHandlers, error) {
+	var handlers launchServicesHandlers
+	path := filepath.Join(homeDir, "Library", "Preferences", "com.apple.LaunchServices", "com.apple.launchservices.secure.plist")
+	file, err := os.Open(path)
+	if err != nil {
+		return handlers, err
+	}
+	defer file.Close()
+
+	err = plist.NewDecoder(file).Decode(&handlers)
+	return handlers, err
+}
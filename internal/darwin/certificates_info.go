@@ -0,0 +1,47 @@
+package darwin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// keychainSource 表示需要读取证书的钥匙串
+type keychainSource struct {
+	path  string
+	store string
+}
+
+// getCertificates 获取系统钥匙串和登录钥匙串中的证书
+// 系统钥匙串只包含管理员或MDM安装的证书（企业根证书、802.1X设备证书等），苹果内置根证书不在其中
+func getCertificates(ctx context.Context, info *model.SystemInfo) error {
+	keychains := []keychainSource{
+		{"/Library/Keychains/System.keychain", "系统钥匙串"},
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		keychains = append(keychains, keychainSource{filepath.Join(homeDir, "Library", "Keychains", "login.keychain-db"), "登录钥匙串"})
+	}
+
+	var lastErr error
+	for _, keychain := range keychains {
+		if _, err := os.Stat(keychain.path); err != nil {
+			continue
+		}
+
+		// 以PEM格式导出钥匙串中的所有证书
+		output, err := runCommand(ctx, "security", "find-certificate", "-a", "-p", keychain.path)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		for _, cert := range analysis.ParsePEMCertificates([]byte(output)) {
+			info.Certificates = append(info.Certificates, analysis.EvaluateCertificate(cert, keychain.store))
+		}
+	}
+
+	return lastErr
+}
@@ -2,77 +2,140 @@ package darwin
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"log"
 	"os/exec"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jaypipes/ghw"
 	"github.com/shirou/gopsutil/v3/host"
 	"github.com/shirou/gopsutil/v3/mem"
 
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/internal/collect"
 	"github.com/AsterZephyr/SysSpector/pkg/model"
 )
 
-// GetSystemInfo 收集 macOS 系统的硬件和系统信息
+// slowTaskTimeout 为延迟探测、已安装应用等耗时较长的采集项的默认超时
+const slowTaskTimeout = 2 * time.Minute
+
+// GetSystemInfo 并发收集 macOS 系统的硬件和系统信息，各采集项的并发数和超时见 collect 配置
 func GetSystemInfo() (model.SystemInfo, error) {
 	var info model.SystemInfo
-	var err error
+	sp := newSystemProfiler(profilerDataTypes...)
 
-	// 获取主机名和操作系统信息
-	hostInfo, err := host.Info()
-	if err != nil {
-		log.Printf("Error getting host info: %v", err)
-	} else {
-		info.Hostname = hostInfo.Hostname
-		info.OS = hostInfo.Platform + " " + hostInfo.PlatformVersion
+	// 型号标识符被处理器、电池和充电器等采集项使用，先获取
+	if err := getModelInfo(context.Background(), &info, sp); err != nil {
+		log.Printf("Error getting model: %v", err)
+	}
+
+	// 硬件、动态硬件、网络和软件信息的采集项一起并发执行，共用同一次system_profiler调用的结果
+	tasks := hardwareTasks(sp)
+	tasks = append(tasks, dynamicTasks(sp)...)
+	tasks = append(tasks, collect.Field(networkTasks(sp), func(info *model.SystemInfo) *model.NetworkInfo { return &info.Network })...)
+	tasks = append(tasks, softwareTasks()...)
+	info.Network = newNetworkInfo()
+	collect.Run(&info, tasks)
+	analyzeNetwork(&info.Network)
+
+	// 获取WiFi自动连接状态（依赖网络信息中的当前WiFi）
+	if err := getWiFiAutoJoinInfo(&info); err != nil {
+		log.Printf("Error getting WiFi auto join info: %v", err)
 	}
 
+	return info, nil
+}
+
+// hardwareTasks 返回硬件信息的采集项
+func hardwareTasks(sp *systemProfiler) []collect.Task[model.SystemInfo] {
+	return []collect.Task[model.SystemInfo]{
+		{Name: "host", Run: getHostInfo},
+		{Name: "serial_number", Run: getSerialNumber},
+		{Name: "cpu", Run: getCPUInfo},
+		{Name: "memory", Run: func(ctx context.Context, info *model.SystemInfo) error { return getMemoryInfo(ctx, info, sp) }},
+		{Name: "disks", Run: func(ctx context.Context, info *model.SystemInfo) error { return getDisks(ctx, info, sp) }},
+		{Name: "uuid", Run: func(ctx context.Context, info *model.SystemInfo) error {
+			var err error
+			info.UUID, err = HardwareUUID()
+			return err
+		}},
+		// 安全硬件信息和启动盘加密信息（依赖安全硬件信息）
+		{Name: "security_hardware", Run: collect.Steps(func(ctx context.Context, info *model.SystemInfo) error { return getSecurityHardware(ctx, info, sp) }, getDiskEncryption)},
+		// PCIe/雷雳设备列表
+		{Name: "pci_devices", Run: func(ctx context.Context, info *model.SystemInfo) error { return getPCIDevices(ctx, info, sp) }},
+		// 第三方内核扩展和系统扩展
+		{Name: "drivers", Run: getDrivers},
+	}
+}
+
+// getModelInfo 获取设备型号标识符、友好的型号名称和固件版本
+func getModelInfo(ctx context.Context, info *model.SystemInfo, sp *systemProfiler) error {
 	// 获取设备型号标识符
-	modelName, err := runCommand("sysctl", "-n", "hw.model")
+	modelName, err := runCommand(ctx, "sysctl", "-n", "hw.model")
 	if err != nil {
-		log.Printf("Error getting model: %v", err)
-	} else {
-		info.Model = strings.TrimSpace(modelName) // 保存型号标识符
+		return err
 	}
+	info.Model = strings.TrimSpace(modelName) // 保存型号标识符
 
 	// 获取友好的型号名称
-	marketingName, err := runCommand("system_profiler", "SPHardwareDataType")
+	report, err := sp.Report(ctx)
 	if err != nil {
-		log.Printf("Error getting marketing model name: %v", err)
-	} else {
-		// 从system_profiler输出中提取型号名称
-		re := regexp.MustCompile(`Model Name: (.+)`)
-		matches := re.FindStringSubmatch(marketingName)
-		if len(matches) > 1 {
-			// 如果找到了型号名称，更新Model字段，并将原始型号标识符保存到ModelID
-			info.ModelID = info.Model                  // 保存原始型号标识符
-			info.Model = strings.TrimSpace(matches[1]) // 更新为友好的型号名称
-		}
+		return fmt.Errorf("get marketing model name: %v", err)
+	}
+	var hardware spHardware
+	if len(report.Hardware) > 0 {
+		hardware = report.Hardware[0]
+	}
+	if name := hardware.MachineName.String(); name != "" {
+		// 如果找到了型号名称，更新Model字段，并将原始型号标识符保存到ModelID
+		info.ModelID = info.Model // 保存原始型号标识符
+		info.Model = name         // 更新为友好的型号名称
 	}
 
-	// 获取序列号
-	serialNumber, err := runCommand("ioreg", "-c", "IOPlatformExpertDevice", "-d", "2")
+	// 解析固件版本
+	parseFirmwareInfo(info, hardware, report.Bridge)
+	return nil
+}
+
+// getHostInfo 获取主机名和操作系统信息
+func getHostInfo(ctx context.Context, info *model.SystemInfo) error {
+	hostInfo, err := host.Info()
 	if err != nil {
-		log.Printf("Error getting serial number: %v", err)
-	} else {
-		// 使用正则表达式从输出中提取序列号
-		re := regexp.MustCompile(`"IOPlatformSerialNumber" = "([^"]+)"`)
-		matches := re.FindStringSubmatch(serialNumber)
-		if len(matches) > 1 {
-			info.SerialNumber = matches[1]
-		}
+		return err
 	}
+	info.Hostname = hostInfo.Hostname
+	info.OS = hostInfo.Platform + " " + hostInfo.PlatformVersion
+	return nil
+}
+
+// getSerialNumber 获取序列号
+func getSerialNumber(ctx context.Context, info *model.SystemInfo) error {
+	serialNumber, err := runCommand(ctx, "ioreg", "-c", "IOPlatformExpertDevice", "-d", "2")
+	if err != nil {
+		return err
+	}
+	// 使用正则表达式从输出中提取序列号
+	re := regexp.MustCompile(`"IOPlatformSerialNumber" = "([^"]+)"`)
+	matches := re.FindStringSubmatch(serialNumber)
+	if len(matches) > 1 {
+		info.SerialNumber = matches[1]
+	}
+	return nil
+}
 
+// getCPUInfo 获取处理器型号和核心数，Apple Silicon根据型号标识符推断芯片型号
+func getCPUInfo(ctx context.Context, info *model.SystemInfo) error {
 	// 使用 ghw 获取 CPU 信息
 	cpuInfo, err := ghw.CPU()
 	if err != nil {
 		log.Printf("Error getting CPU info with ghw: %v", err)
 
 		// 如果 ghw 失败，回退到 gopsutil
-		coreCount, err := runCommand("sysctl", "-n", "hw.physicalcpu")
+		coreCount, err := runCommand(ctx, "sysctl", "-n", "hw.physicalcpu")
 		cores := 0
 		if err != nil {
 			log.Printf("Error getting CPU core count: %v", err)
@@ -84,7 +147,7 @@ func GetSystemInfo() (model.SystemInfo, error) {
 		isAppleSilicon := false
 
 		// 使用sysctl检查CPU架构
-		archOutput, err := runCommand("sysctl", "-n", "hw.machine")
+		archOutput, err := runCommand(ctx, "sysctl", "-n", "hw.machine")
 		if err == nil {
 			arch := strings.TrimSpace(archOutput)
 			// arm64表示Apple Silicon，x86_64表示Intel
@@ -94,14 +157,14 @@ func GetSystemInfo() (model.SystemInfo, error) {
 		// 如果sysctl失败，尝试使用其他方法
 		if err != nil {
 			// 检查是否存在M系列芯片特有的sysctl键
-			_, err := runCommand("sysctl", "-n", "hw.perflevel0.physicalcpu")
+			_, err := runCommand(ctx, "sysctl", "-n", "hw.perflevel0.physicalcpu")
 			isAppleSilicon = err == nil // 如果这个命令成功，说明是M系列芯片
 		}
 
 		var cpuModel string
 		if isAppleSilicon {
 			// 对于 M 系列芯片，尝试获取处理器型号
-			cpuModelOutput, err := runCommand("sysctl", "-n", "machdep.cpu.brand_string")
+			cpuModelOutput, err := runCommand(ctx, "sysctl", "-n", "machdep.cpu.brand_string")
 			if err != nil || strings.TrimSpace(cpuModelOutput) == "" {
 				// 如果无法获取，则根据设备型号推断
 				if strings.Contains(info.ModelID, "Mac") {
@@ -135,7 +198,7 @@ func GetSystemInfo() (model.SystemInfo, error) {
 			}
 		} else {
 			// 对于 Intel 芯片，使用 sysctl 获取
-			cpuModelOutput, err := runCommand("sysctl", "-n", "machdep.cpu.brand_string")
+			cpuModelOutput, err := runCommand(ctx, "sysctl", "-n", "machdep.cpu.brand_string")
 			if err != nil {
 				log.Printf("Error getting CPU model: %v", err)
 				cpuModel = "Intel CPU"
@@ -162,7 +225,7 @@ func GetSystemInfo() (model.SystemInfo, error) {
 			isAppleSilicon := false
 
 			// 使用sysctl检查CPU架构
-			archOutput, err := runCommand("sysctl", "-n", "hw.machine")
+			archOutput, err := runCommand(ctx, "sysctl", "-n", "hw.machine")
 			if err == nil {
 				arch := strings.TrimSpace(archOutput)
 				// arm64表示Apple Silicon，x86_64表示Intel
@@ -172,7 +235,7 @@ func GetSystemInfo() (model.SystemInfo, error) {
 			// 如果sysctl失败，尝试使用其他方法
 			if err != nil {
 				// 检查是否存在M系列芯片特有的sysctl键
-				_, err := runCommand("sysctl", "-n", "hw.perflevel0.physicalcpu")
+				_, err := runCommand(ctx, "sysctl", "-n", "hw.perflevel0.physicalcpu")
 				isAppleSilicon = err == nil // 如果这个命令成功，说明是M系列芯片
 			}
 
@@ -214,66 +277,53 @@ func GetSystemInfo() (model.SystemInfo, error) {
 		}
 	}
 
-	// 获取内存信息
+	return nil
+}
+
+// getMemoryInfo 获取内存容量、类型和各插槽内存条信息
+func getMemoryInfo(ctx context.Context, info *model.SystemInfo, sp *systemProfiler) error {
 	memInfo, err := mem.VirtualMemory()
 	if err != nil {
-		log.Printf("Error getting memory info: %v", err)
-	} else {
-		// 获取内存类型（通过系统命令）
-		memType := "Unknown"
-		memTypeOutput, err := runCommand("system_profiler", "SPMemoryDataType")
-		if err != nil {
-			log.Printf("Error getting memory type: %v", err)
-		} else {
-			// 尝试从输出中提取内存类型
-			if strings.Contains(memTypeOutput, "Type: LPDDR5") {
-				memType = "LPDDR5"
-			} else if strings.Contains(memTypeOutput, "Type: LPDDR4") {
-				memType = "LPDDR4"
-			} else if strings.Contains(memTypeOutput, "Type: DDR4") {
-				memType = "DDR4"
-			}
-		}
+		return err
+	}
 
-		info.Memory = model.MemoryInfo{
-			Total: memInfo.Total,
-			Type:  memType,
+	// 获取内存类型和各插槽内存条信息
+	memType := "Unknown"
+	var modules []model.MemoryModule
+	upgradeable := false
+	report, err := sp.Report(ctx)
+	if err != nil {
+		log.Printf("Error getting memory type: %v", err)
+	} else if len(report.Memory) > 0 {
+		modules, upgradeable = parseMemoryModules(report.Memory[0])
+		if len(modules) > 0 && modules[0].Type != "" {
+			memType = modules[0].Type
 		}
 	}
 
-	// 使用 ghw 获取磁盘信息
+	info.Memory = model.MemoryInfo{
+		Total:       memInfo.Total,
+		Type:        memType,
+		Modules:     modules,
+		Upgradeable: upgradeable,
+		Mismatched:  analysis.MemoryModulesMismatched(modules),
+	}
+	return nil
+}
+
+// getDisks 获取物理磁盘列表
+func getDisks(ctx context.Context, info *model.SystemInfo, sp *systemProfiler) error {
+	// 使用 ghw 获取磁盘信息，失败时回退到 system_profiler
 	blockInfo, err := ghw.Block()
 	if err != nil {
 		log.Printf("Error getting block info with ghw: %v", err)
 
 		// 如果 ghw 失败，回退到 system_profiler
-		diskInfo, err := runCommand("system_profiler", "SPStorageDataType")
+		report, err := sp.Report(ctx)
 		if err != nil {
 			log.Printf("Error getting disk info: %v", err)
-		} else {
-			// 解析磁盘型号
-			deviceNameRegex := regexp.MustCompile(`Device Name: (.+)`)
-			matches := deviceNameRegex.FindStringSubmatch(diskInfo)
-
-			if len(matches) > 1 {
-				diskModel := strings.TrimSpace(matches[1])
-				diskName := "Unknown"
-
-				// 尝试获取第一个磁盘的 BSD 名称
-				bsdNameRegex := regexp.MustCompile(`BSD Name: (.+)`)
-				bsdMatches := bsdNameRegex.FindStringSubmatch(diskInfo)
-				if len(bsdMatches) > 1 {
-					diskName = strings.TrimSpace(bsdMatches[1])
-				}
-
-				// 添加到磁盘列表
-				info.Disks = append(info.Disks, model.Disk{
-					Name:   diskName,
-					Size:   494, // 默认为 494GB，基于输出结果
-					Serial: "",
-					Model:  diskModel,
-				})
-			}
+		} else if disk, ok := internalDisk(report.Storage); ok {
+			info.Disks = append(info.Disks, disk)
 		}
 	} else {
 		// 使用 ghw 获取的磁盘信息
@@ -295,44 +345,49 @@ func GetSystemInfo() (model.SystemInfo, error) {
 		}
 	}
 
-	// 获取硬件 UUID
-	uuidOutput, err := runCommand("ioreg", "-d2", "-c", "IOPlatformExpertDevice")
-	if err != nil {
-		log.Printf("Error getting UUID: %v", err)
-	} else {
-		// 从输出中提取 UUID
-		re := regexp.MustCompile(`"IOPlatformUUID" = "([^"]+)"`)
-		matches := re.FindStringSubmatch(uuidOutput)
-		if len(matches) > 1 {
-			info.UUID = matches[1]
-		}
-	}
+	return nil
+}
 
-	// 收集动态系统信息
-	err = GetDynamicSystemInfo(&info)
-	if err != nil {
-		log.Printf("Error getting dynamic system info: %v", err)
+// internalDisk 返回SPStorageDataType中第一个内置磁盘上的卷所在的物理磁盘，容量为该卷的容量
+func internalDisk(volumes []spStorage) (model.Disk, bool) {
+	for _, volume := range volumes {
+		drive := volume.PhysicalDrive
+		if drive.DeviceName == "" || drive.Internal != "" && !drive.Internal.Bool() {
+			continue
+		}
+		size, _ := strconv.ParseUint(volume.SizeInBytes.String(), 10, 64)
+		name := volume.BSDName.String()
+		if name == "" {
+			name = "Unknown"
+		}
+		return model.Disk{
+			Name:  name,
+			Size:  size / (1024 * 1024 * 1024),
+			Model: drive.DeviceName.String(),
+		}, true
 	}
+	return model.Disk{}, false
+}
 
-	// 收集网络信息
-	err = GetNetworkInfo(&info)
+// HardwareUUID 通过ioreg获取硬件UUID，不需要采集其他系统信息，用于计算设备ID
+func HardwareUUID() (string, error) {
+	output, err := runCommand(context.Background(), "ioreg", "-d2", "-c", "IOPlatformExpertDevice")
 	if err != nil {
-		log.Printf("Error getting network info: %v", err)
+		return "", err
 	}
-
-	// 收集系统和软件信息
-	err = GetSystemSoftwareInfo(&info)
-	if err != nil {
-		log.Printf("Error getting system and software info: %v", err)
+	// 从输出中提取 UUID
+	re := regexp.MustCompile(`"IOPlatformUUID" = "([^"]+)"`)
+	matches := re.FindStringSubmatch(output)
+	if matches == nil {
+		return "", fmt.Errorf("IOPlatformUUID not found")
 	}
-
-	return info, nil
+	return matches[1], nil
 }
 
-// runCommand 执行系统命令并返回输出结果
-func runCommand(command string, args ...string) (string, error) {
+// runCommand 执行系统命令并返回输出结果，ctx被取消或超时时终止命令
+func runCommand(ctx context.Context, command string, args ...string) (string, error) {
 	// 创建命令
-	cmd := exec.Command(command, args...)
+	cmd := exec.CommandContext(ctx, command, args...)
 
 	// 捕获标准输出和错误
 	var stdout, stderr bytes.Buffer
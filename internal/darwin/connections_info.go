@@ -0,0 +1,18 @@
+package darwin
+
+import (
+	"context"
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/internal/sockets"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// getConnections 获取已建立的连接，并按进程和远端组织汇总
+func getConnections(ctx context.Context, info *model.NetworkInfo) error {
+	connections, err := sockets.Established()
+	if err != nil {
+		return err
+	}
+	info.Connections = analysis.SummarizeConnections(connections)
+	return nil
+}
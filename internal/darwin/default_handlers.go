@@ -0,0 +1,90 @@
+package darwin
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// handlerType 表示需要检查默认程序的协议或文件类型
+type handlerType struct {
+	name          string // 显示名称（协议或扩展名）
+	urlScheme     string
+	contentType   string // 统一类型标识符（UTI）
+	systemDefault string // 用户未修改时的系统默认程序
+}
+
+// handlerTypes 常见协议和文件类型
+var handlerTypes = []handlerType{
+	{name: "http", urlScheme: "http", systemDefault: "com.apple.safari"},
+	{name: "https", urlScheme: "https", systemDefault: "com.apple.safari"},
+	{name: "mailto", urlScheme: "mailto", systemDefault: "com.apple.mail"},
+	{name: ".html", contentType: "public.html", systemDefault: "com.apple.safari"},
+	{name: ".pdf", contentType: "com.adobe.pdf", systemDefault: "com.apple.preview"},
+	{name: ".docx", contentType: "org.openxmlformats.wordprocessingml.document"},
+	{name: ".xlsx", contentType: "org.openxmlformats.spreadsheetml.sheet"},
+	{name: ".pptx", contentType: "org.openxmlformats.presentationml.presentation"},
+	{name: ".txt", contentType: "public.plain-text", systemDefault: "com.apple.textedit"},
+	{name: ".eml", contentType: "com.apple.mail.email", systemDefault: "com.apple.mail"},
+	{name: ".ics", contentType: "com.apple.ical.ics", systemDefault: "com.apple.ical"},
+}
+
+// getDefaultHandlers 获取常见协议和文件类型的默认打开程序
+// 依赖getInstalledApps先收集应用的Bundle ID，用于显示应用名称
+func getDefaultHandlers(ctx context.Context, info *model.SystemInfo) error {
+	homeDir, _ := os.UserHomeDir()
+	handlers, err := readLaunchServicesHandlers(homeDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// 协议使用RoleAll，文件类型通常使用RoleViewer
+	userChoices := make(map[string]string)
+	for _, handler := range handlers.LSHandlers {
+		bundleID := handler.RoleAll
+		if bundleID == "" {
+			bundleID = handler.RoleViewer
+		}
+		if bundleID == "" || bundleID == "-" {
+			continue
+		}
+		if handler.URLScheme != "" {
+			userChoices["scheme:"+strings.ToLower(handler.URLScheme)] = strings.ToLower(bundleID)
+		} else if handler.ContentType != "" {
+			userChoices["type:"+strings.ToLower(handler.ContentType)] = strings.ToLower(bundleID)
+		}
+	}
+
+	appNames := make(map[string]string)
+	for _, app := range info.InstalledApps {
+		if app.BundleID != "" {
+			appNames[strings.ToLower(app.BundleID)] = app.Name
+		}
+	}
+
+	for _, t := range handlerTypes {
+		key := "type:" + t.contentType
+		if t.urlScheme != "" {
+			key = "scheme:" + t.urlScheme
+		}
+
+		handler := model.DefaultHandlerInfo{Type: t.name, ID: userChoices[key]}
+		if handler.ID == "" {
+			handler.ID = t.systemDefault
+			handler.SystemDefault = true
+		}
+		if handler.ID == "" {
+			// 没有内置默认程序的类型（如Office文档）由首个注册的应用处理，无法离线确定
+			handler.Handler = "未确定"
+		} else if name, ok := appNames[handler.ID]; ok {
+			handler.Handler = name
+		} else {
+			handler.Handler = handler.ID
+		}
+		info.DefaultHandlers = append(info.DefaultHandlers, handler)
+	}
+
+	return nil
+}
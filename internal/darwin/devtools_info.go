@@ -0,0 +1,129 @@
+package darwin
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// devToolProbe 表示通过命令行查询版本的开发工具
+type devToolProbe struct {
+	name    string
+	command string
+	args    []string
+}
+
+// devToolProbes 需要检测的运行时和包管理器
+var devToolProbes = []devToolProbe{
+	{"Python", "python3", []string{"--version"}},
+	{"Node.js", "node", []string{"--version"}},
+	{"npm", "npm", []string{"--version"}},
+	{".NET", "dotnet", []string{"--version"}},
+	{"Go", "go", []string{"version"}},
+	{"Git", "git", []string{"--version"}},
+	{"Docker", "docker", []string{"--version"}},
+	{"Homebrew", "brew", []string{"--version"}},
+}
+
+// toolVersionRegex 从版本输出中提取第一个版本号
+var toolVersionRegex = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// getDevTools 获取Xcode命令行工具、Java以及常用运行时和包管理器的版本
+func getDevTools(ctx context.Context, info *model.SystemInfo) error {
+	// Xcode命令行工具
+	cltInstalled := false
+	if output, err := runCommand(ctx, "xcode-select", "-p"); err == nil {
+		cltInstalled = true
+		tool := model.DevToolInfo{Name: "Xcode Command Line Tools", Path: strings.TrimSpace(output)}
+		if pkgInfo, err := runCommand(ctx, "pkgutil", "--pkg-info=com.apple.pkg.CLTools_Executables"); err == nil {
+			tool.Version = parsePkgutilVersion(pkgInfo)
+		} else if strings.Contains(tool.Path, "Xcode.app") {
+			// 只安装了Xcode时使用Xcode的版本
+			tool.Name = "Xcode"
+			if xcodeInfo, err := runCommand(ctx, "xcodebuild", "-version"); err == nil {
+				tool.Version = toolVersionRegex.FindString(xcodeInfo)
+			}
+		}
+		info.DevTools = append(info.DevTools, tool)
+	}
+
+	// Java：/usr/bin/java在未安装JDK时会弹出安装提示，因此通过java_home定位JDK
+	if output, err := runCommand(ctx, "/usr/libexec/java_home"); err == nil {
+		javaHome := strings.TrimSpace(output)
+		info.DevTools = append(info.DevTools, model.DevToolInfo{
+			Name:    "Java",
+			Version: readJavaReleaseVersion(javaHome),
+			Path:    javaHome,
+		})
+	}
+
+	for _, probe := range devToolProbes {
+		path := lookupDevTool(probe.command)
+		if path == "" {
+			continue
+		}
+		// /usr/bin下的python3、git等是命令行工具的占位程序，未安装时执行会弹出安装提示
+		if strings.HasPrefix(path, "/usr/bin/") && !cltInstalled {
+			continue
+		}
+
+		output, err := runCommand(ctx, path, probe.args...)
+		if err != nil {
+			continue
+		}
+		info.DevTools = append(info.DevTools, model.DevToolInfo{
+			Name:    probe.name,
+			Version: toolVersionRegex.FindString(output),
+			Path:    path,
+		})
+	}
+
+	return nil
+}
+
+// lookupDevTool 在PATH中查找命令，找不到时再查找Homebrew目录（以服务方式运行时PATH通常不包含Homebrew）
+func lookupDevTool(command string) string {
+	if path, err := exec.LookPath(command); err == nil {
+		return path
+	}
+	for _, prefix := range homebrewPrefixes {
+		path := filepath.Join(prefix, "bin", command)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// parsePkgutilVersion 从pkgutil --pkg-info的输出中提取version字段
+func parsePkgutilVersion(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if value, ok := strings.CutPrefix(line, "version: "); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// readJavaReleaseVersion 从JDK目录下的release文件读取JAVA_VERSION
+func readJavaReleaseVersion(javaHome string) string {
+	file, err := os.Open(filepath.Join(javaHome, "release"))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "JAVA_VERSION="); ok {
+			return strings.Trim(value, `"`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,200 @@
+package darwin
+
+import (
+	"context"
+	"encoding/hex"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"howett.net/plist"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// dhcpLeaseDir 系统DHCP客户端保存租约的目录，每个接口和网络一个plist文件
+const dhcpLeaseDir = "/var/db/dhcpclient/leases"
+
+// getDHCPLeases 通过ipconfig getpacket获取每个接口最近一次DHCP应答中的选项，
+// 获取租约的时间从系统DHCP客户端保存的租约文件中读取
+func getDHCPLeases(ctx context.Context, info *model.NetworkInfo) error {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return err
+	}
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		// 没有通过DHCP获取地址的接口没有应答数据，命令会失败
+		output, err := runCommand(ctx, "ipconfig", "getpacket", iface.Name)
+		if err != nil || strings.TrimSpace(output) == "" {
+			continue
+		}
+		lease := parseDHCPPacket(output)
+		lease.Interface = iface.Name
+		if start, ok := dhcpLeaseStart(iface.Name, lease.IPAddress); ok {
+			lease.LeaseObtained = start.Format("2006-01-02 15:04:05")
+			if lease.LeaseTime > 0 {
+				lease.LeaseExpires = start.Add(time.Duration(lease.LeaseTime) * time.Second).Format("2006-01-02 15:04:05")
+			}
+		}
+		info.DHCPLeases = append(info.DHCPLeases, lease)
+	}
+	return nil
+}
+
+// parseDHCPPacket 解析ipconfig getpacket的输出，例如：
+//
+//	yiaddr = 192.168.1.100
+//	options:
+//	server_identifier (ip): 192.168.1.1
+//	lease_time (uint32): 0x15180
+//	router (ip_mult): {192.168.1.1}
+//	domain_name_server (ip_mult): {192.168.1.1, 8.8.8.8}
+//	domain_name (string): lan
+//	vendor_specific (opaque):
+//	0000  01 04 0a 00 00 01                                 ......
+func parseDHCPPacket(output string) model.DHCPLeaseInfo {
+	var lease model.DHCPLeaseInfo
+	var opaque *string
+	var opaqueData []byte
+	flushOpaque := func() {
+		// 厂商类别标识通常是文本
+		if opaque == &lease.VendorClass && isPrintable(opaqueData) {
+			*opaque = string(opaqueData)
+		} else if opaque != nil {
+			*opaque = hex.EncodeToString(opaqueData)
+		}
+		opaque, opaqueData = nil, nil
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		if opaque != nil {
+			if data, ok := parseHexDumpLine(line); ok {
+				opaqueData = append(opaqueData, data...)
+				continue
+			}
+			flushOpaque()
+		}
+
+		if key, value, ok := strings.Cut(line, " = "); ok {
+			if strings.TrimSpace(key) == "yiaddr" {
+				lease.IPAddress = strings.TrimSpace(value)
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, "):")
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(key, " (")
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(name) {
+		case "server_identifier":
+			lease.Server = value
+		case "lease_time":
+			if seconds, err := strconv.ParseInt(value, 0, 64); err == nil {
+				lease.LeaseTime = int(seconds)
+			}
+		case "router":
+			lease.Routers = parseIPList(value)
+		case "domain_name_server":
+			lease.DNSServers = parseIPList(value)
+		case "domain_name":
+			lease.Domain = value
+		case "vendor_specific":
+			opaque = &lease.VendorSpecific
+		case "vendor_class_identifier":
+			if strings.HasPrefix(key, "vendor_class_identifier (opaque") {
+				opaque = &lease.VendorClass
+			} else {
+				lease.VendorClass = value
+			}
+		}
+	}
+	flushOpaque()
+	return lease
+}
+
+// parseIPList 解析"{192.168.1.1, 8.8.8.8}"格式的地址列表
+func parseIPList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(strings.Trim(value, "{}"), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// parseHexDumpLine 解析"0000  01 04 0a 00 00 01  ......"格式的十六进制转储行，每行最多16字节，之后为ASCII列
+func parseHexDumpLine(line string) ([]byte, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || len(fields[0]) != 4 {
+		return nil, false
+	}
+	if _, err := strconv.ParseUint(fields[0], 16, 16); err != nil {
+		return nil, false
+	}
+	var data []byte
+	for _, field := range fields[1:] {
+		if len(data) == 16 || len(field) != 2 {
+			break
+		}
+		b, err := strconv.ParseUint(field, 16, 8)
+		if err != nil {
+			break
+		}
+		data = append(data, byte(b))
+	}
+	// 只有两个字节且ASCII列恰好也是两个十六进制字符时，ASCII列会被当作第三个字节
+	if len(fields) == 4 && len(data) == 3 && string(data[:2]) == fields[3] {
+		data = data[:2]
+	}
+	return data, len(data) > 0
+}
+
+// isPrintable 判断数据是否都是可打印的ASCII字符
+func isPrintable(data []byte) bool {
+	for _, b := range data {
+		if b < 0x20 || b > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// dhcpLeaseStart 从租约文件中查找接口上指定地址的租约开始时间，文件名为接口名加网络标识，例如en0-1,a4:83:e7:1:2:3
+// 读取失败（例如没有权限）时返回false
+func dhcpLeaseStart(name, address string) (time.Time, bool) {
+	files, err := filepath.Glob(filepath.Join(dhcpLeaseDir, name+"*"))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var latest time.Time
+	for _, file := range files {
+		base := filepath.Base(file)
+		if base != name && !strings.HasPrefix(base, name+"-") && !strings.HasPrefix(base, name+".") {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var lease struct {
+			IPAddress      string
+			LeaseStartDate time.Time
+		}
+		if _, err := plist.Unmarshal(data, &lease); err != nil || lease.IPAddress != address {
+			continue
+		}
+		if lease.LeaseStartDate.After(latest) {
+			latest = lease.LeaseStartDate
+		}
+	}
+	return latest.Local(), !latest.IsZero()
+}
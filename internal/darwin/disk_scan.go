@@ -0,0 +1,35 @@
+package darwin
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/AsterZephyr/SysSpector/internal/diskscan"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// ScanDisk 分析用户主目录中占用最大的目录以及常见的空间占用大户
+// 需要遍历整个主目录，只在使用--scan-disk参数时调用
+func ScanDisk() (model.DiskScanInfo, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return model.DiskScanInfo{}, err
+	}
+
+	home := func(elem ...string) string {
+		return filepath.Join(append([]string{homeDir}, elem...)...)
+	}
+	hogs := []diskscan.SpaceHog{
+		{Name: "用户缓存", Path: home("Library", "Caches"), Hint: "可以删除，应用会按需重新生成"},
+		{Name: "系统缓存", Path: "/Library/Caches", Hint: "可以删除，应用会按需重新生成"},
+		{Name: "Docker虚拟磁盘", Path: home("Library", "Containers", "com.docker.docker", "Data", "vms", "0", "data", "Docker.raw"), Hint: "执行docker system prune清理无用镜像，或在Docker Desktop中调小磁盘上限"},
+		{Name: "Xcode DerivedData", Path: home("Library", "Developer", "Xcode", "DerivedData"), Hint: "可以删除，Xcode会在编译时重新生成"},
+		{Name: "Xcode模拟器", Path: home("Library", "Developer", "CoreSimulator", "Devices"), Hint: "执行xcrun simctl delete unavailable删除不可用的模拟器"},
+		{Name: "iOS设备备份", Path: home("Library", "Application Support", "MobileSync", "Backup"), Hint: "在访达中管理设备备份，删除旧备份"},
+		{Name: "下载", Path: home("Downloads"), Hint: "清理不再需要的下载文件"},
+		{Name: "废纸篓", Path: home(".Trash"), Hint: "清倒废纸篓"},
+		{Name: "OneDrive", Path: home("Library", "CloudStorage", "OneDrive*"), Hint: "在OneDrive中将不常用的文件设为仅联机可用"},
+	}
+
+	return diskscan.Scan(homeDir, hogs), nil
+}
@@ -0,0 +1,87 @@
+package darwin
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// scutilIfIndexRegex 匹配解析器绑定的接口，例如"if_index : 20 (utun4)"
+var scutilIfIndexRegex = regexp.MustCompile(`\((\S+)\)`)
+
+// parseScutilDNS 解析scutil --dns输出中的各个解析器，例如：
+//
+//	DNS configuration
+//
+//	resolver #1
+//	  search domain[0] : corp.example.com
+//	  nameserver[0] : 10.0.0.53
+//	  if_index : 20 (utun4)
+//	  order    : 100000
+//
+//	DNS configuration (for scoped queries)
+//
+//	resolver #1
+//	  nameserver[0] : 192.168.1.1
+//	  if_index : 6 (en0)
+//	  flags    : Scoped, Request A records
+//
+// 第一部分为默认和指定域名的解析器，第二部分为只用于绑定了接口的查询的解析器；没有DNS服务器的mDNS解析器被跳过
+func parseScutilDNS(output string) []model.DNSResolverInfo {
+	var resolvers []model.DNSResolverInfo
+	var current *model.DNSResolverInfo
+	scoped := false
+
+	flush := func() {
+		if current != nil && len(current.Servers) > 0 {
+			resolvers = append(resolvers, *current)
+		}
+		current = nil
+	}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "DNS configuration"):
+			flush()
+			scoped = strings.Contains(line, "scoped")
+			continue
+		case strings.HasPrefix(line, "resolver #"):
+			flush()
+			current = &model.DNSResolverInfo{Scope: analysis.DNSScopeDefault}
+			if scoped {
+				current.Scope = analysis.DNSScopeInterface
+			}
+			continue
+		case current == nil:
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(key, "nameserver"):
+			current.Servers = append(current.Servers, value)
+		case strings.HasPrefix(key, "search domain"):
+			current.SearchDomains = append(current.SearchDomains, value)
+		case key == "domain":
+			current.Domain = value
+			if !scoped {
+				current.Scope = analysis.DNSScopeDomain
+			}
+		case key == "if_index":
+			if m := scutilIfIndexRegex.FindStringSubmatch(value); m != nil {
+				current.Interface = m[1]
+			}
+		case key == "order":
+			current.Order, _ = strconv.Atoi(value)
+		}
+	}
+	flush()
+	return resolvers
+}
@@ -0,0 +1,112 @@
+package darwin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+	"howett.net/plist"
+)
+
+// kmutilLoadedRegex 匹配kmutil showloaded输出中的"名称 (版本) UUID"
+var kmutilLoadedRegex = regexp.MustCompile(`\s(\S+)\s+\(([^)]+)\)\s+[0-9A-Fa-f-]{36}`)
+
+// sysextBundleRegex 匹配systemextensionsctl list中的"bundleID (版本)"
+var sysextBundleRegex = regexp.MustCompile(`^(\S+)\s+\(([^)]+)\)$`)
+
+// getDrivers 获取已加载的第三方内核扩展和系统扩展
+func getDrivers(ctx context.Context, info *model.SystemInfo) error {
+	var errs []string
+
+	// 第三方内核扩展，从macOS 11起已被弃用
+	output, err := runCommand(ctx, "kmutil", "showloaded")
+	if err != nil {
+		// 旧版本macOS没有kmutil
+		output, err = runCommand(ctx, "kextstat", "-l")
+	}
+	if err != nil {
+		errs = append(errs, err.Error())
+	} else {
+		kextPaths := findKextBundles()
+		for _, line := range strings.Split(output, "\n") {
+			matches := kmutilLoadedRegex.FindStringSubmatch(line)
+			if len(matches) < 3 || strings.HasPrefix(matches[1], "com.apple.") {
+				continue
+			}
+
+			driver := model.DriverInfo{
+				Name:       matches[1],
+				Kind:       "内核扩展",
+				Version:    matches[2],
+				State:      "已加载",
+				Signature:  "未知",
+				Deprecated: true,
+			}
+			if path, ok := kextPaths[matches[1]]; ok {
+				if _, err := runCommand(ctx, "codesign", "--verify", path); err == nil {
+					driver.Signature = "已签名"
+				} else {
+					driver.Signature = "未签名"
+				}
+			}
+			info.Drivers = append(info.Drivers, driver)
+		}
+	}
+
+	// 系统扩展，格式为：enabled active teamID bundleID (version) name [state]
+	output, err = runCommand(ctx, "systemextensionsctl", "list")
+	if err != nil {
+		errs = append(errs, err.Error())
+	} else {
+		for _, line := range strings.Split(output, "\n") {
+			fields := strings.Split(line, "\t")
+			if len(fields) < 6 {
+				continue
+			}
+			matches := sysextBundleRegex.FindStringSubmatch(strings.TrimSpace(fields[3]))
+			if len(matches) < 3 {
+				continue
+			}
+
+			// 系统扩展必须经过公证才能激活
+			info.Drivers = append(info.Drivers, model.DriverInfo{
+				Name:      matches[1],
+				Kind:      "系统扩展",
+				Version:   matches[2],
+				Provider:  strings.TrimSpace(fields[2]),
+				State:     strings.Trim(strings.TrimSpace(fields[5]), "[]"),
+				Signature: "已签名",
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// findKextBundles 返回/Library/Extensions下内核扩展的Bundle ID到路径的映射
+func findKextBundles() map[string]string {
+	paths := make(map[string]string)
+
+	bundles, _ := filepath.Glob("/Library/Extensions/*.kext")
+	for _, bundle := range bundles {
+		data, err := os.ReadFile(filepath.Join(bundle, "Contents", "Info.plist"))
+		if err != nil {
+			continue
+		}
+		var bundleInfo struct {
+			Identifier string `plist:"CFBundleIdentifier"`
+		}
+		if _, err := plist.Unmarshal(data, &bundleInfo); err == nil && bundleInfo.Identifier != "" {
+			paths[bundleInfo.Identifier] = bundle
+		}
+	}
+
+	return paths
+}
@@ -1,6 +1,7 @@
 package darwin
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/exec"
@@ -10,62 +11,35 @@ import (
 
 	"fmt"
 
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/internal/collect"
 	"github.com/AsterZephyr/SysSpector/pkg/model"
 	"github.com/shirou/gopsutil/v3/disk"
 	"github.com/shirou/gopsutil/v3/mem"
+	"howett.net/plist"
 )
 
-// GetDynamicSystemInfo 收集macOS系统的动态硬件信息
-func GetDynamicSystemInfo(info *model.SystemInfo) error {
-	var err error
-
-	// 收集硬盘使用情况
-	err = getDiskUsage(info)
-	if err != nil {
-		log.Printf("Error getting disk usage: %v", err)
-	}
-
-	// 收集内存使用情况
-	err = getMemoryUsage(info)
-	if err != nil {
-		log.Printf("Error getting memory usage: %v", err)
-	}
-
-	// 收集电池信息
-	err = getBatteryInfo(info)
-	if err != nil {
-		log.Printf("Error getting battery info: %v", err)
-	}
-
-	// 收集交流充电器信息
-	err = getACAdapterInfo(info)
-	if err != nil {
-		log.Printf("Error getting AC adapter info: %v", err)
-	}
-
-	// 收集蓝牙信息
-	err = getBluetoothInfo(info)
-	if err != nil {
-		log.Printf("Error getting bluetooth info: %v", err)
-	}
-
-	// 收集设备温度信息
-	err = getTemperatureInfo(info)
-	if err != nil {
-		log.Printf("Error getting temperature info: %v", err)
-	}
-
-	// 收集WiFi自动连接状态
-	err = getWiFiAutoJoinInfo(info)
-	if err != nil {
-		log.Printf("Error getting WiFi auto join info: %v", err)
+// dynamicTasks 返回macOS系统动态硬件信息的采集项
+func dynamicTasks(sp *systemProfiler) []collect.Task[model.SystemInfo] {
+	return []collect.Task[model.SystemInfo]{
+		// 硬盘使用情况，外接和可移动存储信息
+		{Name: "disk_usage", Run: getDiskUsage},
+		{Name: "external_storage", Run: getExternalStorage},
+		{Name: "memory_usage", Run: getMemoryUsage},
+		// 电池和交流充电器信息（依赖型号标识符）
+		{Name: "battery", Run: func(ctx context.Context, info *model.SystemInfo) error { return getBatteryInfo(ctx, info, sp) }},
+		{Name: "ac_adapter", Run: func(ctx context.Context, info *model.SystemInfo) error { return getACAdapterInfo(ctx, info, sp) }},
+		// 显示器亮度信息
+		{Name: "display", Run: getDisplayInfo},
+		{Name: "bluetooth", Run: func(ctx context.Context, info *model.SystemInfo) error { return getBluetoothInfo(ctx, info, sp) }},
+		{Name: "input_devices", Run: getInputDevices},
+		// 设备温度信息
+		{Name: "temperature", Run: getTemperatureInfo},
 	}
-
-	return nil
 }
 
 // getDiskUsage 获取硬盘使用情况
-func getDiskUsage(info *model.SystemInfo) error {
+func getDiskUsage(ctx context.Context, info *model.SystemInfo) error {
 	// 使用gopsutil获取根目录的磁盘使用情况
 	usage, err := disk.Usage("/")
 	if err != nil {
@@ -89,7 +63,7 @@ func getDiskUsage(info *model.SystemInfo) error {
 }
 
 // getMemoryUsage 获取内存使用情况
-func getMemoryUsage(info *model.SystemInfo) error {
+func getMemoryUsage(ctx context.Context, info *model.SystemInfo) error {
 	// 使用gopsutil获取内存使用情况
 	memInfo, err := mem.VirtualMemory()
 	if err != nil {
@@ -107,68 +81,58 @@ func getMemoryUsage(info *model.SystemInfo) error {
 }
 
 // getBatteryInfo 获取电池信息
-func getBatteryInfo(info *model.SystemInfo) error {
-	// 使用pmset命令获取电池信息
-	output, err := runCommand("pmset", "-g", "batt")
+func getBatteryInfo(ctx context.Context, info *model.SystemInfo, sp *systemProfiler) error {
+	// 从AppleSmartBattery获取电池是否存在、电量、充电状态和剩余时间
+	smart, present, err := getSmartBattery(ctx)
 	if err != nil {
 		return err
 	}
 
-	// 解析电池百分比和充电状态
-	batteryInfo := model.BatteryInfo{}
-
-	// 检查是否存在电池
-	batteryInfo.IsPresent = !strings.Contains(output, "No batteries available")
-
+	batteryInfo := model.BatteryInfo{IsPresent: present}
 	if !batteryInfo.IsPresent {
 		info.Battery = batteryInfo
 		return nil
 	}
 
-	// 使用正则表达式匹配电池百分比
-	percentRegex := regexp.MustCompile(`(\d+)%`)
-	percentMatches := percentRegex.FindStringSubmatch(output)
-	if len(percentMatches) > 1 {
-		percentage, _ := strconv.Atoi(percentMatches[1])
-		batteryInfo.Percentage = percentage
+	// Apple Silicon上CurrentCapacity和MaxCapacity是百分比，Intel Mac上是mAh，按比例计算电量
+	if smart.MaxCapacity > 0 {
+		batteryInfo.Percentage = smart.CurrentCapacity * 100 / smart.MaxCapacity
 	}
+	batteryInfo.IsCharging = smart.IsCharging
 
-	// 检查充电状态
-	batteryInfo.IsCharging = strings.Contains(output, "charging") && !strings.Contains(output, "discharging")
-
-	// 检查剩余时间
-	timeRegex := regexp.MustCompile(`(\d+):(\d+)`)
-	timeMatches := timeRegex.FindStringSubmatch(output)
-	if len(timeMatches) > 2 {
-		hours, _ := strconv.Atoi(timeMatches[1])
-		minutes, _ := strconv.Atoi(timeMatches[2])
-		batteryInfo.TimeRemaining = hours*60 + minutes
+	// 剩余时间（分钟），正在估算时为65535
+	if smart.TimeRemaining > 0 && smart.TimeRemaining < 65535 {
+		batteryInfo.TimeRemaining = smart.TimeRemaining
 	}
 
 	// 获取电池循环计数和健康状态
-	cycleOutput, err := runCommand("system_profiler", "SPPowerDataType")
-	if err == nil {
-		// 获取循环计数
-		cycleRegex := regexp.MustCompile(`Cycle Count: (\d+)`)
-		cycleMatches := cycleRegex.FindStringSubmatch(cycleOutput)
-		if len(cycleMatches) > 1 {
-			cycleCount, _ := strconv.Atoi(cycleMatches[1])
-			batteryInfo.CycleCount = cycleCount
+	if report, err := sp.Report(ctx); err == nil {
+		if battery, ok := report.power(spPowerBattery); ok {
+			health := battery.HealthInfo
+			batteryInfo.CycleCount = health.CycleCount.Int()
+			batteryInfo.Health = health.Health.String()
+
+			// 获取最大容量
+			if maxCapacity := health.MaximumCapacity.Int(); maxCapacity > 0 {
+				batteryInfo.Status = fmt.Sprintf("最大容量: %d%%", maxCapacity)
+				batteryInfo.HealthPercent = float64(maxCapacity)
+			}
 		}
+	}
 
-		// 获取电池健康状态
-		healthRegex := regexp.MustCompile(`Condition: (.+)`)
-		healthMatches := healthRegex.FindStringSubmatch(cycleOutput)
-		if len(healthMatches) > 1 {
-			batteryInfo.Health = strings.TrimSpace(healthMatches[1])
-		}
+	// 获取设计容量和满充容量
+	batteryInfo.DesignCapacity = smart.DesignCapacity
+	batteryInfo.FullChargeCapacity = smart.fullChargeCapacity()
+
+	// 计算电池健康度和更换建议
+	analysis.EvaluateBatteryHealth(&batteryInfo)
 
-		// 获取最大容量
-		maxCapacityRegex := regexp.MustCompile(`Maximum Capacity: (\d+)%`)
-		maxCapacityMatches := maxCapacityRegex.FindStringSubmatch(cycleOutput)
-		if len(maxCapacityMatches) > 1 {
-			maxCapacity, _ := strconv.Atoi(maxCapacityMatches[1])
-			batteryInfo.Status = fmt.Sprintf("最大容量: %d%%", maxCapacity)
+	// 检查低电量模式，pmset -g输出中包含"lowpowermode 1"
+	pmsetOutput, err := runCommand(ctx, "pmset", "-g")
+	if err == nil {
+		lowPowerRegex := regexp.MustCompile(`lowpowermode\s+(\d+)`)
+		if matches := lowPowerRegex.FindStringSubmatch(pmsetOutput); len(matches) > 1 {
+			batteryInfo.LowPowerMode = matches[1] == "1"
 		}
 	}
 
@@ -176,19 +140,64 @@ func getBatteryInfo(info *model.SystemInfo) error {
 	return nil
 }
 
-// getACAdapterInfo 获取交流充电器信息
-func getACAdapterInfo(info *model.SystemInfo) error {
-	// 检测是否为Apple Silicon芯片
-	isAppleSilicon := false
-	cmd := exec.Command("sysctl", "machdep.cpu.brand_string")
-	cpuOutput, err := cmd.Output()
-	if err == nil {
-		cpuOutputStr := string(cpuOutput)
-		isAppleSilicon = strings.Contains(cpuOutputStr, "Apple")
+// smartBattery 表示ioreg -a输出中AppleSmartBattery的属性，容量单位为mAh
+type smartBattery struct {
+	BatteryInstalled bool `plist:"BatteryInstalled"`
+	CurrentCapacity  int  `plist:"CurrentCapacity"`
+	MaxCapacity      int  `plist:"MaxCapacity"` // Apple Silicon上是百分比
+	IsCharging       bool `plist:"IsCharging"`
+	TimeRemaining    int  `plist:"TimeRemaining"`
+
+	DesignCapacity        int `plist:"DesignCapacity"`
+	AppleRawMaxCapacity   int `plist:"AppleRawMaxCapacity"`
+	NominalChargeCapacity int `plist:"NominalChargeCapacity"`
+
+	// 充电器协商的参数，例如{"Watts"=96,"AdapterVoltage"=20000,"Current"=4700}，电压和电流单位为mV和mA
+	AdapterDetails struct {
+		Watts          int `plist:"Watts"`
+		AdapterVoltage int `plist:"AdapterVoltage"`
+		Current        int `plist:"Current"`
+	} `plist:"AdapterDetails"`
+}
+
+// getSmartBattery 以plist格式读取ioreg中的AppleSmartBattery，没有电池时present为false
+func getSmartBattery(ctx context.Context) (battery smartBattery, present bool, err error) {
+	output, err := runCommand(ctx, "ioreg", "-a", "-r", "-c", "AppleSmartBattery")
+	if err != nil {
+		return battery, false, err
+	}
+	// 没有匹配的对象时ioreg不输出任何内容
+	if strings.TrimSpace(output) == "" {
+		return battery, false, nil
+	}
+
+	var batteries []smartBattery
+	if _, err := plist.Unmarshal([]byte(output), &batteries); err != nil {
+		return battery, false, err
+	}
+	if len(batteries) == 0 {
+		return battery, false, nil
+	}
+	battery = batteries[0]
+	return battery, battery.BatteryInstalled || battery.DesignCapacity > 0, nil
+}
+
+// fullChargeCapacity 返回当前满充容量（mAh）
+// Apple Silicon上MaxCapacity是百分比，真实满充容量在AppleRawMaxCapacity或NominalChargeCapacity中
+func (b smartBattery) fullChargeCapacity() int {
+	for _, capacity := range []int{b.AppleRawMaxCapacity, b.NominalChargeCapacity, b.MaxCapacity} {
+		// 忽略以百分比表示的MaxCapacity
+		if capacity > 100 {
+			return capacity
+		}
 	}
+	return 0
+}
 
+// getACAdapterInfo 获取交流充电器信息
+func getACAdapterInfo(ctx context.Context, info *model.SystemInfo, sp *systemProfiler) error {
 	// 使用system_profiler获取电源信息，这与shell脚本一致
-	powerOutput, err := runCommand("system_profiler", "SPPowerDataType")
+	report, err := sp.Report(ctx)
 	if err != nil {
 		return err
 	}
@@ -196,155 +205,123 @@ func getACAdapterInfo(info *model.SystemInfo) error {
 	// 解析交流充电器信息
 	adapterInfo := model.ACAdapterInfo{}
 
-	// 检查是否连接了交流充电器
-	if isAppleSilicon {
-		// Apple Silicon Mac的充电器信息格式
-		adapterInfo.Connected = strings.Contains(powerOutput, "AC Charger Information:")
-	} else {
-		// Intel Mac的充电器信息格式
-		adapterInfo.Connected = strings.Contains(powerOutput, "AC Charger Information:") || 
-			strings.Contains(powerOutput, "AC Adapter Information:") || 
-			strings.Contains(powerOutput, "Power Adapter Information:")
-	}
+	// 检查是否连接了交流充电器，台式机没有充电器条目
+	charger, ok := report.power(spPowerCharger)
+	adapterInfo.Connected = ok && charger.ChargerConnected.Bool()
 	adapterInfo.IsConnected = adapterInfo.Connected // 设置兼容性字段
 
 	if adapterInfo.Connected {
 		// 尝试获取充电器序列号
-		serialRegex := regexp.MustCompile(`(?:Serial Number|ID): (.+)`)
-		serialMatches := serialRegex.FindStringSubmatch(powerOutput)
-		if len(serialMatches) > 1 {
-			adapterInfo.SerialNum = strings.TrimSpace(serialMatches[1])
+		adapterInfo.SerialNum = charger.ChargerSerialNumber.String()
+		if adapterInfo.SerialNum == "" {
+			adapterInfo.SerialNum = charger.ChargerID.String()
 		}
 
 		// 尝试获取充电器名称
-		nameRegex := regexp.MustCompile(`(?:Name|Family): (.+)`)
-		nameMatches := nameRegex.FindStringSubmatch(powerOutput)
-		if len(nameMatches) > 1 {
-			adapterInfo.Name = strings.TrimSpace(nameMatches[1])
+		adapterInfo.Name = charger.ChargerName.String()
+		if adapterInfo.Name == "" {
+			adapterInfo.Name = charger.ChargerFamily.String()
+		}
 
-			// 尝试从名称中提取功率
+		// 获取功率，没有时尝试从名称中提取
+		adapterInfo.Wattage = charger.ChargerWatts.Int()
+		if adapterInfo.Wattage == 0 {
 			wattageRegex := regexp.MustCompile(`(\d+)W`)
-			wattageMatches := wattageRegex.FindStringSubmatch(adapterInfo.Name)
-			if len(wattageMatches) > 1 {
-				wattage, _ := strconv.Atoi(wattageMatches[1])
-				adapterInfo.Wattage = wattage
-			} else {
-				// 尝试从其他字段获取功率
-				wattageRegex = regexp.MustCompile(`(?:Wattage|Adapter Wattage): (\d+)W`)
-				wattageMatches = wattageRegex.FindStringSubmatch(powerOutput)
-				if len(wattageMatches) > 1 {
-					wattage, _ := strconv.Atoi(wattageMatches[1])
-					adapterInfo.Wattage = wattage
-				}
+			if matches := wattageRegex.FindStringSubmatch(adapterInfo.Name); len(matches) > 1 {
+				adapterInfo.Wattage, _ = strconv.Atoi(matches[1])
 			}
 		}
 
 		// 尝试获取充电器芯片型号
-		chipRegex := regexp.MustCompile(`(?:Manufacturer|Manufacturer ID|Vendor): (.+)`)
-		chipMatches := chipRegex.FindStringSubmatch(powerOutput)
-		if len(chipMatches) > 1 {
-			adapterInfo.ChipModel = strings.TrimSpace(chipMatches[1])
+		adapterInfo.ChipModel = charger.ChargerManufacturer.String()
+
+		// 从AppleSmartBattery的AdapterDetails中获取USB-C PD协商的电压、电流和功率
+		if smart, _, err := getSmartBattery(ctx); err != nil {
+			log.Printf("Error getting adapter details: %v", err)
+		} else {
+			details := smart.AdapterDetails
+			adapterInfo.NegotiatedWattage = float64(details.Watts)
+			adapterInfo.NegotiatedVoltage = float64(details.AdapterVoltage) / 1000
+			adapterInfo.NegotiatedCurrent = float64(details.Current) / 1000
 		}
 	}
 
+	adapterInfo.RatedWattage = analysis.RatedWattage(info.ModelID)
+	analysis.EvaluateCharging(&adapterInfo)
+
 	info.ACAdapter = adapterInfo
 	return nil
 }
 
 // getBluetoothInfo 获取蓝牙信息
-func getBluetoothInfo(info *model.SystemInfo) error {
+func getBluetoothInfo(ctx context.Context, info *model.SystemInfo, sp *systemProfiler) error {
 	// 使用system_profiler获取蓝牙信息
-	output, err := runCommand("system_profiler", "SPBluetoothDataType")
+	report, err := sp.Report(ctx)
 	if err != nil {
 		return err
 	}
 
 	// 解析蓝牙状态
 	bluetoothInfo := model.BluetoothInfo{}
-	bluetoothInfo.Enabled = strings.Contains(output, "State: On") || strings.Contains(output, "Bluetooth: On")
-	bluetoothInfo.Status = "关闭"
-	if bluetoothInfo.Enabled {
-		bluetoothInfo.Status = "打开"
-	}
-
-	// 解析已连接设备
 	var connectedDevices []model.BTDeviceInfo
+	for _, bluetooth := range report.Bluetooth {
+		if bluetooth.Controller.State.Bool() || bluetooth.LocalDevice.Power.Bool() {
+			bluetoothInfo.Enabled = true
+		}
 
-	// 使用正则表达式匹配连接的设备
-	deviceRegex := regexp.MustCompile(`(?s)Connected: Yes.*?Address: ([0-9a-fA-F:]+).*?Name: ([^\n]+)`)
-	deviceMatches := deviceRegex.FindAllStringSubmatch(output, -1)
-
-	for _, match := range deviceMatches {
-		if len(match) > 2 {
-			device := model.BTDeviceInfo{
-				Address:   match[1],
-				Name:      strings.TrimSpace(match[2]),
-				Connected: true,
-			}
-
-			// 尝试确定设备类型
-			deviceName := strings.ToLower(device.Name)
-			if strings.Contains(deviceName, "keyboard") {
-				device.Type = "键盘"
-			} else if strings.Contains(deviceName, "mouse") || strings.Contains(deviceName, "trackpad") {
-				device.Type = "鼠标/触控板"
-			} else if strings.Contains(deviceName, "airpods") || strings.Contains(deviceName, "headphone") || strings.Contains(deviceName, "earphone") {
-				device.Type = "耳机"
-			} else if strings.Contains(deviceName, "speaker") {
-				device.Type = "扬声器"
-			} else {
-				device.Type = "其他"
+		// macOS 12起已连接的设备在device_connected中，更早的版本在device_title中按device_isconnected区分
+		for _, devices := range bluetooth.Connected {
+			for name, device := range devices {
+				connectedDevices = append(connectedDevices, bluetoothDevice(name, device))
 			}
-
-			connectedDevices = append(connectedDevices, device)
 		}
-	}
-
-	// 如果没有找到连接的设备，尝试使用另一种方式解析
-	if len(connectedDevices) == 0 {
-		// 尝试提取设备名称
-		deviceNameRegex := regexp.MustCompile(`Connected:\s+Yes[\s\S]*?^\s*([^:]+):\s*$`)
-		deviceNameMatches := deviceNameRegex.FindAllStringSubmatch(output, -1)
-
-		for _, match := range deviceNameMatches {
-			if len(match) > 1 {
-				deviceName := strings.TrimSpace(match[1])
-				if deviceName != "" {
-					device := model.BTDeviceInfo{
-						Name:      deviceName,
-						Connected: true,
-					}
-
-					// 尝试确定设备类型
-					lowerName := strings.ToLower(deviceName)
-					if strings.Contains(lowerName, "keyboard") {
-						device.Type = "键盘"
-					} else if strings.Contains(lowerName, "mouse") || strings.Contains(lowerName, "trackpad") {
-						device.Type = "鼠标/触控板"
-					} else if strings.Contains(lowerName, "airpods") || strings.Contains(lowerName, "headphone") || strings.Contains(lowerName, "earphone") {
-						device.Type = "耳机"
-					} else if strings.Contains(lowerName, "speaker") {
-						device.Type = "扬声器"
-					} else {
-						device.Type = "其他"
-					}
-
-					connectedDevices = append(connectedDevices, device)
+		for _, devices := range bluetooth.Devices {
+			for name, device := range devices {
+				if device.IsConnected.Bool() {
+					connectedDevices = append(connectedDevices, bluetoothDevice(name, device))
 				}
 			}
 		}
 	}
+	bluetoothInfo.Status = "关闭"
+	if bluetoothInfo.Enabled {
+		bluetoothInfo.Status = "打开"
+	}
 
 	bluetoothInfo.Devices = connectedDevices
 	info.Bluetooth = bluetoothInfo
 	return nil
 }
 
+// bluetoothDevice 根据设备名称和类型确定已连接蓝牙设备的类型
+func bluetoothDevice(name string, device spBluetoothDevice) model.BTDeviceInfo {
+	btDevice := model.BTDeviceInfo{
+		Name:      strings.TrimSpace(name),
+		Address:   device.Address.String(),
+		Connected: true,
+	}
+
+	description := strings.ToLower(name + " " + device.MinorType.String())
+	switch {
+	case strings.Contains(description, "keyboard"):
+		btDevice.Type = "键盘"
+	case strings.Contains(description, "mouse") || strings.Contains(description, "trackpad"):
+		btDevice.Type = "鼠标/触控板"
+	case strings.Contains(description, "airpods") || strings.Contains(description, "headphone") || strings.Contains(description, "earphone"):
+		btDevice.Type = "耳机"
+	case strings.Contains(description, "speaker"):
+		btDevice.Type = "扬声器"
+	default:
+		btDevice.Type = "其他"
+	}
+	return btDevice
+}
+
 // getTemperatureInfo 获取设备温度信息
-func getTemperatureInfo(info *model.SystemInfo) error {
+func getTemperatureInfo(ctx context.Context, info *model.SystemInfo) error {
 	// 检测是否为Apple Silicon芯片
 	isAppleSilicon := false
-	cmd := exec.Command("sysctl", "machdep.cpu.brand_string")
+	cmd := exec.CommandContext(ctx, "sysctl", "machdep.cpu.brand_string")
 	output, err := cmd.Output()
 	if err == nil {
 		outputStr := string(output)
@@ -354,17 +331,17 @@ func getTemperatureInfo(info *model.SystemInfo) error {
 	// 根据芯片类型使用不同的温度获取方法
 	if isAppleSilicon {
 		// Apple Silicon芯片的温度获取方法
-		return getAppleSiliconTemperature(info)
+		return getAppleSiliconTemperature(ctx, info)
 	} else {
 		// Intel芯片的温度获取方法
-		return getIntelTemperature(info)
+		return getIntelTemperature(ctx, info)
 	}
 }
 
 // getAppleSiliconTemperature 获取Apple Silicon设备的温度信息
-func getAppleSiliconTemperature(info *model.SystemInfo) error {
+func getAppleSiliconTemperature(ctx context.Context, info *model.SystemInfo) error {
 	// 使用sysctl命令获取温度信息
-	cmd := exec.Command("sysctl", "-a")
+	cmd := exec.CommandContext(ctx, "sysctl", "-a")
 	output, err := cmd.Output()
 	if err != nil {
 		log.Printf("获取温度信息失败: %v", err)
@@ -413,21 +390,21 @@ func getAppleSiliconTemperature(info *model.SystemInfo) error {
 }
 
 // getIntelTemperature 获取Intel Mac设备的温度信息
-func getIntelTemperature(info *model.SystemInfo) error {
+func getIntelTemperature(ctx context.Context, info *model.SystemInfo) error {
 	// 尝试使用iStats命令获取温度信息
 	// 首先检查是否已安装iStats
 	_, err := exec.LookPath("istats")
 	if err != nil {
 		// iStats未安装，使用备用方法
-		return getIntelTemperatureBackup(info)
+		return getIntelTemperatureBackup(ctx, info)
 	}
 
 	// 使用iStats获取温度信息
-	cmd := exec.Command("istats")
+	cmd := exec.CommandContext(ctx, "istats")
 	output, err := cmd.Output()
 	if err != nil {
 		log.Printf("使用iStats获取温度信息失败: %v", err)
-		return getIntelTemperatureBackup(info)
+		return getIntelTemperatureBackup(ctx, info)
 	}
 
 	outputStr := string(output)
@@ -475,7 +452,7 @@ func getIntelTemperature(info *model.SystemInfo) error {
 }
 
 // getIntelTemperatureBackup 获取Intel Mac设备的温度信息的备用方法
-func getIntelTemperatureBackup(info *model.SystemInfo) error {
+func getIntelTemperatureBackup(ctx context.Context, info *model.SystemInfo) error {
 	// 使用osx-cpu-temp命令获取CPU温度
 	_, err := exec.LookPath("osx-cpu-temp")
 	if err != nil {
@@ -493,7 +470,7 @@ func getIntelTemperatureBackup(info *model.SystemInfo) error {
 		return nil
 	}
 
-	cmd := exec.Command("osx-cpu-temp")
+	cmd := exec.CommandContext(ctx, "osx-cpu-temp")
 	output, err := cmd.Output()
 	if err != nil {
 		log.Printf("使用osx-cpu-temp获取温度信息失败: %v", err)
@@ -0,0 +1,27 @@
+package darwin
+
+import (
+	"context"
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// getGatewayInfo 获取IPv4默认网关，并对比网关和互联网的延迟与丢包
+func getGatewayInfo(ctx context.Context, info *model.NetworkInfo) error {
+	gateway := model.GatewayInfo{}
+
+	// 没有默认路由时route命令返回错误
+	output, err := runCommand(ctx, "route", "-n", "get", "default")
+	if err == nil {
+		if matches := routeGatewayRegex.FindStringSubmatch(output); len(matches) > 1 {
+			gateway.Address = matches[1]
+		}
+		if matches := routeInterfaceRegex.FindStringSubmatch(output); len(matches) > 1 {
+			gateway.Interface = matches[1]
+		}
+	}
+
+	analysis.AssessGateway(&gateway)
+	info.Gateway = gateway
+	return nil
+}
@@ -0,0 +1,318 @@
+package darwin
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// getInputDevices 获取内置和外接输入设备信息
+func getInputDevices(ctx context.Context, info *model.SystemInfo) error {
+	// 使用ioreg列出所有IOHIDDevice及其属性
+	output, err := runCommand(ctx, "ioreg", "-r", "-c", "IOHIDDevice", "-d", "1")
+	if err != nil {
+		return err
+	}
+
+	productRegex := regexp.MustCompile(`"Product" = "([^"]+)"`)
+	transportRegex := regexp.MustCompile(`"Transport" = "([^"]+)"`)
+	manufacturerRegex := regexp.MustCompile(`"Manufacturer" = "([^"]+)"`)
+	usagePageRegex := regexp.MustCompile(`"PrimaryUsagePage" = (\d+)`)
+	usageRegex := regexp.MustCompile(`"PrimaryUsage" = (\d+)`)
+
+	inputDevices := model.InputDevicesInfo{}
+	seen := map[string]bool{}
+
+	// 每个设备以"+-o"开头
+	for _, block := range strings.Split(output, "+-o ")[1:] {
+		usagePage, usage := 0, 0
+		if matches := usagePageRegex.FindStringSubmatch(block); len(matches) > 1 {
+			usagePage, _ = strconv.Atoi(matches[1])
+		}
+		if matches := usageRegex.FindStringSubmatch(block); len(matches) > 1 {
+			usage, _ = strconv.Atoi(matches[1])
+		}
+
+		deviceType := hidDeviceType(usagePage, usage)
+		if deviceType == "" {
+			continue
+		}
+
+		device := model.InputDeviceInfo{
+			Type:    deviceType,
+			BuiltIn: strings.Contains(block, `"Built-In" = Yes`),
+			Status:  "未激活",
+		}
+		if matches := productRegex.FindStringSubmatch(block); len(matches) > 1 {
+			device.Name = matches[1]
+		}
+		if matches := transportRegex.FindStringSubmatch(block); len(matches) > 1 {
+			device.Transport = matches[1]
+		}
+		if matches := manufacturerRegex.FindStringSubmatch(block); len(matches) > 1 {
+			device.Vendor = matches[1]
+		}
+
+		// 类描述行中的active表示驱动已匹配并处于活动状态
+		firstLine := strings.SplitN(block, "\n", 2)[0]
+		if strings.Contains(firstLine, "active") {
+			device.Status = "正常"
+		}
+
+		// 内置键盘/触控板在SPI或FIFO总线上
+		if device.Transport == "SPI" || device.Transport == "FIFO" {
+			device.BuiltIn = true
+		}
+
+		// 同一设备可能暴露多个HID接口，按名称和类型去重
+		key := device.Name + "|" + device.Type
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if device.BuiltIn {
+			inputDevices.BuiltIn = append(inputDevices.BuiltIn, device)
+		} else {
+			inputDevices.External = append(inputDevices.External, device)
+		}
+	}
+
+	info.InputDevices = inputDevices
+	return nil
+}
+
+// hidDeviceType 根据HID Usage Page和Usage确定输入设备类型
+func hidDeviceType(usagePage, usage int) string {
+	switch {
+	case usagePage == 1 && usage == 6:
+		return "键盘"
+	case usagePage == 1 && usage == 2:
+		return "鼠标"
+	case usagePage == 13 && usage == 5:
+		return "触控板"
+	case usagePage == 13 && usage == 4:
+		return "触摸屏"
+	}
+	return ""
+}
+
+// getSecurityHardware 获取T2/Secure Enclave、Touch ID和启动安全模式信息
+func getSecurityHardware(ctx context.Context, info *model.SystemInfo, sp *systemProfiler) error {
+	securityInfo := model.SecurityHardwareInfo{}
+
+	// Apple Silicon芯片内置Secure Enclave
+	archOutput, err := runCommand(ctx, "sysctl", "-n", "hw.machine")
+	isAppleSilicon := err == nil && strings.TrimSpace(archOutput) == "arm64"
+	if isAppleSilicon {
+		securityInfo.SecurityChip = "Apple Silicon"
+		securityInfo.HasSecureEnclave = true
+	} else {
+		// Intel Mac通过SPiBridgeDataType检测T2芯片
+		if report, err := sp.Report(ctx); err == nil && len(report.Bridge) > 0 {
+			securityInfo.SecurityChip = report.Bridge[0].ModelName.String()
+			securityInfo.HasSecureEnclave = strings.Contains(securityInfo.SecurityChip, "T2")
+		}
+	}
+
+	// 检测Touch ID传感器
+	sensorOutput, err := runCommand(ctx, "ioreg", "-r", "-c", "AppleBiometricSensor", "-d", "1")
+	securityInfo.TouchIDPresent = err == nil && strings.TrimSpace(sensorOutput) != ""
+
+	// 获取当前用户已录入的指纹数量，例如"User 501: 2 biometric template(s)"
+	if securityInfo.TouchIDPresent {
+		enrolledOutput, err := runCommand(ctx, "bioutil", "-c")
+		if err == nil {
+			re := regexp.MustCompile(`(\d+) biometric template`)
+			if matches := re.FindStringSubmatch(enrolledOutput); len(matches) > 1 {
+				securityInfo.TouchIDEnrolled, _ = strconv.Atoi(matches[1])
+			}
+		}
+	}
+
+	// Apple Silicon的启动安全模式，bputil需要管理员权限，失败时保持为空
+	if isAppleSilicon {
+		policyOutput, err := runCommand(ctx, "bputil", "-d")
+		if err == nil {
+			securityInfo.SecurityMode = parseSecurityMode(policyOutput)
+		}
+	}
+
+	info.SecurityHardware = securityInfo
+	return nil
+}
+
+// parseSecurityMode 解析bputil -d输出中的安全模式
+// smb0未设置表示完整安全性，smb3设置表示宽松安全性，否则为降低安全性
+func parseSecurityMode(output string) string {
+	smb0Regex := regexp.MustCompile(`\(smb0\):\s*(\S+)`)
+	smb3Regex := regexp.MustCompile(`\(smb3\):\s*(\S+)`)
+
+	smb0 := smb0Regex.FindStringSubmatch(output)
+	if len(smb0) < 2 {
+		return ""
+	}
+	if smb0[1] == "absent" {
+		return "Full Security"
+	}
+	if smb3 := smb3Regex.FindStringSubmatch(output); len(smb3) > 1 && smb3[1] != "absent" {
+		return "Permissive Security"
+	}
+	return "Reduced Security"
+}
+
+// parseFirmwareInfo 从SPHardwareDataType的硬件概览中解析固件、SMC和OS Loader版本，
+// 从SPiBridgeDataType中解析T2机型的iBridge固件版本
+func parseFirmwareInfo(info *model.SystemInfo, hardware spHardware, bridge []spBridge) {
+	firmware := model.FirmwareInfo{
+		Vendor:          "Apple",
+		Mode:            "UEFI",
+		Version:         hardware.BootROMVersion.String(),
+		SMCVersion:      hardware.SMCVersion.String(), // Apple Silicon上不存在
+		OSLoaderVersion: hardware.OSLoaderVersion.String(),
+	}
+
+	// Apple Silicon输出System Firmware Version和OS Loader Version，Intel Mac的同一字段为Boot ROM版本
+	if firmware.OSLoaderVersion == "" {
+		firmware.BootROMVersion = firmware.Version
+	}
+
+	// T2机型的iBridge固件版本在SPiBridgeDataType中
+	if len(bridge) > 0 {
+		firmware.BridgeFirmware = bridge[0].Build.String()
+	}
+
+	info.Firmware = firmware
+}
+
+// parseMemoryModules 从SPMemoryDataType的条目中解析各插槽内存条信息和是否可升级
+func parseMemoryModules(memory spMemory) ([]model.MemoryModule, bool) {
+	var modules []model.MemoryModule
+	for _, dimm := range memory.Items {
+		modules = append(modules, model.MemoryModule{
+			Slot: dimm.Slot,
+			Size: parseMemorySize(dimm.Size.String()),
+			// 例如"2667 MHz"，system_profiler以MHz标注实际为MT/s
+			Speed:        dimm.Speed.Int(),
+			Type:         dimm.Type.String(),
+			Manufacturer: dimm.Manufacturer.String(),
+			PartNumber:   dimm.PartNumber.String(),
+		})
+	}
+
+	// Apple Silicon没有插槽信息，使用顶层字段描述板载内存
+	if len(modules) == 0 {
+		if size := parseMemorySize(memory.Size.String()); size > 0 {
+			modules = append(modules, model.MemoryModule{
+				Slot:         "Onboard",
+				Size:         size,
+				Type:         memory.Type.String(),
+				Manufacturer: memory.Manufacturer.String(),
+			})
+		}
+	}
+
+	return modules, memory.Upgradeable.Bool()
+}
+
+// parseMemorySize 将"8 GB"、"512 MB"等容量描述转换为字节数
+func parseMemorySize(value string) uint64 {
+	fields := strings.Fields(value)
+	if len(fields) < 2 {
+		return 0
+	}
+
+	size, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	switch strings.ToUpper(fields[1]) {
+	case "TB":
+		return size * 1024 * 1024 * 1024 * 1024
+	case "GB":
+		return size * 1024 * 1024 * 1024
+	case "MB":
+		return size * 1024 * 1024
+	}
+	return size
+}
+
+// getDiskEncryption 获取启动盘的硬件加密能力和FileVault状态
+func getDiskEncryption(ctx context.Context, info *model.SystemInfo) error {
+	encryption := model.DiskEncryptionInfo{SoftwareName: "FileVault"}
+
+	// T2和Apple Silicon机型的内置SSD始终由Secure Enclave的AES引擎进行硬件加密
+	if info.SecurityHardware.HasSecureEnclave {
+		encryption.HardwareEncrypted = true
+		encryption.HardwareMethod = info.SecurityHardware.SecurityChip + " inline AES"
+	}
+
+	output, err := runCommand(ctx, "fdesetup", "status")
+	if err != nil {
+		info.DiskEncryption = encryption
+		return err
+	}
+
+	encryption.SoftwareStatus = strings.TrimSpace(strings.Split(output, "\n")[0])
+	encryption.SoftwareEnabled = strings.Contains(output, "FileVault is On")
+
+	info.DiskEncryption = encryption
+	return nil
+}
+
+// getPCIDevices 从SPPCIDataType获取PCIe/雷雳设备列表
+func getPCIDevices(ctx context.Context, info *model.SystemInfo, sp *systemProfiler) error {
+	report, err := sp.Report(ctx)
+	if err != nil {
+		return err
+	}
+
+	var devices []model.PCIDeviceInfo
+	for _, item := range report.PCI {
+		devices = append(devices, model.PCIDeviceInfo{
+			Name:      item.Name,
+			Type:      item.Type.String(),
+			VendorID:  item.VendorID.String(),
+			DeviceID:  item.DeviceID.String(),
+			Slot:      item.Slot.String(),
+			LinkWidth: item.LinkWidth.String(),
+			LinkSpeed: item.LinkSpeed.String(),
+			Driver:    item.DriverInstalled.Bool(),
+		})
+	}
+
+	info.PCIDevices = devices
+	return nil
+}
+
+// getDisplayInfo 获取内置显示器亮度和自动亮度设置
+func getDisplayInfo(ctx context.Context, info *model.SystemInfo) error {
+	display := model.DisplayInfo{}
+
+	// 内置显示器的IODisplayParameters中包含亮度的最小值、最大值和当前值
+	output, err := runCommand(ctx, "ioreg", "-r", "-k", "IODisplayParameters", "-d", "1")
+	if err == nil {
+		brightnessRegex := regexp.MustCompile(`"brightness"=\{"min"=(\d+),"max"=(\d+),"value"=(\d+)\}`)
+		if matches := brightnessRegex.FindStringSubmatch(output); len(matches) > 3 {
+			min, _ := strconv.ParseFloat(matches[1], 64)
+			max, _ := strconv.ParseFloat(matches[2], 64)
+			value, _ := strconv.ParseFloat(matches[3], 64)
+			if max > min {
+				display.Brightness = (value - min) / (max - min) * 100
+			}
+		}
+	}
+
+	// 自动调节亮度设置
+	autoOutput, err := runCommand(ctx, "defaults", "read", "/Library/Preferences/com.apple.iokit.AmbientLightSensor", "Automatic Display Enabled")
+	if err == nil {
+		display.AutoBrightness = strings.TrimSpace(autoOutput) == "1"
+	}
+
+	info.Display = display
+	return nil
+}
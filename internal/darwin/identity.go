@@ -0,0 +1,30 @@
+package darwin
+
+import (
+	"context"
+	"os"
+)
+
+// systemKeychain LaunchDaemon使用的系统钥匙串
+const systemKeychain = "/Library/Keychains/System.keychain"
+
+// ExportIdentities 从钥匙串导出所有身份（证书和私钥），返回以password加密的PKCS#12数据
+// root用户（LaunchDaemon）从系统钥匙串导出，其他用户从默认钥匙串导出；私钥的访问控制不允许本程序时系统会要求用户授权
+func ExportIdentities(password string) ([]byte, error) {
+	file, err := os.CreateTemp("", "sysspector-*.p12")
+	if err != nil {
+		return nil, err
+	}
+	path := file.Name()
+	file.Close()
+	defer os.Remove(path)
+
+	args := []string{"export", "-t", "identities", "-f", "pkcs12", "-P", password, "-o", path}
+	if os.Geteuid() == 0 {
+		args = append(args, "-k", systemKeychain)
+	}
+	if _, err := runCommand(context.Background(), "security", args...); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
@@ -0,0 +1,35 @@
+package darwin
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+var (
+	routeGatewayRegex   = regexp.MustCompile(`gateway: (\S+)`)
+	routeInterfaceRegex = regexp.MustCompile(`interface: (\S+)`)
+)
+
+// getIPv6Info 获取IPv6默认路由，并评估IPv6地址和出口连通性
+func getIPv6Info(ctx context.Context, info *model.NetworkInfo) error {
+	ipv6 := model.IPv6Info{}
+
+	// 没有IPv6默认路由时route命令返回错误
+	output, err := runCommand(ctx, "route", "-n", "get", "-inet6", "default")
+	if err == nil {
+		if matches := routeGatewayRegex.FindStringSubmatch(output); len(matches) > 1 {
+			ipv6.DefaultRoute = true
+			ipv6.Gateway = matches[1]
+		}
+		if matches := routeInterfaceRegex.FindStringSubmatch(output); len(matches) > 1 {
+			ipv6.Interface = matches[1]
+		}
+	}
+
+	analysis.AssessIPv6(&ipv6)
+	info.IPv6 = ipv6
+	return nil
+}
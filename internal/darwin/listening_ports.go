@@ -0,0 +1,55 @@
+package darwin
+
+import (
+	"context"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/internal/sockets"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+var codesignAuthorityRegex = regexp.MustCompile(`(?m)^Authority=(.+)$`)
+
+// getListeningPorts 获取监听中的端口及其所属进程，并读取可执行文件的代码签名
+func getListeningPorts(ctx context.Context, info *model.NetworkInfo) error {
+	ports, err := sockets.Listening()
+	if err != nil {
+		return err
+	}
+
+	// 多个端口可能属于同一个程序，每个程序只检查一次签名
+	signers := make(map[string]string)
+	for i := range ports {
+		path := ports[i].Path
+		if path == "" {
+			continue
+		}
+		signer, ok := signers[path]
+		if !ok {
+			signer = codeSigner(ctx, path)
+			signers[path] = signer
+		}
+		ports[i].Signer = signer
+	}
+
+	info.ListeningPorts = ports
+	return nil
+}
+
+// codeSigner 返回可执行文件签名证书链中的第一个证书名称
+func codeSigner(ctx context.Context, path string) string {
+	// codesign将签名信息输出到标准错误
+	output, err := exec.CommandContext(ctx, "codesign", "-dv", "--verbose=2", path).CombinedOutput()
+	if err != nil {
+		return "未签名"
+	}
+	if matches := codesignAuthorityRegex.FindStringSubmatch(string(output)); len(matches) > 1 {
+		return matches[1]
+	}
+	if strings.Contains(string(output), "Signature=adhoc") {
+		return "临时签名"
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package darwin
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+	"howett.net/plist"
+)
+
+// hiToolboxPrefs 表示com.apple.HIToolbox中已启用的输入源
+type hiToolboxPrefs struct {
+	EnabledInputSources []struct {
+		KeyboardLayoutName string `plist:"KeyboardLayout Name"`
+		BundleID           string `plist:"Bundle ID"`
+		InputMode          string `plist:"Input Mode"`
+	} `plist:"AppleEnabledInputSources"`
+}
+
+// getLocaleInfo 获取系统时区、区域设置、首选语言和键盘输入源
+func getLocaleInfo(ctx context.Context, info *model.SystemInfo) error {
+	locale := &info.Locale
+	locale.UTCOffset = time.Now().Format("-07:00")
+
+	// /etc/localtime指向/var/db/timezone/zoneinfo/<时区>
+	if target, err := os.Readlink("/etc/localtime"); err == nil {
+		if idx := strings.Index(target, "zoneinfo/"); idx >= 0 {
+			locale.TimeZone = target[idx+len("zoneinfo/"):]
+		}
+	}
+
+	if output, err := runCommand(ctx, "defaults", "read", "-g", "AppleLocale"); err == nil {
+		locale.Locale = strings.TrimSpace(output)
+	}
+
+	if output, err := runCommand(ctx, "defaults", "export", "-g", "-"); err == nil {
+		var global struct {
+			AppleLanguages []string `plist:"AppleLanguages"`
+		}
+		if _, err := plist.Unmarshal([]byte(output), &global); err == nil {
+			locale.Languages = global.AppleLanguages
+		}
+	}
+
+	output, err := runCommand(ctx, "defaults", "export", "com.apple.HIToolbox", "-")
+	if err != nil {
+		return err
+	}
+	var prefs hiToolboxPrefs
+	if _, err := plist.Unmarshal([]byte(output), &prefs); err != nil {
+		return err
+	}
+	for _, source := range prefs.EnabledInputSources {
+		switch {
+		case source.KeyboardLayoutName != "":
+			locale.InputSources = append(locale.InputSources, source.KeyboardLayoutName)
+		case source.InputMode != "":
+			locale.InputSources = append(locale.InputSources, source.InputMode)
+		case source.BundleID != "" && !strings.HasPrefix(source.BundleID, "com.apple.CharacterPaletteIM") && !strings.HasPrefix(source.BundleID, "com.apple.PressAndHold"):
+			// 跳过字符检视器等系统辅助输入源
+			locale.InputSources = append(locale.InputSources, source.BundleID)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package darwin
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+	"howett.net/plist"
+)
+
+// installedProfile 表示profiles show -output stdout-xml中的配置描述文件
+type installedProfile struct {
+	DisplayName  string `plist:"ProfileDisplayName"`
+	Identifier   string `plist:"ProfileIdentifier"`
+	Organization string `plist:"ProfileOrganization"`
+	Items        []struct {
+		PayloadType string `plist:"PayloadType"`
+	} `plist:"ProfileItems"`
+}
+
+// getManagement 获取MDM注册状态和已安装的配置描述文件
+func getManagement(ctx context.Context, info *model.SystemInfo) error {
+	// 输出格式为：MDM enrollment: Yes (User Approved)
+	output, err := runCommand(ctx, "profiles", "status", "-type", "enrollment")
+	if err != nil {
+		return err
+	}
+
+	if matches := regexp.MustCompile(`MDM enrollment:\s*(.+)`).FindStringSubmatch(output); len(matches) > 1 {
+		enrollment := strings.TrimSpace(matches[1])
+		info.Management.Enrolled = strings.HasPrefix(enrollment, "Yes")
+		if info.Management.Enrolled {
+			info.Management.Provider = enrollment
+			if strings.Contains(output, "Enrolled via DEP: Yes") {
+				info.Management.Provider += "，通过自动设备注册"
+			}
+		}
+	}
+	if matches := regexp.MustCompile(`MDM server:\s*(.+)`).FindStringSubmatch(output); len(matches) > 1 {
+		info.Management.ServerURL = strings.TrimSpace(matches[1])
+	}
+
+	// 列出所有配置描述文件需要root权限，键为_computerlevel或用户名
+	profilesOutput, err := runCommand(ctx, "profiles", "show", "-output", "stdout-xml")
+	if err != nil {
+		return err
+	}
+	var profiles map[string][]installedProfile
+	if _, err := plist.Unmarshal([]byte(profilesOutput), &profiles); err != nil {
+		return err
+	}
+
+	for scope, scopeProfiles := range profiles {
+		if scope == "_computerlevel" {
+			scope = "设备"
+		}
+		for _, profile := range scopeProfiles {
+			configProfile := model.ConfigProfileInfo{
+				Name:         profile.DisplayName,
+				Identifier:   profile.Identifier,
+				Organization: profile.Organization,
+				Scope:        scope,
+			}
+			for _, item := range profile.Items {
+				configProfile.PayloadTypes = append(configProfile.PayloadTypes, item.PayloadType)
+			}
+			info.Management.Profiles = append(info.Management.Profiles, configProfile)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package darwin
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/AsterZephyr/SysSpector/internal/lldp"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// CaptureNeighbors 在所有已连接的有线网络接口上被动抓取LLDP/CDP通告，获取所连接的交换机名称、端口和VLAN
+// 使用tcpdump抓包，需要root权限；每个接口最多等待一个LLDP通告间隔，只在使用--scan-switch参数时调用
+func CaptureNeighbors(ctx context.Context, info *model.NetworkInfo) error {
+	interfaces, err := wiredInterfaces(ctx)
+	if err != nil {
+		return err
+	}
+	if len(interfaces) == 0 {
+		return fmt.Errorf("no active wired interface")
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var lastErr error
+	for _, name := range interfaces {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			neighbors, err := captureInterface(ctx, name)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				lastErr = err
+			}
+			for _, neighbor := range neighbors {
+				neighbor.Interface = name
+				info.Neighbors = append(info.Neighbors, neighbor)
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	if len(info.Neighbors) == 0 {
+		return lastErr
+	}
+	return nil
+}
+
+// captureInterface 在一个接口上抓取第一个LLDP或CDP报文，超时后终止tcpdump并解析已经抓到的数据
+func captureInterface(ctx context.Context, name string) ([]model.NeighborInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, lldp.CaptureDuration)
+	defer cancel()
+
+	// -U使每个数据包立即写出，tcpdump被终止时不会丢失已抓到的报文
+	cmd := exec.CommandContext(ctx, "tcpdump", "-i", name, "-U", "-s", "0", "-c", "1", "-w", "-", lldp.CaptureFilter)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil && ctx.Err() == nil {
+		return nil, fmt.Errorf("tcpdump on %s failed: %v: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return lldp.ParseCapture(output)
+}
+
+// wiredInterfaces 从networksetup -listallhardwareports中找出已连接的有线网络接口，例如：
+//
+//	Hardware Port: USB 10/100/1000 LAN
+//	Device: en7
+//	Ethernet Address: 00:e0:4c:68:01:02
+func wiredInterfaces(ctx context.Context) ([]string, error) {
+	output, err := runCommand(ctx, "networksetup", "-listallhardwareports")
+	if err != nil {
+		return nil, err
+	}
+
+	var interfaces []string
+	var port string
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "Hardware Port":
+			port = value
+		case "Device":
+			if !strings.Contains(port, "Ethernet") && !strings.Contains(port, "LAN") {
+				continue
+			}
+			// ifconfig输出中的"status: active"表示网线已连接
+			if status, err := runCommand(ctx, "ifconfig", value); err == nil && strings.Contains(status, "status: active") {
+				interfaces = append(interfaces, value)
+			}
+		}
+	}
+	return interfaces, nil
+}
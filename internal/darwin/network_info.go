@@ -2,253 +2,217 @@ package darwin
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"log"
 	"net/http"
 	"os"
 	"regexp"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/internal/collect"
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/ifaces"
+	"github.com/AsterZephyr/SysSpector/internal/netprobe"
 	"github.com/AsterZephyr/SysSpector/pkg/model"
 )
 
-// GetNetworkInfo 收集macOS系统的网络信息
+// GetNetworkInfo 并发收集macOS系统的网络信息
 func GetNetworkInfo(info *model.SystemInfo) error {
-	// 初始化网络信息结构
-	networkInfo := model.NetworkInfo{}
-
-	// 获取WiFi信息
-	err := getWiFiInfo(&networkInfo)
-	if err != nil {
-		log.Printf("Error getting WiFi info: %v", err)
-	}
-
-	// 获取客户端IP和MAC地址
-	err = getIPAndMacAddress(&networkInfo)
-	if err != nil {
-		log.Printf("Error getting IP and MAC address: %v", err)
-	}
-
-	// 获取AWDL状态
-	err = getAWDLStatus(&networkInfo)
-	if err != nil {
-		log.Printf("Error getting AWDL status: %v", err)
-	}
-
-	// 获取DNS配置
-	err = getDNSConfig(&networkInfo)
-	if err != nil {
-		log.Printf("Error getting DNS config: %v", err)
-	}
-
-	// 获取公网IP
-	err = getPublicIP(&networkInfo)
-	if err != nil {
-		log.Printf("Error getting public IP: %v", err)
-	}
-
-	// 获取VPN信息
-	err = getVPNInfo(&networkInfo)
-	if err != nil {
-		log.Printf("Error getting VPN info: %v", err)
-	}
-
-	// 获取网络延迟信息
-	err = getNetworkLatency(&networkInfo)
-	if err != nil {
-		log.Printf("Error getting network latency: %v", err)
-	}
-
-	// 获取网络代理状态
-	err = getProxyStatus(&networkInfo)
-	if err != nil {
-		log.Printf("Error getting proxy status: %v", err)
-	}
+	networkInfo := newNetworkInfo()
+	collect.Run(&networkInfo, networkTasks(newSystemProfiler("SPAirPortDataType")))
+	analyzeNetwork(&networkInfo)
+	info.Network = networkInfo
+	return nil
+}
 
-	// 获取客户端路由表
-	err = getRouteTable(&networkInfo)
-	if err != nil {
-		log.Printf("Error getting route table: %v", err)
+// newNetworkInfo 返回采集前的网络信息，延迟探测结果为空列表而不是null
+func newNetworkInfo() model.NetworkInfo {
+	return model.NetworkInfo{
+		Latency: model.LatencyInfo{
+			Targets:     []model.TargetLatencyInfo{},
+			NetworkHops: []model.NetworkHopInfo{},
+		},
 	}
+}
 
-	// 获取hosts文件内容
-	err = getHostsFile(&networkInfo)
-	if err != nil {
-		log.Printf("Error getting hosts file: %v", err)
+// networkTasks 返回网络信息的采集项，互相依赖的步骤放在同一个采集项中
+func networkTasks(sp *systemProfiler) []collect.Task[model.NetworkInfo] {
+	return []collect.Task[model.NetworkInfo]{
+		// WiFi信息和最近的WiFi连接、漫游和断开记录
+		{Name: "wifi", Run: func(ctx context.Context, info *model.NetworkInfo) error { return getWiFiInfo(ctx, info, sp) }},
+		{Name: "wifi_history", Run: getWiFiHistory},
+		// 客户端IP和MAC地址
+		{Name: "addresses", Run: getIPAndMacAddress},
+		{Name: "awdl", Run: getAWDLStatus},
+		// DNS配置，测试各DNS服务器的解析耗时，诊断关键域名的解析结果，检测分区解析和DNS劫持
+		{Name: "dns", Timeout: slowTaskTimeout, Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			if err := getDNSConfig(ctx, info); err != nil {
+				return err
+			}
+			info.DNSBenchmark = analysis.BenchmarkDNS(info.DNS.Servers, config.Current().DNS)
+			info.DNSDiagnostics = analysis.DiagnoseDNS(info.DNS.Servers, config.Current().DNS)
+			return nil
+		}},
+		// HTTP/HTTPS端点和常用SaaS服务的各阶段耗时和证书链，出站端口是否被防火墙拦截，离线模式下不访问外部服务
+		{Name: "endpoints", Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			if !config.Current().Offline {
+				info.EndpointChecks = analysis.CheckEndpoints(config.Current().Endpoint)
+			}
+			return nil
+		}},
+		{Name: "saas", Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			if !config.Current().Offline {
+				info.SaaS = analysis.CheckSaaS(config.Current().SaaS, config.Current().Endpoint.InspectionIssuers)
+			}
+			return nil
+		}},
+		{Name: "ports", Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			if !config.Current().Offline {
+				info.PortMatrix = analysis.CheckPorts(config.Current().Ports)
+			}
+			return nil
+		}},
+		{Name: "public_ip", Run: getPublicIP},
+		// 对比默认网关和互联网的延迟与丢包
+		{Name: "gateway", Run: getGatewayInfo},
+		{Name: "dhcp", Run: getDHCPLeases},
+		// 采样TCP重传和错误计数
+		{Name: "tcp_stats", Run: getTCPStats},
+		// 探测到配置目标的路径MTU，检测VPN等导致的MTU黑洞
+		{Name: "mtu", Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			info.MTU = analysis.DiscoverPathMTU(config.Current().MTU.Target)
+			return nil
+		}},
+		// 通过STUN检测NAT类型
+		{Name: "nat", Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			info.NAT = analysis.DetectNAT(config.Current().STUN.Servers)
+			return nil
+		}},
+		{Name: "ipv6", Run: getIPv6Info},
+		{Name: "vpn", Run: getVPNInfo},
+		// 按配置文件中的探测分组并发测量各目标的延迟，使用内置的路由跟踪获取网络路径信息
+		{Name: "latency", Timeout: slowTaskTimeout, Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			return analysis.MeasureLatency(&info.Latency, config.Current().Latency.Groups)
+		}},
+		{Name: "traceroute", Timeout: slowTaskTimeout, Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			return analysis.TraceRoute(&info.Latency, analysis.TraceTarget)
+		}},
+		{Name: "proxy", Run: getProxyStatus},
+		{Name: "routes", Run: getRouteTable},
+		// 监听中的端口及其所属进程，按进程和远端组织汇总的已建立连接
+		{Name: "listening_ports", Run: getListeningPorts},
+		{Name: "connections", Run: getConnections},
+		{Name: "hosts", Run: getHostsFile},
+		// 网卡流量和各进程的网络流量
+		{Name: "traffic", Run: getNetworkTraffic},
+		{Name: "process_traffic", Run: getProcessTraffic},
+		// 用户当前所在地区代码
+		{Name: "country_code", Run: getCountryCode},
+		// 时间同步状态和时钟偏差
+		{Name: "time_sync", Run: getTimeSync},
 	}
+}
 
-	// 获取网卡流量
-	err = getNetworkTraffic(&networkInfo)
-	if err != nil {
-		log.Printf("Error getting network traffic: %v", err)
-	}
+// analyzeNetwork 在所有网络采集项完成后分析依赖多个采集项的结果
+func analyzeNetwork(info *model.NetworkInfo) {
+	// 检查VPN推送的DNS与其他接口DNS的冲突
+	analysis.CheckDNSResolvers(&info.DNS, info.VPN)
 
-	// 获取用户当前所在地区代码
-	err = getCountryCode(&networkInfo)
-	if err != nil {
-		log.Printf("Error getting country code: %v", err)
+	// VPN连接时分析哪些网段经过隧道
+	if info.VPN.IsConnected {
+		info.VPN.SplitTunnel = analysis.AnalyzeSplitTunnel(info.VPN, splitTunnelRoutes(info.RouteTable), config.Current().VPN.CorporateSubnets)
 	}
+}
 
-	// 将收集到的网络信息设置到系统信息中
-	info.Network = networkInfo
-
-	return nil
+// CurrentWiFi 只读取当前WiFi连接的信息，用于serve模式下定期推送信号强度
+func CurrentWiFi(ctx context.Context) (model.WiFiInfo, error) {
+	var info model.NetworkInfo
+	err := getWiFiInfo(ctx, &info, newSystemProfiler("SPAirPortDataType"))
+	return info.WiFi, err
 }
 
 // getWiFiInfo 获取WiFi信息
-func getWiFiInfo(info *model.NetworkInfo) error {
+func getWiFiInfo(ctx context.Context, info *model.NetworkInfo, sp *systemProfiler) error {
 	// 使用system_profiler获取WiFi信息
-	output, err := runCommand("system_profiler", "SPAirPortDataType")
+	report, err := sp.Report(ctx)
 	if err != nil {
-		// 如果命令执行失败，设置默认值
-		wifiInfo := model.WiFiInfo{
-			SSID:           "Kwai",
-			BSSID:          "cc:dd:ee:ff:gg:hh",
-			IsConnected:    true,
-			SignalStrength: 0,
-			RSSI:           0,
-			Noise:          0,
-			Channel:        0,
-			Frequency:      0.0,
-			PHYMode:        "802.11ac",
-			TxRate:         600,
-			MCS:            0,
-			NSS:            0,
-			CountryCode:    "CN",
-			SupportedPHY:   "802.11a/b/g/n/ac/ax",
-		}
-		info.WiFi = wifiInfo
-		return nil
+		// 读取失败时不填写WiFi信息，避免被当作已连接的网络
+		info.WiFi = model.WiFiInfo{}
+		return err
 	}
 
-	// 解析WiFi信息
-	scanner := bufio.NewScanner(strings.NewReader(output))
+	// 解析WiFi信息，没有当前网络信息时认为WiFi未连接
 	var wifiInfo model.WiFiInfo
-	wifiInfo.IsConnected = false
-
-	inCurrentNetwork := false
-	foundCurrentNetworkSection := false
-	for scanner.Scan() {
-		line := scanner.Text()
-		line = strings.TrimSpace(line)
-
-		// 检查是否进入当前网络信息部分
-		if strings.Contains(line, "Current Network Information:") {
-			inCurrentNetwork = true
-			foundCurrentNetworkSection = true
+	if iface, ok := wifiInterface(report.AirPort); ok {
+		wifiInfo.SupportedPHY = iface.SupportedPHY.String()
+		wifiInfo.CountryCode = iface.CountryCode.String()
+		if network := iface.CurrentNetwork; network != nil && network.SSID != "" {
 			wifiInfo.IsConnected = true
-			continue
-		}
-
-		// 检查是否离开当前网络信息部分
-		if inCurrentNetwork && (strings.Contains(line, "Other Local Wi-Fi Networks:") || line == "") {
-			inCurrentNetwork = false
-			continue
-		}
-
-		// 解析支持的PHY模式
-		if strings.Contains(line, "Supported PHY Modes:") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				wifiInfo.SupportedPHY = strings.TrimSpace(parts[1])
+			wifiInfo.SSID = network.SSID
+			wifiInfo.BSSID = network.BSSID.String()
+			wifiInfo.PHYMode = network.PHYMode.String()
+			wifiInfo.TxRate = network.Rate.Int()
+			wifiInfo.MCS = network.MCS.Int()
+			if network.CountryCode != "" {
+				wifiInfo.CountryCode = network.CountryCode.String()
 			}
-			continue
-		}
 
-		// 解析国家代码
-		if strings.Contains(line, "Country Code:") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				wifiInfo.CountryCode = strings.TrimSpace(parts[1])
-			}
-			continue
-		}
-
-		// 解析当前网络信息
-		if inCurrentNetwork {
-			if strings.HasSuffix(line, ":") {
-				// 这是一个网络名称行
-				wifiInfo.SSID = strings.TrimSuffix(line, ":")
-				continue
+			// 解析频道信息，例如"64 (5GHz, 40MHz)"
+			channel := network.Channel.String()
+			wifiInfo.Channel = network.Channel.Int()
+			if strings.Contains(channel, "5GHz") {
+				wifiInfo.Frequency = 5.0
+			} else if strings.Contains(channel, "2GHz") {
+				wifiInfo.Frequency = 2.4
 			}
-
-			// 解析网络详细信息
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) != 2 {
-				continue
+			_, _, wifiInfo.ChannelWidth = parseAirportChannel(channel)
+
+			// 解析信号和噪声，例如"-53 dBm / -93 dBm"
+			signalNoiseParts := strings.Split(network.SignalNoise.String(), " / ")
+			if len(signalNoiseParts) == 2 {
+				wifiInfo.RSSI = spValue(signalNoiseParts[0]).Int()
+				wifiInfo.Noise = spValue(signalNoiseParts[1]).Int()
+				wifiInfo.SignalStrength = wifiInfo.RSSI
 			}
 
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-
-			switch key {
-			case "PHY Mode":
-				wifiInfo.PHYMode = value
-			case "Channel":
-				// 解析频道信息，例如"64 (5GHz, 40MHz)"
-				channelParts := strings.Split(value, " ")
-				if len(channelParts) > 0 {
-					wifiInfo.Channel, _ = strconv.Atoi(channelParts[0])
-
-					// 解析频率
-					if strings.Contains(value, "5GHz") {
-						wifiInfo.Frequency = 5.0
-					} else if strings.Contains(value, "2GHz") {
-						wifiInfo.Frequency = 2.4
-					}
-				}
-			case "Signal / Noise":
-				// 解析信号和噪声，例如"-53 dBm / -93 dBm"
-				signalNoiseParts := strings.Split(value, " / ")
-				if len(signalNoiseParts) == 2 {
-					signalStr := strings.TrimSuffix(signalNoiseParts[0], " dBm")
-					noiseStr := strings.TrimSuffix(signalNoiseParts[1], " dBm")
-					wifiInfo.RSSI, _ = strconv.Atoi(signalStr)
-					wifiInfo.Noise, _ = strconv.Atoi(noiseStr)
-					wifiInfo.SignalStrength = wifiInfo.RSSI
-				}
-			case "Transmit Rate":
-				wifiInfo.TxRate, _ = strconv.Atoi(value)
-			case "MCS Index":
-				wifiInfo.MCS, _ = strconv.Atoi(value)
-			case "BSSID":
-				wifiInfo.BSSID = value
-			}
+			// 解析安全类型，例如"spairport_security_mode_wpa2_personal"
+			security := strings.TrimPrefix(network.Security.String(), "spairport_security_mode_")
+			wifiInfo.Security = analysis.WiFiSecurity(strings.ReplaceAll(security, "_", " "))
 		}
 	}
 
-	// 如果没有找到当前网络信息部分，则认为WiFi未连接
-	if !foundCurrentNetworkSection {
-		wifiInfo.IsConnected = false
-	}
-
-	// 如果没有获取到SSID，则认为WiFi未连接
-	if wifiInfo.SSID == "" {
-		wifiInfo.IsConnected = false
-	}
+	info.WiFi = wifiInfo
+	return nil
+}
 
-	// 如果没有获取到NSS，不设置默认值
-	if wifiInfo.NSS == 0 {
-		// 不设置默认值
+// wifiInterface 返回有当前网络信息的无线网卡，都没有连接时返回第一个无线网卡，awdl0等虚拟接口没有支持的PHY模式
+func wifiInterface(airports []spAirPort) (spAirPortInterface, bool) {
+	var first *spAirPortInterface
+	for i := range airports {
+		for j := range airports[i].Interfaces {
+			iface := &airports[i].Interfaces[j]
+			if iface.CurrentNetwork != nil {
+				return *iface, true
+			}
+			if first == nil && iface.SupportedPHY != "" {
+				first = iface
+			}
+		}
 	}
-
-	// 如果没有获取到支持的PHY模式，不设置默认值
-	if wifiInfo.SupportedPHY == "" {
-		// 不设置默认值
+	if first == nil {
+		return spAirPortInterface{}, false
 	}
-
-	info.WiFi = wifiInfo
-	return nil
+	return *first, true
 }
 
 // getIPAndMacAddress 获取客户端IP和MAC地址
-func getIPAndMacAddress(info *model.NetworkInfo) error {
+func getIPAndMacAddress(ctx context.Context, info *model.NetworkInfo) error {
 	// 使用ifconfig命令获取网络接口信息
-	output, err := runCommand("ifconfig", "-a")
+	output, err := runCommand(ctx, "ifconfig", "-a")
 	if err != nil {
 		return err
 	}
@@ -278,9 +242,9 @@ func getIPAndMacAddress(info *model.NetworkInfo) error {
 }
 
 // getAWDLStatus 获取AWDL状态
-func getAWDLStatus(info *model.NetworkInfo) error {
+func getAWDLStatus(ctx context.Context, info *model.NetworkInfo) error {
 	// 使用ifconfig awdl0命令获取AWDL状态
-	output, err := runCommand("ifconfig", "awdl0")
+	output, err := runCommand(ctx, "ifconfig", "awdl0")
 	if err != nil {
 		// 如果命令失败，可能是因为AWDL不可用
 		info.AWDLStatus = "active"
@@ -301,7 +265,7 @@ func getAWDLStatus(info *model.NetworkInfo) error {
 }
 
 // getDNSConfig 获取DNS配置
-func getDNSConfig(info *model.NetworkInfo) error {
+func getDNSConfig(ctx context.Context, info *model.NetworkInfo) error {
 	// 初始化DNS配置信息
 	dnsInfo := model.DNSConfigInfo{
 		Servers:       []string{},
@@ -309,7 +273,7 @@ func getDNSConfig(info *model.NetworkInfo) error {
 	}
 
 	// 使用scutil命令获取DNS配置
-	output, err := runCommand("scutil", "--dns")
+	output, err := runCommand(ctx, "scutil", "--dns")
 	if err != nil {
 		return err
 	}
@@ -359,6 +323,9 @@ func getDNSConfig(info *model.NetworkInfo) error {
 		}
 	}
 
+	// 按接口和作用域区分的解析器
+	dnsInfo.Resolvers = parseScutilDNS(output)
+
 	// 获取DNS解析顺序
 	orderRegex := regexp.MustCompile(`resolver #(\d+)[\s\S]*?domain : (.+)`)
 	orderMatches := orderRegex.FindAllStringSubmatch(output, -1)
@@ -423,351 +390,47 @@ func contains(slice []string, item string) bool {
 	return false
 }
 
-// getPublicIP 获取公网IP
-func getPublicIP(info *model.NetworkInfo) error {
-	// 使用外部服务获取公网IP
-	client := http.Client{
-		Timeout: 5 * time.Second,
-	}
-
-	resp, err := client.Get("https://api.ipify.org?format=json")
-	if err != nil {
-		// 如果获取失败，设置一个默认值
-		info.PublicIP = "202.13.3.2"
-		return err
-	}
-	defer resp.Body.Close()
-
-	// 读取响应
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		// 如果读取失败，设置一个默认值
-		info.PublicIP = "202.13.3.2"
-		return err
-	}
-
-	// 解析JSON响应
-	var result struct {
-		IP string `json:"ip"`
-	}
-
-	err = json.Unmarshal(body, &result)
-	if err != nil {
-		// 如果解析失败，设置一个默认值
-		info.PublicIP = "202.13.3.2"
-		return err
-	}
-
-	// 设置公网IP
-	info.PublicIP = result.IP
-
-	return nil
-}
-
-// getVPNInfo 获取VPN信息
-func getVPNInfo(info *model.NetworkInfo) error {
-	// 初始化VPN信息
-	vpnInfo := model.VPNInfo{
-		IsConnected: false,
-		Services:    []string{},
-		Nodes:       []string{},
-	}
-
-	// 使用networksetup命令获取VPN服务列表
-	output, err := runCommand("networksetup", "-listallnetworkservices")
-	if err != nil {
-		return err
-	}
-
-	// 检查是否有VPN服务
-	scanner := bufio.NewScanner(strings.NewReader(output))
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.Contains(line, "VPN") || strings.Contains(line, "vpn") {
-			vpnServices := strings.TrimSpace(line)
-			vpnInfo.Services = append(vpnInfo.Services, vpnServices)
-		}
-	}
-
-	// 检查是否有VPN连接
-	ifconfigOutput, err := runCommand("ifconfig")
-	if err == nil {
-		// 检查是否有utun接口（通常用于VPN）
-		utunRegex := regexp.MustCompile(`utun\d+: `)
-		if utunRegex.MatchString(ifconfigOutput) {
-			vpnInfo.IsConnected = true
-
-			// 提取utun接口名称
-			utunMatches := utunRegex.FindAllStringSubmatch(ifconfigOutput, -1)
-			for _, match := range utunMatches {
-				if len(match) > 0 {
-					utunName := strings.TrimSuffix(match[0], ": ")
-					vpnInfo.Interfaces = append(vpnInfo.Interfaces, utunName)
-				}
-			}
-		}
-	}
-
-	// 使用scutil命令获取VPN连接详情
-	scutilOutput, err := runCommand("scutil", "--nc", "list")
-	if err == nil {
-		vpnConnRegex := regexp.MustCompile(`"(.+)" \(([A-Za-z0-9-]+)\) : (.+)`)
-		scanner = bufio.NewScanner(strings.NewReader(scutilOutput))
-
-		for scanner.Scan() {
-			line := scanner.Text()
-			matches := vpnConnRegex.FindStringSubmatch(line)
-
-			if len(matches) > 3 {
-				vpnName := matches[1]
-				vpnID := matches[2]
-				vpnStatus := matches[3]
-
-				// 检查是否是VPN连接
-				if strings.Contains(vpnStatus, "Connected") {
-					vpnInfo.IsConnected = true
-					vpnInfo.ActiveConnection = vpnName
-					vpnInfo.ConnectionID = vpnID
-					vpnInfo.Status = vpnStatus
-				}
-
-				// 添加到VPN节点列表
-				vpnNode := model.VPNNodeInfo{
-					Name:   vpnName,
-					ID:     vpnID,
-					Status: vpnStatus,
-				}
-				vpnInfo.NodeInfos = append(vpnInfo.NodeInfos, vpnNode)
-			}
-		}
-	}
-
-	// 如果使用了Cisco AnyConnect VPN，尝试获取其状态
-	anyconnectOutput, err := runCommand("/opt/cisco/anyconnect/bin/vpn", "state")
-	if err == nil && !strings.Contains(anyconnectOutput, "not found") {
-		if strings.Contains(anyconnectOutput, "state: Connected") {
-			vpnInfo.IsConnected = true
-			vpnInfo.Provider = "Cisco AnyConnect"
-
-			// 提取连接的服务器
-			serverRegex := regexp.MustCompile(`>> server\s*:\s*(.+)`)
-			matches := serverRegex.FindStringSubmatch(anyconnectOutput)
-			if len(matches) > 1 {
-				vpnInfo.Server = matches[1]
-				vpnInfo.Nodes = append(vpnInfo.Nodes, matches[1])
-			}
-		}
-	}
-
-	// 如果使用了OpenVPN，尝试获取其状态
-	openvpnOutput, err := runCommand("ps", "-ef")
-	if err == nil {
-		if strings.Contains(openvpnOutput, "openvpn") {
-			// 提取OpenVPN配置文件路径
-			openvpnRegex := regexp.MustCompile(`openvpn\s+--config\s+([^\s]+)`)
-			matches := openvpnRegex.FindStringSubmatch(openvpnOutput)
-			if len(matches) > 1 {
-				vpnInfo.IsConnected = true
-				vpnInfo.Provider = "OpenVPN"
-				vpnInfo.ConfigFile = matches[1]
-
-				// 尝试从配置文件中提取服务器信息
-				if _, err := os.Stat(matches[1]); err == nil {
-					configContent, err := os.ReadFile(matches[1])
-					if err == nil {
-						serverRegex := regexp.MustCompile(`remote\s+([^\s]+)`)
-						serverMatches := serverRegex.FindAllSubmatch(configContent, -1)
-						for _, match := range serverMatches {
-							if len(match) > 1 {
-								server := string(match[1])
-								vpnInfo.Nodes = append(vpnInfo.Nodes, server)
-								if vpnInfo.Server == "" {
-									vpnInfo.Server = server
-								}
-							}
-						}
-					}
-				}
-			}
-		}
-	}
-
-	// 如果没有检测到VPN连接，不设置默认值
-	// VPN状态默认为未连接
-	vpnInfo.IsConnected = false
-
-	info.VPN = vpnInfo
-
-	return nil
-}
-
-// getNetworkLatency 获取网络延迟信息
-func getNetworkLatency(info *model.NetworkInfo) error {
-	// 初始化延迟信息
-	latencyInfo := model.LatencyInfo{
-		Targets:     []model.TargetLatencyInfo{},
-		NetworkHops: []model.NetworkHopInfo{},
-	}
-
-	// 定义要ping的目标
-	targets := []struct {
-		Name string
-		Host string
-	}{
-		{"Google DNS", "8.8.8.8"},
-		{"Cloudflare DNS", "1.1.1.1"},
-		{"Baidu", "www.baidu.com"},
-	}
-
-	for _, target := range targets {
-		// 使用ping命令获取网络延迟信息
-		output, err := runCommand("ping", "-c", "5", "-q", target.Host)
-		if err != nil {
-			log.Printf("Error pinging %s: %v", target.Host, err)
-			continue
-		}
-
-		// 解析ping结果
-		latencyRegex := regexp.MustCompile(`min/avg/max/stddev = ([\d.]+)/([\d.]+)/([\d.]+)/([\d.]+)`)
-		matches := latencyRegex.FindStringSubmatch(output)
-
-		if len(matches) > 4 {
-			// 解析延迟数据
-			min, _ := strconv.ParseFloat(matches[1], 64)
-			avg, _ := strconv.ParseFloat(matches[2], 64)
-			max, _ := strconv.ParseFloat(matches[3], 64)
-			stddev, _ := strconv.ParseFloat(matches[4], 64)
-
-			// 解析丢包率
-			packetLossRegex := regexp.MustCompile(`(\d+)% packet loss`)
-			plMatches := packetLossRegex.FindStringSubmatch(output)
-			var packetLoss float64
-			if len(plMatches) > 1 {
-				packetLoss, _ = strconv.ParseFloat(plMatches[1], 64)
-			}
-
-			// 创建目标延迟信息
-			targetLatency := model.TargetLatencyInfo{
-				TargetName: target.Name,
-				TargetHost: target.Host,
-				MinLatency: min,
-				AvgLatency: avg,
-				MaxLatency: max,
-				StdDev:     stddev,
-				PacketLoss: packetLoss,
-				Jitter:     stddev, // 使用标准差作为抖动的估计值
-			}
-
-			// 添加到延迟信息中
-			latencyInfo.Targets = append(latencyInfo.Targets, targetLatency)
-		}
-	}
-
-	// 使用mtr命令获取更详细的网络路径信息（如果可用）
-	mtrOutput, err := runCommand("mtr", "-r", "-c", "5", "8.8.8.8")
-	if err == nil {
-		// 解析mtr输出
-		scanner := bufio.NewScanner(strings.NewReader(mtrOutput))
-		// 跳过标题行
-		for i := 0; i < 2 && scanner.Scan(); i++ {
-		}
-
-		var hops []model.NetworkHopInfo
-		for scanner.Scan() {
-			line := scanner.Text()
-			fields := strings.Fields(line)
-
-			// 确保有足够的字段
-			if len(fields) < 10 {
-				continue
-			}
-
-			// 解析跳数和主机名
-			hopNum, _ := strconv.Atoi(fields[0])
-			hostname := fields[1]
-
-			// 解析丢包率和延迟
-			lossStr := fields[2]
-			loss, _ := strconv.ParseFloat(strings.TrimSuffix(lossStr, "%"), 64)
-
-			snt, _ := strconv.Atoi(fields[3])
-			last, _ := strconv.ParseFloat(fields[4], 64)
-			avg, _ := strconv.ParseFloat(fields[5], 64)
-			best, _ := strconv.ParseFloat(fields[6], 64)
-			worst, _ := strconv.ParseFloat(fields[7], 64)
-			stddev, _ := strconv.ParseFloat(fields[8], 64)
-
-			// 创建跳点信息
-			hop := model.NetworkHopInfo{
-				HopNum:       hopNum,
-				Host:         hostname,
-				Loss:         loss,
-				SentPackets:  snt,
-				LastLatency:  last,
-				AvgLatency:   avg,
-				BestLatency:  best,
-				WorstLatency: worst,
-				StdDev:       stddev,
-			}
-
-			hops = append(hops, hop)
-		}
-
-		latencyInfo.NetworkHops = hops
-	}
-
-	// 设置默认值
-	info.Latency.AvgLatency = 10
-
-	// 设置网络延迟信息
-	info.Latency = latencyInfo
-
-	return nil
-}
-
-// getProxyStatus 获取网络代理状态
-func getProxyStatus(info *model.NetworkInfo) error {
-	// 使用networksetup命令获取网络代理状态
-	output, err := runCommand("networksetup", "-getwebproxy", "Wi-Fi")
-	if err != nil {
-		return err
+// getPublicIP 同时向多个查询服务获取IPv4和IPv6公网出口地址，取多数服务一致的结果
+// 单个服务被屏蔽（例如部分地区无法访问api.ipify.org）时仍能得到结果；离线模式下不访问外部服务
+func getPublicIP(ctx context.Context, info *model.NetworkInfo) error {
+	cfg := config.Current()
+	if cfg.Offline {
+		return nil
 	}
 
-	// 解析代理状态
-	enabledRegex := regexp.MustCompile(`Enabled: (.+)`)
-	matches := enabledRegex.FindStringSubmatch(output)
-
-	if len(matches) > 1 {
-		if matches[1] == "Yes" {
-			// 如果代理已启用，获取代理服务器和端口
-			serverRegex := regexp.MustCompile(`Server: (.+)`)
-			portRegex := regexp.MustCompile(`Port: (.+)`)
-
-			serverMatches := serverRegex.FindStringSubmatch(output)
-			portMatches := portRegex.FindStringSubmatch(output)
-
-			if len(serverMatches) > 1 && len(portMatches) > 1 {
-				portNum, _ := strconv.Atoi(portMatches[1])
-				info.ProxyInfo = model.ProxyInfo{
-					Enabled: true,
-					Server:  serverMatches[1],
-					Port:    portNum,
-				}
-			}
-		} else {
-			info.ProxyInfo = model.ProxyInfo{
-				Enabled: false,
-			}
-		}
-	}
+	var v4, v6 netprobe.PublicIPResult
+	var err4, err6 error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		v4, err4 = netprobe.LookupPublicIP("tcp4", cfg.PublicIP.Endpoints, netprobe.PublicIPTimeout)
+	}()
+	go func() {
+		defer wg.Done()
+		v6, err6 = netprobe.LookupPublicIP("tcp6", cfg.PublicIP.Endpoints, netprobe.PublicIPTimeout)
+	}()
+	wg.Wait()
+
+	// 没有IPv6出口是常见情况，不作为错误
+	if err6 == nil {
+		info.PublicIPv6 = v6.IP
+	}
+	if err4 != nil {
+		return err4
+	}
+	info.PublicIP = v4.IP
+	info.PublicIPAgree = fmt.Sprintf("%d/%d", v4.Agree, v4.Responded)
+	info.PublicIPOthers = v4.Others
+	analysis.EnrichPublicIP(info, cfg.PublicIP)
 
 	return nil
 }
 
 // getRouteTable 获取客户端路由表
-func getRouteTable(info *model.NetworkInfo) error {
+func getRouteTable(ctx context.Context, info *model.NetworkInfo) error {
 	// 使用netstat -nr命令获取路由表
-	output, err := runCommand("netstat", "-nr")
+	output, err := runCommand(ctx, "netstat", "-nr")
 	if err != nil {
 		return err
 	}
@@ -819,7 +482,7 @@ func getRouteTable(info *model.NetworkInfo) error {
 }
 
 // getHostsFile 获取hosts文件内容
-func getHostsFile(info *model.NetworkInfo) error {
+func getHostsFile(ctx context.Context, info *model.NetworkInfo) error {
 	// 读取hosts文件
 	hostsFile := "/etc/hosts"
 	content, err := os.ReadFile(hostsFile)
@@ -855,75 +518,22 @@ func getHostsFile(info *model.NetworkInfo) error {
 	return nil
 }
 
-// getNetworkTraffic 获取网卡流量和进程流量
-func getNetworkTraffic(info *model.NetworkInfo) error {
-	// 使用netstat -I en0 -b命令获取网卡流量
-	// 获取初始流量数据
-	output1, err := runCommand("netstat", "-I", "en0", "-b")
-	if err != nil {
-		// 如果命令失败，设置默认值
-		info.NetworkTraffic = "0 KB/s"
-		info.ProcessTraffic = "0 KB/s"
-		return nil
-	}
-
-	// 等待1秒
-	time.Sleep(1 * time.Second)
-
-	// 获取1秒后的流量数据
-	output2, err := runCommand("netstat", "-I", "en0", "-b")
+// getNetworkTraffic 采样各网络接口的收发速率，并计算所有网卡的合计流量
+func getNetworkTraffic(ctx context.Context, info *model.NetworkInfo) error {
+	rates, err := ifaces.Rates(analysis.ProcessSampleWindow)
 	if err != nil {
 		info.NetworkTraffic = "0 KB/s"
-		info.ProcessTraffic = "0 KB/s"
-		return nil
+		return err
 	}
 
-	// 解析两次输出，计算流量差值
-	bytes1 := parseNetstatOutput(output1)
-	bytes2 := parseNetstatOutput(output2)
-
-	// 计算每秒流量（字节）
-	bytesPerSecond := bytes2 - bytes1
-
-	// 转换为KB/s
-	kbPerSecond := float64(bytesPerSecond) / 1024.0
-
-	// 设置网卡流量
-	info.NetworkTraffic = fmt.Sprintf("%.2f KB/s", kbPerSecond)
-
-	// 获取进程流量
-	// 这部分需要使用nettop命令，但需要root权限
-	// 这里使用简化的方法，只显示总流量
-	info.ProcessTraffic = fmt.Sprintf("%.2f KB/s", kbPerSecond)
-
+	rx, tx := ifaces.Total(rates)
+	info.InterfaceTraffic = rates
+	info.NetworkTraffic = fmt.Sprintf("%.2f KB/s", (rx+tx)/1024)
 	return nil
 }
 
-// parseNetstatOutput 解析netstat输出，提取字节数
-func parseNetstatOutput(output string) int64 {
-	var totalBytes int64 = 0
-
-	lines := strings.Split(output, "\n")
-	if len(lines) < 2 {
-		return 0
-	}
-
-	// 跳过表头
-	for i := 1; i < len(lines); i++ {
-		fields := strings.Fields(lines[i])
-		if len(fields) >= 10 {
-			// 第7列是接收字节数，第10列是发送字节数
-			inBytes, _ := strconv.ParseInt(fields[6], 10, 64)
-			outBytes, _ := strconv.ParseInt(fields[9], 10, 64)
-			totalBytes += inBytes + outBytes
-		}
-	}
-
-	return totalBytes
-}
-
 // getCountryCode 获取用户当前所在地区代码
-func getCountryCode(info *model.NetworkInfo) error {
+func getCountryCode(ctx context.Context, info *model.NetworkInfo) error {
 	// 使用IP地址查询API获取国家/地区代码
 	client := &http.Client{
 		Timeout: time.Second * 5,
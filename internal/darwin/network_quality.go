@@ -0,0 +1,28 @@
+package darwin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// MeasureNetworkQuality 使用系统自带的networkQuality（macOS 12及以上）测试负载下的响应能力（RPM），
+// 会同时占满上传和下载带宽约20秒，只在使用--network-quality参数时调用；
+// 没有该命令或测试失败时使用内置的负载下延迟测试
+func MeasureNetworkQuality(ctx context.Context, info *model.NetworkInfo) error {
+	if output, err := runCommand(ctx, "networkQuality", "-c"); err == nil {
+		if quality, err := analysis.ParseNetworkQuality([]byte(output)); err == nil {
+			info.Quality = quality
+			return nil
+		}
+	}
+
+	info.Quality = analysis.MeasureNetworkQuality(config.Current().Quality)
+	if info.Quality.Error != "" {
+		return errors.New(info.Quality.Error)
+	}
+	return nil
+}
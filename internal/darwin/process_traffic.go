@@ -0,0 +1,65 @@
+package darwin
+
+import (
+	"bufio"
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// getProcessTraffic 使用nettop统计采样窗口内各进程的收发字节数
+func getProcessTraffic(ctx context.Context, info *model.NetworkInfo) error {
+	window := analysis.ProcessSampleWindow
+	seconds := strconv.Itoa(int(window / time.Second))
+
+	// -P按进程汇总，-x输出原始数值，-d第二次采样输出与上一次的差值，-L 2采样两次后退出
+	output, err := runCommand(ctx, "nettop", "-P", "-x", "-d", "-L", "2", "-s", seconds, "-J", "bytes_in,bytes_out")
+	if err != nil {
+		return err
+	}
+
+	ranked := analysis.RankProcessTraffic(parseNettop(output), window)
+	info.ProcessTrafficStats = ranked
+	info.ProcessTraffic = analysis.SummarizeProcessTraffic(ranked)
+	return nil
+}
+
+// parseNettop 解析nettop的CSV输出，只保留最后一次采样（差值）
+// 格式：time,,bytes_in,bytes_out, 每行的第二列为“进程名.PID”
+func parseNettop(output string) []model.ProcessTrafficInfo {
+	var traffic []model.ProcessTrafficInfo
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ",")
+		if len(fields) < 4 {
+			continue
+		}
+		// 每次采样前都会输出表头
+		if fields[0] == "time" {
+			traffic = nil
+			continue
+		}
+
+		dot := strings.LastIndex(fields[1], ".")
+		if dot < 0 {
+			continue
+		}
+		pid, err := strconv.ParseInt(fields[1][dot+1:], 10, 32)
+		if err != nil {
+			continue
+		}
+		bytesIn, _ := strconv.ParseUint(fields[2], 10, 64)
+		bytesOut, _ := strconv.ParseUint(fields[3], 10, 64)
+		traffic = append(traffic, model.ProcessTrafficInfo{
+			PID:      int32(pid),
+			Process:  fields[1][:dot],
+			BytesIn:  bytesIn,
+			BytesOut: bytesOut,
+		})
+	}
+	return traffic
+}
@@ -0,0 +1,187 @@
+package darwin
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// networksetup中各类代理对应的查询参数
+var proxyCommands = []struct {
+	proxyType string
+	arg       string
+}{
+	{analysis.ProxyHTTP, "-getwebproxy"},
+	{analysis.ProxyHTTPS, "-getsecurewebproxy"},
+	{analysis.ProxySOCKS, "-getsocksfirewallproxy"},
+}
+
+// getProxyStatus 获取所有已启用网络服务的HTTP、HTTPS、SOCKS、PAC和WPAD代理设置
+// scutil --proxy中的生效设置可能来自配置描述文件或VPN客户端，没有出现在任何网络服务中时单独列出
+func getProxyStatus(ctx context.Context, info *model.NetworkInfo) error {
+	var proxy model.ProxyInfo
+	services, err := listNetworkServices(ctx)
+	if err != nil {
+		return err
+	}
+	for _, service := range services {
+		proxy.Settings = append(proxy.Settings, getServiceProxies(ctx, service)...)
+	}
+
+	if output, err := runCommand(ctx, "scutil", "--proxy"); err == nil {
+		settings, bypass := parseScutilProxy(output)
+		proxy.Bypass = bypass
+		for _, setting := range settings {
+			if !hasProxySetting(proxy.Settings, setting) {
+				proxy.Settings = append(proxy.Settings, setting)
+			}
+		}
+	}
+
+	analysis.SummarizeProxy(&proxy)
+	analysis.CollectProxyEnvironment(&proxy, os.Environ())
+	info.ProxyInfo = proxy
+	info.ProxyStatus = proxy.Enabled
+	return nil
+}
+
+// listNetworkServices 列出已启用的网络服务，第一行是说明，禁用的服务以星号开头
+func listNetworkServices(ctx context.Context) ([]string, error) {
+	output, err := runCommand(ctx, "networksetup", "-listallnetworkservices")
+	if err != nil {
+		return nil, err
+	}
+	var services []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "*") || strings.HasPrefix(line, "An asterisk") {
+			continue
+		}
+		services = append(services, line)
+	}
+	return services, nil
+}
+
+// getServiceProxies 获取一个网络服务中启用的代理
+func getServiceProxies(ctx context.Context, service string) []model.ProxySettingInfo {
+	var settings []model.ProxySettingInfo
+	for _, command := range proxyCommands {
+		output, err := runCommand(ctx, "networksetup", command.arg, service)
+		if err != nil {
+			continue
+		}
+		fields := parseNetworksetupFields(output)
+		if fields["Enabled"] != "Yes" || fields["Server"] == "" {
+			continue
+		}
+		port, _ := strconv.Atoi(fields["Port"])
+		settings = append(settings, model.ProxySettingInfo{Source: service, Type: command.proxyType, Server: fields["Server"], Port: port})
+	}
+
+	// 输出格式：URL: http://proxy.example.com/proxy.pac，Enabled: Yes
+	if output, err := runCommand(ctx, "networksetup", "-getautoproxyurl", service); err == nil {
+		fields := parseNetworksetupFields(output)
+		if fields["Enabled"] == "Yes" && fields["URL"] != "" && fields["URL"] != "(null)" {
+			settings = append(settings, model.ProxySettingInfo{Source: service, Type: analysis.ProxyPAC, Server: fields["URL"]})
+		}
+	}
+
+	// 输出格式：Auto Proxy Discovery: On
+	if output, err := runCommand(ctx, "networksetup", "-getproxyautodiscovery", service); err == nil {
+		if parseNetworksetupFields(output)["Auto Proxy Discovery"] == "On" {
+			settings = append(settings, model.ProxySettingInfo{Source: service, Type: analysis.ProxyWPAD})
+		}
+	}
+	return settings
+}
+
+// parseNetworksetupFields 解析networksetup输出的"键: 值"行
+func parseNetworksetupFields(output string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) == 2 {
+			fields[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	return fields
+}
+
+// parseScutilProxy 解析scutil --proxy输出的生效代理设置和例外列表，例如：
+//
+//	<dictionary> {
+//	  ExceptionsList : <array> {
+//	    0 : *.local
+//	  }
+//	  HTTPEnable : 1
+//	  HTTPPort : 8080
+//	  HTTPProxy : proxy.example.com
+//	  ProxyAutoConfigEnable : 1
+//	  ProxyAutoConfigURLString : http://proxy.example.com/proxy.pac
+//	}
+func parseScutilProxy(output string) ([]model.ProxySettingInfo, []string) {
+	values := make(map[string]string)
+	var bypass []string
+	inExceptions := false
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if inExceptions {
+			if line == "}" {
+				inExceptions = false
+				continue
+			}
+			if parts := strings.SplitN(line, " : ", 2); len(parts) == 2 {
+				bypass = append(bypass, strings.TrimSpace(parts[1]))
+			}
+			continue
+		}
+		parts := strings.SplitN(line, " : ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if key == "ExceptionsList" {
+			inExceptions = true
+			continue
+		}
+		values[key] = value
+	}
+
+	var settings []model.ProxySettingInfo
+	const source = "系统生效设置"
+	for _, p := range []struct{ proxyType, prefix string }{
+		{analysis.ProxyHTTP, "HTTP"},
+		{analysis.ProxyHTTPS, "HTTPS"},
+		{analysis.ProxySOCKS, "SOCKS"},
+	} {
+		if values[p.prefix+"Enable"] != "1" || values[p.prefix+"Proxy"] == "" {
+			continue
+		}
+		port, _ := strconv.Atoi(values[p.prefix+"Port"])
+		settings = append(settings, model.ProxySettingInfo{Source: source, Type: p.proxyType, Server: values[p.prefix+"Proxy"], Port: port})
+	}
+	if values["ProxyAutoConfigEnable"] == "1" && values["ProxyAutoConfigURLString"] != "" {
+		settings = append(settings, model.ProxySettingInfo{Source: source, Type: analysis.ProxyPAC, Server: values["ProxyAutoConfigURLString"]})
+	}
+	if values["ProxyAutoDiscoveryEnable"] == "1" {
+		settings = append(settings, model.ProxySettingInfo{Source: source, Type: analysis.ProxyWPAD})
+	}
+	return settings, bypass
+}
+
+// hasProxySetting 判断是否已有相同类型、服务器和端口的代理设置
+func hasProxySetting(settings []model.ProxySettingInfo, setting model.ProxySettingInfo) bool {
+	for _, s := range settings {
+		if s.Type == setting.Type && s.Server == setting.Server && s.Port == setting.Port {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package darwin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// periodicDirs periodic任务脚本所在目录及其执行周期
+var periodicDirs = []struct {
+	dir     string
+	trigger string
+}{
+	{"/etc/periodic/daily", "每天"},
+	{"/etc/periodic/weekly", "每周"},
+	{"/etc/periodic/monthly", "每月"},
+	{"/usr/local/etc/periodic/daily", "每天"},
+	{"/usr/local/etc/periodic/weekly", "每周"},
+	{"/usr/local/etc/periodic/monthly", "每月"},
+}
+
+// getScheduledTasks 获取cron、periodic和按时间触发的launchd任务
+func getScheduledTasks(ctx context.Context, info *model.SystemInfo) error {
+	// 系统crontab和各用户的crontab（读取用户crontab需要root权限）
+	crontabs := []string{"/etc/crontab"}
+	if userTabs, err := filepath.Glob("/usr/lib/cron/tabs/*"); err == nil {
+		crontabs = append(crontabs, userTabs...)
+	}
+	readable := false
+	for _, path := range crontabs {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if path != "/etc/crontab" {
+			readable = true
+		}
+		info.ScheduledTasks = append(info.ScheduledTasks, parseCrontab(string(data), filepath.Base(path), path == "/etc/crontab")...)
+	}
+	// 无权限读取cron目录时退回到当前用户的crontab
+	if !readable {
+		if output, err := runCommand(ctx, "crontab", "-l"); err == nil {
+			user := os.Getenv("USER")
+			info.ScheduledTasks = append(info.ScheduledTasks, parseCrontab(output, user, false)...)
+		}
+	}
+
+	// periodic脚本
+	for _, periodic := range periodicDirs {
+		entries, err := os.ReadDir(periodic.dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			info.ScheduledTasks = append(info.ScheduledTasks, model.ScheduledTaskInfo{
+				Name:    entry.Name(),
+				Source:  "periodic",
+				Trigger: periodic.trigger,
+				Command: filepath.Join(periodic.dir, entry.Name()),
+			})
+		}
+	}
+
+	// 配置了StartInterval或StartCalendarInterval的launchd任务
+	loaded, _ := getLaunchctlList(ctx)
+	for _, jobFile := range loadLaunchdJobs() {
+		job := jobFile.job
+		trigger := ""
+		if job.StartInterval > 0 {
+			trigger = fmt.Sprintf("每 %d 秒", job.StartInterval)
+		} else if job.StartCalendarInterval != nil {
+			trigger = formatCalendarInterval(job.StartCalendarInterval)
+		}
+		if trigger == "" {
+			continue
+		}
+
+		task := model.ScheduledTaskInfo{
+			Name:    job.Label,
+			Source:  "launchd",
+			Trigger: trigger,
+			Command: strings.Join(job.ProgramArguments, " "),
+		}
+		if task.Command == "" {
+			task.Command = job.Program
+		}
+		if entry, ok := loaded[job.Label]; ok && entry.status != "-" {
+			task.LastResult = "退出码 " + entry.status
+		}
+
+		info.ScheduledTasks = append(info.ScheduledTasks, task)
+	}
+
+	return nil
+}
+
+// parseCrontab 解析crontab内容，系统crontab在时间字段后多一个用户字段
+func parseCrontab(content, owner string, system bool) []model.ScheduledTaskInfo {
+	var tasks []model.ScheduledTaskInfo
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		// 跳过环境变量设置
+		if strings.Contains(fields[0], "=") {
+			continue
+		}
+
+		// @reboot、@daily等简写只占一个时间字段
+		timeFields := 5
+		if strings.HasPrefix(fields[0], "@") {
+			timeFields = 1
+		}
+		commandStart := timeFields
+		name := owner
+		if system {
+			if len(fields) <= timeFields {
+				continue
+			}
+			name = fields[timeFields]
+			commandStart++
+		}
+		if len(fields) <= commandStart {
+			continue
+		}
+
+		tasks = append(tasks, model.ScheduledTaskInfo{
+			Name:    name,
+			Source:  "cron",
+			Trigger: strings.Join(fields[:timeFields], " "),
+			Command: strings.Join(fields[commandStart:], " "),
+		})
+	}
+
+	return tasks
+}
+
+// formatCalendarInterval 将StartCalendarInterval格式化为cron风格的"分 时 日 月 周"
+func formatCalendarInterval(value interface{}) string {
+	var intervals []map[string]interface{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		intervals = append(intervals, v)
+	case []interface{}:
+		for _, item := range v {
+			if m, ok := item.(map[string]interface{}); ok {
+				intervals = append(intervals, m)
+			}
+		}
+	}
+
+	var schedules []string
+	for _, interval := range intervals {
+		var fields []string
+		for _, key := range []string{"Minute", "Hour", "Day", "Month", "Weekday"} {
+			if v, ok := interval[key]; ok {
+				fields = append(fields, fmt.Sprint(v))
+			} else {
+				fields = append(fields, "*")
+			}
+		}
+		schedules = append(schedules, strings.Join(fields, " "))
+	}
+
+	return strings.Join(schedules, "; ")
+}
@@ -0,0 +1,53 @@
+package darwin
+
+import (
+	"context"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// spotlightProcesses Spotlight索引相关进程
+var spotlightProcesses = []string{"mds", "mds_stores", "mdworker", "mdworker_shared", "mdsync"}
+
+// getSearchIndex 获取各卷的Spotlight索引状态和索引进程的CPU占用
+// 依赖getRunningApps先收集进程信息
+func getSearchIndex(ctx context.Context, info *model.SystemInfo) error {
+	index := &info.SearchIndex
+	index.Service = "Spotlight"
+
+	output, err := runCommand(ctx, "mdutil", "-sa")
+	if err != nil {
+		return err
+	}
+
+	// 输出格式为卷路径独占一行（以冒号结尾），下一行为缩进的状态
+	var volume *model.SearchIndexVolumeInfo
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, " ") && strings.HasSuffix(trimmed, ":") {
+			index.Volumes = append(index.Volumes, model.SearchIndexVolumeInfo{Volume: strings.TrimSuffix(trimmed, ":")})
+			volume = &index.Volumes[len(index.Volumes)-1]
+			continue
+		}
+		if volume != nil && volume.Status == "" {
+			volume.Status = strings.TrimSuffix(trimmed, ".")
+			volume.Enabled = strings.HasPrefix(trimmed, "Indexing enabled")
+		}
+	}
+
+	index.State = "已停用"
+	for _, v := range index.Volumes {
+		if v.Enabled {
+			index.State = "已启用"
+			break
+		}
+	}
+
+	analysis.EvaluateIndexerLoad(index, info.RunningApps, spotlightProcesses)
+	return nil
+}
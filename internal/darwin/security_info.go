@@ -0,0 +1,334 @@
+package darwin
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// socketfilterfw 应用程序防火墙命令行工具路径
+const socketfilterfw = "/usr/libexec/ApplicationFirewall/socketfilterfw"
+
+// getFirewall 获取应用程序防火墙和pf的状态
+func getFirewall(ctx context.Context, info *model.SystemInfo) error {
+	output, err := runCommand(ctx, socketfilterfw, "--getglobalstate")
+	if err != nil {
+		return err
+	}
+	// 输出格式为：Firewall is enabled. (State = 1)
+	info.Firewall.Enabled = strings.Contains(output, "enabled")
+
+	if output, err := runCommand(ctx, socketfilterfw, "--getstealthmode"); err == nil {
+		info.Firewall.StealthMode = strings.Contains(output, "enabled") || strings.Contains(output, " on")
+	}
+
+	// 不同版本的输出为"Block all ENABLED!"、"Block all DISABLED!"或"Firewall has block all state set to disabled."
+	if output, err := runCommand(ctx, socketfilterfw, "--getblockall"); err == nil {
+		info.Firewall.BlockAllIncoming = strings.Contains(strings.ToLower(output), "enabled")
+	}
+
+	// 统计允许传入连接的应用
+	if output, err := runCommand(ctx, socketfilterfw, "--listapps"); err == nil {
+		info.Firewall.InboundAllowRules = strings.Count(output, "Allow incoming connections")
+	}
+
+	// pfctl需要root权限
+	if output, err := runCommand(ctx, "pfctl", "-s", "info"); err == nil {
+		info.Firewall.PFEnabled = strings.Contains(output, "Status: Enabled")
+	}
+
+	return nil
+}
+
+// knownEndpointAgent 表示已知的防病毒/EDR产品及其安装路径和进程名
+type knownEndpointAgent struct {
+	name      string
+	kind      string
+	paths     []string
+	processes []string
+}
+
+// knownEndpointAgents 常见的macOS防病毒和EDR产品
+var knownEndpointAgents = []knownEndpointAgent{
+	{"CrowdStrike Falcon", "EDR", []string{"/Applications/Falcon.app"}, []string{"falcond", "com.crowdstrike.falcon.Agent"}},
+	{"SentinelOne", "EDR", []string{"/Applications/SentinelOne/SentinelOne Extensions.app", "/Library/Sentinel"}, []string{"sentineld"}},
+	{"Microsoft Defender", "防病毒", []string{"/Applications/Microsoft Defender.app"}, []string{"wdavdaemon"}},
+	{"Sophos", "防病毒", []string{"/Applications/Sophos/Sophos Endpoint.app", "/Library/Sophos Anti-Virus"}, []string{"SophosScanD", "SophosAntiVirus"}},
+	{"Jamf Protect", "EDR", []string{"/Applications/JamfProtect.app"}, []string{"JamfProtect"}},
+	{"VMware Carbon Black", "EDR", []string{"/Applications/VMware Carbon Black Cloud/VMware CBCloud.app"}, []string{"CbOsxSensorService", "com.vmware.carbonblack.cloud.se-agent"}},
+	{"Cortex XDR", "EDR", []string{"/Applications/Cortex XDR.app"}, []string{"Cortex XDR", "cortex-xdr"}},
+	{"Malwarebytes", "防病毒", []string{"/Applications/Malwarebytes.app"}, []string{"RTProtectionDaemon"}},
+}
+
+// getEndpointProtection 根据安装路径和运行进程检测防病毒和EDR产品
+// 依赖getRunningApps先收集进程列表
+func getEndpointProtection(ctx context.Context, info *model.SystemInfo) error {
+	running := make(map[string]bool)
+	for _, process := range info.RunningApps {
+		running[process.Name] = true
+	}
+
+	for _, agent := range knownEndpointAgents {
+		installedPath := ""
+		for _, path := range agent.paths {
+			if _, err := os.Stat(path); err == nil {
+				installedPath = path
+				break
+			}
+		}
+
+		isRunning := false
+		for _, process := range agent.processes {
+			if running[process] {
+				isRunning = true
+				break
+			}
+		}
+
+		if installedPath == "" && !isRunning {
+			continue
+		}
+
+		product := model.EndpointProtectionInfo{
+			Name:     agent.name,
+			Kind:     agent.kind,
+			Running:  isRunning,
+			RealTime: "未知",
+		}
+		if strings.HasSuffix(installedPath, ".app") {
+			if appInfo, err := getAppInfo(installedPath); err == nil {
+				product.Version = appInfo.Version
+			}
+		}
+
+		// Microsoft Defender可通过mdatp查询实时保护状态
+		if agent.name == "Microsoft Defender" {
+			if output, err := runCommand(ctx, "mdatp", "health", "--field", "real_time_protection_enabled"); err == nil {
+				product.RealTime = "未启用"
+				if strings.TrimSpace(output) == "true" {
+					product.RealTime = "已启用"
+				}
+			}
+			if output, err := runCommand(ctx, "mdatp", "health", "--field", "definitions_status"); err == nil {
+				product.Definitions = "已过期"
+				if strings.Contains(output, "up_to_date") {
+					product.Definitions = "最新"
+				}
+			}
+		}
+
+		info.Antivirus = append(info.Antivirus, product)
+	}
+
+	return nil
+}
+
+// xprotectBundles XProtect的安装位置（macOS 11起位于/Library/Apple下）
+var xprotectBundles = []string{
+	"/Library/Apple/System/Library/CoreServices/XProtect.bundle",
+	"/System/Library/CoreServices/XProtect.bundle",
+}
+
+// getPlatformSecurity 获取SIP、Gatekeeper、XProtect版本和第三方内核扩展策略
+func getPlatformSecurity(ctx context.Context, info *model.SystemInfo) error {
+	var security model.PlatformSecurityInfo
+
+	// 输出格式为：System Integrity Protection status: enabled.
+	output, err := runCommand(ctx, "csrutil", "status")
+	if err == nil {
+		if matches := regexp.MustCompile(`status:\s*([^.\n]+)`).FindStringSubmatch(output); len(matches) > 1 {
+			security.SIPStatus = strings.TrimSpace(matches[1])
+		}
+	}
+
+	// 输出格式为：assessments enabled
+	if output, err := runCommand(ctx, "spctl", "--status"); err == nil {
+		security.Gatekeeper = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(output), "assessments"))
+	}
+
+	for _, bundle := range xprotectBundles {
+		if _, statErr := os.Stat(bundle); statErr == nil {
+			if bundleInfo, err := getAppInfo(bundle); err == nil {
+				security.XProtectVersion = bundleInfo.Version
+			}
+			break
+		}
+	}
+
+	// Apple Silicon通过启动策略中的smb2控制第三方内核扩展，需要管理员权限
+	if policyOutput, err := runCommand(ctx, "bputil", "-d"); err == nil {
+		security.ThirdPartyKexts = "不允许"
+		if matches := regexp.MustCompile(`\(smb2\):\s*(\S+)`).FindStringSubmatch(policyOutput); len(matches) > 1 && matches[1] != "absent" {
+			security.ThirdPartyKexts = "允许"
+		}
+	} else if output, err := runCommand(ctx, "spctl", "kext-consent", "status"); err == nil {
+		// Intel机型允许加载第三方内核扩展，启用用户同意时需要手动批准
+		security.ThirdPartyKexts = "允许"
+		if strings.Contains(output, "ENABLED") {
+			security.ThirdPartyKexts = "允许（需用户批准）"
+		}
+	}
+
+	info.PlatformSecurity = security
+	return err
+}
+
+// sshPortRegex 匹配sshd_config中的Port设置
+var sshPortRegex = regexp.MustCompile(`(?m)^\s*Port\s+(\d+)`)
+
+// getRemoteAccess 获取远程登录（SSH）、屏幕共享和远程管理的开放状态
+func getRemoteAccess(ctx context.Context, info *model.SystemInfo) error {
+	// 远程登录（SSH），启用后sshd会在launchd系统域中注册
+	ssh := model.RemoteAccessInfo{Name: "远程登录（SSH）"}
+	if _, err := runCommand(ctx, "launchctl", "print", "system/com.openssh.sshd"); err == nil {
+		ssh.Enabled = true
+		ssh.Ports = []int{22}
+		if config, err := os.ReadFile("/etc/ssh/sshd_config"); err == nil {
+			if ports := parsePorts(sshPortRegex.FindAllStringSubmatch(string(config), -1)); len(ports) > 0 {
+				ssh.Ports = ports
+			}
+		}
+		ssh.AllowedUsers = getAccessGroupMembers(ctx, "com.apple.access_ssh")
+	}
+
+	// 屏幕共享（VNC）
+	screenSharing := model.RemoteAccessInfo{Name: "屏幕共享"}
+	if _, err := runCommand(ctx, "launchctl", "print", "system/com.apple.screensharing"); err == nil {
+		screenSharing.Enabled = true
+		screenSharing.Ports = []int{5900}
+		screenSharing.AllowedUsers = getAccessGroupMembers(ctx, "com.apple.access_screensharing")
+	}
+
+	// 远程管理（Apple Remote Desktop），启用时ARDAgent常驻运行
+	ard := model.RemoteAccessInfo{Name: "远程管理（ARD）"}
+	if output, err := runCommand(ctx, "pgrep", "-x", "ARDAgent"); err == nil && strings.TrimSpace(output) != "" {
+		ard.Enabled = true
+		ard.Ports = []int{3283, 5900}
+		// ARD_AllLocalUsers为false时只允许指定用户
+		if output, err := runCommand(ctx, "defaults", "read", "/Library/Preferences/com.apple.RemoteManagement", "ARD_AllLocalUsers"); err == nil && strings.TrimSpace(output) == "0" {
+			ard.AllowedUsers = getARDPrivilegedUsers(ctx, info)
+		}
+	}
+
+	info.RemoteAccess = []model.RemoteAccessInfo{ssh, screenSharing, ard}
+	return nil
+}
+
+// getAccessGroupMembers 获取服务访问控制组的成员，组不存在表示允许所有用户
+func getAccessGroupMembers(ctx context.Context, group string) []string {
+	output, err := runCommand(ctx, "dscl", ".", "-read", "/Groups/"+group, "GroupMembership")
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(strings.TrimPrefix(strings.TrimSpace(output), "GroupMembership:"))
+}
+
+// getARDPrivilegedUsers 获取具有远程管理权限（naprivs属性）的本地用户
+func getARDPrivilegedUsers(ctx context.Context, info *model.SystemInfo) []string {
+	var users []string
+	for _, account := range info.LocalAccounts {
+		if output, err := runCommand(ctx, "dscl", ".", "-read", "/Users/"+account.Name, "naprivs"); err == nil && strings.Contains(output, "naprivs") {
+			users = append(users, account.Name)
+		}
+	}
+	return users
+}
+
+// parsePorts 将正则匹配的端口号转换为整数列表
+func parsePorts(matches [][]string) []int {
+	var ports []int
+	for _, match := range matches {
+		if port, err := strconv.Atoi(match[1]); err == nil {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+// getFileSharing 获取SMB/AFP文件共享状态和共享点
+func getFileSharing(ctx context.Context, info *model.SystemInfo) error {
+	sharing := &info.FileSharing
+
+	// 文件共享启用后smbd会在launchd系统域中注册
+	if _, err := runCommand(ctx, "launchctl", "print", "system/com.apple.smbd"); err == nil {
+		sharing.Protocols = append(sharing.Protocols, "SMB")
+	}
+	if _, err := runCommand(ctx, "launchctl", "print", "system/com.apple.AppleFileServer"); err == nil {
+		sharing.Protocols = append(sharing.Protocols, "AFP")
+	}
+	sharing.Enabled = len(sharing.Protocols) > 0
+
+	output, err := runCommand(ctx, "sharing", "-l")
+	if err != nil {
+		return err
+	}
+	sharing.Shares = parseSharePoints(output)
+	return nil
+}
+
+// parseSharePoints 解析sharing -l的输出，每个共享点包含name、path及各协议的配置块
+func parseSharePoints(output string) []model.FileShareInfo {
+	var shares []model.FileShareInfo
+	var current *model.FileShareInfo
+	protocol := ""
+	var protocols []string
+	readOnly := false
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.Protocols = strings.Join(protocols, ", ")
+		current.Permissions = "读写"
+		if readOnly {
+			current.Permissions = "只读"
+		}
+		shares = append(shares, *current)
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			if line == "}" {
+				protocol = ""
+			}
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch {
+		case protocol == "" && key == "name":
+			flush()
+			current = &model.FileShareInfo{Name: value}
+			protocols = nil
+			readOnly = false
+		case protocol == "" && key == "path" && current != nil:
+			current.Path = value
+		case protocol == "" && value == "{":
+			protocol = strings.ToUpper(key)
+		case protocol != "" && current != nil:
+			switch key {
+			case "shared":
+				if value == "1" {
+					protocols = append(protocols, protocol)
+				}
+			case "guest access":
+				if value == "1" {
+					current.GuestAccess = true
+				}
+			case "read only":
+				if value == "1" {
+					readOnly = true
+				}
+			}
+		}
+	}
+	flush()
+
+	return shares
+}
@@ -0,0 +1,129 @@
+package darwin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"howett.net/plist"
+)
+
+// ServiceLabel 系统服务的launchd标签
+const ServiceLabel = "com.asterzephyr.sysspector"
+
+// 系统服务使用的文件
+const (
+	launchDaemonPath = "/Library/LaunchDaemons/" + ServiceLabel + ".plist"
+
+	// 服务的日志由程序通过 --log-file 参数写入并轮转；标准错误只有崩溃时的输出，由launchd写入单独的文件
+	serviceLogPath    = "/Library/Logs/SysSpector/sysspector.log"
+	serviceStderrPath = "/Library/Logs/SysSpector/sysspector.stderr.log"
+
+	// legacyNewsyslogPath 以前的版本用newsyslog轮转日志时安装的配置，安装和卸载时删除
+	legacyNewsyslogPath = "/etc/newsyslog.d/" + ServiceLabel + ".conf"
+
+	// ServiceConfigPath 系统服务默认使用的配置文件
+	ServiceConfigPath = "/Library/Application Support/SysSpector/config.json"
+)
+
+// launchDaemon 表示LaunchDaemon的plist文件
+type launchDaemon struct {
+	Label             string   `plist:"Label"`
+	ProgramArguments  []string `plist:"ProgramArguments"`
+	RunAtLoad         bool     `plist:"RunAtLoad"`
+	KeepAlive         bool     `plist:"KeepAlive"`
+	ThrottleInterval  int      `plist:"ThrottleInterval"`
+	StandardOutPath   string   `plist:"StandardOutPath"`
+	StandardErrorPath string   `plist:"StandardErrorPath"`
+}
+
+// InstallService 将程序注册为开机启动并在退出后自动重启的LaunchDaemon并立即启动，需要root权限。
+// 服务的日志写入serviceLogPath，由程序按大小轮转
+func InstallService(executable string, args []string) error {
+	daemon := launchDaemon{
+		Label:             ServiceLabel,
+		ProgramArguments:  append(append([]string{executable}, args...), "--log-file", serviceLogPath),
+		RunAtLoad:         true,
+		KeepAlive:         true,
+		ThrottleInterval:  30,
+		StandardOutPath:   serviceStderrPath,
+		StandardErrorPath: serviceStderrPath,
+	}
+	data, err := plist.MarshalIndent(daemon, plist.XMLFormat, "\t")
+	if err != nil {
+		return err
+	}
+	for _, dir := range []string{filepath.Dir(serviceLogPath), filepath.Dir(ServiceConfigPath)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	// 重新安装时先停止已加载的旧服务
+	if serviceLoaded() {
+		runCommand(context.Background(), "launchctl", "bootout", "system/"+ServiceLabel)
+	}
+	if err := os.WriteFile(launchDaemonPath, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Remove(legacyNewsyslogPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return StartService()
+}
+
+// UninstallService 停止并删除LaunchDaemon，保留配置文件和日志
+func UninstallService() error {
+	if serviceLoaded() {
+		if err := StopService(); err != nil {
+			return err
+		}
+	}
+	for _, path := range []string{launchDaemonPath, legacyNewsyslogPath} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartService 加载并启动LaunchDaemon
+func StartService() error {
+	if _, err := os.Stat(launchDaemonPath); err != nil {
+		return fmt.Errorf("service is not installed: %v", err)
+	}
+	if !serviceLoaded() {
+		if _, err := runCommand(context.Background(), "launchctl", "bootstrap", "system", launchDaemonPath); err != nil {
+			return err
+		}
+	}
+	_, err := runCommand(context.Background(), "launchctl", "kickstart", "system/"+ServiceLabel)
+	return err
+}
+
+// StopService 停止并卸载LaunchDaemon；服务设置了KeepAlive，只结束进程会被launchd重新启动
+// 卸载后直到下次start或重新开机前不会运行
+func StopService() error {
+	_, err := runCommand(context.Background(), "launchctl", "bootout", "system/"+ServiceLabel)
+	if err != nil && !strings.Contains(err.Error(), "No such process") {
+		return err
+	}
+	return nil
+}
+
+// RestartService 重新启动已加载的LaunchDaemon，用于更新程序后使用新版本；服务没有加载时返回false
+func RestartService() (bool, error) {
+	if !serviceLoaded() {
+		return false, nil
+	}
+	_, err := runCommand(context.Background(), "launchctl", "kickstart", "-k", "system/"+ServiceLabel)
+	return err == nil, err
+}
+
+// serviceLoaded 判断LaunchDaemon是否已加载
+func serviceLoaded() bool {
+	_, err := runCommand(context.Background(), "launchctl", "print", "system/"+ServiceLabel)
+	return err == nil
+}
@@ -0,0 +1,152 @@
+package darwin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+	"howett.net/plist"
+)
+
+// launchdJob 表示launchd配置文件中的任务定义
+type launchdJob struct {
+	Label            string      `plist:"Label"`
+	Program          string      `plist:"Program"`
+	ProgramArguments []string    `plist:"ProgramArguments"`
+	RunAtLoad        bool        `plist:"RunAtLoad"`
+	KeepAlive        interface{} `plist:"KeepAlive"`
+	Disabled         bool        `plist:"Disabled"`
+
+	StartInterval         int         `plist:"StartInterval"`
+	StartCalendarInterval interface{} `plist:"StartCalendarInterval"`
+}
+
+// launchdJobFile 表示从配置文件读取的launchd任务
+type launchdJobFile struct {
+	file string
+	kind string
+	job  launchdJob
+}
+
+// launchdDirs 第三方launchd任务所在目录（系统自带的/System/Library下的任务不收集）
+var launchdDirs = []struct {
+	dir  string
+	kind string
+}{
+	{"/Library/LaunchDaemons", "守护进程"},
+	{"/Library/LaunchAgents", "代理"},
+	{"~/Library/LaunchAgents", "代理"},
+}
+
+// launchctlEntry 表示launchctl list中的一行
+type launchctlEntry struct {
+	pid    int
+	status string
+}
+
+// getLaunchctlList 获取已加载任务的状态，格式为：PID Status Label
+func getLaunchctlList(ctx context.Context) (map[string]launchctlEntry, error) {
+	loaded := make(map[string]launchctlEntry)
+	output, err := runCommand(ctx, "launchctl", "list")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(output, "\n")[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		pid, _ := strconv.Atoi(fields[0])
+		loaded[fields[2]] = launchctlEntry{pid: pid, status: fields[1]}
+	}
+	return loaded, nil
+}
+
+// loadLaunchdJobs 读取第三方launchd配置目录中的所有任务定义
+func loadLaunchdJobs() []launchdJobFile {
+	var jobs []launchdJobFile
+
+	homeDir, _ := os.UserHomeDir()
+	for _, launchdDir := range launchdDirs {
+		dir := launchdDir.dir
+		if strings.HasPrefix(dir, "~/") {
+			if homeDir == "" {
+				continue
+			}
+			dir = filepath.Join(homeDir, dir[2:])
+		}
+
+		files, err := filepath.Glob(filepath.Join(dir, "*.plist"))
+		if err != nil {
+			continue
+		}
+
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				continue
+			}
+			var job launchdJob
+			if _, err := plist.Unmarshal(data, &job); err != nil || job.Label == "" {
+				continue
+			}
+			jobs = append(jobs, launchdJobFile{file: file, kind: launchdDir.kind, job: job})
+		}
+	}
+
+	return jobs
+}
+
+// getServices 获取launchd守护进程和代理及其运行状态
+func getServices(ctx context.Context, info *model.SystemInfo) error {
+	loaded, err := getLaunchctlList(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, jobFile := range loadLaunchdJobs() {
+		job := jobFile.job
+		service := model.ServiceInfo{
+			Name:        job.Label,
+			DisplayName: strings.TrimSuffix(filepath.Base(jobFile.file), ".plist"),
+			Kind:        jobFile.kind,
+			StartType:   "按需",
+			Path:        job.Program,
+		}
+		if service.Path == "" && len(job.ProgramArguments) > 0 {
+			service.Path = job.ProgramArguments[0]
+		}
+
+		// KeepAlive可能为布尔值或条件字典，只要设置了就视为常驻
+		keepAlive := job.KeepAlive != nil && job.KeepAlive != false
+		if job.RunAtLoad || keepAlive {
+			service.StartType = "自动"
+		}
+		if job.Disabled {
+			service.StartType = "已禁用"
+		}
+
+		if entry, ok := loaded[job.Label]; ok {
+			service.PID = entry.pid
+			if entry.pid > 0 {
+				service.State = "运行中"
+			} else if entry.status != "0" && entry.status != "-" {
+				service.State = "已停止（退出码 " + entry.status + "）"
+			} else {
+				service.State = "已加载"
+			}
+		} else if jobFile.kind == "代理" && os.Geteuid() == 0 {
+			// 以root运行时launchctl list只包含系统域，无法得知用户代理的状态
+			service.State = "未知"
+		} else {
+			service.State = "未加载"
+		}
+
+		info.Services = append(info.Services, service)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package darwin
+
+import (
+	"context"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// getStartupItems 获取登录项和随登录启动的LaunchAgents
+// 依赖getServices先收集launchd任务
+func getStartupItems(ctx context.Context, info *model.SystemInfo) error {
+	// 通过System Events获取登录项，每行格式为：名称|路径
+	output, err := runCommand(ctx, "osascript",
+		"-e", `set out to ""`,
+		"-e", `tell application "System Events"`,
+		"-e", `repeat with li in every login item`,
+		"-e", `set out to out & (name of li) & "|" & (path of li) & linefeed`,
+		"-e", `end repeat`,
+		"-e", `end tell`,
+		"-e", `return out`)
+	if err == nil {
+		for _, line := range strings.Split(output, "\n") {
+			parts := strings.SplitN(strings.TrimSpace(line), "|", 2)
+			if len(parts) != 2 || parts[0] == "" {
+				continue
+			}
+			info.StartupItems = append(info.StartupItems, model.StartupItemInfo{
+				Name:     parts[0],
+				Location: "登录项",
+				Target:   parts[1],
+			})
+		}
+	}
+
+	// 随登录自动启动的LaunchAgents
+	for _, service := range info.Services {
+		if service.Kind != "代理" || service.StartType != "自动" {
+			continue
+		}
+		info.StartupItems = append(info.StartupItems, model.StartupItemInfo{
+			Name:     service.Name,
+			Location: "LaunchAgents",
+			Target:   service.Path,
+		})
+	}
+
+	return err
+}
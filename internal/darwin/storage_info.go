@@ -0,0 +1,104 @@
+package darwin
+
+import (
+	"context"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+	"howett.net/plist"
+)
+
+// diskutilList 表示diskutil list -plist的输出
+type diskutilList struct {
+	AllDisksAndPartitions []diskutilDisk `plist:"AllDisksAndPartitions"`
+}
+
+// diskutilDisk 表示diskutil list中的磁盘或分区
+type diskutilDisk struct {
+	DeviceIdentifier string         `plist:"DeviceIdentifier"`
+	MountPoint       string         `plist:"MountPoint"`
+	Partitions       []diskutilDisk `plist:"Partitions"`
+	APFSVolumes      []diskutilDisk `plist:"APFSVolumes"`
+}
+
+// diskutilInfo 表示diskutil info -plist的输出
+type diskutilInfo struct {
+	VolumeName     string `plist:"VolumeName"`
+	MountPoint     string `plist:"MountPoint"`
+	FilesystemName string `plist:"FilesystemName"`
+	MediaName      string `plist:"MediaName"`
+	BusProtocol    string `plist:"BusProtocol"`
+	TotalSize      uint64 `plist:"TotalSize"`
+	FreeSpace      uint64 `plist:"FreeSpace"`
+	APFSFree       uint64 `plist:"APFSContainerFree"`
+	Encryption     bool   `plist:"Encryption"`
+	FileVault      bool   `plist:"FileVault"`
+	Locked         bool   `plist:"Locked"`
+}
+
+// getExternalStorage 获取外接和可移动存储上已挂载的卷
+func getExternalStorage(ctx context.Context, info *model.SystemInfo) error {
+	output, err := runCommand(ctx, "diskutil", "list", "-plist", "external")
+	if err != nil {
+		return err
+	}
+
+	var list diskutilList
+	if _, err := plist.Unmarshal([]byte(output), &list); err != nil {
+		return err
+	}
+
+	// 收集所有已挂载卷的设备标识
+	var volumes []string
+	var collect func(disks []diskutilDisk)
+	collect = func(disks []diskutilDisk) {
+		for _, d := range disks {
+			if d.MountPoint != "" {
+				volumes = append(volumes, d.DeviceIdentifier)
+			}
+			collect(d.Partitions)
+			collect(d.APFSVolumes)
+		}
+	}
+	collect(list.AllDisksAndPartitions)
+
+	var storage []model.ExternalStorageInfo
+	for _, volume := range volumes {
+		volumeOutput, err := runCommand(ctx, "diskutil", "info", "-plist", volume)
+		if err != nil {
+			continue
+		}
+
+		var volumeInfo diskutilInfo
+		if _, err := plist.Unmarshal([]byte(volumeOutput), &volumeInfo); err != nil {
+			continue
+		}
+
+		entry := model.ExternalStorageInfo{
+			Name:       volumeInfo.VolumeName,
+			Device:     volume,
+			Model:      strings.TrimSpace(volumeInfo.MediaName),
+			Bus:        volumeInfo.BusProtocol,
+			MountPoint: volumeInfo.MountPoint,
+			Filesystem: volumeInfo.FilesystemName,
+			Total:      volumeInfo.TotalSize,
+			Free:       volumeInfo.FreeSpace,
+			Encrypted:  volumeInfo.Encryption || volumeInfo.FileVault,
+			Encryption: "未加密",
+		}
+		if entry.Free == 0 {
+			entry.Free = volumeInfo.APFSFree
+		}
+		if entry.Encrypted {
+			entry.Encryption = "已加密"
+			if volumeInfo.Locked {
+				entry.Encryption = "已加密（已锁定）"
+			}
+		}
+
+		storage = append(storage, entry)
+	}
+
+	info.ExternalStorage = storage
+	return nil
+}
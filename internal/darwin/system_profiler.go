@@ -0,0 +1,249 @@
+package darwin
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 系统信息采集使用的system_profiler数据类型，一次调用全部获取
+var profilerDataTypes = []string{
+	"SPHardwareDataType",
+	"SPPowerDataType",
+	"SPBluetoothDataType",
+	"SPAirPortDataType",
+	"SPMemoryDataType",
+	"SPStorageDataType",
+	"SPiBridgeDataType",
+	"SPPCIDataType",
+}
+
+// systemProfiler 在第一次使用时通过一次system_profiler -json调用获取所有数据类型，
+// 解析后的结果供同一次采集的各采集项共用，并发的采集项等待同一次调用完成
+type systemProfiler struct {
+	dataTypes []string
+	once      sync.Once
+	report    profilerReport
+	err       error
+}
+
+// newSystemProfiler 创建获取dataTypes的systemProfiler，每次采集创建一个，不在两次采集之间缓存
+func newSystemProfiler(dataTypes ...string) *systemProfiler {
+	return &systemProfiler{dataTypes: dataTypes}
+}
+
+// Report 返回system_profiler的解析结果。调用由多个采集项共用，不随第一个调用者的ctx被取消而终止，
+// 最长运行slowTaskTimeout
+func (p *systemProfiler) Report(ctx context.Context) (*profilerReport, error) {
+	p.once.Do(func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), slowTaskTimeout)
+		defer cancel()
+		args := append([]string{"-json"}, p.dataTypes...)
+		output, err := runCommand(ctx, "system_profiler", args...)
+		if err != nil {
+			p.err = err
+			return
+		}
+		p.err = json.Unmarshal([]byte(output), &p.report)
+	})
+	return &p.report, p.err
+}
+
+// profilerReport 表示system_profiler -json的输出，每个数据类型为一组条目
+type profilerReport struct {
+	Hardware  []spHardware  `json:"SPHardwareDataType"`
+	Power     []spPower     `json:"SPPowerDataType"`
+	Bluetooth []spBluetooth `json:"SPBluetoothDataType"`
+	AirPort   []spAirPort   `json:"SPAirPortDataType"`
+	Memory    []spMemory    `json:"SPMemoryDataType"`
+	Storage   []spStorage   `json:"SPStorageDataType"`
+	Bridge    []spBridge    `json:"SPiBridgeDataType"`
+	PCI       []spPCIDevice `json:"SPPCIDataType"`
+}
+
+// spHardware 表示SPHardwareDataType的硬件概览
+type spHardware struct {
+	MachineName     spValue `json:"machine_name"`     // 型号名称，例如"MacBook Pro"
+	BootROMVersion  spValue `json:"boot_rom_version"` // 系统固件版本，文本输出中为System Firmware Version或Boot ROM Version
+	OSLoaderVersion spValue `json:"os_loader_version"`
+	SMCVersion      spValue `json:"SMC_version_system"`
+}
+
+// spPower 表示SPPowerDataType的一个条目，电池、电源设置和充电器各为一个条目，按_name区分
+type spPower struct {
+	Name       string `json:"_name"`
+	HealthInfo struct {
+		CycleCount      spValue `json:"sppower_battery_cycle_count"`
+		Health          spValue `json:"sppower_battery_health"`
+		MaximumCapacity spValue `json:"sppower_battery_health_maximum_capacity"` // 例如"90%"
+	} `json:"sppower_battery_health_info"`
+
+	ChargerConnected    spValue `json:"sppower_battery_charger_connected"`
+	ChargerWatts        spValue `json:"sppower_ac_charger_watts"`
+	ChargerName         spValue `json:"sppower_ac_charger_name"`
+	ChargerFamily       spValue `json:"sppower_ac_charger_family"`
+	ChargerSerialNumber spValue `json:"sppower_ac_charger_serial_number"`
+	ChargerID           spValue `json:"sppower_ac_charger_ID"`
+	ChargerManufacturer spValue `json:"sppower_ac_charger_manufacturer"`
+}
+
+// SPPowerDataType中电池和充电器条目的_name
+const (
+	spPowerBattery = "spbattery_information"
+	spPowerCharger = "sppower_ac_charger_information"
+)
+
+// power 返回SPPowerDataType中名称为name的条目
+func (r *profilerReport) power(name string) (spPower, bool) {
+	for _, item := range r.Power {
+		if item.Name == name {
+			return item, true
+		}
+	}
+	return spPower{}, false
+}
+
+// spBluetooth 表示SPBluetoothDataType的条目。macOS 12起使用controller_properties和device_connected，
+// 更早的版本使用local_device_title和device_title
+type spBluetooth struct {
+	Controller struct {
+		State spValue `json:"controller_state"` // attrib_on或attrib_off
+	} `json:"controller_properties"`
+	LocalDevice struct {
+		Power spValue `json:"general_power"`
+	} `json:"local_device_title"`
+	Connected []map[string]spBluetoothDevice `json:"device_connected"`
+	Devices   []map[string]spBluetoothDevice `json:"device_title"`
+}
+
+// spBluetoothDevice 表示一个蓝牙设备，设备名称为外层对象的键
+type spBluetoothDevice struct {
+	Address     spValue `json:"device_address"`
+	MinorType   spValue `json:"device_minorType"`
+	IsConnected spValue `json:"device_isconnected"` // macOS 11及更早的版本，attrib_Yes或attrib_No
+}
+
+// spAirPort 表示SPAirPortDataType的条目
+type spAirPort struct {
+	Interfaces []spAirPortInterface `json:"spairport_airport_interfaces"`
+}
+
+// spAirPortInterface 表示一个无线网卡，awdl0等虚拟接口没有当前网络信息
+type spAirPortInterface struct {
+	Name           string            `json:"_name"`
+	CurrentNetwork *spAirPortNetwork `json:"spairport_current_network_information"`
+	SupportedPHY   spValue           `json:"spairport_supported_phymodes"`
+	CountryCode    spValue           `json:"spairport_wireless_country_code"`
+}
+
+// spAirPortNetwork 表示当前连接的WiFi网络
+type spAirPortNetwork struct {
+	SSID        string  `json:"_name"`
+	BSSID       spValue `json:"spairport_network_bssid"`
+	Channel     spValue `json:"spairport_network_channel"` // 例如"149 (5GHz, 80MHz)"
+	CountryCode spValue `json:"spairport_network_country_code"`
+	MCS         spValue `json:"spairport_network_mcs"`
+	PHYMode     spValue `json:"spairport_network_phymode"`
+	Rate        spValue `json:"spairport_network_rate"`
+	Security    spValue `json:"spairport_security_mode"` // 例如"spairport_security_mode_wpa2_personal"
+	SignalNoise spValue `json:"spairport_signal_noise"`  // 例如"-53 dBm / -93 dBm"
+}
+
+// spMemory 表示SPMemoryDataType的条目。Intel Mac的各插槽内存条在_items中，
+// Apple Silicon没有插槽信息，板载内存的容量、类型和制造商直接在条目中
+type spMemory struct {
+	Size         spValue        `json:"SPMemoryDataType"`
+	Type         spValue        `json:"dimm_type"`
+	Manufacturer spValue        `json:"dimm_manufacturer"`
+	Upgradeable  spValue        `json:"is_memory_upgradeable"`
+	Items        []spMemoryDIMM `json:"_items"`
+}
+
+// spMemoryDIMM 表示一个内存插槽
+type spMemoryDIMM struct {
+	Slot         string  `json:"_name"` // 例如"BANK 0/ChannelA-DIMM0"
+	Size         spValue `json:"dimm_size"`
+	Speed        spValue `json:"dimm_speed"` // 例如"2667 MHz"
+	Type         spValue `json:"dimm_type"`
+	Manufacturer spValue `json:"dimm_manufacturer"`
+	PartNumber   spValue `json:"dimm_part_number"`
+}
+
+// spStorage 表示SPStorageDataType中的一个卷
+type spStorage struct {
+	Name          string  `json:"_name"`
+	BSDName       spValue `json:"bsd_name"`
+	SizeInBytes   spValue `json:"size_in_bytes"`
+	PhysicalDrive struct {
+		DeviceName spValue `json:"device_name"`
+		Internal   spValue `json:"is_internal_disk"`
+	} `json:"physical_drive"`
+}
+
+// spBridge 表示SPiBridgeDataType的条目，只有带T2芯片的Intel Mac有内容
+type spBridge struct {
+	ModelName spValue `json:"ibridge_model_name"` // 例如"Apple T2 Security Chip"
+	Build     spValue `json:"ibridge_build"`      // iBridge固件版本，文本输出中为Firmware Version
+}
+
+// spPCIDevice 表示SPPCIDataType中的一个PCIe/雷雳设备
+type spPCIDevice struct {
+	Name            string  `json:"_name"`
+	Type            spValue `json:"sppci_device_type"`
+	VendorID        spValue `json:"sppci_vendor-id"`
+	DeviceID        spValue `json:"sppci_device-id"`
+	Slot            spValue `json:"sppci_slot_name"`
+	LinkWidth       spValue `json:"sppci_link-width"`
+	LinkSpeed       spValue `json:"sppci_link-speed"`
+	DriverInstalled spValue `json:"sppci_driver_installed"` // yes或no
+}
+
+// spValue 表示system_profiler输出中的标量值，同一个字段在不同版本的macOS上可能输出为字符串或数字
+type spValue string
+
+// UnmarshalJSON 接受字符串、数字和布尔值，其他类型的值忽略
+func (v *spValue) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*v = spValue(strings.TrimSpace(s))
+		return nil
+	}
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	switch raw := raw.(type) {
+	case float64:
+		*v = spValue(strconv.FormatFloat(raw, 'f', -1, 64))
+	case bool:
+		*v = spValue(strconv.FormatBool(raw))
+	}
+	return nil
+}
+
+// String 返回值的文本
+func (v spValue) String() string {
+	return string(v)
+}
+
+// Int 返回值开头的整数，例如"90%"返回90、"2667 MHz"返回2667，不是数字时返回0
+func (v spValue) Int() int {
+	s := string(v)
+	end := 0
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9' || end == 0 && s[end] == '-') {
+		end++
+	}
+	n, _ := strconv.Atoi(s[:end])
+	return n
+}
+
+// Bool 判断值是否表示是，system_profiler使用TRUE、Yes、attrib_on、attrib_Yes等
+func (v spValue) Bool() bool {
+	switch strings.ToLower(strings.TrimPrefix(string(v), "attrib_")) {
+	case "true", "yes", "on":
+		return true
+	}
+	return false
+}
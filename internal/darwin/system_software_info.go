@@ -1,6 +1,7 @@
 package darwin
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
 	"log"
@@ -11,52 +12,57 @@ import (
 	"strings"
 	"time"
 
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/internal/collect"
 	"github.com/AsterZephyr/SysSpector/pkg/model"
 	"github.com/shirou/gopsutil/v3/process"
 	"howett.net/plist"
 )
 
-// GetSystemSoftwareInfo 收集macOS系统的系统信息和软件信息
-func GetSystemSoftwareInfo(info *model.SystemInfo) error {
-	var err error
-
-	// 获取系统版本
-	err = getSystemVersion(info)
-	if err != nil {
-		log.Printf("Error getting system version: %v", err)
-	}
-
-	// 获取电脑名称
-	err = getComputerName(info)
-	if err != nil {
-		log.Printf("Error getting computer name: %v", err)
-	}
-
-	// 获取启动时间
-	err = getUpTime(info)
-	if err != nil {
-		log.Printf("Error getting up time: %v", err)
+// softwareTasks 返回macOS系统的系统信息和软件信息的采集项，互相依赖的步骤放在同一个采集项中
+func softwareTasks() []collect.Task[model.SystemInfo] {
+	return []collect.Task[model.SystemInfo]{
+		{Name: "system_version", Run: getSystemVersion},
+		{Name: "computer_name", Run: getComputerName},
+		{Name: "uptime", Run: getUpTime},
+		// 最近的启动和关机记录
+		{Name: "boot_history", Run: getBootHistory},
+		// 时区、区域和输入法
+		{Name: "locale", Run: getLocaleInfo},
+		// 关键环境变量（敏感值脱敏）
+		{Name: "environment", Run: func(ctx context.Context, info *model.SystemInfo) error {
+			info.Environment = analysis.SnapshotEnvironment(os.Environ())
+			return nil
+		}},
+		// 已安装应用、浏览器和协议与文件类型的默认打开程序（依赖已安装应用信息）
+		{Name: "apps", Timeout: slowTaskTimeout, Run: collect.Steps(getInstalledApps, getBrowsers, getDefaultHandlers)},
+		// launchd服务和登录启动项（依赖服务信息）
+		{Name: "services", Run: collect.Steps(getServices, getStartupItems)},
+		{Name: "scheduled_tasks", Run: getScheduledTasks},
+		{Name: "firewall", Run: getFirewall},
+		// 平台安全设置
+		{Name: "platform_security", Run: getPlatformSecurity},
+		// 本地用户账户和远程访问服务状态（依赖本地账户信息）
+		{Name: "accounts", Run: collect.Steps(getLocalAccounts, getRemoteAccess)},
+		{Name: "file_sharing", Run: getFileSharing},
+		// 目录绑定和平台单点登录状态
+		{Name: "identity", Run: getIdentity},
+		// MDM注册状态和配置描述文件
+		{Name: "management", Run: getManagement},
+		// 钥匙串中的证书
+		{Name: "certificates", Run: getCertificates},
+		// 开发运行时和包管理器，运行中的容器和虚拟机
+		{Name: "dev_tools", Run: getDevTools},
+		{Name: "workloads", Run: getWorkloads},
+		// 正在运行的应用、Spotlight索引状态和防病毒与EDR产品（依赖正在运行的应用信息）
+		{Name: "running_apps", Run: collect.Steps(getRunningApps, getSearchIndex, getEndpointProtection)},
 	}
-
-	// 获取已安装应用信息
-	err = getInstalledApps(info)
-	if err != nil {
-		log.Printf("Error getting installed apps: %v", err)
-	}
-
-	// 获取正在运行的应用信息
-	err = getRunningApps(info)
-	if err != nil {
-		log.Printf("Error getting running apps: %v", err)
-	}
-
-	return nil
 }
 
 // getSystemVersion 获取系统版本
-func getSystemVersion(info *model.SystemInfo) error {
+func getSystemVersion(ctx context.Context, info *model.SystemInfo) error {
 	// 使用sw_vers命令获取系统版本
-	output, err := runCommand("sw_vers")
+	output, err := runCommand(ctx, "sw_vers")
 	if err != nil {
 		return err
 	}
@@ -81,9 +87,9 @@ func getSystemVersion(info *model.SystemInfo) error {
 }
 
 // getComputerName 获取电脑名称
-func getComputerName(info *model.SystemInfo) error {
+func getComputerName(ctx context.Context, info *model.SystemInfo) error {
 	// 使用hostname命令获取电脑名称
-	output, err := runCommand("hostname")
+	output, err := runCommand(ctx, "hostname")
 	if err != nil {
 		return err
 	}
@@ -95,9 +101,9 @@ func getComputerName(info *model.SystemInfo) error {
 }
 
 // getUpTime 获取启动时间
-func getUpTime(info *model.SystemInfo) error {
+func getUpTime(ctx context.Context, info *model.SystemInfo) error {
 	// 使用sysctl命令获取启动时间戳
-	output, err := runCommand("sysctl", "-n", "kern.boottime")
+	output, err := runCommand(ctx, "sysctl", "-n", "kern.boottime")
 	if err != nil {
 		return err
 	}
@@ -126,33 +132,65 @@ func getUpTime(info *model.SystemInfo) error {
 	return nil
 }
 
-// getInstalledApps 获取已安装应用信息
-func getInstalledApps(info *model.SystemInfo) error {
-	// 应用程序目录
-	appsDir := "/Applications"
+// appSearchDirs 需要扫描的应用程序目录及其来源
+var appSearchDirs = []struct {
+	dir    string
+	source string
+}{
+	{"/Applications", "应用程序"},
+	{"~/Applications", "用户应用程序"},
+	{"/System/Applications", "系统应用程序"},
+}
 
-	// 遍历应用程序目录
-	err := filepath.Walk(appsDir, func(path string, fileInfo fs.FileInfo, err error) error {
-		if err != nil {
-			return nil // 忽略错误，继续处理其他文件
-		}
+// homebrewPrefixes Homebrew的安装前缀（Apple Silicon与Intel）
+var homebrewPrefixes = []string{"/opt/homebrew", "/usr/local"}
 
-		// 只处理.app目录
-		if fileInfo.IsDir() && strings.HasSuffix(path, ".app") {
-			// 获取应用信息
-			appInfo, err := getAppInfo(path)
-			if err == nil {
-				info.InstalledApps = append(info.InstalledApps, appInfo)
+// getInstalledApps 获取已安装应用信息
+func getInstalledApps(ctx context.Context, info *model.SystemInfo) error {
+	homeDir, _ := os.UserHomeDir()
+	seen := make(map[string]bool)
+
+	for _, searchDir := range appSearchDirs {
+		appsDir := searchDir.dir
+		if strings.HasPrefix(appsDir, "~/") {
+			if homeDir == "" {
+				continue
 			}
+			appsDir = filepath.Join(homeDir, appsDir[2:])
 		}
 
-		return nil
-	})
+		// 遍历应用程序目录
+		err := filepath.Walk(appsDir, func(path string, fileInfo fs.FileInfo, err error) error {
+			if err != nil {
+				return nil // 忽略错误，继续处理其他文件
+			}
 
-	if err != nil {
-		return err
+			// 只处理.app目录，不再进入应用包内部
+			if fileInfo.IsDir() && strings.HasSuffix(path, ".app") {
+				if !seen[path] {
+					seen[path] = true
+					// 获取应用信息
+					appInfo, err := getAppInfo(path)
+					if err == nil {
+						if appInfo.Source == "" {
+							appInfo.Source = searchDir.source
+						}
+						info.InstalledApps = append(info.InstalledApps, appInfo)
+					}
+				}
+				return filepath.SkipDir
+			}
+
+			return nil
+		})
+		if err != nil {
+			log.Printf("Error walking %s: %v", appsDir, err)
+		}
 	}
 
+	// 获取Homebrew安装的软件
+	info.InstalledApps = append(info.InstalledApps, getHomebrewPackages()...)
+
 	return nil
 }
 
@@ -172,7 +210,7 @@ func getAppInfo(appPath string) (model.AppInfo, error) {
 		appInfo.InstallDate = fileInfo.ModTime().Format("2006年01月02日 15:04:05")
 	}
 
-	// 获取应用版本（从Info.plist文件中提取）
+	// 获取应用版本和Bundle ID（从Info.plist文件中提取）
 	infoPlistPath := filepath.Join(appPath, "Contents", "Info.plist")
 	if _, err := os.Stat(infoPlistPath); err == nil {
 		// 读取plist文件
@@ -191,21 +229,95 @@ func getAppInfo(appPath string) (model.AppInfo, error) {
 				} else if version, ok := plistData["CFBundleVersion"].(string); ok {
 					appInfo.Version = version
 				}
+				if bundleID, ok := plistData["CFBundleIdentifier"].(string); ok {
+					appInfo.BundleID = bundleID
+				}
 			}
 		}
 	}
 
+	// 从Mac App Store安装的应用包含收据文件
+	if _, err := os.Stat(filepath.Join(appPath, "Contents", "_MASReceipt", "receipt")); err == nil {
+		appInfo.Source = "Mac App Store"
+	}
+
 	return appInfo, nil
 }
 
+// getHomebrewPackages 获取Homebrew安装的Cask和Formula
+// 直接读取Caskroom和Cellar目录，避免以root身份运行brew命令失败
+func getHomebrewPackages() []model.AppInfo {
+	var apps []model.AppInfo
+
+	for _, prefix := range homebrewPrefixes {
+		apps = append(apps, listHomebrewDir(filepath.Join(prefix, "Caskroom"), "Homebrew Cask")...)
+		apps = append(apps, listHomebrewDir(filepath.Join(prefix, "Cellar"), "Homebrew Formula")...)
+	}
+
+	return apps
+}
+
+// listHomebrewDir 列出Homebrew目录下的软件包，目录结构为<名称>/<版本>
+func listHomebrewDir(dir, source string) []model.AppInfo {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var apps []model.AppInfo
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		packagePath := filepath.Join(dir, entry.Name())
+		appInfo := model.AppInfo{
+			Name:   entry.Name(),
+			Path:   packagePath,
+			Source: source,
+		}
+
+		// 取最近安装的版本
+		versions, err := os.ReadDir(packagePath)
+		if err != nil {
+			continue
+		}
+		var latest time.Time
+		for _, version := range versions {
+			if !version.IsDir() || strings.HasPrefix(version.Name(), ".") {
+				continue
+			}
+			versionInfo, err := version.Info()
+			if err != nil {
+				continue
+			}
+			if versionInfo.ModTime().After(latest) {
+				latest = versionInfo.ModTime()
+				appInfo.Version = version.Name()
+				appInfo.InstallDate = latest.Format("2006年01月02日 15:04:05")
+			}
+		}
+
+		apps = append(apps, appInfo)
+	}
+
+	return apps
+}
+
 // getRunningApps 获取正在运行的应用信息
-func getRunningApps(info *model.SystemInfo) error {
+func getRunningApps(ctx context.Context, info *model.SystemInfo) error {
 	// 使用gopsutil获取进程列表
 	processes, err := process.Processes()
 	if err != nil {
 		return err
 	}
 
+	// 先记录一次CPU时间，等待采样窗口后计算这段时间内的CPU使用率
+	for _, p := range processes {
+		p.Percent(0)
+	}
+	time.Sleep(analysis.ProcessSampleWindow)
+
 	// 处理每个进程
 	for _, p := range processes {
 		// 获取进程名称
@@ -219,8 +331,8 @@ func getRunningApps(info *model.SystemInfo) error {
 			continue
 		}
 
-		// 获取进程CPU使用率
-		cpuPercent, err := p.CPUPercent()
+		// 获取进程在采样窗口内的CPU使用率
+		cpuPercent, err := p.Percent(0)
 		if err != nil {
 			cpuPercent = 0
 		}
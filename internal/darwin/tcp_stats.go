@@ -0,0 +1,79 @@
+package darwin
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// netstat -s -p tcp输出中的计数，例如：
+//
+//	tcp:
+//		1234567 packets sent
+//			1234 data packets (567890 bytes) retransmitted
+//		2345678 packets received
+//			12 discarded for bad checksums
+//		3 bad connection attempts
+//		4567 connections closed (including 89 drops)
+//		10 embryonic connections dropped
+var (
+	tcpSentRegex          = regexp.MustCompile(`(?m)^\s*(\d+) packets sent`)
+	tcpReceivedRegex      = regexp.MustCompile(`(?m)^\s*(\d+) packets received`)
+	tcpRetransmittedRegex = regexp.MustCompile(`(\d+) data packets? \(\d+ bytes?\) retransmitted`)
+	tcpDropsRegex         = regexp.MustCompile(`connections? closed \(including (\d+) drops?\)`)
+	tcpFailureRegexes     = []*regexp.Regexp{
+		regexp.MustCompile(`(\d+) bad connection attempts?`),
+		regexp.MustCompile(`(\d+) embryonic connections? dropped`),
+	}
+	tcpErrorRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`(\d+) discarded for bad checksums?`),
+		regexp.MustCompile(`(\d+) discarded for bad header offset fields?`),
+		regexp.MustCompile(`(\d+) discarded because packet too short`),
+	}
+)
+
+// getTCPStats 采样TCP重传和错误计数
+func getTCPStats(ctx context.Context, info *model.NetworkInfo) error {
+	stats, err := analysis.SampleTCPStats(ctx, func() (analysis.TCPCounters, error) {
+		return readTCPCounters(ctx)
+	}, analysis.TCPSampleWindow)
+	if err != nil {
+		return err
+	}
+	info.TCPStats = stats
+	return nil
+}
+
+// readTCPCounters 读取netstat -s -p tcp的累计计数，macOS没有单独的重置计数，使用连接关闭时被丢弃（收到或发送RST）的连接数
+func readTCPCounters(ctx context.Context) (analysis.TCPCounters, error) {
+	output, err := runCommand(ctx, "netstat", "-s", "-p", "tcp")
+	if err != nil {
+		return analysis.TCPCounters{}, err
+	}
+	counters := analysis.TCPCounters{
+		SegmentsSent:     matchCounter(tcpSentRegex, output),
+		SegmentsReceived: matchCounter(tcpReceivedRegex, output),
+		Retransmitted:    matchCounter(tcpRetransmittedRegex, output),
+		Resets:           matchCounter(tcpDropsRegex, output),
+	}
+	for _, re := range tcpFailureRegexes {
+		counters.Failures += matchCounter(re, output)
+	}
+	for _, re := range tcpErrorRegexes {
+		counters.Errors += matchCounter(re, output)
+	}
+	return counters, nil
+}
+
+// matchCounter 返回正则表达式第一个分组匹配的数字，没有匹配时为0
+func matchCounter(re *regexp.Regexp, output string) uint64 {
+	matches := re.FindStringSubmatch(output)
+	if matches == nil {
+		return 0
+	}
+	value, _ := strconv.ParseUint(matches[1], 10, 64)
+	return value
+}
@@ -0,0 +1,38 @@
+package darwin
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// ntpServerRegex 匹配ntp.conf中的server配置
+var ntpServerRegex = regexp.MustCompile(`(?m)^\s*server\s+(\S+)`)
+
+// getTimeSync 获取网络时间配置并测量与参考服务器的时钟偏差
+// macOS的timed不对外提供上次同步时间，因此LastSync保持为空
+func getTimeSync(ctx context.Context, info *model.NetworkInfo) error {
+	timeSync := &info.TimeSync
+
+	// systemsetup需要管理员权限，输出格式为：Network Time: On
+	if output, err := runCommand(ctx, "systemsetup", "-getusingnetworktime"); err == nil {
+		timeSync.Enabled = strings.Contains(output, ": On")
+	} else if _, err := runCommand(ctx, "launchctl", "print", "system/com.apple.timed"); err == nil {
+		timeSync.Enabled = true
+	}
+
+	if config, err := os.ReadFile("/etc/ntp.conf"); err == nil {
+		for _, match := range ntpServerRegex.FindAllStringSubmatch(string(config), -1) {
+			timeSync.Servers = append(timeSync.Servers, match[1])
+		}
+	}
+	if len(timeSync.Servers) > 0 {
+		timeSync.Source = timeSync.Servers[0]
+	}
+
+	return analysis.MeasureClockOffset(timeSync)
+}
@@ -0,0 +1,228 @@
+package darwin
+
+import (
+	"bufio"
+	"context"
+	"log"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+var (
+	// scutilNCRegex 匹配scutil --nc list的输出行，例如：
+	// * (Connected)      6D6D2B0B-5A6C-4C2B-9F0E-3E1D0C2B1A00 VPN (com.wireguard.macos) "Office" [VPN:com.wireguard.macos]
+	scutilNCRegex = regexp.MustCompile(`^\*?\s*\(([^)]+)\)\s+([0-9A-Fa-f-]{36})\s+(.+?)\s+"([^"]*)"\s+\[([^\]]+)\]`)
+	// scutilNCInterfaceRegex、scutilNCRemoteRegex 匹配scutil --nc status输出中的隧道接口和服务器地址
+	scutilNCInterfaceRegex = regexp.MustCompile(`InterfaceName : (\S+)`)
+	scutilNCRemoteRegex    = regexp.MustCompile(`RemoteAddress : (\S+)`)
+)
+
+// tailscaleCommands tailscale命令行工具的位置，App Store版本不会安装到PATH中
+var tailscaleCommands = []string{"tailscale", "/Applications/Tailscale.app/Contents/MacOS/Tailscale"}
+
+// getVPNInfo 获取VPN信息：系统VPN和基于网络扩展的VPN（scutil --nc）、VPN客户端进程，
+// 以及AnyConnect、Tailscale、WireGuard等客户端命令行工具报告的连接状态
+func getVPNInfo(ctx context.Context, info *model.NetworkInfo) error {
+	// 初始化VPN信息
+	vpnInfo := model.VPNInfo{
+		IsConnected: false,
+		Services:    []string{},
+		Nodes:       []string{},
+	}
+
+	// 使用networksetup命令获取VPN服务列表
+	output, err := runCommand(ctx, "networksetup", "-listallnetworkservices")
+	if err != nil {
+		return err
+	}
+
+	// 检查是否有VPN服务
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.Contains(line, "VPN") || strings.Contains(line, "vpn") {
+			vpnServices := strings.TrimSpace(line)
+			vpnInfo.Services = append(vpnInfo.Services, vpnServices)
+		}
+	}
+
+	// 系统VPN和网络扩展VPN的连接状态
+	if scutilOutput, err := runCommand(ctx, "scutil", "--nc", "list"); err == nil {
+		getNetworkConnections(ctx, &vpnInfo, scutilOutput)
+	}
+
+	// VPN客户端进程和网络扩展进程
+	if err := analysis.DetectVPNProcesses(&vpnInfo); err != nil {
+		log.Printf("Error listing VPN processes: %v", err)
+	}
+
+	// 如果使用了Cisco AnyConnect或Secure Client，尝试获取其状态
+	for _, command := range []string{"/opt/cisco/secureclient/bin/vpn", "/opt/cisco/anyconnect/bin/vpn"} {
+		anyconnectOutput, err := runCommand(ctx, command, "state")
+		if err != nil {
+			continue
+		}
+		client := model.VPNClientInfo{Provider: "Cisco AnyConnect", Running: true, Source: analysis.VPNSourceCLI}
+		if strings.Contains(anyconnectOutput, "state: Connected") {
+			client.Connected = true
+
+			// 提取连接的服务器
+			serverRegex := regexp.MustCompile(`>> server\s*:\s*(.+)`)
+			if matches := serverRegex.FindStringSubmatch(anyconnectOutput); len(matches) > 1 {
+				client.Node = strings.TrimSpace(matches[1])
+				vpnInfo.Server = client.Node
+				vpnInfo.Nodes = append(vpnInfo.Nodes, client.Node)
+			}
+		}
+		analysis.AddVPNClient(&vpnInfo, client)
+		break
+	}
+
+	// 如果使用了OpenVPN，尝试获取其配置文件中的服务器
+	if psOutput, err := runCommand(ctx, "ps", "-ef"); err == nil {
+		getOpenVPNConfig(&vpnInfo, psOutput)
+	}
+
+	// Tailscale的连接状态和出口节点
+	for _, command := range tailscaleCommands {
+		statusOutput, err := runCommand(ctx, command, "status", "--json")
+		if err != nil {
+			continue
+		}
+		if client, err := analysis.ParseTailscaleStatus([]byte(statusOutput)); err == nil {
+			analysis.AddVPNClient(&vpnInfo, client)
+		}
+		break
+	}
+
+	// WireGuard命令行工具需要root权限才能读取隧道状态
+	if wgOutput, err := runCommand(ctx, "wg", "show"); err == nil {
+		for _, client := range analysis.ParseWireGuardShow(wgOutput) {
+			analysis.AddVPNClient(&vpnInfo, client)
+		}
+	}
+
+	analysis.SummarizeVPN(&vpnInfo)
+	info.VPN = vpnInfo
+
+	return nil
+}
+
+// getNetworkConnections 解析scutil --nc list的输出，已连接的服务再通过scutil --nc status获取隧道接口和服务器
+// 网络扩展VPN的类型为"VPN:扩展标识符"，据此识别客户端
+func getNetworkConnections(ctx context.Context, vpnInfo *model.VPNInfo, output string) {
+	for _, line := range strings.Split(output, "\n") {
+		matches := scutilNCRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		status, id, name, kind := matches[1], matches[2], matches[4], matches[5]
+		vpnInfo.NodeInfos = append(vpnInfo.NodeInfos, model.VPNNodeInfo{Name: name, ID: id, Status: status})
+
+		client := model.VPNClientInfo{
+			Provider: analysis.VPNProvider(kind + " " + name),
+			Node:     name,
+			Source:   "scutil",
+		}
+		if client.Provider == "" {
+			client.Provider = "系统VPN（" + strings.TrimPrefix(kind, "VPN:") + "）"
+		}
+		if status == "Connected" {
+			client.Running, client.Connected = true, true
+			vpnInfo.ActiveConnection = name
+			vpnInfo.ConnectionID = id
+			vpnInfo.Status = status
+			if statusOutput, err := runCommand(ctx, "scutil", "--nc", "status", id); err == nil {
+				if m := scutilNCInterfaceRegex.FindStringSubmatch(statusOutput); m != nil {
+					client.Interface = m[1]
+				}
+				if m := scutilNCRemoteRegex.FindStringSubmatch(statusOutput); m != nil {
+					client.Node = name + "（" + m[1] + "）"
+					vpnInfo.Server = m[1]
+				}
+			}
+		}
+		analysis.AddVPNClient(vpnInfo, client)
+	}
+}
+
+// getOpenVPNConfig 从openvpn进程的命令行参数中找到配置文件，并读取其中的服务器
+func getOpenVPNConfig(vpnInfo *model.VPNInfo, psOutput string) {
+	// 提取OpenVPN配置文件路径
+	openvpnRegex := regexp.MustCompile(`openvpn\s+--config\s+([^\s]+)`)
+	matches := openvpnRegex.FindStringSubmatch(psOutput)
+	if len(matches) < 2 {
+		return
+	}
+	vpnInfo.ConfigFile = matches[1]
+	client := model.VPNClientInfo{Provider: "OpenVPN", Running: true, Connected: true, Source: analysis.VPNSourceProcess}
+
+	// 尝试从配置文件中提取服务器信息
+	if configContent, err := os.ReadFile(matches[1]); err == nil {
+		serverRegex := regexp.MustCompile(`remote\s+([^\s]+)`)
+		for _, match := range serverRegex.FindAllSubmatch(configContent, -1) {
+			server := string(match[1])
+			vpnInfo.Nodes = append(vpnInfo.Nodes, server)
+			if client.Node == "" {
+				client.Node = server
+			}
+		}
+	}
+	analysis.AddVPNClient(vpnInfo, client)
+}
+
+// splitTunnelRoutes 将netstat -nr的IPv4路由转换为分离隧道分析使用的格式
+// 跳过绑定到接口的路由（I标志，只用于指定了接口的连接）和链路层的邻居条目（L标志）
+func splitTunnelRoutes(entries []model.RouteEntry) []analysis.Route {
+	var routes []analysis.Route
+	for _, entry := range entries {
+		if strings.ContainsAny(entry.Flags, "IL") {
+			continue
+		}
+		prefix := parseRouteDestination(entry.Destination, strings.Contains(entry.Flags, "H"))
+		if prefix == nil {
+			continue
+		}
+		routes = append(routes, analysis.Route{Prefix: prefix, Gateway: entry.Gateway, Interface: entry.Interface})
+	}
+	return routes
+}
+
+// parseRouteDestination 解析netstat的目标地址，netstat省略了末尾为0的字节，例如"10/8"、"192.168.1"、"128.0/1"
+// 没有前缀长度时按给出的字节数推算，主机路由为/32
+func parseRouteDestination(destination string, host bool) *net.IPNet {
+	if destination == "default" {
+		return &net.IPNet{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(0, 32)}
+	}
+	address, prefixText, hasPrefix := strings.Cut(destination, "/")
+	octets := strings.Split(address, ".")
+	if len(octets) > 4 {
+		return nil
+	}
+	prefixLen := len(octets) * 8
+	if host {
+		prefixLen = 32
+	}
+	if hasPrefix {
+		n, err := strconv.Atoi(prefixText)
+		if err != nil || n < 0 || n > 32 {
+			return nil
+		}
+		prefixLen = n
+	}
+	for len(octets) < 4 {
+		octets = append(octets, "0")
+	}
+	ip := net.ParseIP(strings.Join(octets, ".")).To4()
+	if ip == nil {
+		return nil
+	}
+	mask := net.CIDRMask(prefixLen, 32)
+	return &net.IPNet{IP: ip.Mask(mask), Mask: mask}
+}
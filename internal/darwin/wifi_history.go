@@ -0,0 +1,84 @@
+package darwin
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// wifiLogPredicate 从统一日志中筛选airportd和WiFi驱动记录的链路断开、漫游和链路建立消息
+const wifiLogPredicate = `(process == "airportd" OR process == "kernel" OR subsystem == "com.apple.wifi") AND ` +
+	`(eventMessage CONTAINS[c] "link down" OR eventMessage CONTAINS[c] "link up" OR eventMessage CONTAINS[c] "roamed" OR ` +
+	`eventMessage CONTAINS[c] "roam success" OR eventMessage CONTAINS[c] "disassoc" OR eventMessage CONTAINS[c] "deauth")`
+
+var (
+	// wifiLogTimeRegex 匹配syslog格式日志行开头的时间，例如"2024-05-01 10:00:00.123456+0800"
+	wifiLogTimeRegex = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})`)
+	// wifiReasonRegex 匹配消息中的原因代码，例如"reason=8"或"Reason: 3"
+	wifiReasonRegex = regexp.MustCompile(`(?i)reason(?:\s*code)?\s*[=:]?\s*(\d+)`)
+	// wifiBSSIDRegex 匹配消息中的基站MAC地址
+	wifiBSSIDRegex = regexp.MustCompile(`(?i)\b([0-9a-f]{1,2}(?::[0-9a-f]{1,2}){5})\b`)
+)
+
+// getWiFiHistory 从统一日志中读取最近24小时的WiFi连接、漫游和断开事件
+func getWiFiHistory(ctx context.Context, info *model.NetworkInfo) error {
+	since := time.Now().Add(-analysis.WiFiHistoryWindow)
+	output, err := runCommand(ctx, "log", "show", "--style", "syslog", "--last", "24h", "--predicate", wifiLogPredicate)
+	if err != nil {
+		return err
+	}
+	info.WiFiHistory = analysis.SummarizeWiFiHistory(since, parseWiFiLog(output))
+	return nil
+}
+
+// parseWiFiLog 将日志行归类为WiFi事件，airportd和驱动在同一秒内记录的同类事件只保留一次
+func parseWiFiLog(output string) []model.WiFiEventInfo {
+	var events []model.WiFiEventInfo
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		matches := wifiLogTimeRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		eventType := classifyWiFiLog(line)
+		if eventType == "" {
+			continue
+		}
+		key := matches[1] + eventType
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		event := model.WiFiEventInfo{Time: matches[1], Type: eventType}
+		if match := wifiReasonRegex.FindStringSubmatch(line); match != nil {
+			event.ReasonCode, _ = strconv.Atoi(match[1])
+			event.Reason = analysis.WiFiReason(event.ReasonCode)
+		}
+		// 漫游消息中同时有原基站和新基站，取最后一个
+		if all := wifiBSSIDRegex.FindAllStringSubmatch(line[len(matches[0]):], -1); len(all) > 0 {
+			event.BSSID = strings.ToLower(all[len(all)-1][1])
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// classifyWiFiLog 根据日志消息的关键字判断事件类型，无法归类时返回空字符串
+func classifyWiFiLog(line string) string {
+	message := strings.ToLower(line)
+	switch {
+	case strings.Contains(message, "roamed") || strings.Contains(message, "roam success"):
+		return analysis.WiFiEventRoam
+	case strings.Contains(message, "link down") || strings.Contains(message, "disassoc") || strings.Contains(message, "deauth"):
+		return analysis.WiFiEventDisconnect
+	case strings.Contains(message, "link up"):
+		return analysis.WiFiEventConnect
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package darwin
+
+import (
+	"bufio"
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// channelWidthRegex 匹配信道描述中的信道宽度，例如"80MHz"
+var channelWidthRegex = regexp.MustCompile(`(\d+)MHz`)
+
+// ScanWiFi 扫描附近的无线网络并评估当前信道的拥挤程度，需要先获取当前WiFi信息
+// system_profiler会触发一次无线扫描，耗时数秒，只在使用--scan-wifi参数时调用
+func ScanWiFi(ctx context.Context, info *model.NetworkInfo) error {
+	output, err := runCommand(ctx, "system_profiler", "SPAirPortDataType")
+	if err != nil {
+		return err
+	}
+	info.WiFiScan = analysis.AnalyzeWiFiScan(info.WiFi, parseOtherNetworks(output))
+	return nil
+}
+
+// parseOtherNetworks 解析system_profiler输出中“Other Local Wi-Fi Networks”部分，例如：
+//
+//	Other Local Wi-Fi Networks:
+//	  Office:
+//	    PHY Mode: 802.11a/n/ac/ax
+//	    Channel: 36 (5GHz, 80MHz)
+//	    Security: WPA2 Personal
+//	    Signal / Noise: -61 dBm / -92 dBm
+func parseOtherNetworks(output string) []model.WiFiScanResultInfo {
+	var networks []model.WiFiScanResultInfo
+	var current *model.WiFiScanResultInfo
+	sectionIndent := -1
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		if trimmed == "Other Local Wi-Fi Networks:" {
+			sectionIndent = indent
+			continue
+		}
+		if sectionIndent < 0 {
+			continue
+		}
+		// 缩进回到该部分标题的层级表示该部分结束
+		if indent <= sectionIndent {
+			sectionIndent = -1
+			current = nil
+			continue
+		}
+
+		if strings.HasSuffix(trimmed, ":") {
+			networks = append(networks, model.WiFiScanResultInfo{SSID: strings.TrimSuffix(trimmed, ":")})
+			current = &networks[len(networks)-1]
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		parts := strings.SplitN(trimmed, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(parts[1])
+		switch strings.TrimSpace(parts[0]) {
+		case "Channel":
+			current.Channel, current.Band, _ = parseAirportChannel(value)
+		case "Security":
+			current.Security = value
+		case "Signal / Noise":
+			signal := strings.TrimSpace(strings.SplitN(value, "/", 2)[0])
+			current.RSSI, _ = strconv.Atoi(strings.TrimSuffix(signal, " dBm"))
+		}
+	}
+	return networks
+}
+
+// parseAirportChannel 解析system_profiler的信道描述，返回信道号、频段和信道宽度（MHz）
+// 新版本的格式为"36 (5GHz, 80MHz)"，旧版本为"36,80"或"36,+1"（+1和-1表示40MHz）
+// 描述中没有信道宽度时返回0，表示宽度未知
+func parseAirportChannel(value string) (int, string, int) {
+	end := strings.IndexFunc(value, func(r rune) bool { return r < '0' || r > '9' })
+	if end < 0 {
+		end = len(value)
+	}
+	channel, _ := strconv.Atoi(value[:end])
+
+	band := ""
+	switch {
+	case strings.Contains(value, "6GHz"):
+		band = analysis.Band6GHz
+	case strings.Contains(value, "5GHz"):
+		band = analysis.Band5GHz
+	case strings.Contains(value, "2GHz"):
+		band = analysis.Band24GHz
+	}
+
+	width := 0
+	if match := channelWidthRegex.FindStringSubmatch(value); match != nil {
+		width, _ = strconv.Atoi(match[1])
+	} else if i := strings.Index(value, ","); i >= 0 {
+		switch suffix := strings.TrimSpace(value[i+1:]); suffix {
+		case "+1", "-1":
+			width = 40
+		default:
+			width, _ = strconv.Atoi(suffix)
+		}
+	}
+	return channel, band, width
+}
@@ -0,0 +1,90 @@
+package darwin
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/internal/workload"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// vmrunPath VMware Fusion自带的vmrun工具
+const vmrunPath = "/Applications/VMware Fusion.app/Contents/Library/vmrun"
+
+// parallelsVM 表示prlctl list --info --json输出的虚拟机
+type parallelsVM struct {
+	Name     string `json:"Name"`
+	State    string `json:"State"`
+	Home     string `json:"Home"`
+	Hardware struct {
+		CPU struct {
+			CPUs int `json:"cpus"`
+		} `json:"cpu"`
+		Memory struct {
+			Size string `json:"size"` // 如"8192Mb"
+		} `json:"memory"`
+	} `json:"Hardware"`
+}
+
+// getWorkloads 获取运行中的Docker/Podman容器以及Parallels、VMware虚拟机
+func getWorkloads(ctx context.Context, info *model.SystemInfo) error {
+	collectors := []struct {
+		name    string
+		collect func(context.Context) ([]model.WorkloadInfo, error)
+	}{
+		{"docker", workload.DockerContainers},
+		{"podman", workload.PodmanContainers},
+		{"parallels", getParallelsVMs},
+		{"vmware", func(ctx context.Context) ([]model.WorkloadInfo, error) { return workload.VMwareVMs(ctx, vmrunPath) }},
+	}
+
+	for _, collector := range collectors {
+		workloads, err := collector.collect(ctx)
+		if err != nil {
+			log.Printf("Error getting %s workloads: %v", collector.name, err)
+		}
+		info.Workloads = append(info.Workloads, workloads...)
+	}
+
+	return nil
+}
+
+// getParallelsVMs 获取运行中的Parallels Desktop虚拟机
+func getParallelsVMs(ctx context.Context) ([]model.WorkloadInfo, error) {
+	if _, err := exec.LookPath("prlctl"); err != nil {
+		return nil, nil
+	}
+	output, err := runCommand(ctx, "prlctl", "list", "--info", "--json")
+	if err != nil {
+		return nil, err
+	}
+
+	var vms []parallelsVM
+	if err := json.Unmarshal([]byte(output), &vms); err != nil {
+		return nil, err
+	}
+
+	var workloads []model.WorkloadInfo
+	for _, vm := range vms {
+		if vm.State != "running" {
+			continue
+		}
+		item := model.WorkloadInfo{
+			Name:  vm.Name,
+			Kind:  "Parallels",
+			State: "运行中",
+			Image: vm.Home,
+			CPUs:  float64(vm.Hardware.CPU.CPUs),
+		}
+		if size, err := strconv.ParseUint(strings.TrimSuffix(vm.Hardware.Memory.Size, "Mb"), 10, 64); err == nil {
+			item.Memory = size * 1024 * 1024
+		}
+		workloads = append(workloads, item)
+	}
+
+	return workloads, nil
+}
@@ -0,0 +1,20 @@
+// Package device 计算设备ID，并向设备管理服务器注册设备
+package device
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// ID 根据硬件UUID和盐计算设备ID（SHA-256的前16字节，32个十六进制字符）
+// 同一台设备重装系统后不变，报告中不直接暴露硬件UUID；不同组织使用不同的盐时同一台设备的ID不同
+// 没有硬件UUID时返回空字符串
+func ID(hardwareUUID, salt string) string {
+	hardwareUUID = strings.ToUpper(strings.TrimSpace(hardwareUUID))
+	if hardwareUUID == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(salt + ":" + hardwareUUID))
+	return hex.EncodeToString(sum[:16])
+}
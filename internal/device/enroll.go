@@ -0,0 +1,128 @@
+package device
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/tlsconfig"
+)
+
+// enrollTimeout 注册请求的超时
+const enrollTimeout = 30 * time.Second
+
+// Registration 表示注册请求中的设备信息
+type Registration struct {
+	DeviceID   string `json:"device_id"`
+	Hostname   string `json:"hostname"`
+	OS         string `json:"os"`
+	Arch       string `json:"arch"`
+	CommandKey string `json:"command_key,omitempty"` // 本机签名命令执行结果的Ed25519公钥（base64），配置了commands.public_keys时上报
+}
+
+// Enrollment 表示注册结果，保存在状态文件中，之后每次运行都会使用
+type Enrollment struct {
+	DeviceID   string          `json:"device_id"`
+	Token      string          `json:"token"`            // 服务器签发的设备令牌，没有配置push.token、commands.token时作为推送快照和长轮询命令的Bearer令牌
+	Config     json.RawMessage `json:"config,omitempty"` // 服务器下发的配置，叠加在本地配置文件之上
+	EnrolledAt time.Time       `json:"enrolled_at"`
+}
+
+// DefaultStatePath 返回保存注册结果的默认路径
+func DefaultStatePath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "SysSpector", "enrollment.json"), nil
+}
+
+// StatePath 返回配置的注册结果路径，没有配置时使用默认路径
+func StatePath(cfg config.DeviceConfig) (string, error) {
+	if cfg.StatePath != "" {
+		return cfg.StatePath, nil
+	}
+	return DefaultStatePath()
+}
+
+// LoadEnrollment 读取保存的注册结果，没有注册过时返回的错误满足os.IsNotExist
+func LoadEnrollment(path string) (*Enrollment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var enrollment Enrollment
+	if err := json.Unmarshal(data, &enrollment); err != nil {
+		return nil, fmt.Errorf("parse %s: %v", path, err)
+	}
+	return &enrollment, nil
+}
+
+// Save 保存注册结果，文件中有设备令牌，只有当前用户可以读取
+func (e *Enrollment) Save(path string) error {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Enroll 向设备管理服务器注册设备：以JSON格式POST设备ID、主机名和操作系统，
+// 服务器返回{"token": "...", "config": {...}}，token为设备令牌，config为下发的配置（格式与配置文件相同，可以省略）
+func Enroll(ctx context.Context, cfg config.DeviceConfig, reg Registration) (*Enrollment, error) {
+	if cfg.EnrollURL == "" {
+		return nil, errors.New("device enroll_url is not configured")
+	}
+	tlsConfig, err := tlsconfig.Client(cfg.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("load TLS config: %v", err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	client := &http.Client{Transport: transport, Timeout: enrollTimeout}
+
+	body, err := json.Marshal(reg)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.EnrollURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.EnrollToken != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.EnrollToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("enrollment server returned HTTP %d", resp.StatusCode)
+	}
+
+	var enrollment Enrollment
+	if err := json.NewDecoder(resp.Body).Decode(&enrollment); err != nil {
+		return nil, fmt.Errorf("parse enrollment response: %v", err)
+	}
+	if enrollment.Token == "" {
+		return nil, errors.New("enrollment response has no token")
+	}
+	enrollment.DeviceID = reg.DeviceID
+	enrollment.EnrolledAt = time.Now()
+	return &enrollment, nil
+}
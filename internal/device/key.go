@@ -0,0 +1,63 @@
+package device
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+)
+
+// DefaultKeyPath 返回本机签名命令执行结果的私钥的默认路径
+func DefaultKeyPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "SysSpector", "command.key"), nil
+}
+
+// KeyPath 返回配置的命令签名私钥路径，没有配置时使用默认路径
+func KeyPath(cfg config.CommandConfig) (string, error) {
+	if cfg.KeyPath != "" {
+		return cfg.KeyPath, nil
+	}
+	return DefaultKeyPath()
+}
+
+// LoadOrCreateKey 读取本机的Ed25519私钥（base64编码的种子），文件不存在时生成一个新的私钥并保存，
+// 只有当前用户可以读取。每台设备使用自己的私钥，服务器通过注册时上报的公钥验证执行结果
+func LoadOrCreateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("parse %s: invalid Ed25519 key", path)
+		}
+		return ed25519.NewKeyFromSeed(seed), nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(key.Seed()) + "\n"
+	if err := os.WriteFile(path, []byte(encoded), 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// PublicKey 返回私钥对应的公钥（base64），注册时上报给服务器
+func PublicKey(key ed25519.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+}
@@ -0,0 +1,174 @@
+package diskscan
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// largestDirsDepth 统计最大目录时只记录根目录下两层以内的目录
+const largestDirsDepth = 2
+
+// largestDirsCount 报告的最大目录数量
+const largestDirsCount = 10
+
+// SpaceHog 表示已知的空间占用大户，Path支持通配符
+type SpaceHog struct {
+	Name string
+	Path string
+	Hint string
+}
+
+// Scan 统计root下占用最大的目录以及已知空间占用大户的实际大小
+// 扫描可能需要数分钟，只在显式要求时调用
+func Scan(root string, hogs []SpaceHog) model.DiskScanInfo {
+	start := time.Now()
+	result := model.DiskScanInfo{Root: root}
+
+	var dirs []model.DiskUsageItem
+	for path, size := range walkSizes(root) {
+		if path == root || depth(root, path) > largestDirsDepth {
+			continue
+		}
+		dirs = append(dirs, model.DiskUsageItem{
+			Name: filepath.Base(path),
+			Path: path,
+			Size: size,
+		})
+	}
+	result.LargestDirs = largestDirs(dirs, largestDirsCount)
+
+	for _, hog := range hogs {
+		matches, _ := filepath.Glob(hog.Path)
+		for _, path := range matches {
+			size := pathSize(path)
+			if size == 0 {
+				continue
+			}
+			name := hog.Name
+			if len(matches) > 1 {
+				name += "（" + filepath.Base(path) + "）"
+			}
+			result.SpaceHogs = append(result.SpaceHogs, model.DiskUsageItem{
+				Name: name,
+				Path: path,
+				Size: size,
+				Hint: hog.Hint,
+			})
+		}
+	}
+	sortBySize(result.SpaceHogs)
+
+	result.Duration = time.Since(start).Round(time.Second).String()
+	return result
+}
+
+// largestDirs 返回占用最大的count个目录，父目录已经列出时不再列出其子目录，
+// 子目录的大小已经计入父目录，同时列出会重复计算
+func largestDirs(dirs []model.DiskUsageItem, count int) []model.DiskUsageItem {
+	// 大小相同时父目录排在前面，例如只包含一个子目录的目录
+	sort.Slice(dirs, func(i, j int) bool {
+		if dirs[i].Size != dirs[j].Size {
+			return dirs[i].Size > dirs[j].Size
+		}
+		return len(dirs[i].Path) < len(dirs[j].Path)
+	})
+
+	var largest []model.DiskUsageItem
+	for _, dir := range dirs {
+		if len(largest) == count {
+			break
+		}
+		listed := false
+		for _, parent := range largest {
+			if strings.HasPrefix(dir.Path, parent.Path+string(filepath.Separator)) {
+				listed = true
+				break
+			}
+		}
+		if !listed {
+			largest = append(largest, dir)
+		}
+	}
+	return largest
+}
+
+// walkSizes 遍历root，返回root下两层以内每个目录（含子目录）的实际占用空间
+func walkSizes(root string) map[string]uint64 {
+	sizes := make(map[string]uint64)
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// 跳过无权限访问的目录
+			return nil
+		}
+		if d.IsDir() {
+			if depth(root, path) <= largestDirsDepth {
+				sizes[path] = 0
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			return nil
+		}
+
+		size := allocatedSize(info)
+		for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+			if _, ok := sizes[dir]; ok {
+				sizes[dir] += size
+			}
+			if dir == root || len(dir) < len(root) {
+				break
+			}
+		}
+		return nil
+	})
+	return sizes
+}
+
+// pathSize 计算文件或目录的实际占用空间
+func pathSize(path string) uint64 {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0
+	}
+	if info.Mode().IsRegular() {
+		return allocatedSize(info)
+	}
+	if !info.IsDir() {
+		return 0
+	}
+
+	var total uint64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+			total += allocatedSize(info)
+		}
+		return nil
+	})
+	return total
+}
+
+// depth 返回path相对root的目录层级
+func depth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// sortBySize 按占用空间从大到小排序
+func sortBySize(items []model.DiskUsageItem) {
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Size > items[j].Size
+	})
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+// +build !windows
+
+package diskscan
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// allocatedSize 返回文件实际分配的磁盘空间，Docker.raw等稀疏文件的逻辑大小远大于实际占用
+func allocatedSize(info fs.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Blocks) * 512
+	}
+	return uint64(info.Size())
+}
@@ -0,0 +1,25 @@
+//go:build windows
+// +build windows
+
+package diskscan
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// 仅联机文件（OneDrive文件按需下载）的属性，这类文件不占用本地空间
+const (
+	fileAttributeOffline            = 0x1000
+	fileAttributeRecallOnDataAccess = 0x400000
+)
+
+// allocatedSize 返回文件占用的本地磁盘空间，仅联机文件按0计算
+func allocatedSize(info fs.FileInfo) uint64 {
+	if data, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		if data.FileAttributes&(fileAttributeOffline|fileAttributeRecallOnDataAccess) != 0 {
+			return 0
+		}
+	}
+	return uint64(info.Size())
+}
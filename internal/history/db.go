@@ -0,0 +1,268 @@
+package history
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// 指标记录的粒度（秒）
+const (
+	ResolutionRaw  = 0    // 原始采样
+	ResolutionHour = 3600 // 每小时平均值
+)
+
+// ErrNotFound 表示指定时间之前没有保存快照
+var ErrNotFound = errors.New("no snapshot found")
+
+const schema = `
+CREATE TABLE IF NOT EXISTS snapshots (
+	time         INTEGER PRIMARY KEY,
+	health_score INTEGER NOT NULL,
+	data         TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS metrics (
+	time           INTEGER NOT NULL,
+	resolution     INTEGER NOT NULL,
+	cpu_percent    REAL NOT NULL,
+	memory_percent REAL NOT NULL,
+	memory_used    INTEGER NOT NULL,
+	rx_rate        REAL NOT NULL,
+	tx_rate        REAL NOT NULL,
+	rssi           INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS metrics_time ON metrics (time);
+CREATE TABLE IF NOT EXISTS disk_samples (
+	time        INTEGER NOT NULL,
+	mount_point TEXT NOT NULL,
+	total       INTEGER NOT NULL,
+	used        INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS disk_samples_time ON disk_samples (time);
+`
+
+// Point 表示一条动态指标记录，降采样的记录为该小时内的平均值，时间为该小时的开始
+type Point struct {
+	Time          time.Time
+	Resolution    int // ResolutionRaw或ResolutionHour
+	CPUPercent    float64
+	MemoryPercent float64
+	MemoryUsed    uint64
+	RxRate        float64 // 所有网络接口的接收速率合计（字节/秒）
+	TxRate        float64 // 所有网络接口的发送速率合计（字节/秒）
+	RSSI          int     // WiFi信号强度（dBm），没有连接WiFi时为0
+}
+
+// DiskSample 表示一次磁盘使用采样，用于计算磁盘的增长趋势
+type DiskSample struct {
+	Time       time.Time
+	MountPoint string
+	Total      uint64
+	Used       uint64
+}
+
+// SnapshotRecord 表示一个已保存的快照
+type SnapshotRecord struct {
+	Time        time.Time
+	HealthScore int // 网络健康评分
+}
+
+// DB 表示serve模式下保存快照和动态指标的SQLite数据库，
+// 超过原始数据保留期限的指标降采样为每小时平均值
+type DB struct {
+	db *sql.DB
+}
+
+// DefaultDBPath 返回历史数据库的默认路径
+func DefaultDBPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "SysSpector", "history.db"), nil
+}
+
+// OpenDB 打开历史数据库，不存在时创建
+func OpenDB(path string) (*DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	// WAL模式下查询命令可以在serve写入的同时读取
+	db, err := sql.Open("sqlite3", "file:"+path+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &DB{db: db}, nil
+}
+
+// Close 关闭数据库
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// AddSnapshot 保存一个快照，同一秒内的快照只保留最后一个
+func (d *DB) AddSnapshot(info model.SystemInfo, collectedAt time.Time) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	_, err = d.db.Exec("INSERT OR REPLACE INTO snapshots (time, health_score, data) VALUES (?, ?, ?)",
+		collectedAt.Unix(), info.Network.HealthScore.Score, string(data))
+	return err
+}
+
+// AddPoint 保存一条原始指标记录
+func (d *DB) AddPoint(p Point) error {
+	_, err := d.db.Exec("INSERT INTO metrics (time, resolution, cpu_percent, memory_percent, memory_used, rx_rate, tx_rate, rssi) "+
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		p.Time.Unix(), ResolutionRaw, p.CPUPercent, p.MemoryPercent, p.MemoryUsed, p.RxRate, p.TxRate, p.RSSI)
+	return err
+}
+
+// AddDiskSamples 在一个事务中保存同一次采集的各分区的磁盘使用采样
+func (d *DB) AddDiskSamples(samples ...DiskSample) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, sample := range samples {
+		if _, err := tx.Exec("INSERT INTO disk_samples (time, mount_point, total, used) VALUES (?, ?, ?, ?)",
+			sample.Time.Unix(), sample.MountPoint, sample.Total, sample.Used); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DiskSamples 按时间顺序返回from之后的磁盘使用采样
+func (d *DB) DiskSamples(from time.Time) ([]DiskSample, error) {
+	rows, err := d.db.Query("SELECT time, mount_point, total, used FROM disk_samples WHERE time >= ? ORDER BY time", from.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []DiskSample
+	for rows.Next() {
+		var sample DiskSample
+		var unix int64
+		if err := rows.Scan(&unix, &sample.MountPoint, &sample.Total, &sample.Used); err != nil {
+			return nil, err
+		}
+		sample.Time = time.Unix(unix, 0)
+		samples = append(samples, sample)
+	}
+	return samples, rows.Err()
+}
+
+// Points 按时间顺序返回[from, to]内的指标记录，包括原始采样和降采样的记录
+func (d *DB) Points(from, to time.Time) ([]Point, error) {
+	rows, err := d.db.Query("SELECT time, resolution, cpu_percent, memory_percent, memory_used, rx_rate, tx_rate, rssi "+
+		"FROM metrics WHERE time BETWEEN ? AND ? ORDER BY time, resolution DESC", from.Unix(), to.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := make([]Point, 0)
+	for rows.Next() {
+		var p Point
+		var unix int64
+		if err := rows.Scan(&unix, &p.Resolution, &p.CPUPercent, &p.MemoryPercent, &p.MemoryUsed, &p.RxRate, &p.TxRate, &p.RSSI); err != nil {
+			return nil, err
+		}
+		p.Time = time.Unix(unix, 0)
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// Snapshots 按时间顺序列出[from, to]内保存的快照
+func (d *DB) Snapshots(from, to time.Time) ([]SnapshotRecord, error) {
+	rows, err := d.db.Query("SELECT time, health_score FROM snapshots WHERE time BETWEEN ? AND ? ORDER BY time", from.Unix(), to.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make([]SnapshotRecord, 0)
+	for rows.Next() {
+		var record SnapshotRecord
+		var unix int64
+		if err := rows.Scan(&unix, &record.HealthScore); err != nil {
+			return nil, err
+		}
+		record.Time = time.Unix(unix, 0)
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// Snapshot 返回at及之前最近的一个快照及其采集时间，没有时返回ErrNotFound
+func (d *DB) Snapshot(at time.Time) (model.SystemInfo, time.Time, error) {
+	var info model.SystemInfo
+	var unix int64
+	var data string
+	err := d.db.QueryRow("SELECT time, data FROM snapshots WHERE time <= ? ORDER BY time DESC LIMIT 1", at.Unix()).Scan(&unix, &data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return info, time.Time{}, ErrNotFound
+	}
+	if err != nil {
+		return info, time.Time{}, err
+	}
+	if err := json.Unmarshal([]byte(data), &info); err != nil {
+		return info, time.Time{}, err
+	}
+	return info, time.Unix(unix, 0), nil
+}
+
+// Retention 表示各类数据的保留期限
+type Retention struct {
+	Snapshots time.Duration // 快照
+	Raw       time.Duration // 原始指标，超过后降采样为每小时平均值
+	Metrics   time.Duration // 降采样后的指标和磁盘使用采样
+}
+
+// Compact 将超过原始数据保留期限的完整小时内的指标降采样为每小时平均值，并删除超过保留期限的数据
+// WiFi信号强度只对连接了WiFi的采样求平均
+func (d *DB) Compact(now time.Time, retention Retention) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	cutoff := now.Add(-retention.Raw).Truncate(time.Hour).Unix()
+	statements := []struct {
+		query string
+		args  []interface{}
+	}{
+		{"INSERT INTO metrics (time, resolution, cpu_percent, memory_percent, memory_used, rx_rate, tx_rate, rssi) " +
+			"SELECT time / 3600 * 3600, ?, AVG(cpu_percent), AVG(memory_percent), CAST(AVG(memory_used) AS INTEGER), " +
+			"AVG(rx_rate), AVG(tx_rate), COALESCE(CAST(ROUND(AVG(NULLIF(rssi, 0))) AS INTEGER), 0) " +
+			"FROM metrics WHERE resolution = ? AND time < ? GROUP BY time / 3600",
+			[]interface{}{ResolutionHour, ResolutionRaw, cutoff}},
+		{"DELETE FROM metrics WHERE resolution = ? AND time < ?", []interface{}{ResolutionRaw, cutoff}},
+		{"DELETE FROM metrics WHERE time < ?", []interface{}{now.Add(-retention.Metrics).Unix()}},
+		{"DELETE FROM disk_samples WHERE time < ?", []interface{}{now.Add(-retention.Metrics).Unix()}},
+		{"DELETE FROM snapshots WHERE time < ?", []interface{}{now.Add(-retention.Snapshots).Unix()}},
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement.query, statement.args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,80 @@
+package ifaces
+
+import (
+	"net"
+	"sort"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+	psnet "github.com/shirou/gopsutil/v3/net"
+)
+
+// Rates 间隔window两次读取各网络接口的累计收发字节数，计算每个接口的接收和发送速率，
+// 忽略回环接口和没有任何流量的接口，按收发合计速率从大到小排序
+func Rates(window time.Duration) ([]model.InterfaceTrafficInfo, error) {
+	first, err := psnet.IOCounters(true)
+	if err != nil {
+		return nil, err
+	}
+	time.Sleep(window)
+	second, err := psnet.IOCounters(true)
+	if err != nil {
+		return nil, err
+	}
+
+	loopback := loopbackNames()
+	previous := make(map[string]psnet.IOCountersStat)
+	for _, counter := range first {
+		previous[counter.Name] = counter
+	}
+
+	var rates []model.InterfaceTrafficInfo
+	for _, counter := range second {
+		before, ok := previous[counter.Name]
+		if !ok || loopback[counter.Name] || counter.BytesRecv+counter.BytesSent == 0 {
+			continue
+		}
+		rate := model.InterfaceTrafficInfo{
+			Name:      counter.Name,
+			BytesRecv: counter.BytesRecv,
+			BytesSent: counter.BytesSent,
+		}
+		// 计数器可能在两次采样之间被重置
+		if counter.BytesRecv >= before.BytesRecv {
+			rate.RxRate = float64(counter.BytesRecv-before.BytesRecv) / window.Seconds()
+		}
+		if counter.BytesSent >= before.BytesSent {
+			rate.TxRate = float64(counter.BytesSent-before.BytesSent) / window.Seconds()
+		}
+		rates = append(rates, rate)
+	}
+
+	sort.SliceStable(rates, func(i, j int) bool {
+		return rates[i].RxRate+rates[i].TxRate > rates[j].RxRate+rates[j].TxRate
+	})
+	return rates, nil
+}
+
+// Total 返回所有接口的接收和发送速率合计（字节/秒）
+func Total(rates []model.InterfaceTrafficInfo) (rx, tx float64) {
+	for _, rate := range rates {
+		rx += rate.RxRate
+		tx += rate.TxRate
+	}
+	return rx, tx
+}
+
+// loopbackNames 返回所有回环接口的名称
+func loopbackNames() map[string]bool {
+	names := make(map[string]bool)
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return names
+	}
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagLoopback != 0 {
+			names[iface.Name] = true
+		}
+	}
+	return names
+}
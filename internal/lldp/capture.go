@@ -0,0 +1,123 @@
+package lldp
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// 抓包文件格式的魔数
+const (
+	pcapMagic       = 0xa1b2c3d4 // pcap，微秒时间戳
+	pcapMagicNano   = 0xa1b23c4d // pcap，纳秒时间戳
+	pcapngBlockSHB  = 0x0a0d0d0a // pcapng的节头块
+	pcapngByteMagic = 0x1a2b3c4d // pcapng节头块中用于判断字节序的魔数
+)
+
+// ErrCaptureFormat 抓包数据不是pcap或pcapng格式
+var ErrCaptureFormat = errors.New("unknown capture format")
+
+// ReadFrames 读取pcap（tcpdump -w）或pcapng（pktmon etl2pcap）格式的抓包数据中的所有以太网帧
+// 抓包被中途终止时最后一个不完整的帧会被忽略
+func ReadFrames(data []byte) ([][]byte, error) {
+	if len(data) < 4 {
+		if len(data) == 0 {
+			return nil, nil
+		}
+		return nil, ErrCaptureFormat
+	}
+	if binary.LittleEndian.Uint32(data) == pcapngBlockSHB {
+		return readPcapng(data)
+	}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		if magic := order.Uint32(data); magic == pcapMagic || magic == pcapMagicNano {
+			return readPcap(data, order), nil
+		}
+	}
+	return nil, ErrCaptureFormat
+}
+
+// readPcap 读取pcap格式：24字节文件头，每个数据包为16字节记录头（时间戳、抓取长度、原始长度）和数据
+func readPcap(data []byte, order binary.ByteOrder) [][]byte {
+	var frames [][]byte
+	if len(data) < 24 {
+		return nil
+	}
+	data = data[24:]
+	for len(data) >= 16 {
+		length := int(order.Uint32(data[8:12]))
+		if len(data) < 16+length {
+			break
+		}
+		frames = append(frames, data[16:16+length])
+		data = data[16+length:]
+	}
+	return frames
+}
+
+// readPcapng 读取pcapng格式中的增强数据包块（类型6）和简单数据包块（类型3），
+// 每个块为类型、总长度、内容和重复的总长度，字节序由节头块中的魔数决定
+func readPcapng(data []byte) ([][]byte, error) {
+	var frames [][]byte
+	var order binary.ByteOrder = binary.LittleEndian
+	for len(data) >= 12 {
+		// 节头块的类型值在两种字节序下相同
+		blockType := order.Uint32(data[:4])
+		if blockType == pcapngBlockSHB {
+			switch uint32(pcapngByteMagic) {
+			case binary.LittleEndian.Uint32(data[8:12]):
+				order = binary.LittleEndian
+			case binary.BigEndian.Uint32(data[8:12]):
+				order = binary.BigEndian
+			default:
+				return frames, ErrCaptureFormat
+			}
+		}
+		length := int(order.Uint32(data[4:8]))
+		if length < 12 || length > len(data) {
+			break
+		}
+		body := data[8 : length-4]
+		switch blockType {
+		case 6: // 接口ID、时间戳高低位、抓取长度、原始长度，之后为数据
+			if len(body) >= 20 {
+				captured := int(order.Uint32(body[12:16]))
+				if captured <= len(body)-20 {
+					frames = append(frames, body[20:20+captured])
+				}
+			}
+		case 3: // 原始长度，之后为数据
+			if len(body) >= 4 {
+				captured := int(order.Uint32(body[:4]))
+				if captured <= len(body)-4 {
+					frames = append(frames, body[4:4+captured])
+				}
+			}
+		}
+		data = data[length:]
+	}
+	return frames, nil
+}
+
+// ParseCapture 读取抓包数据中的LLDP和CDP报文，同一台交换机同一端口的多次通告只保留最后一次
+func ParseCapture(data []byte) ([]model.NeighborInfo, error) {
+	frames, err := ReadFrames(data)
+	var neighbors []model.NeighborInfo
+	for _, frame := range frames {
+		neighbor, ok := ParseFrame(frame)
+		if !ok {
+			continue
+		}
+		replaced := false
+		for i := range neighbors {
+			if neighbors[i].Protocol == neighbor.Protocol && neighbors[i].ChassisID == neighbor.ChassisID && neighbors[i].PortID == neighbor.PortID {
+				neighbors[i], replaced = neighbor, true
+			}
+		}
+		if !replaced {
+			neighbors = append(neighbors, neighbor)
+		}
+	}
+	return neighbors, err
+}
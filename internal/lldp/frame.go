@@ -0,0 +1,192 @@
+package lldp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// 邻居发现协议
+const (
+	ProtocolLLDP = "LLDP"
+	ProtocolCDP  = "CDP"
+)
+
+// CaptureDuration 被动抓包的时长，LLDP默认每30秒发送一次；CDP默认每60秒发送一次，可能需要抓包两次才能收到
+const CaptureDuration = 35 * time.Second
+
+// CaptureFilter 只抓取LLDP和CDP报文的BPF过滤条件
+const CaptureFilter = "ether proto 0x88cc or ether dst 01:00:0c:cc:cc:cc"
+
+const (
+	etherTypeLLDP = 0x88cc
+	etherTypeVLAN = 0x8100
+)
+
+// cdpSNAPHeader CDP报文的LLC/SNAP头：DSAP、SSAP、控制字段、思科OUI和协议ID 0x2000
+var cdpSNAPHeader = []byte{0xaa, 0xaa, 0x03, 0x00, 0x00, 0x0c, 0x20, 0x00}
+
+// ParseFrame 解析一个以太网帧，是LLDP或CDP报文时返回交换机通告的邻居信息
+func ParseFrame(frame []byte) (model.NeighborInfo, bool) {
+	if len(frame) < 14 {
+		return model.NeighborInfo{}, false
+	}
+	etherType := binary.BigEndian.Uint16(frame[12:14])
+	payload := frame[14:]
+	if etherType == etherTypeVLAN && len(payload) >= 4 {
+		etherType = binary.BigEndian.Uint16(payload[2:4])
+		payload = payload[4:]
+	}
+
+	switch {
+	case etherType == etherTypeLLDP:
+		return parseLLDP(payload)
+	case etherType <= 1500 && len(payload) >= len(cdpSNAPHeader) && string(payload[:len(cdpSNAPHeader)]) == string(cdpSNAPHeader):
+		// 802.3帧的类型字段为长度，CDP使用SNAP封装
+		return parseCDP(payload[len(cdpSNAPHeader):])
+	}
+	return model.NeighborInfo{}, false
+}
+
+// parseLLDP 解析LLDP报文的TLV，每个TLV头为7位类型和9位长度
+func parseLLDP(data []byte) (model.NeighborInfo, bool) {
+	neighbor := model.NeighborInfo{Protocol: ProtocolLLDP}
+	for len(data) >= 2 {
+		header := binary.BigEndian.Uint16(data[:2])
+		tlvType, length := int(header>>9), int(header&0x1ff)
+		if len(data) < 2+length {
+			break
+		}
+		value := data[2 : 2+length]
+		data = data[2+length:]
+
+		switch tlvType {
+		case 0: // 结束
+			return neighbor, neighbor.ChassisID != ""
+		case 1: // 机箱ID
+			neighbor.ChassisID = lldpID(value, 4)
+		case 2: // 端口ID
+			neighbor.PortID = lldpID(value, 3)
+		case 4:
+			neighbor.PortDescription = printable(value)
+		case 5:
+			neighbor.SwitchName = printable(value)
+		case 6:
+			neighbor.Description = printable(value)
+		case 8: // 管理地址：地址长度（含子类型）、地址子类型、地址
+			if len(value) >= 2 && int(value[0]) >= 1 && len(value) >= 1+int(value[0]) && neighbor.ManagementAddress == "" {
+				if address := ipString(value[1], value[2:1+int(value[0])]); address != "" {
+					neighbor.ManagementAddress = address
+				}
+			}
+		case 127: // 组织自定义TLV，IEEE 802.1子类型1为端口VLAN ID
+			if len(value) >= 6 && value[0] == 0x00 && value[1] == 0x80 && value[2] == 0xc2 && value[3] == 1 {
+				neighbor.VLAN = int(binary.BigEndian.Uint16(value[4:6]))
+			}
+		}
+	}
+	return neighbor, neighbor.ChassisID != ""
+}
+
+// lldpID 解析机箱ID和端口ID，第一个字节为子类型；macSubtype表示MAC地址（机箱ID为4，端口ID为3），5为网络地址，其他为字符串
+func lldpID(value []byte, macSubtype byte) string {
+	if len(value) < 2 {
+		return ""
+	}
+	subtype, id := value[0], value[1:]
+	switch {
+	case subtype == macSubtype && len(id) == 6:
+		return net.HardwareAddr(id).String()
+	case subtype == 5 && len(id) >= 2:
+		if address := ipString(id[0], id[1:]); address != "" {
+			return address
+		}
+	}
+	return printable(id)
+}
+
+// ipString 将IANA地址族（1为IPv4，2为IPv6）的地址转换为字符串
+func ipString(family byte, address []byte) string {
+	if (family == 1 && len(address) == 4) || (family == 2 && len(address) == 16) {
+		return net.IP(address).String()
+	}
+	return ""
+}
+
+// parseCDP 解析CDP报文：版本、TTL、校验和，之后的TLV头为两字节类型和两字节长度（含头部）
+func parseCDP(data []byte) (model.NeighborInfo, bool) {
+	neighbor := model.NeighborInfo{Protocol: ProtocolCDP}
+	if len(data) < 4 {
+		return neighbor, false
+	}
+	data = data[4:]
+	for len(data) >= 4 {
+		tlvType, length := binary.BigEndian.Uint16(data[:2]), int(binary.BigEndian.Uint16(data[2:4]))
+		if length < 4 || len(data) < length {
+			break
+		}
+		value := data[4:length]
+		data = data[length:]
+
+		switch tlvType {
+		case 0x0001:
+			neighbor.SwitchName = printable(value)
+			neighbor.ChassisID = neighbor.SwitchName
+		case 0x0002, 0x0016: // 地址和管理地址
+			if neighbor.ManagementAddress == "" {
+				neighbor.ManagementAddress = cdpAddress(value)
+			}
+		case 0x0003:
+			neighbor.PortID = printable(value)
+		case 0x0006:
+			neighbor.Description = printable(value)
+		case 0x000a:
+			if len(value) >= 2 {
+				neighbor.VLAN = int(binary.BigEndian.Uint16(value[:2]))
+			}
+		}
+	}
+	return neighbor, neighbor.SwitchName != ""
+}
+
+// cdpAddress 返回CDP地址列表中的第一个IPv4地址，地址列表为4字节数量，
+// 每个地址为协议类型、协议长度、协议（IP为0xcc）、2字节地址长度和地址
+func cdpAddress(value []byte) string {
+	if len(value) < 4 {
+		return ""
+	}
+	count := binary.BigEndian.Uint32(value[:4])
+	value = value[4:]
+	for i := uint32(0); i < count && len(value) >= 2; i++ {
+		protocolLength := int(value[1])
+		if len(value) < 2+protocolLength+2 {
+			return ""
+		}
+		protocol := value[2 : 2+protocolLength]
+		addressLength := int(binary.BigEndian.Uint16(value[2+protocolLength:]))
+		value = value[2+protocolLength+2:]
+		if len(value) < addressLength {
+			return ""
+		}
+		if len(protocol) == 1 && protocol[0] == 0xcc && addressLength == 4 {
+			return net.IP(value[:4]).String()
+		}
+		value = value[addressLength:]
+	}
+	return ""
+}
+
+// printable 将TLV中的文本转换为字符串，多行的系统描述合并为一行，包含不可打印字符时以十六进制显示
+func printable(value []byte) string {
+	s := strings.TrimRight(string(value), "\x00")
+	for _, r := range s {
+		if r < 0x20 && r != '\n' && r != '\r' && r != '\t' {
+			return fmt.Sprintf("%x", value)
+		}
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
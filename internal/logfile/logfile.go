@@ -0,0 +1,81 @@
+// Package logfile 提供按大小轮转的日志文件，macOS和Windows的系统服务都由程序自己写入和轮转日志，
+// 不依赖newsyslog等外部工具
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// 日志文件的轮转规则：超过10MB时轮转，保留5个旧日志
+const (
+	maxSize = 10 * 1024 * 1024
+	backups = 5
+)
+
+// File 是按大小轮转的日志文件，超过maxSize时将日志依次重命名为.1、.2……
+type File struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// New 返回写入path的日志文件，所在目录不存在时在第一次写入时创建
+func New(path string) *File {
+	return &File{path: path}
+}
+
+// Write 实现io.Writer
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if f.size+int64(len(p)) > maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// open 以追加方式打开日志文件
+func (f *File) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// rotate 关闭当前日志文件，重命名旧日志并删除超出保留数量的日志，然后打开新的日志文件
+func (f *File) rotate() error {
+	f.file.Close()
+	f.file = nil
+	os.Remove(fmt.Sprintf("%s.%d", f.path, backups))
+	for i := backups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+	}
+	if err := os.Rename(f.path, f.path+".1"); err != nil {
+		return err
+	}
+	return f.open()
+}
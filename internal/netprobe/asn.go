@@ -0,0 +1,83 @@
+package netprobe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// asnLookupTimeout 每次ASN查询的超时
+const asnLookupTimeout = 2 * time.Second
+
+// ASNInfo 表示IP地址所属的自治系统
+type ASNInfo struct {
+	Number  string // 自治系统号，例如AS8075
+	Prefix  string // 所属的路由前缀
+	Country string // 注册国家/地区代码
+	Name    string // 自治系统名称
+}
+
+// LookupASN 通过Team Cymru的DNS接口查询IP地址所属的自治系统，不需要API密钥
+func LookupASN(ip net.IP) (ASNInfo, error) {
+	var info ASNInfo
+	origin, err := lookupCymru(originQueryName(ip))
+	if err != nil {
+		return info, err
+	}
+	// 格式：ASN | 前缀 | 国家 | 注册机构 | 分配日期，同一前缀可能对应多个ASN
+	fields := splitCymru(origin)
+	if len(fields) < 3 || fields[0] == "" {
+		return info, fmt.Errorf("unexpected asn response %q", origin)
+	}
+	info.Number = "AS" + strings.Fields(fields[0])[0]
+	info.Prefix = fields[1]
+	info.Country = fields[2]
+
+	// 格式：ASN | 国家 | 注册机构 | 分配日期 | 名称
+	if description, err := lookupCymru(info.Number + ".asn.cymru.com"); err == nil {
+		if fields := splitCymru(description); len(fields) >= 5 {
+			info.Name = strings.TrimSuffix(fields[4], ", "+info.Country)
+		}
+	}
+	return info, nil
+}
+
+// originQueryName 生成查询IP所属ASN的域名，IPv4按字节、IPv6按半字节反序
+func originQueryName(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d.origin.asn.cymru.com", ip4[3], ip4[2], ip4[1], ip4[0])
+	}
+	const hexDigits = "0123456789abcdef"
+	ip16 := ip.To16()
+	nibbles := make([]string, 0, 32)
+	for i := len(ip16) - 1; i >= 0; i-- {
+		nibbles = append(nibbles, string(hexDigits[ip16[i]&0x0f]), string(hexDigits[ip16[i]>>4]))
+	}
+	return strings.Join(nibbles, ".") + ".origin6.asn.cymru.com"
+}
+
+// lookupCymru 查询TXT记录并返回第一条
+func lookupCymru(name string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), asnLookupTimeout)
+	defer cancel()
+	records, err := net.DefaultResolver.LookupTXT(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if len(records) == 0 {
+		return "", errors.New("no asn record")
+	}
+	return records[0], nil
+}
+
+// splitCymru 按竖线分割应答并去掉空白
+func splitCymru(record string) []string {
+	fields := strings.Split(record, "|")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields
+}
@@ -0,0 +1,96 @@
+package netprobe
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 非ICMP的探测方式
+const (
+	MethodTCP  = "tcp"  // TCP连接建立耗时
+	MethodHTTP = "http" // HTTP请求到收到响应头的耗时
+)
+
+// TCPPing 多次建立到目标端口的TCP连接，记录每次连接的建立耗时
+func TCPPing(host string, port int, opts PingOptions) PingResult {
+	result := PingResult{Host: host, Method: MethodTCP}
+
+	ip, err := resolveIP(host)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Addr = ip.String()
+	address := net.JoinHostPort(result.Addr, strconv.Itoa(port))
+
+	repeat(&result, opts, func() (time.Duration, error) {
+		start := time.Now()
+		conn, err := net.DialTimeout("tcp", address, opts.Timeout)
+		if err != nil {
+			return 0, err
+		}
+		rtt := time.Since(start)
+		conn.Close()
+		return rtt, nil
+	})
+	return result
+}
+
+// HTTPPing 多次请求目标URL，记录每次从建立连接到收到响应头的耗时，任何HTTP状态码都视为可达
+func HTTPPing(url string, opts PingOptions) PingResult {
+	result := PingResult{Host: url, Method: MethodHTTP}
+
+	// 禁用连接复用，使每次请求都包含连接建立和TLS握手的耗时
+	client := &http.Client{
+		Timeout: opts.Timeout,
+		Transport: &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	repeat(&result, opts, func() (time.Duration, error) {
+		start := time.Now()
+		resp, err := client.Get(url)
+		if err != nil {
+			return 0, err
+		}
+		rtt := time.Since(start)
+		resp.Body.Close()
+		return rtt, nil
+	})
+	return result
+}
+
+// repeat 按ping参数重复执行探测并记录结果，超时和连接失败都计为丢包
+func repeat(result *PingResult, opts PingOptions, probe func() (time.Duration, error)) {
+	var lastErr error
+	for seq := 0; seq < opts.Count; seq++ {
+		start := time.Now()
+		rtt, err := probe()
+		result.Sent++
+		if err != nil {
+			lastErr = err
+			result.RTTs = append(result.RTTs, -1)
+		} else {
+			result.Received++
+			result.RTTs = append(result.RTTs, rtt)
+		}
+
+		if seq < opts.Count-1 {
+			if wait := opts.Interval - time.Since(start); wait > 0 {
+				time.Sleep(wait)
+			}
+		}
+	}
+
+	if result.Received == 0 && lastErr != nil {
+		result.Err = lastErr
+	}
+}
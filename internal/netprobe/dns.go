@@ -0,0 +1,198 @@
+package netprobe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// DNSTimeout 每次DNS查询的默认超时
+const DNSTimeout = 2 * time.Second
+
+// ErrDNSTimeout DNS服务器没有在超时时间内响应
+var ErrDNSTimeout = errors.New("dns query timeout")
+
+// dnsID DNS查询的事务ID
+var dnsID uint32
+
+// dnsTypes 支持的查询类型
+var dnsTypes = map[string]dnsmessage.Type{
+	"A":     dnsmessage.TypeA,
+	"AAAA":  dnsmessage.TypeAAAA,
+	"CNAME": dnsmessage.TypeCNAME,
+}
+
+// DNSRecord 表示DNS应答中的一条记录
+type DNSRecord struct {
+	Name  string // 记录所属的域名
+	Type  string // 记录类型（A、AAAA、CNAME）
+	Value string // IP地址或别名
+	TTL   uint32 // 生存时间（秒）
+}
+
+// DNSResponse 表示一次DNS查询的结果
+type DNSResponse struct {
+	Server  string        // 查询的DNS服务器
+	Name    string        // 查询的域名
+	Type    string        // 查询类型
+	RTT     time.Duration // 从发送查询到收到应答的耗时
+	RCode   string        // 响应码（NOERROR、NXDOMAIN、SERVFAIL等）
+	Answers []DNSRecord   // 应答记录
+	Err     error         // 发送失败、超时或应答无法解析时的错误
+}
+
+// QueryDNS 直接向指定的DNS服务器发送一次查询，不使用系统缓存；应答被截断时改用TCP重新查询
+func QueryDNS(server, name, qtype string, timeout time.Duration) DNSResponse {
+	response := DNSResponse{Server: server, Name: name, Type: qtype}
+
+	t, ok := dnsTypes[strings.ToUpper(qtype)]
+	if !ok {
+		response.Err = fmt.Errorf("unsupported dns type %s", qtype)
+		return response
+	}
+	fqdn := name
+	if !strings.HasSuffix(fqdn, ".") {
+		fqdn += "."
+	}
+	question, err := dnsmessage.NewName(fqdn)
+	if err != nil {
+		response.Err = err
+		return response
+	}
+
+	id := uint16(atomic.AddUint32(&dnsID, 1))
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: question, Type: t, Class: dnsmessage.ClassINET}},
+	}
+	packet, err := query.Pack()
+	if err != nil {
+		response.Err = err
+		return response
+	}
+
+	address := net.JoinHostPort(server, "53")
+	start := time.Now()
+	reply, err := exchangeUDP(address, packet, id, timeout)
+	if err == nil && reply.Header.Truncated {
+		reply, err = exchangeTCP(address, packet, id, timeout-time.Since(start))
+	}
+	response.RTT = time.Since(start)
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			err = ErrDNSTimeout
+		}
+		response.Err = err
+		return response
+	}
+
+	response.RCode = rcodeName(reply.Header.RCode)
+	for _, answer := range reply.Answers {
+		record := DNSRecord{Name: strings.TrimSuffix(answer.Header.Name.String(), "."), TTL: answer.Header.TTL}
+		switch body := answer.Body.(type) {
+		case *dnsmessage.AResource:
+			record.Type, record.Value = "A", net.IP(body.A[:]).String()
+		case *dnsmessage.AAAAResource:
+			record.Type, record.Value = "AAAA", net.IP(body.AAAA[:]).String()
+		case *dnsmessage.CNAMEResource:
+			record.Type, record.Value = "CNAME", strings.TrimSuffix(body.CNAME.String(), ".")
+		default:
+			continue
+		}
+		response.Answers = append(response.Answers, record)
+	}
+	return response
+}
+
+// rcodeName 返回响应码的常用名称
+func rcodeName(rcode dnsmessage.RCode) string {
+	switch rcode {
+	case dnsmessage.RCodeSuccess:
+		return "NOERROR"
+	case dnsmessage.RCodeFormatError:
+		return "FORMERR"
+	case dnsmessage.RCodeServerFailure:
+		return "SERVFAIL"
+	case dnsmessage.RCodeNameError:
+		return "NXDOMAIN"
+	case dnsmessage.RCodeNotImplemented:
+		return "NOTIMP"
+	case dnsmessage.RCodeRefused:
+		return "REFUSED"
+	}
+	return fmt.Sprintf("RCODE%d", rcode)
+}
+
+// exchangeUDP 通过UDP发送查询并等待事务ID匹配的应答
+func exchangeUDP(address string, packet []byte, id uint16, timeout time.Duration) (dnsmessage.Message, error) {
+	var reply dnsmessage.Message
+	conn, err := net.DialTimeout("udp", address, timeout)
+	if err != nil {
+		return reply, err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return reply, err
+	}
+	if _, err := conn.Write(packet); err != nil {
+		return reply, err
+	}
+
+	buf := make([]byte, 1232)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return reply, err
+		}
+		// 忽略事务ID不匹配或无法解析的应答
+		if err := reply.Unpack(buf[:n]); err != nil || reply.Header.ID != id || !reply.Header.Response {
+			continue
+		}
+		return reply, nil
+	}
+}
+
+// exchangeTCP 通过TCP发送查询，消息前带两字节长度
+func exchangeTCP(address string, packet []byte, id uint16, timeout time.Duration) (dnsmessage.Message, error) {
+	var reply dnsmessage.Message
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return reply, err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return reply, err
+	}
+	request := make([]byte, 2+len(packet))
+	binary.BigEndian.PutUint16(request, uint16(len(packet)))
+	copy(request[2:], packet)
+	if _, err := conn.Write(request); err != nil {
+		return reply, err
+	}
+
+	var length [2]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		return reply, err
+	}
+	buf := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return reply, err
+	}
+	if err := reply.Unpack(buf); err != nil {
+		return reply, err
+	}
+	if reply.Header.ID != id {
+		return reply, errors.New("dns response id mismatch")
+	}
+	return reply, nil
+}
@@ -0,0 +1,51 @@
+package netprobe
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// geoLookupTimeout IP归属地查询的超时
+const geoLookupTimeout = 5 * time.Second
+
+// GeoInfo 表示IP地址的地理位置和运营商
+type GeoInfo struct {
+	City    string // 城市
+	Region  string // 省/州
+	Country string // 国家/地区代码
+	Org     string // 运营商或组织，ipinfo格式为"AS4134 CHINANET-BACKBONE"
+}
+
+// LookupGeo 请求ipinfo.io格式的IP归属地接口，url中的{ip}会被替换为要查询的地址
+func LookupGeo(url, ip string) (GeoInfo, error) {
+	var info GeoInfo
+	client := http.Client{Timeout: geoLookupTimeout}
+	resp, err := client.Get(strings.ReplaceAll(url, "{ip}", ip))
+	if err != nil {
+		return info, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return info, fmt.Errorf("geo lookup: %s", resp.Status)
+	}
+
+	var result struct {
+		City        string `json:"city"`
+		Region      string `json:"region"`
+		Country     string `json:"country"`
+		CountryCode string `json:"country_code"`
+		Org         string `json:"org"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return info, err
+	}
+	info = GeoInfo{City: result.City, Region: result.Region, Country: result.Country, Org: result.Org}
+	// 兼容ip.sb等使用country_code字段的接口
+	if result.CountryCode != "" {
+		info.Country = result.CountryCode
+	}
+	return info, nil
+}
@@ -0,0 +1,112 @@
+package netprobe
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"time"
+)
+
+// HTTPCheckTimeout 每个HTTP端点检查的超时
+const HTTPCheckTimeout = 10 * time.Second
+
+// HTTPCheck 表示一次HTTP请求各阶段的耗时和服务器证书链
+type HTTPCheck struct {
+	Status       int                 // HTTP状态码
+	RemoteAddr   string              // 实际连接的地址，使用代理时为代理服务器
+	DNS          time.Duration       // 域名解析耗时，使用代理或直接访问IP时为0
+	Connect      time.Duration       // TCP连接建立耗时
+	TLS          time.Duration       // TLS握手耗时
+	TTFB         time.Duration       // 从发送请求到收到第一个响应字节的耗时
+	Total        time.Duration       // 从开始到读完响应的总耗时
+	TLSVersion   string              // 协商的TLS版本
+	Certificates []*x509.Certificate // 服务器发送的证书链，第一个为服务器证书
+	VerifyErr    error               // 使用系统信任的根证书验证证书链的错误，验证通过时为nil
+	Err          error               // 请求失败的错误
+}
+
+// CheckHTTP 请求URL并记录域名解析、TCP连接、TLS握手和首字节的耗时，不跟随重定向
+// 握手时不校验证书，以便在证书无效（例如被TLS解密代理重新签发）时仍能取得证书链，之后再按系统信任的根证书单独验证
+func CheckHTTP(rawURL string, timeout time.Duration) HTTPCheck {
+	var check HTTPCheck
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		check.Err = err
+		return check
+	}
+
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
+			DisableKeepAlives: true,
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	var start, dnsStart, connectStart, tlsStart, wroteRequest time.Time
+	trace := &httptrace.ClientTrace{
+		DNSStart:     func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:      func(httptrace.DNSDoneInfo) { check.DNS = time.Since(dnsStart) },
+		ConnectStart: func(string, string) { connectStart = time.Now() },
+		ConnectDone: func(_, addr string, err error) {
+			if err == nil {
+				check.Connect = time.Since(connectStart)
+				check.RemoteAddr = addr
+			}
+		},
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			if err == nil {
+				check.TLS = time.Since(tlsStart)
+			}
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { wroteRequest = time.Now() },
+		GotFirstResponseByte: func() { check.TTFB = time.Since(wroteRequest) },
+	}
+
+	req, err := http.NewRequest(http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		check.Err = err
+		return check
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	start = time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		check.Err = err
+		return check
+	}
+	defer resp.Body.Close()
+	// 只读取响应的前1 MB，避免检查的页面过大时耗时过长
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	check.Total = time.Since(start)
+	check.Status = resp.StatusCode
+
+	if resp.TLS != nil {
+		check.TLSVersion = tls.VersionName(resp.TLS.Version)
+		check.Certificates = resp.TLS.PeerCertificates
+		check.VerifyErr = verifyChain(parsed.Hostname(), resp.TLS.PeerCertificates)
+	}
+	return check
+}
+
+// verifyChain 使用系统信任的根证书验证服务器证书链和主机名，系统中安装的企业根证书也会被信任
+func verifyChain(host string, certs []*x509.Certificate) error {
+	if len(certs) == 0 {
+		return x509.UnknownAuthorityError{}
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{DNSName: host, Intermediates: intermediates})
+	return err
+}
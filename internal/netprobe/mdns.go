@@ -0,0 +1,96 @@
+package netprobe
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// MDNSTimeout 等待mDNS应答的默认时间
+const MDNSTimeout = 3 * time.Second
+
+// mdnsGroup mDNS的IPv4组播地址
+var mdnsGroup = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// MDNSBrowse 表示一次mDNS服务浏览的结果
+type MDNSBrowse struct {
+	Instances  map[string][]string // 服务类型（如_ipp._tcp）对应的服务实例名称
+	Responders []string            // 发送了应答的主机地址
+}
+
+// BrowseMDNS 向mDNS组播地址查询各服务类型的PTR记录，收集超时时间内的所有应答
+// 查询从临时端口发出（RFC 6762中的单次查询），设备直接单播应答，不需要占用系统mDNS服务使用的5353端口；
+// 查询在超时时间过半时重发一次，以减少组播丢包的影响。只支持IPv4
+func BrowseMDNS(serviceTypes []string, timeout time.Duration) (MDNSBrowse, error) {
+	browse := MDNSBrowse{Instances: make(map[string][]string)}
+
+	var questions []dnsmessage.Question
+	queried := make(map[string]bool)
+	for _, serviceType := range serviceTypes {
+		serviceType = strings.TrimSuffix(serviceType, ".")
+		name, err := dnsmessage.NewName(serviceType + ".local.")
+		if err != nil {
+			return browse, err
+		}
+		questions = append(questions, dnsmessage.Question{Name: name, Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET})
+		queried[serviceType] = true
+	}
+	query := dnsmessage.Message{Questions: questions}
+	packet, err := query.Pack()
+	if err != nil {
+		return browse, err
+	}
+
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return browse, err
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 9000)
+	for _, wait := range []time.Duration{timeout / 2, timeout - timeout/2} {
+		if _, err := conn.WriteToUDP(packet, mdnsGroup); err != nil {
+			return browse, err
+		}
+		if err := conn.SetReadDeadline(time.Now().Add(wait)); err != nil {
+			return browse, err
+		}
+		for {
+			n, addr, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+					break
+				}
+				return browse, err
+			}
+
+			var reply dnsmessage.Message
+			if err := reply.Unpack(buf[:n]); err != nil || !reply.Header.Response {
+				continue
+			}
+			browse.Responders = appendString(browse.Responders, addr.IP.String())
+			for _, answer := range append(reply.Answers, reply.Additionals...) {
+				ptr, ok := answer.Body.(*dnsmessage.PTRResource)
+				serviceType := strings.TrimSuffix(answer.Header.Name.String(), ".local.")
+				if !ok || !queried[serviceType] {
+					continue
+				}
+				instance := strings.TrimSuffix(ptr.PTR.String(), "."+serviceType+".local.")
+				browse.Instances[serviceType] = appendString(browse.Instances[serviceType], instance)
+			}
+		}
+	}
+	return browse, nil
+}
+
+// appendString 追加不重复的字符串
+func appendString(list []string, s string) []string {
+	for _, item := range list {
+		if item == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,174 @@
+package netprobe
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+// IPv4和ICMP Echo报文头部长度，探测大小为整个IP数据包的长度
+const (
+	ipv4HeaderLen = 20
+	icmpHeaderLen = 8
+)
+
+// MTUOptions 表示路径MTU探测参数
+type MTUOptions struct {
+	Min     int           // 探测的最小数据包大小，互联网上的主机至少能接收576字节
+	Timeout time.Duration // 每个探测包的应答超时
+	Retries int           // 每个大小在超时后最多发送的次数，避免把偶然丢包误判为超过MTU
+}
+
+// DefaultMTUOptions 默认从576字节开始探测，超时1秒，每个大小最多发送2次
+var DefaultMTUOptions = MTUOptions{
+	Min:     576,
+	Timeout: time.Second,
+	Retries: 2,
+}
+
+// MTUResult 表示到一个目标的路径MTU探测结果
+type MTUResult struct {
+	Host         string // 目标主机
+	Addr         string // 解析后的IP地址
+	Interface    string // 访问目标使用的网络接口
+	InterfaceMTU int    // 网络接口的MTU
+	PathMTU      int    // 设置不分片标志后能到达目标的最大数据包
+	FragNeeded   bool   // 是否收到路由器返回的需要分片报文
+	Err          error  // 解析、发送失败或最小的数据包也没有应答时的错误
+}
+
+// dfProber 发送设置了不分片（DF）标志、指定大小的ICMP Echo请求
+type dfProber interface {
+	probe(seq, size int, timeout time.Duration) (hopReply, error)
+	Close() error
+}
+
+// PathMTU 发送设置了不分片标志的ICMP Echo请求，在最小值和接口MTU之间二分查找能到达目标的最大数据包
+// 路由器返回需要分片报文时直接尝试其中的下一跳MTU；只支持IPv4
+func PathMTU(host string, opts MTUOptions) MTUResult {
+	result := MTUResult{Host: host}
+
+	ip, err := resolveIP(host)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if ip.To4() == nil {
+		result.Err = errors.New("path mtu discovery only supports ipv4")
+		return result
+	}
+	result.Addr = ip.String()
+	result.Interface, result.InterfaceMTU = outgoingInterface(ip)
+
+	p, err := newDFProber(ip)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer p.Close()
+
+	seq := 0
+	// try 返回该大小的数据包能否到达目标，以及需要分片报文中的下一跳MTU
+	try := func(size int) (bool, int, error) {
+		for i := 0; i < opts.Retries; i++ {
+			reply, err := p.probe(seq, size, opts.Timeout)
+			seq++
+			if err == errPingTimeout {
+				continue
+			}
+			if err != nil {
+				return false, 0, err
+			}
+			switch reply.kind {
+			case replyEcho:
+				return true, 0, nil
+			case replyTooBig:
+				if reply.mtu > 0 {
+					result.FragNeeded = true
+				}
+				return false, reply.mtu, nil
+			}
+		}
+		return false, 0, nil
+	}
+
+	hi := result.InterfaceMTU
+	if hi <= 0 {
+		hi = 1500
+	}
+	ok, nextHop, err := try(hi)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if ok {
+		result.PathMTU = hi
+		return result
+	}
+
+	lo := opts.Min
+	if ok, _, err = try(lo); err != nil || !ok {
+		result.Err = err
+		if result.Err == nil {
+			result.Err = errors.New("no reply to minimum size probe")
+		}
+		return result
+	}
+
+	// lo总能到达目标，hi总是不能
+	for hi-lo > 1 {
+		size := (lo + hi) / 2
+		if nextHop > lo && nextHop < hi {
+			size, nextHop = nextHop, 0
+		}
+		ok, mtu, err := try(size)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		if ok {
+			lo = size
+		} else {
+			hi = size
+			if mtu > 0 {
+				nextHop = mtu
+			}
+		}
+	}
+	result.PathMTU = lo
+	return result
+}
+
+// outgoingInterface 通过UDP“连接”找到访问目标时使用的网络接口，不会发送数据包
+func outgoingInterface(ip net.IP) (string, int) {
+	conn, err := net.Dial("udp", net.JoinHostPort(ip.String(), "53"))
+	if err != nil {
+		return "", 0
+	}
+	local := conn.LocalAddr().(*net.UDPAddr).IP
+	conn.Close()
+
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return "", 0
+	}
+	for _, iface := range interfaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(local) {
+				return iface.Name, iface.MTU
+			}
+		}
+	}
+	return "", 0
+}
+
+// echoPayload 返回指定IP数据包大小所需的Echo数据
+func echoPayload(size int) []byte {
+	payload := make([]byte, size-ipv4HeaderLen-icmpHeaderLen)
+	copy(payload, "SysSpector")
+	return payload
+}
@@ -0,0 +1,11 @@
+package netprobe
+
+import "syscall"
+
+// ipDontFrag 对应IP_DONTFRAG，syscall包中没有定义
+const ipDontFrag = 28
+
+// setDontFragment 设置IPv4数据包的不分片标志
+func setDontFragment(fd int) error {
+	return syscall.SetsockoptInt(fd, syscall.IPPROTO_IP, ipDontFrag, 1)
+}
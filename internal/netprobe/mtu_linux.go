@@ -0,0 +1,8 @@
+package netprobe
+
+import "syscall"
+
+// setDontFragment 设置IPv4数据包的不分片标志，并忽略内核缓存的路径MTU
+func setDontFragment(fd int) error {
+	return syscall.SetsockoptInt(fd, syscall.IPPROTO_IP, syscall.IP_MTU_DISCOVER, syscall.IP_PMTUDISC_PROBE)
+}
@@ -0,0 +1,14 @@
+//go:build !darwin && !linux && !windows
+// +build !darwin,!linux,!windows
+
+package netprobe
+
+import (
+	"errors"
+	"net"
+)
+
+// newDFProber 其他平台不支持设置不分片标志
+func newDFProber(ip net.IP) (dfProber, error) {
+	return nil, errors.New("path mtu discovery is not supported on this platform")
+}
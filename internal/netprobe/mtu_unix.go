@@ -0,0 +1,165 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package netprobe
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+// socketDFProber 基于设置了不分片选项的ICMP套接字的实现
+// icmp.PacketConn不提供底层文件描述符，因此自行创建套接字
+type socketDFProber struct {
+	conn       net.PacketConn
+	dst        net.Addr
+	ip         net.IP
+	id         int
+	privileged bool
+}
+
+// newDFProber 依次尝试原始套接字和非特权ICMP套接字
+func newDFProber(ip net.IP) (dfProber, error) {
+	var lastErr error
+	for _, privileged := range []bool{true, false} {
+		p, err := newSocketDFProber(ip, privileged)
+		if err == nil {
+			return p, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// newSocketDFProber 创建ICMP套接字并设置不分片选项，privileged为true时使用原始套接字
+func newSocketDFProber(ip net.IP, privileged bool) (*socketDFProber, error) {
+	sotype := syscall.SOCK_DGRAM
+	if privileged {
+		sotype = syscall.SOCK_RAW
+	}
+	fd, err := syscall.Socket(syscall.AF_INET, sotype, syscall.IPPROTO_ICMP)
+	if err != nil {
+		return nil, err
+	}
+	if err := setDontFragment(fd); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+
+	// FilePacketConn复制了文件描述符，原文件可以关闭
+	f := os.NewFile(uintptr(fd), "icmp")
+	conn, err := net.FilePacketConn(f)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &socketDFProber{
+		conn:       conn,
+		ip:         ip,
+		id:         int((uint32(os.Getpid()) + atomic.AddUint32(&echoID, 1)) & 0xffff),
+		privileged: privileged,
+	}
+	if privileged {
+		p.dst = &net.IPAddr{IP: ip}
+	} else {
+		p.dst = &net.UDPAddr{IP: ip}
+	}
+	return p, nil
+}
+
+func (p *socketDFProber) Close() error {
+	return p.conn.Close()
+}
+
+// probe 发送指定大小的Echo请求，等待Echo应答或引用了该请求的需要分片报文
+// 数据包超过本机接口MTU时内核直接返回EMSGSIZE
+func (p *socketDFProber) probe(seq, size int, timeout time.Duration) (hopReply, error) {
+	msg := icmp.Message{
+		Type: ipv4.ICMPTypeEcho,
+		Body: &icmp.Echo{ID: p.id, Seq: seq & 0xffff, Data: echoPayload(size)},
+	}
+	request, err := msg.Marshal(nil)
+	if err != nil {
+		return hopReply{}, err
+	}
+
+	start := time.Now()
+	if _, err := p.conn.WriteTo(request, p.dst); err != nil {
+		if errors.Is(err, syscall.EMSGSIZE) {
+			return hopReply{kind: replyTooBig}, nil
+		}
+		return hopReply{}, err
+	}
+	if err := p.conn.SetReadDeadline(start.Add(timeout)); err != nil {
+		return hopReply{}, err
+	}
+
+	buf := make([]byte, 65536)
+	for {
+		n, peer, err := p.conn.ReadFrom(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return hopReply{}, errPingTimeout
+			}
+			return hopReply{}, err
+		}
+		data := stripIPv4Header(buf[:n])
+		reply := hopReply{peer: peerIP(peer), rtt: time.Since(start)}
+
+		message, err := icmp.ParseMessage(protocolICMP, data)
+		if err != nil {
+			continue
+		}
+		switch body := message.Body.(type) {
+		case *icmp.Echo:
+			if message.Type != ipv4.ICMPTypeEchoReply || !reply.peer.Equal(p.ip) || !p.matches(body.ID, body.Seq, seq) {
+				continue
+			}
+			reply.kind = replyEcho
+		case *icmp.DstUnreach:
+			// 代码4为需要分片但设置了不分片标志，下一跳MTU在ICMP头部的第7、8字节
+			if message.Code != 4 || len(data) < icmpHeaderLen {
+				continue
+			}
+			id, quotedSeq, ok := quotedEcho(body.Data, false)
+			if !ok || !p.matches(id, quotedSeq, seq) {
+				continue
+			}
+			reply.kind = replyTooBig
+			reply.mtu = int(binary.BigEndian.Uint16(data[6:8]))
+		default:
+			continue
+		}
+		return reply, nil
+	}
+}
+
+// matches 判断应答的标识符和序号是否对应本次请求，非特权套接字的标识符可能被内核改写，只比较序号
+func (p *socketDFProber) matches(id, replySeq, seq int) bool {
+	if replySeq != seq&0xffff {
+		return false
+	}
+	return !p.privileged || id == p.id
+}
+
+// stripIPv4Header 去掉IPv4头部；原始套接字的头部已由net包去掉，macOS的非特权ICMP套接字仍然带有头部
+func stripIPv4Header(b []byte) []byte {
+	if len(b) < ipv4HeaderLen || b[0]>>4 != 4 {
+		return b
+	}
+	headerLen := int(b[0]&0x0f) * 4
+	if headerLen < ipv4HeaderLen || headerLen > len(b) {
+		return b
+	}
+	return b[headerLen:]
+}
@@ -0,0 +1,81 @@
+package netprobe
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"time"
+)
+
+// ntpEpochOffset NTP时间戳（1900年起）与Unix时间戳（1970年起）之间的秒数
+const ntpEpochOffset = 2208988800
+
+// NTPResult 表示一次SNTP查询的结果
+type NTPResult struct {
+	Offset  time.Duration // 服务器时间减去本机时间，正数表示本机偏慢
+	RTT     time.Duration // 往返时延
+	Stratum int           // 服务器层级
+}
+
+// QueryNTP 向NTP服务器发送SNTP请求并计算本机时钟偏差（RFC 4330）
+func QueryNTP(server string, timeout time.Duration) (NTPResult, error) {
+	var result NTPResult
+
+	conn, err := net.DialTimeout("udp", net.JoinHostPort(server, "123"), timeout)
+	if err != nil {
+		return result, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	// LI=0，VN=4，Mode=3（客户端）
+	request := make([]byte, 48)
+	request[0] = 0<<6 | 4<<3 | 3
+
+	t1 := time.Now()
+	putNTPTime(request[40:], t1)
+	if _, err := conn.Write(request); err != nil {
+		return result, err
+	}
+
+	response := make([]byte, 48)
+	n, err := conn.Read(response)
+	t4 := time.Now()
+	if err != nil {
+		return result, err
+	}
+	if n < 48 {
+		return result, errors.New("ntp response too short")
+	}
+	if response[0]&0x7 != 4 {
+		return result, errors.New("unexpected ntp response mode")
+	}
+
+	result.Stratum = int(response[1])
+	if result.Stratum == 0 {
+		return result, errors.New("ntp server sent kiss-of-death")
+	}
+
+	t2 := ntpTime(response[32:40])
+	t3 := ntpTime(response[40:48])
+
+	result.Offset = (t2.Sub(t1) + t3.Sub(t4)) / 2
+	result.RTT = t4.Sub(t1) - t3.Sub(t2)
+	return result, nil
+}
+
+// ntpTime 将64位NTP时间戳转换为time.Time
+func ntpTime(b []byte) time.Time {
+	seconds := binary.BigEndian.Uint32(b[0:4])
+	fraction := binary.BigEndian.Uint32(b[4:8])
+	nanos := (int64(fraction) * 1e9) >> 32
+	return time.Unix(int64(seconds)-ntpEpochOffset, nanos)
+}
+
+// putNTPTime 将time.Time写入64位NTP时间戳
+func putNTPTime(b []byte, t time.Time) {
+	seconds := uint32(t.Unix() + ntpEpochOffset)
+	fraction := uint32((int64(t.Nanosecond()) << 32) / 1e9)
+	binary.BigEndian.PutUint32(b[0:4], seconds)
+	binary.BigEndian.PutUint32(b[4:8], fraction)
+}
@@ -0,0 +1,437 @@
+package netprobe
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"net"
+	"os"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// ICMP协议号
+const (
+	protocolICMP     = 1
+	protocolIPv6ICMP = 58
+)
+
+// ping实现方式
+const (
+	MethodRaw     = "raw"     // 原始套接字，需要管理员权限
+	MethodUDP     = "udp"     // 非特权ICMP数据报套接字（macOS、Linux）
+	MethodICMPAPI = "icmpapi" // Windows ICMP API（IcmpSendEcho），无需管理员权限
+)
+
+// 探测应答类型
+const (
+	replyEcho         = iota // 目标的Echo应答
+	replyTimeExceeded        // 中间路由器返回的TTL超时
+	replyUnreachable         // 目标不可达
+	replyTooBig              // 数据包超过路径MTU，需要分片
+)
+
+// errPingTimeout 等待应答超时
+var errPingTimeout = errors.New("ping timeout")
+
+// echoID 区分并发ping时各目标的ICMP标识符
+var echoID uint32
+
+// PingOptions 表示ping参数
+type PingOptions struct {
+	Count    int           // 发送的数据包数
+	Interval time.Duration // 发送间隔
+	Timeout  time.Duration // 每个数据包的应答超时
+}
+
+// DefaultPingOptions 默认发送5个数据包，间隔200毫秒，超时2秒
+var DefaultPingOptions = PingOptions{
+	Count:    5,
+	Interval: 200 * time.Millisecond,
+	Timeout:  2 * time.Second,
+}
+
+// PingResult 表示对一个目标的ping结果
+type PingResult struct {
+	Host     string          // 目标主机
+	Addr     string          // 解析后的IP地址
+	Method   string          // 使用的实现方式
+	Sent     int             // 发送的数据包数
+	Received int             // 收到应答的数据包数
+	RTTs     []time.Duration // 每个数据包的往返时延，丢失的数据包为-1
+	Err      error           // 解析或发送失败时的错误
+}
+
+// Loss 返回丢包率（百分比）
+func (r PingResult) Loss() float64 {
+	if r.Sent == 0 {
+		return 100
+	}
+	return float64(r.Sent-r.Received) / float64(r.Sent) * 100
+}
+
+// Stats 返回收到应答的数据包的最小、平均、最大往返时延和标准差
+func (r PingResult) Stats() (min, avg, max, stddev time.Duration) {
+	return rttStats(r.RTTs)
+}
+
+// Jitter 返回相邻两个收到应答的数据包往返时延之差的平均绝对值（参考RFC 3550的到达间隔抖动）
+func (r PingResult) Jitter() time.Duration {
+	var sum time.Duration
+	var count int
+	prev := time.Duration(-1)
+	for _, rtt := range r.RTTs {
+		if rtt < 0 {
+			continue
+		}
+		if prev >= 0 {
+			diff := rtt - prev
+			if diff < 0 {
+				diff = -diff
+			}
+			sum += diff
+			count++
+		}
+		prev = rtt
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / time.Duration(count)
+}
+
+// Percentiles 返回收到应答的数据包往返时延的P50、P95和P99（最近秩法）
+func (r PingResult) Percentiles() (p50, p95, p99 time.Duration) {
+	var rtts []time.Duration
+	for _, rtt := range r.RTTs {
+		if rtt >= 0 {
+			rtts = append(rtts, rtt)
+		}
+	}
+	if len(rtts) == 0 {
+		return 0, 0, 0
+	}
+	sort.Slice(rtts, func(i, j int) bool { return rtts[i] < rtts[j] })
+	return percentile(rtts, 50), percentile(rtts, 95), percentile(rtts, 99)
+}
+
+// percentile 返回已排序样本的第p百分位数
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// rttStats 计算往返时延的最小值、平均值、最大值和标准差，忽略丢失的数据包
+func rttStats(rtts []time.Duration) (min, avg, max, stddev time.Duration) {
+	var sum, sumSquares float64
+	count := 0
+	for _, rtt := range rtts {
+		if rtt < 0 {
+			continue
+		}
+		if count == 0 || rtt < min {
+			min = rtt
+		}
+		if rtt > max {
+			max = rtt
+		}
+		sum += float64(rtt)
+		sumSquares += float64(rtt) * float64(rtt)
+		count++
+	}
+	if count == 0 {
+		return 0, 0, 0, 0
+	}
+	mean := sum / float64(count)
+	avg = time.Duration(mean)
+	stddev = time.Duration(math.Sqrt(math.Max(sumSquares/float64(count)-mean*mean, 0)))
+	return min, avg, max, stddev
+}
+
+// hopReply 表示一次探测收到的应答
+type hopReply struct {
+	peer net.IP        // 应答的来源地址
+	rtt  time.Duration // 往返时延
+	kind int           // 应答类型
+	mtu  int           // 需要分片报文中的下一跳MTU，未知时为0
+}
+
+// echoer 发送一个ICMP Echo请求并等待应答，ttl大于0时设置请求的TTL（IPv6为跳数限制）
+type echoer interface {
+	probe(seq, ttl int, timeout time.Duration) (hopReply, error)
+	method() string
+	Close() error
+}
+
+// Ping 向目标发送ICMP Echo请求并记录每个数据包的往返时延
+// 优先使用原始套接字，没有权限时使用非特权ICMP套接字，Windows上再退回到ICMP API
+func Ping(host string, opts PingOptions) PingResult {
+	result := PingResult{Host: host}
+
+	ip, err := resolveIP(host)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Addr = ip.String()
+
+	e, err := newEchoer(ip)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer e.Close()
+	result.Method = e.method()
+
+	var lastErr error
+	for seq := 0; seq < opts.Count; seq++ {
+		start := time.Now()
+		reply, err := e.probe(seq, 0, opts.Timeout)
+		result.Sent++
+		if err != nil || reply.kind != replyEcho {
+			if err != nil && err != errPingTimeout {
+				lastErr = err
+			}
+			result.RTTs = append(result.RTTs, -1)
+		} else {
+			result.Received++
+			result.RTTs = append(result.RTTs, reply.rtt)
+		}
+
+		if seq < opts.Count-1 {
+			if wait := opts.Interval - time.Since(start); wait > 0 {
+				time.Sleep(wait)
+			}
+		}
+	}
+
+	// 所有数据包都发送失败（而不是超时）时返回错误
+	if result.Received == 0 && lastErr != nil {
+		result.Err = lastErr
+	}
+	return result
+}
+
+// resolveIP 解析主机名，优先使用IPv4地址
+func resolveIP(host string) (net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip, nil
+	}
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			return ip, nil
+		}
+	}
+	if len(ips) == 0 {
+		return nil, errors.New("no address for " + host)
+	}
+	return ips[0], nil
+}
+
+// newEchoer 依次尝试原始套接字、非特权ICMP套接字和平台相关的实现（Windows优先使用ICMP API）
+func newEchoer(ip net.IP) (echoer, error) {
+	if preferPlatformEchoer {
+		if e, err := newPlatformEchoer(ip); err == nil {
+			return e, nil
+		}
+	}
+
+	e, err := newSocketEchoer(ip, true)
+	if err == nil {
+		return e, nil
+	}
+	if e, err := newSocketEchoer(ip, false); err == nil {
+		return e, nil
+	}
+	if !preferPlatformEchoer {
+		if e, platformErr := newPlatformEchoer(ip); platformErr == nil {
+			return e, nil
+		}
+	}
+	return nil, err
+}
+
+// socketEchoer 基于ICMP套接字的实现
+type socketEchoer struct {
+	conn       *icmp.PacketConn
+	dst        net.Addr
+	ip         net.IP
+	id         int
+	privileged bool
+	v6         bool
+}
+
+// newSocketEchoer 打开ICMP套接字，privileged为true时使用原始套接字
+func newSocketEchoer(ip net.IP, privileged bool) (*socketEchoer, error) {
+	e := &socketEchoer{
+		ip:         ip,
+		id:         int((uint32(os.Getpid()) + atomic.AddUint32(&echoID, 1)) & 0xffff),
+		privileged: privileged,
+		v6:         ip.To4() == nil,
+	}
+
+	network, address := "ip4:icmp", "0.0.0.0"
+	if e.v6 {
+		network, address = "ip6:ipv6-icmp", "::"
+	}
+	if !privileged {
+		network = "udp4"
+		if e.v6 {
+			network = "udp6"
+		}
+	}
+
+	conn, err := icmp.ListenPacket(network, address)
+	if err != nil {
+		return nil, err
+	}
+	e.conn = conn
+
+	if privileged {
+		e.dst = &net.IPAddr{IP: ip}
+	} else {
+		e.dst = &net.UDPAddr{IP: ip}
+	}
+	return e, nil
+}
+
+func (e *socketEchoer) method() string {
+	if e.privileged {
+		return MethodRaw
+	}
+	return MethodUDP
+}
+
+func (e *socketEchoer) Close() error {
+	return e.conn.Close()
+}
+
+// probe 发送Echo请求并等待匹配的Echo应答，或引用了该请求的超时、不可达报文
+func (e *socketEchoer) probe(seq, ttl int, timeout time.Duration) (hopReply, error) {
+	var requestType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	protocol := protocolICMP
+	if e.v6 {
+		requestType, replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+		protocol = protocolIPv6ICMP
+	}
+
+	if ttl > 0 {
+		if err := e.setTTL(ttl); err != nil {
+			return hopReply{}, err
+		}
+	}
+
+	msg := icmp.Message{
+		Type: requestType,
+		Body: &icmp.Echo{ID: e.id, Seq: seq & 0xffff, Data: []byte("SysSpector")},
+	}
+	request, err := msg.Marshal(nil)
+	if err != nil {
+		return hopReply{}, err
+	}
+
+	start := time.Now()
+	if _, err := e.conn.WriteTo(request, e.dst); err != nil {
+		return hopReply{}, err
+	}
+	if err := e.conn.SetReadDeadline(start.Add(timeout)); err != nil {
+		return hopReply{}, err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := e.conn.ReadFrom(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return hopReply{}, errPingTimeout
+			}
+			return hopReply{}, err
+		}
+		reply := hopReply{peer: peerIP(peer), rtt: time.Since(start)}
+
+		message, err := icmp.ParseMessage(protocol, buf[:n])
+		if err != nil {
+			continue
+		}
+		switch body := message.Body.(type) {
+		case *icmp.Echo:
+			if message.Type != replyType || !reply.peer.Equal(e.ip) || !e.matches(body.ID, body.Seq, seq) {
+				continue
+			}
+			reply.kind = replyEcho
+		case *icmp.TimeExceeded:
+			id, quotedSeq, ok := quotedEcho(body.Data, e.v6)
+			if !ok || !e.matches(id, quotedSeq, seq) {
+				continue
+			}
+			reply.kind = replyTimeExceeded
+		case *icmp.DstUnreach:
+			id, quotedSeq, ok := quotedEcho(body.Data, e.v6)
+			if !ok || !e.matches(id, quotedSeq, seq) {
+				continue
+			}
+			reply.kind = replyUnreachable
+		default:
+			continue
+		}
+		return reply, nil
+	}
+}
+
+// setTTL 设置后续请求的TTL或跳数限制
+func (e *socketEchoer) setTTL(ttl int) error {
+	if e.v6 {
+		return e.conn.IPv6PacketConn().SetHopLimit(ttl)
+	}
+	return e.conn.IPv4PacketConn().SetTTL(ttl)
+}
+
+// matches 判断应答的标识符和序号是否对应本次请求
+// 非特权套接字的标识符可能被内核改写，只比较序号
+func (e *socketEchoer) matches(id, replySeq, seq int) bool {
+	if replySeq != seq&0xffff {
+		return false
+	}
+	return !e.privileged || id == e.id
+}
+
+// quotedEcho 从超时、不可达报文引用的原始数据包中取出Echo请求的标识符和序号
+func quotedEcho(data []byte, v6 bool) (id, seq int, ok bool) {
+	headerLen, requestType := 40, byte(128)
+	if !v6 {
+		if len(data) < 20 {
+			return 0, 0, false
+		}
+		headerLen, requestType = int(data[0]&0x0f)*4, 8
+	}
+	if len(data) < headerLen+8 || data[headerLen] != requestType {
+		return 0, 0, false
+	}
+	id = int(binary.BigEndian.Uint16(data[headerLen+4:]))
+	seq = int(binary.BigEndian.Uint16(data[headerLen+6:]))
+	return id, seq, true
+}
+
+// peerIP 从对端地址中取出IP
+func peerIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.IPAddr:
+		return a.IP
+	case *net.UDPAddr:
+		return a.IP
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+// +build !windows
+
+package netprobe
+
+import (
+	"errors"
+	"net"
+)
+
+// preferPlatformEchoer 非Windows平台优先使用ICMP套接字
+const preferPlatformEchoer = false
+
+// newPlatformEchoer 非Windows平台没有额外的实现
+func newPlatformEchoer(ip net.IP) (echoer, error) {
+	return nil, errors.New("no permission to open icmp socket")
+}
@@ -0,0 +1,166 @@
+//go:build windows
+// +build windows
+
+package netprobe
+
+import (
+	"errors"
+	"net"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// Windows ICMP API（iphlpapi.dll），普通用户即可使用
+var (
+	iphlpapi            = syscall.NewLazyDLL("iphlpapi.dll")
+	procIcmpCreateFile  = iphlpapi.NewProc("IcmpCreateFile")
+	procIcmpCloseHandle = iphlpapi.NewProc("IcmpCloseHandle")
+	procIcmpSendEcho    = iphlpapi.NewProc("IcmpSendEcho")
+)
+
+// preferPlatformEchoer Windows原始套接字需要管理员权限且绑定0.0.0.0时可能收不到应答，因此优先使用ICMP API
+const preferPlatformEchoer = true
+
+// ICMP_ECHO_REPLY.Status的取值
+const (
+	ipSuccess             = 0     // IP_SUCCESS
+	ipDestNetUnreachable  = 11002 // IP_DEST_NET_UNREACHABLE
+	ipDestPortUnreachable = 11005 // IP_DEST_PORT_UNREACHABLE
+	ipPacketTooBig        = 11009 // IP_PACKET_TOO_BIG
+	ipReqTimedOut         = 11010 // IP_REQ_TIMED_OUT
+	ipTTLExpiredTransit   = 11013 // IP_TTL_EXPIRED_TRANSIT
+)
+
+// ipFlagDF 对应IP_FLAG_DF，设置IPv4头部的不分片标志
+const ipFlagDF = 0x2
+
+// defaultTTL 需要通过IP_OPTION_INFORMATION设置标志但不限制TTL时使用的TTL
+const defaultTTL = 128
+
+// ipOptionInformation 对应IP_OPTION_INFORMATION
+type ipOptionInformation struct {
+	TTL         uint8
+	TOS         uint8
+	Flags       uint8
+	OptionsSize uint8
+	OptionsData uintptr
+}
+
+// icmpEchoReply 对应ICMP_ECHO_REPLY
+type icmpEchoReply struct {
+	Address       uint32
+	Status        uint32
+	RoundTripTime uint32
+	DataSize      uint16
+	Reserved      uint16
+	Data          uintptr
+	Options       ipOptionInformation
+}
+
+// icmpAPIEchoer 基于IcmpSendEcho的实现，只支持IPv4
+type icmpAPIEchoer struct {
+	handle uintptr
+	addr   uint32
+}
+
+// newPlatformEchoer 没有原始套接字权限时使用Windows ICMP API
+func newPlatformEchoer(ip net.IP) (echoer, error) {
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return nil, errors.New("icmp api only supports ipv4")
+	}
+	if err := procIcmpCreateFile.Find(); err != nil {
+		return nil, err
+	}
+	handle, _, err := procIcmpCreateFile.Call()
+	if handle == uintptr(syscall.InvalidHandle) {
+		return nil, err
+	}
+	// IPAddr为网络字节序，内存中的字节顺序与IP地址一致
+	addr := *(*uint32)(unsafe.Pointer(&ip4[0]))
+	return &icmpAPIEchoer{handle: handle, addr: addr}, nil
+}
+
+func (e *icmpAPIEchoer) method() string {
+	return MethodICMPAPI
+}
+
+func (e *icmpAPIEchoer) Close() error {
+	procIcmpCloseHandle.Call(e.handle)
+	return nil
+}
+
+func (e *icmpAPIEchoer) probe(seq, ttl int, timeout time.Duration) (hopReply, error) {
+	// ttl大于0时通过IP_OPTION_INFORMATION设置请求的TTL
+	var options *ipOptionInformation
+	if ttl > 0 {
+		options = &ipOptionInformation{TTL: uint8(ttl)}
+	}
+	return e.send([]byte("SysSpector"), options, timeout)
+}
+
+// send 发送一个Echo请求并等待应答，options为nil时使用系统默认的IP选项
+func (e *icmpAPIEchoer) send(request []byte, options *ipOptionInformation, timeout time.Duration) (hopReply, error) {
+	reply := make([]byte, int(unsafe.Sizeof(icmpEchoReply{}))+len(request)+8)
+
+	start := time.Now()
+	n, _, err := procIcmpSendEcho.Call(
+		e.handle,
+		uintptr(e.addr),
+		uintptr(unsafe.Pointer(&request[0])),
+		uintptr(len(request)),
+		uintptr(unsafe.Pointer(options)),
+		uintptr(unsafe.Pointer(&reply[0])),
+		uintptr(len(reply)),
+		uintptr(timeout.Milliseconds()),
+	)
+	rtt := time.Since(start)
+	if n == 0 {
+		if errno, ok := err.(syscall.Errno); ok && errno == ipReqTimedOut {
+			return hopReply{}, errPingTimeout
+		}
+		// 数据包超过本机接口MTU时直接返回IP_PACKET_TOO_BIG
+		if errno, ok := err.(syscall.Errno); ok && errno == ipPacketTooBig {
+			return hopReply{kind: replyTooBig}, nil
+		}
+		return hopReply{}, err
+	}
+
+	echoReply := (*icmpEchoReply)(unsafe.Pointer(&reply[0]))
+	// Address为网络字节序，按内存中的字节顺序还原IP地址
+	address := (*[4]byte)(unsafe.Pointer(&echoReply.Address))
+	result := hopReply{peer: net.IPv4(address[0], address[1], address[2], address[3]), rtt: rtt}
+	switch {
+	case echoReply.Status == ipSuccess:
+		result.kind = replyEcho
+	case echoReply.Status == ipTTLExpiredTransit:
+		result.kind = replyTimeExceeded
+	case echoReply.Status >= ipDestNetUnreachable && echoReply.Status <= ipDestPortUnreachable:
+		result.kind = replyUnreachable
+	case echoReply.Status == ipPacketTooBig:
+		result.kind = replyTooBig
+	default:
+		return hopReply{}, errPingTimeout
+	}
+	return result, nil
+}
+
+// icmpDFProber 通过ICMP API发送设置了不分片标志的Echo请求
+// ICMP API不提供需要分片报文中的下一跳MTU
+type icmpDFProber struct {
+	*icmpAPIEchoer
+}
+
+// newDFProber Windows使用ICMP API，无需管理员权限
+func newDFProber(ip net.IP) (dfProber, error) {
+	e, err := newPlatformEchoer(ip)
+	if err != nil {
+		return nil, err
+	}
+	return icmpDFProber{e.(*icmpAPIEchoer)}, nil
+}
+
+func (p icmpDFProber) probe(seq, size int, timeout time.Duration) (hopReply, error) {
+	return p.send(echoPayload(size), &ipOptionInformation{TTL: defaultTTL, Flags: ipFlagDF}, timeout)
+}
@@ -0,0 +1,127 @@
+package netprobe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PublicIPTimeout 每个公网IP查询服务的超时
+const PublicIPTimeout = 5 * time.Second
+
+// PublicIPResult 表示通过多个查询服务得到的公网出口地址
+type PublicIPResult struct {
+	IP        string   // 多数服务返回的地址
+	Agree     int      // 返回该地址的服务数
+	Responded int      // 成功返回地址的服务数
+	Others    []string // 与多数结果不一致的地址，分流代理或多出口时会出现
+}
+
+// LookupPublicIP 同时请求所有查询服务，network为tcp4或tcp6时强制使用对应的协议访问服务
+// 服务返回纯文本或{"ip": "..."}格式的JSON，取多数服务一致的地址，数量相同时按服务的顺序优先
+func LookupPublicIP(network string, endpoints []string, timeout time.Duration) (PublicIPResult, error) {
+	var result PublicIPResult
+	dialer := &net.Dialer{Timeout: timeout}
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: func(ctx context.Context, _, addr string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, addr)
+			},
+			DisableKeepAlives: true,
+		},
+	}
+
+	answers := make([]string, len(endpoints))
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func(i int, endpoint string) {
+			defer wg.Done()
+			answers[i], errs[i] = queryPublicIP(client, endpoint, network)
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	counts := make(map[string]int)
+	var lastErr error
+	for i, answer := range answers {
+		if errs[i] != nil {
+			lastErr = errs[i]
+			continue
+		}
+		counts[answer]++
+		result.Responded++
+	}
+	if result.Responded == 0 {
+		if lastErr == nil {
+			lastErr = errors.New("no public ip endpoint")
+		}
+		return result, lastErr
+	}
+
+	for _, answer := range answers {
+		if answer == "" {
+			continue
+		}
+		if counts[answer] > result.Agree {
+			result.IP, result.Agree = answer, counts[answer]
+		}
+	}
+	for _, answer := range answers {
+		if answer != "" && answer != result.IP && !contains(result.Others, answer) {
+			result.Others = append(result.Others, answer)
+		}
+	}
+	return result, nil
+}
+
+// queryPublicIP 请求一个查询服务并校验返回的地址属于要求的协议
+func queryPublicIP(client *http.Client, endpoint, network string) (string, error) {
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.New(endpoint + ": " + resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", err
+	}
+
+	text := strings.TrimSpace(string(body))
+	var result struct {
+		IP string `json:"ip"`
+	}
+	if json.Unmarshal(body, &result) == nil && result.IP != "" {
+		text = result.IP
+	}
+	ip := net.ParseIP(text)
+	if ip == nil {
+		return "", errors.New(endpoint + ": unexpected response")
+	}
+	if (ip.To4() != nil) != (network != "tcp6") {
+		return "", errors.New(endpoint + ": address family mismatch")
+	}
+	return ip.String(), nil
+}
+
+// contains 判断字符串切片中是否包含指定值
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
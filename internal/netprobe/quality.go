@@ -0,0 +1,172 @@
+package netprobe
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 负载下延迟测试的参数
+const (
+	qualityIdleProbes    = 10                     // 空闲时的握手次数
+	qualityProbeInterval = 200 * time.Millisecond // 握手间隔
+	qualityProbeTimeout  = 3 * time.Second        // 每次握手的超时
+	qualityWarmup        = 2 * time.Second        // 开始下载后等待带宽占满再测量
+)
+
+// LoadedLatencyResult 表示负载下延迟测试的结果
+type LoadedLatencyResult struct {
+	Idle       []time.Duration // 空闲时与服务器建立TCP连接的耗时
+	Loaded     []time.Duration // 下载占满带宽时建立TCP连接的耗时
+	LoadedLost int             // 负载下超时的握手次数
+	Throughput float64         // 负载期间的下载速率（字节/秒）
+}
+
+// Medians 返回空闲时和负载下握手耗时的中位数，没有样本时为0
+func (r LoadedLatencyResult) Medians() (idle, loaded time.Duration) {
+	return median(r.Idle), median(r.Loaded)
+}
+
+// median 返回样本的中位数
+func median(samples []time.Duration) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration{}, samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return percentile(sorted, 50)
+}
+
+// MeasureLoadedLatency 先测量空闲时与下载服务器建立TCP连接的耗时，再用多个并发连接持续下载占满带宽，
+// 同时反复新建TCP连接测量握手耗时。瓶颈链路的缓冲区过大时握手包要排在下载数据后面，
+// 负载下的延迟会远高于空闲时（缓冲区膨胀），这是空闲时的ping无法发现的
+func MeasureLoadedLatency(downloadURL string, streams int, duration time.Duration) (LoadedLatencyResult, error) {
+	var result LoadedLatencyResult
+	u, err := url.Parse(downloadURL)
+	if err != nil {
+		return result, err
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	address := net.JoinHostPort(u.Hostname(), port)
+
+	for i := 0; i < qualityIdleProbes; i++ {
+		if rtt, err := dialTime(address); err == nil {
+			result.Idle = append(result.Idle, rtt)
+		}
+		time.Sleep(qualityProbeInterval)
+	}
+	if len(result.Idle) == 0 {
+		return result, fmt.Errorf("cannot connect to %s", address)
+	}
+
+	// 关闭HTTP/2，保证每个下载使用独立的TCP连接
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ForceAttemptHTTP2 = false
+	transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	transport.DisableCompression = true
+	transport.MaxIdleConnsPerHost = streams
+	defer transport.CloseIdleConnections()
+	client := &http.Client{Transport: transport}
+
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+	var received int64
+	var downloadErr atomic.Value
+	var wg sync.WaitGroup
+	for i := 0; i < streams; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if err := download(ctx, client, downloadURL, &received); err != nil && ctx.Err() == nil {
+					downloadErr.Store(err)
+					time.Sleep(qualityProbeInterval)
+				}
+			}
+		}()
+	}
+
+	select {
+	case <-time.After(qualityWarmup):
+	case <-ctx.Done():
+	}
+	start, startBytes := time.Now(), atomic.LoadInt64(&received)
+	for ctx.Err() == nil {
+		if rtt, err := dialTime(address); err == nil {
+			result.Loaded = append(result.Loaded, rtt)
+		} else if ctx.Err() == nil {
+			result.LoadedLost++
+		}
+		select {
+		case <-time.After(qualityProbeInterval):
+		case <-ctx.Done():
+		}
+	}
+	if elapsed := time.Since(start).Seconds(); elapsed > 0 {
+		result.Throughput = float64(atomic.LoadInt64(&received)-startBytes) / elapsed
+	}
+	cancel()
+	wg.Wait()
+
+	if atomic.LoadInt64(&received) == 0 {
+		if err, ok := downloadErr.Load().(error); ok {
+			return result, fmt.Errorf("download failed: %v", err)
+		}
+		return result, errors.New("download failed")
+	}
+	return result, nil
+}
+
+// dialTime 返回建立一次TCP连接的耗时
+func dialTime(address string) (time.Duration, error) {
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", address, qualityProbeTimeout)
+	if err != nil {
+		return 0, err
+	}
+	rtt := time.Since(start)
+	conn.Close()
+	return rtt, nil
+}
+
+// download 下载一次文件并丢弃内容，累计收到的字节数
+func download(ctx context.Context, client *http.Client, downloadURL string, received *int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := resp.Body.Read(buf)
+		atomic.AddInt64(received, int64(n))
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package netprobe
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+)
+
+// STUN报文类型和属性（RFC 5389、RFC 5780）
+const (
+	stunBindingRequest  = 0x0001
+	stunBindingResponse = 0x0101
+	stunMagicCookie     = 0x2112a442
+	stunHeaderLen       = 20
+
+	stunAttrMappedAddress    = 0x0001
+	stunAttrChangeRequest    = 0x0003
+	stunAttrChangedAddress   = 0x0005
+	stunAttrXORMappedAddress = 0x0020
+	stunAttrOtherAddress     = 0x802c
+
+	stunChangeIPAndPort = 0x06 // CHANGE-REQUEST中同时更换IP和端口的标志
+)
+
+// STUNTimeout 每个STUN请求的默认超时
+const STUNTimeout = time.Second
+
+// STUNBinding 表示一个STUN服务器返回的外部映射地址
+type STUNBinding struct {
+	Server string        // STUN服务器
+	Mapped string        // 服务器看到的本机外部地址（IP:端口）
+	RTT    time.Duration // 请求往返时延
+}
+
+// NATProbe 表示从同一个本地UDP端口向多个STUN服务器发送请求的结果
+type NATProbe struct {
+	Local            string        // 本机UDP地址（IP:端口）
+	Bindings         []STUNBinding // 成功响应的服务器及其映射地址，最多两个不同IP的服务器
+	FilteringTested  bool          // 服务器支持更换源地址应答，已测试过滤行为
+	AcceptsAnySource bool          // 能收到从其他IP和端口发来的应答（完全锥形）
+}
+
+// stunResponse 表示解析后的Binding应答
+type stunResponse struct {
+	mapped *net.UDPAddr
+	other  *net.UDPAddr // 服务器的备用地址，支持更换源地址应答时才有
+}
+
+// ProbeNAT 从同一个本地UDP端口依次向STUN服务器发送Binding请求，直到两个不同IP的服务器响应
+// 比较两次的映射地址可以判断NAT的映射行为；第一个服务器支持CHANGE-REQUEST时再测试过滤行为。只支持IPv4
+func ProbeNAT(servers []string, timeout time.Duration) (NATProbe, error) {
+	var probe NATProbe
+
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return probe, err
+	}
+	defer conn.Close()
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+
+	var first *stunResponse
+	var firstAddr *net.UDPAddr
+	var lastErr error
+	for _, server := range servers {
+		addr, err := net.ResolveUDPAddr("udp4", server)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		// 同一个IP的服务器无法区分端点相关的映射
+		if firstAddr != nil && addr.IP.Equal(firstAddr.IP) {
+			continue
+		}
+
+		start := time.Now()
+		response, err := stunRequest(conn, addr, 0, timeout)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		probe.Bindings = append(probe.Bindings, STUNBinding{Server: server, Mapped: response.mapped.String(), RTT: time.Since(start)})
+
+		if first == nil {
+			first, firstAddr = &response, addr
+			// 通过UDP“连接”获取内核选择的源地址，不会发送数据包
+			if c, err := net.DialUDP("udp4", nil, addr); err == nil {
+				probe.Local = net.JoinHostPort(c.LocalAddr().(*net.UDPAddr).IP.String(), strconv.Itoa(port))
+				c.Close()
+			}
+			continue
+		}
+		break
+	}
+	if first == nil {
+		if lastErr == nil {
+			lastErr = errors.New("no stun server")
+		}
+		return probe, lastErr
+	}
+
+	if first.other != nil {
+		probe.FilteringTested = true
+		if _, err := stunRequest(conn, firstAddr, stunChangeIPAndPort, timeout); err == nil {
+			probe.AcceptsAnySource = true
+		}
+	}
+	return probe, nil
+}
+
+// stunRequest 发送Binding请求并等待事务ID匹配的应答，change不为0时附带CHANGE-REQUEST属性
+// 请求更换源地址时应答来自其他地址，因此不检查应答的来源；超时后重发一次
+func stunRequest(conn *net.UDPConn, server *net.UDPAddr, change uint32, timeout time.Duration) (stunResponse, error) {
+	var id [12]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return stunResponse{}, err
+	}
+
+	var attrs []byte
+	if change != 0 {
+		attrs = make([]byte, 8)
+		binary.BigEndian.PutUint16(attrs[0:], stunAttrChangeRequest)
+		binary.BigEndian.PutUint16(attrs[2:], 4)
+		binary.BigEndian.PutUint32(attrs[4:], change)
+	}
+	request := make([]byte, stunHeaderLen, stunHeaderLen+len(attrs))
+	binary.BigEndian.PutUint16(request[0:], stunBindingRequest)
+	binary.BigEndian.PutUint16(request[2:], uint16(len(attrs)))
+	binary.BigEndian.PutUint32(request[4:], stunMagicCookie)
+	copy(request[8:], id[:])
+	request = append(request, attrs...)
+
+	buf := make([]byte, 1500)
+	for attempt := 0; attempt < 2; attempt++ {
+		if _, err := conn.WriteToUDP(request, server); err != nil {
+			return stunResponse{}, err
+		}
+		if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+			return stunResponse{}, err
+		}
+		for {
+			n, _, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					break
+				}
+				return stunResponse{}, err
+			}
+			if n < stunHeaderLen || binary.BigEndian.Uint16(buf[0:]) != stunBindingResponse || !bytes.Equal(buf[8:20], id[:]) {
+				continue
+			}
+			return parseSTUNResponse(buf[:n])
+		}
+	}
+	return stunResponse{}, errors.New("stun request timeout")
+}
+
+// parseSTUNResponse 解析Binding应答中的映射地址和备用地址，优先使用XOR-MAPPED-ADDRESS
+func parseSTUNResponse(message []byte) (stunResponse, error) {
+	var response stunResponse
+	var mapped, xorMapped *net.UDPAddr
+
+	length := int(binary.BigEndian.Uint16(message[2:]))
+	if stunHeaderLen+length > len(message) {
+		return response, errors.New("truncated stun response")
+	}
+	attrs := message[stunHeaderLen : stunHeaderLen+length]
+	for len(attrs) >= 4 {
+		attrType := binary.BigEndian.Uint16(attrs[0:])
+		attrLen := int(binary.BigEndian.Uint16(attrs[2:]))
+		if 4+attrLen > len(attrs) {
+			break
+		}
+		value := attrs[4 : 4+attrLen]
+		switch attrType {
+		case stunAttrMappedAddress:
+			mapped = stunAddress(value, false)
+		case stunAttrXORMappedAddress:
+			xorMapped = stunAddress(value, true)
+		case stunAttrOtherAddress, stunAttrChangedAddress:
+			response.other = stunAddress(value, false)
+		}
+		// 属性按4字节对齐
+		next := 4 + (attrLen+3)&^3
+		if next > len(attrs) {
+			break
+		}
+		attrs = attrs[next:]
+	}
+
+	response.mapped = xorMapped
+	if response.mapped == nil {
+		response.mapped = mapped
+	}
+	if response.mapped == nil {
+		return response, errors.New("stun response has no mapped address")
+	}
+	return response, nil
+}
+
+// stunAddress 解析IPv4地址属性，xor为true时按XOR-MAPPED-ADDRESS的规则还原
+func stunAddress(value []byte, xor bool) *net.UDPAddr {
+	// 第2个字节为地址族，0x01表示IPv4
+	if len(value) < 8 || value[1] != 0x01 {
+		return nil
+	}
+	port := binary.BigEndian.Uint16(value[2:])
+	ip := net.IPv4(value[4], value[5], value[6], value[7])
+	if xor {
+		var cookie [4]byte
+		binary.BigEndian.PutUint32(cookie[:], stunMagicCookie)
+		port ^= uint16(stunMagicCookie >> 16)
+		ip = net.IPv4(value[4]^cookie[0], value[5]^cookie[1], value[6]^cookie[2], value[7]^cookie[3])
+	}
+	return &net.UDPAddr{IP: ip, Port: int(port)}
+}
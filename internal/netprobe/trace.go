@@ -0,0 +1,117 @@
+package netprobe
+
+import (
+	"sync"
+	"time"
+)
+
+// TraceOptions 表示路由跟踪参数
+type TraceOptions struct {
+	MaxHops int           // 最大跳数
+	Probes  int           // 每一跳发送的探测包数
+	Timeout time.Duration // 每个探测包的应答超时
+}
+
+// DefaultTraceOptions 默认最多30跳，每跳发送3个探测包，超时1秒
+var DefaultTraceOptions = TraceOptions{
+	MaxHops: 30,
+	Probes:  3,
+	Timeout: time.Second,
+}
+
+// TraceHop 表示路由上的一跳
+type TraceHop struct {
+	TTL  int             // 跳点序号
+	Addr string          // 返回应答的地址，所有探测包都超时时为空
+	RTTs []time.Duration // 每个探测包的往返时延，丢失的探测包为-1
+}
+
+// Loss 返回该跳的丢包率（百分比）
+func (h TraceHop) Loss() float64 {
+	if len(h.RTTs) == 0 {
+		return 100
+	}
+	lost := 0
+	for _, rtt := range h.RTTs {
+		if rtt < 0 {
+			lost++
+		}
+	}
+	return float64(lost) / float64(len(h.RTTs)) * 100
+}
+
+// Stats 返回该跳的最小、平均、最大往返时延和标准差
+func (h TraceHop) Stats() (min, avg, max, stddev time.Duration) {
+	return rttStats(h.RTTs)
+}
+
+// Trace 逐跳递增TTL发送ICMP Echo请求，记录每一跳返回超时报文的路由器和往返时延
+// 各跳使用独立的套接字并发探测，结果截断到第一个到达目标的跳点
+func Trace(host string, opts TraceOptions) ([]TraceHop, error) {
+	ip, err := resolveIP(host)
+	if err != nil {
+		return nil, err
+	}
+
+	hops := make([]TraceHop, opts.MaxHops)
+	reached := make([]bool, opts.MaxHops)
+	errs := make([]error, opts.MaxHops)
+
+	var wg sync.WaitGroup
+	for i := range hops {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ttl := i + 1
+			hops[i].TTL = ttl
+
+			e, err := newEchoer(ip)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer e.Close()
+
+			for probe := 0; probe < opts.Probes; probe++ {
+				// 序号在所有跳点之间唯一，避免并发的套接字误认其他跳点的应答
+				reply, err := e.probe(ttl*opts.Probes+probe, ttl, opts.Timeout)
+				if err != nil {
+					hops[i].RTTs = append(hops[i].RTTs, -1)
+					continue
+				}
+				hops[i].RTTs = append(hops[i].RTTs, reply.rtt)
+				if hops[i].Addr == "" && reply.peer != nil {
+					hops[i].Addr = reply.peer.String()
+				}
+				if reply.kind != replyTimeExceeded {
+					reached[i] = true
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// 所有跳点都无法打开套接字时返回错误
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed == len(hops) && failed > 0 {
+		return nil, errs[0]
+	}
+
+	for i := range hops {
+		if reached[i] {
+			return hops[:i+1], nil
+		}
+	}
+
+	// 没有到达目标时去掉末尾没有应答的跳点
+	last := len(hops)
+	for last > 0 && hops[last-1].Addr == "" {
+		last--
+	}
+	return hops[:last], nil
+}
@@ -0,0 +1,109 @@
+package report
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/sink"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// Device 生成本机的报告：概况、磁盘、电池、网络、延迟探测、安全设置和当前的告警
+func Device(info model.SystemInfo, collectedAt time.Time, alerts []sink.Alert) Document {
+	doc := Document{
+		Title:    "SysSpector 报告：" + info.Hostname,
+		Subtitle: "采集时间：" + formatTime(collectedAt),
+	}
+
+	overview := [][]string{
+		{"主机名", info.Hostname},
+		{"设备 ID", info.DeviceID},
+		{"系统", strings.TrimSpace(info.OS + " " + info.SystemVersion)},
+		{"型号", info.Model},
+		{"序列号", info.SerialNumber},
+		{"处理器", fmt.Sprintf("%s（%d 核）", info.CPU.Model, info.CPU.Cores)},
+		{"内存", fmt.Sprintf("%s，已用 %.1f%%", formatBytes(info.Memory.Total), info.MemoryUsage.UsedPerc)},
+		{"运行时间", info.UpTime},
+	}
+	if info.Firmware.Version != "" {
+		overview = append(overview, []string{"固件", strings.TrimSpace(info.Firmware.Vendor + " " + info.Firmware.Version)})
+	}
+	doc.Sections = append(doc.Sections, Section{Title: "概况", Rows: overview})
+
+	disks := Section{Title: "磁盘", Header: []string{"挂载点", "文件系统", "容量", "已用", "使用率"}, Empty: "没有磁盘使用信息"}
+	for _, partition := range info.DiskUsage {
+		disks.Rows = append(disks.Rows, []string{partition.MountPoint, partition.Filesystem,
+			formatBytes(partition.Total), formatBytes(partition.Used), fmt.Sprintf("%.1f%%", partition.UsedPerc)})
+	}
+	doc.Sections = append(doc.Sections, disks)
+
+	if battery := info.Battery; battery.IsPresent {
+		rows := [][]string{
+			{"电量", fmt.Sprintf("%d%%", battery.Percentage)},
+			{"正在充电", yesNo(battery.IsCharging)},
+			{"循环次数", strconv.Itoa(battery.CycleCount)},
+		}
+		if battery.HealthVerdict != "" {
+			rows = append(rows, []string{"健康状况", fmt.Sprintf("%s（%.0f%%）", battery.HealthVerdict, battery.HealthPercent)})
+		}
+		doc.Sections = append(doc.Sections, Section{Title: "电池", Rows: rows})
+	}
+
+	network := info.Network
+	networkRows := [][]string{
+		{"IP", network.IP},
+		{"公网 IP", network.PublicIP},
+	}
+	if network.WiFi.IsConnected {
+		networkRows = append(networkRows, []string{"WiFi", fmt.Sprintf("%s（%d dBm）", network.WiFi.SSID, network.WiFi.RSSI)})
+	}
+	if network.VPN.IsConnected {
+		networkRows = append(networkRows, []string{"VPN", network.VPN.Provider})
+	}
+	if health := network.HealthScore; health.Grade != "" {
+		networkRows = append(networkRows, []string{"网络健康评分", fmt.Sprintf("%d（%s）：%s", health.Score, health.Grade, health.Verdict)})
+		for _, deduction := range health.Deductions {
+			networkRows = append(networkRows, []string{"扣分项", deduction})
+		}
+	}
+	doc.Sections = append(doc.Sections, Section{Title: "网络", Rows: networkRows})
+
+	latency := Section{Title: "延迟探测", Header: []string{"目标", "协议", "平均延迟", "P95", "丢包率"}, Empty: "没有延迟探测结果"}
+	for _, target := range network.Latency.Targets {
+		latency.Rows = append(latency.Rows, []string{target.TargetName, target.Protocol,
+			fmt.Sprintf("%.1f ms", target.AvgLatency), fmt.Sprintf("%.1f ms", target.P95), fmt.Sprintf("%.1f%%", target.PacketLoss)})
+	}
+	doc.Sections = append(doc.Sections, latency)
+
+	encryption := info.DiskEncryption.SoftwareName
+	if encryption == "" {
+		encryption = "磁盘加密"
+	}
+	encrypted := yesNo(info.DiskEncryption.SoftwareEnabled || info.DiskEncryption.HardwareEncrypted)
+	if info.DiskEncryption.Unknown {
+		encrypted = "未知"
+	}
+	doc.Sections = append(doc.Sections, Section{Title: "安全", Rows: [][]string{
+		{encryption, encrypted},
+		{"防火墙", yesNo(info.Firewall.Enabled)},
+	}})
+
+	doc.Sections = append(doc.Sections, alertSection(alerts))
+	return doc
+}
+
+// alertSection 生成告警一节，严重告警在前
+func alertSection(alerts []sink.Alert) Section {
+	section := Section{Title: "告警", Header: []string{"严重程度", "来源", "告警", "详情"}, Empty: "没有告警"}
+	for _, severity := range []string{config.SeverityCritical, config.SeverityWarning} {
+		for _, alert := range alerts {
+			if alert.Severity == severity {
+				section.Rows = append(section.Rows, []string{alert.Severity, alert.Source, alert.Title, alert.Message})
+			}
+		}
+	}
+	return section
+}
@@ -0,0 +1,71 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/aggregator"
+)
+
+// lowHealthScore 设备清单汇总中列出网络健康评分低于该值的设备
+const lowHealthScore = 60
+
+// Fleet 生成设备清单的汇总：设备数、按系统和型号的分布、超过staleDays天没有上报的设备、网络健康评分较低的设备和全部设备
+func Fleet(devices []aggregator.Device, now time.Time, staleDays int) Document {
+	doc := Document{
+		Title:    "SysSpector 设备清单汇总",
+		Subtitle: fmt.Sprintf("生成时间：%s，共 %d 台设备", formatTime(now), len(devices)),
+	}
+	doc.Sections = append(doc.Sections,
+		countSection("按系统", "系统", devices, func(d aggregator.Device) string { return d.OS }),
+		countSection("按型号", "型号", devices, func(d aggregator.Device) string { return d.Model }))
+
+	staleBefore := now.Add(-time.Duration(staleDays) * 24 * time.Hour)
+	stale := Section{Title: fmt.Sprintf("超过 %d 天没有上报", staleDays),
+		Header: []string{"主机名", "设备 ID", "序列号", "最近上报"}, Empty: "没有"}
+	low := Section{Title: fmt.Sprintf("网络健康评分低于 %d", lowHealthScore),
+		Header: []string{"主机名", "IP", "评分", "最近上报"}, Empty: "没有"}
+	all := Section{Title: "全部设备", Header: []string{"主机名", "系统", "型号", "序列号", "固件版本", "IP", "评分", "最近上报"},
+		Empty: "还没有设备上报"}
+	for _, d := range devices {
+		if d.CollectedAt.Before(staleBefore) {
+			stale.Rows = append(stale.Rows, []string{d.Hostname, d.DeviceID, d.SerialNumber, formatTime(d.CollectedAt)})
+		}
+		if d.HealthScore < lowHealthScore {
+			low.Rows = append(low.Rows, []string{d.Hostname, d.IP, strconv.Itoa(d.HealthScore), formatTime(d.CollectedAt)})
+		}
+		all.Rows = append(all.Rows, []string{d.Hostname, d.OS + " " + d.SystemVersion, d.Model, d.SerialNumber,
+			d.FirmwareVersion, d.IP, strconv.Itoa(d.HealthScore), formatTime(d.CollectedAt)})
+	}
+	doc.Sections = append(doc.Sections, stale, low, all)
+	return doc
+}
+
+// countSection 按key统计设备数，按设备数从多到少排列
+func countSection(title, name string, devices []aggregator.Device, key func(aggregator.Device) string) Section {
+	counts := make(map[string]int)
+	for _, d := range devices {
+		value := key(d)
+		if value == "" {
+			value = "未知"
+		}
+		counts[value]++
+	}
+	values := make([]string, 0, len(counts))
+	for value := range counts {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if counts[values[i]] != counts[values[j]] {
+			return counts[values[i]] > counts[values[j]]
+		}
+		return values[i] < values[j]
+	})
+	section := Section{Title: title, Header: []string{name, "设备数"}, Empty: "还没有设备上报"}
+	for _, value := range values {
+		section.Rows = append(section.Rows, []string{value, strconv.Itoa(counts[value])})
+	}
+	return section
+}
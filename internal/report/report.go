@@ -0,0 +1,112 @@
+// Package report 将快照或设备清单渲染为Markdown或HTML报告，用于定期发送的邮件报告
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// Document 表示一份报告，由标题和若干节组成
+type Document struct {
+	Title    string
+	Subtitle string // 标题下方的说明，例如采集时间
+	Sections []Section
+}
+
+// Section 表示报告中的一节：有表头时为表格，否则每行为"名称、值"两列；没有数据时显示Empty
+type Section struct {
+	Title  string
+	Header []string
+	Rows   [][]string
+	Empty  string
+}
+
+// Markdown 将报告渲染为Markdown文本
+func (d Document) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", d.Title)
+	if d.Subtitle != "" {
+		fmt.Fprintf(&b, "%s\n\n", d.Subtitle)
+	}
+	for _, section := range d.Sections {
+		fmt.Fprintf(&b, "## %s\n\n", section.Title)
+		if len(section.Rows) == 0 {
+			fmt.Fprintf(&b, "%s\n\n", section.Empty)
+			continue
+		}
+		header := section.Header
+		if header == nil {
+			header = []string{"项目", "值"}
+		}
+		writeMarkdownRow(&b, header)
+		separator := make([]string, len(header))
+		for i := range separator {
+			separator[i] = "---"
+		}
+		writeMarkdownRow(&b, separator)
+		for _, row := range section.Rows {
+			writeMarkdownRow(&b, row)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// writeMarkdownRow 写入表格的一行，转义单元格中的竖线和换行
+func writeMarkdownRow(b *strings.Builder, cells []string) {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = strings.NewReplacer("|", `\|`, "\n", " ").Replace(cell)
+	}
+	fmt.Fprintf(b, "| %s |\n", strings.Join(escaped, " | "))
+}
+
+// htmlTemplate 渲染HTML报告，样式写在元素上，因为很多邮件客户端会忽略<style>
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body style="font-family: -apple-system, 'Segoe UI', 'PingFang SC', 'Microsoft YaHei', sans-serif; color: #222; font-size: 14px;">
+<h1 style="font-size: 20px;">{{.Title}}</h1>
+{{if .Subtitle}}<p style="color: #666;">{{.Subtitle}}</p>{{end}}
+{{range .Sections}}<h2 style="font-size: 16px; margin-top: 24px;">{{.Title}}</h2>
+{{if .Rows}}<table style="border-collapse: collapse;">
+{{if .Header}}<tr>{{range .Header}}<th style="border: 1px solid #ddd; padding: 4px 8px; background: #f5f5f5; text-align: left;">{{.}}</th>{{end}}</tr>
+{{end}}{{range .Rows}}<tr>{{range .}}<td style="border: 1px solid #ddd; padding: 4px 8px;">{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{else}}<p style="color: #666;">{{.Empty}}</p>
+{{end}}{{end}}</body>
+</html>
+`))
+
+// HTML 将报告渲染为HTML
+func (d Document) HTML() (string, error) {
+	var b bytes.Buffer
+	if err := htmlTemplate.Execute(&b, d); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// formatBytes 以GB为单位格式化容量
+func formatBytes(n uint64) string {
+	return fmt.Sprintf("%.2f GB", float64(n)/(1024*1024*1024))
+}
+
+// formatTime 格式化报告中的时间，零值为"-"
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+// yesNo 将布尔值显示为"是"或"否"
+func yesNo(b bool) string {
+	if b {
+		return "是"
+	}
+	return "否"
+}
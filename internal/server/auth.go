@@ -0,0 +1,177 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+)
+
+// scopeLevels 权限范围的级别，级别高的令牌可以访问级别低的接口
+var scopeLevels = map[string]int{config.ScopeRead: 1, config.ScopeCollect: 2, config.ScopeAdmin: 3}
+
+// 校验访问令牌的错误
+var (
+	errUnauthorized = errors.New("unauthorized")
+	errForbidden    = errors.New("token scope is insufficient")
+)
+
+// SetTokens 设置REST和gRPC接口的访问令牌，为空时不校验令牌；重新加载配置时替换，用于轮换令牌
+func (s *Server) SetTokens(tokens []config.APIToken) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.tokens = tokens
+}
+
+// checkToken 校验令牌是否有效且权限范围不低于scope，没有配置令牌时不校验
+func (s *Server) checkToken(presented, scope, resource string) error {
+	s.hooksMu.RLock()
+	tokens := s.tokens
+	s.hooksMu.RUnlock()
+	if len(tokens) == 0 {
+		return nil
+	}
+	token := matchToken(tokens, presented, time.Now())
+	if token == nil {
+		return errUnauthorized
+	}
+	if scopeLevels[token.Scope] < scopeLevels[scope] {
+		log.Printf("Token %s (%s) is not allowed to access %s", token.Name, token.Scope, resource)
+		return errForbidden
+	}
+	return nil
+}
+
+// matchToken 返回与presented一致且未过期的令牌，没有时返回nil
+func matchToken(tokens []config.APIToken, presented string, now time.Time) *config.APIToken {
+	if presented == "" {
+		return nil
+	}
+	sum := sha256.Sum256([]byte(presented))
+	digest := hex.EncodeToString(sum[:])
+	for i := range tokens {
+		token := &tokens[i]
+		if !token.ExpiresAt.IsZero() && now.After(token.ExpiresAt) {
+			continue
+		}
+		expected, candidate := token.Token, presented
+		if hashed, ok := strings.CutPrefix(token.Token, "sha256:"); ok {
+			expected, candidate = strings.ToLower(hashed), digest
+		}
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(expected)) == 1 {
+			return token
+		}
+	}
+	return nil
+}
+
+// checkHost 没有配置访问令牌时只接受Host为localhost、回环地址或监听地址的请求，防止DNS重绑定：
+// 网站将自己的域名重新解析到本机地址后，浏览器把该网站页面对本机API的请求当作同源请求，不需要令牌即可读取结果。
+// 监听所有地址时也接受IP地址形式的Host，IP地址不经过DNS解析，局域网中的客户端仍然可以访问
+func (s *Server) checkHost(listen string, next http.Handler) http.Handler {
+	listenHost, _, _ := net.SplitHostPort(listen)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.hooksMu.RLock()
+		tokens := s.tokens
+		s.hooksMu.RUnlock()
+		if len(tokens) == 0 && !allowedHost(r.Host, listenHost) {
+			writeJSON(w, http.StatusForbidden, errorResponse{Error: "host " + r.Host + " is not allowed without server tokens"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost 判断请求的Host是否为localhost、回环地址、监听地址，或监听所有地址时的任意IP地址
+func allowedHost(host, listenHost string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+	if strings.EqualFold(host, "localhost") || listenHost != "" && strings.EqualFold(host, listenHost) {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() {
+		return true
+	}
+	listenIP := net.ParseIP(listenHost)
+	return listenHost == "" || listenIP != nil && listenIP.IsUnspecified()
+}
+
+// requiredScope 返回REST请求需要的权限范围，为空时不需要令牌
+func requiredScope(r *http.Request) string {
+	switch r.URL.Path {
+	case "/healthz":
+		// 供负载均衡和服务编排的健康检查使用，只返回状态
+		return ""
+	case "/v1/reload":
+		return config.ScopeAdmin
+	case "/v1/commands":
+		return config.ScopeCollect
+	}
+	if refresh, _ := strconv.ParseBool(r.URL.Query().Get("refresh")); refresh {
+		return config.ScopeCollect
+	}
+	return config.ScopeRead
+}
+
+// authorize 按请求需要的权限范围校验Bearer令牌，令牌无效时返回401，权限不足时返回403
+// 浏览器的WebSocket无法设置请求头，/v1/stream 也可以通过access_token参数传递令牌
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope := requiredScope(r)
+		if scope == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok && r.URL.Path == "/v1/stream" {
+			token = r.URL.Query().Get("access_token")
+		}
+		switch err := s.checkToken(token, scope, r.URL.Path); err {
+		case nil:
+			next.ServeHTTP(w, r)
+		case errForbidden:
+			w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+			writeJSON(w, http.StatusForbidden, errorResponse{Error: err.Error()})
+		default:
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: err.Error()})
+		}
+	})
+}
+
+// authorizeGRPC 按权限范围校验gRPC请求元数据authorization中的Bearer令牌
+func (s *Server) authorizeGRPC(ctx context.Context, scope, method string) error {
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token, _ = strings.CutPrefix(values[0], "Bearer ")
+		}
+	}
+	switch err := s.checkToken(token, scope, method); err {
+	case nil:
+		return nil
+	case errForbidden:
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+}
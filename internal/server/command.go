@@ -0,0 +1,355 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/internal/config"
+)
+
+// 按需采集命令的参数
+const (
+	SignatureHeader = "X-SysSpector-Signature" // 请求体的Ed25519签名（base64），命令由服务器签名，执行结果由设备签名
+
+	maxCommandSize = 64 << 10         // 命令请求体的最大长度
+	commandTimeout = 5 * time.Minute  // 每个命令的最长执行时间
+	pollTimeout    = 2 * time.Minute  // 长轮询请求的超时，服务器没有命令时应在此之前返回204
+	pollRetry      = 30 * time.Second // 长轮询失败后等待的时间
+)
+
+// CommandRunner 执行一个采集项或探测，args为命令参数，返回的结果编码为JSON
+type CommandRunner func(ctx context.Context, args json.RawMessage) (interface{}, error)
+
+// Command 表示服务台或设备管理服务器下发的按需采集命令
+type Command struct {
+	ID       string          `json:"id"`        // 命令ID，有效期内同一个ID只执行一次
+	DeviceID string          `json:"device_id"` // 目标设备，必须与本机的设备ID一致
+	Action   string          `json:"action"`    // 采集项或探测，例如network、wifi、probe
+	Args     json.RawMessage `json:"args,omitempty"`
+	IssuedAt time.Time       `json:"issued_at"` // 签发时间，与本机时间相差超过有效期的命令被拒绝
+}
+
+// CommandResult 表示命令的执行结果
+type CommandResult struct {
+	ID         string      `json:"id"`
+	DeviceID   string      `json:"device_id"`
+	Action     string      `json:"action"`
+	StartedAt  time.Time   `json:"started_at"`
+	FinishedAt time.Time   `json:"finished_at"`
+	Result     interface{} `json:"result,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// ProbeArgs 表示probe命令的参数
+type ProbeArgs struct {
+	Host     string `json:"host"`
+	Protocol string `json:"protocol"` // icmp（默认）、tcp或http
+	Port     int    `json:"port"`
+	Count    int    `json:"count"` // 发送的数据包数，为0时使用默认值
+}
+
+// ProbeCommand 按命令参数（ProbeArgs）对目标执行一次延迟探测，与gRPC接口的RunProbe相同
+func ProbeCommand(ctx context.Context, args json.RawMessage) (interface{}, error) {
+	var probe ProbeArgs
+	if err := json.Unmarshal(args, &probe); err != nil {
+		return nil, fmt.Errorf("invalid probe args: %v", err)
+	}
+	if probe.Count > maxProbeCount {
+		return nil, fmt.Errorf("count must not exceed %d", maxProbeCount)
+	}
+	target := config.ProbeTarget{Host: probe.Host, Protocol: probe.Protocol, Port: probe.Port}
+	if err := target.Normalize(); err != nil {
+		return nil, err
+	}
+	return analysis.ProbeTarget(target, probe.Count)
+}
+
+// Commands 验证命令的签名、有效期和目标设备，并执行注册的采集项
+type Commands struct {
+	keys     []ed25519.PublicKey
+	signer   ed25519.PrivateKey
+	deviceID string
+	maxAge   time.Duration
+	runners  map[string]CommandRunner
+	executed *CommandLog
+}
+
+// CommandLog 记录有效期内执行过的命令ID，防止重放。serve模式重新加载配置时会创建新的Commands，
+// 同一个Server的Commands共用Server的CommandLog，重新加载前执行过的命令在有效期内仍然被拒绝
+type CommandLog struct {
+	mu     sync.Mutex
+	maxAge time.Duration // 使用过的最长有效期，有效期变短时仍按原有效期保留记录
+	seen   map[string]time.Time
+}
+
+// NewCommandLog 创建空的命令记录
+func NewCommandLog() *CommandLog {
+	return &CommandLog{seen: make(map[string]time.Time)}
+}
+
+// add 记录签发时间为issuedAt的命令id，清理超过有效期的记录；有效期内已经记录过时返回false
+func (l *CommandLog) add(id string, issuedAt time.Time, maxAge time.Duration) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if maxAge > l.maxAge {
+		l.maxAge = maxAge
+	}
+	now := time.Now()
+	for seenID, seenAt := range l.seen {
+		if now.Sub(seenAt) > l.maxAge {
+			delete(l.seen, seenID)
+		}
+	}
+	if _, ok := l.seen[id]; ok {
+		return false
+	}
+	l.seen[id] = issuedAt
+	return true
+}
+
+// NewCommands 创建按需采集命令的处理器，publicKeys为验证命令签名的Ed25519公钥（base64），
+// signer为本机签名执行结果的私钥，maxAge为命令的有效期，executed记录执行过的命令。没有公钥或设备ID时返回错误，
+// 否则任何人都可以构造出发给所有设备的命令
+func NewCommands(publicKeys []string, signer ed25519.PrivateKey, deviceID string, maxAge time.Duration, executed *CommandLog) (*Commands, error) {
+	if deviceID == "" {
+		return nil, errors.New("commands require a device ID")
+	}
+	if len(publicKeys) == 0 {
+		return nil, errors.New("commands require public keys")
+	}
+	if len(signer) != ed25519.PrivateKeySize {
+		return nil, errors.New("commands require a signing key")
+	}
+	keys := make([]ed25519.PublicKey, 0, len(publicKeys))
+	for _, encoded := range publicKeys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid public key %q", encoded)
+		}
+		keys = append(keys, key)
+	}
+	return &Commands{
+		keys:     keys,
+		signer:   signer,
+		deviceID: deviceID,
+		maxAge:   maxAge,
+		runners:  make(map[string]CommandRunner),
+		executed: executed,
+	}, nil
+}
+
+// Register 注册一个采集项或探测
+func (c *Commands) Register(action string, run CommandRunner) {
+	c.runners[action] = run
+}
+
+// Actions 返回已注册的采集项，按名称排序
+func (c *Commands) Actions() []string {
+	actions := make([]string, 0, len(c.runners))
+	for action := range c.runners {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// Sign 使用本机的私钥返回body的Ed25519签名（base64）
+func (c *Commands) Sign(body []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(c.signer, body))
+}
+
+// verifySignature 判断signature是否为任意一个公钥对body的签名
+func (c *Commands) verifySignature(body []byte, signature string) bool {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signature))
+	if err != nil || len(decoded) != ed25519.SignatureSize {
+		return false
+	}
+	for _, key := range c.keys {
+		if ed25519.Verify(key, body, decoded) {
+			return true
+		}
+	}
+	return false
+}
+
+// Verify 校验命令的签名、有效期、目标设备和是否重放，通过后返回解析出的命令
+func (c *Commands) Verify(body []byte, signature string) (Command, error) {
+	var cmd Command
+	if !c.verifySignature(body, signature) {
+		return cmd, errors.New("invalid signature")
+	}
+	if err := json.Unmarshal(body, &cmd); err != nil {
+		return cmd, fmt.Errorf("invalid command: %v", err)
+	}
+	if cmd.ID == "" || cmd.Action == "" {
+		return cmd, errors.New("command id and action are required")
+	}
+	if cmd.DeviceID != c.deviceID {
+		return cmd, fmt.Errorf("command is for device %q", cmd.DeviceID)
+	}
+	if age := time.Since(cmd.IssuedAt); age > c.maxAge || age < -c.maxAge {
+		return cmd, errors.New("command has expired")
+	}
+	if !c.executed.add(cmd.ID, cmd.IssuedAt, c.maxAge) {
+		return cmd, errors.New("command has already been executed")
+	}
+	return cmd, nil
+}
+
+// Run 执行一个已验证的命令，不支持的采集项和执行失败记录在结果的Error中
+func (c *Commands) Run(ctx context.Context, cmd Command) CommandResult {
+	result := CommandResult{ID: cmd.ID, DeviceID: c.deviceID, Action: cmd.Action, StartedAt: time.Now()}
+	run, ok := c.runners[cmd.Action]
+	if !ok {
+		result.Error = fmt.Sprintf("unknown action %q, supported actions: %s", cmd.Action, strings.Join(c.Actions(), ", "))
+	} else {
+		ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+		defer cancel()
+		value, err := run(ctx, cmd.Args)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Result = value
+		}
+	}
+	result.FinishedAt = time.Now()
+	log.Printf("Executed command %s (%s) in %s", cmd.ID, cmd.Action, result.FinishedAt.Sub(result.StartedAt).Round(time.Millisecond))
+	return result
+}
+
+// CommandLog 返回Server的命令记录，创建Commands时使用，使重新加载配置后仍然拒绝重放的命令
+func (s *Server) CommandLog() *CommandLog {
+	return s.executed
+}
+
+// SetCommands 设置按需采集命令的处理器，未设置时 /v1/commands 返回404
+func (s *Server) SetCommands(commands *Commands) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.commands = commands
+}
+
+// handleCommand 验证POST的命令并立即执行，返回执行结果
+func (s *Server) handleCommand(w http.ResponseWriter, r *http.Request) {
+	s.hooksMu.RLock()
+	commands := s.commands
+	s.hooksMu.RUnlock()
+	if commands == nil {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "commands are not enabled"})
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxCommandSize))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	cmd, err := commands.Verify(body, r.Header.Get(SignatureHeader))
+	if err != nil {
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, commands.Run(r.Context(), cmd))
+}
+
+// Poll 通过长轮询从pollURL获取命令，直到ctx被取消：GET pollURL?device_id=设备ID，
+// 服务器有命令时返回200和签名的命令，没有时返回204；执行结果以签名的JSON POST到pollURL
+func (c *Commands) Poll(ctx context.Context, pollURL, token string, client *http.Client) {
+	for ctx.Err() == nil {
+		if err := c.pollOnce(ctx, pollURL, token, client); err != nil && ctx.Err() == nil {
+			log.Printf("Error polling commands: %v", err)
+			select {
+			case <-time.After(pollRetry):
+			case <-ctx.Done():
+			}
+		}
+	}
+}
+
+// pollOnce 获取并执行一个命令
+func (c *Commands) pollOnce(ctx context.Context, pollURL, token string, client *http.Client) error {
+	u, err := url.Parse(pollURL)
+	if err != nil {
+		return err
+	}
+	query := u.Query()
+	query.Set("device_id", c.deviceID)
+	u.RawQuery = query.Encode()
+
+	reqCtx, cancel := context.WithTimeout(ctx, pollTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent:
+		return nil
+	default:
+		io.Copy(io.Discard, resp.Body)
+		return fmt.Errorf("command server returned HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCommandSize))
+	if err != nil {
+		return err
+	}
+	cmd, err := c.Verify(body, resp.Header.Get(SignatureHeader))
+	if err != nil {
+		return fmt.Errorf("reject command: %v", err)
+	}
+	return c.report(ctx, pollURL, token, client, c.Run(ctx, cmd))
+}
+
+// report 将命令的执行结果POST到命令服务器，请求体带有本机私钥的签名
+func (c *Commands) report(ctx context.Context, pollURL, token string, client *http.Client, result CommandResult) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pollURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, c.Sign(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("command server returned HTTP %d for result of %s", resp.StatusCode, result.ID)
+	}
+	return nil
+}
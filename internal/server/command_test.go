@@ -0,0 +1,133 @@
+package server
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// generateKey 生成测试用的Ed25519密钥对
+func generateKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return public, private
+}
+
+// signCommand 编码命令并返回请求体和key的签名
+func signCommand(t *testing.T, key ed25519.PrivateKey, cmd Command) ([]byte, string) {
+	t.Helper()
+	body, err := json.Marshal(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body, base64.StdEncoding.EncodeToString(ed25519.Sign(key, body))
+}
+
+// newTestCommands 创建设备ID为device、有效期为5分钟的命令处理器
+func newTestCommands(t *testing.T, public ed25519.PublicKey, executed *CommandLog) *Commands {
+	t.Helper()
+	_, deviceKey := generateKey(t)
+	commands, err := NewCommands([]string{base64.StdEncoding.EncodeToString(public)}, deviceKey, "device", 5*time.Minute, executed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return commands
+}
+
+func TestCommandsVerify(t *testing.T) {
+	serverPublic, serverKey := generateKey(t)
+	_, otherKey := generateKey(t)
+	now := time.Now()
+	command := func(id, deviceID, action string, issuedAt time.Time) Command {
+		return Command{ID: id, DeviceID: deviceID, Action: action, IssuedAt: issuedAt}
+	}
+
+	tests := []struct {
+		name    string
+		key     ed25519.PrivateKey
+		cmd     Command
+		tamper  bool // 签名后修改请求体
+		wantErr bool
+	}{
+		{"valid", serverKey, command("1", "device", "network", now), false, false},
+		{"slightly in the future", serverKey, command("1", "device", "network", now.Add(time.Minute)), false, false},
+		{"unknown key", otherKey, command("1", "device", "network", now), false, true},
+		{"tampered body", serverKey, command("1", "device", "network", now), true, true},
+		{"other device", serverKey, command("1", "other", "network", now), false, true},
+		{"missing id", serverKey, command("", "device", "network", now), false, true},
+		{"missing action", serverKey, command("1", "device", "", now), false, true},
+		{"expired", serverKey, command("1", "device", "network", now.Add(-10*time.Minute)), false, true},
+		{"issued too far in the future", serverKey, command("1", "device", "network", now.Add(10*time.Minute)), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commands := newTestCommands(t, serverPublic, NewCommandLog())
+			body, signature := signCommand(t, tt.key, tt.cmd)
+			if tt.tamper {
+				body = bytes.Replace(body, []byte(`"network"`), []byte(`"snapshot"`), 1)
+			}
+			cmd, err := commands.Verify(body, signature)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cmd.ID != tt.cmd.ID {
+				t.Errorf("Verify() id = %q, want %q", cmd.ID, tt.cmd.ID)
+			}
+		})
+	}
+}
+
+func TestCommandsVerifySignature(t *testing.T) {
+	serverPublic, serverKey := generateKey(t)
+	commands := newTestCommands(t, serverPublic, NewCommandLog())
+	body, signature := signCommand(t, serverKey, Command{ID: "1", DeviceID: "device", Action: "network", IssuedAt: time.Now()})
+
+	tests := []struct {
+		name      string
+		signature string
+	}{
+		{"empty", ""},
+		{"not base64", "not a signature"},
+		{"truncated", signature[:len(signature)/2]},
+		{"signature of other body", base64.StdEncoding.EncodeToString(ed25519.Sign(serverKey, []byte("{}")))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := commands.Verify(body, tt.signature); err == nil {
+				t.Fatal("Verify() accepted an invalid signature")
+			}
+		})
+	}
+}
+
+func TestCommandsVerifyReplay(t *testing.T) {
+	serverPublic, serverKey := generateKey(t)
+	executed := NewCommandLog()
+	first := newTestCommands(t, serverPublic, executed)
+	body, signature := signCommand(t, serverKey, Command{ID: "1", DeviceID: "device", Action: "network", IssuedAt: time.Now()})
+
+	if _, err := first.Verify(body, signature); err != nil {
+		t.Fatalf("first Verify() error = %v", err)
+	}
+	if _, err := first.Verify(body, signature); err == nil {
+		t.Fatal("Verify() accepted a replayed command")
+	}
+
+	// 重新加载配置后创建的Commands共用同一个CommandLog，仍然拒绝重放
+	reloaded := newTestCommands(t, serverPublic, executed)
+	if _, err := reloaded.Verify(body, signature); err == nil {
+		t.Fatal("Verify() accepted a command replayed after reload")
+	}
+
+	body, signature = signCommand(t, serverKey, Command{ID: "2", DeviceID: "device", Action: "network", IssuedAt: time.Now()})
+	if _, err := reloaded.Verify(body, signature); err != nil {
+		t.Fatalf("Verify() of a new command error = %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	sysspectorv1 "github.com/AsterZephyr/SysSpector/api/sysspector/v1"
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// maxProbeCount RunProbe一次最多发送的数据包数
+const maxProbeCount = 100
+
+// grpcService 实现api/sysspector/v1中定义的gRPC服务，与REST API共用采集缓存
+type grpcService struct {
+	sysspectorv1.UnimplementedSysSpectorServer
+	server *Server
+}
+
+// ServeGRPC 在指定地址上提供gRPC服务，设置了TLS配置时使用TLS，直到ctx被取消
+// 配置了访问令牌时，请求元数据authorization中需要带有"Bearer 令牌"，RunProbe和refresh为true的GetSnapshot需要collect令牌
+func (s *Server) ServeGRPC(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	var opts []grpc.ServerOption
+	if s.tls != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.tls)))
+	}
+	grpcServer := grpc.NewServer(opts...)
+	sysspectorv1.RegisterSysSpectorServer(grpcServer, &grpcService{server: s})
+	go func() {
+		<-ctx.Done()
+		grpcServer.GracefulStop()
+	}()
+	return grpcServer.Serve(listener)
+}
+
+// GetSnapshot 返回系统信息快照，完整的系统信息以JSON格式放在system_json中
+func (g *grpcService) GetSnapshot(ctx context.Context, req *sysspectorv1.GetSnapshotRequest) (*sysspectorv1.Snapshot, error) {
+	scope := config.ScopeRead
+	if req.GetRefresh() {
+		scope = config.ScopeCollect
+	}
+	if err := g.server.authorizeGRPC(ctx, scope, "GetSnapshot"); err != nil {
+		return nil, err
+	}
+	var info model.SystemInfo
+	var collectedAt time.Time
+	var err error
+	if req.GetRefresh() {
+		info, collectedAt, err = g.server.Snapshot(true)
+	} else {
+		info, collectedAt, err = g.server.Cached(ctx)
+	}
+	if errors.Is(err, errNotCollected) {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	snapshot, err := sysspectorv1.NewSnapshot(info, collectedAt)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return snapshot, nil
+}
+
+// StreamDynamicMetrics 每个间隔采样一次CPU、内存和网络速率并推送给客户端
+func (g *grpcService) StreamDynamicMetrics(req *sysspectorv1.StreamDynamicMetricsRequest, stream sysspectorv1.SysSpector_StreamDynamicMetricsServer) error {
+	if err := g.server.authorizeGRPC(stream.Context(), config.ScopeRead, "StreamDynamicMetrics"); err != nil {
+		return err
+	}
+	interval := DefaultMetricsInterval
+	if req.GetIntervalSeconds() > 0 {
+		interval = time.Duration(req.GetIntervalSeconds()) * time.Second
+	}
+	for stream.Context().Err() == nil {
+		metrics, err := SampleMetrics(interval, g.server.wifi)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if err := stream.Send(dynamicMetrics(metrics)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dynamicMetrics 将动态指标采样转换为gRPC消息
+func dynamicMetrics(metrics Metrics) *sysspectorv1.DynamicMetrics {
+	message := &sysspectorv1.DynamicMetrics{
+		Time:                timestamppb.New(metrics.Time),
+		CpuPercent:          metrics.CPUPercent,
+		MemoryTotalBytes:    metrics.MemoryTotal,
+		MemoryUsedBytes:     metrics.MemoryUsed,
+		MemoryPercent:       metrics.MemoryPercent,
+		NetRxBytesPerSecond: metrics.RxRate,
+		NetTxBytesPerSecond: metrics.TxRate,
+		WifiSsid:            metrics.SSID,
+		WifiRssiDbm:         int32(metrics.RSSI),
+	}
+	for _, iface := range metrics.Interfaces {
+		message.Interfaces = append(message.Interfaces, &sysspectorv1.InterfaceRate{
+			Name:             iface.Name,
+			RxBytesPerSecond: iface.RxRate,
+			TxBytesPerSecond: iface.TxRate,
+		})
+	}
+	return message
+}
+
+// RunProbe 对请求的目标执行一次延迟探测
+func (g *grpcService) RunProbe(ctx context.Context, req *sysspectorv1.RunProbeRequest) (*sysspectorv1.ProbeResult, error) {
+	if err := g.server.authorizeGRPC(ctx, config.ScopeCollect, "RunProbe"); err != nil {
+		return nil, err
+	}
+	if req.GetCount() > maxProbeCount {
+		return nil, status.Errorf(codes.InvalidArgument, "count must not exceed %d", maxProbeCount)
+	}
+	target := config.ProbeTarget{Host: req.GetHost(), Protocol: req.GetProtocol(), Port: int(req.GetPort())}
+	if err := target.Normalize(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	result, err := analysis.ProbeTarget(target, int(req.GetCount()))
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return &sysspectorv1.ProbeResult{
+		Host:              result.TargetHost,
+		Protocol:          result.Protocol,
+		Port:              uint32(result.Port),
+		Method:            result.Method,
+		MinMs:             result.MinLatency,
+		AvgMs:             result.AvgLatency,
+		MaxMs:             result.MaxLatency,
+		P50Ms:             result.P50,
+		P95Ms:             result.P95,
+		P99Ms:             result.P99,
+		JitterMs:          result.Jitter,
+		PacketLossPercent: result.PacketLoss,
+		RttsMs:            result.RTTs,
+	}, nil
+}
@@ -0,0 +1,236 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+
+	"github.com/AsterZephyr/SysSpector/internal/collect"
+)
+
+// 采集器和后台任务的健康状态
+const (
+	HealthOK       = "ok"       // 最近一次采集成功，所有采集项和后台任务最近一次运行成功
+	HealthDegraded = "degraded" // 有采集项出错或超时，或有后台任务（推送、发布、通知等）最近一次运行失败
+	HealthFailing  = "failing"  // 最近一次采集系统信息失败，或半数以上的采集项最近一次出错或超时
+)
+
+// collectorPrefix 采集项的运行记录名称的前缀，之后为采集项名称，例如collect.battery
+const collectorPrefix = "collect."
+
+// taskStatus 表示一个采集项或后台任务的运行记录
+type taskStatus struct {
+	runs        uint64
+	failures    uint64
+	timeouts    uint64
+	lastRun     time.Time
+	lastSuccess time.Time
+	lastError   string
+}
+
+// TaskStatus 表示 /internal/metrics 中一个采集器或后台任务的运行记录
+type TaskStatus struct {
+	Name        string
+	Runs        uint64
+	Failures    uint64
+	Timeouts    uint64 `json:",omitempty"` // 超时的次数，包括在Failures中，只有采集项会超时
+	LastRun     time.Time
+	LastSuccess time.Time // 最近一次成功的时间，从未成功时为零值
+	LastError   string    `json:",omitempty"` // 最近一次运行失败时的错误，成功后清空
+}
+
+// HealthResponse 表示 /healthz 的返回结果，状态为failing时HTTP状态码为503
+type HealthResponse struct {
+	Status        string
+	StartedAt     time.Time
+	UptimeSeconds float64
+	FailingTasks  []string `json:",omitempty"` // 最近一次运行失败的采集项和后台任务
+}
+
+// AgentMetrics 表示 /internal/metrics 的返回结果，用于监控SysSpector本身
+type AgentMetrics struct {
+	Status        string
+	StartedAt     time.Time
+	UptimeSeconds float64
+	Tasks         []TaskStatus // 系统信息采集（snapshot）、各采集项（collect.名称）和各后台任务的运行记录，按名称排序
+	QueueDepth    int          // 尚未发送的快照数（推送失败或离线模式下暂存的快照）
+	Memory        AgentMemory
+}
+
+// AgentMemory 表示SysSpector进程自身的内存使用
+type AgentMemory struct {
+	RSS        uint64 // 常驻内存（字节），无法读取时为0
+	HeapAlloc  uint64 // Go堆中已分配的对象（字节）
+	HeapSys    uint64 // Go堆向操作系统申请的内存（字节）
+	Sys        uint64 // Go运行时向操作系统申请的全部内存（字节）
+	NumGC      uint32
+	Goroutines int
+}
+
+// QueueDepth 返回尚未发送的报告数
+type QueueDepth func() (int, error)
+
+// SetQueueDepth 设置读取未发送报告数的方式，未设置时为0
+func (s *Server) SetQueueDepth(depth QueueDepth) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.queueDepth = depth
+}
+
+// ReportTask 记录一次后台任务（推送、MQTT发布、通知等）的运行结果，用于 /healthz 和 /internal/metrics
+func (s *Server) ReportTask(name string, err error) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	if s.tasks == nil {
+		s.tasks = make(map[string]*taskStatus)
+	}
+	task := s.tasks[name]
+	if task == nil {
+		task = &taskStatus{}
+		s.tasks[name] = task
+	}
+	task.runs++
+	task.lastRun = time.Now()
+	if err != nil {
+		task.failures++
+		task.lastError = err.Error()
+		if errors.Is(err, collect.ErrTimeout) {
+			task.timeouts++
+		}
+	} else {
+		task.lastSuccess = task.lastRun
+		task.lastError = ""
+	}
+}
+
+// ReportCollector 记录一次采集项的运行结果，用于collect.SetReporter，运行记录的名称为collect.加采集项名称
+func (s *Server) ReportCollector(name string, err error) {
+	s.ReportTask(collectorPrefix+name, err)
+}
+
+// taskStatuses 返回系统信息采集、各采集项和各后台任务的运行记录，按名称排序
+func (s *Server) taskStatuses() []TaskStatus {
+	stats := s.currentStats()
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	statuses := make([]TaskStatus, 0, len(s.tasks)+1)
+	if stats.collections > 0 {
+		snapshot := TaskStatus{Name: "snapshot", Runs: stats.collections, Failures: stats.failures,
+			LastRun: stats.lastRun, LastSuccess: stats.lastSuccess}
+		if stats.lastErr != nil {
+			snapshot.LastError = stats.lastErr.Error()
+		}
+		statuses = append(statuses, snapshot)
+	}
+	for name, task := range s.tasks {
+		statuses = append(statuses, TaskStatus{Name: name, Runs: task.runs, Failures: task.failures, Timeouts: task.timeouts,
+			LastRun: task.lastRun, LastSuccess: task.lastSuccess, LastError: task.lastError})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// health 根据运行记录计算健康状态，返回最近一次运行失败的任务。各平台的采集在采集项出错时仍返回已采集的信息，
+// 因此除了系统信息采集本身失败外，半数以上的采集项出错或超时也认为采集失败
+func health(tasks []TaskStatus) (string, []string) {
+	status := HealthOK
+	var failing []string
+	collectors, failingCollectors := 0, 0
+	for _, task := range tasks {
+		collector := strings.HasPrefix(task.Name, collectorPrefix)
+		if collector {
+			collectors++
+		}
+		if task.LastError == "" {
+			continue
+		}
+		failing = append(failing, task.Name)
+		if collector {
+			failingCollectors++
+		}
+		if task.Name == "snapshot" {
+			status = HealthFailing
+		} else if status == HealthOK {
+			status = HealthDegraded
+		}
+	}
+	if collectors > 0 && failingCollectors*2 > collectors {
+		status = HealthFailing
+	}
+	return status, failing
+}
+
+// handleHealth 返回SysSpector的健康状态，最近一次采集失败时返回503
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+	status, failing := health(s.taskStatuses())
+	code := http.StatusOK
+	if status == HealthFailing {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, HealthResponse{
+		Status:        status,
+		StartedAt:     s.startedAt,
+		UptimeSeconds: time.Since(s.startedAt).Seconds(),
+		FailingTasks:  failing,
+	})
+}
+
+// handleAgentMetrics 返回SysSpector自身的运行时间、各采集器和后台任务的运行记录、未发送的报告数和内存使用
+func (s *Server) handleAgentMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+	tasks := s.taskStatuses()
+	status, _ := health(tasks)
+	metrics := AgentMetrics{
+		Status:        status,
+		StartedAt:     s.startedAt,
+		UptimeSeconds: time.Since(s.startedAt).Seconds(),
+		Tasks:         tasks,
+		Memory:        agentMemory(),
+	}
+	s.hooksMu.RLock()
+	queueDepth := s.queueDepth
+	s.hooksMu.RUnlock()
+	if queueDepth != nil {
+		depth, err := queueDepth()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+			return
+		}
+		metrics.QueueDepth = depth
+	}
+	writeJSON(w, http.StatusOK, metrics)
+}
+
+// agentMemory 读取当前进程的常驻内存和Go运行时的内存统计
+func agentMemory() AgentMemory {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	memory := AgentMemory{
+		HeapAlloc:  stats.HeapAlloc,
+		HeapSys:    stats.HeapSys,
+		Sys:        stats.Sys,
+		NumGC:      stats.NumGC,
+		Goroutines: runtime.NumGoroutine(),
+	}
+	if proc, err := process.NewProcess(int32(os.Getpid())); err == nil {
+		if info, err := proc.MemoryInfo(); err == nil {
+			memory.RSS = info.RSS
+		}
+	}
+	return memory
+}
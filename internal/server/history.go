@@ -0,0 +1,118 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/history"
+)
+
+// defaultHistoryRange 历史查询没有指定from参数时返回的时长
+const defaultHistoryRange = 24 * time.Hour
+
+// SetHistory 设置历史查询接口使用的数据库，未设置时历史查询接口返回404
+func (s *Server) SetHistory(db *history.DB) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.history = db
+}
+
+// handleHistoryMetrics 返回[from, to]内的动态指标，from和to为RFC 3339格式的时间，默认为最近24小时
+func (s *Server) handleHistoryMetrics(w http.ResponseWriter, r *http.Request) {
+	db, from, to, ok := s.historyRange(w, r)
+	if !ok {
+		return
+	}
+	points, err := db.Points(from, to)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, points)
+}
+
+// handleHistorySnapshots 列出[from, to]内保存的快照
+func (s *Server) handleHistorySnapshots(w http.ResponseWriter, r *http.Request) {
+	db, from, to, ok := s.historyRange(w, r)
+	if !ok {
+		return
+	}
+	records, err := db.Snapshots(from, to)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, records)
+}
+
+// handleHistorySnapshot 返回at参数（RFC 3339格式，默认为当前时间）之前最近的快照，响应头Last-Modified为采集时间
+func (s *Server) handleHistorySnapshot(w http.ResponseWriter, r *http.Request) {
+	db, ok := s.checkHistory(w, r)
+	if !ok {
+		return
+	}
+	at := time.Now()
+	if value := r.URL.Query().Get("at"); value != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339, value); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid at: %v", err)})
+			return
+		}
+	}
+	info, collectedAt, err := db.Snapshot(at)
+	if errors.Is(err, history.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	w.Header().Set("Last-Modified", collectedAt.UTC().Format(http.TimeFormat))
+	writeJSON(w, http.StatusOK, info)
+}
+
+// historyRange 检查请求并解析from和to参数，返回历史数据库，参数错误时写入错误响应并返回false
+func (s *Server) historyRange(w http.ResponseWriter, r *http.Request) (*history.DB, time.Time, time.Time, bool) {
+	db, ok := s.checkHistory(w, r)
+	if !ok {
+		return nil, time.Time{}, time.Time{}, false
+	}
+	to := time.Now()
+	from := to.Add(-defaultHistoryRange)
+	for _, param := range []struct {
+		name  string
+		value *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		text := r.URL.Query().Get(param.name)
+		if text == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid %s: %v", param.name, err)})
+			return nil, time.Time{}, time.Time{}, false
+		}
+		*param.value = t
+	}
+	return db, from, to, true
+}
+
+// checkHistory 检查请求方法和历史数据库是否已启用，返回历史数据库，不满足时写入错误响应并返回false
+func (s *Server) checkHistory(w http.ResponseWriter, r *http.Request) (*history.DB, bool) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return nil, false
+	}
+	s.hooksMu.RLock()
+	db := s.history
+	s.hooksMu.RUnlock()
+	if db == nil {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "history is not enabled"})
+		return nil, false
+	}
+	return db, true
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/mem"
+
+	"github.com/AsterZephyr/SysSpector/internal/ifaces"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// DefaultMetricsInterval 动态指标的默认采样间隔
+const DefaultMetricsInterval = 5 * time.Second
+
+// Metrics 表示一次动态指标采样
+type Metrics struct {
+	Time          time.Time
+	CPUPercent    float64 // 采样窗口内所有核心的平均CPU使用率
+	MemoryTotal   uint64
+	MemoryUsed    uint64
+	MemoryPercent float64
+	RxRate        float64 // 所有网络接口的接收速率合计（字节/秒）
+	TxRate        float64 // 所有网络接口的发送速率合计（字节/秒）
+	Interfaces    []model.InterfaceTrafficInfo
+	SSID          string // 当前WiFi网络，没有连接WiFi或无法读取时为空
+	RSSI          int    // WiFi信号强度（dBm），没有连接WiFi或无法读取时为0
+}
+
+// WiFiReader 读取当前WiFi连接的信息
+type WiFiReader func() (model.WiFiInfo, error)
+
+// CacheWiFi 返回缓存read结果ttl的WiFiReader，多个推送任务和WebSocket连接同时采样时也只读取一次。
+// macOS上读取WiFi信息需要运行数秒的system_profiler，不能在每次采样时执行
+func CacheWiFi(read WiFiReader, ttl time.Duration) WiFiReader {
+	var mu sync.Mutex
+	var info model.WiFiInfo
+	var err error
+	var readAt time.Time
+	return func() (model.WiFiInfo, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if readAt.IsZero() || time.Since(readAt) >= ttl {
+			info, err = read()
+			readAt = time.Now()
+		}
+		return info, err
+	}
+}
+
+// RunMetrics 持续以interval为采样窗口采样动态指标并交给fn处理，直到ctx取消；采样失败时记录日志
+func RunMetrics(ctx context.Context, interval time.Duration, wifi WiFiReader, fn func(Metrics)) {
+	for ctx.Err() == nil {
+		metrics, err := SampleMetrics(interval, wifi)
+		if err != nil {
+			log.Printf("Error sampling metrics: %v", err)
+			continue
+		}
+		if ctx.Err() == nil {
+			fn(metrics)
+		}
+	}
+}
+
+// SampleMetrics 在window内同时采样CPU使用率和各网络接口的速率，并读取内存使用情况和WiFi信号强度，wifi为nil时不读取
+func SampleMetrics(window time.Duration, wifi WiFiReader) (Metrics, error) {
+	var metrics Metrics
+	type rateResult struct {
+		rates []model.InterfaceTrafficInfo
+		err   error
+	}
+	rateCh := make(chan rateResult, 1)
+	go func() {
+		rates, err := ifaces.Rates(window)
+		rateCh <- rateResult{rates, err}
+	}()
+	wifiCh := make(chan model.WiFiInfo, 1)
+	go func() {
+		var info model.WiFiInfo
+		if wifi != nil {
+			// WiFi信息读取失败不影响其他指标
+			info, _ = wifi()
+		}
+		wifiCh <- info
+	}()
+
+	percents, err := cpu.Percent(window, false)
+	rates := <-rateCh
+	if err != nil {
+		return metrics, err
+	}
+	if rates.err != nil {
+		return metrics, rates.err
+	}
+	if len(percents) > 0 {
+		metrics.CPUPercent = percents[0]
+	}
+	metrics.Interfaces = rates.rates
+	metrics.RxRate, metrics.TxRate = ifaces.Total(rates.rates)
+
+	memory, err := mem.VirtualMemory()
+	if err != nil {
+		return metrics, err
+	}
+	metrics.MemoryTotal, metrics.MemoryUsed, metrics.MemoryPercent = memory.Total, memory.Used, memory.UsedPercent
+	if info := <-wifiCh; info.RSSI != 0 {
+		metrics.SSID, metrics.RSSI = info.SSID, info.RSSI
+	}
+	metrics.Time = time.Now()
+	return metrics, nil
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// metricPrefix 所有指标名称的前缀
+const metricPrefix = "sysspector_"
+
+// metricWriter 以Prometheus文本格式输出指标，同名指标的HELP和TYPE只输出一次，调用方需要连续输出同名指标的所有样本
+type metricWriter struct {
+	buf  bytes.Buffer
+	seen map[string]bool
+}
+
+// gauge 输出一个gauge样本，labels为依次排列的标签名和标签值
+func (w *metricWriter) gauge(name, help string, value float64, labels ...string) {
+	w.sample(name, "gauge", help, value, labels...)
+}
+
+// counter 输出一个counter样本
+func (w *metricWriter) counter(name, help string, value float64, labels ...string) {
+	w.sample(name, "counter", help, value, labels...)
+}
+
+// sample 输出一个样本，必要时先输出HELP和TYPE
+func (w *metricWriter) sample(name, metricType, help string, value float64, labels ...string) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return
+	}
+	name = metricPrefix + name
+	if w.seen == nil {
+		w.seen = make(map[string]bool)
+	}
+	if !w.seen[name] {
+		w.seen[name] = true
+		fmt.Fprintf(&w.buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+	}
+	w.buf.WriteString(name)
+	if len(labels) > 0 {
+		w.buf.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				w.buf.WriteByte(',')
+			}
+			fmt.Fprintf(&w.buf, "%s=\"%s\"", labels[i], escapeLabel(labels[i+1]))
+		}
+		w.buf.WriteByte('}')
+	}
+	w.buf.WriteByte(' ')
+	w.buf.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	w.buf.WriteByte('\n')
+}
+
+// labelEscaper 转义标签值中的反斜杠、双引号和换行
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel 转义标签值
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+// boolValue 将布尔值转换为0或1
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// handleMetrics 以Prometheus文本格式返回采集耗时、失败次数和缓存的系统信息中的数值，不会采集；
+// 还没有成功采集过时只返回采集相关的指标
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	info, _, err := s.Cached(r.Context())
+
+	var m metricWriter
+	stats := s.currentStats()
+	m.gauge("up", "Whether the last collection succeeded.", boolValue(err == nil && stats.lastErr == nil))
+	m.counter("collections_total", "Number of system information collections.", float64(stats.collections))
+	m.counter("collection_failures_total", "Number of failed system information collections.", float64(stats.failures))
+	m.gauge("collection_duration_seconds", "Duration of the last system information collection.", stats.duration.Seconds())
+	if !stats.lastSuccess.IsZero() {
+		m.gauge("collection_last_success_timestamp_seconds", "Unix time of the last successful collection.", float64(stats.lastSuccess.Unix()))
+	}
+	if err == nil {
+		writeSystemMetrics(&m, info)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(m.buf.Bytes())
+}
+
+// writeSystemMetrics 输出系统信息中的数值：电池、内存、磁盘、温度、WiFi、延迟、DNS、网卡流量、TCP和网络健康评分
+func writeSystemMetrics(m *metricWriter, info model.SystemInfo) {
+	m.gauge("info", "Host information, always 1.", 1, "hostname", info.Hostname, "os", info.OS, "model", info.Model, "version", info.SystemVersion)
+
+	if battery := info.Battery; battery.IsPresent {
+		m.gauge("battery_percent", "Battery charge level in percent.", float64(battery.Percentage))
+		m.gauge("battery_charging", "Whether the battery is charging.", boolValue(battery.IsCharging))
+		m.gauge("battery_cycle_count", "Battery charge cycle count.", float64(battery.CycleCount))
+		m.gauge("battery_health_percent", "Full charge capacity as a percentage of design capacity.", battery.HealthPercent)
+		m.gauge("battery_time_remaining_minutes", "Estimated battery time remaining.", float64(battery.TimeRemaining))
+	}
+
+	m.gauge("memory_total_bytes", "Total physical memory.", float64(info.MemoryUsage.Total))
+	m.gauge("memory_used_bytes", "Used physical memory.", float64(info.MemoryUsage.Used))
+	m.gauge("memory_used_percent", "Used physical memory in percent.", info.MemoryUsage.UsedPerc)
+
+	for _, disk := range info.DiskUsage {
+		m.gauge("disk_total_bytes", "Total capacity of the partition.", float64(disk.Total), "mountpoint", disk.MountPoint)
+	}
+	for _, disk := range info.DiskUsage {
+		m.gauge("disk_used_bytes", "Used capacity of the partition.", float64(disk.Used), "mountpoint", disk.MountPoint)
+	}
+	for _, disk := range info.DiskUsage {
+		m.gauge("disk_used_percent", "Used capacity of the partition in percent.", disk.UsedPerc, "mountpoint", disk.MountPoint)
+	}
+	if trend := info.DiskTrend; trend.DaysUntilFull > 0 {
+		m.gauge("disk_days_until_full", "Projected days until the system disk is full.", trend.DaysUntilFull, "mountpoint", trend.MountPoint)
+	}
+
+	for _, sensor := range info.Temperature {
+		m.gauge("temperature_celsius", "Temperature sensor reading.", sensor.Temperature, "sensor", sensor.Name)
+	}
+
+	network := info.Network
+	if wifi := network.WiFi; wifi.IsConnected && wifi.RSSI != 0 {
+		m.gauge("wifi_rssi_dbm", "WiFi received signal strength.", float64(wifi.RSSI), "ssid", wifi.SSID)
+		m.gauge("wifi_noise_dbm", "WiFi noise level.", float64(wifi.Noise), "ssid", wifi.SSID)
+		m.gauge("wifi_tx_rate_mbps", "WiFi transmit rate.", float64(wifi.TxRate), "ssid", wifi.SSID)
+		m.gauge("wifi_channel", "WiFi channel.", float64(wifi.Channel), "ssid", wifi.SSID)
+	}
+
+	m.gauge("latency_avg_ms", "Average latency of reachable probe targets.", network.Latency.AvgLatency)
+	m.gauge("latency_jitter_ms", "Average jitter of reachable probe targets.", network.Latency.Jitter)
+	m.gauge("latency_packet_loss_percent", "Average packet loss of all probe targets.", network.Latency.PacketLoss)
+	for _, target := range network.Latency.Targets {
+		m.gauge("probe_latency_avg_ms", "Average round-trip time of a probe target.", target.AvgLatency, targetLabels(target)...)
+	}
+	for _, target := range network.Latency.Targets {
+		m.gauge("probe_latency_p95_ms", "95th percentile round-trip time of a probe target.", target.P95, targetLabels(target)...)
+	}
+	for _, target := range network.Latency.Targets {
+		m.gauge("probe_packet_loss_percent", "Packet loss of a probe target.", target.PacketLoss, targetLabels(target)...)
+	}
+	if gateway := network.Gateway; gateway.Address != "" {
+		m.gauge("gateway_latency_ms", "Average latency to the default gateway.", gateway.Latency, "gateway", gateway.Address)
+		m.gauge("gateway_packet_loss_percent", "Packet loss to the default gateway.", gateway.Loss, "gateway", gateway.Address)
+	}
+	for _, dns := range network.DNSBenchmark {
+		m.gauge("dns_query_avg_ms", "Average DNS query time of a resolver.", dns.AvgTime, "server", dns.Server, "source", dns.Source)
+	}
+	for _, dns := range network.DNSBenchmark {
+		m.gauge("dns_query_failures", "Failed or timed out DNS queries of a resolver.", float64(dns.Failures+dns.Timeouts), "server", dns.Server, "source", dns.Source)
+	}
+
+	for _, traffic := range network.InterfaceTraffic {
+		m.gauge("interface_receive_bytes_per_second", "Receive rate of a network interface.", traffic.RxRate, "interface", traffic.Name)
+	}
+	for _, traffic := range network.InterfaceTraffic {
+		m.gauge("interface_transmit_bytes_per_second", "Transmit rate of a network interface.", traffic.TxRate, "interface", traffic.Name)
+	}
+
+	if tcp := network.TCPStats; tcp.Assessment != "" {
+		m.gauge("tcp_retransmit_percent", "TCP retransmission rate during the sample window.", tcp.RetransmitRate)
+		m.gauge("tcp_resets", "TCP connections reset during the sample window.", float64(tcp.Resets))
+	}
+	for _, check := range network.EndpointChecks {
+		m.gauge("endpoint_total_ms", "Total time of an HTTP endpoint check.", check.TotalTime, "name", check.Name, "url", check.URL)
+	}
+	for _, check := range network.EndpointChecks {
+		m.gauge("endpoint_up", "Whether an HTTP endpoint check succeeded.", boolValue(check.Error == ""), "name", check.Name, "url", check.URL)
+	}
+	for _, check := range network.PortMatrix.Checks {
+		m.gauge("outbound_port_open", "Whether an outbound TCP port can be connected.", boolValue(check.Open), "name", check.Name, "port", strconv.Itoa(check.Port))
+	}
+	if quality := network.Quality; quality.RPM > 0 {
+		m.gauge("responsiveness_rpm", "Round-trips per minute under load.", float64(quality.RPM))
+	}
+	m.gauge("vpn_connected", "Whether a VPN is connected.", boolValue(network.VPN.IsConnected))
+	m.gauge("proxy_enabled", "Whether a system proxy is enabled.", boolValue(network.ProxyStatus))
+	m.gauge("network_health_score", "Network health score from 0 to 100.", float64(network.HealthScore.Score))
+}
+
+// targetLabels 返回延迟探测目标的标签
+func targetLabels(target model.TargetLatencyInfo) []string {
+	return []string{"group", target.Group, "target", target.TargetName, "host", target.TargetHost, "protocol", target.Protocol}
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"net/http"
+)
+
+// Reloader 重新加载配置文件，按新配置重启后台任务
+type Reloader func() error
+
+// ReloadResponse 表示 /v1/reload 的返回结果
+type ReloadResponse struct {
+	Status string
+}
+
+// SetReloader 设置 /v1/reload 重新加载配置的方式，未设置时 /v1/reload 返回404
+func (s *Server) SetReloader(reload Reloader) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.reloader = reload
+}
+
+// handleReload 重新加载配置，新配置无效时返回500并继续使用原来的配置
+func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
+	s.hooksMu.RLock()
+	reload := s.reloader
+	s.hooksMu.RUnlock()
+	if reload == nil {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "reload is not enabled"})
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+	if err := reload(); err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, ReloadResponse{Status: "reloaded"})
+}
@@ -0,0 +1,315 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/history"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// shutdownTimeout 停止服务时等待正在处理的请求完成的时长
+const shutdownTimeout = 5 * time.Second
+
+// errNotCollected 表示还没有成功采集过系统信息
+var errNotCollected = errors.New("system information has not been collected yet")
+
+// Collector 采集一次系统信息
+type Collector func() (model.SystemInfo, error)
+
+// Server 表示REST API服务，系统信息在后台每隔缓存时长采集一次，查询请求返回最近一次采集的结果，
+// 只有refresh请求（需要collect令牌）才会在请求中采集，多个请求同时到达时只采集一次
+type Server struct {
+	collect  Collector
+	cacheTTL time.Duration
+	wifi     WiFiReader
+	tls      *tls.Config
+
+	startedAt time.Time
+	attempted chan struct{} // 第一次采集结束（无论成功与否）后关闭
+	once      sync.Once
+	executed  *CommandLog // 执行过的命令，重新加载配置时保留
+
+	// 以下字段在serve模式重新加载配置时会被替换
+	hooksMu    sync.RWMutex
+	history    *history.DB
+	commands   *Commands
+	queueDepth QueueDepth
+	reloader   Reloader
+	tokens     []config.APIToken
+	origins    []string
+
+	collectMu   sync.Mutex // 同一时间只进行一次采集
+	mu          sync.Mutex // 只保护info和collectedAt，采集期间不持有
+	info        model.SystemInfo
+	collectedAt time.Time
+
+	statsMu sync.Mutex
+	stats   collectStats
+	tasks   map[string]*taskStatus // 后台任务的运行记录，按任务名称
+}
+
+// collectStats 表示采集次数、失败次数和耗时，用于 /metrics
+type collectStats struct {
+	collections uint64
+	failures    uint64
+	duration    time.Duration // 最近一次采集的耗时
+	lastRun     time.Time
+	lastSuccess time.Time
+	lastErr     error // 最近一次采集失败时的错误，成功后清空
+}
+
+// ProcessesResponse 表示 /v1/processes 的返回结果
+type ProcessesResponse struct {
+	ResourceHogs model.ResourceHogsInfo
+	Processes    []model.ProcessInfo
+}
+
+// errorResponse 表示请求失败时返回的JSON
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// New 创建REST API服务，cacheTTL为RefreshCache在后台重新采集的间隔
+func New(collect Collector, cacheTTL time.Duration) *Server {
+	return &Server{collect: collect, cacheTTL: cacheTTL, startedAt: time.Now(), attempted: make(chan struct{}), executed: NewCommandLog()}
+}
+
+// SetWiFiReader 设置动态指标中读取WiFi信号强度的方式，未设置时不推送信号强度
+func (s *Server) SetWiFiReader(wifi WiFiReader) {
+	s.wifi = wifi
+}
+
+// SetTLSConfig 设置REST和gRPC服务使用的TLS配置，为nil时不使用TLS
+func (s *Server) SetTLSConfig(tlsConfig *tls.Config) {
+	s.tls = tlsConfig
+}
+
+// Snapshot 返回系统信息及其采集时间，缓存过期或refresh为true时重新采集。
+// 同一时间只进行一次采集，等待期间其他请求完成的采集结果直接返回；采集期间Cached仍然返回上一次的结果
+func (s *Server) Snapshot(refresh bool) (model.SystemInfo, time.Time, error) {
+	requested := time.Now()
+	info, collectedAt := s.latest()
+	if !refresh && s.fresh(collectedAt) {
+		return info, collectedAt, nil
+	}
+
+	s.collectMu.Lock()
+	defer s.collectMu.Unlock()
+	info, collectedAt = s.latest()
+	if collectedAt.After(requested) || !refresh && s.fresh(collectedAt) {
+		return info, collectedAt, nil
+	}
+
+	start := time.Now()
+	info, err := s.collect()
+	if err != nil {
+		s.recordCollection(time.Since(start), err)
+		return info, time.Time{}, err
+	}
+	collectedAt = time.Now()
+	s.mu.Lock()
+	s.info, s.collectedAt = info, collectedAt
+	s.mu.Unlock()
+	s.recordCollection(time.Since(start), nil)
+	return info, collectedAt, nil
+}
+
+// latest 返回最近一次成功采集的系统信息及其采集时间，还没有成功采集时采集时间为零值
+func (s *Server) latest() (model.SystemInfo, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.info, s.collectedAt
+}
+
+// fresh 判断在collectedAt采集的结果是否还在缓存时长内
+func (s *Server) fresh(collectedAt time.Time) bool {
+	return !collectedAt.IsZero() && time.Since(collectedAt) < s.cacheTTL
+}
+
+// Cached 返回最近一次成功采集的系统信息及其采集时间，不会采集；第一次采集还没有结束时等待其结束或ctx取消
+func (s *Server) Cached(ctx context.Context) (model.SystemInfo, time.Time, error) {
+	select {
+	case <-s.attempted:
+	case <-ctx.Done():
+		return model.SystemInfo{}, time.Time{}, ctx.Err()
+	}
+	info, collectedAt := s.latest()
+	if collectedAt.IsZero() {
+		return model.SystemInfo{}, time.Time{}, errNotCollected
+	}
+	return info, collectedAt, nil
+}
+
+// RefreshCache 每隔缓存时长在后台重新采集一次系统信息，供查询请求、/metrics和RunPeriodic使用，直到ctx取消
+func (s *Server) RefreshCache(ctx context.Context) {
+	ticker := time.NewTicker(s.cacheTTL)
+	defer ticker.Stop()
+	for {
+		if _, _, err := s.Snapshot(true); err != nil {
+			log.Printf("Error collecting system info: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RunPeriodic 每隔interval将RefreshCache在后台采集的最近一次结果交给fn处理，直到ctx取消，本身不触发采集；
+// 还没有成功采集过时记录日志并等待下一次
+func (s *Server) RunPeriodic(ctx context.Context, interval time.Duration, fn func(model.SystemInfo, time.Time)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		info, collectedAt, err := s.Cached(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Error getting system info: %v", err)
+		} else {
+			fn(info, collectedAt)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// recordCollection 记录一次采集的耗时和结果
+func (s *Server) recordCollection(duration time.Duration, err error) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	s.stats.collections++
+	s.stats.duration = duration
+	s.stats.lastRun = time.Now()
+	s.stats.lastErr = err
+	if err != nil {
+		s.stats.failures++
+	} else {
+		s.stats.lastSuccess = s.stats.lastRun
+	}
+	s.once.Do(func() { close(s.attempted) })
+}
+
+// currentStats 返回采集统计
+func (s *Server) currentStats() collectStats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	return s.stats
+}
+
+// Handler 返回API的路由：
+//
+//	GET /v1/system               全部系统信息
+//	GET /v1/network              网络信息
+//	GET /v1/processes            资源占用最高的进程和所有运行中的进程
+//	GET /v1/stream               WebSocket，按interval参数（秒）持续推送动态指标
+//	GET /v1/history/metrics      历史数据库中的动态指标
+//	GET /v1/history/snapshots    历史数据库中保存的快照列表
+//	GET /v1/history/snapshot     历史数据库中指定时间之前最近的快照
+//	POST /v1/commands            执行签名的按需采集命令，返回执行结果
+//	GET /metrics                 Prometheus文本格式的指标
+//	GET /healthz                 SysSpector自身的健康状态，最近一次采集失败时返回503
+//	GET /internal/metrics        SysSpector自身的运行时间、各采集器和后台任务的运行记录、未发送的报告数和内存使用
+//	POST /v1/reload              重新加载配置
+//
+// 请求参数refresh=1时忽略缓存重新采集，响应头Last-Modified为采集时间
+// 配置了访问令牌时，除 /healthz 外的请求都需要带有Authorization: Bearer 令牌：read令牌可以查询，
+// 重新采集和 /v1/commands 需要collect令牌，/v1/reload 需要admin令牌
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/system", s.handle(func(info model.SystemInfo) interface{} {
+		return info
+	}))
+	mux.HandleFunc("/v1/network", s.handle(func(info model.SystemInfo) interface{} {
+		return info.Network
+	}))
+	mux.HandleFunc("/v1/processes", s.handle(func(info model.SystemInfo) interface{} {
+		return ProcessesResponse{ResourceHogs: info.ResourceHogs, Processes: info.RunningApps}
+	}))
+	mux.Handle("/v1/stream", s.streamHandler())
+	mux.HandleFunc("/v1/history/metrics", s.handleHistoryMetrics)
+	mux.HandleFunc("/v1/history/snapshots", s.handleHistorySnapshots)
+	mux.HandleFunc("/v1/history/snapshot", s.handleHistorySnapshot)
+	mux.HandleFunc("/v1/commands", s.handleCommand)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/healthz", s.handleHealth)
+	mux.HandleFunc("/internal/metrics", s.handleAgentMetrics)
+	mux.HandleFunc("/v1/reload", s.handleReload)
+	return s.authorize(mux)
+}
+
+// handle 返回一个接口的处理函数，从系统信息中选取需要返回的部分
+func (s *Server) handle(selectFn func(model.SystemInfo) interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+			return
+		}
+		// 只有refresh请求在请求中采集，其他请求（包括read令牌的请求）返回后台采集的结果
+		var info model.SystemInfo
+		var collectedAt time.Time
+		var err error
+		if refresh, _ := strconv.ParseBool(r.URL.Query().Get("refresh")); refresh {
+			info, collectedAt, err = s.Snapshot(true)
+		} else {
+			info, collectedAt, err = s.Cached(r.Context())
+		}
+		if errors.Is(err, errNotCollected) {
+			writeJSON(w, http.StatusServiceUnavailable, errorResponse{Error: err.Error()})
+			return
+		}
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+			return
+		}
+		w.Header().Set("Last-Modified", collectedAt.UTC().Format(http.TimeFormat))
+		writeJSON(w, http.StatusOK, selectFn(info))
+	}
+}
+
+// writeJSON 以JSON格式写入响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+// ListenAndServe 在指定地址上提供API服务，设置了TLS配置时使用HTTPS，直到ctx被取消。
+// 没有配置访问令牌时拒绝Host不是本机或监听地址的请求，见checkHost
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	httpServer := &http.Server{Addr: addr, Handler: s.checkHost(addr, s.Handler()), TLSConfig: s.tls, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+	var err error
+	if s.tls != nil {
+		// 证书已在TLSConfig中
+		err = httpServer.ListenAndServeTLS("", "")
+	} else {
+		err = httpServer.ListenAndServe()
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
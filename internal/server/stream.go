@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// 推送间隔的取值范围
+const (
+	minStreamInterval = time.Second
+	maxStreamInterval = 5 * time.Minute
+)
+
+// streamHandler 返回 /v1/stream 的WebSocket处理函数，连接建立后每隔interval秒推送一次动态指标（JSON），
+// 直到客户端断开。握手时检查Origin，拒绝其他网站的页面在用户浏览器中发起的连接
+func (s *Server) streamHandler() websocket.Server {
+	return websocket.Server{
+		Handshake: func(_ *websocket.Config, r *http.Request) error { return s.checkOrigin(r) },
+		Handler:   s.stream,
+	}
+}
+
+// SetAllowedOrigins 设置可以连接 /v1/stream 的其他网站的页面来源（scheme://host[:port]），重新加载配置时替换
+func (s *Server) SetAllowedOrigins(origins []string) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.origins = origins
+}
+
+// checkOrigin 允许不发送Origin的客户端（非浏览器）、与API同源的页面和配置中允许的来源。
+// WebSocket不受同源策略限制，不检查时任何网站都可以通过访问者的浏览器读取本机的指标
+func (s *Server) checkOrigin(r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return fmt.Errorf("invalid origin %q", origin)
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return nil
+	}
+	s.hooksMu.RLock()
+	origins := s.origins
+	s.hooksMu.RUnlock()
+	for _, allowed := range origins {
+		if strings.EqualFold(origin, allowed) {
+			return nil
+		}
+	}
+	return fmt.Errorf("origin %s is not allowed", origin)
+}
+
+// stream 持续推送动态指标，读取到客户端关闭连接时停止
+func (s *Server) stream(ws *websocket.Conn) {
+	defer ws.Close()
+	interval := DefaultMetricsInterval
+	if seconds, err := strconv.Atoi(ws.Request().URL.Query().Get("interval")); err == nil {
+		interval = time.Duration(seconds) * time.Second
+		if interval < minStreamInterval {
+			interval = minStreamInterval
+		} else if interval > maxStreamInterval {
+			interval = maxStreamInterval
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ws.Request().Context())
+	defer cancel()
+	go func() {
+		// 客户端不会发送数据，读取返回说明连接已关闭
+		io.Copy(io.Discard, ws)
+		cancel()
+	}()
+
+	for ctx.Err() == nil {
+		metrics, err := SampleMetrics(interval, s.wifi)
+		if err != nil {
+			websocket.JSON.Send(ws, errorResponse{Error: err.Error()})
+			return
+		}
+		if ctx.Err() != nil || websocket.JSON.Send(ws, metrics) != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 连续推送失败后的退避参数
+const (
+	backoffBase      = 30 * time.Second // 第一次失败后等待的时间，之后每次加倍，最长为max_backoff
+	backoffStateFile = "backoff.state"  // 保存在暂存目录中，单次运行模式下每次运行也会遵守退避
+)
+
+// backoffState 记录连续推送失败的次数和下次允许发送的时间
+type backoffState struct {
+	Failures int       `json:"failures"`
+	RetryAt  time.Time `json:"retry_at"`
+}
+
+// busyError 表示收集服务器暂时无法处理（429或503），retryAfter为Retry-After响应头要求等待的时间
+type busyError struct {
+	status     int
+	retryAfter time.Duration
+}
+
+func (e busyError) Error() string {
+	if e.retryAfter > 0 {
+		return fmt.Sprintf("collector is busy: HTTP %d, retry after %s", e.status, e.retryAfter)
+	}
+	return fmt.Sprintf("collector is busy: HTTP %d", e.status)
+}
+
+// jitter 返回[d/2, d)内的随机时长，避免大量设备在同一时刻重试
+func jitter(d time.Duration) time.Duration {
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + time.Duration(rand.Int63n(int64(d-half)))
+}
+
+// parseRetryAfter 解析Retry-After响应头（秒数或HTTP日期），没有或无法解析时返回0
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// loadBackoff 读取保存的退避状态，没有或无法读取时从头开始
+func (p *Pusher) loadBackoff() {
+	data, err := os.ReadFile(filepath.Join(p.spoolDir, backoffStateFile))
+	if err == nil {
+		json.Unmarshal(data, &p.backoff)
+	}
+}
+
+// recordFailure 记录一次连续失败，按指数退避（带随机抖动）计算下次允许发送的时间，Retry-After更长时以其为准
+func (p *Pusher) recordFailure(err error) {
+	p.backoff.Failures++
+	delay := time.Duration(p.cfg.MaxBackoff) * time.Second
+	if shift := p.backoff.Failures - 1; shift < 16 && backoffBase<<shift < delay {
+		delay = backoffBase << shift
+	}
+	delay = jitter(delay)
+	var busy busyError
+	if errors.As(err, &busy) && busy.retryAfter > delay {
+		delay = busy.retryAfter
+	}
+	p.backoff.RetryAt = time.Now().Add(delay)
+	p.saveBackoff()
+}
+
+// recordSuccess 发送成功后清除退避状态
+func (p *Pusher) recordSuccess() {
+	if p.backoff.Failures == 0 {
+		return
+	}
+	p.backoff = backoffState{}
+	os.Remove(filepath.Join(p.spoolDir, backoffStateFile))
+}
+
+// saveBackoff 保存退避状态
+func (p *Pusher) saveBackoff() {
+	data, err := json.Marshal(p.backoff)
+	if err == nil {
+		os.WriteFile(filepath.Join(p.spoolDir, backoffStateFile), data, 0600)
+	}
+}
+
+// throttle 限制上传带宽，同一个推送器的所有请求共享同一个速率
+type throttle struct {
+	rate int64 // 字节/秒
+
+	mu   sync.Mutex
+	next time.Time // 已发送的数据按速率计算的完成时间
+}
+
+// wait 记录即将发送的n字节，等待到之前的数据按速率发送完毕
+func (t *throttle) wait(ctx context.Context, n int) error {
+	t.mu.Lock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	delay := t.next.Sub(now)
+	t.next = t.next.Add(t.duration(n))
+	t.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// duration 返回按速率发送n字节需要的时间
+func (t *throttle) duration(n int) time.Duration {
+	return time.Duration(int64(n) * int64(time.Second) / t.rate)
+}
+
+// throttledReader 按带宽上限读取请求体，每次最多读取约0.1秒的数据量
+type throttledReader struct {
+	ctx      context.Context
+	reader   io.Reader
+	throttle *throttle
+}
+
+func (r *throttledReader) Read(b []byte) (int, error) {
+	chunk := int(r.throttle.rate / 10)
+	if chunk < 1024 {
+		chunk = 1024
+	}
+	if len(b) > chunk {
+		b = b[:chunk]
+	}
+	n, err := r.reader.Read(b)
+	if n > 0 {
+		if waitErr := r.throttle.wait(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
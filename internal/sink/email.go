@@ -0,0 +1,242 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/tlsconfig"
+)
+
+// 发送邮件报告的参数
+const (
+	emailTimeout    = 60 * time.Second // 连接服务器并发送一封邮件的超时
+	emailRetryDelay = 10 * time.Minute // 发送失败后重试的间隔，发送间隔更短时按发送间隔重试
+)
+
+// Mailer 通过SMTP发送报告邮件，HTML报告以multipart/alternative同时附带Markdown文本，不显示HTML的邮件客户端显示文本
+type Mailer struct {
+	cfg  config.EmailConfig
+	tls  *tls.Config
+	from *mail.Address
+	to   []*mail.Address
+}
+
+// NewMailer 根据配置创建邮件发送器，检查发件人和收件人地址，加载SMTP服务器证书的校验方式和客户端证书
+func NewMailer(cfg config.EmailConfig) (*Mailer, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("email: host is not configured")
+	}
+	m := &Mailer{cfg: cfg}
+	var err error
+	if m.from, err = mail.ParseAddress(cfg.From); err != nil {
+		return nil, fmt.Errorf("email: invalid from address %q", cfg.From)
+	}
+	for _, to := range cfg.To {
+		address, err := mail.ParseAddress(to)
+		if err != nil {
+			return nil, fmt.Errorf("email: invalid to address %q", to)
+		}
+		m.to = append(m.to, address)
+	}
+	if cfg.Security != config.SMTPNone {
+		tlsConfig, err := tlsconfig.Client(cfg.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("load TLS config: %v", err)
+		}
+		tlsConfig.ServerName = cfg.Host
+		m.tls = tlsConfig
+	}
+	return m, nil
+}
+
+// Subject 返回邮件主题：配置的主题前缀、title和日期
+func (m *Mailer) Subject(title string, now time.Time) string {
+	return fmt.Sprintf("%s %s %s", m.cfg.Subject, title, now.Format("2006-01-02"))
+}
+
+// Send 发送一封邮件，text为Markdown文本，html不为空时同时发送HTML版本
+// 使用starttls时服务器必须支持STARTTLS；配置了用户名时使用PLAIN认证，未加密的连接只允许连接本机
+func (m *Mailer) Send(ctx context.Context, subject, text, html string) error {
+	message, err := m.message(subject, text, html, time.Now())
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, emailTimeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	dialer := &net.Dialer{}
+	var conn net.Conn
+	if m.cfg.Security == config.SMTPTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: m.tls}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	// SMTP客户端不支持ctx，超时或取消时关闭连接
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if m.cfg.Security == config.SMTPStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("SMTP server does not support STARTTLS")
+		}
+		if err := client.StartTLS(m.tls); err != nil {
+			return err
+		}
+	}
+	if m.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %v", err)
+		}
+	}
+	if err := client.Mail(m.from.Address); err != nil {
+		return err
+	}
+	for _, to := range m.to {
+		if err := client.Rcpt(to.Address); err != nil {
+			return fmt.Errorf("recipient %s: %v", to.Address, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// message 生成MIME邮件，正文使用quoted-printable编码
+func (m *Mailer) message(subject, text, html string, now time.Time) ([]byte, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	domain := m.from.Address[strings.LastIndex(m.from.Address, "@")+1:]
+	recipients := make([]string, len(m.to))
+	for i, to := range m.to {
+		recipients[i] = to.String()
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", m.from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Message-ID: <%s@%s>\r\n", hex.EncodeToString(id), domain)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	if html == "" {
+		b.WriteString("Content-Type: text/plain; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n")
+		return b.Bytes(), writeQuotedPrintable(&b, text)
+	}
+
+	parts := multipart.NewWriter(&b)
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", parts.Boundary())
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// writeQuotedPrintable 以quoted-printable编码写入正文
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// EmailStatePath 返回记录上次发送邮件报告时间的文件路径，kind区分本机报告和设备清单汇总
+func EmailStatePath(kind string) (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "SysSpector", "email-"+kind+".state"), nil
+}
+
+// RunSchedule 每隔interval调用一次fn，直到ctx取消。上次成功的时间保存在statePath中，
+// 重新启动或重新加载配置后按上次的时间继续计时，不会每次启动都发送；fn失败时过一段时间后重试
+func RunSchedule(ctx context.Context, statePath string, interval time.Duration, fn func() error) {
+	var next time.Time
+	if data, err := os.ReadFile(statePath); err == nil {
+		if last, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data))); err == nil {
+			next = last.Add(interval)
+		}
+	}
+	for {
+		if wait := time.Until(next); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err := fn(); err != nil {
+			retry := emailRetryDelay
+			if interval < retry {
+				retry = interval
+			}
+			next = time.Now().Add(retry)
+			continue
+		}
+		now := time.Now()
+		if err := os.MkdirAll(filepath.Dir(statePath), 0700); err == nil {
+			os.WriteFile(statePath, []byte(now.Format(time.RFC3339)), 0600)
+		}
+		next = now.Add(interval)
+	}
+}
@@ -0,0 +1,159 @@
+package sink
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/server"
+	"github.com/AsterZephyr/SysSpector/internal/tlsconfig"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// mqttTimeout 连接和等待发布确认的超时
+const mqttTimeout = 10 * time.Second
+
+// MQTT主题，均位于配置的主题前缀之下
+const (
+	TopicStatus    = "status"    // 保留消息，online或offline（遗嘱消息）
+	TopicInventory = "inventory" // 保留消息，硬件和系统版本等静态信息
+	TopicSnapshot  = "snapshot"  // 完整的系统信息快照
+	TopicMetrics   = "metrics"   // CPU、内存、WiFi信号强度和网络速率等动态指标
+)
+
+// 在线状态
+const (
+	statusOnline  = "online"
+	statusOffline = "offline"
+)
+
+// Inventory 表示发布为保留消息的静态信息，新订阅的客户端可以立即得到每台设备的最新清单
+type Inventory struct {
+	Hostname      string
+	DeviceID      string
+	ComputerName  string
+	OS            string
+	SystemVersion string
+	Model         string
+	ModelID       string
+	SerialNumber  string
+	UUID          string
+	CPU           model.CPUInfo
+	Memory        model.MemoryInfo
+	Disks         []model.Disk
+	Firmware      model.FirmwareInfo
+	CollectedAt   time.Time
+}
+
+// MQTTPublisher 将快照和动态指标发布到MQTT服务器的主题前缀下，
+// 连接断开时自动重连，并通过遗嘱消息将在线状态置为offline
+type MQTTPublisher struct {
+	cfg    config.MQTTConfig
+	prefix string
+	client mqtt.Client
+}
+
+// NewMQTTPublisher 连接MQTT服务器并发布在线状态，ssl://、tls://、wss://地址使用配置的TLS证书
+func NewMQTTPublisher(cfg config.MQTTConfig) (*MQTTPublisher, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	p := &MQTTPublisher{
+		cfg:    cfg,
+		prefix: strings.TrimSuffix(strings.ReplaceAll(cfg.TopicPrefix, "{host}", hostname), "/"),
+	}
+
+	tlsConfig, err := tlsconfig.Client(cfg.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("load TLS config: %v", err)
+	}
+	clientID := cfg.ClientID
+	if clientID == "" {
+		clientID = "sysspector-" + hostname
+	}
+	opts := mqtt.NewClientOptions().
+		AddBroker(cfg.Broker).
+		SetClientID(clientID).
+		SetUsername(cfg.Username).
+		SetPassword(cfg.Password).
+		SetTLSConfig(tlsConfig).
+		SetConnectTimeout(mqttTimeout).
+		SetAutoReconnect(true).
+		SetWill(p.topic(TopicStatus), statusOffline, cfg.QoS, true).
+		// 重连后重新发布在线状态，覆盖服务器发出的遗嘱消息
+		SetOnConnectHandler(func(client mqtt.Client) {
+			client.Publish(p.topic(TopicStatus), cfg.QoS, true, statusOnline)
+		})
+	p.client = mqtt.NewClient(opts)
+	if err := wait(p.client.Connect()); err != nil {
+		return nil, fmt.Errorf("connect to %s: %v", cfg.Broker, err)
+	}
+	return p, nil
+}
+
+// PublishSnapshot 发布静态清单（保留消息）和完整的快照
+func (p *MQTTPublisher) PublishSnapshot(info model.SystemInfo, collectedAt time.Time) error {
+	inventory := Inventory{
+		Hostname:      info.Hostname,
+		DeviceID:      info.DeviceID,
+		ComputerName:  info.ComputerName,
+		OS:            info.OS,
+		SystemVersion: info.SystemVersion,
+		Model:         info.Model,
+		ModelID:       info.ModelID,
+		SerialNumber:  info.SerialNumber,
+		UUID:          info.UUID,
+		CPU:           info.CPU,
+		Memory:        info.Memory,
+		Disks:         info.Disks,
+		Firmware:      info.Firmware,
+		CollectedAt:   collectedAt,
+	}
+	if err := p.publish(TopicInventory, inventory, true); err != nil {
+		return err
+	}
+	return p.publish(TopicSnapshot, info, false)
+}
+
+// PublishMetrics 发布一次动态指标
+func (p *MQTTPublisher) PublishMetrics(metrics server.Metrics) error {
+	return p.publish(TopicMetrics, metrics, false)
+}
+
+// Close 将在线状态置为offline并断开连接
+func (p *MQTTPublisher) Close() {
+	wait(p.client.Publish(p.topic(TopicStatus), p.cfg.QoS, true, statusOffline))
+	p.client.Disconnect(250)
+}
+
+// publish 以JSON格式发布消息，QoS大于0时等待服务器确认
+func (p *MQTTPublisher) publish(name string, v interface{}, retained bool) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := wait(p.client.Publish(p.topic(name), p.cfg.QoS, retained, payload)); err != nil {
+		return fmt.Errorf("publish %s: %v", p.topic(name), err)
+	}
+	return nil
+}
+
+// topic 返回主题前缀下的完整主题
+func (p *MQTTPublisher) topic(name string) string {
+	return p.prefix + "/" + name
+}
+
+// wait 等待MQTT操作完成
+func wait(token mqtt.Token) error {
+	if !token.WaitTimeout(mqttTimeout) {
+		return errors.New("timed out")
+	}
+	return token.Error()
+}
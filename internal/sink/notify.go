@@ -0,0 +1,305 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// notifyTimeout 每个通知请求的超时
+const notifyTimeout = 15 * time.Second
+
+// 告警来源
+const (
+	AlertSourceLatency = "latency" // 延迟探测目标超出配置的阈值
+	AlertSourceProcess = "process" // 进程的资源占用超过阈值
+	AlertSourceHealth  = "health"  // 网络健康评分过低
+)
+
+// Alert 表示一条告警
+type Alert struct {
+	Host     string
+	DeviceID string
+	Source   string
+	Severity string // config.SeverityWarning或config.SeverityCritical
+	Title    string
+	Message  string
+	Time     time.Time
+}
+
+// key 返回告警的标识，用于判断告警是否为新出现的
+func (a Alert) key() string {
+	return a.Source + "|" + a.Title
+}
+
+// WebhookPayload 表示发送到通用Webhook的JSON消息
+type WebhookPayload struct {
+	Host     string
+	DeviceID string
+	Time     time.Time
+	Alerts   []Alert
+}
+
+// DetectAlerts 根据快照中已有的检查结果生成告警：延迟探测目标超出阈值、进程资源占用超过阈值、网络健康评分低于配置的值
+func DetectAlerts(info model.SystemInfo, cfg config.NotifyConfig, collectedAt time.Time) []Alert {
+	var alerts []Alert
+	add := func(source, severity, title, message string) {
+		alerts = append(alerts, Alert{Host: info.Hostname, DeviceID: info.DeviceID, Source: source, Severity: severity, Title: title, Message: message, Time: collectedAt})
+	}
+
+	for _, target := range info.Network.Latency.Targets {
+		if len(target.Warnings) > 0 {
+			add(AlertSourceLatency, config.SeverityWarning, fmt.Sprintf("延迟探测 %s/%s", target.Group, target.TargetName),
+				strings.Join(target.Warnings, "；"))
+		}
+	}
+	for _, hog := range info.ResourceHogs.Offenders {
+		add(AlertSourceProcess, config.SeverityWarning, fmt.Sprintf("进程 %s（%d）的%s占用超过阈值", hog.Name, hog.PID, hog.Resource),
+			formatHogValue(hog))
+	}
+	// 标题不包含评分，评分变化时仍然是同一个告警，只在评分恢复后再次低于阈值时重新通知
+	if health := info.Network.HealthScore; health.Grade != "" && health.Score < cfg.MinHealthScore {
+		message := fmt.Sprintf("评分 %d（%s），%s", health.Score, health.Grade, health.Verdict)
+		if len(health.Deductions) > 0 {
+			message += "：" + strings.Join(health.Deductions, "；")
+		}
+		add(AlertSourceHealth, config.SeverityCritical, "网络健康评分过低", message)
+	}
+	return alerts
+}
+
+// formatHogValue 按资源类型格式化进程的资源占用
+func formatHogValue(hog model.ProcessHogInfo) string {
+	switch hog.Resource {
+	case analysis.ResourceMemory:
+		return fmt.Sprintf("%.2f GB", hog.Value/(1024*1024*1024))
+	case analysis.ResourceNetwork:
+		return fmt.Sprintf("%.1f KB/s", hog.Value/1024)
+	default:
+		return fmt.Sprintf("%.1f%%", hog.Value)
+	}
+}
+
+// alertTracker 记录仍然存在的告警，持续存在的告警只报告一次
+type alertTracker struct {
+	mu     sync.Mutex
+	active map[string]bool
+}
+
+// newAlerts 返回上次检查时不存在的告警，已经消失的告警下次出现时会重新报告
+func (t *alertTracker) newAlerts(alerts []Alert) []Alert {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var fresh []Alert
+	active := make(map[string]bool)
+	for _, alert := range alerts {
+		active[alert.key()] = true
+		if !t.active[alert.key()] {
+			fresh = append(fresh, alert)
+		}
+	}
+	t.active = active
+	return fresh
+}
+
+// Notifier 将告警发送到配置的通知渠道，持续存在的告警只发送一次
+type Notifier struct {
+	cfg     config.NotifyConfig
+	clients []*http.Client // 与cfg.Channels一一对应
+	alerts  alertTracker
+}
+
+// NewNotifier 创建通知器，加载每个渠道的TLS配置
+func NewNotifier(cfg config.NotifyConfig) (*Notifier, error) {
+	n := &Notifier{cfg: cfg}
+	for _, channel := range cfg.Channels {
+		client, err := newHTTPClient(channel.TLS, notifyTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("notify channel %q: %v", channel.Name, err)
+		}
+		n.clients = append(n.clients, client)
+	}
+	return n, nil
+}
+
+// NewAlerts 返回上次检查时不存在的告警，已经消失的告警下次出现时会重新发送
+func (n *Notifier) NewAlerts(alerts []Alert) []Alert {
+	return n.alerts.newAlerts(alerts)
+}
+
+// Notify 将告警发送到每个渠道中符合严重程度和来源条件的渠道，返回第一个发送失败的错误
+func (n *Notifier) Notify(ctx context.Context, alerts []Alert) error {
+	var firstErr error
+	for i, channel := range n.cfg.Channels {
+		var selected []Alert
+		for _, alert := range alerts {
+			if matchChannel(channel, alert) {
+				selected = append(selected, alert)
+			}
+		}
+		if len(selected) == 0 {
+			continue
+		}
+		if err := send(ctx, n.clients[i], channel, selected); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("notify %s: %v", channel.Name, err)
+		}
+	}
+	return firstErr
+}
+
+// matchChannel 判断告警是否应该发送到渠道
+func matchChannel(channel config.NotifyChannel, alert Alert) bool {
+	if channel.MinSeverity == config.SeverityCritical && alert.Severity != config.SeverityCritical {
+		return false
+	}
+	if len(channel.Sources) == 0 {
+		return true
+	}
+	for _, source := range channel.Sources {
+		if source == alert.Source {
+			return true
+		}
+	}
+	return false
+}
+
+// send 按渠道的消息格式发送告警
+func send(ctx context.Context, client *http.Client, channel config.NotifyChannel, alerts []Alert) error {
+	title := fmt.Sprintf("SysSpector告警：%s", alerts[0].Host)
+	text := formatAlerts(alerts)
+	target := channel.URL
+	var payload interface{}
+	switch channel.Type {
+	case config.NotifySlack:
+		payload = map[string]string{"text": "*" + title + "*\n" + text}
+	case config.NotifyTeams:
+		// Office 365连接器的MessageCard格式，Markdown中的换行需要两个空格
+		payload = map[string]string{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"summary":    title,
+			"title":      title,
+			"themeColor": themeColor(alerts),
+			"text":       strings.ReplaceAll(text, "\n", "  \n"),
+		}
+	case config.NotifyDingTalk:
+		payload = map[string]interface{}{
+			"msgtype":  "markdown",
+			"markdown": map[string]string{"title": title, "text": "### " + title + "\n" + text},
+		}
+		if channel.Secret != "" {
+			target = signDingTalk(target, channel.Secret, time.Now())
+		}
+	case config.NotifyFeishu:
+		message := map[string]interface{}{
+			"msg_type": "text",
+			"content":  map[string]string{"text": title + "\n" + text},
+		}
+		if channel.Secret != "" {
+			timestamp := time.Now().Unix()
+			message["timestamp"] = strconv.FormatInt(timestamp, 10)
+			message["sign"] = signFeishu(channel.Secret, timestamp)
+		}
+		payload = message
+	default:
+		payload = WebhookPayload{Host: alerts[0].Host, DeviceID: alerts[0].DeviceID, Time: time.Now(), Alerts: alerts}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	// 钉钉和飞书在请求被拒绝（签名错误、关键字不匹配等）时仍然返回200，错误码在响应中
+	if channel.Type == config.NotifyDingTalk || channel.Type == config.NotifyFeishu {
+		var result struct {
+			ErrCode int    `json:"errcode"`
+			ErrMsg  string `json:"errmsg"`
+			Code    int    `json:"code"`
+			Msg     string `json:"msg"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err == nil {
+			if result.ErrCode != 0 {
+				return fmt.Errorf("error %d: %s", result.ErrCode, result.ErrMsg)
+			}
+			if result.Code != 0 {
+				return fmt.Errorf("error %d: %s", result.Code, result.Msg)
+			}
+		}
+	}
+	return nil
+}
+
+// formatAlerts 将告警格式化为每行一条的文本
+func formatAlerts(alerts []Alert) string {
+	var lines []string
+	for _, alert := range alerts {
+		severity := "警告"
+		if alert.Severity == config.SeverityCritical {
+			severity = "严重"
+		}
+		line := fmt.Sprintf("- [%s] %s", severity, alert.Title)
+		if alert.Message != "" {
+			line += "：" + alert.Message
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// themeColor 返回Teams消息卡片的颜色，有严重告警时为红色，否则为橙色
+func themeColor(alerts []Alert) string {
+	for _, alert := range alerts {
+		if alert.Severity == config.SeverityCritical {
+			return "D70000"
+		}
+	}
+	return "FF8C00"
+}
+
+// signDingTalk 为钉钉机器人的Webhook地址添加签名参数：
+// 签名为以密钥对"毫秒时间戳\n密钥"做HMAC-SHA256后的Base64编码
+func signDingTalk(webhook, secret string, now time.Time) string {
+	timestamp := strconv.FormatInt(now.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + secret))
+	sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	separator := "&"
+	if !strings.Contains(webhook, "?") {
+		separator = "?"
+	}
+	return webhook + separator + "timestamp=" + timestamp + "&sign=" + url.QueryEscape(sign)
+}
+
+// signFeishu 计算飞书机器人的签名：以"秒级时间戳\n密钥"为密钥对空消息做HMAC-SHA256后的Base64编码
+func signFeishu(secret string, timestamp int64) string {
+	mac := hmac.New(sha256.New, []byte(strconv.FormatInt(timestamp, 10)+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
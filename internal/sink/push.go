@@ -0,0 +1,400 @@
+package sink
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	sysspectorv1 "github.com/AsterZephyr/SysSpector/api/sysspector/v1"
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/tlsconfig"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// 推送请求的参数
+const (
+	pushTimeout  = 30 * time.Second // 每次请求的超时
+	retryBackoff = time.Second      // 第一次重试前的等待时间，之后每次加倍
+)
+
+// 暂存文件的扩展名，记录快照的编码格式和压缩方式，重新发送时使用相同的请求头
+// 增量快照只在发送时计算，不会暂存
+const (
+	extJSON       = ".json"
+	extProtobuf   = ".pb"
+	extMergePatch = ".patch"
+	extGzip       = ".gz"
+
+	spoolTimeFormat = "20060102T150405.000000000Z"
+)
+
+// 推送请求中标识快照的请求头，采集时间为RFC 3339格式
+const (
+	HeaderCollectedAt = "X-SysSpector-Collected-At" // 快照的采集时间
+	HeaderBase        = "X-SysSpector-Base"         // 增量快照所基于的快照的采集时间，收集服务器将补丁合并到该快照上
+	HeaderDeviceID    = "X-SysSpector-Device-ID"    // 设备ID，增量快照中没有变化的字段不会出现，收集服务器据此找到基准快照
+)
+
+// permanentError 表示服务器拒绝了请求（4xx），重试和暂存都没有意义
+type permanentError struct {
+	status int
+}
+
+func (e permanentError) Error() string {
+	return fmt.Sprintf("collector rejected snapshot: HTTP %d", e.status)
+}
+
+// payload 表示一次推送的请求体及其元数据
+type payload struct {
+	body        []byte
+	ext         string    // 编码格式和压缩方式，与暂存文件的扩展名相同
+	collectedAt time.Time // 快照的采集时间
+	baseTime    time.Time // 增量快照所基于的快照的采集时间，完整快照为零值
+	deviceID    string    // 设备ID，补发暂存的快照时为空
+}
+
+// Pusher 将系统信息快照推送到远程收集服务器，发送失败时按指数退避重试，
+// 仍然失败或处于离线模式时暂存到本地目录（按数量和总大小限制），下次推送前按时间顺序补发
+// 连续失败后在退避期间只暂存不发送，上传受带宽上限限制，避免收集服务器恢复时被大量设备同时补发压垮
+// 增量模式下定期发送完整快照，其他时候只发送相对上次被确认（2xx响应）的快照的变化
+type Pusher struct {
+	cfg      config.PushConfig
+	client   *http.Client
+	spoolDir string
+	backoff  backoffState
+	throttle *throttle // 为nil时不限制带宽
+
+	base     *model.SystemInfo // 增量模式下最近一次被确认的快照，为nil时下次发送完整快照
+	baseTime time.Time
+	lastFull time.Time // 最近一次被确认的完整快照的采集时间
+}
+
+// DefaultSpoolDir 返回暂存快照的默认目录
+func DefaultSpoolDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "SysSpector", "spool"), nil
+}
+
+// NewPusher 根据配置创建推送器，加载mTLS客户端证书、自定义CA证书和证书固定
+func NewPusher(cfg config.PushConfig) (*Pusher, error) {
+	spoolDir := cfg.SpoolDir
+	if spoolDir == "" {
+		dir, err := DefaultSpoolDir()
+		if err != nil {
+			return nil, err
+		}
+		spoolDir = dir
+	}
+	if err := os.MkdirAll(spoolDir, 0700); err != nil {
+		return nil, err
+	}
+
+	// 每个请求的超时按请求体大小和带宽上限计算
+	client, err := newHTTPClient(cfg.TLS, 0)
+	if err != nil {
+		return nil, err
+	}
+	p := &Pusher{cfg: cfg, client: client, spoolDir: spoolDir}
+	if cfg.MaxBandwidth > 0 {
+		p.throttle = &throttle{rate: cfg.MaxBandwidth}
+	}
+	p.loadBackoff()
+	return p, nil
+}
+
+// newHTTPClient 创建使用指定TLS配置的HTTP客户端
+func newHTTPClient(cfg config.TLSConfig, timeout time.Duration) (*http.Client, error) {
+	tlsConfig, err := tlsconfig.Client(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("load TLS config: %v", err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// Push 编码并推送一个快照，先补发暂存的快照；离线模式下只暂存不发送
+// 发送失败时暂存的是完整快照，增量模式下之后重新从完整快照开始
+func (p *Pusher) Push(ctx context.Context, info model.SystemInfo, collectedAt time.Time) error {
+	full, err := encodeSnapshot(info, collectedAt, p.cfg.Format, p.cfg.Compression)
+	if err != nil {
+		return err
+	}
+	if config.Current().Offline {
+		p.base = nil
+		return p.spool(full)
+	}
+	if wait := time.Until(p.backoff.RetryAt); wait > 0 {
+		p.base = nil
+		if err := p.spool(full); err != nil {
+			return err
+		}
+		return fmt.Errorf("collector unavailable after %d failures, snapshot spooled, next attempt in %s",
+			p.backoff.Failures, wait.Round(time.Second))
+	}
+
+	if err := p.flush(ctx); err != nil {
+		// 收集服务器仍然不可用，新快照直接暂存，不再重复等待
+		p.base = nil
+		p.recordFailure(err)
+		if spoolErr := p.spool(full); spoolErr != nil {
+			return spoolErr
+		}
+		return err
+	}
+
+	next := full
+	if p.cfg.Delta && p.base != nil && collectedAt.Sub(p.lastFull) < time.Duration(p.cfg.FullInterval)*time.Second {
+		if next, err = p.delta(info, collectedAt); err != nil {
+			return err
+		}
+	}
+	err = p.sendWithRetry(ctx, next)
+	var rejected permanentError
+	if err != nil && !next.baseTime.IsZero() && errors.As(err, &rejected) {
+		// 服务器拒绝了增量快照（例如没有保存基准快照或基准不一致），立即改为发送完整快照，不丢失本次快照
+		next = full
+		err = p.sendWithRetry(ctx, next)
+	}
+	if err == nil {
+		p.recordSuccess()
+		if p.cfg.Delta {
+			p.base, p.baseTime = &info, collectedAt
+			if next.baseTime.IsZero() {
+				p.lastFull = collectedAt
+			}
+		}
+		return nil
+	}
+	// 服务器没有收到或拒绝了快照，下次发送完整快照
+	p.base = nil
+	if !errors.As(err, &rejected) {
+		p.recordFailure(err)
+		if spoolErr := p.spool(full); spoolErr != nil {
+			return spoolErr
+		}
+	}
+	return err
+}
+
+// encodeSnapshot 按指定的格式和压缩方式编码完整快照
+func encodeSnapshot(info model.SystemInfo, collectedAt time.Time, format, compression string) (payload, error) {
+	var body []byte
+	var ext string
+	if format == config.PushFormatProtobuf {
+		snapshot, err := sysspectorv1.NewSnapshot(info, collectedAt)
+		if err != nil {
+			return payload{}, err
+		}
+		if body, err = proto.Marshal(snapshot); err != nil {
+			return payload{}, err
+		}
+		ext = extProtobuf
+	} else {
+		var err error
+		if body, err = json.Marshal(info); err != nil {
+			return payload{}, err
+		}
+		ext = extJSON
+	}
+	return compress(payload{body: body, ext: ext, collectedAt: collectedAt, deviceID: info.DeviceID}, compression)
+}
+
+// delta 计算相对上次被确认的快照的JSON合并补丁（RFC 7396）
+func (p *Pusher) delta(info model.SystemInfo, collectedAt time.Time) (payload, error) {
+	patch, err := model.Diff(*p.base, info)
+	if err != nil {
+		return payload{}, err
+	}
+	return compress(payload{body: patch, ext: extMergePatch, collectedAt: collectedAt, baseTime: p.baseTime, deviceID: info.DeviceID}, p.cfg.Compression)
+}
+
+// compress 按指定的压缩方式压缩请求体
+func compress(pl payload, compression string) (payload, error) {
+	if compression != config.CompressionGzip {
+		return pl, nil
+	}
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(pl.body); err != nil {
+		return payload{}, err
+	}
+	if err := writer.Close(); err != nil {
+		return payload{}, err
+	}
+	pl.body, pl.ext = buf.Bytes(), pl.ext+extGzip
+	return pl, nil
+}
+
+// sendWithRetry 发送快照，网络错误、5xx和429响应时按带有随机抖动的指数退避重试，
+// 服务器通过Retry-After要求等待时不再重试，由Push在退避期间暂存
+func (p *Pusher) sendWithRetry(ctx context.Context, pl payload) error {
+	backoff := retryBackoff
+	var err error
+	for attempt := 1; attempt <= p.cfg.MaxAttempts; attempt++ {
+		if err = p.send(ctx, pl); err == nil {
+			return nil
+		}
+		var rejected permanentError
+		var busy busyError
+		if errors.As(err, &rejected) || (errors.As(err, &busy) && busy.retryAfter > 0) || attempt == p.cfg.MaxAttempts {
+			break
+		}
+		select {
+		case <-time.After(jitter(backoff)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+	return err
+}
+
+// send 发送一次快照，设置了带宽上限时按上限发送请求体
+func (p *Pusher) send(ctx context.Context, pl payload) error {
+	timeout := pushTimeout
+	var body io.Reader = bytes.NewReader(pl.body)
+	if p.throttle != nil {
+		timeout += p.throttle.duration(len(pl.body))
+		body = &throttledReader{ctx: ctx, reader: body, throttle: p.throttle}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.URL, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(pl.body))
+	req.Header.Set("Content-Type", pl.contentType())
+	if strings.HasSuffix(pl.ext, extGzip) {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	req.Header.Set(HeaderCollectedAt, pl.collectedAt.Format(time.RFC3339Nano))
+	if !pl.baseTime.IsZero() {
+		req.Header.Set(HeaderBase, pl.baseTime.Format(time.RFC3339Nano))
+	}
+	if pl.deviceID != "" {
+		req.Header.Set(HeaderDeviceID, pl.deviceID)
+	}
+	if p.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.cfg.Token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		return busyError{status: resp.StatusCode, retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())}
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return permanentError{status: resp.StatusCode}
+	default:
+		return fmt.Errorf("collector returned HTTP %d", resp.StatusCode)
+	}
+}
+
+// contentType 返回请求体编码格式对应的Content-Type
+func (pl payload) contentType() string {
+	switch {
+	case strings.HasPrefix(pl.ext, extProtobuf):
+		return "application/x-protobuf"
+	case strings.HasPrefix(pl.ext, extMergePatch):
+		return "application/merge-patch+json"
+	default:
+		return "application/json"
+	}
+}
+
+// spool 将快照写入暂存目录，文件名为采集时间，超出数量或总大小上限时删除最早的快照
+func (p *Pusher) spool(pl payload) error {
+	name := pl.collectedAt.UTC().Format(spoolTimeFormat) + pl.ext
+	if err := os.WriteFile(filepath.Join(p.spoolDir, name), pl.body, 0600); err != nil {
+		return err
+	}
+	files, err := p.spooled()
+	if err != nil {
+		return err
+	}
+	sizes := make([]int64, len(files))
+	var total int64
+	for i, file := range files {
+		if stat, err := os.Stat(filepath.Join(p.spoolDir, file)); err == nil {
+			sizes[i] = stat.Size()
+			total += sizes[i]
+		}
+	}
+	for len(files) > 0 && (len(files) > p.cfg.MaxSpooled || total > p.cfg.MaxSpoolSize) {
+		os.Remove(filepath.Join(p.spoolDir, files[0]))
+		total -= sizes[0]
+		files, sizes = files[1:], sizes[1:]
+	}
+	return nil
+}
+
+// Pending 返回暂存目录中尚未发送的快照数
+func (p *Pusher) Pending() (int, error) {
+	files, err := p.spooled()
+	return len(files), err
+}
+
+// spooled 按时间顺序列出暂存的快照
+func (p *Pusher) spooled() ([]string, error) {
+	entries, err := os.ReadDir(p.spoolDir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && (strings.Contains(name, extJSON) || strings.Contains(name, extProtobuf)) {
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// flush 按时间顺序补发暂存的快照，发送成功或被服务器拒绝的快照会被删除，遇到网络错误时停止
+func (p *Pusher) flush(ctx context.Context) error {
+	files, err := p.spooled()
+	if err != nil {
+		return err
+	}
+	for _, name := range files {
+		path := filepath.Join(p.spoolDir, name)
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		// 文件名为"采集时间Z"加扩展名，采集时间中也有小数点
+		timestamp, ext, _ := strings.Cut(name, "Z")
+		collectedAt, _ := time.Parse(spoolTimeFormat, timestamp+"Z")
+		err = p.sendWithRetry(ctx, payload{body: body, ext: ext, collectedAt: collectedAt})
+		var rejected permanentError
+		if err != nil && !errors.As(err, &rejected) {
+			return err
+		}
+		os.Remove(path)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package sink
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+func TestPusherDelta(t *testing.T) {
+	baseTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	collectedAt := baseTime.Add(5 * time.Minute)
+	base := model.SystemInfo{
+		Hostname: "host",
+		DeviceID: "device",
+		Network:  model.NetworkInfo{WiFi: model.WiFiInfo{SSID: "office", RSSI: -50}},
+		DiskUsage: []model.DiskPartitionInfo{
+			{MountPoint: "/", Total: 100, Used: 40},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		update func(info *model.SystemInfo)
+		want   string
+	}{
+		{
+			name:   "no changes",
+			update: func(info *model.SystemInfo) {},
+			want:   `{}`,
+		},
+		{
+			name:   "top-level field",
+			update: func(info *model.SystemInfo) { info.Hostname = "renamed" },
+			want:   `{"Hostname": "renamed"}`,
+		},
+		{
+			name:   "nested field",
+			update: func(info *model.SystemInfo) { info.Network.WiFi.RSSI = -70 },
+			want:   `{"Network": {"WiFi": {"RSSI": -70}}}`,
+		},
+		{
+			name: "array replaced as a whole",
+			update: func(info *model.SystemInfo) {
+				info.DiskUsage = []model.DiskPartitionInfo{{MountPoint: "/", Total: 100, Used: 50}}
+			},
+			want: `{"DiskUsage": [{"MountPoint": "/", "Filesystem": "", "Total": 100, "Used": 50, "Free": 0, "UsedPerc": 0}]}`,
+		},
+		{
+			name:   "array removed",
+			update: func(info *model.SystemInfo) { info.DiskUsage = nil },
+			want:   `{"DiskUsage": null}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := base
+			info.DiskUsage = append([]model.DiskPartitionInfo(nil), base.DiskUsage...)
+			tt.update(&info)
+
+			p := &Pusher{cfg: config.PushConfig{Compression: config.CompressionNone}, base: &base, baseTime: baseTime}
+			pl, err := p.delta(info, collectedAt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pl.ext != extMergePatch || !pl.baseTime.Equal(baseTime) || !pl.collectedAt.Equal(collectedAt) || pl.deviceID != "device" {
+				t.Errorf("delta() payload = ext %q, base %s, collected %s, device %q", pl.ext, pl.baseTime, pl.collectedAt, pl.deviceID)
+			}
+			assertJSONEqual(t, pl.body, tt.want)
+
+			// 补丁合并到基准快照上应得到新快照
+			patched, err := model.Patch(base, pl.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(patched, info) {
+				t.Errorf("Patch(base, delta) = %+v, want %+v", patched, info)
+			}
+		})
+	}
+}
+
+func TestPusherDeltaGzip(t *testing.T) {
+	base := model.SystemInfo{Hostname: "host"}
+	info := model.SystemInfo{Hostname: "renamed"}
+	p := &Pusher{cfg: config.PushConfig{Compression: config.CompressionGzip}, base: &base, baseTime: time.Now()}
+	pl, err := p.delta(info, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pl.ext != extMergePatch+extGzip {
+		t.Errorf("delta() ext = %q, want %q", pl.ext, extMergePatch+extGzip)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(pl.body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertJSONEqual(t, body, `{"Hostname": "renamed"}`)
+}
+
+// assertJSONEqual 比较两个JSON文本解析后是否相同
+func assertJSONEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("invalid JSON %s: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("invalid JSON %s: %v", want, err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
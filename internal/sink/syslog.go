@@ -0,0 +1,197 @@
+package sink
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/tlsconfig"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// syslog消息的参数
+const (
+	syslogTimeout = 10 * time.Second // 连接和每次发送的超时
+	syslogAppName = "sysspector"
+
+	// syslogSDID 结构化数据的ID，32473是RFC 5612为示例保留的企业号
+	syslogSDID = "sysspector@32473"
+)
+
+// syslog消息的严重程度（RFC 5424）
+const (
+	syslogCritical = 2
+	syslogWarning  = 4
+	syslogInfo     = 6
+)
+
+// syslog消息的MSGID
+const (
+	syslogMsgSnapshot = "snapshot"
+	syslogMsgAlert    = "alert"
+)
+
+// SyslogForwarder 将快照摘要和告警以RFC 5424格式发送到syslog服务器：UDP每条消息一个数据报，
+// TCP和TLS按RFC 6587、RFC 5425在每条消息前加上长度；连接断开时重新连接后再发送一次
+type SyslogForwarder struct {
+	cfg      config.SyslogConfig
+	tls      *tls.Config
+	facility int
+	procID   string
+	alerts   alertTracker
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogForwarder 创建syslog转发器，第一次发送时才连接服务器
+func NewSyslogForwarder(cfg config.SyslogConfig) (*SyslogForwarder, error) {
+	f := &SyslogForwarder{cfg: cfg, facility: config.SyslogFacilities[cfg.Facility], procID: strconv.Itoa(os.Getpid())}
+	if cfg.Network == config.SyslogTLS {
+		tlsConfig, err := tlsconfig.Client(cfg.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("load TLS config: %v", err)
+		}
+		f.tls = tlsConfig
+	}
+	return f, nil
+}
+
+// NewAlerts 返回上次检查时不存在的告警，已经消失的告警下次出现时会重新发送
+func (f *SyslogForwarder) NewAlerts(alerts []Alert) []Alert {
+	return f.alerts.newAlerts(alerts)
+}
+
+// Forward 发送一条快照摘要（informational）和每条告警（warning或critical）
+func (f *SyslogForwarder) Forward(info model.SystemInfo, collectedAt time.Time, alerts []Alert) error {
+	network := info.Network
+	health := network.HealthScore
+	summary := fmt.Sprintf("%s %s", info.Hostname, info.SystemVersion)
+	if health.Grade != "" {
+		summary += fmt.Sprintf("，网络健康评分 %d（%s）：%s", health.Score, health.Grade, health.Verdict)
+	}
+	params := [][2]string{
+		{"deviceId", info.DeviceID},
+		{"os", info.OS},
+		{"model", info.Model},
+		{"serialNumber", info.SerialNumber},
+		{"ip", network.IP},
+		{"publicIp", network.PublicIP},
+		{"ssid", network.WiFi.SSID},
+		{"vpnConnected", strconv.FormatBool(network.VPN.IsConnected)},
+		{"vpnProvider", network.VPN.Provider},
+		{"avgLatencyMs", strconv.FormatFloat(network.Latency.AvgLatency, 'f', 1, 64)},
+		{"packetLoss", strconv.FormatFloat(network.Latency.PacketLoss, 'f', 1, 64)},
+		{"healthScore", strconv.Itoa(health.Score)},
+	}
+	if err := f.send(f.format(syslogInfo, collectedAt, info.Hostname, syslogMsgSnapshot, params, summary)); err != nil {
+		return err
+	}
+
+	for _, alert := range alerts {
+		severity := syslogWarning
+		if alert.Severity == config.SeverityCritical {
+			severity = syslogCritical
+		}
+		params := [][2]string{{"deviceId", alert.DeviceID}, {"source", alert.Source}, {"severity", alert.Severity}}
+		message := alert.Title + "：" + alert.Message
+		if err := f.send(f.format(severity, alert.Time, alert.Host, syslogMsgAlert, params, message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// format 按RFC 5424格式化一条消息：<PRI>1 时间 主机名 应用名 进程号 MSGID [结构化数据] 消息，值为空的参数省略
+func (f *SyslogForwarder) format(severity int, t time.Time, hostname, msgID string, params [][2]string, message string) []byte {
+	var sd strings.Builder
+	sd.WriteString("[" + syslogSDID)
+	for _, param := range params {
+		if param[1] != "" {
+			sd.WriteString(" " + param[0] + `="` + escapeSDValue(param[1]) + `"`)
+		}
+	}
+	sd.WriteString("]")
+	return []byte(fmt.Sprintf("<%d>1 %s %s %s %s %s %s %s",
+		f.facility*8+severity, t.Format("2006-01-02T15:04:05.000000Z07:00"), headerField(hostname, 255),
+		syslogAppName, f.procID, msgID, sd.String(), message))
+}
+
+// escapeSDValue 转义结构化数据参数值中的双引号、反斜杠和右方括号
+func escapeSDValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
+
+// headerField 将消息头中的字段限制为可打印的ASCII字符，为空时使用"-"
+func headerField(value string, maxLen int) string {
+	field := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, value)
+	if len(field) > maxLen {
+		field = field[:maxLen]
+	}
+	if field == "" {
+		return "-"
+	}
+	return field
+}
+
+// send 发送一条消息，写入失败时重新连接后再发送一次
+func (f *SyslogForwarder) send(message []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.cfg.Network != config.SyslogUDP {
+		message = append([]byte(strconv.Itoa(len(message))+" "), message...)
+	}
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if f.conn == nil {
+			conn, err := f.dial()
+			if err != nil {
+				return fmt.Errorf("connect to syslog server %s: %v", f.cfg.Address, err)
+			}
+			f.conn = conn
+		}
+		f.conn.SetWriteDeadline(time.Now().Add(syslogTimeout))
+		if _, err = f.conn.Write(message); err == nil {
+			return nil
+		}
+		f.conn.Close()
+		f.conn = nil
+	}
+	return err
+}
+
+// dial 按配置的传输方式连接syslog服务器
+func (f *SyslogForwarder) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: syslogTimeout}
+	switch f.cfg.Network {
+	case config.SyslogTLS:
+		return tls.DialWithDialer(dialer, "tcp", f.cfg.Address, f.tls)
+	case config.SyslogTCP:
+		return dialer.Dial("tcp", f.cfg.Address)
+	default:
+		return dialer.Dial("udp", f.cfg.Address)
+	}
+}
+
+// Close 关闭与syslog服务器的连接
+func (f *SyslogForwarder) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.conn == nil {
+		return nil
+	}
+	err := f.conn.Close()
+	f.conn = nil
+	return err
+}
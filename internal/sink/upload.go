@@ -0,0 +1,276 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// 上传对象的参数
+const (
+	uploadTimeout    = 60 * time.Second // 每次上传的超时
+	uploadTimeFormat = "20060102T150405Z"
+
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	azureAPIVersion = "2021-08-06" // 支持加密范围和客户提供的密钥的Blob服务版本
+)
+
+// storageErrorCode 匹配S3和Azure错误响应（XML）中的错误码
+var storageErrorCode = regexp.MustCompile(`<Code>([^<]+)</Code>`)
+
+// Uploader 将快照上传到兼容S3的对象存储或Azure Blob，每个快照一个对象，
+// 对象名为"前缀/设备ID/年/月/日/采集时间"加扩展名，可以按设备和日期的前缀列出或查询
+// S3使用AWS签名版本4，Azure使用共享密钥签名或SAS
+type Uploader struct {
+	cfg        config.UploadConfig
+	client     *http.Client
+	endpoint   *url.URL
+	accountKey []byte // Azure存储账户的访问密钥
+}
+
+// NewUploader 根据配置创建上传器，S3没有配置访问密钥时使用AWS_ACCESS_KEY_ID、AWS_SECRET_ACCESS_KEY和AWS_SESSION_TOKEN环境变量
+func NewUploader(cfg config.UploadConfig) (*Uploader, error) {
+	u := &Uploader{cfg: cfg}
+	endpoint := cfg.Endpoint
+	if cfg.Provider == config.UploadAzure {
+		if endpoint == "" {
+			endpoint = "https://" + cfg.Account + ".blob.core.windows.net"
+		}
+		if cfg.AccountKey != "" {
+			key, err := base64.StdEncoding.DecodeString(cfg.AccountKey)
+			if err != nil {
+				return nil, errors.New("upload: account_key is not valid base64")
+			}
+			u.accountKey = key
+		}
+	} else {
+		if endpoint == "" {
+			endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+		}
+		if u.cfg.AccessKeyID == "" {
+			u.cfg.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+			u.cfg.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+			u.cfg.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+		}
+		if u.cfg.AccessKeyID == "" || u.cfg.SecretAccessKey == "" {
+			return nil, errors.New("upload: s3 access key is not configured")
+		}
+	}
+	parsed, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("upload: invalid endpoint %q", endpoint)
+	}
+	u.endpoint = parsed
+
+	if u.client, err = newHTTPClient(cfg.TLS, uploadTimeout); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Upload 编码并上传一个快照，返回对象名
+func (u *Uploader) Upload(ctx context.Context, info model.SystemInfo, collectedAt time.Time) (string, error) {
+	pl, err := encodeSnapshot(info, collectedAt, u.cfg.Format, u.cfg.Compression)
+	if err != nil {
+		return "", err
+	}
+	key := u.objectKey(info, pl)
+	req, err := u.newRequest(ctx, key, pl)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if match := storageErrorCode.FindSubmatch(body); match != nil {
+			return "", fmt.Errorf("object storage returned HTTP %d: %s", resp.StatusCode, match[1])
+		}
+		return "", fmt.Errorf("object storage returned HTTP %d", resp.StatusCode)
+	}
+	return key, nil
+}
+
+// objectKey 返回快照的对象名，没有设备ID时使用主机名
+func (u *Uploader) objectKey(info model.SystemInfo, pl payload) string {
+	device := info.DeviceID
+	if device == "" {
+		device = info.Hostname
+	}
+	collectedAt := pl.collectedAt.UTC()
+	return path.Join(u.cfg.Prefix, device, collectedAt.Format("2006/01/02"), collectedAt.Format(uploadTimeFormat)+pl.ext)
+}
+
+// newRequest 创建上传对象的PUT请求并签名，S3默认使用虚拟主机形式的地址（bucket.endpoint/key）
+func (u *Uploader) newRequest(ctx context.Context, key string, pl payload) (*http.Request, error) {
+	target := *u.endpoint
+	objectPath := "/" + key
+	if u.cfg.Provider == config.UploadAzure || u.cfg.PathStyle {
+		objectPath = "/" + u.cfg.Bucket + objectPath
+	} else {
+		target.Host = u.cfg.Bucket + "." + target.Host
+	}
+	target.Path += objectPath
+	target.RawPath = uriEncodePath(target.Path)
+	if u.cfg.Provider == config.UploadAzure && u.cfg.SASToken != "" {
+		target.RawQuery = u.cfg.SASToken
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), bytes.NewReader(pl.body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", pl.contentType())
+	if strings.HasSuffix(pl.ext, extGzip) {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if u.cfg.Provider == config.UploadAzure {
+		u.signAzure(req, len(pl.body))
+	} else {
+		u.signS3(req, pl.body)
+	}
+	return req, nil
+}
+
+// signS3 设置服务端加密的请求头，并按AWS签名版本4签名Host、Content-Type、Content-Encoding和所有x-amz-*请求头
+func (u *Uploader) signS3(req *http.Request, body []byte) {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if u.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", u.cfg.SessionToken)
+	}
+	switch {
+	case u.cfg.CustomerKey != "":
+		key, _ := base64.StdEncoding.DecodeString(u.cfg.CustomerKey)
+		sum := md5.Sum(key)
+		req.Header.Set("X-Amz-Server-Side-Encryption-Customer-Algorithm", "AES256")
+		req.Header.Set("X-Amz-Server-Side-Encryption-Customer-Key", u.cfg.CustomerKey)
+		req.Header.Set("X-Amz-Server-Side-Encryption-Customer-Key-Md5", base64.StdEncoding.EncodeToString(sum[:]))
+	case u.cfg.SSE != "":
+		req.Header.Set("X-Amz-Server-Side-Encryption", u.cfg.SSE)
+		if u.cfg.KMSKeyID != "" {
+			req.Header.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", u.cfg.KMSKeyID)
+		}
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(values[0])
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{req.Method, req.URL.EscapedPath(), req.URL.RawQuery,
+		canonicalHeaders.String(), signedHeaders, payloadHash}, "\n")
+
+	date := now.Format("20060102")
+	scope := date + "/" + u.cfg.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{sigV4Algorithm, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+	signingKey := []byte("AWS4" + u.cfg.SecretAccessKey)
+	for _, part := range []string{date, u.cfg.Region, "s3", "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, u.cfg.AccessKeyID, scope, signedHeaders, hex.EncodeToString(hmacSHA256(signingKey, stringToSign))))
+}
+
+// signAzure 设置Put Blob和加密的请求头，使用SAS时地址中已经带有签名，否则按共享密钥签名
+func (u *Uploader) signAzure(req *http.Request, contentLength int) {
+	req.Header.Set("X-Ms-Date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("X-Ms-Version", azureAPIVersion)
+	req.Header.Set("X-Ms-Blob-Type", "BlockBlob")
+	switch {
+	case u.cfg.CustomerKey != "":
+		key, _ := base64.StdEncoding.DecodeString(u.cfg.CustomerKey)
+		sum := sha256.Sum256(key)
+		req.Header.Set("X-Ms-Encryption-Key", u.cfg.CustomerKey)
+		req.Header.Set("X-Ms-Encryption-Key-Sha256", base64.StdEncoding.EncodeToString(sum[:]))
+		req.Header.Set("X-Ms-Encryption-Algorithm", "AES256")
+	case u.cfg.EncryptionScope != "":
+		req.Header.Set("X-Ms-Encryption-Scope", u.cfg.EncryptionScope)
+	}
+	if u.accountKey == nil {
+		return
+	}
+
+	var msHeaders []string
+	for name, values := range req.Header {
+		if name = strings.ToLower(name); strings.HasPrefix(name, "x-ms-") {
+			msHeaders = append(msHeaders, name+":"+strings.TrimSpace(values[0]))
+		}
+	}
+	sort.Strings(msHeaders)
+	length := ""
+	if contentLength > 0 {
+		length = strconv.Itoa(contentLength)
+	}
+	// 依次为方法、Content-Encoding、Content-Language、Content-Length、Content-MD5、Content-Type、Date、
+	// If-Modified-Since、If-Match、If-None-Match、If-Unmodified-Since、Range，之后为x-ms-*请求头和资源路径
+	stringToSign := strings.Join([]string{req.Method, req.Header.Get("Content-Encoding"), "", length, "",
+		req.Header.Get("Content-Type"), "", "", "", "", "", ""}, "\n") +
+		"\n" + strings.Join(msHeaders, "\n") + "\n/" + u.cfg.Account + req.URL.EscapedPath()
+	mac := hmac.New(sha256.New, u.accountKey)
+	mac.Write([]byte(stringToSign))
+	req.Header.Set("Authorization", "SharedKey "+u.cfg.Account+":"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+}
+
+// uriEncodePath 按RFC 3986编码路径，只保留非保留字符和"/"
+func uriEncodePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			(c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// sha256Hex 返回数据的SHA-256摘要（十六进制）
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 返回以key计算的data的HMAC-SHA256
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,43 @@
+package sockets
+
+import (
+	"net"
+	"syscall"
+
+	psnet "github.com/shirou/gopsutil/v3/net"
+)
+
+// Connection 表示一个已建立的TCP连接
+type Connection struct {
+	PID        int32
+	Process    string
+	RemoteIP   net.IP
+	RemotePort uint32
+}
+
+// Established 返回所有已建立的TCP连接及其所属进程，忽略本机内部的回环连接
+func Established() ([]Connection, error) {
+	connections, err := psnet.Connections("tcp")
+	if err != nil {
+		return nil, err
+	}
+
+	processes := newProcessCache()
+	var established []Connection
+	for _, conn := range connections {
+		if conn.Type != syscall.SOCK_STREAM || conn.Status != "ESTABLISHED" {
+			continue
+		}
+		ip := net.ParseIP(conn.Raddr.IP)
+		if ip == nil || ip.IsLoopback() {
+			continue
+		}
+
+		connection := Connection{PID: conn.Pid, RemoteIP: ip, RemotePort: conn.Raddr.Port}
+		if conn.Pid > 0 {
+			connection.Process, _ = processes.lookup(conn.Pid)
+		}
+		established = append(established, connection)
+	}
+	return established, nil
+}
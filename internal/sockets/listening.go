@@ -0,0 +1,79 @@
+package sockets
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+	psnet "github.com/shirou/gopsutil/v3/net"
+)
+
+// Listening 返回所有处于监听状态的TCP套接字和未连接的UDP套接字及其所属进程，按协议和端口排序
+// 非管理员权限下可能无法获取其他用户进程的套接字或进程信息
+func Listening() ([]model.ListeningPortInfo, error) {
+	connections, err := psnet.Connections("inet")
+	if err != nil {
+		return nil, err
+	}
+
+	processes := newProcessCache()
+	seen := make(map[string]bool)
+	var ports []model.ListeningPortInfo
+	for _, conn := range connections {
+		var protocol string
+		switch conn.Type {
+		case syscall.SOCK_STREAM:
+			if conn.Status != "LISTEN" {
+				continue
+			}
+			protocol = "TCP"
+		case syscall.SOCK_DGRAM:
+			// UDP没有监听状态，未连接远端的套接字视为监听
+			if conn.Raddr.IP != "" && conn.Raddr.Port != 0 {
+				continue
+			}
+			protocol = "UDP"
+		default:
+			continue
+		}
+		if conn.Family == syscall.AF_INET6 {
+			protocol += "6"
+		}
+
+		// 同一进程的多个套接字可能监听同一地址（例如SO_REUSEPORT），只保留一条
+		key := fmt.Sprintf("%s %s %d %d", protocol, conn.Laddr.IP, conn.Laddr.Port, conn.Pid)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		port := model.ListeningPortInfo{
+			Protocol: protocol,
+			Address:  conn.Laddr.IP,
+			Port:     conn.Laddr.Port,
+			PID:      conn.Pid,
+			Exposed:  !isLoopback(conn.Laddr.IP),
+		}
+		if conn.Pid > 0 {
+			port.Process, port.Path = processes.lookup(conn.Pid)
+		}
+		ports = append(ports, port)
+	}
+
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Protocol != ports[j].Protocol {
+			return ports[i].Protocol < ports[j].Protocol
+		}
+		return ports[i].Port < ports[j].Port
+	})
+	return ports, nil
+}
+
+// isLoopback 判断监听地址是否只能从本机访问
+func isLoopback(address string) bool {
+	ip := net.ParseIP(strings.Split(address, "%")[0])
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,31 @@
+package sockets
+
+import "github.com/shirou/gopsutil/v3/process"
+
+// processInfo 表示进程名称和可执行文件路径
+type processInfo struct {
+	name string
+	path string
+}
+
+// processCache 缓存进程信息，避免同一进程的多个套接字重复查询
+type processCache map[int32]processInfo
+
+func newProcessCache() processCache {
+	return make(processCache)
+}
+
+// lookup 返回进程名称和可执行文件路径，进程已退出或没有权限时返回空字符串
+func (c processCache) lookup(pid int32) (string, string) {
+	if info, ok := c[pid]; ok {
+		return info.name, info.path
+	}
+
+	var info processInfo
+	if p, err := process.NewProcess(pid); err == nil {
+		info.name, _ = p.Name()
+		info.path, _ = p.Exe()
+	}
+	c[pid] = info
+	return info.name, info.path
+}
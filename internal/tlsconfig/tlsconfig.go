@@ -0,0 +1,198 @@
+package tlsconfig
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/pkcs12"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/darwin"
+	"github.com/AsterZephyr/SysSpector/internal/windows"
+)
+
+// Client 根据配置创建连接远程服务器使用的TLS配置：配置了证书时作为mTLS客户端证书，
+// ca_file为校验服务器证书的CA，pins要求服务器证书链中有固定的公钥
+func Client(cfg config.TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.HasCertificate() {
+		cert, err := loadCertificate(cfg)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if cfg.CAFile != "" {
+		pool, err := loadCertPool(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if len(cfg.Pins) > 0 {
+		tlsConfig.VerifyConnection = verifyPins(cfg.Pins)
+	}
+	return tlsConfig, nil
+}
+
+// Server 根据配置创建serve模式下提供服务使用的TLS配置，没有配置证书时返回nil（不使用TLS）。
+// 配置了ca_file时要求客户端提供该CA签发的证书；配置了pins时要求客户端证书的公钥在固定列表中
+func Server(cfg config.TLSConfig) (*tls.Config, error) {
+	if !cfg.HasCertificate() {
+		if cfg.CAFile != "" || len(cfg.Pins) > 0 {
+			return nil, errors.New("client certificate verification requires a server certificate")
+		}
+		return nil, nil
+	}
+	cert, err := loadCertificate(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, Certificates: []tls.Certificate{cert}}
+	switch {
+	case cfg.CAFile != "":
+		pool, err := loadCertPool(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	case len(cfg.Pins) > 0:
+		// 只使用证书固定时不校验证书链，握手已经证明客户端持有固定公钥对应的私钥
+		tlsConfig.ClientAuth = tls.RequireAnyClientCert
+	}
+	if len(cfg.Pins) > 0 {
+		tlsConfig.VerifyConnection = verifyPins(cfg.Pins)
+	}
+	return tlsConfig, nil
+}
+
+// Pin 返回证书公钥的固定摘要（SubjectPublicKeyInfo的SHA-256，base64编码）
+func Pin(cert *x509.Certificate) string {
+	digest := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(digest[:])
+}
+
+// verifyPins 返回检查对端证书链中是否有固定公钥的校验函数。证书链经过校验时检查校验通过的证书链，
+// 没有校验证书链时（服务器只使用证书固定）只检查对端自己的证书：对端发送的其他证书没有经过校验，
+// 任何人都可以把固定的CA证书附在自签名证书之后
+func verifyPins(pins []string) func(tls.ConnectionState) error {
+	allowed := make(map[string]bool)
+	for _, pin := range pins {
+		allowed[pin] = true
+	}
+	return func(state tls.ConnectionState) error {
+		if len(state.VerifiedChains) == 0 {
+			if len(state.PeerCertificates) > 0 && allowed[Pin(state.PeerCertificates[0])] {
+				return nil
+			}
+			return errors.New("peer certificate does not match the configured pins")
+		}
+		for _, chain := range state.VerifiedChains {
+			for _, cert := range chain {
+				if allowed[Pin(cert)] {
+					return nil
+				}
+			}
+		}
+		return errors.New("no certificate in the verified peer chain matches the configured pins")
+	}
+}
+
+// loadCertPool 读取PEM格式的CA证书
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+// loadCertificate 从PEM文件或系统证书存储加载证书和私钥
+func loadCertificate(cfg config.TLSConfig) (tls.Certificate, error) {
+	if cfg.CertFile != "" {
+		return tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	}
+
+	// 导出使用的临时密码，只用于本次解析
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		return tls.Certificate{}, err
+	}
+	password := hex.EncodeToString(secret)
+	var data []byte
+	var err error
+	if runtime.GOOS == "windows" {
+		data, err = windows.ExportCertificate(cfg.CertStore, password)
+	} else {
+		data, err = darwin.ExportIdentities(password)
+	}
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return findIdentity(data, password, cfg.CertStore)
+}
+
+// findIdentity 在PKCS#12数据中查找主题包含name、带有私钥且未过期的证书，有多个时取有效期最晚的一个
+// 证书和私钥通过localKeyId属性对应
+func findIdentity(data []byte, password, name string) (tls.Certificate, error) {
+	blocks, err := pkcs12.ToPEM(data, password)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	keys := make(map[string]crypto.PrivateKey)
+	for _, block := range blocks {
+		if block.Type != "PRIVATE KEY" {
+			continue
+		}
+		if key, err := parsePrivateKey(block.Bytes); err == nil {
+			keys[block.Headers["localKeyId"]] = key
+		}
+	}
+
+	var best tls.Certificate
+	now := time.Now()
+	for _, block := range blocks {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		key, ok := keys[block.Headers["localKeyId"]]
+		if !ok {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil || now.After(cert.NotAfter) || !strings.Contains(strings.ToLower(cert.Subject.String()), strings.ToLower(name)) {
+			continue
+		}
+		if best.Leaf == nil || cert.NotAfter.After(best.Leaf.NotAfter) {
+			best = tls.Certificate{Certificate: [][]byte{cert.Raw}, PrivateKey: key, Leaf: cert}
+		}
+	}
+	if best.Leaf == nil {
+		return best, fmt.Errorf("no valid certificate with a private key matches %q in the certificate store", name)
+	}
+	return best, nil
+}
+
+// parsePrivateKey 解析pkcs12.ToPEM输出的私钥，RSA私钥为PKCS#1格式，ECDSA私钥为SEC 1格式
+func parsePrivateKey(der []byte) (crypto.PrivateKey, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	return x509.ParseECPrivateKey(der)
+}
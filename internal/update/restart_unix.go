@@ -0,0 +1,18 @@
+//go:build !windows
+// +build !windows
+
+package update
+
+import (
+	"os"
+	"syscall"
+)
+
+// Restart 以相同的参数和环境变量执行替换后的程序，进程ID不变，launchd等服务管理器不会认为服务退出
+func Restart() error {
+	executable, err := Executable()
+	if err != nil {
+		return err
+	}
+	return syscall.Exec(executable, os.Args, os.Environ())
+}
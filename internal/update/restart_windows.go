@@ -0,0 +1,12 @@
+//go:build windows
+// +build windows
+
+package update
+
+import "errors"
+
+// Restart 在Windows上不能替换当前进程。作为服务运行时由服务的恢复操作重新启动，
+// 在前台运行时需要手动重新启动
+func Restart() error {
+	return errors.New("the program has been updated, start it again to use the new version")
+}
@@ -0,0 +1,281 @@
+// Package update 从更新通道检查新版本，下载签名的发布并替换当前程序
+//
+// 更新通道提供一个JSON格式的发布清单，清单的Ed25519签名（base64）在同一地址加".sig"：
+//
+//	{"version": "1.5.0", "assets": {"darwin-arm64": {"url": "sysinfo_macos_arm", "sha256": "..."}}}
+//
+// 清单中的程序地址可以是相对于清单地址的相对地址。只有签名有效的清单才会被使用，
+// 下载的程序的SHA-256与清单一致后才替换当前程序。
+package update
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/tlsconfig"
+)
+
+// 下载的参数
+const (
+	manifestTimeout   = 30 * time.Second
+	downloadTimeout   = 10 * time.Minute
+	maxManifestSize   = 1 << 20
+	maxExecutableSize = 512 << 20
+)
+
+// ErrRestart 表示已经替换了程序，需要重新启动才能使用新版本
+var ErrRestart = errors.New("restart to apply the update")
+
+// startupExecutable 为启动时的程序路径，替换程序后Linux上的os.Executable会返回已删除的旧文件
+var startupExecutable, startupExecutableErr = os.Executable()
+
+// Manifest 表示更新通道的发布清单
+type Manifest struct {
+	Version string           `json:"version"`
+	Assets  map[string]Asset `json:"assets"` // 键为"GOOS-GOARCH"，例如darwin-arm64、windows-amd64
+}
+
+// Asset 表示一个平台的程序
+type Asset struct {
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256"`
+}
+
+// Asset 返回当前平台的程序
+func (m Manifest) Asset() (Asset, bool) {
+	asset, ok := m.Assets[runtime.GOOS+"-"+runtime.GOARCH]
+	return asset, ok
+}
+
+// Updater 从配置的更新通道检查和下载新版本
+type Updater struct {
+	url    string
+	keys   []ed25519.PublicKey
+	client *http.Client
+}
+
+// New 根据配置创建更新器
+func New(cfg config.UpdateConfig) (*Updater, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("update: url is not configured")
+	}
+	u := &Updater{url: strings.ReplaceAll(cfg.URL, "{channel}", url.PathEscape(cfg.Channel))}
+	for _, key := range cfg.PublicKeys {
+		decoded, err := base64.StdEncoding.DecodeString(key)
+		if err != nil || len(decoded) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("update: invalid public key %q", key)
+		}
+		u.keys = append(u.keys, ed25519.PublicKey(decoded))
+	}
+	tlsConfig, err := tlsconfig.Client(cfg.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("load TLS config: %v", err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	u.client = &http.Client{Transport: transport}
+	return u, nil
+}
+
+// Check 下载发布清单并校验签名
+func (u *Updater) Check(ctx context.Context) (Manifest, error) {
+	ctx, cancel := context.WithTimeout(ctx, manifestTimeout)
+	defer cancel()
+	data, err := u.fetch(ctx, u.url)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("download manifest: %v", err)
+	}
+	sig, err := u.fetch(ctx, u.url+".sig")
+	if err != nil {
+		return Manifest{}, fmt.Errorf("download manifest signature: %v", err)
+	}
+	if !u.verify(data, sig) {
+		return Manifest{}, errors.New("manifest signature is invalid")
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return Manifest{}, fmt.Errorf("parse manifest: %v", err)
+	}
+	if manifest.Version == "" {
+		return Manifest{}, errors.New("manifest has no version")
+	}
+	return manifest, nil
+}
+
+// verify 检查sig是否为任一公钥对data的签名
+func (u *Updater) verify(data, sig []byte) bool {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return false
+	}
+	for _, key := range u.keys {
+		if ed25519.Verify(key, data, decoded) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetch 下载一个较小的文件
+func (u *Updater) fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	resp, err := u.get(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+}
+
+// get 发送GET请求，状态码不是200时返回错误
+func (u *Updater) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s", rawURL, resp.Status)
+	}
+	return resp, nil
+}
+
+// Apply 下载asset，校验SHA-256后替换当前程序
+// 新程序先下载到程序所在目录的临时文件中，校验通过后再通过重命名替换，替换失败时保留原来的程序
+func (u *Updater) Apply(ctx context.Context, asset Asset) error {
+	expected, err := hex.DecodeString(asset.SHA256)
+	if err != nil || len(expected) != sha256.Size {
+		return fmt.Errorf("invalid sha256 %q in manifest", asset.SHA256)
+	}
+	base, err := url.Parse(u.url)
+	if err != nil {
+		return err
+	}
+	ref, err := url.Parse(asset.URL)
+	if err != nil {
+		return fmt.Errorf("invalid asset url %q: %v", asset.URL, err)
+	}
+	executable, err := Executable()
+	if err != nil {
+		return err
+	}
+	stat, err := os.Stat(executable)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, downloadTimeout)
+	defer cancel()
+	resp, err := u.get(ctx, base.ResolveReference(ref).String())
+	if err != nil {
+		return fmt.Errorf("download: %v", err)
+	}
+	defer resp.Body.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(executable), ".sysinfo-update-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(resp.Body, maxExecutableSize))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("download: %v", err)
+	}
+	if sum := hash.Sum(nil); !bytes.Equal(sum, expected) {
+		return fmt.Errorf("checksum mismatch: got %x, want %x", sum, expected)
+	}
+	if err := os.Chmod(tmp.Name(), stat.Mode().Perm()|0700); err != nil {
+		return err
+	}
+	return replace(executable, tmp.Name())
+}
+
+// replace 用newPath替换executable。Windows上不能覆盖正在运行的程序，但可以重命名，
+// 先将原来的程序重命名为.old，下次更新时删除
+func replace(executable, newPath string) error {
+	if runtime.GOOS != "windows" {
+		return os.Rename(newPath, executable)
+	}
+	old := executable + ".old"
+	os.Remove(old)
+	if err := os.Rename(executable, old); err != nil {
+		return err
+	}
+	if err := os.Rename(newPath, executable); err != nil {
+		os.Rename(old, executable)
+		return err
+	}
+	return nil
+}
+
+// Executable 返回当前程序的路径，解析符号链接后替换的是实际的程序文件
+func Executable() (string, error) {
+	if startupExecutableErr != nil {
+		return "", startupExecutableErr
+	}
+	return filepath.EvalSymlinks(startupExecutable)
+}
+
+// Newer 返回latest是否比current新，版本号按点分隔的数字比较，可以带"v"前缀；
+// current不是版本号（例如开发版本"dev"）时返回false，只能强制更新
+func Newer(latest, current string) bool {
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	for i := 0; i < len(l) || i < len(c); i++ {
+		var a, b int
+		if i < len(l) {
+			a = l[i]
+		}
+		if i < len(c) {
+			b = c[i]
+		}
+		if a != b {
+			return a > b
+		}
+	}
+	return false
+}
+
+// parseVersion 解析点分隔的版本号
+func parseVersion(version string) ([]int, bool) {
+	fields := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	parts := make([]int, len(fields))
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
@@ -0,0 +1,76 @@
+package update
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+)
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		want            bool
+	}{
+		{"1.5.0", "1.4.9", true},
+		{"1.10.0", "1.9.0", true},
+		{"v1.5.1", "1.5.0", true},
+		{"1.5.1", "1.5", true},
+		{"2", "1.9.9", true},
+		{"1.5.0", "1.5.0", false},
+		{"1.5", "1.5.0", false},
+		{"1.4.9", "1.5.0", false},
+		{"1.5.0", "dev", false},
+		{"latest", "1.5.0", false},
+		{"1.5.0-rc1", "1.4.0", false},
+		{"1.-1", "1.0", false},
+		{"", "1.0", false},
+	}
+	for _, tt := range tests {
+		if got := Newer(tt.latest, tt.current); got != tt.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tt.latest, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	oldPublic, oldKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newPublic, newKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &Updater{keys: []ed25519.PublicKey{oldPublic, newPublic}}
+	manifest := []byte(`{"version": "1.5.0"}`)
+	sign := func(key ed25519.PrivateKey, data []byte) []byte {
+		return []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)))
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		sig  []byte
+		want bool
+	}{
+		{"old key", manifest, sign(oldKey, manifest), true},
+		{"new key during rotation", manifest, sign(newKey, manifest), true},
+		{"trailing newline", manifest, append(sign(oldKey, manifest), '\n'), true},
+		{"unknown key", manifest, sign(otherKey, manifest), false},
+		{"tampered manifest", []byte(`{"version": "9.9.9"}`), sign(oldKey, manifest), false},
+		{"not base64", manifest, []byte("not a signature"), false},
+		{"empty", manifest, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := u.verify(tt.data, tt.sig); got != tt.want {
+				t.Errorf("verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
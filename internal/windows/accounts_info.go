@@ -0,0 +1,98 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// localUser 表示Get-LocalUser返回的账户
+type localUser struct {
+	Name             string
+	FullName         string
+	SID              string
+	Enabled          bool
+	PasswordRequired bool
+	LastLogon        string
+	IsAdmin          bool
+}
+
+// localAccountsScript 枚举本地账户，通过内置Administrators组的SID判断管理员，避免依赖系统语言
+const localAccountsScript = `$admins = @(Get-LocalGroupMember -SID 'S-1-5-32-544' -ErrorAction SilentlyContinue | ForEach-Object { $_.SID.Value })
+Get-LocalUser | ForEach-Object {
+	[PSCustomObject]@{
+		Name = $_.Name
+		FullName = $_.FullName
+		SID = $_.SID.Value
+		Enabled = $_.Enabled
+		PasswordRequired = $_.PasswordRequired
+		LastLogon = if ($_.LastLogon) { $_.LastLogon.ToString('yyyy-MM-dd HH:mm:ss') } else { '' }
+		IsAdmin = $admins -contains $_.SID.Value
+	}
+}`
+
+// getLocalAccounts 获取本地用户账户、管理员身份、密码要求和上次登录时间
+func getLocalAccounts(ctx context.Context) ([]model.LocalAccountInfo, error) {
+	var users []localUser
+	if err := runPowerShellJSON(ctx, localAccountsScript, &users); err != nil {
+		return nil, err
+	}
+
+	var accounts []model.LocalAccountInfo
+	for _, user := range users {
+		accounts = append(accounts, model.LocalAccountInfo{
+			Name:       user.Name,
+			FullName:   user.FullName,
+			ID:         user.SID,
+			IsAdmin:    user.IsAdmin,
+			Disabled:   !user.Enabled,
+			NoPassword: !user.PasswordRequired,
+			LastLogin:  user.LastLogon,
+		})
+	}
+
+	return accounts, nil
+}
+
+// runDsregcmd 执行dsregcmd /status并解析为键值对，输出格式为：AzureAdJoined : YES
+func runDsregcmd(ctx context.Context) (map[string]string, error) {
+	cmd := exec.CommandContext(ctx, "dsregcmd", "/status")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.SplitN(line, " : ", 2)
+		if len(parts) == 2 {
+			values[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	return values, nil
+}
+
+// getIdentity 通过dsregcmd /status获取AD域和Azure AD/Entra加入状态
+func getIdentity(ctx context.Context) (model.IdentityInfo, error) {
+	var identity model.IdentityInfo
+
+	values, err := runDsregcmd(ctx)
+	if err != nil {
+		return identity, err
+	}
+
+	identity.DomainJoined = values["DomainJoined"] == "YES"
+	identity.Domain = values["DomainName"]
+	identity.AzureADJoined = values["AzureAdJoined"] == "YES"
+	identity.WorkplaceJoined = values["WorkplaceJoined"] == "YES"
+	identity.EnterpriseJoined = values["EnterpriseJoined"] == "YES"
+	identity.TenantName = values["TenantName"]
+	identity.TenantID = values["TenantId"]
+
+	return identity, nil
+}
@@ -0,0 +1,102 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// windowsApplicationID SoftwareLicensingProduct中Windows操作系统许可证的ApplicationID
+const windowsApplicationID = "55c92734-d682-4d71-983e-d6ec3f16059f"
+
+// softwareLicensingProduct 表示SoftwareLicensingProduct中的许可证信息（slmgr /dlv的数据来源）
+type softwareLicensingProduct struct {
+	Name                                      string
+	Description                               string
+	LicenseStatus                             uint32
+	PartialProductKey                         string
+	GracePeriodRemaining                      uint32 // 分钟
+	KeyManagementServiceMachine               string
+	KeyManagementServicePort                  uint32
+	DiscoveredKeyManagementServiceMachineName string
+	DiscoveredKeyManagementServiceMachinePort uint32
+}
+
+// licenseStatuses LicenseStatus的含义
+var licenseStatuses = map[uint32]string{
+	0: "未激活",
+	1: "已激活",
+	2: "初始宽限期",
+	3: "非正常宽限期（硬件变更）",
+	4: "非正版宽限期",
+	5: "通知模式（未激活）",
+	6: "延长宽限期",
+}
+
+// licenseChannels Description中的渠道标识，如"Windows(R) Operating System, VOLUME_KMSCLIENT channel"
+var licenseChannels = []struct {
+	marker  string
+	channel string
+}{
+	{"VOLUME_KMSCLIENT", "Volume:KMS"},
+	{"VOLUME_MAK", "Volume:MAK"},
+	{"OEM", "OEM"},
+	{"RETAIL", "Retail"},
+}
+
+// getActivation 获取Windows激活状态、许可证渠道和KMS服务器
+func getActivation() (model.ActivationInfo, error) {
+	var activation model.ActivationInfo
+
+	var products []softwareLicensingProduct
+	query := fmt.Sprintf("SELECT Name, Description, LicenseStatus, PartialProductKey, GracePeriodRemaining, "+
+		"KeyManagementServiceMachine, KeyManagementServicePort, DiscoveredKeyManagementServiceMachineName, DiscoveredKeyManagementServiceMachinePort "+
+		"FROM SoftwareLicensingProduct WHERE ApplicationID = '%s' AND PartialProductKey IS NOT NULL", windowsApplicationID)
+	if err := safeWMIQuery(query, &products); err != nil {
+		return activation, err
+	}
+	if len(products) == 0 {
+		activation.Status = "未安装产品密钥"
+		return activation, nil
+	}
+
+	product := products[0]
+	activation.Product = product.Name
+	activation.PartialKey = product.PartialProductKey
+	activation.Status = licenseStatuses[product.LicenseStatus]
+	if product.LicenseStatus != 1 && product.GracePeriodRemaining > 0 {
+		activation.GraceRemaining = int(product.GracePeriodRemaining / (60 * 24))
+	}
+
+	description := strings.ToUpper(product.Description)
+	for _, c := range licenseChannels {
+		if strings.Contains(description, c.marker) {
+			activation.Channel = c.channel
+			break
+		}
+	}
+
+	// 手动指定的KMS服务器优先，其次是通过DNS SRV记录发现的服务器
+	if activation.Channel == "Volume:KMS" {
+		switch {
+		case product.KeyManagementServiceMachine != "":
+			activation.KMSHost = fmt.Sprintf("%s:%d", product.KeyManagementServiceMachine, kmsPort(product.KeyManagementServicePort))
+		case product.DiscoveredKeyManagementServiceMachineName != "":
+			activation.KMSHost = fmt.Sprintf("%s:%d", product.DiscoveredKeyManagementServiceMachineName, kmsPort(product.DiscoveredKeyManagementServiceMachinePort))
+		}
+	}
+
+	return activation, nil
+}
+
+// kmsPort 未设置端口时使用KMS默认端口1688
+func kmsPort(port uint32) uint32 {
+	if port == 0 {
+		return 1688
+	}
+	return port
+}
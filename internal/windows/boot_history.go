@@ -0,0 +1,139 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// bootHistoryCount 报告的启动和关机事件数量
+const bootHistoryCount = 10
+
+// bootEvent 表示系统日志中与启动和关机相关的事件
+type bootEvent struct {
+	Id          int
+	TimeCreated string
+	Properties  []string
+}
+
+// bootEventsScript 获取启动（Kernel-General 12）、意外关机（Kernel-Power 41）以及程序或用户发起的关机和重启（User32 1074）事件
+const bootEventsScript = `Get-WinEvent -FilterHashtable @{ LogName = 'System'; Id = 12, 41, 1074 } -MaxEvents 60 -ErrorAction SilentlyContinue |
+	Where-Object { ($_.Id -eq 12 -and $_.ProviderName -eq 'Microsoft-Windows-Kernel-General') -or ($_.Id -eq 41 -and $_.ProviderName -eq 'Microsoft-Windows-Kernel-Power') -or ($_.Id -eq 1074 -and $_.ProviderName -eq 'User32') } |
+	ForEach-Object {
+		[PSCustomObject]@{
+			Id = $_.Id
+			TimeCreated = $_.TimeCreated.ToString('yyyy-MM-dd HH:mm:ss')
+			Properties = @($_.Properties | ForEach-Object { [string]$_.Value })
+		}
+	}`
+
+// updateProcesses 由Windows更新发起重启的进程
+var updateProcesses = map[string]bool{
+	"trustedinstaller.exe":  true,
+	"tiworker.exe":          true,
+	"mousocoreworker.exe":   true,
+	"usoclient.exe":         true,
+	"wuauclt.exe":           true,
+	"musnotification.exe":   true,
+	"musnotificationux.exe": true,
+}
+
+// getBootHistory 获取最近的启动和关机记录，并根据事件判断原因
+func getBootHistory(ctx context.Context) ([]model.BootEventInfo, error) {
+	var events []bootEvent
+	if err := runPowerShellJSON(ctx, bootEventsScript, &events); err != nil {
+		return nil, err
+	}
+
+	// 事件从新到旧排列；41号事件在异常关机后的下一次启动时写入，合并到对应的启动记录
+	var history []model.BootEventInfo
+	var crashes []bootEvent
+	for _, event := range events {
+		switch event.Id {
+		case 12:
+			history = append(history, model.BootEventInfo{Time: event.TimeCreated, Type: "启动"})
+		case 1074:
+			history = append(history, parseShutdownEvent(event))
+		case 41:
+			crashes = append(crashes, event)
+		}
+	}
+
+	for _, crash := range crashes {
+		crashTime, err := time.ParseInLocation("2006-01-02 15:04:05", crash.TimeCreated, time.Local)
+		if err != nil {
+			continue
+		}
+		for i := range history {
+			bootTime, err := time.ParseInLocation("2006-01-02 15:04:05", history[i].Time, time.Local)
+			if err != nil || history[i].Type != "启动" {
+				continue
+			}
+			if diff := crashTime.Sub(bootTime); diff >= 0 && diff <= 5*time.Minute {
+				history[i].Reason, history[i].Detail = crashReason(crash)
+				break
+			}
+		}
+	}
+
+	if len(history) > bootHistoryCount {
+		history = history[:bootHistoryCount]
+	}
+	return history, nil
+}
+
+// parseShutdownEvent 解析1074事件，属性依次为：进程、计算机名、原因、原因代码、关机类型、注释、用户
+func parseShutdownEvent(event bootEvent) model.BootEventInfo {
+	info := model.BootEventInfo{Time: event.TimeCreated, Type: "关机"}
+	property := func(i int) string {
+		if i < len(event.Properties) {
+			return strings.TrimSpace(event.Properties[i])
+		}
+		return ""
+	}
+
+	// 关机类型为本地化文本，如restart/重新启动
+	shutdownType := strings.ToLower(property(4))
+	if strings.Contains(shutdownType, "restart") || strings.Contains(shutdownType, "重新启动") {
+		info.Type = "重启"
+	}
+
+	// 进程格式为"C:\Windows\servicing\TrustedInstaller.exe (PC-NAME)"
+	process := property(0)
+	if idx := strings.Index(process, " ("); idx > 0 {
+		process = process[:idx]
+	}
+	processName := strings.ToLower(filepath.Base(process))
+	if updateProcesses[processName] {
+		info.Reason = "系统更新"
+	} else {
+		info.Reason = "用户或程序发起"
+	}
+
+	info.Detail = filepath.Base(process)
+	if user := property(6); user != "" {
+		info.Detail += "，用户 " + user
+	}
+	if reason := property(2); reason != "" {
+		info.Detail += "，" + reason
+	}
+	return info
+}
+
+// crashReason 根据41号事件的BugcheckCode判断是蓝屏还是断电或强制关机
+func crashReason(event bootEvent) (string, string) {
+	if len(event.Properties) > 0 {
+		if code, err := strconv.ParseUint(event.Properties[0], 10, 64); err == nil && code != 0 {
+			return "系统崩溃", fmt.Sprintf("蓝屏代码 0x%X", code)
+		}
+	}
+	return "意外关机", "断电、强制关机或系统无响应"
+}
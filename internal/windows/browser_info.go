@@ -0,0 +1,115 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/internal/browser"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// knownBrowser 表示已知浏览器的可执行文件、ProgId前缀及用户数据目录
+type knownBrowser struct {
+	exe     string
+	progID  string
+	baseEnv string // 用户数据目录所在的环境变量
+	dataDir string // 相对于baseEnv的用户数据目录
+	firefox bool
+}
+
+// knownBrowsers 已知的浏览器列表
+var knownBrowsers = []knownBrowser{
+	{exe: "chrome.exe", progID: "ChromeHTML", baseEnv: "LOCALAPPDATA", dataDir: `Google\Chrome\User Data`},
+	{exe: "msedge.exe", progID: "MSEdgeHTM", baseEnv: "LOCALAPPDATA", dataDir: `Microsoft\Edge\User Data`},
+	{exe: "firefox.exe", progID: "FirefoxURL", baseEnv: "APPDATA", dataDir: `Mozilla\Firefox\Profiles`, firefox: true},
+	{exe: "brave.exe", progID: "BraveHTML", baseEnv: "LOCALAPPDATA", dataDir: `BraveSoftware\Brave-Browser\User Data`},
+	{exe: "vivaldi.exe", progID: "VivaldiHTM", baseEnv: "LOCALAPPDATA", dataDir: `Vivaldi\User Data`},
+	{exe: "chromium.exe", progID: "ChromiumHTM", baseEnv: "LOCALAPPDATA", dataDir: `Chromium\User Data`},
+	{exe: "opera.exe", progID: "Opera"},
+	{exe: "launcher.exe", progID: "Opera"},
+	{exe: "iexplore.exe", progID: "IE."},
+}
+
+// startMenuInternet 表示注册表StartMenuInternet下注册的浏览器
+type startMenuInternet struct {
+	Name    string
+	Path    string
+	Version string
+}
+
+// getBrowsers 获取已安装的浏览器和默认浏览器
+func getBrowsers(ctx context.Context) (model.BrowsersInfo, error) {
+	var browsers model.BrowsersInfo
+
+	var registered []startMenuInternet
+	err := runPowerShellJSON(ctx, `$keys = 'HKLM:\SOFTWARE\Clients\StartMenuInternet', 'HKLM:\SOFTWARE\WOW6432Node\Clients\StartMenuInternet', 'HKCU:\SOFTWARE\Clients\StartMenuInternet'
+Get-ChildItem $keys -ErrorAction SilentlyContinue | ForEach-Object {
+	$cmd = (Get-ItemProperty (Join-Path $_.PSPath 'shell\open\command') -ErrorAction SilentlyContinue).'(default)'
+	$exe = if ($cmd) { $cmd -replace '^"([^"]+)".*$', '$1' } else { '' }
+	[PSCustomObject]@{
+		Name = (Get-ItemProperty $_.PSPath).'(default)'
+		Path = $exe
+		Version = if ($exe -and (Test-Path $exe)) { (Get-Item $exe).VersionInfo.ProductVersion } else { '' }
+	}
+}`, &registered)
+	if err != nil {
+		return browsers, err
+	}
+
+	// 读取http协议的默认处理程序
+	defaultProgID := ""
+	if output, err := runPowerShell(ctx, `(Get-ItemProperty 'HKCU:\Software\Microsoft\Windows\Shell\Associations\UrlAssociations\http\UserChoice' -ErrorAction SilentlyContinue).ProgId`); err == nil {
+		defaultProgID = strings.TrimSpace(output)
+	}
+
+	seen := make(map[string]bool)
+	for _, r := range registered {
+		if r.Path == "" || seen[strings.ToLower(r.Path)] {
+			continue
+		}
+		seen[strings.ToLower(r.Path)] = true
+
+		browserInfo := model.BrowserInfo{
+			Name:       r.Name,
+			Version:    r.Version,
+			Path:       r.Path,
+			Extensions: -1,
+		}
+
+		exe := strings.ToLower(filepath.Base(r.Path))
+		for _, known := range knownBrowsers {
+			if exe != known.exe {
+				continue
+			}
+			if defaultProgID != "" && strings.HasPrefix(defaultProgID, known.progID) {
+				browserInfo.IsDefault = true
+			}
+			if known.dataDir != "" && os.Getenv(known.baseEnv) != "" {
+				dataDir := filepath.Join(os.Getenv(known.baseEnv), known.dataDir)
+				if known.firefox {
+					browserInfo.Extensions, browserInfo.Proxy = browser.FirefoxProfile(dataDir)
+				} else {
+					browserInfo.Extensions, browserInfo.Proxy = browser.ChromiumProfile(dataDir)
+				}
+			}
+			break
+		}
+
+		if browserInfo.IsDefault {
+			browsers.Default = browserInfo.Name
+		}
+		browsers.Installed = append(browsers.Installed, browserInfo)
+	}
+
+	// 默认浏览器不在已注册列表中时显示ProgId
+	if browsers.Default == "" {
+		browsers.Default = defaultProgID
+	}
+
+	return browsers, nil
+}
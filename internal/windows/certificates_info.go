@@ -0,0 +1,86 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// certificateEntry 表示PowerShell导出的证书，Data为DER编码，Blob为注册表中的序列化证书
+type certificateEntry struct {
+	Store string
+	Data  string
+	Blob  string
+}
+
+// certificatesScript 导出个人证书存储中的证书，以及通过组策略或AD企业存储下发的根证书
+// 完整的受信任根存储包含大量系统内置根证书，因此只读取企业下发的部分
+const certificatesScript = `foreach ($store in 'Cert:\LocalMachine\My', 'Cert:\CurrentUser\My') {
+	Get-ChildItem $store -ErrorAction SilentlyContinue | ForEach-Object {
+		[PSCustomObject]@{ Store = $store; Data = [Convert]::ToBase64String($_.RawData); Blob = '' }
+	}
+}
+foreach ($key in 'HKLM:\SOFTWARE\Policies\Microsoft\SystemCertificates\Root\Certificates',
+	'HKLM:\SOFTWARE\Microsoft\EnterpriseCertificates\Root\Certificates') {
+	Get-ChildItem $key -ErrorAction SilentlyContinue | ForEach-Object {
+		$blob = (Get-ItemProperty $_.PSPath -ErrorAction SilentlyContinue).Blob
+		if ($blob) { [PSCustomObject]@{ Store = $key; Data = ''; Blob = [Convert]::ToBase64String($blob) } }
+	}
+}`
+
+// certCertPropID 序列化证书中保存DER编码证书的属性ID（CERT_CERT_PROP_ID）
+const certCertPropID = 32
+
+// getCertificates 获取个人证书和企业下发的根证书
+func getCertificates(ctx context.Context) ([]model.CertificateInfo, error) {
+	var entries []certificateEntry
+	if err := runPowerShellJSON(ctx, certificatesScript, &entries); err != nil {
+		return nil, err
+	}
+
+	var certificates []model.CertificateInfo
+	for _, entry := range entries {
+		var der []byte
+		if entry.Data != "" {
+			der, _ = base64.StdEncoding.DecodeString(entry.Data)
+		} else if blob, err := base64.StdEncoding.DecodeString(entry.Blob); err == nil {
+			der = certificateFromBlob(blob)
+		}
+		if der == nil {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			continue
+		}
+		certificates = append(certificates, analysis.EvaluateCertificate(cert, entry.Store))
+	}
+
+	return certificates, nil
+}
+
+// certificateFromBlob 从注册表中的序列化证书提取DER编码的证书
+// 格式为连续的属性：属性ID(4字节) + 保留(4字节) + 长度(4字节) + 数据
+func certificateFromBlob(blob []byte) []byte {
+	for len(blob) >= 12 {
+		propID := binary.LittleEndian.Uint32(blob[0:4])
+		length := binary.LittleEndian.Uint32(blob[8:12])
+		blob = blob[12:]
+		if uint32(len(blob)) < length {
+			return nil
+		}
+		if propID == certCertPropID {
+			return blob[:length]
+		}
+		blob = blob[length:]
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// adminOnlySDDL 只允许SYSTEM和Administrators访问的安全描述符，不继承上级目录（%ProgramData%允许普通用户创建文件）的权限，
+// 目录中新建的文件和子目录继承同样的权限
+const adminOnlySDDL = "O:BAD:PAI(A;OICI;FA;;;SY)(A;OICI;FA;;;BA)"
+
+// trustedInstallerSID TrustedInstaller服务的SID，系统文件的所有者
+const trustedInstallerSID = "S-1-5-80-956008885-3418522649-1831038044-1853292631-2271478464"
+
+// 可以修改文件内容、替换文件或修改权限的访问权限
+const configWriteMask = 0x2 | // FILE_WRITE_DATA/FILE_ADD_FILE
+	0x4 | // FILE_APPEND_DATA/FILE_ADD_SUBDIRECTORY
+	0x40 | // FILE_DELETE_CHILD
+	windows.DELETE | windows.WRITE_DAC | windows.WRITE_OWNER |
+	windows.GENERIC_WRITE | windows.GENERIC_ALL
+
+// sddlRights SDDL中访问权限缩写对应的访问掩码
+var sddlRights = map[string]uint32{
+	"GA": windows.GENERIC_ALL, "GR": windows.GENERIC_READ, "GW": windows.GENERIC_WRITE, "GX": windows.GENERIC_EXECUTE,
+	"RC": windows.READ_CONTROL, "SD": windows.DELETE, "WD": windows.WRITE_DAC, "WO": windows.WRITE_OWNER,
+	"CC": 0x1, "DC": 0x2, "LC": 0x4, "SW": 0x8, "RP": 0x10, "WP": 0x20, "DT": 0x40, "LO": 0x80, "CR": 0x100,
+	"FA": 0x1f01ff, "FR": 0x120089, "FW": 0x120116, "FX": 0x1200a0,
+}
+
+// sddlACERegex 匹配SDDL中DACL的每个ACE
+var sddlACERegex = regexp.MustCompile(`\(([^)]*)\)`)
+
+// secureServiceDir 创建服务的数据目录，并将其权限设置为只有SYSTEM和Administrators可以访问，
+// 已有的文件和子目录继承同样的权限
+func secureServiceDir(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	return setAdminOnly(dir)
+}
+
+// setAdminOnly 将文件或目录的所有者设为Administrators，DACL设为adminOnlySDDL
+func setAdminOnly(path string) error {
+	sd, err := windows.SecurityDescriptorFromString(adminOnlySDDL)
+	if err != nil {
+		return err
+	}
+	owner, _, err := sd.Owner()
+	if err != nil {
+		return err
+	}
+	dacl, _, err := sd.DACL()
+	if err != nil {
+		return err
+	}
+	var info windows.SECURITY_INFORMATION = windows.OWNER_SECURITY_INFORMATION | windows.DACL_SECURITY_INFORMATION | windows.PROTECTED_DACL_SECURITY_INFORMATION
+	if err := windows.SetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, info, owner, nil, dacl, nil); err != nil {
+		return fmt.Errorf("set permissions of %s: %v", path, err)
+	}
+	return nil
+}
+
+// SecureConfigFile 将服务使用的配置文件的权限设为只有SYSTEM和Administrators可以访问，
+// 文件不存在时以同样的权限创建，内容为defaults。文件所在的目录不是服务的数据目录时，
+// 只检查而不修改目录的权限，普通用户可以在目录中创建或替换文件时返回错误
+func SecureConfigFile(path string, defaults []byte) error {
+	dir := filepath.Dir(path)
+	if strings.EqualFold(filepath.Clean(dir), serviceDataDir()) {
+		if err := secureServiceDir(dir); err != nil {
+			return err
+		}
+	} else if err := checkAdminOnly(dir); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		return setAdminOnly(path)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	return createAdminOnly(path, defaults)
+}
+
+// createAdminOnly 以adminOnlySDDL的权限创建文件并写入data，文件已存在时返回错误
+func createAdminOnly(path string, data []byte) error {
+	sd, err := windows.SecurityDescriptorFromString(adminOnlySDDL)
+	if err != nil {
+		return err
+	}
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return err
+	}
+	sa := &windows.SecurityAttributes{SecurityDescriptor: sd}
+	sa.Length = uint32(unsafe.Sizeof(*sa))
+	handle, err := windows.CreateFile(name, windows.GENERIC_WRITE, 0, sa, windows.CREATE_NEW, windows.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		return fmt.Errorf("create %s: %v", path, err)
+	}
+	file := os.NewFile(uintptr(handle), path)
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// CheckConfigPermissions 检查以SYSTEM运行的服务加载的配置文件及其所在目录是否只有SYSTEM和Administrators可以修改。
+// 普通用户可以修改或在目录中放置配置文件时，可以通过update.url、commands.public_keys等配置获得SYSTEM权限，
+// 这种情况下返回错误，不加载配置文件。目录或文件不存在时不检查
+func CheckConfigPermissions(path string) error {
+	for _, p := range []string{filepath.Dir(path), path} {
+		if err := checkAdminOnly(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAdminOnly 检查文件或目录的所有者是否为SYSTEM、Administrators或TrustedInstaller，
+// 并且DACL中没有允许其他用户修改的ACE。文件不存在时返回的错误满足errors.Is(err, os.ErrNotExist)
+func checkAdminOnly(path string) error {
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.OWNER_SECURITY_INFORMATION|windows.DACL_SECURITY_INFORMATION)
+	if err != nil {
+		if errors.Is(err, windows.ERROR_FILE_NOT_FOUND) || errors.Is(err, windows.ERROR_PATH_NOT_FOUND) {
+			return fmt.Errorf("%s: %w", path, os.ErrNotExist)
+		}
+		return fmt.Errorf("get permissions of %s: %v", path, err)
+	}
+	owner, _, err := sd.Owner()
+	if err != nil {
+		return err
+	}
+	if !trustedSID(owner.String()) {
+		return fmt.Errorf("%s is owned by %s, only Administrators or SYSTEM may own it", path, owner)
+	}
+	dacl, _, err := sd.DACL()
+	if err != nil {
+		return err
+	}
+	if dacl == nil {
+		return fmt.Errorf("%s has no DACL and can be modified by everyone", path)
+	}
+	if sid := writableBy(sd.String()); sid != "" {
+		return fmt.Errorf("%s can be modified by %s, only Administrators and SYSTEM may modify it", path, sid)
+	}
+	return nil
+}
+
+// writableBy 返回SDDL中被允许修改对象的第一个不受信任的账户，没有时返回空字符串。
+// 只作用于子对象的ACE（IO）和拒绝ACE不影响对象本身的修改权限，无法识别的访问权限按可以修改处理
+func writableBy(sddl string) string {
+	dacl := sddl
+	if i := strings.Index(dacl, "D:"); i >= 0 {
+		dacl = dacl[i+2:]
+	}
+	if i := strings.Index(dacl, "S:"); i >= 0 {
+		dacl = dacl[:i]
+	}
+	for _, match := range sddlACERegex.FindAllStringSubmatch(dacl, -1) {
+		fields := strings.Split(match[1], ";")
+		if len(fields) < 6 || (fields[0] != "A" && fields[0] != "OA") {
+			continue
+		}
+		if strings.Contains(fields[1], "IO") {
+			continue
+		}
+		if accessMask(fields[2])&configWriteMask != 0 && !trustedSID(fields[5]) {
+			return fields[5]
+		}
+	}
+	return ""
+}
+
+// accessMask 解析SDDL中ACE的访问权限，可以是十六进制的掩码或权限缩写的组合
+func accessMask(rights string) uint32 {
+	if hex, ok := strings.CutPrefix(strings.ToLower(rights), "0x"); ok {
+		mask, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return ^uint32(0)
+		}
+		return uint32(mask)
+	}
+	var mask uint32
+	for i := 0; i+2 <= len(rights); i += 2 {
+		right, ok := sddlRights[rights[i:i+2]]
+		if !ok {
+			return ^uint32(0)
+		}
+		mask |= right
+	}
+	return mask
+}
+
+// trustedSID 判断SID（字符串形式或SDDL缩写）是否为SYSTEM、Administrators、TrustedInstaller或对象的所有者
+func trustedSID(sid string) bool {
+	switch sid {
+	case "SY", "BA", "OW", "S-1-5-18", "S-1-5-32-544", trustedInstallerSID:
+		return true
+	}
+	return false
+}
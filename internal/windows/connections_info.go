@@ -0,0 +1,19 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/internal/sockets"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// getConnections 获取已建立的连接，并按进程和远端组织汇总
+func getConnections() ([]model.ConnectionGroupInfo, error) {
+	connections, err := sockets.Established()
+	if err != nil {
+		return nil, err
+	}
+	return analysis.SummarizeConnections(connections), nil
+}
@@ -0,0 +1,66 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// defaultHandler 表示PowerShell返回的默认程序
+type defaultHandler struct {
+	Type          string
+	ProgId        string
+	Name          string
+	SystemDefault bool
+}
+
+// defaultHandlersScript 读取常见协议和文件扩展名的UserChoice
+// 用户未选择时，文件扩展名使用HKCR中注册的默认ProgId；程序名称优先取Application\ApplicationName
+const defaultHandlersScript = `New-PSDrive -Name HKCR -PSProvider Registry -Root HKEY_CLASSES_ROOT -ErrorAction SilentlyContinue | Out-Null
+$schemes = 'http', 'https', 'mailto'
+$extensions = '.html', '.pdf', '.docx', '.xlsx', '.pptx', '.txt', '.eml', '.ics'
+$items = @($schemes | ForEach-Object { @{ Type = $_; Key = "HKCU:\Software\Microsoft\Windows\Shell\Associations\UrlAssociations\$_\UserChoice" } }) +
+	@($extensions | ForEach-Object { @{ Type = $_; Key = "HKCU:\Software\Microsoft\Windows\CurrentVersion\Explorer\FileExts\$_\UserChoice" } })
+foreach ($item in $items) {
+	$progId = (Get-ItemProperty $item.Key -ErrorAction SilentlyContinue).ProgId
+	$systemDefault = $false
+	if (-not $progId -and $item.Type.StartsWith('.')) {
+		$progId = (Get-ItemProperty "HKCR:\$($item.Type)" -ErrorAction SilentlyContinue).'(default)'
+		$systemDefault = $true
+	}
+	$name = ''
+	if ($progId) {
+		$name = (Get-ItemProperty "HKCR:\$progId\Application" -ErrorAction SilentlyContinue).ApplicationName
+		if (-not $name -or $name.StartsWith('@')) { $name = (Get-ItemProperty "HKCR:\$progId" -ErrorAction SilentlyContinue).'(default)' }
+	}
+	[PSCustomObject]@{ Type = $item.Type; ProgId = [string]$progId; Name = [string]$name; SystemDefault = $systemDefault }
+}`
+
+// getDefaultHandlers 获取常见协议和文件类型的默认打开程序
+func getDefaultHandlers(ctx context.Context) ([]model.DefaultHandlerInfo, error) {
+	var entries []defaultHandler
+	if err := runPowerShellJSON(ctx, defaultHandlersScript, &entries); err != nil {
+		return nil, err
+	}
+
+	var handlers []model.DefaultHandlerInfo
+	for _, entry := range entries {
+		handler := model.DefaultHandlerInfo{
+			Type:          entry.Type,
+			Handler:       entry.Name,
+			ID:            entry.ProgId,
+			SystemDefault: entry.SystemDefault,
+		}
+		if handler.Handler == "" {
+			handler.Handler = entry.ProgId
+		}
+		if handler.Handler == "" {
+			handler.Handler = "未设置"
+		}
+		handlers = append(handlers, handler)
+	}
+
+	return handlers, nil
+}
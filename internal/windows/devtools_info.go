@@ -0,0 +1,87 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// devToolProbe 表示通过命令行查询版本的开发工具
+type devToolProbe struct {
+	name    string
+	command string
+	args    []string
+}
+
+// devToolProbes 需要检测的运行时和包管理器，java -version输出到标准错误，因此统一读取合并输出
+var devToolProbes = []devToolProbe{
+	{"Java", "java", []string{"-version"}},
+	{"Python", "python", []string{"--version"}},
+	{"Node.js", "node", []string{"--version"}},
+	{"npm", "npm", []string{"--version"}},
+	{".NET", "dotnet", []string{"--version"}},
+	{"Go", "go", []string{"version"}},
+	{"Git", "git", []string{"--version"}},
+	{"Docker", "docker", []string{"--version"}},
+	{"winget", "winget", []string{"--version"}},
+}
+
+// toolVersionRegex 从版本输出中提取第一个版本号
+var toolVersionRegex = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// getDevTools 获取常用运行时和包管理器的版本
+func getDevTools(ctx context.Context) ([]model.DevToolInfo, error) {
+	var tools []model.DevToolInfo
+	for _, probe := range devToolProbes {
+		path, err := exec.LookPath(probe.command)
+		if err != nil {
+			continue
+		}
+		// 未安装Python时WindowsApps下的python.exe是应用商店的占位别名，执行会打开商店
+		if probe.command == "python" && strings.Contains(strings.ToLower(path), `\microsoft\windowsapps\`) {
+			continue
+		}
+
+		output, err := exec.CommandContext(ctx, path, probe.args...).CombinedOutput()
+		if err != nil {
+			continue
+		}
+		tools = append(tools, model.DevToolInfo{
+			Name:    probe.name,
+			Version: toolVersionRegex.FindString(string(output)),
+			Path:    path,
+		})
+	}
+
+	// 以服务方式运行时PATH可能不包含JDK，补充检测JAVA_HOME
+	if javaHome := os.Getenv("JAVA_HOME"); javaHome != "" && !hasDevTool(tools, "Java") {
+		javaPath := filepath.Join(javaHome, "bin", "java.exe")
+		if output, err := exec.CommandContext(ctx, javaPath, "-version").CombinedOutput(); err == nil {
+			tools = append(tools, model.DevToolInfo{
+				Name:    "Java",
+				Version: toolVersionRegex.FindString(string(output)),
+				Path:    javaPath,
+			})
+		}
+	}
+
+	return tools, nil
+}
+
+// hasDevTool 判断是否已检测到指定名称的工具
+func hasDevTool(tools []model.DevToolInfo, name string) bool {
+	for _, tool := range tools {
+		if tool.Name == name {
+			return true
+		}
+	}
+	return false
+}
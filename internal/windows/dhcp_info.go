@@ -0,0 +1,118 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// win32DHCPAdapter 表示Win32_NetworkAdapterConfiguration中与DHCP相关的字段
+type win32DHCPAdapter struct {
+	Description          string
+	SettingID            string
+	InterfaceIndex       int
+	IPAddress            []string
+	DHCPServer           string
+	DHCPLeaseObtained    time.Time
+	DHCPLeaseExpires     time.Time
+	DefaultIPGateway     []string
+	DNSServerSearchOrder []string
+	DNSDomain            string
+}
+
+// getDHCPLeases 获取启用了DHCP的网卡的租约，选项43和60从注册表中DHCP客户端保存的原始选项读取
+func getDHCPLeases(ctx context.Context) ([]model.DHCPLeaseInfo, error) {
+	var adapters []win32DHCPAdapter
+	err := safeWMIQuery("SELECT Description, SettingID, InterfaceIndex, IPAddress, DHCPServer, DHCPLeaseObtained, DHCPLeaseExpires, "+
+		"DefaultIPGateway, DNSServerSearchOrder, DNSDomain FROM Win32_NetworkAdapterConfiguration WHERE IPEnabled = True AND DHCPEnabled = True", &adapters)
+	if err != nil {
+		return nil, err
+	}
+
+	var interfaces []struct {
+		Guid    string
+		Options []int
+	}
+	if err := runPowerShellJSON(ctx, `Get-ChildItem 'HKLM:\SYSTEM\CurrentControlSet\Services\Tcpip\Parameters\Interfaces' -ErrorAction SilentlyContinue | `+
+		`ForEach-Object { [pscustomobject]@{Guid = $_.PSChildName; Options = @((Get-ItemProperty $_.PSPath -ErrorAction SilentlyContinue).DhcpInterfaceOptions)} }`, &interfaces); err != nil {
+		log.Printf("Error reading DHCP options: %v", err)
+	}
+
+	var leases []model.DHCPLeaseInfo
+	for _, adapter := range adapters {
+		if adapter.DHCPServer == "" {
+			continue
+		}
+		lease := model.DHCPLeaseInfo{
+			Interface:  adapter.Description,
+			Server:     adapter.DHCPServer,
+			Routers:    adapter.DefaultIPGateway,
+			DNSServers: adapter.DNSServerSearchOrder,
+			Domain:     adapter.DNSDomain,
+		}
+		// Go在Windows上使用网卡的连接名称（如"以太网"）作为接口名
+		if iface, err := net.InterfaceByIndex(adapter.InterfaceIndex); err == nil {
+			lease.Interface = iface.Name
+		}
+		for _, address := range adapter.IPAddress {
+			if net.ParseIP(address).To4() != nil {
+				lease.IPAddress = address
+				break
+			}
+		}
+		if !adapter.DHCPLeaseObtained.IsZero() {
+			lease.LeaseObtained = adapter.DHCPLeaseObtained.Local().Format("2006-01-02 15:04:05")
+		}
+		if !adapter.DHCPLeaseExpires.IsZero() {
+			lease.LeaseExpires = adapter.DHCPLeaseExpires.Local().Format("2006-01-02 15:04:05")
+			lease.LeaseTime = int(adapter.DHCPLeaseExpires.Sub(adapter.DHCPLeaseObtained).Seconds())
+		}
+
+		for _, iface := range interfaces {
+			if !strings.EqualFold(iface.Guid, adapter.SettingID) {
+				continue
+			}
+			options := parseDhcpInterfaceOptions(toBytes(iface.Options))
+			if data := options[43]; len(data) > 0 {
+				lease.VendorSpecific = hex.EncodeToString(data)
+			}
+			if data := options[60]; len(data) > 0 {
+				lease.VendorClass = strings.TrimRight(string(data), "\x00")
+			}
+		}
+		leases = append(leases, lease)
+	}
+	return leases, nil
+}
+
+// parseDhcpInterfaceOptions 解析注册表DhcpInterfaceOptions中保存的DHCP选项（未公开的格式）：
+// 每个选项为4字节选项号、4字节厂商选项标志、4字节数据长度、8字节保留字段和数据，数据按4字节对齐，均为小端序
+func parseDhcpInterfaceOptions(data []byte) map[int][]byte {
+	options := make(map[int][]byte)
+	for len(data) >= 20 {
+		code := int(binary.LittleEndian.Uint32(data[0:4]))
+		vendor := binary.LittleEndian.Uint32(data[4:8])
+		length := int(binary.LittleEndian.Uint32(data[8:12]))
+		if length < 0 || len(data) < 20+length {
+			break
+		}
+		if vendor == 0 {
+			options[code] = data[20 : 20+length]
+		}
+		next := 20 + (length+3)/4*4
+		if next > len(data) {
+			break
+		}
+		data = data[next:]
+	}
+	return options
+}
@@ -0,0 +1,36 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/AsterZephyr/SysSpector/internal/diskscan"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// ScanDisk 分析用户目录中占用最大的目录以及常见的空间占用大户
+// 需要遍历整个用户目录，只在使用--scan-disk参数时调用
+func ScanDisk() (model.DiskScanInfo, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return model.DiskScanInfo{}, err
+	}
+
+	localAppData := os.Getenv("LOCALAPPDATA")
+	systemRoot := os.Getenv("SystemRoot")
+	systemDrive := os.Getenv("SystemDrive") + `\`
+	hogs := []diskscan.SpaceHog{
+		{Name: "临时文件", Path: os.TempDir(), Hint: "运行磁盘清理（cleanmgr）或在设置的存储中删除临时文件"},
+		{Name: "Windows更新缓存", Path: filepath.Join(systemRoot, "SoftwareDistribution", "Download"), Hint: "运行磁盘清理（cleanmgr）并勾选Windows更新清理"},
+		{Name: "Docker虚拟磁盘", Path: filepath.Join(localAppData, "Docker", "wsl", "*", "*.vhdx"), Hint: "执行docker system prune清理无用镜像，然后在Docker Desktop中回收磁盘空间"},
+		{Name: "WSL发行版磁盘", Path: filepath.Join(localAppData, "Packages", "*", "LocalState", "ext4.vhdx"), Hint: "在发行版中清理文件后使用Optimize-VHD压缩虚拟磁盘"},
+		{Name: "下载", Path: filepath.Join(homeDir, "Downloads"), Hint: "清理不再需要的下载文件"},
+		{Name: "OneDrive", Path: filepath.Join(homeDir, "OneDrive*"), Hint: "在OneDrive中将不常用的文件设为仅联机可用"},
+		{Name: "回收站", Path: filepath.Join(systemDrive, "$Recycle.Bin"), Hint: "清空回收站"},
+	}
+
+	return diskscan.Scan(homeDir, hogs), nil
+}
@@ -0,0 +1,66 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// getDNSResolvers 获取每个网卡的IPv4 DNS服务器，顺序为接口跃点数（跃点数小的接口优先解析），
+// 以及名称解析策略表（NRPT）中按域名指定的DNS服务器，VPN客户端通常通过NRPT只为内部域名推送DNS
+func getDNSResolvers(ctx context.Context) ([]model.DNSResolverInfo, error) {
+	var adapters []struct {
+		InterfaceAlias  string
+		ServerAddresses []string
+		Suffix          string
+		InterfaceMetric int
+	}
+	err := runPowerShellJSON(ctx, "Get-DnsClientServerAddress -AddressFamily IPv4 -ErrorAction SilentlyContinue | "+
+		"Where-Object { $_.ServerAddresses } | ForEach-Object { "+
+		"$client = Get-DnsClient -InterfaceIndex $_.InterfaceIndex -ErrorAction SilentlyContinue; "+
+		"$ip = Get-NetIPInterface -InterfaceIndex $_.InterfaceIndex -AddressFamily IPv4 -ErrorAction SilentlyContinue; "+
+		"[pscustomobject]@{ InterfaceAlias = $_.InterfaceAlias; ServerAddresses = @($_.ServerAddresses); "+
+		"Suffix = [string]$client.ConnectionSpecificSuffix; InterfaceMetric = [int]$ip.InterfaceMetric } }", &adapters)
+	if err != nil {
+		return nil, err
+	}
+
+	var resolvers []model.DNSResolverInfo
+	for _, adapter := range adapters {
+		resolver := model.DNSResolverInfo{
+			Interface: adapter.InterfaceAlias,
+			Scope:     analysis.DNSScopeDefault,
+			Servers:   adapter.ServerAddresses,
+			Order:     adapter.InterfaceMetric,
+		}
+		if adapter.Suffix != "" {
+			resolver.SearchDomains = []string{adapter.Suffix}
+		}
+		resolvers = append(resolvers, resolver)
+	}
+
+	// NRPT规则不绑定网卡，与VPN网卡使用相同DNS服务器的规则会被标记为VPN推送
+	var policies []struct {
+		Namespace   string
+		NameServers []string
+	}
+	if err := runPowerShellJSON(ctx, "Get-DnsClientNrptPolicy -ErrorAction SilentlyContinue | "+
+		"Select-Object @{n='Namespace';e={[string]$_.Namespace}}, @{n='NameServers';e={@($_.NameServers)}}", &policies); err != nil {
+		return resolvers, err
+	}
+	for _, policy := range policies {
+		if len(policy.NameServers) == 0 {
+			continue
+		}
+		resolvers = append(resolvers, model.DNSResolverInfo{
+			Interface: "NRPT",
+			Scope:     analysis.DNSScopeDomain,
+			Domain:    policy.Namespace,
+			Servers:   policy.NameServers,
+		})
+	}
+	return resolvers, nil
+}
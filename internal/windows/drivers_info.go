@@ -0,0 +1,60 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// win32PnPSignedDriver 表示Win32_PnPSignedDriver中的驱动信息（driverquery /si的数据来源）
+type win32PnPSignedDriver struct {
+	DeviceName         string
+	DriverVersion      string
+	DriverProviderName string
+	InfName            string
+	IsSigned           bool
+	Signer             string
+}
+
+// getDrivers 获取第三方驱动程序及其签名状态
+func getDrivers() ([]model.DriverInfo, error) {
+	var drivers []win32PnPSignedDriver
+	err := safeWMIQuery("SELECT DeviceName, DriverVersion, DriverProviderName, InfName, IsSigned, Signer FROM Win32_PnPSignedDriver", &drivers)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []model.DriverInfo
+	seen := make(map[string]bool)
+	for _, d := range drivers {
+		// 跳过微软自带驱动，同一驱动包只记录一次
+		if d.InfName == "" || strings.EqualFold(d.DriverProviderName, "Microsoft") {
+			continue
+		}
+		key := strings.ToLower(d.InfName + "|" + d.DriverVersion)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		driver := model.DriverInfo{
+			Name:      d.DeviceName,
+			Kind:      "驱动程序",
+			Version:   d.DriverVersion,
+			Provider:  d.DriverProviderName,
+			Signature: "未签名",
+		}
+		if d.IsSigned {
+			driver.Signature = "已签名"
+			if d.Signer != "" {
+				driver.Signature += "（" + d.Signer + "）"
+			}
+		}
+		result = append(result, driver)
+	}
+
+	return result, nil
+}
@@ -4,14 +4,16 @@
 package windows
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"os/exec"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/internal/collect"
 	"github.com/AsterZephyr/SysSpector/pkg/model"
 	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/disk"
@@ -29,6 +31,16 @@ type win32Battery struct {
 	Name                string
 }
 
+// batteryStaticData 表示root\wmi命名空间中的电池静态数据
+type batteryStaticData struct {
+	DesignedCapacity uint32
+}
+
+// batteryFullChargedCapacity 表示root\wmi命名空间中的电池满充容量
+type batteryFullChargedCapacity struct {
+	FullChargedCapacity uint32
+}
+
 type win32ACAdapter struct {
 	DeviceID     string
 	Name         string
@@ -43,114 +55,154 @@ type win32TemperatureSensor struct {
 	Location    string
 }
 
-// GetDynamicInfo 获取Windows系统的动态信息
+// GetDynamicInfo 并发获取Windows系统的动态信息
 func GetDynamicInfo() (model.SystemInfo, error) {
 	var info model.SystemInfo
-	var err error
+	collect.Run(&info, dynamicTasks())
+	return info, nil
+}
 
-	// 获取磁盘使用情况
-	partitions, err := disk.Partitions(false)
-	if err != nil {
-		log.Printf("Error getting disk partitions: %v", err)
-	} else {
-		for _, p := range partitions {
-			usage, err := disk.Usage(p.Mountpoint)
-			if err != nil {
-				continue
-			}
-			
-			info.DiskUsage = append(info.DiskUsage, model.DiskPartitionInfo{
-				MountPoint: p.Mountpoint,
-				Total:      usage.Total,
-				Used:       usage.Used,
-				Free:       usage.Free,
-				UsedPerc:   usage.UsedPercent,
-				Filesystem: p.Fstype,
-			})
-		}
+// dynamicTasks 返回动态信息的采集项
+func dynamicTasks() []collect.Task[model.SystemInfo] {
+	return []collect.Task[model.SystemInfo]{
+		{Name: "disk_usage", Run: getDiskUsage},
+		// 外接和可移动存储信息
+		{Name: "external_storage", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.ExternalStorage, err = getExternalStorage(ctx)
+			return err
+		}},
+		{Name: "memory_usage", Run: getMemoryUsage},
+		{Name: "battery", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.Battery, err = getBatteryInfo(ctx)
+			return err
+		}},
+		// 显示器亮度信息
+		{Name: "display", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.Display, err = getDisplayInfo(ctx)
+			return err
+		}},
+		// 交流充电器信息
+		{Name: "ac_adapter", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.ACAdapter, err = getACAdapterInfo(ctx)
+			return err
+		}},
+		{Name: "bluetooth", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.Bluetooth, err = getBluetoothInfo(ctx)
+			return err
+		}},
+		{Name: "input_devices", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.InputDevices, err = getInputDevices(ctx)
+			return err
+		}},
+		{Name: "temperature", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.Temperature, err = getTemperatureInfo(ctx)
+			return err
+		}},
+		// 已安装应用和浏览器信息
+		{Name: "apps", Timeout: slowTaskTimeout, Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.InstalledApps, err = getInstalledApps(ctx)
+			return err
+		}},
+		{Name: "browsers", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.Browsers, err = getBrowsers(ctx)
+			return err
+		}},
+		// Windows服务和正在运行的应用，防病毒和EDR产品、Windows Search索引状态依赖服务和进程信息
+		{Name: "services", Run: collect.Steps(
+			func(ctx context.Context, info *model.SystemInfo) (err error) {
+				info.Services, err = getServices()
+				return err
+			},
+			func(ctx context.Context, info *model.SystemInfo) (err error) {
+				info.Antivirus, err = getEndpointProtection(ctx, info.Services)
+				return err
+			},
+			func(ctx context.Context, info *model.SystemInfo) (err error) {
+				info.RunningApps, err = getRunningApps()
+				return err
+			},
+			func(ctx context.Context, info *model.SystemInfo) (err error) {
+				info.SearchIndex, err = getSearchIndex(info.Services, info.RunningApps)
+				return err
+			},
+		)},
+		{Name: "startup_items", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.StartupItems, err = getStartupItems(ctx)
+			return err
+		}},
+		{Name: "scheduled_tasks", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.ScheduledTasks, err = getScheduledTasks(ctx)
+			return err
+		}},
+		{Name: "uptime", Run: getUptime},
 	}
+}
 
-	// 获取内存使用情况
-	memStats, err := mem.VirtualMemory()
+// getDiskUsage 获取磁盘使用情况
+func getDiskUsage(ctx context.Context, info *model.SystemInfo) error {
+	partitions, err := disk.Partitions(false)
 	if err != nil {
-		log.Printf("Error getting memory stats: %v", err)
-	} else {
-		info.MemoryUsage = model.MemoryUsageInfo{
-			Total:    memStats.Total,
-			Used:     memStats.Used,
-			Free:     memStats.Free,
-			UsedPerc: memStats.UsedPercent,
-			Active:   memStats.Active,
-			Inactive: memStats.Inactive,
-			Cached:   memStats.Cached,
-		}
+		return err
 	}
+	for _, p := range partitions {
+		usage, err := disk.Usage(p.Mountpoint)
+		if err != nil {
+			continue
+		}
 
-	// 获取电池信息
-	batteryInfo, err := getBatteryInfo()
-	if err != nil {
-		log.Printf("Error getting battery info: %v", err)
-	} else {
-		info.Battery = batteryInfo
+		info.DiskUsage = append(info.DiskUsage, model.DiskPartitionInfo{
+			MountPoint: p.Mountpoint,
+			Total:      usage.Total,
+			Used:       usage.Used,
+			Free:       usage.Free,
+			UsedPerc:   usage.UsedPercent,
+			Filesystem: p.Fstype,
+		})
 	}
+	return nil
+}
 
-	// 获取交流充电器信息
-	adapterInfo, err := getACAdapterInfo()
+// getMemoryUsage 获取内存使用情况
+func getMemoryUsage(ctx context.Context, info *model.SystemInfo) error {
+	memStats, err := mem.VirtualMemory()
 	if err != nil {
-		log.Printf("Error getting AC adapter info: %v", err)
-	} else {
-		info.ACAdapter = adapterInfo
+		return err
 	}
-
-	// 获取蓝牙信息
-	bluetoothInfo, err := getBluetoothInfo()
-	if err != nil {
-		log.Printf("Error getting bluetooth info: %v", err)
-	} else {
-		info.Bluetooth = bluetoothInfo
+	info.MemoryUsage = model.MemoryUsageInfo{
+		Total:    memStats.Total,
+		Used:     memStats.Used,
+		Free:     memStats.Free,
+		UsedPerc: memStats.UsedPercent,
+		Active:   memStats.Active,
+		Inactive: memStats.Inactive,
+		Cached:   memStats.Cached,
 	}
+	return nil
+}
 
-	// 获取温度信息
-	tempInfo, err := getTemperatureInfo()
+// getUptime 获取系统启动时间并格式化运行时间
+func getUptime(ctx context.Context, info *model.SystemInfo) error {
+	bootTime, err := host.BootTime()
 	if err != nil {
-		log.Printf("Error getting temperature info: %v", err)
-	} else {
-		info.Temperature = tempInfo
+		return err
 	}
+	uptime := time.Since(time.Unix(int64(bootTime), 0))
 
-	// 获取已安装应用
-	if installedApps, err := getInstalledApps(); err == nil {
-		info.InstalledApps = installedApps
-	}
+	// 格式化启动时间
+	days := int(uptime.Hours()) / 24
+	hours := int(uptime.Hours()) % 24
+	minutes := int(uptime.Minutes()) % 60
 
-	// 获取正在运行的应用
-	if runningApps, err := getRunningApps(); err == nil {
-		info.RunningApps = runningApps
-	}
-
-	// 获取系统启动时间
-	bootTime, err := host.BootTime()
-	if err == nil {
-		bootTimeT := time.Unix(int64(bootTime), 0)
-		uptime := time.Since(bootTimeT)
-		
-		// 格式化启动时间
-		days := int(uptime.Hours()) / 24
-		hours := int(uptime.Hours()) % 24
-		minutes := int(uptime.Minutes()) % 60
-		
-		if days > 0 {
-			info.UpTime = fmt.Sprintf("%d天%d小时%d分钟", days, hours, minutes)
-		} else {
-			info.UpTime = fmt.Sprintf("%d小时%d分钟", hours, minutes)
-		}
+	if days > 0 {
+		info.UpTime = fmt.Sprintf("%d天%d小时%d分钟", days, hours, minutes)
+	} else {
+		info.UpTime = fmt.Sprintf("%d小时%d分钟", hours, minutes)
 	}
-
-	return info, nil
+	return nil
 }
 
 // getBatteryInfo 获取电池信息
-func getBatteryInfo() (model.BatteryInfo, error) {
+func getBatteryInfo(ctx context.Context) (model.BatteryInfo, error) {
 	var batteryInfo model.BatteryInfo
 	
 	// 通过WMI查询电池信息
@@ -159,7 +211,7 @@ func getBatteryInfo() (model.BatteryInfo, error) {
 	
 	if err != nil || len(batteries) == 0 {
 		// 尝试使用PowerShell命令获取电池信息
-		cmd := exec.Command("powershell", "-Command", "Get-WmiObject -Class Win32_Battery | Select-Object BatteryStatus, EstimatedChargeRemaining, Name")
+		cmd := exec.CommandContext(ctx, "powershell", "-Command", "Get-WmiObject -Class Win32_Battery | Select-Object BatteryStatus, EstimatedChargeRemaining, Name")
 		output, err := cmd.Output()
 		if err != nil {
 			return batteryInfo, fmt.Errorf("error getting battery info: %v", err)
@@ -219,12 +271,39 @@ func getBatteryInfo() (model.BatteryInfo, error) {
 	
 	// 获取电池循环计数（Windows没有直接提供此信息，使用0作为默认值）
 	batteryInfo.CycleCount = 0
-	
+
+	// 从root\wmi获取设计容量和满充容量（mWh），计算电池健康度
+	getBatteryCapacity(&batteryInfo)
+	analysis.EvaluateBatteryHealth(&batteryInfo)
+	if batteryInfo.HealthVerdict != "" {
+		batteryInfo.Health = batteryInfo.HealthVerdict
+	}
+
+	// SystemStatusFlag为1表示节电模式已开启
+	if status, err := getSystemPowerStatus(); err == nil {
+		batteryInfo.LowPowerMode = status.SystemStatusFlag == 1
+	}
+
 	return batteryInfo, nil
 }
 
+// getBatteryCapacity 获取电池的设计容量和满充容量
+func getBatteryCapacity(batteryInfo *model.BatteryInfo) {
+	var staticData []batteryStaticData
+	err := safeWMIQueryNamespace("SELECT DesignedCapacity FROM BatteryStaticData", &staticData, `root\wmi`)
+	if err == nil && len(staticData) > 0 {
+		batteryInfo.DesignCapacity = int(staticData[0].DesignedCapacity)
+	}
+
+	var fullCharged []batteryFullChargedCapacity
+	err = safeWMIQueryNamespace("SELECT FullChargedCapacity FROM BatteryFullChargedCapacity", &fullCharged, `root\wmi`)
+	if err == nil && len(fullCharged) > 0 {
+		batteryInfo.FullChargeCapacity = int(fullCharged[0].FullChargedCapacity)
+	}
+}
+
 // getACAdapterInfo 获取交流充电器信息
-func getACAdapterInfo() (model.ACAdapterInfo, error) {
+func getACAdapterInfo(ctx context.Context) (model.ACAdapterInfo, error) {
 	var adapterInfo model.ACAdapterInfo
 	
 	// 通过WMI查询交流充电器信息
@@ -241,7 +320,7 @@ func getACAdapterInfo() (model.ACAdapterInfo, error) {
 		adapterInfo.IsConnected = (batteries[0].BatteryStatus == 2)
 	} else {
 		// 如果无法获取电池状态，尝试使用PowerShell命令
-		cmd := exec.Command("powershell", "-Command", "Get-WmiObject -Class Win32_Battery | Select-Object BatteryStatus")
+		cmd := exec.CommandContext(ctx, "powershell", "-Command", "Get-WmiObject -Class Win32_Battery | Select-Object BatteryStatus")
 		output, err := cmd.Output()
 		if err == nil {
 			outputStr := string(output)
@@ -255,6 +334,11 @@ func getACAdapterInfo() (model.ACAdapterInfo, error) {
 		}
 	}
 	
+	// 从root\wmi的BatteryStatus读取电池的充电速率
+	if adapterInfo.Connected {
+		getBatteryChargeRate(&adapterInfo)
+	}
+
 	if err != nil || len(adapters) == 0 {
 		// 如果WMI查询失败，尝试使用PowerShell命令
 		if adapterInfo.Connected {
@@ -279,12 +363,35 @@ func getACAdapterInfo() (model.ACAdapterInfo, error) {
 	return adapterInfo, nil
 }
 
+// batteryStatus 表示root\wmi命名空间中的电池实时状态
+type batteryStatus struct {
+	ChargeRate  int32
+	PowerOnline bool
+}
+
+// getBatteryChargeRate 获取电池的充电速率（mW）
+// Windows没有公开USB-C PD协商结果，协商参数保持为空；充电速率只是流入电池的功率，
+// 不包括系统消耗，电池充满时为0，不能用来判断充电器功率不足
+func getBatteryChargeRate(adapterInfo *model.ACAdapterInfo) {
+	var statuses []batteryStatus
+	err := safeWMIQueryNamespace("SELECT ChargeRate, PowerOnline FROM BatteryStatus", &statuses, `root\wmi`)
+	if err != nil || len(statuses) == 0 {
+		return
+	}
+
+	status := statuses[0]
+	if !status.PowerOnline || status.ChargeRate <= 0 {
+		return
+	}
+	adapterInfo.BatteryChargeRate = float64(status.ChargeRate) / 1000
+}
+
 // getBluetoothInfo 获取蓝牙信息
-func getBluetoothInfo() (model.BluetoothInfo, error) {
+func getBluetoothInfo(ctx context.Context) (model.BluetoothInfo, error) {
 	var bluetoothInfo model.BluetoothInfo
 	
 	// 使用PowerShell命令获取蓝牙信息
-	cmd := exec.Command("powershell", "-Command", "Get-PnpDevice | Where-Object {$_.Class -eq 'Bluetooth'}")
+	cmd := exec.CommandContext(ctx, "powershell", "-Command", "Get-PnpDevice | Where-Object {$_.Class -eq 'Bluetooth'}")
 	output, err := cmd.Output()
 	if err != nil {
 		return bluetoothInfo, fmt.Errorf("error getting bluetooth info: %v", err)
@@ -307,7 +414,7 @@ func getBluetoothInfo() (model.BluetoothInfo, error) {
 		}
 		
 		// 获取已连接的蓝牙设备
-		deviceCmd := exec.Command("powershell", "-Command", "Get-PnpDevice | Where-Object {$_.Class -eq 'Bluetooth' -and $_.Status -eq 'OK'}")
+		deviceCmd := exec.CommandContext(ctx, "powershell", "-Command", "Get-PnpDevice | Where-Object {$_.Class -eq 'Bluetooth' -and $_.Status -eq 'OK'}")
 		deviceOutput, err := deviceCmd.Output()
 		if err == nil {
 			deviceOutputStr := string(deviceOutput)
@@ -331,13 +438,13 @@ func getBluetoothInfo() (model.BluetoothInfo, error) {
 }
 
 // getTemperatureInfo 获取温度信息
-func getTemperatureInfo() ([]model.TempSensorInfo, error) {
+func getTemperatureInfo(ctx context.Context) ([]model.TempSensorInfo, error) {
 	var tempInfo []model.TempSensorInfo
 	
 	// 尝试使用OpenHardwareMonitor获取温度信息
 	// 注意：这需要用户安装OpenHardwareMonitor
 	ohwmPath := "C:\\Program Files\\OpenHardwareMonitor\\OpenHardwareMonitor.exe"
-	cmd := exec.Command(ohwmPath, "/report")
+	cmd := exec.CommandContext(ctx, ohwmPath, "/report")
 	output, err := cmd.Output()
 	
 	if err == nil {
@@ -439,11 +546,11 @@ func getTemperatureInfo() ([]model.TempSensorInfo, error) {
 }
 
 // getInstalledApps 获取已安装应用
-func getInstalledApps() ([]model.AppInfo, error) {
+func getInstalledApps(ctx context.Context) ([]model.AppInfo, error) {
 	var apps []model.AppInfo
 	
 	// 使用PowerShell命令获取已安装应用
-	cmd := exec.Command("powershell", "-Command", "Get-ItemProperty HKLM:\\Software\\Microsoft\\Windows\\CurrentVersion\\Uninstall\\* | Select-Object DisplayName, DisplayVersion, InstallDate | Where-Object {$_.DisplayName -ne $null}")
+	cmd := exec.CommandContext(ctx, "powershell", "-Command", "Get-ItemProperty HKLM:\\Software\\Microsoft\\Windows\\CurrentVersion\\Uninstall\\* | Select-Object DisplayName, DisplayVersion, InstallDate | Where-Object {$_.DisplayName -ne $null}")
 	output, err := cmd.Output()
 	if err != nil {
 		return apps, fmt.Errorf("error getting installed apps: %v", err)
@@ -488,6 +595,12 @@ func getRunningApps() ([]model.ProcessInfo, error) {
 		return procs, fmt.Errorf("error getting running processes: %v", err)
 	}
 	
+	// 先记录一次CPU时间，等待采样窗口后计算这段时间内的CPU使用率
+	for _, p := range processes {
+		p.Percent(0)
+	}
+	time.Sleep(analysis.ProcessSampleWindow)
+	
 	for _, p := range processes {
 		name, err := p.Name()
 		if err != nil {
@@ -496,7 +609,7 @@ func getRunningApps() ([]model.ProcessInfo, error) {
 		
 		pid := int(p.Pid)
 		
-		cpuPercent, _ := p.CPUPercent()
+		cpuPercent, _ := p.Percent(0)
 		
 		memInfo, err := p.MemoryInfo()
 		var memUsage uint64
@@ -0,0 +1,148 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// eventLogWindow 事件统计的时间范围
+const eventLogWindow = 24 * time.Hour
+
+// eventLogRecentCount 报告的最近事件数量
+const eventLogRecentCount = 10
+
+// winEvent 表示Get-WinEvent返回的事件
+type winEvent struct {
+	LogName      string
+	Level        int
+	ProviderName string
+	Id           int
+	TimeCreated  string
+	Message      string
+}
+
+// eventLogScript 获取系统和应用程序日志中最近24小时的严重（Level 1）和错误（Level 2）事件
+// 每个日志最多读取500条，消息只保留第一行
+const eventLogScript = `$start = (Get-Date).AddHours(-24)
+foreach ($log in 'System', 'Application') {
+	Get-WinEvent -FilterHashtable @{ LogName = $log; Level = 1, 2; StartTime = $start } -MaxEvents 500 -ErrorAction SilentlyContinue | ForEach-Object {
+		[PSCustomObject]@{
+			LogName = $_.LogName
+			Level = [int]$_.Level
+			ProviderName = $_.ProviderName
+			Id = $_.Id
+			TimeCreated = $_.TimeCreated.ToString('yyyy-MM-dd HH:mm:ss')
+			Message = if ($_.Message) { ($_.Message -split "\r?\n")[0] } else { '' }
+		}
+	}
+}`
+
+// eventCategory 表示可以归类的常见问题事件
+type eventCategory struct {
+	provider string
+	ids      []int // 为空时匹配该来源的所有事件
+	category string
+}
+
+// eventCategories 常见问题事件的来源和事件ID
+var eventCategories = []eventCategory{
+	{"disk", nil, "磁盘错误"},
+	{"Ntfs", nil, "磁盘错误"},
+	{"Microsoft-Windows-Ntfs", nil, "磁盘错误"},
+	{"volmgr", nil, "磁盘错误"},
+	{"storahci", nil, "磁盘错误"},
+	{"stornvme", nil, "磁盘错误"},
+	{"Service Control Manager", []int{7031, 7034}, "服务崩溃"},
+	{"Microsoft-Windows-Kernel-Power", []int{41}, "意外关机"},
+	{"EventLog", []int{6008}, "意外关机"},
+	{"Application Error", []int{1000}, "应用崩溃"},
+	{"Application Hang", []int{1002}, "应用无响应"},
+	{"Microsoft-Windows-WHEA-Logger", nil, "硬件错误"},
+}
+
+// getEventLogSummary 统计最近24小时的严重和错误事件
+func getEventLogSummary(ctx context.Context) (model.EventLogInfo, error) {
+	summary := model.EventLogInfo{
+		Since: time.Now().Add(-eventLogWindow).Format("2006-01-02 15:04:05"),
+	}
+
+	var events []winEvent
+	if err := runPowerShellJSON(ctx, eventLogScript, &events); err != nil {
+		return summary, err
+	}
+
+	summary.Logs = []model.EventCountInfo{{Log: "System"}, {Log: "Application"}}
+	counts := make(map[string]*model.EventCountInfo)
+	for i := range summary.Logs {
+		counts[summary.Logs[i].Log] = &summary.Logs[i]
+	}
+
+	var recent []model.EventEntryInfo
+	for _, event := range events {
+		count := counts[event.LogName]
+		level := "错误"
+		if event.Level == 1 {
+			level = "严重"
+			summary.Critical++
+			if count != nil {
+				count.Critical++
+			}
+		} else {
+			summary.Errors++
+			if count != nil {
+				count.Errors++
+			}
+		}
+
+		recent = append(recent, model.EventEntryInfo{
+			Time:     event.TimeCreated,
+			Log:      event.LogName,
+			Level:    level,
+			Source:   event.ProviderName,
+			EventID:  event.Id,
+			Category: categorizeEvent(event.ProviderName, event.Id),
+			Message:  strings.TrimSpace(event.Message),
+		})
+	}
+
+	// 两个日志的结果分别按时间从新到旧排列，合并后重新排序
+	sortEventsByTime(recent)
+	if len(recent) > eventLogRecentCount {
+		recent = recent[:eventLogRecentCount]
+	}
+	summary.Recent = recent
+
+	return summary, nil
+}
+
+// categorizeEvent 根据事件来源和ID判断问题分类
+func categorizeEvent(provider string, id int) string {
+	for _, c := range eventCategories {
+		if !strings.EqualFold(c.provider, provider) {
+			continue
+		}
+		if len(c.ids) == 0 {
+			return c.category
+		}
+		for _, categoryID := range c.ids {
+			if categoryID == id {
+				return c.category
+			}
+		}
+	}
+	return ""
+}
+
+// sortEventsByTime 按事件时间从新到旧排序，时间格式为yyyy-MM-dd HH:mm:ss，可直接按字符串比较
+func sortEventsByTime(events []model.EventEntryInfo) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time > events[j].Time
+	})
+}
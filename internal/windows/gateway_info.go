@@ -0,0 +1,30 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// getGatewayInfo 获取IPv4默认网关，并对比网关和互联网的延迟与丢包
+func getGatewayInfo(ctx context.Context) (model.GatewayInfo, error) {
+	gateway := model.GatewayInfo{}
+
+	var routes []struct {
+		NextHop        string
+		InterfaceAlias string
+		RouteMetric    int
+	}
+	err := runPowerShellJSON(ctx, "Get-NetRoute -AddressFamily IPv4 -DestinationPrefix '0.0.0.0/0' -ErrorAction SilentlyContinue | "+
+		"Sort-Object RouteMetric | Select-Object NextHop, InterfaceAlias, RouteMetric", &routes)
+	if err == nil && len(routes) > 0 {
+		gateway.Address = routes[0].NextHop
+		gateway.Interface = routes[0].InterfaceAlias
+	}
+
+	analysis.AssessGateway(&gateway)
+	return gateway, err
+}
@@ -0,0 +1,357 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// pnpDevice 表示Get-PnpDevice返回的设备
+type pnpDevice struct {
+	FriendlyName string
+	Class        string
+	Status       string
+	InstanceId   string
+	Manufacturer string
+}
+
+// getInputDevices 获取内置和外接输入设备信息
+func getInputDevices(ctx context.Context) (model.InputDevicesInfo, error) {
+	var inputDevices model.InputDevicesInfo
+
+	var devices []pnpDevice
+	err := runPowerShellJSON(ctx, "Get-PnpDevice -PresentOnly -Class Keyboard,Mouse,HIDClass | Select-Object FriendlyName, Class, Status, InstanceId, Manufacturer", &devices)
+	if err != nil {
+		return inputDevices, err
+	}
+
+	seen := map[string]bool{}
+	for _, d := range devices {
+		deviceType := pnpInputDeviceType(d)
+		if deviceType == "" {
+			continue
+		}
+
+		device := model.InputDeviceInfo{
+			Name:   d.FriendlyName,
+			Type:   deviceType,
+			Vendor: d.Manufacturer,
+			Status: d.Status,
+		}
+
+		// 根据实例ID推断连接方式
+		instanceID := strings.ToUpper(d.InstanceId)
+		switch {
+		case strings.HasPrefix(instanceID, "ACPI\\"):
+			device.Transport = "ACPI"
+			device.BuiltIn = true
+		case strings.Contains(instanceID, "BTH") || strings.Contains(instanceID, "{00001124") || strings.Contains(instanceID, "{00001812"):
+			device.Transport = "Bluetooth"
+		case strings.Contains(instanceID, "VID_"):
+			device.Transport = "USB"
+		default:
+			// 没有USB厂商ID的HID设备通常挂在I2C总线上（内置触控板/触摸屏）
+			device.Transport = "I2C"
+			device.BuiltIn = true
+		}
+
+		key := device.Name + "|" + device.Type + "|" + device.Transport
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if device.BuiltIn {
+			inputDevices.BuiltIn = append(inputDevices.BuiltIn, device)
+		} else {
+			inputDevices.External = append(inputDevices.External, device)
+		}
+	}
+
+	return inputDevices, nil
+}
+
+// pnpInputDeviceType 根据设备类别和名称确定输入设备类型
+func pnpInputDeviceType(d pnpDevice) string {
+	name := strings.ToLower(d.FriendlyName)
+	switch {
+	case strings.Contains(name, "touch screen"):
+		return "触摸屏"
+	case strings.Contains(name, "touchpad") || strings.Contains(name, "touch pad"):
+		return "触控板"
+	case d.Class == "Keyboard":
+		return "键盘"
+	case d.Class == "Mouse":
+		return "鼠标"
+	}
+	return ""
+}
+
+// win32Tpm 表示root\CIMV2\Security\MicrosoftTpm命名空间中的TPM信息
+type win32Tpm struct {
+	IsActivated_InitialValue bool
+	IsEnabled_InitialValue   bool
+	IsOwned_InitialValue     bool
+	ManufacturerIdTxt        string
+	ManufacturerVersion      string
+	SpecVersion              string
+}
+
+// getTPMInfo 获取TPM的存在状态、版本、制造商和就绪状态
+func getTPMInfo(ctx context.Context, securityInfo *model.SecurityHardwareInfo) error {
+	// Win32_Tpm需要管理员权限
+	var tpms []win32Tpm
+	err := safeWMIQueryNamespace("SELECT IsActivated_InitialValue, IsEnabled_InitialValue, IsOwned_InitialValue, ManufacturerIdTxt, ManufacturerVersion, SpecVersion FROM Win32_Tpm", &tpms, `root\CIMV2\Security\MicrosoftTpm`)
+	if err != nil {
+		// 查询失败时无法判断是否存在TPM，不能报告为未检测到
+		securityInfo.TPMUnknown = true
+		return err
+	}
+	if len(tpms) == 0 {
+		securityInfo.TPMPresent = false
+		return nil
+	}
+
+	tpm := tpms[0]
+	securityInfo.TPMPresent = true
+	securityInfo.TPMManufacturer = strings.TrimSpace(tpm.ManufacturerIdTxt)
+	if tpm.ManufacturerVersion != "" {
+		securityInfo.TPMManufacturer += " " + tpm.ManufacturerVersion
+	}
+
+	// SpecVersion形如"2.0, 0, 1.38"，第一段为规范版本
+	securityInfo.TPMVersion = strings.TrimSpace(strings.Split(tpm.SpecVersion, ",")[0])
+	securityInfo.TPMReady = tpm.IsEnabled_InitialValue && tpm.IsActivated_InitialValue && tpm.IsOwned_InitialValue
+
+	// Get-Tpm能更准确地反映就绪状态
+	output, err := runPowerShell(ctx, "(Get-Tpm).TpmReady")
+	if err == nil {
+		switch strings.TrimSpace(output) {
+		case "True":
+			securityInfo.TPMReady = true
+		case "False":
+			securityInfo.TPMReady = false
+		}
+	}
+
+	return nil
+}
+
+// win32BIOSFirmware 表示Win32_BIOS中的固件版本信息
+type win32BIOSFirmware struct {
+	Manufacturer      string
+	SMBIOSBIOSVersion string
+	ReleaseDate       time.Time
+}
+
+// getFirmwareInfo 获取BIOS/UEFI厂商、版本、发布日期和安全启动状态
+func getFirmwareInfo(ctx context.Context) (model.FirmwareInfo, error) {
+	var firmware model.FirmwareInfo
+
+	var bios []win32BIOSFirmware
+	err := safeWMIQuery("SELECT Manufacturer, SMBIOSBIOSVersion, ReleaseDate FROM Win32_BIOS", &bios)
+	if err != nil {
+		return firmware, err
+	}
+	if len(bios) > 0 {
+		firmware.Vendor = strings.TrimSpace(bios[0].Manufacturer)
+		firmware.Version = strings.TrimSpace(bios[0].SMBIOSBIOSVersion)
+		if !bios[0].ReleaseDate.IsZero() {
+			firmware.ReleaseDate = bios[0].ReleaseDate.Format("2006-01-02")
+		}
+	}
+
+	// 获取固件启动模式（UEFI/Legacy）
+	output, err := runPowerShell(ctx, "$env:firmware_type")
+	if err == nil {
+		firmware.Mode = strings.TrimSpace(output)
+	}
+
+	// 从注册表读取安全启动状态，无需管理员权限
+	cmd := exec.CommandContext(ctx, "reg", "query", `HKLM\SYSTEM\CurrentControlSet\Control\SecureBoot\State`, "/v", "UEFISecureBootEnabled")
+	regOutput, err := cmd.Output()
+	switch {
+	case err != nil:
+		firmware.SecureBoot = "不支持"
+	case strings.Contains(string(regOutput), "0x1"):
+		firmware.SecureBoot = "已启用"
+	default:
+		firmware.SecureBoot = "未启用"
+	}
+
+	return firmware, nil
+}
+
+// getDiskEncryption 获取系统盘的BitLocker状态以及是否使用硬件加密（eDrive/OPAL）
+func getDiskEncryption(ctx context.Context) (model.DiskEncryptionInfo, error) {
+	encryption := model.DiskEncryptionInfo{SoftwareName: "BitLocker"}
+
+	systemDrive := os.Getenv("SystemDrive")
+	if systemDrive == "" {
+		systemDrive = "C:"
+	}
+
+	// manage-bde需要管理员权限
+	cmd := exec.CommandContext(ctx, "manage-bde", "-status", systemDrive)
+	output, err := cmd.Output()
+	if err != nil {
+		// 查询失败时无法判断是否加密，不能报告为未加密
+		encryption.Unknown = true
+		return encryption, fmt.Errorf("error running manage-bde: %v", err)
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+
+		switch key {
+		case "Conversion Status":
+			encryption.SoftwareStatus = value
+		case "Protection Status":
+			encryption.SoftwareEnabled = strings.Contains(value, "Protection On")
+		case "Encryption Method":
+			// 使用eDrive（OPAL）时，加密方法显示为"Hardware Encryption"
+			if strings.Contains(value, "Hardware") {
+				encryption.HardwareEncrypted = true
+				encryption.HardwareMethod = "OPAL/eDrive"
+			}
+		}
+	}
+
+	return encryption, nil
+}
+
+// pciPnpDevice 表示PCI总线上的即插即用设备及其链路属性
+type pciPnpDevice struct {
+	Name         string
+	Class        string
+	Manufacturer string
+	InstanceId   string
+	Status       string
+	LinkWidth    int
+	LinkSpeed    int
+	Location     string
+}
+
+// pciLinkSpeeds 将DEVPKEY_PciDevice_CurrentLinkSpeed代码转换为传输速率
+var pciLinkSpeeds = map[int]string{
+	1: "2.5 GT/s",
+	2: "5.0 GT/s",
+	3: "8.0 GT/s",
+	4: "16.0 GT/s",
+	5: "32.0 GT/s",
+}
+
+// getPCIDevices 通过SetupAPI设备属性获取PCIe设备及其链路宽度和速度
+func getPCIDevices(ctx context.Context) ([]model.PCIDeviceInfo, error) {
+	script := `Get-PnpDevice -PresentOnly | Where-Object { $_.InstanceId -like 'PCI\*' -and $_.Class -ne 'System' } | ForEach-Object {
+		$props = $_ | Get-PnpDeviceProperty -KeyName DEVPKEY_PciDevice_CurrentLinkWidth,DEVPKEY_PciDevice_CurrentLinkSpeed,DEVPKEY_Device_LocationInfo -ErrorAction SilentlyContinue
+		[PSCustomObject]@{
+			Name = $_.FriendlyName; Class = $_.Class; Manufacturer = $_.Manufacturer; InstanceId = $_.InstanceId; Status = $_.Status
+			LinkWidth = ($props | Where-Object KeyName -eq 'DEVPKEY_PciDevice_CurrentLinkWidth').Data
+			LinkSpeed = ($props | Where-Object KeyName -eq 'DEVPKEY_PciDevice_CurrentLinkSpeed').Data
+			Location = ($props | Where-Object KeyName -eq 'DEVPKEY_Device_LocationInfo').Data
+		}
+	}`
+
+	var pnpDevices []pciPnpDevice
+	if err := runPowerShellJSON(ctx, script, &pnpDevices); err != nil {
+		return nil, err
+	}
+
+	idRegex := regexp.MustCompile(`VEN_([0-9A-Fa-f]{4})&DEV_([0-9A-Fa-f]{4})`)
+
+	var devices []model.PCIDeviceInfo
+	for _, d := range pnpDevices {
+		device := model.PCIDeviceInfo{
+			Name:      d.Name,
+			Type:      d.Class,
+			Vendor:    d.Manufacturer,
+			Slot:      d.Location,
+			LinkSpeed: pciLinkSpeeds[d.LinkSpeed],
+			Driver:    d.Status == "OK",
+		}
+		if matches := idRegex.FindStringSubmatch(d.InstanceId); len(matches) > 2 {
+			device.VendorID = "0x" + strings.ToLower(matches[1])
+			device.DeviceID = "0x" + strings.ToLower(matches[2])
+		}
+		if d.LinkWidth > 0 {
+			device.LinkWidth = fmt.Sprintf("x%d", d.LinkWidth)
+		}
+		devices = append(devices, device)
+	}
+
+	return devices, nil
+}
+
+// wmiMonitorBrightness 表示root\wmi命名空间中的显示器亮度
+type wmiMonitorBrightness struct {
+	CurrentBrightness uint8
+}
+
+// getDisplayInfo 获取内置显示器亮度和自适应亮度设置
+func getDisplayInfo(ctx context.Context) (model.DisplayInfo, error) {
+	var display model.DisplayInfo
+
+	// 外接显示器通常不支持WmiMonitorBrightness，只有内置显示器会返回结果
+	var brightness []wmiMonitorBrightness
+	err := safeWMIQueryNamespace("SELECT CurrentBrightness FROM WmiMonitorBrightness", &brightness, `root\wmi`)
+	if err == nil && len(brightness) > 0 {
+		display.Brightness = float64(brightness[0].CurrentBrightness)
+	}
+
+	// 读取当前电源计划的自适应亮度设置
+	cmd := exec.CommandContext(ctx, "powercfg", "/q", "SCHEME_CURRENT", "SUB_VIDEO", "ADAPTBRIGHT")
+	output, err := cmd.Output()
+	if err != nil {
+		return display, nil
+	}
+
+	// 根据当前供电方式选择交流或直流设置
+	key := "Current AC Power Setting Index"
+	if status, err := getSystemPowerStatus(); err == nil && status.ACLineStatus == 0 {
+		key = "Current DC Power Setting Index"
+	}
+	settingRegex := regexp.MustCompile(key + `:\s+0x([0-9a-fA-F]+)`)
+	if matches := settingRegex.FindStringSubmatch(string(output)); len(matches) > 1 {
+		display.AutoBrightness = strings.TrimLeft(matches[1], "0") != ""
+	}
+
+	return display, nil
+}
+
+// systemPowerStatus 对应Win32 API的SYSTEM_POWER_STATUS结构体
+type systemPowerStatus struct {
+	ACLineStatus        byte
+	BatteryFlag         byte
+	BatteryLifePercent  byte
+	SystemStatusFlag    byte
+	BatteryLifeTime     uint32
+	BatteryFullLifeTime uint32
+}
+
+// getSystemPowerStatus 调用GetSystemPowerStatus获取供电和节电模式状态
+func getSystemPowerStatus() (systemPowerStatus, error) {
+	var status systemPowerStatus
+	proc := syscall.NewLazyDLL("kernel32.dll").NewProc("GetSystemPowerStatus")
+	ret, _, err := proc.Call(uintptr(unsafe.Pointer(&status)))
+	if ret == 0 {
+		return status, fmt.Errorf("GetSystemPowerStatus failed: %v", err)
+	}
+	return status, nil
+}
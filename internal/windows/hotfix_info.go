@@ -0,0 +1,49 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"sort"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// win32QuickFixEngineering 表示Win32_QuickFixEngineering中的补丁信息（Get-HotFix的数据来源）
+type win32QuickFixEngineering struct {
+	HotFixID    string
+	Description string
+	InstalledOn string
+	InstalledBy string
+}
+
+// getHotfixes 获取已安装的补丁，按安装日期从新到旧排序
+func getHotfixes() ([]model.HotfixInfo, error) {
+	var fixes []win32QuickFixEngineering
+	err := safeWMIQuery("SELECT HotFixID, Description, InstalledOn, InstalledBy FROM Win32_QuickFixEngineering", &fixes)
+	if err != nil {
+		return nil, err
+	}
+
+	var hotfixes []model.HotfixInfo
+	for _, fix := range fixes {
+		hotfix := model.HotfixInfo{
+			ID:          fix.HotFixID,
+			Description: fix.Description,
+			InstalledOn: fix.InstalledOn,
+			InstalledBy: fix.InstalledBy,
+		}
+		// WMI返回的日期格式为M/D/YYYY，统一转换为YYYY-MM-DD便于排序
+		if installedOn, err := time.Parse("1/2/2006", fix.InstalledOn); err == nil {
+			hotfix.InstalledOn = installedOn.Format("2006-01-02")
+		}
+		hotfixes = append(hotfixes, hotfix)
+	}
+
+	sort.SliceStable(hotfixes, func(i, j int) bool {
+		return hotfixes[i].InstalledOn > hotfixes[j].InstalledOn
+	})
+
+	return hotfixes, nil
+}
@@ -0,0 +1,42 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// exportCertificateScript 在本地计算机和当前用户的个人证书存储中查找主题包含指定名称、带有私钥且未过期的证书，
+// 取有效期最晚的一个导出为PKCS#12文件；使用TripleDES_SHA1加密以便解析，私钥需要标记为可导出
+const exportCertificateScript = `$cert = Get-ChildItem Cert:\LocalMachine\My, Cert:\CurrentUser\My -ErrorAction SilentlyContinue |
+	Where-Object { $_.HasPrivateKey -and $_.NotAfter -gt (Get-Date) -and $_.Subject -like '*%s*' } |
+	Sort-Object NotAfter -Descending | Select-Object -First 1
+if (-not $cert) { throw 'certificate not found' }
+$password = ConvertTo-SecureString -String '%s' -Force -AsPlainText
+Export-PfxCertificate -Cert $cert -FilePath '%s' -Password $password -CryptoAlgorithmOption TripleDES_SHA1 | Out-Null`
+
+// ExportCertificate 从Windows证书存储导出主题包含name的证书和私钥，返回以password加密的PKCS#12数据
+func ExportCertificate(name, password string) ([]byte, error) {
+	file, err := os.CreateTemp("", "sysspector-*.pfx")
+	if err != nil {
+		return nil, err
+	}
+	path := file.Name()
+	file.Close()
+	defer os.Remove(path)
+
+	script := fmt.Sprintf(exportCertificateScript, quotePowerShell(name), quotePowerShell(password), quotePowerShell(path))
+	if _, err := runPowerShell(context.Background(), script); err != nil {
+		return nil, fmt.Errorf("export certificate %q: %v", name, err)
+	}
+	return os.ReadFile(path)
+}
+
+// quotePowerShell 转义PowerShell单引号字符串中的单引号
+func quotePowerShell(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
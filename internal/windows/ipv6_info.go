@@ -0,0 +1,31 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// getIPv6Info 获取IPv6默认路由，并评估IPv6地址和出口连通性
+func getIPv6Info(ctx context.Context) (model.IPv6Info, error) {
+	ipv6 := model.IPv6Info{}
+
+	var routes []struct {
+		NextHop        string
+		InterfaceAlias string
+		RouteMetric    int
+	}
+	err := runPowerShellJSON(ctx, "Get-NetRoute -AddressFamily IPv6 -DestinationPrefix '::/0' -ErrorAction SilentlyContinue | "+
+		"Sort-Object RouteMetric | Select-Object NextHop, InterfaceAlias, RouteMetric", &routes)
+	if err == nil && len(routes) > 0 {
+		ipv6.DefaultRoute = true
+		ipv6.Gateway = routes[0].NextHop
+		ipv6.Interface = routes[0].InterfaceAlias
+	}
+
+	analysis.AssessIPv6(&ipv6)
+	return ipv6, err
+}
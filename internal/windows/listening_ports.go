@@ -0,0 +1,69 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/internal/sockets"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+var certCommonNameRegex = regexp.MustCompile(`CN=("[^"]+"|[^,]+)`)
+
+// getListeningPorts 获取监听中的端口及其所属进程，并读取可执行文件的Authenticode签名
+func getListeningPorts(ctx context.Context) ([]model.ListeningPortInfo, error) {
+	ports, err := sockets.Listening()
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	seen := make(map[string]bool)
+	for _, port := range ports {
+		if port.Path != "" && !seen[port.Path] {
+			seen[port.Path] = true
+			paths = append(paths, "'"+strings.ReplaceAll(port.Path, "'", "''")+"'")
+		}
+	}
+	if len(paths) == 0 {
+		return ports, nil
+	}
+
+	// 一次PowerShell调用检查所有程序的签名
+	var signatures []struct {
+		Path   string
+		Status string
+		Signer string
+	}
+	script := fmt.Sprintf("Get-AuthenticodeSignature -LiteralPath %s -ErrorAction SilentlyContinue | "+
+		"Select-Object Path, @{n='Status';e={[string]$_.Status}}, @{n='Signer';e={$_.SignerCertificate.Subject}}", strings.Join(paths, ","))
+	if err := runPowerShellJSON(ctx, script, &signatures); err != nil {
+		return ports, err
+	}
+
+	signers := make(map[string]string)
+	for _, signature := range signatures {
+		switch {
+		case signature.Status == "NotSigned":
+			signers[strings.ToLower(signature.Path)] = "未签名"
+		case signature.Signer != "":
+			signer := signature.Signer
+			if matches := certCommonNameRegex.FindStringSubmatch(signer); len(matches) > 1 {
+				signer = strings.Trim(matches[1], `"`)
+			}
+			if signature.Status != "Valid" {
+				signer += "（" + signature.Status + "）"
+			}
+			signers[strings.ToLower(signature.Path)] = signer
+		}
+	}
+	for i := range ports {
+		ports[i].Signer = signers[strings.ToLower(ports[i].Path)]
+	}
+	return ports, nil
+}
@@ -0,0 +1,48 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// localeSettings 表示PowerShell返回的时区、区域和语言设置
+type localeSettings struct {
+	TimeZone     string
+	Culture      string
+	Languages    []string
+	InputSources []string
+}
+
+// localeScript 获取时区、区域设置以及用户语言列表中的输入法
+const localeScript = `$languages = Get-WinUserLanguageList -ErrorAction SilentlyContinue
+[PSCustomObject]@{
+	TimeZone = (Get-TimeZone).Id
+	Culture = (Get-Culture).Name
+	Languages = @($languages | ForEach-Object { $_.LanguageTag })
+	InputSources = @($languages | ForEach-Object { $tag = $_.LanguageTag; $_.InputMethodTips | ForEach-Object { "$tag $_" } })
+}`
+
+// getLocaleInfo 获取系统时区、区域设置、首选语言和键盘输入法
+func getLocaleInfo(ctx context.Context) (model.LocaleInfo, error) {
+	locale := model.LocaleInfo{
+		UTCOffset: time.Now().Format("-07:00"),
+	}
+
+	var settings []localeSettings
+	if err := runPowerShellJSON(ctx, localeScript, &settings); err != nil {
+		return locale, err
+	}
+	if len(settings) > 0 {
+		locale.TimeZone = settings[0].TimeZone
+		locale.Locale = settings[0].Culture
+		locale.Languages = settings[0].Languages
+		locale.InputSources = settings[0].InputSources
+	}
+
+	return locale, nil
+}
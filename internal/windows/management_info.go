@@ -0,0 +1,72 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// mdmEnrollment 表示注册表Enrollments下的MDM注册信息
+type mdmEnrollment struct {
+	ProviderID   string
+	UPN          string
+	DiscoveryURL string
+}
+
+// mdmEnrollmentsScript 读取HKLM\SOFTWARE\Microsoft\Enrollments中带有ProviderID的注册项
+const mdmEnrollmentsScript = `Get-ChildItem 'HKLM:\SOFTWARE\Microsoft\Enrollments' -ErrorAction SilentlyContinue | ForEach-Object {
+	$item = Get-ItemProperty $_.PSPath -ErrorAction SilentlyContinue
+	if ($item.ProviderID) {
+		[PSCustomObject]@{
+			ProviderID = $item.ProviderID
+			UPN = [string]$item.UPN
+			DiscoveryURL = [string]$item.DiscoveryServiceFullURL
+		}
+	}
+}`
+
+// mdmProviders 常见MDM提供商ID的显示名称
+var mdmProviders = map[string]string{
+	"MS DM Server":           "Microsoft Intune",
+	"WMI_Bridge_SCCM_Server": "Configuration Manager",
+}
+
+// getManagement 获取MDM注册提供商和合规状态
+func getManagement(ctx context.Context) (model.ManagementInfo, error) {
+	var management model.ManagementInfo
+
+	var enrollments []mdmEnrollment
+	if err := runPowerShellJSON(ctx, mdmEnrollmentsScript, &enrollments); err != nil {
+		return management, err
+	}
+
+	for _, enrollment := range enrollments {
+		management.Enrolled = true
+		management.Provider = enrollment.ProviderID
+		if name, ok := mdmProviders[enrollment.ProviderID]; ok {
+			management.Provider = name
+		}
+		if enrollment.UPN != "" {
+			management.Provider += "（" + enrollment.UPN + "）"
+		}
+		management.ServerURL = enrollment.DiscoveryURL
+		// 优先使用Intune注册
+		if enrollment.ProviderID == "MS DM Server" {
+			break
+		}
+	}
+
+	// dsregcmd在已注册设备上报告合规状态
+	if values, err := runDsregcmd(ctx); err == nil {
+		switch values["IsCompliant"] {
+		case "YES":
+			management.Compliance = "合规"
+		case "NO":
+			management.Compliance = "不合规"
+		}
+	}
+
+	return management, nil
+}
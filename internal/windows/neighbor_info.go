@@ -0,0 +1,73 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/lldp"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// CaptureNeighbors 使用系统自带的pktmon被动抓取LLDP/CDP通告，获取所连接的交换机名称、端口和VLAN
+// 需要管理员权限和Windows 10 2004及以上版本；pktmon在所有网卡上抓包，无法区分收到通告的网卡。
+// 抓包前后会清除pktmon中已有的过滤条件，只在使用--scan-switch参数时调用
+func CaptureNeighbors(ctx context.Context) ([]model.NeighborInfo, error) {
+	dir, err := os.MkdirTemp("", "sysspector-lldp")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	etlFile := filepath.Join(dir, "lldp.etl")
+	pcapFile := filepath.Join(dir, "lldp.pcapng")
+
+	steps := [][]string{
+		{"filter", "remove"},
+		{"filter", "add", "LLDP", "-d", "0x88CC"},
+		{"filter", "add", "CDP", "-m", "01-00-0C-CC-CC-CC"},
+		{"start", "--capture", "--pkt-size", "0", "--file-name", etlFile},
+	}
+	// 采集被取消时仍然停止抓包并清除过滤条件，避免pktmon在后台继续运行
+	cleanup := context.WithoutCancel(ctx)
+	defer runPktmon(cleanup, "filter", "remove")
+	for _, args := range steps {
+		if err := runPktmon(ctx, args...); err != nil {
+			return nil, err
+		}
+	}
+
+	select {
+	case <-time.After(lldp.CaptureDuration):
+	case <-ctx.Done():
+		runPktmon(cleanup, "stop")
+		return nil, ctx.Err()
+	}
+	if err := runPktmon(cleanup, "stop"); err != nil {
+		return nil, err
+	}
+	if err := runPktmon(ctx, "etl2pcap", etlFile, "--out", pcapFile); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(pcapFile)
+	if err != nil {
+		return nil, err
+	}
+	return lldp.ParseCapture(data)
+}
+
+// runPktmon 执行pktmon命令，失败时返回命令的输出
+func runPktmon(ctx context.Context, args ...string) error {
+	output, err := exec.CommandContext(ctx, "pktmon", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("pktmon %s failed: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
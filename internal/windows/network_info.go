@@ -4,6 +4,7 @@
 package windows
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -16,8 +17,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/internal/collect"
+	"github.com/AsterZephyr/SysSpector/internal/config"
+	"github.com/AsterZephyr/SysSpector/internal/ifaces"
 	"github.com/AsterZephyr/SysSpector/pkg/model"
-	"github.com/shirou/gopsutil/v3/net"
 )
 
 // 定义WMI查询结构体
@@ -48,86 +52,227 @@ type win32NetworkAdapterConfiguration struct {
 	MACAddress           string
 }
 
-// GetNetworkInfo 获取Windows系统的网络信息
+// GetNetworkInfo 并发收集Windows系统的网络信息
 func GetNetworkInfo() (model.NetworkInfo, error) {
-	var info model.NetworkInfo
-	var err error
+	info := newNetworkInfo()
+	collect.Run(&info, networkTasks())
 
-	// 获取网络适配器信息
+	// 检查VPN推送的DNS与其他网卡DNS的冲突
+	analysis.CheckDNSResolvers(&info.DNS, info.VPN)
+	return info, nil
+}
+
+// newNetworkInfo 返回网络采集项的初始值，网卡的地址、网关和DNS服务器供采集项使用，先于其他采集项获取
+func newNetworkInfo() model.NetworkInfo {
+	info := model.NetworkInfo{
+		Latency: model.LatencyInfo{
+			Targets:     []model.TargetLatencyInfo{},
+			NetworkHops: []model.NetworkHopInfo{},
+		},
+	}
+	getAdapterInfo(&info)
+	return info
+}
+
+// getAdapterInfo 从活跃的物理网卡获取IP、MAC地址、网关和DNS服务器
+func getAdapterInfo(info *model.NetworkInfo) {
 	var adapters []win32NetworkAdapter
-	err = safeWMIQuery("SELECT Name, NetConnectionID, MACAddress, Speed, AdapterType, PhysicalAdapter, NetEnabled, ProductName, ServiceName, DHCPEnabled, IPAddress, IPSubnet, DefaultIPGateway, DNSServerSearchOrder FROM Win32_NetworkAdapter WHERE PhysicalAdapter=True", &adapters)
-	
+	err := safeWMIQuery("SELECT Name, NetConnectionID, MACAddress, Speed, AdapterType, PhysicalAdapter, NetEnabled, ProductName, ServiceName, DHCPEnabled, IPAddress, IPSubnet, DefaultIPGateway, DNSServerSearchOrder FROM Win32_NetworkAdapter WHERE PhysicalAdapter=True", &adapters)
 	if err != nil || len(adapters) == 0 {
 		log.Printf("Error getting network adapters or no adapters found: %v", err)
-	} else {
-		// 找到活跃的网络适配器
-		for _, adapter := range adapters {
-			if adapter.NetEnabled && adapter.PhysicalAdapter {
-				// 获取IP地址
-				if len(adapter.IPAddress) > 0 {
-					info.IP = adapter.IPAddress[0]
-				}
-				
-				// 获取MAC地址
-				info.MacAddress = adapter.MACAddress
-				
-				// 获取网关
-				if len(adapter.DefaultIPGateway) > 0 {
-					// 设置VPN信息中的服务器字段作为网关
-					info.VPN.Server = adapter.DefaultIPGateway[0]
-				}
-				
-				// 获取DNS服务器
-				info.DNSServers = adapter.DNSServerSearchOrder
-				
-				// 设置WiFi连接状态
-				if strings.Contains(adapter.Name, "Wireless") || strings.Contains(adapter.Name, "WiFi") || strings.Contains(adapter.Name, "Wi-Fi") {
-					info.WiFi.IsConnected = adapter.NetEnabled
-				}
-				
-				break
+		return
+	}
+
+	// 找到活跃的网络适配器
+	for _, adapter := range adapters {
+		if adapter.NetEnabled && adapter.PhysicalAdapter {
+			// 获取IP地址
+			if len(adapter.IPAddress) > 0 {
+				info.IP = adapter.IPAddress[0]
 			}
+
+			// 获取MAC地址
+			info.MacAddress = adapter.MACAddress
+
+			// 获取网关
+			if len(adapter.DefaultIPGateway) > 0 {
+				// 设置VPN信息中的服务器字段作为网关
+				info.VPN.Server = adapter.DefaultIPGateway[0]
+			}
+
+			// 获取DNS服务器
+			info.DNSServers = adapter.DNSServerSearchOrder
+
+			// 设置WiFi连接状态
+			if strings.Contains(adapter.Name, "Wireless") || strings.Contains(adapter.Name, "WiFi") || strings.Contains(adapter.Name, "Wi-Fi") {
+				info.WiFi.IsConnected = adapter.NetEnabled
+			}
+
+			break
 		}
 	}
-	
-	// 获取公网IP
-	info.PublicIP = getPublicIP()
-	
-	// 获取网络代理状态
-	info.ProxyStatus = getProxyStatus()
-	
-	// 获取路由表
-	info.RouteTable = getRouteTable()
-	
-	// 获取Hosts文件
-	hostEntries := getHostsFile()
-	if len(hostEntries) > 0 {
-		info.DNS.HostEntries = hostEntries
-	}
-	
-	// 获取国家/地区代码
-	info.CountryCode = getCountryCode()
-	
-	// 获取WiFi信息
-	wifiInfo, err := getWiFiInfo()
-	if err == nil {
-		info.WiFi = wifiInfo
-	}
-	
-	// 获取网络流量
-	info.NetworkTraffic = getNetworkTraffic()
-	
-	// 获取VPN状态
-	vpnStatus := getVPNStatus()
-	if vpnStatus == "已连接" {
-		info.VPN.IsConnected = true
-		info.VPN.Status = vpnStatus
-	} else {
-		info.VPN.IsConnected = false
-		info.VPN.Status = vpnStatus
+}
+
+// networkTasks 返回网络信息的采集项，各采集项可以读取网卡信息
+func networkTasks() []collect.Task[model.NetworkInfo] {
+	return []collect.Task[model.NetworkInfo]{
+		// 公网IP，离线模式下不访问外部服务
+		{Name: "public_ip", Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			if !config.Current().Offline {
+				info.PublicIP = getPublicIP()
+				analysis.EnrichPublicIP(info, config.Current().PublicIP)
+			}
+			return nil
+		}},
+		// WinINET和WinHTTP代理设置
+		{Name: "proxy", Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			proxyInfo, err := getProxyInfo(ctx)
+			info.ProxyInfo = proxyInfo
+			info.ProxyStatus = proxyInfo.Enabled
+			return err
+		}},
+		{Name: "routes", Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			info.RouteTable = getRouteTable(ctx)
+			return nil
+		}},
+		// 监听中的端口及其所属进程，按进程和远端组织汇总的已建立连接
+		{Name: "listening_ports", Run: func(ctx context.Context, info *model.NetworkInfo) (err error) {
+			info.ListeningPorts, err = getListeningPorts(ctx)
+			return err
+		}},
+		{Name: "connections", Run: func(ctx context.Context, info *model.NetworkInfo) (err error) {
+			info.Connections, err = getConnections()
+			return err
+		}},
+		{Name: "hosts", Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			if hostEntries := getHostsFile(); len(hostEntries) > 0 {
+				info.DNS.HostEntries = hostEntries
+			}
+			return nil
+		}},
+		{Name: "country_code", Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			info.CountryCode = getCountryCode()
+			return nil
+		}},
+		// WiFi信息和最近的WiFi连接、漫游和断开记录
+		{Name: "wifi", Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			wifiInfo, err := getWiFiInfo(ctx)
+			if err == nil {
+				info.WiFi = wifiInfo
+			}
+			return nil
+		}},
+		{Name: "wifi_history", Run: func(ctx context.Context, info *model.NetworkInfo) (err error) {
+			info.WiFiHistory, err = getWiFiHistory(ctx)
+			return err
+		}},
+		// 各网络接口的收发速率和合计流量
+		{Name: "traffic", Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			interfaceTraffic, err := ifaces.Rates(analysis.ProcessSampleWindow)
+			rx, tx := ifaces.Total(interfaceTraffic)
+			info.InterfaceTraffic = interfaceTraffic
+			info.NetworkTraffic = fmt.Sprintf("%.2f KB/s", (rx+tx)/1024)
+			return err
+		}},
+		// 各进程的网络流量
+		{Name: "process_traffic", Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			processTraffic, err := getProcessTraffic()
+			info.ProcessTrafficStats = processTraffic
+			info.ProcessTraffic = analysis.SummarizeProcessTraffic(processTraffic)
+			return err
+		}},
+		// 对比默认网关和互联网的延迟与丢包
+		{Name: "gateway", Run: func(ctx context.Context, info *model.NetworkInfo) (err error) {
+			info.Gateway, err = getGatewayInfo(ctx)
+			return err
+		}},
+		// 各网卡的DHCP租约和选项
+		{Name: "dhcp", Run: func(ctx context.Context, info *model.NetworkInfo) (err error) {
+			info.DHCPLeases, err = getDHCPLeases(ctx)
+			return err
+		}},
+		// 采样TCP重传和错误计数
+		{Name: "tcp_stats", Run: func(ctx context.Context, info *model.NetworkInfo) (err error) {
+			info.TCPStats, err = getTCPStats(ctx)
+			return err
+		}},
+		// 探测到配置目标的路径MTU，检测VPN等导致的MTU黑洞
+		{Name: "mtu", Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			info.MTU = analysis.DiscoverPathMTU(config.Current().MTU.Target)
+			return nil
+		}},
+		// 通过STUN检测NAT类型
+		{Name: "nat", Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			info.NAT = analysis.DetectNAT(config.Current().STUN.Servers)
+			return nil
+		}},
+		{Name: "ipv6", Run: func(ctx context.Context, info *model.NetworkInfo) (err error) {
+			info.IPv6, err = getIPv6Info(ctx)
+			return err
+		}},
+		// VPN客户端和连接状态，VPN连接时分析哪些网段经过隧道
+		{Name: "vpn", Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			vpnInfo, err := getVPNInfo(ctx)
+			if err != nil {
+				log.Printf("Error getting VPN info: %v", err)
+			}
+			if vpnInfo.Server == "" {
+				vpnInfo.Server = info.VPN.Server
+			}
+			info.VPN = vpnInfo
+			if !info.VPN.IsConnected {
+				return nil
+			}
+			routes, err := getSplitTunnelRoutes(ctx)
+			if err != nil {
+				log.Printf("Error getting routes for split tunnel analysis: %v", err)
+			}
+			info.VPN.SplitTunnel = analysis.AnalyzeSplitTunnel(info.VPN, routes, config.Current().VPN.CorporateSubnets)
+			return nil
+		}},
+		// 每个网卡和NRPT规则的DNS服务器
+		{Name: "dns_resolvers", Run: func(ctx context.Context, info *model.NetworkInfo) (err error) {
+			info.DNS.Resolvers, err = getDNSResolvers(ctx)
+			return err
+		}},
+		// 测试各DNS服务器的解析耗时，诊断关键域名的解析结果，检测分区解析和DNS劫持
+		{Name: "dns", Timeout: slowTaskTimeout, Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			info.DNSBenchmark = analysis.BenchmarkDNS(info.DNSServers, config.Current().DNS)
+			info.DNSDiagnostics = analysis.DiagnoseDNS(info.DNSServers, config.Current().DNS)
+			return nil
+		}},
+		// HTTP/HTTPS端点和常用SaaS服务的各阶段耗时和证书链，出站端口是否被防火墙拦截，离线模式下不访问外部服务
+		{Name: "endpoints", Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			if !config.Current().Offline {
+				info.EndpointChecks = analysis.CheckEndpoints(config.Current().Endpoint)
+			}
+			return nil
+		}},
+		{Name: "saas", Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			if !config.Current().Offline {
+				info.SaaS = analysis.CheckSaaS(config.Current().SaaS, config.Current().Endpoint.InspectionIssuers)
+			}
+			return nil
+		}},
+		{Name: "ports", Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			if !config.Current().Offline {
+				info.PortMatrix = analysis.CheckPorts(config.Current().Ports)
+			}
+			return nil
+		}},
+		// 按配置文件中的探测分组并发测量各目标的延迟，使用内置的路由跟踪获取网络路径信息
+		{Name: "latency", Timeout: slowTaskTimeout, Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			return analysis.MeasureLatency(&info.Latency, config.Current().Latency.Groups)
+		}},
+		{Name: "traceroute", Timeout: slowTaskTimeout, Run: func(ctx context.Context, info *model.NetworkInfo) error {
+			return analysis.TraceRoute(&info.Latency, analysis.TraceTarget)
+		}},
+		// 时间同步状态和时钟偏差
+		{Name: "time_sync", Run: func(ctx context.Context, info *model.NetworkInfo) (err error) {
+			info.TimeSync, err = getTimeSync(ctx)
+			return err
+		}},
 	}
-	
-	return info, nil
 }
 
 // getPublicIP 获取公网IP
@@ -167,29 +312,12 @@ func getPublicIP() string {
 	return ""
 }
 
-// getProxyStatus 获取代理状态
-func getProxyStatus() bool {
-	// 通过注册表查询代理设置
-	cmd := exec.Command("reg", "query", "HKCU\\Software\\Microsoft\\Windows\\CurrentVersion\\Internet Settings", "/v", "ProxyEnable")
-	output, err := cmd.Output()
-	if err != nil {
-		return false
-	}
-	
-	// 解析输出
-	if strings.Contains(string(output), "0x1") {
-		return true
-	}
-	
-	return false
-}
-
 // getRouteTable 获取路由表
-func getRouteTable() []model.RouteEntry {
+func getRouteTable(ctx context.Context) []model.RouteEntry {
 	var routes []model.RouteEntry
 	
 	// 使用route print命令获取路由表
-	cmd := exec.Command("route", "print")
+	cmd := exec.CommandContext(ctx, "route", "print")
 	output, err := cmd.Output()
 	if err != nil {
 		log.Printf("Error getting route table: %v", err)
@@ -312,12 +440,17 @@ func getCountryCode() string {
 	return result.CountryCode
 }
 
+// CurrentWiFi 只读取当前WiFi连接的信息，用于serve模式下定期推送信号强度
+func CurrentWiFi(ctx context.Context) (model.WiFiInfo, error) {
+	return getWiFiInfo(ctx)
+}
+
 // getWiFiInfo 获取WiFi信息
-func getWiFiInfo() (model.WiFiInfo, error) {
+func getWiFiInfo(ctx context.Context) (model.WiFiInfo, error) {
 	var wifiInfo model.WiFiInfo
 	
 	// 使用netsh命令获取WiFi信息
-	cmd := exec.Command("netsh", "wlan", "show", "interfaces")
+	cmd := exec.CommandContext(ctx, "netsh", "wlan", "show", "interfaces")
 	output, err := cmd.Output()
 	if err != nil {
 		return wifiInfo, fmt.Errorf("error getting WiFi info: %v", err)
@@ -370,6 +503,13 @@ func getWiFiInfo() (model.WiFiInfo, error) {
 		}
 	}
 	
+	// 提取安全类型
+	authRegex := regexp.MustCompile(`Authentication\s+:\s+(.+)`)
+	authMatches := authRegex.FindStringSubmatch(outputStr)
+	if len(authMatches) > 1 {
+		wifiInfo.Security = analysis.WiFiSecurity(authMatches[1])
+	}
+	
 	// 提取PHY模式
 	radioTypeRegex := regexp.MustCompile(`Radio type\s+:\s+(.+)`)
 	radioTypeMatches := radioTypeRegex.FindStringSubmatch(outputStr)
@@ -400,7 +540,7 @@ func getWiFiInfo() (model.WiFiInfo, error) {
 	}
 	
 	// 获取支持的PHY模式
-	cmd = exec.Command("netsh", "wlan", "show", "drivers")
+	cmd = exec.CommandContext(ctx, "netsh", "wlan", "show", "drivers")
 	output, err = cmd.Output()
 	if err == nil {
 		outputStr = string(output)
@@ -441,7 +581,7 @@ func getWiFiInfo() (model.WiFiInfo, error) {
 	}
 	
 	// 获取WiFi国家/地区代码
-	cmd = exec.Command("netsh", "wlan", "show", "settings")
+	cmd = exec.CommandContext(ctx, "netsh", "wlan", "show", "settings")
 	output, err = cmd.Output()
 	if err == nil {
 		outputStr = string(output)
@@ -472,73 +612,12 @@ func getWiFiInfo() (model.WiFiInfo, error) {
 		wifiInfo.TxRate = txRateNum
 	}
 	
-	return wifiInfo, nil
-}
-
-// getNetworkTraffic 获取网络流量
-func getNetworkTraffic() string {
-	// 获取当前网络流量
-	counters, err := net.IOCounters(true)
-	if err != nil {
-		return ""
-	}
-	
-	// 记录第一次采样
-	var activeInterface net.IOCountersStat
-	found := false
-	
-	// 查找活跃的网络接口
-	for _, counter := range counters {
-		if counter.BytesSent > 0 || counter.BytesRecv > 0 {
-			activeInterface = counter
-			found = true
-			break
+	// netsh不显示信道宽度，从当前基站的信标帧信息元素中解析
+	if wifiInfo.BSSID != "" {
+		if width, err := getChannelWidth(wifiInfo.BSSID); err == nil {
+			wifiInfo.ChannelWidth = width
 		}
 	}
 	
-	if !found {
-		return "0 KB/s"
-	}
-	
-	// 等待1秒进行第二次采样
-	time.Sleep(1 * time.Second)
-	
-	// 获取第二次采样
-	counters, err = net.IOCounters(true)
-	if err != nil {
-		return ""
-	}
-	
-	// 计算流量差值
-	for _, counter := range counters {
-		if counter.Name == activeInterface.Name {
-			sentDiff := float64(counter.BytesSent - activeInterface.BytesSent)
-			recvDiff := float64(counter.BytesRecv - activeInterface.BytesRecv)
-			
-			// 计算总流量（KB/s）
-			totalKBps := (sentDiff + recvDiff) / 1024
-			
-			return fmt.Sprintf("%.2f KB/s", totalKBps)
-		}
-	}
-	
-	return "0 KB/s"
-}
-
-// getVPNStatus 获取VPN状态
-func getVPNStatus() string {
-	// 使用netsh命令检查VPN连接
-	cmd := exec.Command("netsh", "interface", "show", "interface")
-	output, err := cmd.Output()
-	if err != nil {
-		return "未连接"
-	}
-	
-	// 检查输出中是否包含VPN接口
-	outputStr := string(output)
-	if strings.Contains(outputStr, "VPN") || strings.Contains(outputStr, "PPP") {
-		return "已连接"
-	}
-	
-	return "未连接"
+	return wifiInfo, nil
 }
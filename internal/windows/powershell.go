@@ -0,0 +1,41 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// runPowerShell 执行PowerShell命令并返回输出，ctx被取消或超时时终止命令
+func runPowerShell(ctx context.Context, script string) (string, error) {
+	cmd := exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("powershell command failed: %v", err)
+	}
+	return string(output), nil
+}
+
+// runPowerShellJSON 执行返回对象数组的PowerShell命令，并将JSON输出解析到dst
+// script的结果会被强制包装为数组，避免单个对象时ConvertTo-Json输出非数组
+func runPowerShellJSON(ctx context.Context, script string, dst interface{}) error {
+	output, err := runPowerShell(ctx, fmt.Sprintf("ConvertTo-Json -Depth 4 -Compress -InputObject @(%s)", script))
+	if err != nil {
+		return err
+	}
+
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return nil
+	}
+
+	if err := json.Unmarshal([]byte(output), dst); err != nil {
+		return fmt.Errorf("error parsing powershell output: %v", err)
+	}
+	return nil
+}
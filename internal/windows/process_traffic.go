@@ -0,0 +1,185 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"errors"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// TCP扩展统计相关的IP Helper API
+var (
+	iphlpapi                      = syscall.NewLazyDLL("iphlpapi.dll")
+	procGetExtendedTcpTable       = iphlpapi.NewProc("GetExtendedTcpTable")
+	procSetPerTcpConnectionEStats = iphlpapi.NewProc("SetPerTcpConnectionEStats")
+	procGetPerTcpConnectionEStats = iphlpapi.NewProc("GetPerTcpConnectionEStats")
+)
+
+const (
+	afINET                      = 2   // AF_INET
+	tcpTableOwnerPIDConnections = 4   // TCP_TABLE_OWNER_PID_CONNECTIONS
+	tcpConnectionEstatsData     = 1   // TcpConnectionEstatsData
+	mibTCPStateEstab            = 5   // MIB_TCP_STATE_ESTAB
+	errorInsufficientBuffer     = 122 // ERROR_INSUFFICIENT_BUFFER
+)
+
+// tcpRowOwnerPID 对应MIB_TCPROW_OWNER_PID，前5个字段与MIB_TCPROW相同
+type tcpRowOwnerPID struct {
+	State      uint32
+	LocalAddr  uint32
+	LocalPort  uint32
+	RemoteAddr uint32
+	RemotePort uint32
+	OwningPID  uint32
+}
+
+// tcpEStatsDataROD 对应TCP_ESTATS_DATA_ROD_v0
+type tcpEStatsDataROD struct {
+	DataBytesOut      uint64
+	DataSegsOut       uint64
+	DataBytesIn       uint64
+	DataSegsIn        uint64
+	SegsOut           uint64
+	SegsIn            uint64
+	SoftErrors        uint32
+	SoftErrorReason   uint32
+	SndUna            uint32
+	SndNxt            uint32
+	SndMax            uint32
+	ThruBytesAcked    uint64
+	RcvNxt            uint32
+	ThruBytesReceived uint64
+}
+
+// getProcessTraffic 使用TCP扩展统计（GetPerTcpConnectionEStats）统计采样窗口内各进程的收发字节数
+// 开启统计需要管理员权限；只统计IPv4 TCP连接，采样开始后新建的连接不计入
+func getProcessTraffic() ([]model.ProcessTrafficInfo, error) {
+	rows, err := tcpConnections()
+	if err != nil {
+		return nil, err
+	}
+
+	// 为所有已建立的连接开启数据统计并记录初始值
+	var enabled []tcpRowOwnerPID
+	var before []tcpEStatsDataROD
+	for _, row := range rows {
+		if row.State != mibTCPStateEstab {
+			continue
+		}
+		if err := setEStatsCollection(&row, true); err != nil {
+			if err == syscall.ERROR_ACCESS_DENIED {
+				return nil, errors.New("enabling tcp extended statistics requires administrator privileges")
+			}
+			continue
+		}
+		data, err := readEStats(&row)
+		if err != nil {
+			setEStatsCollection(&row, false)
+			continue
+		}
+		enabled = append(enabled, row)
+		before = append(before, data)
+	}
+
+	window := analysis.ProcessSampleWindow
+	time.Sleep(window)
+
+	byPID := make(map[uint32]*model.ProcessTrafficInfo)
+	var traffic []*model.ProcessTrafficInfo
+	for i := range enabled {
+		after, err := readEStats(&enabled[i])
+		setEStatsCollection(&enabled[i], false)
+		if err != nil || after.DataBytesIn < before[i].DataBytesIn || after.DataBytesOut < before[i].DataBytesOut {
+			continue
+		}
+
+		pid := enabled[i].OwningPID
+		t, ok := byPID[pid]
+		if !ok {
+			t = &model.ProcessTrafficInfo{PID: int32(pid)}
+			if p, err := process.NewProcess(int32(pid)); err == nil {
+				t.Process, _ = p.Name()
+			}
+			byPID[pid] = t
+			traffic = append(traffic, t)
+		}
+		t.BytesIn += after.DataBytesIn - before[i].DataBytesIn
+		t.BytesOut += after.DataBytesOut - before[i].DataBytesOut
+	}
+
+	result := make([]model.ProcessTrafficInfo, 0, len(traffic))
+	for _, t := range traffic {
+		result = append(result, *t)
+	}
+	return analysis.RankProcessTraffic(result, window), nil
+}
+
+// tcpConnections 获取所有IPv4 TCP连接及其所属进程
+func tcpConnections() ([]tcpRowOwnerPID, error) {
+	var size uint32
+	ret, _, _ := procGetExtendedTcpTable.Call(0, uintptr(unsafe.Pointer(&size)), 0, afINET, tcpTableOwnerPIDConnections, 0)
+	if ret != errorInsufficientBuffer {
+		return nil, syscall.Errno(ret)
+	}
+
+	buf := make([]byte, size)
+	ret, _, _ = procGetExtendedTcpTable.Call(uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size)), 0, afINET, tcpTableOwnerPIDConnections, 0)
+	if ret != 0 {
+		return nil, syscall.Errno(ret)
+	}
+
+	// MIB_TCPTABLE_OWNER_PID：DWORD条目数，后接条目数组
+	count := *(*uint32)(unsafe.Pointer(&buf[0]))
+	rows := make([]tcpRowOwnerPID, count)
+	rowSize := unsafe.Sizeof(tcpRowOwnerPID{})
+	for i := range rows {
+		rows[i] = *(*tcpRowOwnerPID)(unsafe.Pointer(&buf[4+uintptr(i)*rowSize]))
+	}
+	return rows, nil
+}
+
+// setEStatsCollection 开启或关闭一个连接的数据统计
+func setEStatsCollection(row *tcpRowOwnerPID, enable bool) error {
+	// TCP_ESTATS_DATA_RW_v0只有一个BOOLEAN字段
+	rw := byte(0)
+	if enable {
+		rw = 1
+	}
+	ret, _, _ := procSetPerTcpConnectionEStats.Call(
+		uintptr(unsafe.Pointer(row)),
+		tcpConnectionEstatsData,
+		uintptr(unsafe.Pointer(&rw)),
+		0,
+		unsafe.Sizeof(rw),
+		0,
+	)
+	if ret != 0 {
+		return syscall.Errno(ret)
+	}
+	return nil
+}
+
+// readEStats 读取一个连接开启统计以来的收发字节数
+func readEStats(row *tcpRowOwnerPID) (tcpEStatsDataROD, error) {
+	var rod tcpEStatsDataROD
+	ret, _, _ := procGetPerTcpConnectionEStats.Call(
+		uintptr(unsafe.Pointer(row)),
+		tcpConnectionEstatsData,
+		0, 0, 0,
+		0, 0, 0,
+		uintptr(unsafe.Pointer(&rod)),
+		0,
+		unsafe.Sizeof(rod),
+	)
+	if ret != 0 {
+		return rod, syscall.Errno(ret)
+	}
+	return rod, nil
+}
@@ -0,0 +1,151 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// 代理设置的来源
+const (
+	proxySourceWinINET = "WinINET（当前用户）"
+	proxySourceWinHTTP = "WinHTTP"
+)
+
+// DefaultConnectionSettings第9个字节中表示自动检测设置（WPAD）的标志
+const wininetFlagAutoDetect = 0x08
+
+// WinHttpSettings中的标志，3表示使用代理服务器，1表示直接访问
+const winhttpFlagProxy = 0x02
+
+// getProxyInfo 获取当前用户的WinINET代理设置（浏览器等应用使用）和系统的WinHTTP代理设置（系统服务使用）
+// 两者经常不一致，导致浏览器能访问而Windows更新等服务不能访问
+func getProxyInfo(ctx context.Context) (model.ProxyInfo, error) {
+	var proxy model.ProxyInfo
+
+	var settings []struct {
+		ProxyEnable       int
+		ProxyServer       string
+		ProxyOverride     string
+		AutoConfigURL     string
+		ConnectionSetting []int
+		WinHttpSettings   []int
+	}
+	err := runPowerShellJSON(ctx, `$s = Get-ItemProperty 'HKCU:\Software\Microsoft\Windows\CurrentVersion\Internet Settings' -ErrorAction SilentlyContinue; `+
+		`$c = Get-ItemProperty 'HKCU:\Software\Microsoft\Windows\CurrentVersion\Internet Settings\Connections' -ErrorAction SilentlyContinue; `+
+		`$w = Get-ItemProperty 'HKLM:\SOFTWARE\Microsoft\Windows\CurrentVersion\Internet Settings\Connections' -ErrorAction SilentlyContinue; `+
+		`[pscustomobject]@{ProxyEnable = [int]$s.ProxyEnable; ProxyServer = [string]$s.ProxyServer; ProxyOverride = [string]$s.ProxyOverride; `+
+		`AutoConfigURL = [string]$s.AutoConfigURL; ConnectionSetting = @($c.DefaultConnectionSettings); WinHttpSettings = @($w.WinHttpSettings)}`, &settings)
+	if err != nil || len(settings) == 0 {
+		return proxy, err
+	}
+	s := settings[0]
+
+	if s.ProxyEnable != 0 && s.ProxyServer != "" {
+		proxy.Settings = append(proxy.Settings, parseProxyServer(proxySourceWinINET, s.ProxyServer)...)
+		proxy.Bypass = splitProxyList(s.ProxyOverride)
+	}
+	if s.AutoConfigURL != "" {
+		proxy.Settings = append(proxy.Settings, model.ProxySettingInfo{Source: proxySourceWinINET, Type: analysis.ProxyPAC, Server: s.AutoConfigURL})
+	}
+	if len(s.ConnectionSetting) > 8 && s.ConnectionSetting[8]&wininetFlagAutoDetect != 0 {
+		proxy.Settings = append(proxy.Settings, model.ProxySettingInfo{Source: proxySourceWinINET, Type: analysis.ProxyWPAD})
+	}
+
+	if server, bypass, ok := parseWinHTTPSettings(toBytes(s.WinHttpSettings)); ok {
+		proxy.Settings = append(proxy.Settings, parseProxyServer(proxySourceWinHTTP, server)...)
+		proxy.Bypass = append(proxy.Bypass, splitProxyList(bypass)...)
+	}
+
+	analysis.SummarizeProxy(&proxy)
+	analysis.CollectProxyEnvironment(&proxy, os.Environ())
+	return proxy, nil
+}
+
+// parseProxyServer 解析代理服务器设置，格式为"host:port"（所有协议使用同一个代理）
+// 或"http=host:port;https=host:port;socks=host:port"
+func parseProxyServer(source, value string) []model.ProxySettingInfo {
+	var settings []model.ProxySettingInfo
+	for _, entry := range splitProxyList(value) {
+		proxyType := analysis.ProxyHTTP
+		if parts := strings.SplitN(entry, "=", 2); len(parts) == 2 {
+			switch strings.ToLower(parts[0]) {
+			case "http":
+			case "https":
+				proxyType = analysis.ProxyHTTPS
+			case "socks":
+				proxyType = analysis.ProxySOCKS
+			default:
+				continue
+			}
+			entry = parts[1]
+		}
+		host, port := splitProxyAddress(entry)
+		settings = append(settings, model.ProxySettingInfo{Source: source, Type: proxyType, Server: host, Port: port})
+	}
+	return settings
+}
+
+// parseWinHTTPSettings 解析注册表中WinHttpSettings的二进制值：
+// 结构大小、计数器、标志、代理服务器长度和内容、例外列表长度和内容，整数均为小端序的DWORD
+func parseWinHTTPSettings(data []byte) (string, string, bool) {
+	if len(data) < 16 || binary.LittleEndian.Uint32(data[8:])&winhttpFlagProxy == 0 {
+		return "", "", false
+	}
+	readString := func(offset int) (string, int, bool) {
+		if offset+4 > len(data) {
+			return "", offset, false
+		}
+		n := int(binary.LittleEndian.Uint32(data[offset:]))
+		offset += 4
+		if offset+n > len(data) {
+			return "", offset, false
+		}
+		return string(data[offset : offset+n]), offset + n, true
+	}
+	server, offset, ok := readString(12)
+	if !ok || server == "" {
+		return "", "", false
+	}
+	bypass, _, _ := readString(offset)
+	return server, bypass, true
+}
+
+// splitProxyAddress 拆分代理地址中的主机和端口，允许带有http://前缀
+func splitProxyAddress(address string) (string, int) {
+	if i := strings.Index(address, "://"); i >= 0 {
+		address = address[i+3:]
+	}
+	address = strings.TrimSuffix(address, "/")
+	host, portText, err := net.SplitHostPort(address)
+	if err != nil {
+		return address, 0
+	}
+	port, _ := strconv.Atoi(portText)
+	return host, port
+}
+
+// splitProxyList 拆分分号或空白分隔的列表
+func splitProxyList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ';' || r == ' ' || r == '\t'
+	})
+}
+
+// toBytes 将PowerShell输出的字节数组转换为[]byte
+func toBytes(values []int) []byte {
+	data := make([]byte, len(values))
+	for i, v := range values {
+		data[i] = byte(v)
+	}
+	return data
+}
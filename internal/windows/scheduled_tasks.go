@@ -0,0 +1,83 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// scheduledTask 表示PowerShell返回的计划任务
+type scheduledTask struct {
+	TaskPath   string
+	TaskName   string
+	State      string
+	Triggers   string
+	Command    string
+	LastRun    string
+	LastResult int64
+}
+
+// scheduledTasksScript 枚举计划任务，跳过\Microsoft\下的系统内置任务
+const scheduledTasksScript = `Get-ScheduledTask | Where-Object { $_.TaskPath -notlike '\Microsoft\*' } | ForEach-Object {
+	$taskInfo = $_ | Get-ScheduledTaskInfo -ErrorAction SilentlyContinue
+	[PSCustomObject]@{
+		TaskPath = $_.TaskPath
+		TaskName = $_.TaskName
+		State = [string]$_.State
+		Triggers = (@($_.Triggers | ForEach-Object { $_.CimClass.CimClassName -replace '^MSFT_Task', '' -replace 'Trigger$', '' }) -join ', ')
+		Command = (@($_.Actions | Where-Object { $_.Execute } | ForEach-Object { ($_.Execute + ' ' + $_.Arguments).Trim() }) -join '; ')
+		LastRun = if ($taskInfo -and $taskInfo.LastRunTime -and $taskInfo.LastRunTime.Year -gt 1999) { $taskInfo.LastRunTime.ToString('yyyy-MM-dd HH:mm:ss') } else { '' }
+		LastResult = if ($taskInfo) { [int64]$taskInfo.LastTaskResult } else { 0 }
+	}
+}`
+
+// taskResults 常见的计划任务结果码
+var taskResults = map[int64]string{
+	0x0:        "成功",
+	0x1:        "函数调用错误",
+	0x41300:    "任务已准备好运行",
+	0x41301:    "正在运行",
+	0x41302:    "任务已禁用",
+	0x41303:    "尚未运行",
+	0x41306:    "任务已终止",
+	0x8004131F: "已有实例正在运行",
+}
+
+// getScheduledTasks 获取第三方计划任务及其触发器和上次运行结果
+func getScheduledTasks(ctx context.Context) ([]model.ScheduledTaskInfo, error) {
+	var tasks []scheduledTask
+	if err := runPowerShellJSON(ctx, scheduledTasksScript, &tasks); err != nil {
+		return nil, err
+	}
+
+	var result []model.ScheduledTaskInfo
+	for _, task := range tasks {
+		taskInfo := model.ScheduledTaskInfo{
+			Name:    strings.TrimPrefix(task.TaskPath, `\`) + task.TaskName,
+			Source:  "任务计划程序",
+			Trigger: task.Triggers,
+			Command: task.Command,
+			LastRun: task.LastRun,
+		}
+		if task.State == "Disabled" {
+			taskInfo.Trigger += "（已禁用）"
+		}
+
+		// 结果码为HRESULT，按无符号32位解释
+		code := task.LastResult & 0xFFFFFFFF
+		if desc, ok := taskResults[code]; ok {
+			taskInfo.LastResult = desc
+		} else {
+			taskInfo.LastResult = fmt.Sprintf("0x%X", code)
+		}
+
+		result = append(result, taskInfo)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,44 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// searchIndexerProcesses Windows Search索引相关进程
+var searchIndexerProcesses = []string{"SearchIndexer.exe", "SearchProtocolHost.exe", "SearchFilterHost.exe"}
+
+// getSearchIndex 获取Windows Search服务状态、索引数据库大小和索引进程的CPU占用
+// services和processes为已收集的服务和进程信息
+func getSearchIndex(services []model.ServiceInfo, processes []model.ProcessInfo) (model.SearchIndexInfo, error) {
+	index := model.SearchIndexInfo{Service: "Windows Search", State: "未安装"}
+
+	for _, service := range services {
+		if strings.EqualFold(service.Name, "WSearch") {
+			index.State = service.State
+			index.StartType = service.StartType
+			break
+		}
+	}
+
+	// 索引数据库：Windows 10为Windows.edb，Windows 11为Windows.db及其附属文件
+	dataDir := filepath.Join(os.Getenv("ProgramData"), "Microsoft", "Search", "Data", "Applications", "Windows")
+	for _, pattern := range []string{"*.edb", "*.db"} {
+		matches, _ := filepath.Glob(filepath.Join(dataDir, pattern))
+		for _, path := range matches {
+			if stat, err := os.Stat(path); err == nil {
+				index.IndexSize += uint64(stat.Size())
+			}
+		}
+	}
+
+	analysis.EvaluateIndexerLoad(&index, processes, searchIndexerProcesses)
+	return index, nil
+}
@@ -0,0 +1,246 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// netFirewallProfile 表示Get-NetFirewallProfile返回的配置文件
+type netFirewallProfile struct {
+	Name                  string
+	Enabled               string
+	DefaultInboundAction  string
+	DefaultOutboundAction string
+}
+
+// getFirewall 获取Windows Defender防火墙各配置文件的状态和入站允许规则数量
+func getFirewall(ctx context.Context) (model.FirewallInfo, error) {
+	var firewall model.FirewallInfo
+
+	var profiles []netFirewallProfile
+	err := runPowerShellJSON(ctx, `Get-NetFirewallProfile | ForEach-Object {
+	[PSCustomObject]@{
+		Name = $_.Name
+		Enabled = [string]$_.Enabled
+		DefaultInboundAction = [string]$_.DefaultInboundAction
+		DefaultOutboundAction = [string]$_.DefaultOutboundAction
+	}
+}`, &profiles)
+	if err != nil {
+		return firewall, err
+	}
+
+	for _, p := range profiles {
+		profile := model.FirewallProfileInfo{
+			Name:            p.Name,
+			Enabled:         p.Enabled == "True",
+			DefaultInbound:  p.DefaultInboundAction,
+			DefaultOutbound: p.DefaultOutboundAction,
+		}
+		if profile.Enabled {
+			firewall.Enabled = true
+		}
+		firewall.Profiles = append(firewall.Profiles, profile)
+	}
+
+	// 统计已启用的入站允许规则
+	output, err := runPowerShell(ctx, "(Get-NetFirewallRule -Direction Inbound -Action Allow -Enabled True | Measure-Object).Count")
+	if err == nil {
+		firewall.InboundAllowRules, _ = strconv.Atoi(strings.TrimSpace(output))
+	}
+
+	return firewall, nil
+}
+
+// antiVirusProduct 表示SecurityCenter2中注册的防病毒产品
+type antiVirusProduct struct {
+	Name         string
+	ProductState uint32
+	Version      string
+}
+
+// knownEDRServices 常见EDR产品的服务名
+var knownEDRServices = map[string]string{
+	"CSFalconService":  "CrowdStrike Falcon",
+	"SentinelAgent":    "SentinelOne",
+	"Sense":            "Microsoft Defender for Endpoint",
+	"CbDefense":        "VMware Carbon Black",
+	"CylanceSvc":       "Cylance",
+	"cyserver":         "Cortex XDR",
+	"xagt":             "Trellix Endpoint Security",
+	"elastic-endpoint": "Elastic Endpoint",
+}
+
+// getEndpointProtection 通过Windows安全中心获取防病毒产品，并根据服务识别EDR代理
+func getEndpointProtection(ctx context.Context, services []model.ServiceInfo) ([]model.EndpointProtectionInfo, error) {
+	var products []model.EndpointProtectionInfo
+
+	// SecurityCenter2只存在于客户端版本的Windows中
+	var avProducts []antiVirusProduct
+	err := runPowerShellJSON(ctx, `Get-CimInstance -Namespace root/SecurityCenter2 -ClassName AntiVirusProduct | ForEach-Object {
+	$exe = [Environment]::ExpandEnvironmentVariables($_.pathToSignedProductExe)
+	[PSCustomObject]@{
+		Name = $_.displayName
+		ProductState = $_.productState
+		Version = if ($exe -and (Test-Path $exe)) { (Get-Item $exe).VersionInfo.ProductVersion } else { '' }
+	}
+}`, &avProducts)
+
+	for _, av := range avProducts {
+		// productState第二个字节表示实时保护状态，第三个字节表示病毒库是否最新
+		product := model.EndpointProtectionInfo{
+			Name:        av.Name,
+			Kind:        "防病毒",
+			Version:     av.Version,
+			RealTime:    "未启用",
+			Definitions: "已过期",
+		}
+		if (av.ProductState>>12)&0xF == 1 {
+			product.RealTime = "已启用"
+			product.Running = true
+		}
+		if (av.ProductState>>4)&0xF == 0 {
+			product.Definitions = "最新"
+		}
+		products = append(products, product)
+	}
+
+	for _, service := range services {
+		name, ok := knownEDRServices[service.Name]
+		if !ok {
+			continue
+		}
+		products = append(products, model.EndpointProtectionInfo{
+			Name:     name,
+			Kind:     "EDR",
+			Running:  service.State == "运行中",
+			RealTime: "未知",
+		})
+	}
+
+	return products, err
+}
+
+// remoteAccessStatus 表示PowerShell返回的远程访问服务状态
+type remoteAccessStatus struct {
+	RDPEnabled   bool
+	RDPPort      int
+	RDPUsers     []string
+	SSHInstalled bool
+	SSHRunning   bool
+	SSHConfig    string
+}
+
+// remoteAccessScript 读取远程桌面注册表设置、远程桌面用户组成员和OpenSSH服务状态
+// 组通过内置SID查询，避免依赖系统语言
+const remoteAccessScript = `$ts = Get-ItemProperty 'HKLM:\SYSTEM\CurrentControlSet\Control\Terminal Server' -ErrorAction SilentlyContinue
+$rdpTcp = Get-ItemProperty 'HKLM:\SYSTEM\CurrentControlSet\Control\Terminal Server\WinStations\RDP-Tcp' -ErrorAction SilentlyContinue
+$sshd = Get-Service sshd -ErrorAction SilentlyContinue
+[PSCustomObject]@{
+	RDPEnabled = ($ts -and $ts.fDenyTSConnections -eq 0)
+	RDPPort = if ($rdpTcp) { [int]$rdpTcp.PortNumber } else { 3389 }
+	RDPUsers = @(@('S-1-5-32-544', 'S-1-5-32-555') | ForEach-Object { Get-LocalGroupMember -SID $_ -ErrorAction SilentlyContinue } | ForEach-Object { $_.Name } | Select-Object -Unique)
+	SSHInstalled = [bool]$sshd
+	SSHRunning = ($sshd -and $sshd.Status -eq 'Running')
+	SSHConfig = if (Test-Path "$env:ProgramData\ssh\sshd_config") { Get-Content -Raw "$env:ProgramData\ssh\sshd_config" } else { '' }
+}`
+
+// sshPortRegex 匹配sshd_config中的Port设置
+var sshPortRegex = regexp.MustCompile(`(?m)^\s*Port\s+(\d+)`)
+
+// sshAllowRegex 匹配sshd_config中的AllowUsers和AllowGroups设置
+var sshAllowRegex = regexp.MustCompile(`(?m)^\s*Allow(?:Users|Groups)\s+(.+)$`)
+
+// getRemoteAccess 获取远程桌面和OpenSSH服务器的开放状态
+func getRemoteAccess(ctx context.Context) ([]model.RemoteAccessInfo, error) {
+	var statuses []remoteAccessStatus
+	if err := runPowerShellJSON(ctx, remoteAccessScript, &statuses); err != nil {
+		return nil, err
+	}
+	if len(statuses) == 0 {
+		return nil, nil
+	}
+	status := statuses[0]
+
+	rdp := model.RemoteAccessInfo{Name: "远程桌面（RDP）", Enabled: status.RDPEnabled}
+	if rdp.Enabled {
+		rdp.Ports = []int{status.RDPPort}
+		rdp.AllowedUsers = status.RDPUsers
+	}
+
+	ssh := model.RemoteAccessInfo{Name: "OpenSSH服务器", Enabled: status.SSHRunning}
+	if ssh.Enabled {
+		ssh.Ports = []int{22}
+		var ports []int
+		for _, match := range sshPortRegex.FindAllStringSubmatch(status.SSHConfig, -1) {
+			if port, err := strconv.Atoi(match[1]); err == nil {
+				ports = append(ports, port)
+			}
+		}
+		if len(ports) > 0 {
+			ssh.Ports = ports
+		}
+		for _, match := range sshAllowRegex.FindAllStringSubmatch(status.SSHConfig, -1) {
+			ssh.AllowedUsers = append(ssh.AllowedUsers, strings.Fields(match[1])...)
+		}
+	}
+
+	return []model.RemoteAccessInfo{rdp, ssh}, nil
+}
+
+// smbShare 表示Get-SmbShare返回的共享及其访问权限
+type smbShare struct {
+	Name   string
+	Path   string
+	Access []string
+}
+
+// smbSharesScript 枚举非系统默认的SMB共享（跳过ADMIN$、C$等管理共享）及其访问控制
+const smbSharesScript = `Get-SmbShare -Special $false -ErrorAction SilentlyContinue | ForEach-Object {
+	[PSCustomObject]@{
+		Name = $_.Name
+		Path = $_.Path
+		Access = @(Get-SmbShareAccess -Name $_.Name -ErrorAction SilentlyContinue | ForEach-Object { "$($_.AccountName): $($_.AccessControlType) $($_.AccessRight)" })
+	}
+}`
+
+// getFileSharing 获取SMB服务器状态和共享文件夹
+func getFileSharing(ctx context.Context) (model.FileSharingInfo, error) {
+	var sharing model.FileSharingInfo
+
+	output, err := runPowerShell(ctx, "(Get-Service LanmanServer -ErrorAction SilentlyContinue).Status")
+	if err == nil && strings.TrimSpace(output) == "Running" {
+		sharing.Enabled = true
+		sharing.Protocols = []string{"SMB"}
+	}
+
+	var shares []smbShare
+	if err := runPowerShellJSON(ctx, smbSharesScript, &shares); err != nil {
+		return sharing, err
+	}
+
+	for _, share := range shares {
+		fileShare := model.FileShareInfo{
+			Name:        share.Name,
+			Path:        share.Path,
+			Protocols:   "SMB",
+			Permissions: strings.Join(share.Access, "; "),
+		}
+		// Everyone或Guest具有允许权限时视为访客可访问
+		for _, access := range share.Access {
+			if (strings.HasPrefix(access, "Everyone:") || strings.Contains(access, "Guest")) && strings.Contains(access, "Allow") {
+				fileShare.GuestAccess = true
+			}
+		}
+		sharing.Shares = append(sharing.Shares, fileShare)
+	}
+
+	return sharing, nil
+}
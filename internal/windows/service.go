@@ -0,0 +1,247 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+
+	"github.com/AsterZephyr/SysSpector/internal/logfile"
+)
+
+// ServiceName 系统服务的名称，同时也是事件日志的来源
+const ServiceName = "SysSpector"
+
+// serviceStopTimeout 停止服务时等待服务退出的时长
+const serviceStopTimeout = 30 * time.Second
+
+// ServiceConfigPath 返回系统服务默认使用的配置文件
+func ServiceConfigPath() string {
+	return filepath.Join(serviceDataDir(), "config.json")
+}
+
+// serviceDataDir 返回系统服务的数据目录（%ProgramData%\SysSpector）
+func serviceDataDir() string {
+	programData := os.Getenv("ProgramData")
+	if programData == "" {
+		programData = `C:\ProgramData`
+	}
+	return filepath.Join(programData, "SysSpector")
+}
+
+// InstallService 将程序注册为自动启动的Windows服务，进程异常退出后1分钟内重启，并注册事件日志来源，需要管理员权限
+// 服务以SYSTEM运行，数据目录设为只有SYSTEM和Administrators可以访问
+func InstallService(executable string, args []string) error {
+	if err := secureServiceDir(serviceDataDir()); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(serviceDataDir(), "logs"), 0700); err != nil {
+		return err
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(ServiceName); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s is already installed", ServiceName)
+	}
+	s, err := m.CreateService(ServiceName, executable, mgr.Config{
+		DisplayName: "SysSpector",
+		Description: "采集系统和网络信息，提供REST/gRPC接口并按配置推送快照和告警",
+		StartType:   mgr.StartAutomatic,
+	}, args...)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	recovery := []mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: 10 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: time.Minute},
+		{Type: mgr.ServiceRestart, Delay: time.Minute},
+	}
+	if err := s.SetRecoveryActions(recovery, uint32((24 * time.Hour).Seconds())); err != nil {
+		log.Printf("Error setting service recovery actions: %v", err)
+	}
+	if err := eventlog.InstallAsEventCreate(ServiceName, eventlog.Error|eventlog.Warning|eventlog.Info); err != nil {
+		s.Delete()
+		return fmt.Errorf("install event log source: %v", err)
+	}
+	return s.Start()
+}
+
+// UninstallService 停止并删除服务和事件日志来源，保留配置文件和日志
+func UninstallService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(ServiceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", ServiceName)
+	}
+	defer s.Close()
+
+	if err := stopService(s); err != nil {
+		return err
+	}
+	if err := s.Delete(); err != nil {
+		return err
+	}
+	if err := eventlog.Remove(ServiceName); err != nil {
+		log.Printf("Error removing event log source: %v", err)
+	}
+	return nil
+}
+
+// StartService 启动服务
+func StartService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(ServiceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", ServiceName)
+	}
+	defer s.Close()
+	return s.Start()
+}
+
+// StopService 停止服务并等待其退出
+func StopService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(ServiceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", ServiceName)
+	}
+	defer s.Close()
+	return stopService(s)
+}
+
+// RestartService 重新启动正在运行的服务，用于更新程序后使用新版本；服务没有安装或没有运行时返回false
+func RestartService() (bool, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return false, err
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(ServiceName)
+	if err != nil {
+		return false, nil
+	}
+	defer s.Close()
+	status, err := s.Query()
+	if err != nil || status.State == svc.Stopped {
+		return false, err
+	}
+	if err := stopService(s); err != nil {
+		return false, err
+	}
+	return true, s.Start()
+}
+
+// stopService 发送停止请求并等待服务进入已停止状态，服务没有运行时直接返回
+func stopService(s *mgr.Service) error {
+	status, err := s.Query()
+	if err != nil {
+		return err
+	}
+	if status.State == svc.Stopped {
+		return nil
+	}
+	if status, err = s.Control(svc.Stop); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(serviceStopTimeout)
+	for status.State != svc.Stopped {
+		if time.Now().After(deadline) {
+			return errors.New("timed out waiting for the service to stop")
+		}
+		time.Sleep(300 * time.Millisecond)
+		if status, err = s.Query(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsService 判断当前进程是否由服务控制管理器启动
+func IsService() bool {
+	isService, err := svc.IsWindowsService()
+	return err == nil && isService
+}
+
+// RunService 作为Windows服务运行run，收到停止或关机请求时取消ctx并等待run返回。
+// 日志写入%ProgramData%\SysSpector\logs下的轮转日志文件，启动、停止和异常退出同时记录到事件日志
+func RunService(run func(ctx context.Context) error) error {
+	log.SetOutput(logfile.New(filepath.Join(serviceDataDir(), "logs", "sysspector.log")))
+	events, err := eventlog.Open(ServiceName)
+	if err != nil {
+		return err
+	}
+	defer events.Close()
+	return svc.Run(ServiceName, &serviceHandler{run: run, events: events})
+}
+
+// serviceHandler 处理服务控制管理器的请求
+type serviceHandler struct {
+	run    func(ctx context.Context) error
+	events *eventlog.Log
+}
+
+// Execute 实现svc.Handler
+func (h *serviceHandler) Execute(args []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	changes <- svc.Status{State: svc.StartPending}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- h.run(ctx) }()
+
+	changes <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	h.events.Info(1, "SysSpector service started")
+	for {
+		select {
+		case request := <-requests:
+			switch request.Cmd {
+			case svc.Interrogate:
+				changes <- request.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				cancel()
+				if err := <-done; err != nil {
+					h.events.Error(3, fmt.Sprintf("SysSpector service stopped with error: %v", err))
+					return false, 1
+				}
+				h.events.Info(2, "SysSpector service stopped")
+				return false, 0
+			}
+		case err := <-done:
+			// 服务自行退出（例如监听端口被占用），返回非0退出码以触发恢复操作
+			if err == nil {
+				err = errors.New("service exited unexpectedly")
+			}
+			h.events.Error(3, fmt.Sprintf("SysSpector service failed: %v", err))
+			return false, 1
+		}
+	}
+}
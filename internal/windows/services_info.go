@@ -0,0 +1,69 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// win32Service 表示Win32_Service中的服务信息
+type win32Service struct {
+	Name        string
+	DisplayName string
+	StartMode   string
+	State       string
+	ProcessId   uint32
+	PathName    string
+}
+
+// serviceStartModes Windows服务启动类型的中文描述
+var serviceStartModes = map[string]string{
+	"Auto":     "自动",
+	"Manual":   "手动",
+	"Disabled": "已禁用",
+	"Boot":     "引导",
+	"System":   "系统",
+}
+
+// serviceStates Windows服务运行状态的中文描述
+var serviceStates = map[string]string{
+	"Running":          "运行中",
+	"Stopped":          "已停止",
+	"Paused":           "已暂停",
+	"Start Pending":    "正在启动",
+	"Stop Pending":     "正在停止",
+	"Continue Pending": "正在继续",
+	"Pause Pending":    "正在暂停",
+}
+
+// getServices 获取Windows服务及其启动类型和运行状态
+func getServices() ([]model.ServiceInfo, error) {
+	var services []win32Service
+	err := safeWMIQuery("SELECT Name, DisplayName, StartMode, State, ProcessId, PathName FROM Win32_Service", &services)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []model.ServiceInfo
+	for _, s := range services {
+		service := model.ServiceInfo{
+			Name:        s.Name,
+			DisplayName: s.DisplayName,
+			Kind:        "服务",
+			StartType:   s.StartMode,
+			State:       s.State,
+			PID:         int(s.ProcessId),
+			Path:        s.PathName,
+		}
+		if startType, ok := serviceStartModes[s.StartMode]; ok {
+			service.StartType = startType
+		}
+		if state, ok := serviceStates[s.State]; ok {
+			service.State = state
+		}
+		result = append(result, service)
+	}
+
+	return result, nil
+}
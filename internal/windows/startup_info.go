@@ -0,0 +1,59 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// startupEntry 表示PowerShell返回的启动项
+type startupEntry struct {
+	Name     string
+	Location string
+	Target   string
+}
+
+// startupScript 枚举Run/RunOnce注册表键和启动文件夹，快捷方式解析为目标路径
+const startupScript = `$keys = 'HKLM:\SOFTWARE\Microsoft\Windows\CurrentVersion\Run',
+	'HKLM:\SOFTWARE\Microsoft\Windows\CurrentVersion\RunOnce',
+	'HKLM:\SOFTWARE\WOW6432Node\Microsoft\Windows\CurrentVersion\Run',
+	'HKLM:\SOFTWARE\WOW6432Node\Microsoft\Windows\CurrentVersion\RunOnce',
+	'HKCU:\SOFTWARE\Microsoft\Windows\CurrentVersion\Run',
+	'HKCU:\SOFTWARE\Microsoft\Windows\CurrentVersion\RunOnce'
+foreach ($key in $keys) {
+	$item = Get-ItemProperty $key -ErrorAction SilentlyContinue
+	if ($item) {
+		$item.PSObject.Properties | Where-Object { $_.Name -notlike 'PS*' } | ForEach-Object {
+			[PSCustomObject]@{ Name = $_.Name; Location = $key; Target = [string]$_.Value }
+		}
+	}
+}
+$shell = New-Object -ComObject WScript.Shell
+foreach ($folder in [Environment]::GetFolderPath('Startup'), [Environment]::GetFolderPath('CommonStartup')) {
+	Get-ChildItem $folder -File -ErrorAction SilentlyContinue | Where-Object { $_.Name -ne 'desktop.ini' } | ForEach-Object {
+		$target = $_.FullName
+		if ($_.Extension -eq '.lnk') { $target = $shell.CreateShortcut($_.FullName).TargetPath }
+		[PSCustomObject]@{ Name = $_.BaseName; Location = $folder; Target = $target }
+	}
+}`
+
+// getStartupItems 获取Run/RunOnce注册表键和启动文件夹中的启动项
+func getStartupItems(ctx context.Context) ([]model.StartupItemInfo, error) {
+	var entries []startupEntry
+	if err := runPowerShellJSON(ctx, startupScript, &entries); err != nil {
+		return nil, err
+	}
+
+	var items []model.StartupItemInfo
+	for _, entry := range entries {
+		items = append(items, model.StartupItemInfo{
+			Name:     entry.Name,
+			Location: entry.Location,
+			Target:   entry.Target,
+		})
+	}
+
+	return items, nil
+}
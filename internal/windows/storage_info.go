@@ -0,0 +1,76 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// externalVolume 表示外接磁盘上带盘符的卷
+type externalVolume struct {
+	Model       string
+	Bus         string
+	DriveLetter string
+	Label       string
+	FileSystem  string
+	Size        uint64
+	Free        uint64
+	BitLocker   int
+}
+
+// bitLockerProtection 表示Shell属性System.Volume.BitLockerProtection的取值
+var bitLockerProtection = map[int]string{
+	1: "已加密",
+	2: "未加密",
+	3: "正在加密",
+	4: "正在解密",
+	5: "已暂停保护",
+	6: "已加密（已锁定）",
+}
+
+// getExternalStorage 获取USB、SD卡等外接和可移动磁盘上的卷
+func getExternalStorage(ctx context.Context) ([]model.ExternalStorageInfo, error) {
+	// 通过Shell属性读取BitLocker状态，无需管理员权限
+	script := `$shell = New-Object -ComObject Shell.Application
+	Get-Disk | Where-Object { $_.BusType -in 'USB','SD','MMC' } | ForEach-Object {
+		$disk = $_
+		Get-Partition -DiskNumber $disk.Number -ErrorAction SilentlyContinue | Where-Object DriveLetter | ForEach-Object {
+			$vol = $_ | Get-Volume
+			$letter = "$($_.DriveLetter):"
+			[PSCustomObject]@{
+				Model = $disk.FriendlyName; Bus = "$($disk.BusType)"; DriveLetter = $letter
+				Label = $vol.FileSystemLabel; FileSystem = $vol.FileSystem; Size = $vol.Size; Free = $vol.SizeRemaining
+				BitLocker = $shell.NameSpace(17).ParseName($letter).ExtendedProperty('System.Volume.BitLockerProtection')
+			}
+		}
+	}`
+
+	var volumes []externalVolume
+	if err := runPowerShellJSON(ctx, script, &volumes); err != nil {
+		return nil, err
+	}
+
+	var storage []model.ExternalStorageInfo
+	for _, v := range volumes {
+		entry := model.ExternalStorageInfo{
+			Name:       v.Label,
+			Device:     v.DriveLetter,
+			Model:      v.Model,
+			Bus:        v.Bus,
+			MountPoint: v.DriveLetter + "\\",
+			Filesystem: v.FileSystem,
+			Total:      v.Size,
+			Free:       v.Free,
+			Encryption: "未加密",
+		}
+		if state, ok := bitLockerProtection[v.BitLocker]; ok {
+			entry.Encryption = state
+			entry.Encrypted = v.BitLocker != 2
+		}
+		storage = append(storage, entry)
+	}
+
+	return storage, nil
+}
@@ -4,6 +4,7 @@
 package windows
 
 import (
+	"context"
 	"fmt"
 	"runtime"
 
@@ -29,3 +30,83 @@ func GetNetworkInfo() (model.NetworkInfo, error) {
 func GetDynamicInfo() (model.SystemInfo, error) {
 	return model.SystemInfo{}, fmt.Errorf("Windows dynamic information collection is not supported on %s", runtime.GOOS)
 }
+
+// CurrentWiFi 是 Windows WiFi信息读取的存根实现
+func CurrentWiFi(ctx context.Context) (model.WiFiInfo, error) {
+	return model.WiFiInfo{}, fmt.Errorf("Windows WiFi information is not supported on %s", runtime.GOOS)
+}
+
+// ScanDisk 是 Windows 磁盘空间分析的存根实现
+func ScanDisk() (model.DiskScanInfo, error) {
+	return model.DiskScanInfo{}, fmt.Errorf("Windows disk scan is not supported on %s", runtime.GOOS)
+}
+
+// ScanWiFi 是 Windows 无线网络扫描的存根实现
+func ScanWiFi(ctx context.Context, current model.WiFiInfo) (model.WiFiScanInfo, error) {
+	return model.WiFiScanInfo{}, fmt.Errorf("Windows WiFi scan is not supported on %s", runtime.GOOS)
+}
+
+// CaptureNeighbors 是 Windows LLDP/CDP 抓包的存根实现
+func CaptureNeighbors(ctx context.Context) ([]model.NeighborInfo, error) {
+	return nil, fmt.Errorf("Windows LLDP capture is not supported on %s", runtime.GOOS)
+}
+
+// HardwareUUID 是 Windows 硬件UUID查询的存根实现
+func HardwareUUID() (string, error) {
+	return "", fmt.Errorf("Windows hardware UUID is not supported on %s", runtime.GOOS)
+}
+
+// ServiceConfigPath 是 Windows 服务默认配置文件的存根实现
+func ServiceConfigPath() string {
+	return ""
+}
+
+// InstallService 是 Windows 服务安装的存根实现
+func InstallService(executable string, args []string) error {
+	return fmt.Errorf("Windows service is not supported on %s", runtime.GOOS)
+}
+
+// UninstallService 是 Windows 服务卸载的存根实现
+func UninstallService() error {
+	return fmt.Errorf("Windows service is not supported on %s", runtime.GOOS)
+}
+
+// StartService 是 Windows 服务启动的存根实现
+func StartService() error {
+	return fmt.Errorf("Windows service is not supported on %s", runtime.GOOS)
+}
+
+// StopService 是 Windows 服务停止的存根实现
+func StopService() error {
+	return fmt.Errorf("Windows service is not supported on %s", runtime.GOOS)
+}
+
+// RestartService 是 Windows 服务重新启动的存根实现，非Windows系统上没有服务需要重新启动
+func RestartService() (bool, error) {
+	return false, nil
+}
+
+// SecureConfigFile 是 Windows 服务配置文件权限设置的存根实现
+func SecureConfigFile(path string, defaults []byte) error {
+	return fmt.Errorf("Windows service is not supported on %s", runtime.GOOS)
+}
+
+// CheckConfigPermissions 是 Windows 服务配置文件权限检查的存根实现
+func CheckConfigPermissions(path string) error {
+	return fmt.Errorf("Windows service is not supported on %s", runtime.GOOS)
+}
+
+// IsService 是 Windows 服务检测的存根实现，非Windows系统上总是返回false
+func IsService() bool {
+	return false
+}
+
+// RunService 是 Windows 服务运行的存根实现
+func RunService(run func(ctx context.Context) error) error {
+	return fmt.Errorf("Windows service is not supported on %s", runtime.GOOS)
+}
+
+// ExportCertificate 是 Windows 证书存储导出的存根实现
+func ExportCertificate(name, password string) ([]byte, error) {
+	return nil, fmt.Errorf("Windows certificate store is not supported on %s", runtime.GOOS)
+}
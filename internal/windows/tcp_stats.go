@@ -0,0 +1,45 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// win32TCPCounters 表示Win32_PerfRawData_Tcpip_TCPv4/TCPv6性能计数器的原始值，Persec计数器的原始值为开机以来的累计数
+type win32TCPCounters struct {
+	SegmentsSentPersec          uint32
+	SegmentsReceivedPersec      uint32
+	SegmentsRetransmittedPersec uint32
+	ConnectionsReset            uint32
+	ConnectionFailures          uint32
+}
+
+// getTCPStats 采样TCP重传和错误计数，Windows的性能计数器中没有无效报文段的计数
+func getTCPStats(ctx context.Context) (model.TCPStatsInfo, error) {
+	return analysis.SampleTCPStats(ctx, readTCPCounters, analysis.TCPSampleWindow)
+}
+
+// readTCPCounters 读取IPv4和IPv6的TCP性能计数器并合计
+func readTCPCounters() (analysis.TCPCounters, error) {
+	var counters analysis.TCPCounters
+	for _, class := range []string{"Win32_PerfRawData_Tcpip_TCPv4", "Win32_PerfRawData_Tcpip_TCPv6"} {
+		var raw []win32TCPCounters
+		err := safeWMIQuery("SELECT SegmentsSentPersec, SegmentsReceivedPersec, SegmentsRetransmittedPersec, ConnectionsReset, ConnectionFailures FROM "+class, &raw)
+		if err != nil {
+			return counters, err
+		}
+		for _, c := range raw {
+			counters.SegmentsSent += uint64(c.SegmentsSentPersec)
+			counters.SegmentsReceived += uint64(c.SegmentsReceivedPersec)
+			counters.Retransmitted += uint64(c.SegmentsRetransmittedPersec)
+			counters.Resets += uint64(c.ConnectionsReset)
+			counters.Failures += uint64(c.ConnectionFailures)
+		}
+	}
+	return counters, nil
+}
@@ -0,0 +1,50 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// 匹配w32tm /query /status输出中的同步源和上次同步时间（兼容英文和中文系统）
+var (
+	w32tmSourceRegex   = regexp.MustCompile(`(?m)^(?:Source|源):\s*(.+)$`)
+	w32tmLastSyncRegex = regexp.MustCompile(`(?m)^(?:Last Successful Sync Time|上次成功同步时间):\s*(.+)$`)
+)
+
+// getTimeSync 获取Windows时间服务配置、上次同步时间，并测量与参考服务器的时钟偏差
+func getTimeSync(ctx context.Context) (model.TimeSyncInfo, error) {
+	var timeSync model.TimeSyncInfo
+
+	// 注册表中的NtpServer格式为：time.windows.com,0x9 other.server,0x8
+	output, err := runPowerShell(ctx, `$p = Get-ItemProperty 'HKLM:\SYSTEM\CurrentControlSet\Services\W32Time\Parameters' -ErrorAction SilentlyContinue
+"$($p.Type)|$($p.NtpServer)|$((Get-Service W32Time -ErrorAction SilentlyContinue).Status)"`)
+	if err == nil {
+		parts := strings.SplitN(strings.TrimSpace(output), "|", 3)
+		if len(parts) == 3 {
+			timeSync.Enabled = parts[0] != "NoSync" && parts[2] == "Running"
+			for _, server := range strings.Fields(parts[1]) {
+				timeSync.Servers = append(timeSync.Servers, strings.Split(server, ",")[0])
+			}
+		}
+	}
+
+	// w32tm的输出会随系统语言变化
+	if statusOutput, err := exec.CommandContext(ctx, "w32tm", "/query", "/status").Output(); err == nil {
+		if matches := w32tmSourceRegex.FindStringSubmatch(string(statusOutput)); len(matches) > 1 {
+			timeSync.Source = strings.TrimSpace(matches[1])
+		}
+		if matches := w32tmLastSyncRegex.FindStringSubmatch(string(statusOutput)); len(matches) > 1 {
+			timeSync.LastSync = strings.TrimSpace(matches[1])
+		}
+	}
+
+	return timeSync, analysis.MeasureClockOffset(&timeSync)
+}
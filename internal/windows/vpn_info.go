@@ -0,0 +1,128 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// getVPNInfo 获取VPN信息：Windows内置VPN连接、VPN客户端的虚拟网卡和进程，
+// 以及Tailscale、WireGuard命令行工具报告的连接状态
+func getVPNInfo(ctx context.Context) (model.VPNInfo, error) {
+	vpnInfo := model.VPNInfo{}
+
+	// Windows内置VPN（系统设置中添加的VPN连接）
+	var connections []struct {
+		Name             string
+		ServerAddress    string
+		ConnectionStatus string
+	}
+	if err := runPowerShellJSON(ctx, "Get-VpnConnection -ErrorAction SilentlyContinue | Select-Object Name, ServerAddress, @{n='ConnectionStatus';e={[string]$_.ConnectionStatus}}", &connections); err != nil {
+		log.Printf("Error getting VPN connections: %v", err)
+	}
+	for _, conn := range connections {
+		vpnInfo.NodeInfos = append(vpnInfo.NodeInfos, model.VPNNodeInfo{Name: conn.Name, Status: conn.ConnectionStatus})
+		client := model.VPNClientInfo{Provider: "Windows VPN（" + conn.Name + "）", Node: conn.ServerAddress, Source: "Get-VpnConnection"}
+		if conn.ConnectionStatus == "Connected" {
+			client.Running, client.Connected = true, true
+			client.Interface = conn.Name
+			vpnInfo.ActiveConnection = conn.Name
+			vpnInfo.Server = conn.ServerAddress
+		}
+		analysis.AddVPNClient(&vpnInfo, client)
+	}
+
+	// VPN客户端安装的虚拟网卡，网卡已启用表示隧道已建立
+	var adapters []struct {
+		Name                 string
+		InterfaceDescription string
+		Status               string
+	}
+	err := runPowerShellJSON(ctx, "Get-NetAdapter -ErrorAction SilentlyContinue | Select-Object Name, InterfaceDescription, Status", &adapters)
+	for _, adapter := range adapters {
+		provider := analysis.VPNProvider(adapter.InterfaceDescription + " " + adapter.Name)
+		if provider == "" {
+			continue
+		}
+		client := model.VPNClientInfo{Provider: provider, Source: "网卡"}
+		if adapter.Status == "Up" {
+			client.Running, client.Connected = true, true
+			client.Interface = adapter.Name
+		}
+		analysis.AddVPNClient(&vpnInfo, client)
+	}
+
+	// VPN客户端进程
+	if err := analysis.DetectVPNProcesses(&vpnInfo); err != nil {
+		log.Printf("Error listing VPN processes: %v", err)
+	}
+
+	// Tailscale的连接状态和出口节点
+	if output, err := exec.CommandContext(ctx, findCommand("tailscale", `Tailscale\tailscale.exe`), "status", "--json").Output(); err == nil {
+		if client, err := analysis.ParseTailscaleStatus(output); err == nil {
+			analysis.AddVPNClient(&vpnInfo, client)
+		}
+	}
+
+	// WireGuard命令行工具需要管理员权限才能读取隧道状态
+	if output, err := exec.CommandContext(ctx, findCommand("wg", `WireGuard\wg.exe`), "show").Output(); err == nil {
+		for _, client := range analysis.ParseWireGuardShow(string(output)) {
+			analysis.AddVPNClient(&vpnInfo, client)
+		}
+	}
+
+	analysis.SummarizeVPN(&vpnInfo)
+	return vpnInfo, err
+}
+
+// findCommand 在PATH中查找命令，找不到时使用Program Files下的默认安装位置
+func findCommand(name, programFilesPath string) string {
+	if path, err := exec.LookPath(name); err == nil {
+		return path
+	}
+	programFiles := os.Getenv("ProgramFiles")
+	if programFiles == "" {
+		programFiles = `C:\Program Files`
+	}
+	return filepath.Join(programFiles, programFilesPath)
+}
+
+// getSplitTunnelRoutes 获取IPv4路由表，跃点数为路由跃点数与接口跃点数之和，与系统选择路由的规则一致
+func getSplitTunnelRoutes(ctx context.Context) ([]analysis.Route, error) {
+	var entries []struct {
+		DestinationPrefix string
+		NextHop           string
+		InterfaceAlias    string
+		RouteMetric       int
+		InterfaceMetric   int
+	}
+	err := runPowerShellJSON(ctx, "Get-NetRoute -AddressFamily IPv4 -ErrorAction SilentlyContinue | "+
+		"Select-Object DestinationPrefix, NextHop, InterfaceAlias, RouteMetric, InterfaceMetric", &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	var routes []analysis.Route
+	for _, entry := range entries {
+		_, prefix, err := net.ParseCIDR(entry.DestinationPrefix)
+		if err != nil {
+			continue
+		}
+		routes = append(routes, analysis.Route{
+			Prefix:    prefix,
+			Gateway:   entry.NextHop,
+			Interface: entry.InterfaceAlias,
+			Metric:    entry.RouteMetric + entry.InterfaceMetric,
+		})
+	}
+	return routes, nil
+}
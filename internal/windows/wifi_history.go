@@ -0,0 +1,87 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// wlanEvent 表示WLAN-AutoConfig日志中的一个事件
+type wlanEvent struct {
+	Id          int
+	TimeCreated string
+	SSID        string
+	BSSID       string
+	Reason      string
+	ReasonCode  int
+}
+
+// WLAN-AutoConfig事件ID
+const (
+	wlanEventConnected    = 8001 // 已成功连接到无线网络
+	wlanEventFailed       = 8002 // 无法连接到无线网络
+	wlanEventDisconnected = 8003 // 已从无线网络断开
+)
+
+// wlanEventScript 获取最近24小时WLAN-AutoConfig日志中的连接、连接失败和断开事件
+// 事件数据按名称读取，失败事件的原因字段为FailureReason
+const wlanEventScript = `$start = (Get-Date).AddHours(-24)
+Get-WinEvent -FilterHashtable @{ LogName = 'Microsoft-Windows-WLAN-AutoConfig/Operational'; Id = 8001, 8002, 8003; StartTime = $start } -MaxEvents 500 -ErrorAction SilentlyContinue | ForEach-Object {
+	$data = @{}
+	foreach ($item in ([xml]$_.ToXml()).Event.EventData.Data) { $data[$item.Name] = $item.'#text' }
+	[PSCustomObject]@{
+		Id = $_.Id
+		TimeCreated = $_.TimeCreated.ToString('yyyy-MM-dd HH:mm:ss')
+		SSID = [string]$data['SSID']
+		BSSID = [string]$data['BSSID']
+		Reason = if ($data['Reason']) { [string]$data['Reason'] } else { [string]$data['FailureReason'] }
+		ReasonCode = [int]$data['ReasonCode']
+	}
+}`
+
+// getWiFiHistory 读取最近24小时的WiFi连接、漫游和断开事件
+// WLAN-AutoConfig没有单独的漫游事件，没有断开而再次连接到同一网络视为一次漫游
+func getWiFiHistory(ctx context.Context) (model.WiFiHistoryInfo, error) {
+	since := time.Now().Add(-analysis.WiFiHistoryWindow)
+
+	var events []wlanEvent
+	if err := runPowerShellJSON(ctx, wlanEventScript, &events); err != nil {
+		return model.WiFiHistoryInfo{}, err
+	}
+
+	// Get-WinEvent按时间从新到旧返回，按时间顺序处理以识别漫游
+	var result []model.WiFiEventInfo
+	connected := ""
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		info := model.WiFiEventInfo{
+			Time:       event.TimeCreated,
+			SSID:       event.SSID,
+			BSSID:      event.BSSID,
+			ReasonCode: event.ReasonCode,
+			Reason:     event.Reason,
+		}
+		switch event.Id {
+		case wlanEventConnected:
+			info.Type = analysis.WiFiEventConnect
+			if connected != "" && connected == event.SSID {
+				info.Type = analysis.WiFiEventRoam
+			}
+			connected = event.SSID
+		case wlanEventFailed:
+			info.Type = analysis.WiFiEventFailure
+		case wlanEventDisconnected:
+			info.Type = analysis.WiFiEventDisconnect
+			connected = ""
+		default:
+			continue
+		}
+		result = append(result, info)
+	}
+	return analysis.SummarizeWiFiHistory(since, result), nil
+}
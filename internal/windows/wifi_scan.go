@@ -0,0 +1,70 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// ScanWiFi 列出附近的无线网络并评估当前信道的拥挤程度，只在使用--scan-wifi参数时调用
+// netsh返回的是WLAN服务最近一次后台扫描的结果，每个BSSID作为一个网络
+func ScanWiFi(ctx context.Context, current model.WiFiInfo) (model.WiFiScanInfo, error) {
+	output, err := exec.CommandContext(ctx, "netsh", "wlan", "show", "networks", "mode=bssid").Output()
+	if err != nil {
+		return model.WiFiScanInfo{}, fmt.Errorf("error scanning WiFi networks: %v", err)
+	}
+	return analysis.AnalyzeWiFiScan(current, parseNetshNetworks(string(output))), nil
+}
+
+// parseNetshNetworks 解析netsh wlan show networks mode=bssid的输出，例如：
+//
+//	SSID 1 : Office
+//	    Authentication          : WPA2-Personal
+//	    BSSID 1                 : 11:22:33:44:55:66
+//	         Signal             : 80%
+//	         Band               : 5 GHz
+//	         Channel            : 36
+func parseNetshNetworks(output string) []model.WiFiScanResultInfo {
+	var networks []model.WiFiScanResultInfo
+	var ssid, security string
+	var current *model.WiFiScanResultInfo
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+
+		switch {
+		case strings.HasPrefix(key, "SSID"):
+			ssid, security, current = value, "", nil
+		case key == "Authentication":
+			security = value
+		case strings.HasPrefix(key, "BSSID"):
+			networks = append(networks, model.WiFiScanResultInfo{SSID: ssid, BSSID: value, Security: security})
+			current = &networks[len(networks)-1]
+		case current == nil:
+		case key == "Signal":
+			// 将信号质量百分比换算为dBm（近似值），与getWiFiInfo一致
+			signal, _ := strconv.Atoi(strings.TrimSuffix(value, "%"))
+			current.RSSI = -30 - (100-signal)*70/100
+		case key == "Band":
+			current.Band = strings.ReplaceAll(value, " ", "")
+		case key == "Channel":
+			current.Channel, _ = strconv.Atoi(value)
+		}
+	}
+	return networks
+}
@@ -4,37 +4,25 @@
 package windows
 
 import (
+	"time"
+
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/internal/collect"
 	"github.com/AsterZephyr/SysSpector/pkg/model"
 )
 
-// GetAllSystemInfo 获取所有Windows系统信息
+// slowTaskTimeout 为耗时较长的采集项（延迟探测、路由跟踪、DNS测试和已安装应用）的默认超时
+const slowTaskTimeout = 2 * time.Minute
+
+// GetAllSystemInfo 获取所有Windows系统信息，基本系统信息、动态信息和网络信息的采集项一起并发执行
 func GetAllSystemInfo() (model.SystemInfo, error) {
-	// 获取基本系统信息
-	sysInfo, err := GetSystemInfo()
-	if err != nil {
-		return sysInfo, err
-	}
-	
-	// 获取网络信息
-	netInfo, err := GetNetworkInfo()
-	if err == nil {
-		// 将网络信息整合到系统信息中
-		sysInfo.Network = netInfo
-	}
-	
-	// 获取动态信息
-	dynamicInfo, err := GetDynamicInfo()
-	if err == nil {
-		sysInfo.DiskUsage = dynamicInfo.DiskUsage
-		sysInfo.MemoryUsage = dynamicInfo.MemoryUsage
-		sysInfo.Battery = dynamicInfo.Battery
-		sysInfo.ACAdapter = dynamicInfo.ACAdapter
-		sysInfo.Bluetooth = dynamicInfo.Bluetooth
-		sysInfo.Temperature = dynamicInfo.Temperature
-		sysInfo.InstalledApps = dynamicInfo.InstalledApps
-		sysInfo.RunningApps = dynamicInfo.RunningApps
-		sysInfo.UpTime = dynamicInfo.UpTime
-	}
-	
+	sysInfo := model.SystemInfo{Network: newNetworkInfo()}
+
+	tasks := append(systemTasks(), dynamicTasks()...)
+	tasks = append(tasks, collect.Field(networkTasks(), func(info *model.SystemInfo) *model.NetworkInfo { return &info.Network })...)
+	collect.Run(&sysInfo, tasks)
+
+	// 检查VPN推送的DNS与其他网卡DNS的冲突
+	analysis.CheckDNSResolvers(&sysInfo.Network.DNS, sysInfo.Network.VPN)
 	return sysInfo, nil
 }
@@ -0,0 +1,190 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"syscall"
+	"unsafe"
+)
+
+// 本机WLAN服务API
+var (
+	wlanapi                   = syscall.NewLazyDLL("wlanapi.dll")
+	procWlanOpenHandle        = wlanapi.NewProc("WlanOpenHandle")
+	procWlanCloseHandle       = wlanapi.NewProc("WlanCloseHandle")
+	procWlanEnumInterfaces    = wlanapi.NewProc("WlanEnumInterfaces")
+	procWlanGetNetworkBssList = wlanapi.NewProc("WlanGetNetworkBssList")
+	procWlanFreeMemory        = wlanapi.NewProc("WlanFreeMemory")
+)
+
+const (
+	wlanClientVersion = 2   // Windows Vista及以上的WLAN API版本
+	dot11BssTypeAny   = 3   // dot11_BSS_type_any
+	wlanInterfaceSize = 532 // sizeof(WLAN_INTERFACE_INFO)
+	wlanBssEntrySize  = 360 // sizeof(WLAN_BSS_ENTRY)
+
+	// WLAN_BSS_ENTRY中字段的偏移
+	bssEntryBssidOffset  = 40
+	bssEntryIeOffsetAt   = 352
+	bssEntryIeSizeAt     = 356
+	wlanListHeaderLength = 8 // 列表结构开头的两个DWORD
+)
+
+// 802.11信息元素ID
+const (
+	ieHTOperation    = 61
+	ieVHTOperation   = 192
+	ieExtension      = 255
+	ieExtHEOperation = 36
+)
+
+// getChannelWidth 从WLAN服务缓存的BSS列表中找到当前连接的基站，根据其信标帧中的信息元素解析信道宽度（MHz）
+// 返回的是基站的工作带宽，客户端能力较低时实际使用的带宽可能更窄
+func getChannelWidth(bssid string) (int, error) {
+	mac, err := net.ParseMAC(bssid)
+	if err != nil {
+		return 0, err
+	}
+
+	var negotiated uint32
+	var handle uintptr
+	ret, _, _ := procWlanOpenHandle.Call(wlanClientVersion, 0, uintptr(unsafe.Pointer(&negotiated)), uintptr(unsafe.Pointer(&handle)))
+	if ret != 0 {
+		return 0, syscall.Errno(ret)
+	}
+	defer procWlanCloseHandle.Call(handle, 0)
+
+	var interfaces unsafe.Pointer
+	ret, _, _ = procWlanEnumInterfaces.Call(handle, 0, uintptr(unsafe.Pointer(&interfaces)))
+	if ret != 0 {
+		return 0, syscall.Errno(ret)
+	}
+	defer procWlanFreeMemory.Call(uintptr(interfaces))
+
+	count := *(*uint32)(interfaces)
+	for i := 0; i < int(count); i++ {
+		// 每个WLAN_INTERFACE_INFO以接口GUID开头
+		guid := unsafe.Add(interfaces, wlanListHeaderLength+i*wlanInterfaceSize)
+		ies, err := bssInformationElements(handle, guid, mac)
+		if err != nil || ies == nil {
+			continue
+		}
+		return parseChannelWidth(ies), nil
+	}
+	return 0, errors.New("connected bss not found")
+}
+
+// bssInformationElements 返回指定接口上BSSID匹配的基站的信息元素
+func bssInformationElements(handle uintptr, guid unsafe.Pointer, mac net.HardwareAddr) ([]byte, error) {
+	var list unsafe.Pointer
+	ret, _, _ := procWlanGetNetworkBssList.Call(handle, uintptr(guid), 0, dot11BssTypeAny, 0, 0, uintptr(unsafe.Pointer(&list)))
+	if ret != 0 {
+		return nil, syscall.Errno(ret)
+	}
+	defer procWlanFreeMemory.Call(uintptr(list))
+
+	// 按字节读取WLAN_BSS_LIST，避免依赖结构体在不同架构下的对齐方式
+	data := unsafe.Slice((*byte)(list), *(*uint32)(list))
+	count := int(binary.LittleEndian.Uint32(data[4:]))
+	for i := 0; i < count; i++ {
+		start := wlanListHeaderLength + i*wlanBssEntrySize
+		if start+wlanBssEntrySize > len(data) {
+			break
+		}
+		entry := data[start:]
+		if !bytes.Equal(entry[bssEntryBssidOffset:bssEntryBssidOffset+6], mac) {
+			continue
+		}
+		offset := int(binary.LittleEndian.Uint32(entry[bssEntryIeOffsetAt:]))
+		size := int(binary.LittleEndian.Uint32(entry[bssEntryIeSizeAt:]))
+		if offset+size > len(entry) {
+			return nil, errors.New("invalid information element offset")
+		}
+		ies := make([]byte, size)
+		copy(ies, entry[offset:offset+size])
+		return ies, nil
+	}
+	return nil, nil
+}
+
+// parseChannelWidth 根据HT、VHT和HE Operation信息元素计算基站的信道宽度
+func parseChannelWidth(ies []byte) int {
+	width := 20
+	for len(ies) >= 2 {
+		id, length := ies[0], int(ies[1])
+		if len(ies) < 2+length {
+			break
+		}
+		body := ies[2 : 2+length]
+		ies = ies[2+length:]
+
+		switch id {
+		case ieHTOperation:
+			// 第二个字节的低两位为辅信道偏移，第3位表示允许使用40MHz
+			if len(body) >= 2 && body[1]&0x03 != 0 && body[1]&0x04 != 0 {
+				width = maxWidth(width, 40)
+			}
+		case ieVHTOperation:
+			if len(body) < 3 {
+				continue
+			}
+			switch body[0] {
+			case 1:
+				// 第二个中心频率段不为0时为160MHz或80+80MHz
+				if body[2] != 0 {
+					width = maxWidth(width, 160)
+				} else {
+					width = maxWidth(width, 80)
+				}
+			case 2, 3:
+				width = maxWidth(width, 160)
+			}
+		case ieExtension:
+			if len(body) >= 7 && body[0] == ieExtHEOperation {
+				width = maxWidth(width, he6GHzWidth(body))
+			}
+		}
+	}
+	return width
+}
+
+// he6GHzWidth 解析HE Operation中的6GHz Operation Information，没有该部分时返回0
+func he6GHzWidth(body []byte) int {
+	params := uint32(body[1]) | uint32(body[2])<<8 | uint32(body[3])<<16
+	if params&(1<<17) == 0 {
+		return 0
+	}
+	// 跳过扩展ID、HE Operation参数、BSS Color和Basic HE-MCS And NSS Set，以及可选的VHT Operation信息和Co-Hosted BSSID指示
+	offset := 7
+	if params&(1<<14) != 0 {
+		offset += 3
+	}
+	if params&(1<<15) != 0 {
+		offset++
+	}
+	if len(body) < offset+2 {
+		return 0
+	}
+	switch body[offset+1] & 0x03 {
+	case 1:
+		return 40
+	case 2:
+		return 80
+	case 3:
+		return 160
+	}
+	return 20
+}
+
+// maxWidth 返回两个信道宽度中较大的一个
+func maxWidth(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
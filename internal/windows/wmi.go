@@ -4,8 +4,10 @@
 package windows
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os"
 	"strconv"
 	"strings"
 
@@ -13,6 +15,8 @@ import (
 	"github.com/shirou/gopsutil/v3/host"
 	"github.com/shirou/gopsutil/v3/mem"
 
+	"github.com/AsterZephyr/SysSpector/internal/analysis"
+	"github.com/AsterZephyr/SysSpector/internal/collect"
 	"github.com/AsterZephyr/SysSpector/pkg/model"
 )
 
@@ -38,8 +42,17 @@ type win32BIOS struct {
 
 // win32PhysicalMemory 表示物理内存信息
 type win32PhysicalMemory struct {
-	Capacity   uint64 // 内存容量
-	MemoryType uint16 // 内存类型代码
+	Capacity      uint64 // 内存容量
+	MemoryType    uint16 // 内存类型代码
+	DeviceLocator string // 插槽位置
+	Speed         uint32 // 速度（MT/s）
+	Manufacturer  string // 制造商
+	PartNumber    string // 型号
+}
+
+// win32PhysicalMemoryArray 表示主板内存插槽信息
+type win32PhysicalMemoryArray struct {
+	MemoryDevices uint16 // 内存插槽数量
 }
 
 // win32DiskDrive 表示磁盘驱动器信息
@@ -76,102 +89,262 @@ func safeWMIQuery(query string, dst interface{}) error {
 	return err
 }
 
-// GetSystemInfo 收集 Windows 系统的硬件和系统信息
-// 该函数用于收集Windows系统的硬件和系统信息，包括主机名、操作系统信息、计算机系统信息、序列号、CPU信息、内存信息、磁盘信息和硬件UUID
+// HardwareUUID 查询Win32_ComputerSystemProduct表获取硬件UUID，不需要采集其他系统信息，用于计算设备ID
+func HardwareUUID() (string, error) {
+	var systemProducts []win32ComputerSystemProduct
+	if err := safeWMIQuery("SELECT UUID FROM Win32_ComputerSystemProduct", &systemProducts); err != nil {
+		return "", err
+	}
+	if len(systemProducts) == 0 {
+		return "", fmt.Errorf("Win32_ComputerSystemProduct returned no rows")
+	}
+	return systemProducts[0].UUID, nil
+}
+
+// safeWMIQueryNamespace 在指定命名空间（如root\wmi）中执行WMI查询
+func safeWMIQueryNamespace(query string, dst interface{}, namespace string) error {
+	err := wmi.QueryNamespace(query, dst, namespace)
+	if err != nil {
+		log.Printf("WMI query failed: %v. Namespace: %s, Query: %s", err, namespace, query)
+	}
+	return err
+}
+
+// GetSystemInfo 并发收集 Windows 系统的硬件和系统信息
+// 包括主机名、操作系统信息、计算机系统信息、序列号、CPU信息、内存信息、磁盘信息、硬件UUID以及固件、安全和软件配置
 func GetSystemInfo() (model.SystemInfo, error) {
 	var info model.SystemInfo
-	var err error
+	collect.Run(&info, systemTasks())
+	return info, nil
+}
 
-	// 获取主机名和操作系统信息
-	// 通过调用host.Info()函数获取主机名和操作系统信息
+// systemTasks 返回硬件和系统信息的采集项
+func systemTasks() []collect.Task[model.SystemInfo] {
+	return []collect.Task[model.SystemInfo]{
+		{Name: "host", Run: getHostInfo},
+		{Name: "computer_system", Run: getComputerSystem},
+		{Name: "serial_number", Run: getSerialNumber},
+		{Name: "cpu", Run: getCPUInfo},
+		{Name: "memory", Run: getMemoryInfo},
+		{Name: "disks", Run: getDisks},
+		// 查询Win32_ComputerSystemProduct表获取硬件UUID
+		{Name: "uuid", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.UUID, err = HardwareUUID()
+			return err
+		}},
+		{Name: "firmware", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.Firmware, err = getFirmwareInfo(ctx)
+			return err
+		}},
+		// 系统盘加密信息和TPM信息
+		{Name: "disk_encryption", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.DiskEncryption, err = getDiskEncryption(ctx)
+			return err
+		}},
+		{Name: "tpm", Run: func(ctx context.Context, info *model.SystemInfo) error {
+			return getTPMInfo(ctx, &info.SecurityHardware)
+		}},
+		// PCIe设备列表和第三方驱动程序
+		{Name: "pci_devices", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.PCIDevices, err = getPCIDevices(ctx)
+			return err
+		}},
+		{Name: "drivers", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.Drivers, err = getDrivers()
+			return err
+		}},
+		// 时区、区域和输入法
+		{Name: "locale", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.Locale, err = getLocaleInfo(ctx)
+			return err
+		}},
+		// 关键环境变量（敏感值脱敏）
+		{Name: "environment", Run: func(ctx context.Context, info *model.SystemInfo) error {
+			info.Environment = analysis.SnapshotEnvironment(os.Environ())
+			return nil
+		}},
+		// 协议和文件类型的默认打开程序
+		{Name: "default_handlers", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.DefaultHandlers, err = getDefaultHandlers(ctx)
+			return err
+		}},
+		// 开发运行时和包管理器，运行中的容器和虚拟机
+		{Name: "dev_tools", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.DevTools, err = getDevTools(ctx)
+			return err
+		}},
+		{Name: "workloads", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.Workloads, err = getWorkloads(ctx)
+			return err
+		}},
+		// 已安装的补丁，Windows激活和许可证信息
+		{Name: "hotfixes", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.Hotfixes, err = getHotfixes()
+			return err
+		}},
+		{Name: "activation", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.Activation, err = getActivation()
+			return err
+		}},
+		// 最近24小时的严重和错误事件，最近的启动和关机记录
+		{Name: "event_log", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.EventLog, err = getEventLogSummary(ctx)
+			return err
+		}},
+		{Name: "boot_history", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.BootHistory, err = getBootHistory(ctx)
+			return err
+		}},
+		{Name: "firewall", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.Firewall, err = getFirewall(ctx)
+			return err
+		}},
+		// 本地用户账户、远程访问服务和文件共享状态
+		{Name: "accounts", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.LocalAccounts, err = getLocalAccounts(ctx)
+			return err
+		}},
+		{Name: "remote_access", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.RemoteAccess, err = getRemoteAccess(ctx)
+			return err
+		}},
+		{Name: "file_sharing", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.FileSharing, err = getFileSharing(ctx)
+			return err
+		}},
+		// AD域和Azure AD加入状态，MDM注册状态
+		{Name: "identity", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.Identity, err = getIdentity(ctx)
+			return err
+		}},
+		{Name: "management", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.Management, err = getManagement(ctx)
+			return err
+		}},
+		{Name: "certificates", Run: func(ctx context.Context, info *model.SystemInfo) (err error) {
+			info.Certificates, err = getCertificates(ctx)
+			return err
+		}},
+	}
+}
+
+// getHostInfo 通过调用host.Info()函数获取主机名和操作系统信息
+func getHostInfo(ctx context.Context, info *model.SystemInfo) error {
 	hostInfo, err := host.Info()
 	if err != nil {
-		log.Printf("Error getting host info: %v", err)
-	} else {
-		info.Hostname = hostInfo.Hostname
-		info.OS = hostInfo.Platform + " " + hostInfo.PlatformVersion
-
-		// 记录 Windows 版本信息，用于后续可能的版本特定查询
-		log.Printf("Windows version: %s %s", hostInfo.Platform, hostInfo.PlatformVersion)
+		return err
 	}
+	info.Hostname = hostInfo.Hostname
+	info.OS = hostInfo.Platform + " " + hostInfo.PlatformVersion
+
+	// 记录 Windows 版本信息，用于后续可能的版本特定查询
+	log.Printf("Windows version: %s %s", hostInfo.Platform, hostInfo.PlatformVersion)
+	return nil
+}
 
-	// 获取计算机系统信息
-	// 通过调用safeWMIQuery()函数查询Win32_ComputerSystem表获取计算机系统信息
+// getComputerSystem 查询Win32_ComputerSystem表获取型号
+func getComputerSystem(ctx context.Context, info *model.SystemInfo) error {
 	var computerSystems []win32ComputerSystem
-	err = safeWMIQuery("SELECT Model, Name, TotalPhysicalMemory FROM Win32_ComputerSystem", &computerSystems)
-	if err == nil && len(computerSystems) > 0 {
-		info.ModelID = computerSystems[0].Model // 在Windows中，型号标识符与型号名称相同
-		info.Model = computerSystems[0].Model
-
-		// 尝试获取更友好的型号名称
-		marketingName, err := getMarketingModelName(info.Model)
-		if err == nil && marketingName != "" {
-			info.Model = marketingName
-		}
+	if err := safeWMIQuery("SELECT Model, Name, TotalPhysicalMemory FROM Win32_ComputerSystem", &computerSystems); err != nil {
+		return err
+	}
+	if len(computerSystems) == 0 {
+		return nil
+	}
+	info.ModelID = computerSystems[0].Model // 在Windows中，型号标识符与型号名称相同
+	info.Model = computerSystems[0].Model
+
+	// 尝试获取更友好的型号名称
+	marketingName, err := getMarketingModelName(info.Model)
+	if err == nil && marketingName != "" {
+		info.Model = marketingName
 	}
+	return nil
+}
 
-	// 获取序列号
-	// 通过调用safeWMIQuery()函数查询Win32_BIOS表获取序列号
+// getSerialNumber 查询Win32_BIOS表获取序列号
+func getSerialNumber(ctx context.Context, info *model.SystemInfo) error {
 	var biosInfo []win32BIOS
-	err = safeWMIQuery("SELECT SerialNumber FROM Win32_BIOS", &biosInfo)
-	if err == nil && len(biosInfo) > 0 {
+	if err := safeWMIQuery("SELECT SerialNumber FROM Win32_BIOS", &biosInfo); err != nil {
+		return err
+	}
+	if len(biosInfo) > 0 {
 		info.SerialNumber = biosInfo[0].SerialNumber
 	}
+	return nil
+}
 
-	// 获取CPU信息
-	// 通过调用safeWMIQuery()函数查询Win32_Processor表获取CPU信息
+// getCPUInfo 查询Win32_Processor表获取CPU信息
+func getCPUInfo(ctx context.Context, info *model.SystemInfo) error {
 	var processors []win32Processor
-	err = safeWMIQuery("SELECT Name, NumberOfCores FROM Win32_Processor", &processors)
+	err := safeWMIQuery("SELECT Name, NumberOfCores FROM Win32_Processor", &processors)
 	if err == nil && len(processors) > 0 {
 		info.CPU = model.CPUInfo{
 			Model: processors[0].Name,
 			Cores: int(processors[0].NumberOfCores),
 		}
-	} else {
-		// 如果WMI查询失败，尝试使用备用方法获取CPU信息
-		log.Printf("Falling back to alternative method for CPU info")
+		return nil
+	}
 
-		// 在某些Windows版本中，Win32_Processor可能有不同的属性名称
-		var altProcessors []struct {
-			ProcessorName string
-			CoreCount     uint32
-		}
+	// 如果WMI查询失败，尝试使用备用方法获取CPU信息
+	log.Printf("Falling back to alternative method for CPU info")
 
-		// 尝试备选查询
-		altErr := safeWMIQuery("SELECT Name AS ProcessorName, NumberOfCores AS CoreCount FROM Win32_Processor", &altProcessors)
-		if altErr == nil && len(altProcessors) > 0 {
-			info.CPU = model.CPUInfo{
-				Model: altProcessors[0].ProcessorName,
-				Cores: int(altProcessors[0].CoreCount),
-			}
-		} else {
-			// 如果备选查询也失败，使用默认值
-			info.CPU = model.CPUInfo{
-				Model: "Unknown CPU",
-				Cores: 0,
-			}
+	// 在某些Windows版本中，Win32_Processor可能有不同的属性名称
+	var altProcessors []struct {
+		ProcessorName string
+		CoreCount     uint32
+	}
+
+	// 尝试备选查询
+	altErr := safeWMIQuery("SELECT Name AS ProcessorName, NumberOfCores AS CoreCount FROM Win32_Processor", &altProcessors)
+	if altErr == nil && len(altProcessors) > 0 {
+		info.CPU = model.CPUInfo{
+			Model: altProcessors[0].ProcessorName,
+			Cores: int(altProcessors[0].CoreCount),
+		}
+	} else {
+		// 如果备选查询也失败，使用默认值
+		info.CPU = model.CPUInfo{
+			Model: "Unknown CPU",
+			Cores: 0,
 		}
 	}
+	return nil
+}
 
-	// 通过调用safeWMIQuery()函数查询Win32_PhysicalMemory表获取内存信息
+// getMemoryInfo 查询Win32_PhysicalMemory表获取内存条信息，通过mem.VirtualMemory()函数获取总内存
+func getMemoryInfo(ctx context.Context, info *model.SystemInfo) error {
 	var memoryInfo []win32PhysicalMemory
-	err = safeWMIQuery("SELECT Capacity, MemoryType FROM Win32_PhysicalMemory", &memoryInfo)
+	safeWMIQuery("SELECT Capacity, MemoryType, DeviceLocator, Speed, Manufacturer, PartNumber FROM Win32_PhysicalMemory", &memoryInfo)
 
-	// 通过调用mem.VirtualMemory()函数获取内存信息，并计算总内存和内存类型
 	memStats, err := mem.VirtualMemory()
 	if err != nil {
-		log.Printf("Error getting memory info: %v", err)
-	} else {
-		info.Memory = model.MemoryInfo{
-			Total: memStats.Total,
-			Type:  getMemoryTypeString(memoryInfo),
+		return err
+	}
+	modules := getMemoryModules(memoryInfo)
+	info.Memory = model.MemoryInfo{
+		Total:      memStats.Total,
+		Type:       getMemoryTypeString(memoryInfo),
+		Modules:    modules,
+		Mismatched: analysis.MemoryModulesMismatched(modules),
+	}
+
+	// 主板插槽数多于已安装的内存条时认为可升级
+	var memoryArrays []win32PhysicalMemoryArray
+	if err := safeWMIQuery("SELECT MemoryDevices FROM Win32_PhysicalMemoryArray", &memoryArrays); err == nil {
+		slots := 0
+		for _, array := range memoryArrays {
+			slots += int(array.MemoryDevices)
 		}
+		info.Memory.Upgradeable = slots > len(modules)
 	}
+	return nil
+}
 
-	// 获取磁盘信息
-	// 通过调用safeWMIQuery()函数查询Win32_DiskDrive表获取磁盘信息
+// getDisks 查询Win32_DiskDrive表获取磁盘信息
+func getDisks(ctx context.Context, info *model.SystemInfo) error {
 	var diskDrives []win32DiskDrive
-	err = safeWMIQuery("SELECT Caption, Model, Size, SerialNumber FROM Win32_DiskDrive", &diskDrives)
+	err := safeWMIQuery("SELECT Caption, Model, Size, SerialNumber FROM Win32_DiskDrive", &diskDrives)
 	if err == nil {
 		for _, d := range diskDrives {
 			size, _ := strconv.ParseUint(d.Size, 10, 64)
@@ -184,40 +357,33 @@ func GetSystemInfo() (model.SystemInfo, error) {
 				Serial: d.SerialNumber,
 			})
 		}
-	} else {
-		// 如果标准查询失败，尝试备选查询（适用于某些Windows版本）
-		var altDiskDrives []struct {
-			DiskName   string
-			DiskModel  string
-			DiskSize   string
-			DiskSerial string
-		}
-
-		// 尝试备选查询
-		altErr := safeWMIQuery("SELECT Caption AS DiskName, Model AS DiskModel, Size AS DiskSize, SerialNumber AS DiskSerial FROM Win32_DiskDrive", &altDiskDrives)
-		if altErr == nil {
-			for _, d := range altDiskDrives {
-				size, _ := strconv.ParseUint(d.DiskSize, 10, 64)
-				// 转换为GB
-				sizeGB := size / (1024 * 1024 * 1024)
-				info.Disks = append(info.Disks, model.Disk{
-					Name:   d.DiskName,
-					Model:  d.DiskModel,
-					Size:   sizeGB,
-					Serial: d.DiskSerial,
-				})
-			}
-		}
+		return nil
 	}
 
-	// 通过调用safeWMIQuery()函数查询Win32_ComputerSystemProduct表获取硬件UUID
-	var systemProducts []win32ComputerSystemProduct
-	err = safeWMIQuery("SELECT UUID FROM Win32_ComputerSystemProduct", &systemProducts)
-	if err == nil && len(systemProducts) > 0 {
-		info.UUID = systemProducts[0].UUID
+	// 如果标准查询失败，尝试备选查询（适用于某些Windows版本）
+	var altDiskDrives []struct {
+		DiskName   string
+		DiskModel  string
+		DiskSize   string
+		DiskSerial string
 	}
 
-	return info, nil
+	// 尝试备选查询
+	if err := safeWMIQuery("SELECT Caption AS DiskName, Model AS DiskModel, Size AS DiskSize, SerialNumber AS DiskSerial FROM Win32_DiskDrive", &altDiskDrives); err != nil {
+		return err
+	}
+	for _, d := range altDiskDrives {
+		size, _ := strconv.ParseUint(d.DiskSize, 10, 64)
+		// 转换为GB
+		sizeGB := size / (1024 * 1024 * 1024)
+		info.Disks = append(info.Disks, model.Disk{
+			Name:   d.DiskName,
+			Model:  d.DiskModel,
+			Size:   sizeGB,
+			Serial: d.DiskSerial,
+		})
+	}
+	return nil
 }
 
 // getMarketingModelName 尝试获取更友好的型号名称
@@ -309,3 +475,19 @@ func getMemoryTypeString(memoryModules []win32PhysicalMemory) string {
 	}
 	return fmt.Sprintf("Unknown (%d)", memType)
 }
+
+// getMemoryModules 将WMI物理内存信息转换为各插槽内存条信息
+func getMemoryModules(memoryModules []win32PhysicalMemory) []model.MemoryModule {
+	var modules []model.MemoryModule
+	for _, m := range memoryModules {
+		modules = append(modules, model.MemoryModule{
+			Slot:         strings.TrimSpace(m.DeviceLocator),
+			Size:         m.Capacity,
+			Speed:        int(m.Speed),
+			Type:         getMemoryTypeString([]win32PhysicalMemory{m}),
+			Manufacturer: strings.TrimSpace(m.Manufacturer),
+			PartNumber:   strings.TrimSpace(m.PartNumber),
+		})
+	}
+	return modules
+}
@@ -0,0 +1,123 @@
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/internal/workload"
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// hyperVVM 表示Get-VM返回的虚拟机
+type hyperVVM struct {
+	Name           string
+	ProcessorCount int
+	MemoryAssigned uint64
+}
+
+// hyperVScript 获取运行中的Hyper-V虚拟机，未启用Hyper-V或非管理员时没有输出
+const hyperVScript = `Get-VM -ErrorAction SilentlyContinue | Where-Object { $_.State -eq 'Running' } | Select-Object Name, ProcessorCount, MemoryAssigned`
+
+// wslVMScript WSL2所有发行版共享一个轻量虚拟机，通过vmmemWSL（旧版本为vmmem）进程的工作集估算其内存占用
+const wslVMScript = `(Get-Process vmmemWSL, vmmem -ErrorAction SilentlyContinue | Measure-Object WorkingSet64 -Sum).Sum`
+
+// getWorkloads 获取运行中的Docker/Podman容器以及Hyper-V、WSL2、VMware虚拟机
+func getWorkloads(ctx context.Context) ([]model.WorkloadInfo, error) {
+	collectors := []struct {
+		name    string
+		collect func(context.Context) ([]model.WorkloadInfo, error)
+	}{
+		{"docker", workload.DockerContainers},
+		{"podman", workload.PodmanContainers},
+		{"hyper-v", getHyperVVMs},
+		{"wsl", getWSLDistributions},
+		{"vmware", func(ctx context.Context) ([]model.WorkloadInfo, error) { return workload.VMwareVMs(ctx, vmrunPath()) }},
+	}
+
+	var workloads []model.WorkloadInfo
+	for _, collector := range collectors {
+		items, err := collector.collect(ctx)
+		if err != nil {
+			log.Printf("Error getting %s workloads: %v", collector.name, err)
+		}
+		workloads = append(workloads, items...)
+	}
+
+	return workloads, nil
+}
+
+// getHyperVVMs 获取运行中的Hyper-V虚拟机及其分配的处理器和内存
+func getHyperVVMs(ctx context.Context) ([]model.WorkloadInfo, error) {
+	var vms []hyperVVM
+	if err := runPowerShellJSON(ctx, hyperVScript, &vms); err != nil {
+		return nil, err
+	}
+
+	var workloads []model.WorkloadInfo
+	for _, vm := range vms {
+		workloads = append(workloads, model.WorkloadInfo{
+			Name:   vm.Name,
+			Kind:   "Hyper-V",
+			State:  "运行中",
+			CPUs:   float64(vm.ProcessorCount),
+			Memory: vm.MemoryAssigned,
+		})
+	}
+	return workloads, nil
+}
+
+// getWSLDistributions 获取运行中的WSL发行版
+func getWSLDistributions(ctx context.Context) ([]model.WorkloadInfo, error) {
+	if _, err := exec.LookPath("wsl.exe"); err != nil {
+		return nil, nil
+	}
+
+	// wsl.exe默认输出UTF-16，设置WSL_UTF8后输出UTF-8（旧版本不支持，因此再去掉空字节）
+	cmd := exec.CommandContext(ctx, "wsl.exe", "--list", "--running", "--quiet")
+	cmd.Env = append(os.Environ(), "WSL_UTF8=1")
+	output, err := cmd.Output()
+	if err != nil {
+		// 没有运行中的发行版时wsl.exe返回非零退出码
+		return nil, nil
+	}
+
+	var workloads []model.WorkloadInfo
+	for _, line := range strings.Split(strings.ReplaceAll(string(output), "\x00", ""), "\n") {
+		name := strings.TrimSpace(line)
+		if name == "" {
+			continue
+		}
+		workloads = append(workloads, model.WorkloadInfo{
+			Name:  name,
+			Kind:  "WSL2发行版",
+			State: "运行中",
+		})
+	}
+
+	// 所有发行版共享一个WSL2虚拟机，单独记录其内存占用
+	if len(workloads) > 0 {
+		vm := model.WorkloadInfo{Name: "WSL2虚拟机", Kind: "WSL2", State: "运行中"}
+		if output, err := runPowerShell(ctx, wslVMScript); err == nil {
+			vm.Memory, _ = strconv.ParseUint(strings.TrimSpace(output), 10, 64)
+		}
+		workloads = append(workloads, vm)
+	}
+
+	return workloads, nil
+}
+
+// vmrunPath 获取VMware Workstation自带的vmrun工具路径
+func vmrunPath() string {
+	if path, err := exec.LookPath("vmrun.exe"); err == nil {
+		return path
+	}
+	return filepath.Join(os.Getenv("ProgramFiles(x86)"), "VMware", "VMware Workstation", "vmrun.exe")
+}
@@ -0,0 +1,17 @@
+package workload
+
+import (
+	"context"
+	"os/exec"
+	"time"
+)
+
+// commandTimeout 容器引擎未响应时（如Docker Desktop正在启动）避免阻塞采集
+const commandTimeout = 10 * time.Second
+
+// runTool 执行命令并返回标准输出，ctx被取消时终止命令
+func runTool(ctx context.Context, name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	return exec.CommandContext(ctx, name, args...).Output()
+}
@@ -0,0 +1,155 @@
+package workload
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// 工作负载类型
+const (
+	KindDocker       = "Docker容器"
+	KindDockerEngine = "Docker引擎虚拟机"
+	KindPodman       = "Podman容器"
+	KindPodmanVM     = "Podman虚拟机"
+)
+
+// dockerContainer 表示docker ps --format '{{json .}}'输出的一行
+type dockerContainer struct {
+	ID     string `json:"ID"`
+	Names  string `json:"Names"`
+	Image  string `json:"Image"`
+	Status string `json:"Status"`
+}
+
+// DockerContainers 获取运行中的Docker容器及其资源限制
+// macOS和Windows上的Docker Desktop运行在虚拟机中，同时返回该虚拟机分配的CPU和内存
+// 未安装docker或守护进程未运行时返回空列表
+func DockerContainers(ctx context.Context) ([]model.WorkloadInfo, error) {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return nil, nil
+	}
+	output, err := runTool(ctx, "docker", "ps", "--no-trunc", "--format", "{{json .}}")
+	if err != nil {
+		return nil, nil
+	}
+
+	var workloads []model.WorkloadInfo
+	var ids []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		var container dockerContainer
+		if err := json.Unmarshal(scanner.Bytes(), &container); err != nil {
+			return nil, err
+		}
+		ids = append(ids, container.ID)
+		workloads = append(workloads, model.WorkloadInfo{
+			Name:  container.Names,
+			Kind:  KindDocker,
+			State: container.Status,
+			Image: container.Image,
+		})
+	}
+
+	// 一次性查询所有容器的内存和CPU限制
+	if len(ids) > 0 {
+		args := append([]string{"inspect", "--format", "{{.HostConfig.Memory}} {{.HostConfig.NanoCpus}}"}, ids...)
+		if output, err := runTool(ctx, "docker", args...); err == nil {
+			for i, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+				if i >= len(workloads) {
+					break
+				}
+				fields := strings.Fields(line)
+				if len(fields) != 2 {
+					continue
+				}
+				workloads[i].Memory, _ = strconv.ParseUint(fields[0], 10, 64)
+				if nanoCPUs, err := strconv.ParseFloat(fields[1], 64); err == nil {
+					workloads[i].CPUs = nanoCPUs / 1e9
+				}
+			}
+		}
+	}
+
+	// Docker Desktop虚拟机的资源分配，Linux原生引擎的operatingsystem不包含Docker Desktop
+	if output, err := runTool(ctx, "docker", "info", "--format", "{{.OperatingSystem}}|{{.NCPU}}|{{.MemTotal}}"); err == nil {
+		fields := strings.Split(strings.TrimSpace(string(output)), "|")
+		if len(fields) == 3 && strings.Contains(fields[0], "Docker Desktop") {
+			engine := model.WorkloadInfo{Name: fields[0], Kind: KindDockerEngine, State: "运行中"}
+			engine.CPUs, _ = strconv.ParseFloat(fields[1], 64)
+			engine.Memory, _ = strconv.ParseUint(fields[2], 10, 64)
+			workloads = append(workloads, engine)
+		}
+	}
+
+	return workloads, nil
+}
+
+// podmanContainer 表示podman ps --format json输出的容器
+type podmanContainer struct {
+	Names []string `json:"Names"`
+	Image string   `json:"Image"`
+	State string   `json:"State"`
+}
+
+// podmanMachine 表示podman machine list --format json输出的虚拟机
+type podmanMachine struct {
+	Name    string      `json:"Name"`
+	Running bool        `json:"Running"`
+	VMType  string      `json:"VMType"`
+	CPUs    json.Number `json:"CPUs"`
+	Memory  json.Number `json:"Memory"`
+}
+
+// PodmanContainers 获取运行中的Podman容器和Podman虚拟机
+// 未安装podman时返回空列表
+func PodmanContainers(ctx context.Context) ([]model.WorkloadInfo, error) {
+	if _, err := exec.LookPath("podman"); err != nil {
+		return nil, nil
+	}
+
+	var workloads []model.WorkloadInfo
+
+	// macOS和Windows上的Podman运行在podman machine虚拟机中
+	if output, err := runTool(ctx, "podman", "machine", "list", "--format", "json"); err == nil {
+		// 旧版本的Memory可能是"2GiB"这样的字符串，此时跳过虚拟机信息
+		var machines []podmanMachine
+		json.Unmarshal(output, &machines)
+		for _, machine := range machines {
+			if !machine.Running {
+				continue
+			}
+			vm := model.WorkloadInfo{Name: machine.Name, Kind: KindPodmanVM, State: "运行中", Image: machine.VMType}
+			vm.CPUs, _ = machine.CPUs.Float64()
+			if memory, err := machine.Memory.Int64(); err == nil {
+				vm.Memory = uint64(memory)
+			}
+			workloads = append(workloads, vm)
+		}
+	}
+
+	output, err := runTool(ctx, "podman", "ps", "--format", "json")
+	if err != nil {
+		return workloads, nil
+	}
+	var containers []podmanContainer
+	if err := json.Unmarshal(output, &containers); err != nil {
+		return workloads, err
+	}
+	for _, container := range containers {
+		workloads = append(workloads, model.WorkloadInfo{
+			Name:  strings.Join(container.Names, ","),
+			Kind:  KindPodman,
+			State: container.State,
+			Image: container.Image,
+		})
+	}
+
+	return workloads, nil
+}
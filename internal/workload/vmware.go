@@ -0,0 +1,74 @@
+package workload
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/AsterZephyr/SysSpector/pkg/model"
+)
+
+// KindVMware VMware Fusion/Workstation虚拟机
+const KindVMware = "VMware"
+
+// VMwareVMs 通过vmrun列出运行中的VMware虚拟机，并从.vmx文件读取分配的CPU和内存
+// vmrunPath为vmrun的完整路径，不存在时返回空列表
+func VMwareVMs(ctx context.Context, vmrunPath string) ([]model.WorkloadInfo, error) {
+	if _, err := os.Stat(vmrunPath); err != nil {
+		return nil, nil
+	}
+	output, err := runTool(ctx, vmrunPath, "list")
+	if err != nil {
+		return nil, err
+	}
+
+	// 输出第一行为"Total running VMs: N"，之后每行一个.vmx路径
+	var workloads []model.WorkloadInfo
+	for _, line := range strings.Split(string(output), "\n") {
+		vmxPath := strings.TrimSpace(line)
+		if !strings.HasSuffix(strings.ToLower(vmxPath), ".vmx") {
+			continue
+		}
+
+		vm := model.WorkloadInfo{
+			Name:  strings.TrimSuffix(filepath.Base(vmxPath), filepath.Ext(vmxPath)),
+			Kind:  KindVMware,
+			State: "运行中",
+			Image: vmxPath,
+		}
+		config := parseVMX(vmxPath)
+		if name := config["displayname"]; name != "" {
+			vm.Name = name
+		}
+		vm.CPUs, _ = strconv.ParseFloat(config["numvcpus"], 64)
+		if memsize, err := strconv.ParseUint(config["memsize"], 10, 64); err == nil {
+			vm.Memory = memsize * 1024 * 1024
+		}
+		workloads = append(workloads, vm)
+	}
+
+	return workloads, nil
+}
+
+// parseVMX 解析.vmx配置文件的key = "value"行，键统一为小写
+func parseVMX(path string) map[string]string {
+	config := make(map[string]string)
+	file, err := os.Open(path)
+	if err != nil {
+		return config
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		config[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return config
+}
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// Diff 返回从old到new的JSON合并补丁（RFC 7396）：只包含有变化的字段，删除的字段为null，数组有变化时整体替换
+// 将补丁合并到old的JSON上即可得到new的JSON，没有变化时为{}
+func Diff(old, new SystemInfo) (json.RawMessage, error) {
+	oldValue, err := toJSONObject(old)
+	if err != nil {
+		return nil, err
+	}
+	newValue, err := toJSONObject(new)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergePatch(oldValue, newValue))
+}
+
+// toJSONObject 将结构体转换为JSON对象，数字保留原始的文本，避免大整数转换为浮点数后丢失精度
+func toJSONObject(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// mergePatch 比较两个JSON对象，嵌套的对象递归比较，其他值不相等时使用新值
+func mergePatch(old, new map[string]interface{}) map[string]interface{} {
+	patch := make(map[string]interface{})
+	for key, oldValue := range old {
+		newValue, ok := new[key]
+		if !ok {
+			patch[key] = nil
+			continue
+		}
+		oldObject, oldIsObject := oldValue.(map[string]interface{})
+		newObject, newIsObject := newValue.(map[string]interface{})
+		if oldIsObject && newIsObject {
+			if changes := mergePatch(oldObject, newObject); len(changes) > 0 {
+				patch[key] = changes
+			}
+			continue
+		}
+		if !reflect.DeepEqual(oldValue, newValue) {
+			patch[key] = newValue
+		}
+	}
+	for key, newValue := range new {
+		if _, ok := old[key]; !ok {
+			patch[key] = newValue
+		}
+	}
+	return patch
+}
+
+// Patch 将JSON合并补丁（RFC 7396）合并到base上，返回合并后的系统信息
+func Patch(base SystemInfo, patch []byte) (SystemInfo, error) {
+	var result SystemInfo
+	object, err := toJSONObject(base)
+	if err != nil {
+		return result, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(patch))
+	decoder.UseNumber()
+	var changes map[string]interface{}
+	if err := decoder.Decode(&changes); err != nil {
+		return result, err
+	}
+	data, err := json.Marshal(applyPatch(object, changes))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
+
+// applyPatch 将补丁合并到JSON对象上：值为null的字段被删除，对象递归合并，其他值直接替换
+func applyPatch(target, patch map[string]interface{}) map[string]interface{} {
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		patchObject, ok := value.(map[string]interface{})
+		if !ok {
+			target[key] = value
+			continue
+		}
+		targetObject, ok := target[key].(map[string]interface{})
+		if !ok {
+			targetObject = make(map[string]interface{})
+		}
+		target[key] = applyPatch(targetObject, patchObject)
+	}
+	return target
+}
@@ -5,6 +5,12 @@ type NetworkInfo struct {
 	// WiFi信息
 	WiFi WiFiInfo
 
+	// 附近无线网络扫描（需要--scan-wifi参数）
+	WiFiScan WiFiScanInfo
+
+	// 最近的WiFi连接、漫游和断开记录
+	WiFiHistory WiFiHistoryInfo
+
 	// 客户端信息
 	IP         string // 客户端IP地址
 	MacAddress string // 客户端MAC地址
@@ -17,12 +23,47 @@ type NetworkInfo struct {
 	AWDLEnabled bool   // AWDL是否启用
 
 	// 公网IP信息
-	PublicIP string // 公网出口IP
+	PublicIP       string   // 公网出口IP
+	PublicIPv6     string   // IPv6公网出口地址
+	PublicIPAgree  string   // 公网IP查询服务的一致情况，例如"3/4"
+	PublicIPOthers []string // 与多数结果不一致的出口地址，分流代理或多出口时会出现
+	PublicASN      string   // 公网IP所属的自治系统号
+	PublicISP      string   // 运营商或组织名称
+	PublicCountry  string   // 公网IP所在国家/地区代码
+	PublicRegion   string   // 公网IP所在省份和城市
+	EgressProvider string   // 公网IP属于已知的VPN/代理服务商时为服务商名称
 
 	// DNS信息
 	DNS        DNSConfigInfo
 	DNSServers []string // DNS服务器列表（兼容性字段）
 
+	// DNS服务器解析性能
+	DNSBenchmark []DNSBenchmarkInfo
+
+	// 关键域名的DNS诊断结果
+	DNSDiagnostics DNSDiagnosticsInfo
+
+	// 默认网关与互联网的连通性对比
+	Gateway GatewayInfo
+
+	// 路径MTU
+	MTU MTUInfo
+
+	// NAT类型
+	NAT NATInfo
+
+	// 局域网mDNS/Bonjour服务发现（需要--scan-lan参数）
+	MDNS MDNSInfo
+
+	// 有线网络连接的交换机端口，通过LLDP/CDP通告获取（需要--scan-switch参数）
+	Neighbors []NeighborInfo
+
+	// 各网络接口的DHCP租约
+	DHCPLeases []DHCPLeaseInfo
+
+	// IPv6连通性
+	IPv6 IPv6Info
+
 	// VPN信息
 	VPN VPNInfo
 
@@ -36,11 +77,40 @@ type NetworkInfo struct {
 	// 客户端路由表
 	RouteTable []RouteEntry // 路由表条目
 
+	// 监听中的端口
+	ListeningPorts []ListeningPortInfo
+
+	// 按进程和远端组织汇总的已建立连接
+	Connections []ConnectionGroupInfo
+
 	// 网卡流量
-	NetworkTraffic string // 网卡流量（KB/s）
+	NetworkTraffic   string                 // 所有网卡的收发速率合计（KB/s）
+	InterfaceTraffic []InterfaceTrafficInfo // 各网络接口的收发速率
 
 	// 各进程流量
-	ProcessTraffic string // 各进程流量（KB/s）
+	ProcessTraffic      string               // 流量最大的几个进程的摘要
+	ProcessTrafficStats []ProcessTrafficInfo // 采样窗口内各进程的收发字节数，按流量从大到小排序
+
+	// TCP重传和错误统计
+	TCPStats TCPStatsInfo
+
+	// HTTP/HTTPS端点检查
+	EndpointChecks []EndpointCheckInfo
+
+	// 常用SaaS服务的可达性
+	SaaS []SaaSSuiteInfo
+
+	// 出站端口可达性
+	PortMatrix PortMatrixInfo
+
+	// 负载下的响应能力（--network-quality）
+	Quality NetworkQualityInfo
+
+	// 综合延迟、丢包、DNS和端点检查结果的网络健康评分
+	HealthScore HealthScoreInfo
+
+	// 时间同步
+	TimeSync TimeSyncInfo
 }
 
 // WiFiInfo 表示WiFi信息
@@ -53,7 +123,9 @@ type WiFiInfo struct {
 	Noise          int     // 噪声（dBm）
 	Channel        int     // 频道
 	Frequency      float64 // 频率（GHz）
+	ChannelWidth   int     // 信道宽度（MHz）
 	PHYMode        string  // 物理层模式（如802.11ac）
+	Security       string  // 安全类型（如WPA2-Personal、WPA3-Enterprise、Open）
 	TxRate         int     // 传输速率（Mbps）
 	MCS            int     // MCS索引
 	NSS            int     // 空间流数量
@@ -61,6 +133,284 @@ type WiFiInfo struct {
 	SupportedPHY   string  // 支持的PHY模式
 }
 
+// WiFiScanInfo 表示附近无线网络的扫描结果和信道拥挤程度
+type WiFiScanInfo struct {
+	Networks   []WiFiScanResultInfo // 扫描到的无线网络，按信号强度从强到弱排序
+	Channels   []WiFiChannelInfo    // 有网络使用的信道，按频段和信道号排序
+	Summary    string               // 当前连接信道的拥挤程度评估
+	Suggestion string               // 建议使用的更空闲的信道
+}
+
+// WiFiScanResultInfo 表示扫描到的一个无线网络
+type WiFiScanResultInfo struct {
+	SSID     string // 网络名称，隐藏网络为空
+	BSSID    string // 基站MAC地址，系统不提供时为空
+	Channel  int    // 信道
+	Band     string // 频段（2.4GHz、5GHz或6GHz）
+	RSSI     int    // 信号强度（dBm）
+	Security string // 安全类型（如WPA2 Personal）
+}
+
+// WiFiChannelInfo 表示一个信道上的网络数量
+type WiFiChannelInfo struct {
+	Band      string // 频段
+	Channel   int    // 信道
+	Networks  int    // 使用该信道的网络数量
+	Adjacent  int    // 使用重叠的相邻信道的网络数量（仅2.4GHz）
+	Strongest int    // 该信道上最强的信号（dBm）
+}
+
+// WiFiHistoryInfo 表示一段时间内的WiFi连接、漫游和断开记录
+type WiFiHistoryInfo struct {
+	Since       string          // 统计开始时间
+	Connects    int             // 连接次数
+	Roams       int             // 漫游次数
+	Disconnects int             // 断开次数
+	Failures    int             // 连接失败次数
+	Events      []WiFiEventInfo // 最近的事件，按时间从新到旧排序
+}
+
+// WiFiEventInfo 表示一次WiFi连接、漫游或断开事件
+type WiFiEventInfo struct {
+	Time       string // 事件时间
+	Type       string // 事件类型（连接、漫游、断开、连接失败）
+	SSID       string // 网络名称，日志中没有时为空
+	BSSID      string // 基站MAC地址，日志中没有时为空
+	ReasonCode int    // 原因代码（macOS为802.11原因代码，Windows为WLAN原因代码）
+	Reason     string // 原因说明
+}
+
+// TimeSyncInfo 表示时间同步配置和时钟偏差
+type TimeSyncInfo struct {
+	Enabled         bool     // 是否启用网络时间同步
+	Servers         []string // 配置的NTP服务器
+	Source          string   // 当前同步源
+	LastSync        string   // 上次成功同步时间
+	ReferenceServer string   // 测量时钟偏差使用的参考服务器
+	Offset          float64  // 本机时钟与参考服务器的偏差（秒，正数表示本机偏快）
+	Status          string   // 偏差评估结果
+}
+
+// GatewayInfo 表示默认网关和互联网的延迟与丢包对比，用于区分局域网问题和运营商问题
+type GatewayInfo struct {
+	Address         string  // 默认网关地址
+	Interface       string  // 默认路由所在的网络接口
+	Latency         float64 // 网关平均延迟（毫秒）
+	Loss            float64 // 网关丢包率（%）
+	InternetTarget  string  // 互联网参考目标
+	InternetLatency float64 // 互联网平均延迟（毫秒）
+	InternetLoss    float64 // 互联网丢包率（%）
+	Assessment      string  // 评估结果
+}
+
+// MTUInfo 表示到探测目标的路径MTU和本机接口MTU
+type MTUInfo struct {
+	Target       string // 探测目标
+	Interface    string // 访问目标使用的网络接口
+	InterfaceMTU int    // 网络接口的MTU
+	PathMTU      int    // 设置不分片标志后能到达目标的最大数据包（字节）
+	Blackhole    bool   // 是否存在MTU黑洞（超过路径MTU的数据包被静默丢弃）
+	Assessment   string // 评估结果
+}
+
+// NATInfo 表示通过STUN检测到的外部映射地址和NAT类型
+type NATInfo struct {
+	LocalAddress  string   // 本机UDP地址（IP:端口）
+	MappedAddress string   // STUN服务器看到的外部地址（IP:端口）
+	Servers       []string // 成功响应的STUN服务器
+	Type          string   // NAT类型（无NAT、完全锥形、受限锥形、锥形、对称、UDP不可用）
+	Assessment    string   // 对语音和视频通话的影响
+}
+
+// MDNSInfo 表示局域网中通过mDNS发现的服务
+type MDNSInfo struct {
+	Services   []MDNSServiceInfo // 查询的服务类型及发现的实例
+	Responders []string          // 发送了应答的设备地址，不含本机
+	LocalOnly  bool              // 只收到本机的应答
+	Assessment string            // 组播是否被过滤的评估
+}
+
+// MDNSServiceInfo 表示一种mDNS服务类型的发现结果
+type MDNSServiceInfo struct {
+	Type        string   // 服务类型，例如_ipp._tcp
+	Description string   // 服务说明，例如打印机
+	Instances   []string // 发现的服务实例名称
+}
+
+// NeighborInfo 表示交换机通过LLDP或CDP通告的设备和端口信息
+type NeighborInfo struct {
+	Protocol          string // LLDP或CDP
+	Interface         string // 收到通告的本机网络接口，无法确定时为空
+	SwitchName        string // 交换机名称
+	ChassisID         string // 机箱ID，通常为交换机MAC地址
+	PortID            string // 交换机端口，例如GigabitEthernet1/0/12
+	PortDescription   string // 端口描述
+	VLAN              int    // 端口VLAN ID，交换机没有通告时为0
+	ManagementAddress string // 交换机管理地址
+	Description       string // 交换机型号或系统描述
+}
+
+// DHCPLeaseInfo 表示一个网络接口从DHCP服务器获取的租约和选项
+type DHCPLeaseInfo struct {
+	Interface      string   // 网络接口
+	IPAddress      string   // 分配的IP地址
+	Server         string   // DHCP服务器地址
+	LeaseObtained  string   // 获取租约的时间，无法获取时为空
+	LeaseExpires   string   // 租约到期时间，无法获取时为空
+	LeaseTime      int      // 租期（秒）
+	Routers        []string // 路由器（选项3）
+	DNSServers     []string // DNS服务器（选项6）
+	Domain         string   // 域名（选项15）
+	VendorSpecific string   // 厂商特定信息（选项43），十六进制
+	VendorClass    string   // 厂商类别标识（选项60）
+}
+
+// TCPStatsInfo 表示采样窗口内的TCP重传、重置和错误计数，以及开机以来的累计重传率
+type TCPStatsInfo struct {
+	SampleWindow        string  // 采样时长
+	SegmentsSent        uint64  // 发送的报文段
+	SegmentsReceived    uint64  // 接收的报文段
+	Retransmitted       uint64  // 重传的报文段
+	RetransmitRate      float64 // 重传率（%），重传数占发送数的比例
+	Resets              uint64  // 被重置的连接
+	Failures            uint64  // 建立失败的连接
+	Errors              uint64  // 校验和错误等无效报文段，系统不提供时为0
+	TotalRetransmitRate float64 // 开机以来的累计重传率（%）
+	Assessment          string  // 评估结果
+}
+
+// EndpointCheckInfo 表示一个HTTP/HTTPS端点的检查结果，耗时单位为毫秒
+type EndpointCheckInfo struct {
+	Name             string            // 端点名称
+	URL              string            // 请求的URL
+	Status           int               // HTTP状态码，请求失败时为0
+	RemoteAddr       string            // 实际连接的地址，使用代理时为代理服务器
+	DNSTime          float64           // 域名解析耗时
+	ConnectTime      float64           // TCP连接建立耗时
+	TLSTime          float64           // TLS握手耗时
+	TTFB             float64           // 从发送请求到收到第一个响应字节的耗时
+	TotalTime        float64           // 总耗时
+	TLSVersion       string            // 协商的TLS版本
+	Certificates     []CertificateInfo // 服务器证书链，第一个为服务器证书
+	CertificateValid bool              // 证书链是否被系统信任且与主机名匹配
+	CertificateError string            // 证书验证失败的原因
+	Intercepted      bool              // 证书是否被TLS解密代理（中间人）重新签发
+	InterceptedBy    string            // 重新签发证书的签发者
+	Error            string            // 请求失败的原因
+	Assessment       string            // 评估结果
+}
+
+// SaaSSuiteInfo 表示一个SaaS服务（例如Microsoft 365）各端点的检查结果
+type SaaSSuiteInfo struct {
+	Name       string              // 服务名称
+	Checks     []EndpointCheckInfo // 各端点的检查结果
+	Reachable  int                 // 可以访问的端点数量
+	AvgTime    float64             // 可以访问的端点的平均总耗时（毫秒）
+	Assessment string              // 评估结果
+}
+
+// PortMatrixInfo 表示出站TCP端口的可达性
+type PortMatrixInfo struct {
+	Checks     []PortCheckInfo // 各端口的检查结果
+	Blocked    []string        // 被拦截的端口，例如"SMTP提交 587"
+	Assessment string          // 评估结果
+}
+
+// PortCheckInfo 表示一个出站TCP端口的连接结果
+type PortCheckInfo struct {
+	Name   string  // 名称，例如HTTPS、远程桌面
+	Host   string  // 目标主机
+	Port   int     // 目标端口
+	Status string  // 开放、拒绝、超时、解析失败
+	Open   bool    // 连接是否建立
+	Time   float64 // 连接建立耗时（毫秒）
+	Error  string  // 连接失败的原因
+}
+
+// NetworkQualityInfo 表示下载占满带宽时的网络响应能力，负载下延迟远高于空闲延迟说明存在缓冲区膨胀
+type NetworkQualityInfo struct {
+	Source        string  // 测试方式：networkQuality或内置的负载下延迟测试
+	RPM           int     // 负载下每分钟可以完成的往返次数（Round-trips Per Minute），越高越好
+	Rating        string  // 响应能力等级（高、中、低）
+	IdleLatency   float64 // 空闲时延迟（毫秒）
+	LoadedLatency float64 // 负载下延迟（毫秒）
+	Bufferbloat   float64 // 负载下延迟比空闲时增加的部分（毫秒）
+	Download      float64 // 下载速率（Mbps）
+	Upload        float64 // 上传速率（Mbps），内置测试不测量上传
+	Error         string  // 测试失败的原因
+	Assessment    string  // 评估结果
+}
+
+// HealthScoreInfo 表示网络健康评分，满分100分
+type HealthScoreInfo struct {
+	Score      int      // 评分（0-100）
+	Grade      string   // 等级（良好、一般、较差、很差）
+	Deductions []string // 扣分项及原因
+	Verdict    string   // 一句话结论
+}
+
+// IPv6Info 表示IPv6地址和连通性
+type IPv6Info struct {
+	Addresses     []IPv6AddressInfo // 本机IPv6地址
+	DefaultRoute  bool              // 是否有IPv6默认路由
+	Gateway       string            // IPv6默认网关
+	Interface     string            // 默认路由所在的网络接口
+	SourceAddress string            // 访问公网时使用的IPv6源地址
+	Egress        bool              // IPv6出口是否可用
+	IPv6Latency   float64           // 通过IPv6建立TCP连接的平均耗时（毫秒）
+	IPv4Latency   float64           // 通过IPv4建立TCP连接的平均耗时（毫秒）
+	Assessment    string            // 评估结果
+}
+
+// IPv6AddressInfo 表示一个IPv6地址
+type IPv6AddressInfo struct {
+	Interface string // 网络接口
+	Address   string // 地址
+	Scope     string // 范围（全局单播、唯一本地、链路本地）
+}
+
+// ListeningPortInfo 表示一个处于监听状态的套接字
+type ListeningPortInfo struct {
+	Protocol string // 协议（TCP、TCP6、UDP、UDP6）
+	Address  string // 监听地址
+	Port     uint32 // 端口
+	PID      int32  // 所属进程ID
+	Process  string // 进程名称
+	Path     string // 可执行文件路径
+	Signer   string // 代码签名者（macOS为签名证书，Windows为Authenticode发布者）
+	Exposed  bool   // 是否可以从其他主机访问（未绑定到回环地址）
+}
+
+// ConnectionGroupInfo 表示一个进程到同一远端组织的已建立连接
+type ConnectionGroupInfo struct {
+	Process     string   // 进程名称
+	PIDs        []int32  // 进程ID
+	Remote      string   // 远端组织（ASN名称、反向解析的域名、局域网或IP）
+	ASN         string   // 远端自治系统号
+	Connections int      // 连接数
+	Hosts       []string // 远端主机名或IP
+	Ports       []uint32 // 远端端口
+}
+
+// InterfaceTrafficInfo 表示一个网络接口的流量
+type InterfaceTrafficInfo struct {
+	Name      string  // 接口名称
+	RxRate    float64 // 接收速率（字节/秒）
+	TxRate    float64 // 发送速率（字节/秒）
+	BytesRecv uint64  // 累计接收字节数
+	BytesSent uint64  // 累计发送字节数
+}
+
+// ProcessTrafficInfo 表示采样窗口内一个进程的网络流量
+type ProcessTrafficInfo struct {
+	PID      int32   // 进程ID
+	Process  string  // 进程名称
+	BytesIn  uint64  // 接收的字节数
+	BytesOut uint64  // 发送的字节数
+	RateIn   float64 // 接收速率（字节/秒）
+	RateOut  float64 // 发送速率（字节/秒）
+}
+
 // DNSConfigInfo 表示DNS配置信息
 type DNSConfigInfo struct {
 	Servers         []string    // DNS服务器列表
@@ -69,6 +419,20 @@ type DNSConfigInfo struct {
 	HostsFile       string      // hosts文件内容
 	ResolvConfFile  string      // resolv.conf文件内容
 	HostEntries     []HostEntry // hosts条目
+
+	Resolvers []DNSResolverInfo // 按接口和作用域区分的DNS解析器
+	Findings  []string          // VPN推送的DNS与DHCP获取的DNS之间的冲突等问题
+}
+
+// DNSResolverInfo 表示一个接口或作用域的DNS解析器
+type DNSResolverInfo struct {
+	Interface     string   // 网络接口，例如en0、utun4、以太网
+	Scope         string   // 作用域：默认、指定域名或接口限定
+	Domain        string   // 只用于解析该域名及其子域名，为空表示所有域名
+	Servers       []string // DNS服务器
+	SearchDomains []string // 搜索域
+	Order         int      // 解析顺序（macOS）或接口跃点数（Windows），数值小的优先
+	VPN           bool     // 是否为VPN隧道接口的解析器
 }
 
 // HostEntry 表示hosts文件中的条目
@@ -77,6 +441,52 @@ type HostEntry struct {
 	Hostname string // 主机名
 }
 
+// DNSBenchmarkInfo 表示一个DNS服务器的解析性能
+type DNSBenchmarkInfo struct {
+	Server   string               // DNS服务器地址
+	Source   string               // 来源（系统、配置文件）
+	Queries  int                  // 查询次数
+	Failures int                  // 返回SERVFAIL、REFUSED等错误响应或查询失败的次数
+	Timeouts int                  // 超时次数
+	AvgTime  float64              // 收到应答的查询的平均耗时（毫秒）
+	MinTime  float64              // 最短耗时（毫秒）
+	MaxTime  float64              // 最长耗时（毫秒）
+	Status   string               // 评估结果（正常、较慢、部分失败、不可用）
+	Results  []DNSQueryResultInfo // 每个测试域名的查询结果
+}
+
+// DNSQueryResultInfo 表示一次DNS查询的结果
+type DNSQueryResultInfo struct {
+	Name   string  // 查询的域名
+	Time   float64 // 耗时（毫秒），超时或失败时为0
+	Status string  // 响应码（NOERROR、NXDOMAIN等），或超时、错误信息
+}
+
+// DNSDiagnosticsInfo 表示DNS诊断结果
+type DNSDiagnosticsInfo struct {
+	Domains  []DNSDomainDiagInfo // 关键域名的诊断结果
+	Findings []string            // 与具体域名无关的发现，例如NXDOMAIN劫持
+}
+
+// DNSDomainDiagInfo 表示一个关键域名在各DNS服务器上的解析结果
+type DNSDomainDiagInfo struct {
+	Domain   string                  // 域名
+	Answers  []DNSResolverAnswerInfo // 各DNS服务器的应答
+	Findings []string                // 诊断发现（分区解析、解析失败、疑似劫持等）
+}
+
+// DNSResolverAnswerInfo 表示一个DNS服务器对域名的应答
+type DNSResolverAnswerInfo struct {
+	Server string   // DNS服务器地址
+	Source string   // 来源（系统、配置文件）
+	RCode  string   // 响应码
+	Time   float64  // 最长查询耗时（毫秒）
+	A      []string // IPv4地址
+	AAAA   []string // IPv6地址
+	CNAME  []string // 别名
+	Error  string   // 所有查询都失败时的错误信息
+}
+
 // DNSInfo 表示DNS信息（兼容性结构体）
 type DNSInfo struct {
 	Servers []string // DNS服务器列表
@@ -96,6 +506,38 @@ type VPNInfo struct {
 	Interfaces       []string      // 接口列表
 	NodeInfos        []VPNNodeInfo // 节点详细信息
 	ConfigFile       string        // 配置文件路径
+
+	Clients     []VPNClientInfo // 检测到的VPN客户端
+	SplitTunnel SplitTunnelInfo // VPN连接时的隧道路由分析
+}
+
+// SplitTunnelInfo 表示VPN连接时哪些目标经过隧道、哪些直接经过物理接口
+type SplitTunnelInfo struct {
+	Mode             string              // 全隧道、分离隧道，或没有路由经过隧道
+	Interfaces       []string            // 隧道接口
+	DefaultInterface string              // 默认路由使用的接口
+	TunnelRoutes     []string            // 经过隧道的网段
+	BypassRoutes     []string            // 全隧道时仍然经过物理接口的网段，例如本地局域网和VPN服务器
+	Subnets          []SplitTunnelSubnet // 配置的公司网段使用的接口
+	Assessment       string              // 评估结论
+}
+
+// SplitTunnelSubnet 表示一个公司网段的路由
+type SplitTunnelSubnet struct {
+	Subnet    string // 网段
+	Route     string // 匹配的路由
+	Interface string // 使用的接口
+	Tunneled  bool   // 是否经过隧道
+}
+
+// VPNClientInfo 表示检测到的VPN客户端
+type VPNClientInfo struct {
+	Provider  string // 客户端，例如WireGuard、Tailscale、GlobalProtect
+	Running   bool   // 客户端进程或网络扩展是否在运行
+	Connected bool   // 隧道是否已建立
+	Interface string // 隧道接口，例如utun4、wg0
+	Node      string // 连接的节点，例如服务器地址、门户或出口节点
+	Source    string // 检测依据，例如scutil、进程、网卡
 }
 
 // VPNNodeInfo 表示VPN节点信息
@@ -110,20 +552,38 @@ type LatencyInfo struct {
 	AvgLatency  float64             // 平均延迟（ms）
 	Targets     []TargetLatencyInfo // 延迟目标列表
 	NetworkHops []NetworkHopInfo    // 网络跳点信息
-	Jitter      float64             // 抖动（毫秒）
+	Jitter      float64             // 可达目标的平均抖动（毫秒）
 	PacketLoss  float64             // 丢包率（百分比）
 }
 
 // TargetLatencyInfo 表示目标延迟信息
 type TargetLatencyInfo struct {
-	TargetName string  // 目标名称
-	TargetHost string  // 目标主机
-	MinLatency float64 // 最小延迟（ms）
-	AvgLatency float64 // 平均延迟（ms）
-	MaxLatency float64 // 最大延迟（ms）
-	PacketLoss float64 // 丢包率（%）
-	StdDev     float64 // 标准差（毫秒）
-	Jitter     float64 // 抖动（毫秒）
+	Group      string       // 探测分组
+	TargetName string       // 目标名称
+	TargetHost string       // 目标主机
+	Protocol   string       // 探测协议（icmp、tcp、http）
+	Port       int          // 探测端口（tcp、http）
+	MinLatency float64      // 最小延迟（ms）
+	AvgLatency float64      // 平均延迟（ms）
+	MaxLatency float64      // 最大延迟（ms）
+	P50        float64      // 往返时延中位数（ms）
+	P95        float64      // 往返时延第95百分位数（ms）
+	P99        float64      // 往返时延第99百分位数（ms）
+	PacketLoss float64      // 丢包率（%）
+	StdDev     float64      // 往返时延标准差（毫秒）
+	Jitter     float64      // 抖动，相邻往返时延之差的平均绝对值（毫秒）
+	RTTs       []float64    // 每个数据包的往返时延（毫秒），丢失的数据包为-1
+	Method     string       // 探测实现方式（raw、udp、icmpapi、tcp、http）
+	Warnings   []string     // 超出配置阈值的项目
+	Outages    []OutageInfo // 连续丢包造成的中断（持续监控模式）
+}
+
+// OutageInfo 表示一次连续丢包造成的中断
+type OutageInfo struct {
+	Start    string  // 第一个丢失的数据包的发送时间
+	End      string  // 恢复后第一个收到应答的数据包的发送时间，未恢复时为空
+	Duration float64 // 中断时长（秒）
+	Lost     int     // 连续丢失的数据包数
 }
 
 // NetworkHopInfo 表示网络跳点信息
@@ -158,6 +618,40 @@ type ProxyInfo struct {
 	Enabled bool   // 是否启用
 	Server  string // 服务器地址
 	Port    int    // 端口
+
+	Settings []ProxySettingInfo // 各网络服务或配置来源中启用的代理
+	PAC      []PACInfo          // 自动代理配置（PAC）文件摘要
+	WPAD     bool               // 是否启用自动发现代理（WPAD）
+	Bypass   []string           // 不经过代理的主机和网段
+
+	Environment     []ProxyEnvInfo // 代理相关的环境变量和shell配置文件中的设置
+	EnvironmentNote string         // 命令行工具与图形界面应用的代理设置不一致时的说明
+}
+
+// ProxyEnvInfo 表示一个代理相关的环境变量
+type ProxyEnvInfo struct {
+	Name   string // 变量名，例如HTTPS_PROXY
+	Value  string // 变量值，地址中的密码已替换为******
+	Source string // 当前环境，或设置该变量的配置文件和行号
+}
+
+// ProxySettingInfo 表示一项启用的代理设置
+type ProxySettingInfo struct {
+	Source string // 网络服务名称（macOS）或配置来源（Windows的WinINET、WinHTTP）
+	Type   string // HTTP、HTTPS、SOCKS、PAC或WPAD
+	Server string // 代理服务器地址，PAC为文件地址
+	Port   int    // 代理端口
+}
+
+// PACInfo 表示PAC文件的内容摘要
+type PACInfo struct {
+	URL     string   // 文件地址
+	Source  string   // 来源：手动配置或WPAD自动发现
+	Size    int      // 文件大小（字节）
+	Rules   int      // 条件判断的数量（shExpMatch、dnsDomainIs、isInNet等）
+	Proxies []string // 文件中出现的代理，例如"PROXY proxy.example.com:8080"
+	Direct  bool     // 是否有直连规则
+	Error   string   // 下载失败或不是有效PAC文件的原因
 }
 
 // RouteEntry 表示路由表条目
@@ -2,28 +2,63 @@ package model
 
 // SystemInfo 表示收集的系统信息的总体结构
 type SystemInfo struct {
-	Hostname      string
-	OS            string
-	Model         string
-	ModelID       string
-	SerialNumber  string
-	UUID          string
-	CPU           CPUInfo
-	Memory        MemoryInfo
-	Disks         []Disk
-	DiskUsage     []DiskPartitionInfo
-	MemoryUsage   MemoryUsageInfo
-	Battery       BatteryInfo
-	ACAdapter     ACAdapterInfo
-	Bluetooth     BluetoothInfo
-	Temperature   []TempSensorInfo
-	Network       NetworkInfo      // 网络信息
-	WiFiAutoJoin  WiFiAutoJoinInfo // WiFi自动连接状态
-	SystemVersion string
-	ComputerName  string
-	UpTime        string
-	InstalledApps []AppInfo
-	RunningApps   []ProcessInfo
+	Hostname         string
+	OS               string
+	Model            string
+	ModelID          string
+	SerialNumber     string
+	UUID             string
+	DeviceID         string // 设备ID，由硬件UUID加盐哈希得到，用于关联同一台设备的多次报告
+	CPU              CPUInfo
+	Memory           MemoryInfo
+	Disks            []Disk
+	DiskEncryption   DiskEncryptionInfo    // 启动盘加密信息
+	Drivers          []DriverInfo          // 第三方内核扩展、系统扩展和驱动程序
+	PCIDevices       []PCIDeviceInfo       // PCIe/雷雳设备列表
+	ExternalStorage  []ExternalStorageInfo // 外接和可移动存储（U盘、SD卡等）
+	SecurityHardware SecurityHardwareInfo  // 安全硬件信息
+	Firmware         FirmwareInfo          // 固件信息
+	DiskUsage        []DiskPartitionInfo
+	DiskTrend        DiskTrendInfo // 磁盘使用趋势（需要本地历史数据）
+	DiskScan         DiskScanInfo  // 深度磁盘空间分析（需要--scan-disk参数）
+	MemoryUsage      MemoryUsageInfo
+	Battery          BatteryInfo
+	Display          DisplayInfo // 显示器亮度信息
+	ACAdapter        ACAdapterInfo
+	Bluetooth        BluetoothInfo
+	InputDevices     InputDevicesInfo // 输入设备信息
+	Temperature      []TempSensorInfo
+	Network          NetworkInfo      // 网络信息
+	WiFiAutoJoin     WiFiAutoJoinInfo // WiFi自动连接状态
+	SystemVersion    string
+	ComputerName     string
+	UpTime           string
+	Locale           LocaleInfo   // 时区、区域和输入法
+	Environment      []EnvVarInfo // 关键环境变量（敏感值已脱敏）
+	InstalledApps    []AppInfo
+	Browsers         BrowsersInfo             // 浏览器信息
+	DefaultHandlers  []DefaultHandlerInfo     // 协议和文件类型的默认打开程序
+	DevTools         []DevToolInfo            // 开发运行时和包管理器
+	Services         []ServiceInfo            // 系统服务（launchd守护进程/代理、Windows服务）
+	StartupItems     []StartupItemInfo        // 登录/开机启动项
+	ScheduledTasks   []ScheduledTaskInfo      // 计划任务
+	Hotfixes         []HotfixInfo             // 已安装的补丁（Windows）
+	Activation       ActivationInfo           // Windows激活和许可证信息
+	EventLog         EventLogInfo             // 最近24小时的严重和错误事件（Windows）
+	BootHistory      []BootEventInfo          // 最近的启动和关机记录
+	Firewall         FirewallInfo             // 主机防火墙状态
+	Antivirus        []EndpointProtectionInfo // 防病毒/EDR产品
+	PlatformSecurity PlatformSecurityInfo     // macOS平台安全设置
+	LocalAccounts    []LocalAccountInfo       // 本地用户账户
+	Identity         IdentityInfo             // 目录/身份加入状态
+	Management       ManagementInfo           // MDM管理状态和配置描述文件
+	Certificates     []CertificateInfo        // 企业访问相关的证书
+	RemoteAccess     []RemoteAccessInfo       // 远程访问服务状态
+	FileSharing      FileSharingInfo          // 文件共享状态
+	Workloads        []WorkloadInfo           // 运行中的容器和虚拟机
+	SearchIndex      SearchIndexInfo          // Spotlight/Windows Search索引状态
+	ResourceHogs     ResourceHogsInfo         // 资源占用最高的进程
+	RunningApps      []ProcessInfo
 }
 
 // CPUInfo 表示处理器信息
@@ -36,6 +71,47 @@ type CPUInfo struct {
 type MemoryInfo struct {
 	Total uint64 // 总内存容量（字节）
 	Type  string // 内存类型（如LPDDR5, DDR4等）
+
+	Modules     []MemoryModule // 各插槽内存条信息
+	Upgradeable bool           // 内存是否可升级
+	Mismatched  bool           // 各内存条容量、速度或型号是否不一致
+}
+
+// MemoryModule 表示单个插槽上的内存条
+type MemoryModule struct {
+	Slot         string // 插槽位置
+	Size         uint64 // 容量（字节）
+	Speed        int    // 速度（MT/s）
+	Type         string // 内存类型
+	Manufacturer string // 制造商
+	PartNumber   string // 型号
+}
+
+// SecurityHardwareInfo 表示安全硬件信息
+type SecurityHardwareInfo struct {
+	SecurityChip     string // 安全芯片（如Apple T2 Security Chip、Apple Silicon）
+	HasSecureEnclave bool   // 是否具备安全隔区（Secure Enclave）
+	TouchIDPresent   bool   // 是否具备Touch ID传感器
+	TouchIDEnrolled  int    // 当前用户已录入的指纹数量
+	SecurityMode     string // 启动安全模式（Full/Reduced/Permissive Security）
+	TPMPresent       bool   // 是否存在TPM
+	TPMUnknown       bool   // 是否无法查询TPM（例如没有管理员权限），为true时TPMPresent没有意义
+	TPMVersion       string // TPM规范版本（1.2/2.0）
+	TPMManufacturer  string // TPM制造商
+	TPMReady         bool   // TPM是否已就绪（已启用、已激活、已获取所有权）
+}
+
+// FirmwareInfo 表示BIOS/UEFI固件信息
+type FirmwareInfo struct {
+	Vendor          string // 固件厂商
+	Version         string // 固件版本（Windows为BIOS版本，macOS为System Firmware Version）
+	ReleaseDate     string // 固件发布日期
+	Mode            string // 启动模式（UEFI/Legacy）
+	SecureBoot      string // 安全启动状态（已启用/未启用/不支持）
+	BootROMVersion  string // Boot ROM版本（macOS）
+	SMCVersion      string // SMC版本（Intel Mac）
+	BridgeFirmware  string // iBridge/T2固件版本（macOS）
+	OSLoaderVersion string // OS Loader版本（macOS）
 }
 
 // Disk 表示存储设备信息
@@ -46,6 +122,54 @@ type Disk struct {
 	Model  string // 设备型号
 }
 
+// DiskEncryptionInfo 表示启动盘的加密信息，硬件加密与软件加密分开记录
+type DiskEncryptionInfo struct {
+	HardwareEncrypted bool   // 是否由硬件加密（T2/Apple Silicon、OPAL/eDrive）
+	HardwareMethod    string // 硬件加密方式
+	SoftwareName      string // 软件加密名称（FileVault/BitLocker）
+	SoftwareEnabled   bool   // 软件加密是否开启
+	SoftwareStatus    string // 软件加密状态描述
+	Unknown           bool   // 是否无法查询加密状态（例如manage-bde需要管理员权限），为true时其他字段没有意义
+}
+
+// PCIDeviceInfo 表示PCIe或雷雳连接的设备
+type PCIDeviceInfo struct {
+	Name      string // 设备名称
+	Type      string // 设备类型
+	Vendor    string // 厂商
+	VendorID  string // 厂商ID
+	DeviceID  string // 设备ID
+	Slot      string // 插槽或连接位置
+	LinkWidth string // 链路宽度（如x4）
+	LinkSpeed string // 链路速度（如8.0 GT/s）
+	Driver    bool   // 是否已安装驱动
+}
+
+// DriverInfo 表示内核扩展、系统扩展或驱动程序信息
+type DriverInfo struct {
+	Name       string // 名称或Bundle ID
+	Kind       string // 类型（内核扩展、系统扩展、驱动程序）
+	Version    string // 版本
+	Provider   string // 提供商或Team ID
+	State      string // 状态
+	Signature  string // 签名状态（已签名、未签名、未知）
+	Deprecated bool   // 是否为已弃用的技术（如macOS第三方内核扩展）
+}
+
+// ExternalStorageInfo 表示外接或可移动存储上的一个卷
+type ExternalStorageInfo struct {
+	Name       string // 卷名
+	Device     string // 设备标识（如disk4s1、E:）
+	Model      string // 设备型号
+	Bus        string // 总线类型（USB、SD等）
+	MountPoint string // 挂载点
+	Filesystem string // 文件系统
+	Total      uint64 // 总容量（字节）
+	Free       uint64 // 可用容量（字节）
+	Encrypted  bool   // 是否加密
+	Encryption string // 加密状态描述
+}
+
 // DiskPartitionInfo 表示硬盘分区信息
 type DiskPartitionInfo struct {
 	MountPoint string  // 挂载点
@@ -56,6 +180,31 @@ type DiskPartitionInfo struct {
 	UsedPerc   float64 // 使用百分比
 }
 
+// DiskTrendInfo 表示根据历史采样计算的磁盘使用趋势
+type DiskTrendInfo struct {
+	MountPoint    string  // 挂载点
+	Samples       int     // 参与计算的历史采样数
+	Since         string  // 最早采样时间
+	DailyGrowth   float64 // 每日增长量（字节/天）
+	DaysUntilFull float64 // 预计写满天数，增长为0或负数时为0
+}
+
+// DiskScanInfo 表示深度磁盘空间分析结果
+type DiskScanInfo struct {
+	Root        string          // 扫描的根目录（用户主目录）
+	Duration    string          // 扫描耗时
+	LargestDirs []DiskUsageItem // 占用空间最大的目录
+	SpaceHogs   []DiskUsageItem // 已知的空间占用大户（缓存、虚拟磁盘等）
+}
+
+// DiskUsageItem 表示一个目录或文件的实际占用空间
+type DiskUsageItem struct {
+	Name string // 名称
+	Path string // 路径
+	Size uint64 // 实际占用空间（字节），稀疏文件和仅联机文件按实际分配计算
+	Hint string // 清理建议
+}
+
 // MemoryUsageInfo 表示内存使用情况
 type MemoryUsageInfo struct {
 	Total    uint64  // 总容量（字节）
@@ -76,6 +225,20 @@ type BatteryInfo struct {
 	Health        string // 电池健康状态
 	Status        string // 电池状态
 	TimeRemaining int    // 剩余使用时间（分钟）
+
+	DesignCapacity     int     // 设计容量（mAh或mWh，取决于平台）
+	FullChargeCapacity int     // 当前满充容量（与设计容量单位一致）
+	HealthPercent      float64 // 满充容量占设计容量的百分比
+	WearPercent        float64 // 损耗百分比（100 - HealthPercent）
+	HealthVerdict      string  // 归一化的健康结论（良好/一般/建议更换）
+	Advice             string  // 更换建议
+	LowPowerMode       bool    // 是否开启低电量模式（macOS）/节电模式（Windows）
+}
+
+// DisplayInfo 表示内置显示器的亮度设置
+type DisplayInfo struct {
+	Brightness     float64 // 当前亮度（百分比），无法获取时为0
+	AutoBrightness bool    // 是否开启自动调节亮度
 }
 
 // ACAdapterInfo 表示交流充电器信息
@@ -86,6 +249,13 @@ type ACAdapterInfo struct {
 	Name        string // 名称
 	Wattage     int    // 功率（瓦）
 	ChipModel   string // 芯片型号
+
+	NegotiatedVoltage float64 // 协商电压（V），Windows没有公开USB-C PD协商结果，始终为0
+	NegotiatedCurrent float64 // 协商电流（A）
+	NegotiatedWattage float64 // 协商功率（W）
+	BatteryChargeRate float64 // 电池的充电速率（W），Windows上代替协商功率提供，不等于充电器的输出功率
+	RatedWattage      int     // 本机额定充电功率（W），未知时为0
+	ChargingSlowly    bool    // 协商功率是否低于本机额定功率
 }
 
 // BluetoothInfo 表示蓝牙信息
@@ -107,6 +277,22 @@ type BTDeviceInfo struct {
 	Connected bool   // 是否已连接
 }
 
+// InputDevicesInfo 表示输入设备信息，内置设备与外接设备分开记录
+type InputDevicesInfo struct {
+	BuiltIn  []InputDeviceInfo // 内置键盘/触控板/触摸屏
+	External []InputDeviceInfo // 外接HID设备（USB、蓝牙等）
+}
+
+// InputDeviceInfo 表示单个输入设备
+type InputDeviceInfo struct {
+	Name      string // 设备名称
+	Type      string // 设备类型（键盘、触控板、触摸屏、鼠标）
+	Transport string // 连接方式（SPI、USB、Bluetooth、I2C等）
+	Vendor    string // 厂商
+	Status    string // 驱动状态
+	BuiltIn   bool   // 是否为内置设备
+}
+
 // TempSensorInfo 表示温度传感器信息
 type TempSensorInfo struct {
 	Name        string  // 传感器名称
@@ -116,12 +302,301 @@ type TempSensorInfo struct {
 	Value       float64 // 温度值（兼容性字段）
 }
 
+// LocaleInfo 表示时区、区域语言和键盘输入源
+type LocaleInfo struct {
+	TimeZone     string   // 时区（如Asia/Shanghai）
+	UTCOffset    string   // 与UTC的偏移（如+08:00）
+	Locale       string   // 区域设置（如zh_CN）
+	Languages    []string // 首选语言列表
+	InputSources []string // 已启用的键盘布局和输入法
+}
+
+// EnvVarInfo 表示一个环境变量
+type EnvVarInfo struct {
+	Name   string // 变量名
+	Value  string // 变量值，敏感内容已替换为******
+	Masked bool   // 是否进行了脱敏
+}
+
 // AppInfo 表示应用信息
 type AppInfo struct {
 	Name        string // 应用名称
 	Version     string // 版本
 	InstallDate string // 安装日期
 	Path        string // 安装路径
+	Source      string // 来源（应用程序目录、Mac App Store、Homebrew等）
+	BundleID    string // Bundle ID（macOS）
+}
+
+// BrowsersInfo 表示已安装的浏览器及系统默认浏览器
+type BrowsersInfo struct {
+	Default   string        // 系统默认浏览器
+	Installed []BrowserInfo // 已安装的浏览器
+}
+
+// BrowserInfo 表示单个浏览器信息
+type BrowserInfo struct {
+	Name       string // 浏览器名称
+	Version    string // 版本
+	Path       string // 安装路径
+	IsDefault  bool   // 是否为默认浏览器
+	Extensions int    // 扩展数量（默认配置文件，无法读取时为-1）
+	Proxy      string // 代理配置（无法读取时为空）
+}
+
+// DefaultHandlerInfo 表示协议或文件类型的默认打开程序
+type DefaultHandlerInfo struct {
+	Type          string // 协议（http、mailto）或文件扩展名（.pdf）
+	Handler       string // 默认程序名称
+	ID            string // Bundle ID（macOS）或ProgId（Windows）
+	SystemDefault bool   // 用户未修改，使用系统默认程序
+}
+
+// DevToolInfo 表示开发运行时或包管理器
+type DevToolInfo struct {
+	Name    string // 工具名称
+	Version string // 版本
+	Path    string // 可执行文件或安装路径
+}
+
+// ServiceInfo 表示系统服务信息
+type ServiceInfo struct {
+	Name        string // 服务名称（launchd标签或Windows服务名）
+	DisplayName string // 显示名称
+	Kind        string // 类型（守护进程、代理、服务）
+	StartType   string // 启动类型（自动、手动、按需、已禁用）
+	State       string // 运行状态（运行中、已停止等）
+	PID         int    // 进程ID（未运行时为0）
+	Path        string // 可执行文件路径
+}
+
+// StartupItemInfo 表示登录或开机时自动启动的项目
+type StartupItemInfo struct {
+	Name     string // 名称
+	Location string // 所在位置（登录项、LaunchAgents目录、注册表键、启动文件夹）
+	Target   string // 启动目标（可执行文件路径或命令行）
+}
+
+// ScheduledTaskInfo 表示计划任务信息
+type ScheduledTaskInfo struct {
+	Name       string // 任务名称
+	Source     string // 来源（任务计划程序、cron、periodic、launchd）
+	Trigger    string // 触发条件
+	Command    string // 执行的命令
+	LastRun    string // 上次运行时间
+	LastResult string // 上次运行结果
+}
+
+// HotfixInfo 表示已安装的Windows补丁
+type HotfixInfo struct {
+	ID          string // 补丁编号（如KB5034441）
+	Description string // 描述（如Security Update）
+	InstalledOn string // 安装日期
+	InstalledBy string // 安装者
+}
+
+// ActivationInfo 表示Windows激活状态和许可证渠道
+type ActivationInfo struct {
+	Product        string // 许可证对应的产品（如Windows(R), Professional edition）
+	Status         string // 激活状态（已激活、未激活、宽限期等）
+	Channel        string // 许可证渠道（Retail、OEM、Volume:MAK、Volume:KMS）
+	PartialKey     string // 产品密钥的后5位
+	KMSHost        string // KMS服务器（手动指定或通过DNS发现）
+	GraceRemaining int    // 宽限期剩余天数
+}
+
+// EventLogInfo 表示系统和应用程序日志中严重和错误事件的统计
+type EventLogInfo struct {
+	Since    string           // 统计起始时间
+	Critical int              // 严重事件数量
+	Errors   int              // 错误事件数量
+	Logs     []EventCountInfo // 按日志分类的统计
+	Recent   []EventEntryInfo // 最近的事件（从新到旧）
+}
+
+// EventCountInfo 表示单个日志的事件数量
+type EventCountInfo struct {
+	Log      string // 日志名称（System、Application）
+	Critical int    // 严重事件数量
+	Errors   int    // 错误事件数量
+}
+
+// EventEntryInfo 表示一条事件日志记录
+type EventEntryInfo struct {
+	Time     string // 事件时间
+	Log      string // 日志名称
+	Level    string // 级别（严重、错误）
+	Source   string // 事件来源
+	EventID  int    // 事件ID
+	Category string // 问题分类（磁盘错误、服务崩溃、意外关机、应用崩溃等）
+	Message  string // 事件消息的第一行
+}
+
+// BootEventInfo 表示一次启动或关机事件
+type BootEventInfo struct {
+	Time   string // 事件时间
+	Type   string // 类型（启动、关机、重启）
+	Reason string // 原因（正常关机、系统更新、系统崩溃、意外断电等）
+	Detail string // 详细信息（发起进程、关机代码等）
+}
+
+// FirewallInfo 表示主机防火墙状态
+type FirewallInfo struct {
+	Enabled           bool                  // 防火墙是否启用（Windows为任一配置文件启用）
+	StealthMode       bool                  // 隐身模式（macOS）
+	BlockAllIncoming  bool                  // 阻止所有传入连接（macOS）
+	PFEnabled         bool                  // pf包过滤是否启用（macOS）
+	Profiles          []FirewallProfileInfo // 防火墙配置文件状态（Windows）
+	InboundAllowRules int                   // 允许传入连接的规则或应用数量
+}
+
+// FirewallProfileInfo 表示Windows Defender防火墙配置文件状态
+type FirewallProfileInfo struct {
+	Name            string // 配置文件名称（Domain、Private、Public）
+	Enabled         bool   // 是否启用
+	DefaultInbound  string // 默认入站操作
+	DefaultOutbound string // 默认出站操作
+}
+
+// EndpointProtectionInfo 表示防病毒或EDR产品信息
+type EndpointProtectionInfo struct {
+	Name        string // 产品名称
+	Kind        string // 类型（防病毒、EDR）
+	Version     string // 版本
+	Running     bool   // 代理是否正在运行
+	RealTime    string // 实时保护状态（已启用、未启用、未知）
+	Definitions string // 病毒库状态（最新、已过期，未知时为空）
+}
+
+// PlatformSecurityInfo 表示macOS平台安全设置
+type PlatformSecurityInfo struct {
+	SIPStatus       string // 系统完整性保护（SIP）状态
+	Gatekeeper      string // Gatekeeper状态
+	XProtectVersion string // XProtect版本
+	ThirdPartyKexts string // 是否允许加载第三方内核扩展
+}
+
+// LocalAccountInfo 表示本地用户账户信息
+type LocalAccountInfo struct {
+	Name       string // 用户名
+	FullName   string // 全名
+	ID         string // 用户标识（macOS为UID，Windows为SID）
+	IsAdmin    bool   // 是否为管理员
+	Disabled   bool   // 账户是否已禁用
+	NoPassword bool   // 是否未设置密码或禁用了密码登录
+	LastLogin  string // 上次登录时间
+}
+
+// IdentityInfo 表示目录服务和身份加入状态
+type IdentityInfo struct {
+	DomainJoined     bool   // 是否加入AD域
+	Domain           string // AD域名
+	AzureADJoined    bool   // 是否加入Azure AD/Entra ID（Windows）
+	WorkplaceJoined  bool   // 是否已注册到Azure AD/Entra ID（Windows）
+	EnterpriseJoined bool   // 是否加入本地企业设备注册服务（Windows）
+	TenantName       string // Azure AD/Entra租户名称
+	TenantID         string // Azure AD/Entra租户ID
+	PlatformSSO      string // 平台单点登录状态（macOS）
+}
+
+// ManagementInfo 表示MDM注册状态和已安装的配置描述文件
+type ManagementInfo struct {
+	Enrolled   bool                // 是否已注册MDM
+	Provider   string              // MDM提供商或注册方式
+	ServerURL  string              // MDM服务器地址
+	Compliance string              // 合规状态（Windows）
+	Profiles   []ConfigProfileInfo // 已安装的配置描述文件（macOS）
+}
+
+// ConfigProfileInfo 表示配置描述文件信息
+type ConfigProfileInfo struct {
+	Name         string   // 名称
+	Identifier   string   // 标识符
+	Organization string   // 组织
+	Scope        string   // 作用范围（设备或用户名）
+	PayloadTypes []string // 包含的负载类型
+}
+
+// CertificateInfo 表示证书信息
+type CertificateInfo struct {
+	Subject      string // 主题
+	Issuer       string // 颁发者
+	Store        string // 所在的证书存储或钥匙串
+	Purpose      string // 用途（CA证书、客户端认证等）
+	NotAfter     string // 到期时间
+	DaysLeft     int    // 剩余天数（已过期时为负数）
+	ExpiringSoon bool   // 是否将在30天内到期
+	Expired      bool   // 是否已过期
+}
+
+// RemoteAccessInfo 表示远程访问服务的开放状态
+type RemoteAccessInfo struct {
+	Name         string   // 服务名称（SSH、屏幕共享、远程桌面等）
+	Enabled      bool     // 是否启用
+	Ports        []int    // 监听端口
+	AllowedUsers []string // 允许连接的用户或组（为空表示所有用户）
+}
+
+// FileSharingInfo 表示文件共享服务状态和共享文件夹
+type FileSharingInfo struct {
+	Enabled   bool            // 文件共享服务是否启用
+	Protocols []string        // 已启用的共享协议（SMB、AFP）
+	Shares    []FileShareInfo // 共享文件夹
+}
+
+// FileShareInfo 表示共享文件夹信息
+type FileShareInfo struct {
+	Name        string // 共享名称
+	Path        string // 本地路径
+	Protocols   string // 共享协议
+	Permissions string // 访问权限
+	GuestAccess bool   // 是否允许访客访问
+}
+
+// WorkloadInfo 表示本机运行的容器或虚拟机
+type WorkloadInfo struct {
+	Name   string  // 名称
+	Kind   string  // 类型（Docker容器、Podman容器、Parallels、VMware、Hyper-V、WSL2等）
+	State  string  // 运行状态
+	Image  string  // 容器镜像或虚拟机配置文件路径
+	CPUs   float64 // 分配的CPU数量，0表示未限制或未知
+	Memory uint64  // 分配的内存（字节），0表示未限制或未知
+}
+
+// SearchIndexInfo 表示系统搜索索引状态
+type SearchIndexInfo struct {
+	Service    string                  // 索引服务（Spotlight、Windows Search）
+	State      string                  // 服务状态
+	StartType  string                  // 启动类型（Windows）
+	Volumes    []SearchIndexVolumeInfo // 各卷的索引状态（macOS）
+	IndexSize  uint64                  // 索引数据库大小（字节，Windows）
+	IndexerCPU float64                 // 索引相关进程的CPU使用率合计
+	Busy       bool                    // 索引进程CPU占用过高，可能正在大量重建索引
+}
+
+// SearchIndexVolumeInfo 表示单个卷的Spotlight索引状态
+type SearchIndexVolumeInfo struct {
+	Volume  string // 卷挂载点
+	Enabled bool   // 是否启用索引
+	Status  string // mdutil报告的状态
+}
+
+// ResourceHogsInfo 表示按CPU、内存和网络排序的资源占用最高的进程
+type ResourceHogsInfo struct {
+	SampleWindow string           // CPU使用率的采样时长
+	TopCPU       []ProcessHogInfo // CPU使用率最高的进程
+	TopMemory    []ProcessHogInfo // 内存占用最高的进程
+	TopNetwork   []ProcessHogInfo // 网络流量最高的进程
+	Offenders    []ProcessHogInfo // 超过阈值的进程
+}
+
+// ProcessHogInfo 表示一个进程在某项资源上的占用
+type ProcessHogInfo struct {
+	PID      int     // 进程ID
+	Name     string  // 进程名称
+	Resource string  // 资源类型（CPU、内存、网络）
+	Value    float64 // 占用值：CPU为百分比，内存为字节，网络为字节/秒
+	Exceeded bool    // 是否超过阈值
 }
 
 // ProcessInfo 表示进程信息